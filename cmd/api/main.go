@@ -2,27 +2,26 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
-	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
 	"github.com/hedeqiang/skeleton/internal/wire"
+	lifecycle "github.com/hedeqiang/skeleton/pkg/app"
+	"github.com/hedeqiang/skeleton/pkg/buildinfo"
+
 	"go.uber.org/zap"
 )
 
 func main() {
+	fmt.Print(buildinfo.Banner("api"))
+
 	// 使用 Wire 创建应用实例
 	application, err := wire.InitializeApplication()
 	if err != nil {
 		log.Fatalf("Failed to create application: %v", err)
 	}
 
-	// 创建一个 channel 来接收系统信号
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
 	// 在一个 goroutine 中启动应用
 	go func() {
 		if err := application.Run(); err != nil {
@@ -30,8 +29,8 @@ func main() {
 		}
 	}()
 
-	// 阻塞，直到接收到退出信号
-	sig := <-quit
+	// 阻塞，直到接收到退出信号；期间 SIGHUP 重新加载日志级别，SIGUSR1 输出调用栈，SIGUSR2 切换 debug 日志
+	sig := lifecycle.WaitForShutdown(application.Logger())
 	application.Logger().Info("Received signal, shutting down...", zap.String("signal", sig.String()))
 
 	// 创建一个带超时的 context 用于优雅关闭
@@ -4,53 +4,98 @@ import (
 	"context"
 	"fmt"
 	"github.com/hedeqiang/skeleton/internal/app"
+	"github.com/hedeqiang/skeleton/internal/config"
 	"github.com/hedeqiang/skeleton/internal/messaging/consumer"
 	"github.com/hedeqiang/skeleton/internal/wire"
+	lifecycle "github.com/hedeqiang/skeleton/pkg/app"
+	"github.com/hedeqiang/skeleton/pkg/buildinfo"
+	"github.com/hedeqiang/skeleton/pkg/logger"
 	"github.com/hedeqiang/skeleton/pkg/mq"
+	"github.com/hedeqiang/skeleton/pkg/pool"
 	"os"
-	"os/signal"
-	"syscall"
 	"time"
 
+	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
 func main() {
-	// 使用 Wire 初始化应用
-	application, err := wire.InitializeApplication()
+	fmt.Print(buildinfo.Banner("consumer"))
+
+	// 使用 Wire 初始化应用和消息消费服务（自动注册所有事件处理器）
+	consumerApp, err := wire.InitializeConsumer()
 	if err != nil {
 		fmt.Printf("Failed to initialize application: %v\n", err)
 		os.Exit(1)
 	}
+	application := consumerApp.App
+	messageConsumerService := consumerApp.Service
+	archiver := consumerApp.Archiver
 
 	application.Logger().Info("Starting message consumer service...")
 
-	// 创建消息消费服务（自动注册所有事件处理器）
-	messageConsumerService := consumer.NewMessageConsumerService(application)
+	// 创建消息处理工作池，限制并发处理的消息数量
+	workerPool := pool.New(pool.Config{
+		Name:      "consumer",
+		Workers:   application.Config.Consumer.Workers,
+		QueueSize: application.Config.Consumer.QueueSize,
+	}, pool.WithPanicHandler(func(recovered interface{}, stack []byte) {
+		application.Logger().Error("Message handler panicked",
+			zap.Any("panic", recovered),
+			zap.ByteString("stack", stack),
+		)
+	}))
 
-	// 创建 RabbitMQ Consumer
-	rabbitConsumer, err := mq.NewConsumer(application.RabbitMQ)
+	// 本实例消费的队列，consumer.queues 中配置了 workers 的队列使用独立的工作池
+	queues, err := application.Config.Consumer.ConsumedQueues(application.Config.RabbitMQ.Queues)
 	if err != nil {
-		application.Logger().Fatal("Failed to create RabbitMQ consumer", zap.Error(err))
+		application.Logger().Fatal("Invalid consumer queue selection", zap.Error(err))
+	}
+	queuePools := newQueueWorkerPools(application)
+
+	// 消费根 context，关闭时取消仍在处理的消息，处理函数收到的 ctx 均由它派生
+	consumeCtx, cancelConsume := context.WithCancel(context.Background())
+	defer cancelConsume()
+
+	// 成功确认的消息计入每日统计；开启消息归档时，每条消息处理后连同结果写入归档
+	stats := consumerApp.Stats
+	if archiver != nil {
+		archiver.Start()
+	}
+	observer := func(queue string, d amqp.Delivery, outcome string, err error) {
+		if outcome == mq.OutcomeAcked {
+			stats.RecordMessageProcessed(consumeCtx)
+		}
+		if archiver != nil {
+			archiver.Observe(queue, d, outcome, err)
+		}
 	}
-	defer rabbitConsumer.Close()
 
-	// 使用配置化的方式设置 RabbitMQ 基础设施（避免重复定义）
-	if err := rabbitConsumer.SetupInfrastructureFromConfig(&application.Config.RabbitMQ); err != nil {
-		application.Logger().Fatal("Failed to setup RabbitMQ infrastructure from config", zap.Error(err))
+	// 为每个 broker 创建独立的消费连接，并按配置设置 RabbitMQ 基础设施（避免重复定义）
+	rabbitConsumers, err := newBrokerConsumers(consumeCtx, application, workerPool, queuePools, observer)
+	if err != nil {
+		application.Logger().Fatal("Failed to create RabbitMQ consumers", zap.Error(err))
+	}
+
+	// driver 为 redis 的队列由 Redis Streams 消费者处理，死信按交换机所属驱动转发
+	redisConsumer, err := newRedisStreamConsumer(consumeCtx, application, workerPool, queuePools, observer, consumerApp.Publisher)
+	if err != nil {
+		application.Logger().Fatal("Failed to create Redis Streams consumer", zap.Error(err))
+	}
+	// driver 为 nats 的队列由 JetStream durable 消费者处理
+	natsConsumer, err := newJetStreamConsumer(consumeCtx, application, workerPool, queuePools, observer, consumerApp.Publisher)
+	if err != nil {
+		application.Logger().Fatal("Failed to create NATS JetStream consumer", zap.Error(err))
 	}
 
 	// 启动消息消费
-	if err := startMessageConsumption(application, messageConsumerService, rabbitConsumer); err != nil {
+	if err := startMessageConsumption(application, messageConsumerService, queues, rabbitConsumers, redisConsumer, natsConsumer); err != nil {
 		application.Logger().Fatal("Failed to start message consumption", zap.Error(err))
 	}
 
-	// 等待中断信号
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-
+	// 等待中断信号；期间 SIGHUP 重新加载日志级别，SIGUSR1 输出调用栈，SIGUSR2 切换 debug 日志
 	application.Logger().Info("Message consumer service is running. Press Ctrl+C to exit.")
-	<-quit
+	lifecycle.WaitForShutdown(application.Logger())
 
 	application.Logger().Info("Received shutdown signal, stopping message consumer service...")
 
@@ -58,6 +103,39 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// 停止接收新消息，等待正在处理的消息完成后再关闭 channel
+	for broker, rabbitConsumer := range rabbitConsumers {
+		if err := rabbitConsumer.Cancel(); err != nil {
+			application.Logger().Error("Error cancelling RabbitMQ consumer", zap.String("broker", broker), zap.Error(err))
+		}
+	}
+	if redisConsumer != nil {
+		redisConsumer.Cancel()
+	}
+	if natsConsumer != nil {
+		natsConsumer.Cancel()
+	}
+	if err := workerPool.Shutdown(ctx); err != nil {
+		application.Logger().Error("Error draining consumer worker pool", zap.Error(err))
+	}
+	for queue, queuePool := range queuePools {
+		if err := queuePool.Shutdown(ctx); err != nil {
+			application.Logger().Error("Error draining queue worker pool", zap.String("queue", queue), zap.Error(err))
+		}
+	}
+	// 等待超时后取消仍在处理的消息，由处理函数尽快返回并重新入队
+	cancelConsume()
+	if archiver != nil {
+		if err := archiver.Close(ctx); err != nil {
+			application.Logger().Error("Error flushing message archive", zap.Error(err))
+		}
+	}
+	for broker, rabbitConsumer := range rabbitConsumers {
+		if err := rabbitConsumer.Close(); err != nil {
+			application.Logger().Error("Error closing RabbitMQ consumer", zap.String("broker", broker), zap.Error(err))
+		}
+	}
+
 	if err := messageConsumerService.Shutdown(ctx); err != nil {
 		application.Logger().Error("Error during message consumer service shutdown", zap.Error(err))
 	}
@@ -69,18 +147,147 @@ func main() {
 	application.Logger().Info("Message consumer service stopped gracefully")
 }
 
-// startMessageConsumption 启动消息消费
-func startMessageConsumption(app *app.App, messageConsumerService *consumer.MessageConsumerService, rabbitConsumer *mq.Consumer) error {
+// newQueueWorkerPools 为 consumer.queues 中配置了 workers 的队列创建独立的工作池
+func newQueueWorkerPools(app *app.App) map[string]*pool.Pool {
+	pools := make(map[string]*pool.Pool)
+	for _, queueCfg := range app.Config.Consumer.Queues {
+		if queueCfg.Workers <= 0 {
+			continue
+		}
+		queue := queueCfg.Name
+		pools[queue] = pool.New(pool.Config{
+			Name:      "consumer:" + queue,
+			Workers:   queueCfg.Workers,
+			QueueSize: queueCfg.QueueSize,
+		}, pool.WithPanicHandler(func(recovered interface{}, stack []byte) {
+			app.Logger().Error("Message handler panicked",
+				zap.String("queue", queue),
+				zap.Any("panic", recovered),
+				zap.ByteString("stack", stack),
+			)
+		}))
+	}
+	return pools
+}
+
+// newBrokerConsumers 为配置了交换机或队列的每个 broker 创建消费者，共享同一个工作池，queuePools 中的队列使用各自的工作池
+// 每条消息的处理 context 由 ctx 派生，并按配置设置处理超时；observer 非空时接收每条消息的处理结果
+func newBrokerConsumers(ctx context.Context, app *app.App, workerPool *pool.Pool, queuePools map[string]*pool.Pool, observer mq.DeliveryObserver) (map[string]*mq.Consumer, error) {
+	used := make(map[string]bool)
+	for _, exchangeCfg := range app.Config.RabbitMQ.Exchanges {
+		if app.Config.RabbitMQ.ExchangeDriver(exchangeCfg.Name) == config.DriverRabbitMQ {
+			used[exchangeCfg.BrokerName()] = true
+		}
+	}
+	for _, queueCfg := range app.Config.RabbitMQ.Queues {
+		if queueCfg.DriverName() == config.DriverRabbitMQ {
+			used[queueCfg.BrokerName()] = true
+		}
+	}
+
+	rabbitConsumers := make(map[string]*mq.Consumer, len(used))
+	for broker := range used {
+		conn, err := app.Brokers.Connection(broker, mq.RoleConsumer)
+		if err != nil {
+			return nil, err
+		}
+		producer, err := app.Brokers.Producer(broker)
+		if err != nil {
+			return nil, err
+		}
+
+		rabbitConsumer, err := mq.NewConsumer(conn,
+			mq.WithWorkerPool(workerPool),
+			mq.WithQueueWorkerPools(queuePools),
+			mq.WithBroker(broker),
+			mq.WithDeadLetterProducer(producer),
+			mq.WithContext(ctx),
+			mq.WithProcessingTimeout(app.Config.Consumer.ProcessingTimeout),
+			mq.WithDeliveryObserver(observer),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("broker %s: %w", broker, err)
+		}
+		if err := rabbitConsumer.SetupInfrastructureFromConfig(&app.Config.RabbitMQ); err != nil {
+			rabbitConsumer.Close()
+			return nil, fmt.Errorf("failed to setup RabbitMQ infrastructure on broker %s: %w", broker, err)
+		}
+		rabbitConsumers[broker] = rabbitConsumer
+	}
+
+	return rabbitConsumers, nil
+}
+
+// newRedisStreamConsumer 配置了 driver 为 redis 的队列时创建 Redis Streams 消费者并创建消费者组，否则返回 nil
+// 与 RabbitMQ 消费者共享工作池、队列独立工作池、根 context、处理超时和观察者
+func newRedisStreamConsumer(ctx context.Context, app *app.App, workerPool *pool.Pool, queuePools map[string]*pool.Pool, observer mq.DeliveryObserver, publisher mq.Publisher) (*mq.RedisStreamConsumer, error) {
+	if !app.Config.RabbitMQ.UsesDriver(config.DriverRedis) {
+		return nil, nil
+	}
+
+	onError := func(queue string, err error) {
+		app.Logger().Error("Redis Streams consumer error", zap.String("queue", queue), zap.Error(err))
+	}
+	redisConsumer := mq.NewRedisStreamConsumer(app.Redis, &app.Config.RedisStreams, onError,
+		mq.WithWorkerPool(workerPool),
+		mq.WithQueueWorkerPools(queuePools),
+		mq.WithDeadLetterProducer(publisher),
+		mq.WithContext(ctx),
+		mq.WithProcessingTimeout(app.Config.Consumer.ProcessingTimeout),
+		mq.WithDeliveryObserver(observer),
+	)
+	if err := redisConsumer.SetupInfrastructureFromConfig(&app.Config.RabbitMQ); err != nil {
+		return nil, err
+	}
+	return redisConsumer, nil
+}
+
+// newJetStreamConsumer 配置了 driver 为 nats 的队列时创建 JetStream 消费者并创建 durable 消费者，否则返回 nil
+// 与 RabbitMQ 消费者共享工作池、队列独立工作池、根 context、处理超时和观察者
+func newJetStreamConsumer(ctx context.Context, app *app.App, workerPool *pool.Pool, queuePools map[string]*pool.Pool, observer mq.DeliveryObserver, publisher mq.Publisher) (*mq.JetStreamConsumer, error) {
+	if app.JetStream == nil {
+		return nil, nil
+	}
+
+	onError := func(queue string, err error) {
+		app.Logger().Error("NATS JetStream consumer error", zap.String("queue", queue), zap.Error(err))
+	}
+	natsConsumer := mq.NewJetStreamConsumer(app.JetStream, onError,
+		mq.WithWorkerPool(workerPool),
+		mq.WithQueueWorkerPools(queuePools),
+		mq.WithDeadLetterProducer(publisher),
+		mq.WithContext(ctx),
+		mq.WithProcessingTimeout(app.Config.Consumer.ProcessingTimeout),
+		mq.WithDeliveryObserver(observer),
+	)
+	if err := natsConsumer.SetupInfrastructureFromConfig(&app.Config.RabbitMQ); err != nil {
+		return nil, err
+	}
+	return natsConsumer, nil
+}
+
+// queueConsumer 队列消费者，RabbitMQ、Redis Streams 和 JetStream 消费者均实现
+type queueConsumer interface {
+	Consume(queueName, consumerName string, handler mq.MessageHandler) error
+}
+
+// startMessageConsumption 为本实例消费的每个队列启动消费者
+func startMessageConsumption(app *app.App, messageConsumerService *consumer.MessageConsumerService, queues []config.QueueConfig, rabbitConsumers map[string]*mq.Consumer, redisConsumer *mq.RedisStreamConsumer, natsConsumer *mq.JetStreamConsumer) error {
 	app.Logger().Info("Starting message consumption...")
 
-	// 从配置中获取队列名称
-	if len(app.Config.RabbitMQ.Queues) == 0 {
-		return fmt.Errorf("no queues configured")
+	if len(queues) == 0 {
+		return fmt.Errorf("no queues to consume")
 	}
 
-	// 为每个配置的队列启动消费者
-	for _, queueConfig := range app.Config.RabbitMQ.Queues {
-		if err := startQueueConsumer(app, messageConsumerService, rabbitConsumer, queueConfig.Name); err != nil {
+	for _, queueConfig := range queues {
+		var target queueConsumer = rabbitConsumers[queueConfig.BrokerName()]
+		switch queueConfig.DriverName() {
+		case config.DriverRedis:
+			target = redisConsumer
+		case config.DriverNATS:
+			target = natsConsumer
+		}
+		if err := startQueueConsumer(app, messageConsumerService, target, queueConfig.Name); err != nil {
 			return fmt.Errorf("failed to start consumer for queue %s: %w", queueConfig.Name, err)
 		}
 	}
@@ -93,38 +300,36 @@ func startMessageConsumption(app *app.App, messageConsumerService *consumer.Mess
 }
 
 // startQueueConsumer 启动单个队列的消费者
-func startQueueConsumer(app *app.App, messageConsumerService *consumer.MessageConsumerService, rabbitConsumer *mq.Consumer, queueName string) error {
+func startQueueConsumer(app *app.App, messageConsumerService *consumer.MessageConsumerService, queueConsumer queueConsumer, queueName string) error {
 	app.Logger().Info("Starting consumer for queue", zap.String("queue", queueName))
 
-	// 创建消息处理函数
+	// 逐条消息的日志写入 mq logger，可通过 logger.levels.mq 单独调整级别
+	mqLogger := app.Logger().Named(mq.LoggerName)
+
+	// 创建消息处理函数，ctx 携带 queue 日志字段，之后的处理链路中自动带上
 	messageHandler := func(ctx context.Context, body []byte) error {
-		app.Logger().Info("Processing message from queue",
-			zap.String("queue", queueName),
-			zap.Int("body_size", len(body)),
-		)
+		ctx = logger.WithFields(ctx, zap.String("queue", queueName))
+		log := logger.FromContext(ctx, mqLogger)
+
+		log.Info("Processing message from queue", zap.Int("body_size", len(body)))
 
 		// 委托给消息消费服务处理
 		if err := messageConsumerService.ConsumeMessage(ctx, body); err != nil {
-			app.Logger().Error("Failed to consume message",
-				zap.Error(err),
-				zap.String("queue", queueName),
-			)
+			log.Error("Failed to consume message", zap.Error(err))
 			return err
 		}
 
-		app.Logger().Debug("Message processed successfully",
-			zap.String("queue", queueName),
-		)
+		log.Debug("Message processed successfully")
 		return nil
 	}
 
-	// 启动消费协程（mq.Consumer.Consume 会阻塞，所以放在 goroutine 中）
+	// 启动消费协程（Consume 会阻塞，所以放在 goroutine 中）
 	go func() {
 		app.Logger().Info("Started consuming messages from queue",
 			zap.String("queue", queueName),
 		)
 
-		if err := rabbitConsumer.Consume(queueName, "", messageHandler); err != nil {
+		if err := queueConsumer.Consume(queueName, "", messageHandler); err != nil {
 			app.Logger().Error("Consumer stopped with error",
 				zap.Error(err),
 				zap.String("queue", queueName),
@@ -1,30 +1,39 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
-	"os"
-	"os/signal"
-	"syscall"
+	"net/http"
+	"time"
 
 	"github.com/hedeqiang/skeleton/internal/config"
 	"github.com/hedeqiang/skeleton/internal/scheduler"
+	lifecycle "github.com/hedeqiang/skeleton/pkg/app"
+	"github.com/hedeqiang/skeleton/pkg/buildinfo"
+	"github.com/hedeqiang/skeleton/pkg/clock"
 	"github.com/hedeqiang/skeleton/pkg/logger"
+	"github.com/hedeqiang/skeleton/pkg/metrics"
 
 	"go.uber.org/zap"
 )
 
 func main() {
+	fmt.Print(buildinfo.Banner("scheduler"))
+
 	// 加载配置
 	cfg, err := config.LoadConfig()
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	// 初始化日志
+	// 初始化日志，只输出到标准输出，编码格式和服务信息与配置一致，便于日志采集
 	loggerConfig := &config.Logger{
 		Level:      cfg.Logger.Level,
-		Encoding:   "console",
+		Encoding:   cfg.Logger.Encoding,
 		OutputPath: []string{"stdout"},
+		Service:    cfg.Logger.Service,
 	}
 
 	zapLogger, err := logger.New(loggerConfig)
@@ -34,28 +43,42 @@ func main() {
 	defer zapLogger.Sync()
 
 	zapLogger.Info("Starting scheduler service...")
+	if violations := cfg.AuditProduction(); len(violations) > 0 {
+		zapLogger.Warn("Starting with insecure production config", zap.Strings("violations", violations))
+	}
 
 	// 创建调度器服务
-	schedulerService, err := scheduler.NewSchedulerService(zapLogger)
+	schedulerService, err := scheduler.NewSchedulerService(zapLogger, cfg.Scheduler.Timezone)
 	if err != nil {
 		zapLogger.Fatal("Failed to create scheduler service", zap.Error(err))
 	}
 
 	// 创建任务管理器
-	jobRegistry := scheduler.NewJobRegistry(schedulerService, zapLogger, cfg.Scheduler)
+	jobRegistry := scheduler.NewJobRegistry(schedulerService, zapLogger, clock.New(), cfg.Scheduler)
 
 	// 启动任务管理器
 	if err := jobRegistry.Start(); err != nil {
 		zapLogger.Fatal("Failed to start job registry", zap.Error(err))
 	}
 
-	zapLogger.Info("Scheduler service started successfully")
+	// 启动指标服务
+	var metricsServer *http.Server
+	if cfg.Scheduler.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		metricsServer = &http.Server{Addr: cfg.Scheduler.MetricsAddr, Handler: mux}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				zapLogger.Error("Metrics server failed", zap.Error(err))
+			}
+		}()
+		zapLogger.Info("Metrics server started", zap.String("addr", cfg.Scheduler.MetricsAddr))
+	}
 
-	// 等待关闭信号
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	zapLogger.Info("Scheduler service started successfully")
 
-	<-quit
+	// 等待关闭信号；期间 SIGHUP 重新加载日志级别，SIGUSR1 输出调用栈，SIGUSR2 切换 debug 日志
+	lifecycle.WaitForShutdown(zapLogger)
 	zapLogger.Info("Shutting down scheduler service...")
 
 	// 停止任务管理器
@@ -63,5 +86,13 @@ func main() {
 		zapLogger.Error("Failed to stop job registry gracefully", zap.Error(err))
 	}
 
+	if metricsServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			zapLogger.Error("Failed to stop metrics server", zap.Error(err))
+		}
+	}
+
 	zapLogger.Info("Scheduler service stopped")
 }
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/messaging"
+	"github.com/hedeqiang/skeleton/internal/messaging/consumer"
+	"github.com/hedeqiang/skeleton/internal/wire"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+
+	"go.uber.org/zap"
+)
+
+const consumeUsage = "Usage: skeleton consume --from-file <messages.ndjson|-> [--dry-run]"
+
+// consumeOutcomes 汇总输出的处理结果
+var consumeOutcomes = []string{mq.OutcomeAcked, mq.OutcomeDeadLettered, mq.OutcomeDropped, mq.OutcomeParked, mq.OutcomeRequeued, mq.OutcomeRejected}
+
+// consumeFile 执行 consume 命令并返回退出码，所有消息都处理成功时为 0
+// 按消费者的规则输出每条消息的处理结果：成功为 acked，死信错误为 dead_lettered，按失败策略丢弃或停放为 dropped、parked，
+// 其他错误为 requeued，panic 为 rejected
+func consumeFile(args []string) int {
+	cmd := flag.NewFlagSet("consume", flag.ExitOnError)
+	cmd.Usage = func() {
+		fmt.Fprintln(cmd.Output(), consumeUsage)
+		cmd.PrintDefaults()
+	}
+	fromFile := cmd.String("from-file", "", "NDJSON 消息文件，每行一个消息信封或归档记录，- 表示标准输入")
+	dryRun := cmd.Bool("dry-run", false, "处理器的外部依赖替换为只记录日志的实现，不连接数据库、缓存和消息队列")
+	cmd.Parse(args)
+
+	if *fromFile == "" || cmd.NArg() > 0 {
+		cmd.Usage()
+		return 2
+	}
+
+	var input io.Reader = os.Stdin
+	if *fromFile != "-" {
+		file, err := os.Open(*fromFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open message file: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		input = file
+	}
+
+	var (
+		service   *consumer.MessageConsumerService
+		zapLogger *zap.Logger
+		timeout   time.Duration
+		stop      = func() {}
+	)
+	if *dryRun {
+		dryRunApp, err := wire.InitializeDryRunConsumer()
+		if err != nil {
+			log.Printf("Failed to initialize dry-run consumer: %v", err)
+			return 1
+		}
+		service, zapLogger, timeout = dryRunApp.Service, dryRunApp.Logger, dryRunApp.Config.Consumer.ProcessingTimeout
+		stop = func() { zapLogger.Sync() }
+	} else {
+		// 使用消费者的注入器，处理器及其依赖与消费者进程一致
+		consumerApp, err := wire.InitializeConsumer()
+		if err != nil {
+			log.Printf("Failed to initialize application: %v", err)
+			return 1
+		}
+		service, zapLogger, timeout = consumerApp.Service, consumerApp.App.Logger(), consumerApp.App.Config.Consumer.ProcessingTimeout
+		stop = func() { stopApplication(consumerApp.App) }
+	}
+
+	total := 0
+	counts := make(map[string]int)
+	ctx := logger.WithFields(context.Background(), zap.Bool("dry_run", *dryRun))
+	err := messaging.ReadMessageFile(input, func(line int, body []byte) error {
+		var envelope messaging.MessageEnvelope
+		_ = json.Unmarshal(body, &envelope)
+
+		msgCtx := logger.WithFields(ctx, zap.Int("line", line))
+		outcome, err := consumeOne(msgCtx, service.ConsumeMessage, body, timeout)
+		total++
+		counts[outcome]++
+
+		result := fmt.Sprintf("%d\t%s\t%s\t%s", line, envelope.MessageID, envelope.MessageType, outcome)
+		if err != nil {
+			result += "\t" + err.Error()
+		}
+		fmt.Println(result)
+		return nil
+	})
+	if err != nil {
+		zapLogger.Error("Failed to read message file", zap.String("file", *fromFile), zap.Error(err))
+	}
+
+	summary := make([]string, 0, len(consumeOutcomes))
+	for _, outcome := range consumeOutcomes {
+		summary = append(summary, fmt.Sprintf("%d %s", counts[outcome], outcome))
+	}
+	fmt.Printf("Processed %d messages: %s\n", total, strings.Join(summary, ", "))
+
+	stop()
+	if err != nil || counts[mq.OutcomeAcked] != total {
+		return 1
+	}
+	return 0
+}
+
+// consumeOne 处理一条消息并返回消费者对应的处理结果，timeout 大于 0 时限制处理时间
+func consumeOne(ctx context.Context, consume func(context.Context, []byte) error, body []byte, timeout time.Duration) (outcome string, err error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			outcome, err = mq.OutcomeRejected, fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	err = consume(ctx, body)
+	var (
+		dlErr   *mq.DeadLetterError
+		dropErr *mq.DropError
+		parkErr *mq.ParkError
+	)
+	switch {
+	case err == nil:
+		return mq.OutcomeAcked, nil
+	case errors.As(err, &dlErr):
+		return mq.OutcomeDeadLettered, err
+	case errors.As(err, &dropErr):
+		return mq.OutcomeDropped, err
+	case errors.As(err, &parkErr):
+		return mq.OutcomeParked, err
+	default:
+		return mq.OutcomeRequeued, err
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/app"
+	"github.com/hedeqiang/skeleton/internal/wire"
+
+	"go.uber.org/zap"
+)
+
+const jobRunUsage = "Usage: skeleton job run <name> [--params key=value ...] [--timeout 30m]"
+
+// runJob 执行 job run 命令并返回退出码
+func runJob(args []string) int {
+	cmd := flag.NewFlagSet("job run", flag.ExitOnError)
+	cmd.Usage = func() {
+		fmt.Fprintln(cmd.Output(), jobRunUsage)
+		cmd.PrintDefaults()
+	}
+	params := paramsFlag{}
+	cmd.Var(params, "params", "任务参数 key=value，可重复指定，覆盖 scheduler.jobs 中配置的同名参数")
+	timeout := cmd.Duration("timeout", 30*time.Minute, "执行超时时间，超时后以失败退出")
+
+	// 任务名称在前，参数在后：job run cleanup --params older_than=720h
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		cmd.Usage()
+		return 2
+	}
+	name := args[0]
+	cmd.Parse(args[1:])
+	if cmd.NArg() > 0 {
+		cmd.Usage()
+		return 2
+	}
+
+	command, err := wire.InitializeCommand()
+	if err != nil {
+		log.Printf("Failed to initialize application: %v", err)
+		return 1
+	}
+	application := command.App
+	zapLogger := application.Logger().With(zap.String("job_name", name))
+
+	// SIGTERM（如 CronJob 被终止）和 Ctrl+C 取消任务 ctx
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	zapLogger.Info("Running job once", zap.Any("params", map[string]interface{}(params)), zap.Duration("timeout", *timeout))
+	start := time.Now()
+
+	// 任务不一定响应 ctx 取消，超时或中断后不再等待任务返回
+	done := make(chan error, 1)
+	go func() {
+		done <- application.JobRegistry.RunOnce(ctx, name, params)
+	}()
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			zapLogger.Error("Job timed out", zap.Duration("timeout", *timeout))
+		} else {
+			zapLogger.Error("Job failed", zap.Error(err))
+		}
+	} else {
+		zapLogger.Info("Job completed", zap.Duration("duration", time.Since(start)))
+	}
+
+	stopApplication(application)
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+// stopApplication 释放命令初始化的资源
+func stopApplication(application *app.App) {
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer stopCancel()
+	if err := application.Stop(stopCtx); err != nil {
+		application.Logger().Error("Error during application shutdown", zap.Error(err))
+	}
+}
+
+// paramsFlag 可重复指定的 key=value 参数，值统一为字符串，由任务解码时转换类型
+type paramsFlag map[string]interface{}
+
+func (p paramsFlag) String() string {
+	pairs := make([]string, 0, len(p))
+	for key, value := range p {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", key, value))
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (p paramsFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("invalid param %q, expected key=value", value)
+	}
+	p[key] = val
+	return nil
+}
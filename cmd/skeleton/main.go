@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// 运维命令
+//
+//	skeleton job run <name> [--params key=value ...] [--timeout 30m]
+//	skeleton mq publish --type <message_type> [--payload json] [--exchange name] [--routing-key key]
+//	skeleton mq schemas sync [--url http://schema-registry:8081]
+//	skeleton consume --from-file <messages.ndjson|-> [--dry-run]
+//	skeleton mock [--spec docs/swagger/swagger.json] [--host 127.0.0.1] [--port 4010] [--dynamic]
+//
+// job run 初始化全部依赖后立即执行一次任务，成功退出码为 0，失败、超时或被中断为 1，
+// 用于本地调试任务和 Kubernetes CronJob 等不常驻调度器的部署
+//
+// mq publish 将载荷包装为事件信封发布到配置的交换机，用于在本地触发消息处理器
+//
+// mq schemas sync 将消息载荷 schema 推送到 Confluent Schema Registry 兼容的服务
+//
+// consume 将文件中的消息逐条交给消费者的处理器，--dry-run 时处理器不产生外部副作用，用于用归档消息复现线上问题
+//
+// mock 按 OpenAPI 规范返回示例响应，用于在后端接口实现之前进行前端联调
+func main() {
+	if len(os.Args) >= 2 && os.Args[1] == "consume" {
+		os.Exit(consumeFile(os.Args[2:]))
+	}
+	if len(os.Args) >= 2 && os.Args[1] == "mock" {
+		os.Exit(serveMock(os.Args[2:]))
+	}
+	if len(os.Args) < 3 {
+		printUsage()
+		os.Exit(2)
+	}
+
+	switch os.Args[1] + " " + os.Args[2] {
+	case "job run":
+		os.Exit(runJob(os.Args[3:]))
+	case "mq publish":
+		os.Exit(publishMessage(os.Args[3:]))
+	case "mq schemas":
+		os.Exit(syncSchemas(os.Args[3:]))
+	default:
+		printUsage()
+		os.Exit(2)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, jobRunUsage)
+	fmt.Fprintln(os.Stderr, mqPublishUsage)
+	fmt.Fprintln(os.Stderr, mqSchemasSyncUsage)
+	fmt.Fprintln(os.Stderr, consumeUsage)
+	fmt.Fprintln(os.Stderr, mockUsage)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/openapi"
+)
+
+const mockUsage = "Usage: skeleton mock [--spec docs/swagger/swagger.json] [--host 127.0.0.1] [--port 4010] [--dynamic]"
+
+// serveMock 执行 mock 命令，按 OpenAPI 规范返回示例响应直到收到中断信号
+// 不加载配置、不连接任何依赖，只需要规范文件
+func serveMock(args []string) int {
+	cmd := flag.NewFlagSet("mock", flag.ExitOnError)
+	cmd.Usage = func() {
+		fmt.Fprintln(cmd.Output(), mockUsage)
+		cmd.PrintDefaults()
+	}
+	spec := cmd.String("spec", "docs/swagger/swagger.json", "OpenAPI 规范文件，支持 Swagger 2.0 和 OpenAPI 3")
+	host := cmd.String("host", "127.0.0.1", "监听地址")
+	port := cmd.Int("port", 4010, "监听端口")
+	dynamic := cmd.Bool("dynamic", false, "忽略规范中的示例，按 schema 类型随机生成响应")
+	cmd.Parse(args)
+
+	if cmd.NArg() > 0 {
+		cmd.Usage()
+		return 2
+	}
+
+	mock, err := openapi.LoadMock(*spec, openapi.WithDynamicExamples(*dynamic))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load OpenAPI spec: %v\n", err)
+		return 1
+	}
+
+	server := &http.Server{
+		Addr:              net.JoinHostPort(*host, strconv.Itoa(*port)),
+		Handler:           mock,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+	fmt.Fprintf(os.Stderr, "Mock server serving %s on http://%s\n", *spec, server.Addr)
+
+	select {
+	case err = <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Mock server failed: %v\n", err)
+			return 1
+		}
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to shut down mock server: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
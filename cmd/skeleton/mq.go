@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/schemas"
+	"github.com/hedeqiang/skeleton/internal/wire"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+
+	"go.uber.org/zap"
+)
+
+const mqPublishUsage = "Usage: skeleton mq publish --type <message_type> [--payload json] [--exchange name] [--routing-key key]"
+
+// publishMessage 执行 mq publish 命令并返回退出码
+// 路由键默认与消息类型相同；未指定交换机时使用配置中绑定了该路由键的第一个消费队列的交换机
+func publishMessage(args []string) int {
+	cmd := flag.NewFlagSet("mq publish", flag.ExitOnError)
+	cmd.Usage = func() {
+		fmt.Fprintln(cmd.Output(), mqPublishUsage)
+		cmd.PrintDefaults()
+	}
+	messageType := cmd.String("type", "", "消息类型，对应处理器的 GetSupportedMessageType")
+	payload := cmd.String("payload", "{}", "JSON 载荷，写入信封的 payload 字段")
+	exchange := cmd.String("exchange", "", "交换机，默认按路由键从 rabbitmq.queues 中查找")
+	routingKey := cmd.String("routing-key", "", "路由键，默认与消息类型相同")
+	cmd.Parse(args)
+
+	if *messageType == "" || cmd.NArg() > 0 {
+		cmd.Usage()
+		return 2
+	}
+	if !json.Valid([]byte(*payload)) {
+		fmt.Fprintf(os.Stderr, "Invalid payload, expected JSON: %s\n", *payload)
+		return 2
+	}
+	if *routingKey == "" {
+		*routingKey = *messageType
+	}
+
+	command, err := wire.InitializeCommand()
+	if err != nil {
+		log.Printf("Failed to initialize application: %v", err)
+		return 1
+	}
+	application := command.App
+	zapLogger := application.Logger()
+
+	if *exchange == "" {
+		resolved, ok := mq.ExchangeFor(&application.Config.RabbitMQ, *routingKey)
+		if !ok {
+			zapLogger.Error("No configured queue is bound to the routing key, use --exchange",
+				zap.String("routing_key", *routingKey))
+			stopApplication(application)
+			return 1
+		}
+		*exchange = resolved
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	result, err := command.MQAdmin.PublishTest(ctx, &model.MQTestPublishRequest{
+		Exchange:    *exchange,
+		RoutingKey:  *routingKey,
+		MessageType: *messageType,
+		Payload:     json.RawMessage(*payload),
+	})
+	if err != nil {
+		zapLogger.Error("Failed to publish message",
+			zap.String("exchange", *exchange),
+			zap.String("routing_key", *routingKey),
+			zap.Error(err),
+		)
+	} else {
+		fmt.Printf("Published %s message %s to %s with routing key %s (%s)\n",
+			*messageType, result.MessageID, result.Exchange, result.RoutingKey, result.Driver)
+	}
+
+	stopApplication(application)
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+const mqSchemasSyncUsage = "Usage: skeleton mq schemas sync [--url http://schema-registry:8081]"
+
+// syncSchemas 执行 mq schemas sync 命令并返回退出码
+// 将 message_schemas.dir（默认内置）中的 schema 推送到 message_schemas.registry.url，只加载配置，不连接其他依赖
+func syncSchemas(args []string) int {
+	cmd := flag.NewFlagSet("mq schemas sync", flag.ExitOnError)
+	cmd.Usage = func() {
+		fmt.Fprintln(cmd.Output(), mqSchemasSyncUsage)
+		cmd.PrintDefaults()
+	}
+	registryURL := cmd.String("url", "", "schema 服务地址，默认 message_schemas.registry.url")
+	if len(args) == 0 || args[0] != "sync" {
+		cmd.Usage()
+		return 2
+	}
+	cmd.Parse(args[1:])
+	if cmd.NArg() > 0 {
+		cmd.Usage()
+		return 2
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Printf("Failed to load config: %v", err)
+		return 1
+	}
+	if *registryURL == "" {
+		*registryURL = cfg.Schemas.Registry.URL
+	}
+	if *registryURL == "" {
+		fmt.Fprintln(os.Stderr, "No schema registry configured, set message_schemas.registry.url or use --url")
+		return 2
+	}
+	timeout := cfg.Schemas.Registry.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+
+	registry, err := schemas.Load(cfg.Schemas.Dir)
+	if err != nil {
+		log.Printf("Failed to load message schemas: %v", err)
+		return 1
+	}
+	definitions := registry.Definitions()
+	client := &http.Client{Timeout: timeout}
+	if err := mq.PushSchemas(context.Background(), client, *registryURL, definitions); err != nil {
+		log.Printf("Failed to sync message schemas: %v", err)
+		return 1
+	}
+	for _, definition := range definitions {
+		fmt.Printf("%s\tv%s\n", definition.MessageType, definition.Version)
+	}
+	fmt.Printf("Synced %d schemas to %s\n", len(definitions), *registryURL)
+	return 0
+}
@@ -0,0 +1,27 @@
+// Package configs 内置各环境的默认配置文件
+package configs
+
+import (
+	"embed"
+	"fmt"
+)
+
+//go:embed config.dev.yaml config.docker.yaml config.prod.yaml
+var files embed.FS
+
+// Envs 内置配置支持的环境
+var Envs = []string{"dev", "docker", "prod"}
+
+// Default 返回指定环境的内置配置文件内容，包含所有配置段及注释
+func Default(env string) ([]byte, error) {
+	data, err := files.ReadFile(FileName(env))
+	if err != nil {
+		return nil, fmt.Errorf("no embedded config for env %q, available: %v", env, Envs)
+	}
+	return data, nil
+}
+
+// FileName 返回指定环境的配置文件名
+func FileName(env string) string {
+	return "config." + env + ".yaml"
+}
@@ -3,14 +3,25 @@ package app
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 
+	"github.com/hedeqiang/skeleton/internal/middleware"
 	"github.com/hedeqiang/skeleton/internal/router"
+	apiv1 "github.com/hedeqiang/skeleton/internal/router/api/v1"
+	"github.com/hedeqiang/skeleton/internal/router/system"
 	"github.com/hedeqiang/skeleton/internal/scheduler"
+	"github.com/hedeqiang/skeleton/internal/service"
+	lifecycle "github.com/hedeqiang/skeleton/pkg/app"
+	"github.com/hedeqiang/skeleton/pkg/buildinfo"
+	"github.com/hedeqiang/skeleton/pkg/database"
 	"github.com/hedeqiang/skeleton/pkg/idgen"
+	"github.com/hedeqiang/skeleton/pkg/loadshed"
+	mongopkg "github.com/hedeqiang/skeleton/pkg/mongo"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+	"github.com/hedeqiang/skeleton/pkg/trace"
 
 	"github.com/hedeqiang/skeleton/internal/config"
-	v1 "github.com/hedeqiang/skeleton/internal/handler/v1"
 
 	"github.com/gin-gonic/gin"
 	amqp "github.com/rabbitmq/amqp091-go"
@@ -28,9 +39,10 @@ type Application interface {
 
 // App 应用程序结构体，直接包含所有依赖
 type App struct {
-	// HTTP 服务
-	Engine *gin.Engine
-	Server *http.Server
+	// HTTP 服务，两个端口共用 Engine，由 listener_scope 中间件按端口限制可访问的路由
+	Engine         *gin.Engine
+	Server         *http.Server
+	InternalServer *http.Server // 内部端口，未配置 app.internal_port 时为 nil
 
 	// 基础设施依赖
 	logger      *zap.Logger
@@ -38,14 +50,27 @@ type App struct {
 	DataSources map[string]*gorm.DB
 	MainDB      *gorm.DB
 	Redis       *redis.Client
-	RabbitMQ    *amqp.Connection
+	Mongo       *mongopkg.Client      // 未启用 MongoDB 时为 nil
+	RabbitMQ    *amqp.Connection      // default broker 的生产连接
+	Brokers     *mq.Brokers           // 所有命名 broker 的连接
+	JetStream   *mq.JetStream         // NATS JetStream 连接，没有 driver 为 nats 的队列时为 nil
+	DBPool      *database.PoolMonitor // 连接池监控，未启用时为 nil
+	LoadShed    *loadshed.Monitor     // 自适应降载监控，未启用时为 nil
+	Warmup      *lifecycle.Warmup     // 启动预热，未启用时为 nil
+	Tracing     *trace.Provider       // 链路追踪，未启用时为 nil
 	IDGenerator idgen.IDGenerator
 
 	// 业务层依赖
-	UserHandler      *v1.UserHandler
-	HelloHandler     *v1.HelloHandler
-	SchedulerHandler *v1.SchedulerHandler
-	JobRegistry      *scheduler.JobRegistry
+	JobRegistry *scheduler.JobRegistry
+
+	// 后台任务服务，供消息消费者上报任务进度
+	TaskService service.TaskService
+	// 邮件服务，供消息消费者和计划任务发送模板邮件
+	MailService service.MailService
+	// 搜索服务，供命令行重建索引，未启用搜索时为 nil
+	SearchService service.SearchService
+	// 用户数据导出工作池，关闭时等待执行中的导出结束
+	ExportPool service.ExportPool
 }
 
 // NewApp 创建新的应用实例
@@ -55,22 +80,23 @@ func NewApp(
 	dataSources map[string]*gorm.DB,
 	mainDB *gorm.DB,
 	redis *redis.Client,
+	mongo *mongopkg.Client,
 	rabbitMQ *amqp.Connection,
+	brokers *mq.Brokers,
+	jetStream *mq.JetStream,
 	idGenerator idgen.IDGenerator,
-	userHandler *v1.UserHandler,
-	helloHandler *v1.HelloHandler,
-	schedulerHandler *v1.SchedulerHandler,
+	routes *apiv1.Registry,
+	readinessChecks system.ReadinessChecks,
 	jobRegistry *scheduler.JobRegistry,
+	taskService service.TaskService,
+	mailService service.MailService,
+	searchService service.SearchService,
+	exportPool service.ExportPool,
+	middlewares *router.Middlewares,
+	warmup *lifecycle.Warmup,
 ) *App {
-	// 创建处理器集合
-	handlers := &router.Handlers{
-		UserHandler:      userHandler,
-		HelloHandler:     helloHandler,
-		SchedulerHandler: schedulerHandler,
-	}
-
 	// 初始化路由
-	engine := router.SetupRouter(logger, handlers)
+	engine := router.SetupRouter(config, logger, routes, readinessChecks, middlewares)
 	logger.Info("Router initialized successfully")
 
 	// 初始化 HTTP Server
@@ -79,27 +105,53 @@ func NewApp(
 		Handler: engine,
 	}
 
+	// 内部端口的请求在 context 中带有标记，listener_scope 中间件据此区分端口
+	var internalServer *http.Server
+	if config.App.SplitListeners() {
+		internalServer = &http.Server{
+			Addr:    fmt.Sprintf("%s:%d", config.App.Host, config.App.InternalPort),
+			Handler: engine,
+			BaseContext: func(net.Listener) context.Context {
+				return middleware.WithInternalListener(context.Background())
+			},
+		}
+	}
+
 	app := &App{
-		Engine:           engine,
-		Server:           server,
-		logger:           logger,
-		Config:           config,
-		DataSources:      dataSources,
-		MainDB:           mainDB,
-		Redis:            redis,
-		RabbitMQ:         rabbitMQ,
-		IDGenerator:      idGenerator,
-		UserHandler:      userHandler,
-		HelloHandler:     helloHandler,
-		SchedulerHandler: schedulerHandler,
-		JobRegistry:      jobRegistry,
+		Engine:         engine,
+		Server:         server,
+		InternalServer: internalServer,
+		logger:         logger,
+		Config:         config,
+		DataSources:    dataSources,
+		MainDB:         mainDB,
+		Redis:          redis,
+		Mongo:          mongo,
+		RabbitMQ:       rabbitMQ,
+		Brokers:        brokers,
+		JetStream:      jetStream,
+		IDGenerator:    idGenerator,
+		JobRegistry:    jobRegistry,
+		TaskService:    taskService,
+		MailService:    mailService,
+		SearchService:  searchService,
+		ExportPool:     exportPool,
+		DBPool:         middlewares.DBPool,
+		LoadShed:       middlewares.LoadShed,
+		Warmup:         warmup,
+		Tracing:        middlewares.Tracing,
 	}
 
 	logger.Info("Application initialized successfully",
 		zap.String("host", config.App.Host),
 		zap.Int("port", config.App.Port),
+		zap.Int("internal_port", config.App.InternalPort),
 		zap.String("env", config.App.Env),
+		zap.String("version", buildinfo.Version),
 	)
+	if violations := config.AuditProduction(); len(violations) > 0 {
+		logger.Warn("Starting with insecure production config", zap.Strings("violations", violations))
+	}
 
 	return app
 }
@@ -116,17 +168,67 @@ func (app *App) Run() error {
 		}()
 	}
 
+	// 与 HTTP 服务器并行执行启动预热，完成前 /ready 返回 503，/health 不受影响
+	if app.Warmup != nil {
+		go func() {
+			_ = app.Warmup.Run(context.Background())
+		}()
+	}
+
+	// 先监听内部端口，端口被占用时直接返回错误，不启动公开端口
+	if app.InternalServer != nil {
+		listener, err := net.Listen("tcp", app.InternalServer.Addr)
+		if err != nil {
+			return err
+		}
+		app.logger.Info("Starting internal HTTP server",
+			zap.String("addr", app.InternalServer.Addr),
+		)
+		go func() {
+			if err := app.InternalServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+				app.logger.Error("Internal HTTP server failed", zap.Error(err))
+			}
+		}()
+	}
+
 	// 启动 HTTP 服务器
+	listener, err := app.listen()
+	if err != nil {
+		return err
+	}
 	app.logger.Info("Starting HTTP server",
-		zap.String("addr", app.Server.Addr),
+		zap.String("network", listener.Addr().Network()),
+		zap.String("addr", listener.Addr().String()),
 	)
-	// ListenAndServe 是一个阻塞操作，只有在服务器关闭时才会返回
-	if err := app.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	// Serve 是一个阻塞操作，只有在服务器关闭时才会返回
+	if err := app.Server.Serve(listener); err != nil && err != http.ErrServerClosed {
 		return err
 	}
 	return nil
 }
 
+// listen 按 app.listen 创建公开端口的监听器：TCP 的 host:port、Unix socket 或 systemd 传入的 socket
+func (app *App) listen() (net.Listener, error) {
+	cfg := app.Config.App.Listen
+	switch cfg.Network {
+	case "", config.ListenNetworkTCP:
+		return net.Listen("tcp", app.Server.Addr)
+	case config.ListenNetworkUnix:
+		if cfg.SocketPath == "" {
+			return nil, fmt.Errorf("app.listen.socket_path is required when app.listen.network is %q", cfg.Network)
+		}
+		mode, err := cfg.FileMode()
+		if err != nil {
+			return nil, err
+		}
+		return lifecycle.ListenUnix(cfg.SocketPath, mode)
+	case config.ListenNetworkSystemd:
+		return lifecycle.SystemdListener(cfg.FDName)
+	default:
+		return nil, fmt.Errorf("unsupported app.listen.network %q", cfg.Network)
+	}
+}
+
 // Stop 优雅地停止应用程序
 func (app *App) Stop(ctx context.Context) error {
 	app.logger.Info("Shutting down server...")
@@ -136,6 +238,25 @@ func (app *App) Stop(ctx context.Context) error {
 		app.logger.Error("Server forced to shutdown", zap.Error(err))
 	}
 
+	// 公开端口的请求处理完后再关闭内部端口，排空期间仍可访问健康检查和指标；
+	// 在关闭数据库等连接之前关闭，避免就绪检查和管理接口使用已关闭的连接
+	if app.InternalServer != nil {
+		if err := app.InternalServer.Shutdown(ctx); err != nil {
+			app.logger.Error("Internal server forced to shutdown", zap.Error(err))
+		} else {
+			app.logger.Info("Internal server stopped")
+		}
+	}
+
+	// 等待已提交的导出结束，导出会读取数据库并写入存储，需要在关闭连接之前完成
+	if app.ExportPool.Pool != nil {
+		if err := app.ExportPool.Shutdown(ctx); err != nil {
+			app.logger.Error("Export pool forced to shutdown", zap.Error(err))
+		} else {
+			app.logger.Info("Export pool stopped")
+		}
+	}
+
 	// 停止调度器
 	if app.Config.Scheduler.Enabled && app.JobRegistry != nil {
 		if err := app.JobRegistry.Stop(); err != nil {
@@ -145,7 +266,11 @@ func (app *App) Stop(ctx context.Context) error {
 		}
 	}
 
-	// 关闭数据库连接
+	// 停止降载监控
+	app.LoadShed.Stop()
+
+	// 停止连接池监控后关闭数据库连接
+	app.DBPool.Stop()
 	for name, db := range app.DataSources {
 		if sqlDB, err := db.DB(); err == nil {
 			if err := sqlDB.Close(); err != nil {
@@ -168,8 +293,23 @@ func (app *App) Stop(ctx context.Context) error {
 		}
 	}
 
-	// 关闭 RabbitMQ 连接
-	if app.RabbitMQ != nil && !app.RabbitMQ.IsClosed() {
+	// 关闭 MongoDB 连接
+	if app.Mongo != nil {
+		if err := app.Mongo.Close(ctx); err != nil {
+			app.logger.Error("Failed to close MongoDB connection", zap.Error(err))
+		} else {
+			app.logger.Info("MongoDB connection closed")
+		}
+	}
+
+	// 关闭 RabbitMQ 连接，default broker 的连接也由 Brokers 管理
+	if app.Brokers != nil {
+		if err := app.Brokers.Close(); err != nil {
+			app.logger.Error("Failed to close RabbitMQ connections", zap.Error(err))
+		} else {
+			app.logger.Info("RabbitMQ connections closed")
+		}
+	} else if app.RabbitMQ != nil && !app.RabbitMQ.IsClosed() {
 		if err := app.RabbitMQ.Close(); err != nil {
 			app.logger.Error("Failed to close RabbitMQ connection", zap.Error(err))
 		} else {
@@ -177,6 +317,22 @@ func (app *App) Stop(ctx context.Context) error {
 		}
 	}
 
+	// 关闭 NATS 连接
+	if app.JetStream != nil {
+		if err := app.JetStream.Close(); err != nil {
+			app.logger.Error("Failed to close NATS connection", zap.Error(err))
+		} else {
+			app.logger.Info("NATS connection closed")
+		}
+	}
+
+	// 上报缓冲中的 span，放在最后以包含关闭过程中的请求和消息
+	if err := app.Tracing.Shutdown(ctx); err != nil {
+		app.logger.Error("Failed to flush traces", zap.Error(err))
+	} else if app.Tracing != nil {
+		app.logger.Info("Tracer provider stopped")
+	}
+
 	// 同步日志
 	app.logger.Sync()
 
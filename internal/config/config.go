@@ -1,10 +1,18 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
 	"log"
+	"os"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/hedeqiang/skeleton/configs"
+
 	"github.com/spf13/viper"
 )
 
@@ -13,30 +21,145 @@ var C *Config
 
 // Config 是整个应用的配置结构体
 type Config struct {
-	App         App                 `mapstructure:"app"`
-	Logger      Logger              `mapstructure:"logger"`
-	Databases   map[string]Database `mapstructure:"databases"`
-	Redis       Redis               `mapstructure:"redis"`
-	RabbitMQ    RabbitMQ            `mapstructure:"rabbitmq"`
-	Scheduler   SchedulerConfig     `mapstructure:"scheduler"`
-	Trace       Trace               `mapstructure:"trace"`
-	JWT         JWT                 `mapstructure:"jwt"`
-	IDGenerator *IDGeneratorConfig  `mapstructure:"id_generator"`
+	App           App                 `mapstructure:"app"`
+	Logger        Logger              `mapstructure:"logger"`
+	Databases     map[string]Database `mapstructure:"databases"`
+	Redis         Redis               `mapstructure:"redis"`
+	Mongo         Mongo               `mapstructure:"mongo"`
+	Search        Search              `mapstructure:"search"`
+	RabbitMQ      RabbitMQ            `mapstructure:"rabbitmq"`
+	RedisStreams  RedisStreams        `mapstructure:"redis_streams"`
+	NATS          NATS                `mapstructure:"nats"`
+	Scheduler     SchedulerConfig     `mapstructure:"scheduler"`
+	Trace         Trace               `mapstructure:"trace"`
+	JWT           JWT                 `mapstructure:"jwt"`
+	Password      Password            `mapstructure:"password"`
+	IDGenerator   *IDGeneratorConfig  `mapstructure:"id_generator"`
+	QueryCounter  QueryCounter        `mapstructure:"query_counter"`
+	SlowRequest   SlowRequest         `mapstructure:"slow_request"`
+	HTTPMetrics   HTTPMetrics         `mapstructure:"http_metrics"`
+	SLO           SLO                 `mapstructure:"slo"`
+	DBPool        DBPool              `mapstructure:"db_pool"`
+	LoadShedding  LoadShedding        `mapstructure:"load_shedding"`
+	Faults        FaultInjection      `mapstructure:"fault_injection"`
+	OpenAPI       OpenAPIValidation   `mapstructure:"openapi_validation"`
+	Decompression Decompression       `mapstructure:"request_decompression"`
+	Problem       ProblemDetails      `mapstructure:"problem_details"`
+	Cache         Cache               `mapstructure:"cache"`
+	BloomFilter   BloomFilter         `mapstructure:"bloom_filter"`
+	Uniqueness    Uniqueness          `mapstructure:"uniqueness"`
+	Consumer      ConsumerConfig      `mapstructure:"consumer"`
+	Archive       MessageArchive      `mapstructure:"message_archive"`
+	Schemas       MessageSchemas      `mapstructure:"message_schemas"`
+	EventPublish  EventPublish        `mapstructure:"event_publish"`
+	Tasks         Tasks               `mapstructure:"tasks"`
+	Storage       Storage             `mapstructure:"storage"`
+	Upload        Upload              `mapstructure:"upload"`
+	Privacy       Privacy             `mapstructure:"privacy"`
+	EmailChange   EmailChange         `mapstructure:"email_change"`
+	PasswordReset PasswordReset       `mapstructure:"password_reset"`
+	Identities    Identities          `mapstructure:"identities"`
+	Retention     Retention           `mapstructure:"retention"`
+	Static        Static              `mapstructure:"static"`
+	Template      Template            `mapstructure:"template"`
+	I18n          I18n                `mapstructure:"i18n"`
+	Mailer        Mailer              `mapstructure:"mailer"`
+	Webhook       Webhook             `mapstructure:"webhook"`
+	Migration     Migration           `mapstructure:"migration"`
+	Auth          Auth                `mapstructure:"auth"`
+	Authorization Authorization       `mapstructure:"authorization"`
+	RoutePolicies RoutePolicies       `mapstructure:"route_policies"`
+	Deprecations  Deprecations        `mapstructure:"deprecations"`
+	Health        Health              `mapstructure:"health"`
+	Warmup        Warmup              `mapstructure:"warmup"`
+	CORS          CORS                `mapstructure:"cors"`
+	ProdGuard     ProdGuard           `mapstructure:"prod_guard"`
 }
 
 // App 应用配置
 type App struct {
-	Name string `mapstructure:"name"`
-	Env  string `mapstructure:"env"`
-	Host string `mapstructure:"host"`
-	Port int    `mapstructure:"port"`
+	Name         string `mapstructure:"name"`
+	Env          string `mapstructure:"env"`
+	Host         string `mapstructure:"host"`
+	Port         int    `mapstructure:"port"`
+	InternalPort int    `mapstructure:"internal_port"` // 内部端口，暴露健康检查、指标、pprof 和管理接口，为 0 时所有路由都在 port 上
+	Listen       Listen `mapstructure:"listen"`        // 公开端口的监听方式，默认监听 host:port
+}
+
+// 公开端口的监听方式
+const (
+	ListenNetworkTCP     = "tcp"
+	ListenNetworkUnix    = "unix"
+	ListenNetworkSystemd = "systemd"
+)
+
+// Listen 公开端口的监听配置，unix、systemd 用于由 sidecar 或反向代理转发请求的部署，内部端口始终监听 TCP
+type Listen struct {
+	Network    string `mapstructure:"network"`     // tcp (默认)、unix、systemd
+	SocketPath string `mapstructure:"socket_path"` // network 为 unix 时的 socket 文件路径
+	SocketMode string `mapstructure:"socket_mode"` // socket 文件权限，八进制，如 "0660"，为空时由 umask 决定
+	FDName     string `mapstructure:"fd_name"`     // network 为 systemd 时 .socket 单元的 FileDescriptorName，为空时使用第一个 socket
+}
+
+// FileMode 解析 socket_mode，为空时返回 0
+func (l Listen) FileMode() (os.FileMode, error) {
+	if l.SocketMode == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(l.SocketMode, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, fmt.Errorf("invalid app.listen.socket_mode %q, expected octal permissions like \"0660\"", l.SocketMode)
+	}
+	return os.FileMode(mode), nil
+}
+
+// SplitListeners 是否启用内部端口，启用后公开端口只暴露业务接口
+func (a App) SplitListeners() bool {
+	return a.InternalPort > 0
+}
+
+// IsDevelopment 是否为开发环境
+func (a App) IsDevelopment() bool {
+	return a.Env == "dev" || a.Env == "development"
+}
+
+// GinMode 根据运行环境返回 Gin 模式，开发环境为 debug，测试环境为 test，其他为 release
+func (a App) GinMode() string {
+	switch {
+	case a.IsDevelopment():
+		return "debug"
+	case a.Env == "test" || a.Env == "testing":
+		return "test"
+	default:
+		return "release"
+	}
 }
 
 // Logger 日志配置
 type Logger struct {
-	Level      string   `mapstructure:"level"`
-	Encoding   string   `mapstructure:"encoding"`
-	OutputPath []string `mapstructure:"output_path"`
+	Level       string            `mapstructure:"level"`
+	Levels      map[string]string `mapstructure:"levels"`       // 按 logger 名称单独设置的级别，如 mq、gorm、scheduler，未设置的 logger 使用 level
+	ToggleDebug []string          `mapstructure:"toggle_debug"` // SIGUSR2 只切换这些 logger 的 debug 级别，为空时切换全局级别
+	Encoding    string            `mapstructure:"encoding"`
+	OutputPath  []string          `mapstructure:"output_path"`
+	Service     LogService        `mapstructure:"service"`
+	Dedup       LogDedup          `mapstructure:"dedup"`
+}
+
+// LogDedup 重复日志抑制配置，同一 logger、同一级别、同一消息在窗口内只输出第一条，
+// 窗口结束后输出一条带 repeated 字段的汇总；修改后需重启生效
+type LogDedup struct {
+	Enabled bool                     `mapstructure:"enabled"`
+	Windows map[string]time.Duration `mapstructure:"windows"`  // 按级别设置的去重窗口，如 warn: 10s，未设置的级别不去重
+	MaxKeys int                      `mapstructure:"max_keys"` // 同时跟踪的不同消息数上限，超出后新消息不去重，默认 1000
+}
+
+// LogService 写入每条日志的服务信息，json 和 ecs 编码时生效
+// 未配置时 name、env 使用 app 配置，version 使用构建版本号
+type LogService struct {
+	Name    string `mapstructure:"name"`
+	Env     string `mapstructure:"env"`
+	Version string `mapstructure:"version"`
 }
 
 // Database 单个数据源的配置
@@ -46,8 +169,29 @@ type Database struct {
 	MaxOpenConns    int           `mapstructure:"max_open_conns"`
 	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
 	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+
+	// PrepareStmt 开启后 GORM 会缓存预编译语句
+	PrepareStmt bool `mapstructure:"prepare_stmt"`
+	// SkipDefaultTransaction 跳过 GORM 为单条写操作默认开启的事务
+	SkipDefaultTransaction bool `mapstructure:"skip_default_transaction"`
+	// ConnectionMode 连接模式: direct(默认) 或 pgbouncer（事务级连接池，不支持预编译语句）
+	ConnectionMode string `mapstructure:"connection_mode"`
+	// AutoMigrateOnStart 启动时持有咨询锁执行待执行的版本化迁移，适用于没有独立迁移步骤的部署
+	AutoMigrateOnStart bool `mapstructure:"auto_migrate_on_start"`
+	// QueryTimeout 单条 SQL 的默认执行超时，ctx 中已有更早的截止时间时以 ctx 为准，
+	// 可按调用通过 database.WithQueryTimeout 覆盖；0 表示不限制
+	QueryTimeout time.Duration `mapstructure:"query_timeout"`
+	// StatementTimeout 仅 postgres：建立连接时设置服务端 statement_timeout，客户端取消失效时由服务端兜底终止查询，
+	// 不能按调用覆盖，应不小于 WithQueryTimeout 设置的最长超时；0 表示使用服务端默认值
+	StatementTimeout time.Duration `mapstructure:"statement_timeout"`
 }
 
+// 数据库连接模式
+const (
+	ConnectionModeDirect    = "direct"
+	ConnectionModePgBouncer = "pgbouncer"
+)
+
 // Redis 配置
 type Redis struct {
 	Addr     string `mapstructure:"addr"`
@@ -55,11 +199,509 @@ type Redis struct {
 	DB       int    `mapstructure:"db"`
 }
 
+// Mongo MongoDB 配置，用于日志、灵活元数据等文档型数据
+type Mongo struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	URI             string        `mapstructure:"uri"`
+	Database        string        `mapstructure:"database"`
+	Username        string        `mapstructure:"username"` // 为空时使用 URI 中的认证信息
+	Password        string        `mapstructure:"password"`
+	AuthSource      string        `mapstructure:"auth_source"`        // 认证数据库，为空使用 admin
+	MinPoolSize     uint64        `mapstructure:"min_pool_size"`      // 连接池保持的最少连接数
+	MaxPoolSize     uint64        `mapstructure:"max_pool_size"`      // 连接池最大连接数，0 使用驱动默认值 100
+	MaxConnIdleTime time.Duration `mapstructure:"max_conn_idle_time"` // 空闲连接的最长保留时间
+	ConnectTimeout  time.Duration `mapstructure:"connect_timeout"`    // 建立连接和启动时 Ping 的超时时间
+	Timeout         time.Duration `mapstructure:"timeout"`            // 单次操作的默认超时，ctx 中已有截止时间时以 ctx 为准
+}
+
+// Search 搜索引擎配置，启用后用户数据通过领域事件同步到索引并提供全文搜索
+type Search struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	Driver             string        `mapstructure:"driver"`    // 搜索驱动，目前支持 opensearch（兼容 Elasticsearch 7.x API）
+	Addresses          []string      `mapstructure:"addresses"` // 节点地址，请求失败时依次尝试下一个
+	Username           string        `mapstructure:"username"`
+	Password           string        `mapstructure:"password"`
+	IndexPrefix        string        `mapstructure:"index_prefix"`         // 索引别名前缀，多个应用共用集群时区分索引
+	Timeout            time.Duration `mapstructure:"timeout"`              // 单次请求超时
+	InsecureSkipVerify bool          `mapstructure:"insecure_skip_verify"` // 跳过 TLS 证书校验，仅用于自签名证书的测试集群
+	EventsExchange     string        `mapstructure:"events_exchange"`      // 用户领域事件发布的交换机，索引消费者从该交换机订阅
+	ReindexBatchSize   int           `mapstructure:"reindex_batch_size"`   // 重建索引时每批读取和写入的文档数
+	ReindexRate        int           `mapstructure:"reindex_rate"`         // 重建索引每秒最多写入的文档数，0 表示不限制
+}
+
+// RedisStreams Redis Streams 消息驱动配置，driver 为 redis 的队列使用 redis 配置的连接
+type RedisStreams struct {
+	Prefix        string        `mapstructure:"prefix"`         // stream key 前缀，队列 stream 为 <prefix>:<队列名>
+	MaxLen        int64         `mapstructure:"max_len"`        // 每个 stream 保留的大致消息数，0 表示不裁剪
+	BatchSize     int64         `mapstructure:"batch_size"`     // 每次读取或认领的消息数量
+	Block         time.Duration `mapstructure:"block"`          // 没有新消息时 XREADGROUP 的阻塞时长
+	ClaimIdle     time.Duration `mapstructure:"claim_idle"`     // 已投递未确认超过该时长的消息由其他消费者认领重新处理
+	MaxDeliveries int64         `mapstructure:"max_deliveries"` // 最大投递次数，超过后转入死信 stream，0 表示不限制
+}
+
+// NATS NATS JetStream 消息驱动配置，driver 为 nats 的队列使用
+// 所有消息写入同一个 stream，subject 为 <subject_prefix>.<交换机>.<路由键>
+type NATS struct {
+	URL           string        `mapstructure:"url"`
+	Name          string        `mapstructure:"name"`           // 连接名称，为空使用应用名称
+	CredsFile     string        `mapstructure:"creds_file"`     // 用户凭证文件，为空时使用 URL 中的认证信息
+	Stream        string        `mapstructure:"stream"`         // JetStream stream 名称，启动时自动创建或更新
+	SubjectPrefix string        `mapstructure:"subject_prefix"` // subject 前缀，stream 订阅 <subject_prefix>.>
+	MaxAge        time.Duration `mapstructure:"max_age"`        // stream 中消息的保留时长，0 表示不限制
+	Replicas      int           `mapstructure:"replicas"`       // stream 副本数，集群部署时配置
+	AckWait       time.Duration `mapstructure:"ack_wait"`       // 投递后未确认超过该时长由服务端重新投递
+	MaxDeliver    int           `mapstructure:"max_deliver"`    // 最大投递次数，最后一次仍失败时转入死信 subject，0 表示不限制
+	BatchSize     int           `mapstructure:"batch_size"`     // 每次拉取的消息数量
+	FetchWait     time.Duration `mapstructure:"fetch_wait"`     // 没有新消息时单次拉取的等待时长
+}
+
+// 队列驱动
+const (
+	DriverRabbitMQ = "rabbitmq"
+	DriverRedis    = "redis"
+	DriverNATS     = "nats"
+)
+
+// DefaultBroker 未指定 broker 的交换机和队列使用的连接名称
+const DefaultBroker = "default"
+
 // RabbitMQ 配置
 type RabbitMQ struct {
-	URL       string           `mapstructure:"url"`
-	Exchanges []ExchangeConfig `mapstructure:"exchanges"`
-	Queues    []QueueConfig    `mapstructure:"queues"`
+	URL       string            `mapstructure:"url"`       // default broker 的地址，brokers 中配置了 default 时忽略
+	Heartbeat time.Duration     `mapstructure:"heartbeat"` // default broker 的心跳间隔
+	TLS       BrokerTLS         `mapstructure:"tls"`       // default broker 的 TLS 配置
+	Brokers   map[string]Broker `mapstructure:"brokers"`   // 命名 broker 连接，用于连接不同集群或 vhost
+	Exchanges []ExchangeConfig  `mapstructure:"exchanges"`
+	Queues    []QueueConfig     `mapstructure:"queues"`
+	Async     AsyncPublish      `mapstructure:"async_publish"` // mq.AsyncPublisher 的缓冲和批量发布配置
+}
+
+// AsyncPublish 异步批量发布配置，用于遥测等允许少量丢失、吞吐优先的生产者
+// 缓冲达到 batch_size 或距上次发布达到 flush_interval 时整批发布，以先到者为准
+type AsyncPublish struct {
+	BufferSize    int           `mapstructure:"buffer_size"`    // 待发布缓冲长度，缓冲满时 Publish 返回错误，默认 10000
+	BatchSize     int           `mapstructure:"batch_size"`     // 每批最多发布的消息数，默认 100
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // 最长发布间隔，默认 1s
+	Timeout       time.Duration `mapstructure:"timeout"`        // 单批发布等待确认的超时时间，默认 10s
+}
+
+// Broker 单个 RabbitMQ 集群或 vhost 的连接配置
+type Broker struct {
+	URL         string        `mapstructure:"url"`
+	Heartbeat   time.Duration `mapstructure:"heartbeat"`    // 心跳间隔，0 使用默认值 10s
+	DialTimeout time.Duration `mapstructure:"dial_timeout"` // 建立连接超时，0 使用默认值 30s
+	TLS         BrokerTLS     `mapstructure:"tls"`
+}
+
+// BrokerTLS RabbitMQ TLS 配置，启用时 URL 需使用 amqps://
+type BrokerTLS struct {
+	Enabled            bool   `mapstructure:"enabled"`
+	CAFile             string `mapstructure:"ca_file"`              // CA 证书，为空使用系统根证书
+	CertFile           string `mapstructure:"cert_file"`            // 客户端证书，用于双向认证
+	KeyFile            string `mapstructure:"key_file"`             // 客户端私钥
+	ServerName         string `mapstructure:"server_name"`          // 校验的服务端名称，为空使用 URL 中的主机名
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify"` // 跳过证书校验，仅用于测试环境
+}
+
+// BrokerConfigs 返回所有命名 broker 的配置，顶层 url 作为 default broker
+func (r RabbitMQ) BrokerConfigs() map[string]Broker {
+	brokers := make(map[string]Broker, len(r.Brokers)+1)
+	for name, broker := range r.Brokers {
+		brokers[name] = broker
+	}
+	if _, ok := brokers[DefaultBroker]; !ok && r.URL != "" {
+		brokers[DefaultBroker] = Broker{URL: r.URL, Heartbeat: r.Heartbeat, TLS: r.TLS}
+	}
+	return brokers
+}
+
+// ExchangeDriver 返回交换机所属驱动，绑定了 redis 或 nats 队列的交换机由对应驱动路由
+func (r RabbitMQ) ExchangeDriver(exchange string) string {
+	for _, queue := range r.Queues {
+		if queue.Exchange == exchange && queue.DriverName() != DriverRabbitMQ {
+			return queue.DriverName()
+		}
+	}
+	return DriverRabbitMQ
+}
+
+// UsesDriver 判断是否有队列使用指定驱动
+func (r RabbitMQ) UsesDriver(driver string) bool {
+	for _, queue := range r.Queues {
+		if queue.DriverName() == driver {
+			return true
+		}
+	}
+	return false
+}
+
+// Tasks 后台任务配置
+type Tasks struct {
+	TTL         time.Duration `mapstructure:"ttl"`          // 任务记录保留时长，过期后由清理任务删除
+	DownloadTTL time.Duration `mapstructure:"download_ttl"` // 查询任务时签发的结果下载地址有效期
+}
+
+// Webhook 出站 Webhook 投递配置，失败的投递由 webhook_relay_job 按指数退避重试
+type Webhook struct {
+	Timeout     time.Duration `mapstructure:"timeout"`      // 单次投递的请求超时
+	MaxAttempts int           `mapstructure:"max_attempts"` // 最大投递次数，达到后标记为 dead 不再重试
+	BatchSize   int           `mapstructure:"batch_size"`   // 每次扫描处理的投递数量
+	BackoffBase time.Duration `mapstructure:"backoff_base"` // 首次重试间隔，之后每次翻倍
+	BackoffMax  time.Duration `mapstructure:"backoff_max"`  // 重试间隔上限
+	Concurrency int           `mapstructure:"concurrency"`  // 每批记录的并发投递数，默认 4
+}
+
+// Storage 对象存储配置
+type Storage struct {
+	Driver string       `mapstructure:"driver"` // 存储驱动: local
+	Local  LocalStorage `mapstructure:"local"`
+
+	// SigningSecret 签名下载地址的 HMAC 密钥，为空时不提供签名下载
+	SigningSecret string `mapstructure:"signing_secret"`
+}
+
+// LocalStorage 本地文件系统存储配置
+type LocalStorage struct {
+	Root string `mapstructure:"root"` // 存储根目录
+}
+
+// Upload 分片上传配置
+type Upload struct {
+	ChunkSize          int64         `mapstructure:"chunk_size"`            // 分片大小（字节）
+	MaxSize            int64         `mapstructure:"max_size"`              // 单个文件最大大小（字节），0 表示不限制
+	SessionTTL         time.Duration `mapstructure:"session_ttl"`           // 上传会话空闲过期时间
+	MaxSessionsPerUser int           `mapstructure:"max_sessions_per_user"` // 同一用户未完成的会话数上限，0 表示不限制
+	MaxBytesPerUser    int64         `mapstructure:"max_bytes_per_user"`    // 同一用户未完成的会话声明的总大小上限（字节），0 表示不限制
+	FileTTL            time.Duration `mapstructure:"file_ttl"`              // 合并后文件的保留时长，过期后由清理任务删除，默认 7 天
+}
+
+// Privacy 用户数据导出与账户删除配置
+type Privacy struct {
+	ExportTTL           time.Duration `mapstructure:"export_ttl"`            // 导出文件保留时长，过期后由清理任务删除；下载地址在查询任务时签发
+	DeletionGracePeriod time.Duration `mapstructure:"deletion_grace_period"` // 申请删除到彻底删除的宽限期，期间可以撤销
+	ExportWorkers       int           `mapstructure:"export_workers"`        // 同时执行的导出数，默认 2
+	ExportQueueSize     int           `mapstructure:"export_queue_size"`     // 等待执行的导出数上限，队列满时申请导出的请求等待至超时，默认 16
+	ExportPerUser       int           `mapstructure:"export_per_user"`       // 同一用户排队和执行中的导出数上限，超出时返回 429，默认 2
+}
+
+// EmailChange 修改邮箱确认配置
+type EmailChange struct {
+	TokenTTL   time.Duration `mapstructure:"token_ttl"`   // 确认令牌有效期，过期后需要重新发起修改
+	ConfirmURL string        `mapstructure:"confirm_url"` // 确认邮件中的链接，附加 user_id 和 token 查询参数；为空时邮件只包含令牌
+}
+
+// PasswordReset 管理员强制重置密码配置
+type PasswordReset struct {
+	TokenTTL time.Duration `mapstructure:"token_ttl"` // 重置令牌有效期，过期后需要管理员重新发起
+	ResetURL string        `mapstructure:"reset_url"` // 重置邮件中的链接，附加 user_id 和 token 查询参数；为空时邮件只包含令牌
+}
+
+// Identities 外部账号关联配置
+type Identities struct {
+	// TokenEncryptionKey 加密外部账号访问令牌和刷新令牌的密钥，base64 编码的 32 字节，可通过 IDENTITIES_TOKEN_ENCRYPTION_KEY 环境变量设置
+	// 为空时不保存外部令牌，只记录关联关系
+	TokenEncryptionKey string `mapstructure:"token_encryption_key"`
+}
+
+// 保留策略到期数据的处理方式
+const (
+	RetentionActionDelete  = "delete"  // 彻底删除
+	RetentionActionArchive = "archive" // 复制到归档表后删除
+)
+
+// Retention 数据保留配置，retention_job 按策略分批删除或归档过期数据
+type Retention struct {
+	BatchSize int                        `mapstructure:"batch_size"` // 每批处理的行数，默认 1000
+	DryRun    bool                       `mapstructure:"dry_run"`    // 只统计到期的行数，不删除
+	Policies  map[string]RetentionPolicy `mapstructure:"policies"`   // 键为策略名称，用于日志和指标
+}
+
+// RetentionPolicy 单张表的保留策略，时间列早于当前时间减去 after 的行到期
+type RetentionPolicy struct {
+	Disabled     bool          `mapstructure:"disabled"`
+	DataSource   string        `mapstructure:"data_source"`   // databases 中的数据源名称，默认 primary
+	Table        string        `mapstructure:"table"`         // 表名
+	Column       string        `mapstructure:"column"`        // 判断到期的时间列，例如软删除的 deleted_at，为 NULL 的行不会到期
+	KeyColumn    string        `mapstructure:"key_column"`    // 分批使用的主键列，默认 id
+	After        time.Duration `mapstructure:"after"`         // 保留时长
+	Action       string        `mapstructure:"action"`        // delete 或 archive，默认 delete
+	ArchiveTable string        `mapstructure:"archive_table"` // archive 时写入的表，列与原表一致
+}
+
+// Static 静态文件与 SPA 托管配置
+type Static struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Mounts  []StaticMount `mapstructure:"mounts"`
+	SPA     SPA           `mapstructure:"spa"`
+}
+
+// StaticMount 静态目录挂载
+type StaticMount struct {
+	Prefix string        `mapstructure:"prefix"`  // URL 前缀，例如 /assets
+	Dir    string        `mapstructure:"dir"`     // 本地目录
+	MaxAge time.Duration `mapstructure:"max_age"` // Cache-Control max-age，0 表示 no-cache
+}
+
+// SPA 单页应用回退配置
+type SPA struct {
+	Enabled         bool     `mapstructure:"enabled"`
+	Root            string   `mapstructure:"root"`             // 前端构建产物目录
+	Index           string   `mapstructure:"index"`            // 入口文件，相对于 root
+	ExcludePrefixes []string `mapstructure:"exclude_prefixes"` // 不回退到 index 的路径前缀，例如 /api
+}
+
+// Template HTML 模板配置
+type Template struct {
+	Dir    string `mapstructure:"dir"`    // 模板目录，为空时使用内置模板
+	Reload bool   `mapstructure:"reload"` // 每次渲染重新解析模板，仅用于开发环境
+}
+
+// I18n 多语言配置
+type I18n struct {
+	Enabled         bool   `mapstructure:"enabled"`          // 是否按请求语言翻译错误消息
+	DefaultLanguage string `mapstructure:"default_language"` // 请求语言无法匹配时使用的语言
+	Dir             string `mapstructure:"dir"`              // 消息文件目录，为空时使用内置消息文件
+	// SupportedLanguages 可匹配的语言，为空时为全部消息文件的语言；默认语言始终支持
+	SupportedLanguages []string `mapstructure:"supported_languages"`
+	// Fallbacks 语言的回退链，请求语言不受支持或缺少消息时依次使用，如 zh-TW: [zh-HK, zh]
+	Fallbacks map[string][]string `mapstructure:"fallbacks"`
+}
+
+// Mailer 邮件发送配置
+type Mailer struct {
+	Enabled  bool   `mapstructure:"enabled"` // 未启用时只记录日志不实际发送
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+	FromName string `mapstructure:"from_name"`
+}
+
+// Migration 数据库迁移配置
+type Migration struct {
+	RequireApplied bool `mapstructure:"require_applied"` // 存在待执行迁移时 /ready 返回 503
+}
+
+// Auth 路由组认证配置，protected 中的路由必须携带有效的 Bearer Token，skip_paths 中的路由除外
+// 与 route_policies 的 auth 相互独立：这里按路由组统一要求登录，角色、限流等仍由 route_policies 按路由声明
+type Auth struct {
+	Enabled   bool        `mapstructure:"enabled"`
+	Protected []AuthRoute `mapstructure:"protected"`  // 需要认证的路由组
+	SkipPaths []AuthRoute `mapstructure:"skip_paths"` // 受保护路由组中无需认证的路由，优先于 protected
+	// RevocationFailOpen Redis 不可用、无法检查令牌是否已吊销时放行，默认关闭：返回 503，
+	// 避免被禁用、强制重置密码或收回角色的用户在故障期间继续访问
+	RevocationFailOpen bool `mapstructure:"revocation_fail_open"`
+}
+
+// AuthRoute 认证中间件匹配的路由
+type AuthRoute struct {
+	Path    string   `mapstructure:"path"`    // Gin 路由模板，以 /* 结尾时按前缀匹配
+	Methods []string `mapstructure:"methods"` // 为空时匹配所有方法
+}
+
+// Authorization 资源级授权配置，命中规则的请求按路由参数中的资源 ID 调用 internal/policy 中注册的策略
+// 规则按顺序匹配，第一条匹配的规则生效；命中规则的路由隐含认证
+type Authorization struct {
+	Enabled    bool                `mapstructure:"enabled"`
+	AdminRoles []string            `mapstructure:"admin_roles"` // 视为管理员的角色，所有者策略对其放行，默认 ["admin"]
+	Rules      []AuthorizationRule `mapstructure:"rules"`
+}
+
+// AuthorizationRule 路由与授权策略的映射
+type AuthorizationRule struct {
+	Path     string   `mapstructure:"path"`     // Gin 路由模板，以 /* 结尾时按前缀匹配
+	Methods  []string `mapstructure:"methods"`  // 为空时匹配所有方法
+	Resource string   `mapstructure:"resource"` // 资源名称，对应注册的策略，例如 user
+	Action   string   `mapstructure:"action"`   // view 或 edit
+	Param    string   `mapstructure:"param"`    // 资源 ID 所在的路由参数，默认 id
+}
+
+// RoutePolicies 路由级策略配置，集中声明各路由的认证、角色、限流、并发、缓存和超时
+type RoutePolicies struct {
+	Enabled           bool                         `mapstructure:"enabled"`
+	RateLimits        map[string]RateLimitPolicy   `mapstructure:"rate_limits"`        // 命名限流策略，供 rules 引用
+	ConcurrencyLimits map[string]ConcurrencyPolicy `mapstructure:"concurrency_limits"` // 命名并发限制策略，供 rules 引用
+	Rules             []RoutePolicy                `mapstructure:"rules"`              // 按顺序匹配，第一条匹配的规则生效
+	AdminRoles        []string                     `mapstructure:"admin_roles"`        // 不受 owner 限制的角色
+}
+
+// RoutePolicy 单条路由策略
+type RoutePolicy struct {
+	Path        string        `mapstructure:"path"`        // Gin 路由模板，以 /* 结尾时按前缀匹配
+	Methods     []string      `mapstructure:"methods"`     // 为空时匹配所有方法
+	Auth        bool          `mapstructure:"auth"`        // 是否需要携带有效的 Bearer Token
+	Roles       []string      `mapstructure:"roles"`       // 允许访问的角色，满足任一即可，隐含 auth
+	Owner       string        `mapstructure:"owner"`       // 保存用户 ID 的路由参数名，只允许该用户本人或 admin_roles 访问，隐含 auth
+	RateLimit   string        `mapstructure:"rate_limit"`  // 引用 rate_limits 中的策略名
+	Concurrency string        `mapstructure:"concurrency"` // 引用 concurrency_limits 中的策略名
+	CacheTTL    time.Duration `mapstructure:"cache_ttl"`   // GET 响应缓存时间，需要开启 cache
+	Timeout     time.Duration `mapstructure:"timeout"`     // 请求处理超时时间
+}
+
+// RateLimitPolicy 限流策略
+type RateLimitPolicy struct {
+	Requests int           `mapstructure:"requests"` // 窗口内允许的请求数
+	Window   time.Duration `mapstructure:"window"`   // 窗口大小
+	Key      string        `mapstructure:"key"`      // 限流维度: ip (默认) 或 user
+}
+
+// 并发限制维度
+const (
+	ConcurrencyKeyRoute  = "route"   // 整条路由共享槽位，占满时返回 503
+	ConcurrencyKeyUser   = "user"    // 按登录用户，未登录时按 IP
+	ConcurrencyKeyIP     = "ip"      // 按客户端 IP
+	ConcurrencyKeyAPIKey = "api_key" // 按 api_key_header 请求头的值，未携带时按 IP
+)
+
+// ConcurrencyPolicy 并发限制策略，按实例在进程内计数
+type ConcurrencyPolicy struct {
+	Limit        int           `mapstructure:"limit"`          // 同一 key 允许同时处理的请求数
+	Key          string        `mapstructure:"key"`            // 并发维度: route (默认)、user、ip 或 api_key，除 route 外占满时返回 429
+	QueueTimeout time.Duration `mapstructure:"queue_timeout"`  // 槽位占满时的最长排队时间，为 0 时立即拒绝
+	APIKeyHeader string        `mapstructure:"api_key_header"` // key 为 api_key 时读取的请求头，默认 X-API-Key
+}
+
+// Deprecations 废弃接口配置，命中的路由在响应中携带 Deprecation、Sunset、Link 头，并按客户端统计调用次数
+type Deprecations struct {
+	Enabled      bool              `mapstructure:"enabled"`
+	ClientHeader string            `mapstructure:"client_header"` // 标识调用方的请求头，默认 X-Client-Name
+	Routes       []DeprecatedRoute `mapstructure:"routes"`        // 按顺序匹配，第一条匹配的规则生效
+}
+
+// DeprecatedRoute 单个废弃接口
+type DeprecatedRoute struct {
+	Path    string   `mapstructure:"path"`    // Gin 路由模板，以 /* 结尾时按前缀匹配
+	Methods []string `mapstructure:"methods"` // 为空时匹配所有方法
+	Since   string   `mapstructure:"since"`   // 废弃日期 (2006-01-02)，为空时 Deprecation 头为 true
+	Sunset  string   `mapstructure:"sunset"`  // 计划下线日期 (2006-01-02)，为空时不输出 Sunset 头
+	Link    string   `mapstructure:"link"`    // 迁移说明或替代接口的文档地址
+}
+
+// ConsumerConfig 消息消费者配置
+type ConsumerConfig struct {
+	Workers           int             `mapstructure:"workers"`            // 并发处理消息的 worker 数量
+	QueueSize         int             `mapstructure:"queue_size"`         // 工作池等待队列长度
+	ProcessingTimeout time.Duration   `mapstructure:"processing_timeout"` // 单条消息的处理超时，超时后消息重新入队，0 表示不限制
+	FailurePolicies   []FailurePolicy `mapstructure:"failure_policies"`   // 按消息类型配置处理失败后的去向，未配置的类型为 retry
+
+	// Queues 本实例消费的队列，为空时消费全部未设置 no_consume 的队列，用于将实例专门分配给某类负载
+	Queues []ConsumerQueue `mapstructure:"queues"`
+	// DisabledProcessors 不注册处理器的消息类型，本实例收到这些类型的消息时按未知类型确认丢弃，
+	// 应与 Queues 配合使用，确保这些消息由其他实例消费
+	DisabledProcessors []string `mapstructure:"disabled_processors"`
+}
+
+// ConsumerQueue 本实例消费的队列
+type ConsumerQueue struct {
+	Name      string `mapstructure:"name"`
+	Workers   int    `mapstructure:"workers"`    // 大于 0 时使用独立的工作池，不与其他队列争用 worker；0 使用共享工作池
+	QueueSize int    `mapstructure:"queue_size"` // 独立工作池的等待队列长度
+}
+
+// ConsumedQueues 从 queues 中选出本实例消费的队列，按 consumer.queues 的顺序返回
+// consumer.queues 引用了不存在或设置了 no_consume 的队列时返回错误
+func (c ConsumerConfig) ConsumedQueues(queues []QueueConfig) ([]QueueConfig, error) {
+	if len(c.Queues) == 0 {
+		var consumed []QueueConfig
+		for _, queue := range queues {
+			if !queue.NoConsume {
+				consumed = append(consumed, queue)
+			}
+		}
+		return consumed, nil
+	}
+
+	byName := make(map[string]QueueConfig, len(queues))
+	for _, queue := range queues {
+		byName[queue.Name] = queue
+	}
+	consumed := make([]QueueConfig, 0, len(c.Queues))
+	seen := make(map[string]bool, len(c.Queues))
+	for _, selected := range c.Queues {
+		queue, ok := byName[selected.Name]
+		switch {
+		case !ok:
+			return nil, fmt.Errorf("consumer.queues: queue %q is not configured in rabbitmq.queues", selected.Name)
+		case queue.NoConsume:
+			return nil, fmt.Errorf("consumer.queues: queue %q is marked no_consume", selected.Name)
+		case seen[selected.Name]:
+			return nil, fmt.Errorf("consumer.queues: queue %q is listed more than once", selected.Name)
+		}
+		seen[selected.Name] = true
+		consumed = append(consumed, queue)
+	}
+	return consumed, nil
+}
+
+// 消息处理失败策略
+const (
+	FailurePolicyRetry      = "retry"       // 重新入队，不可重试的错误仍转入死信队列
+	FailurePolicyDrop       = "drop"        // 记录日志后确认丢弃，用于尽力而为的消息
+	FailurePolicyDeadLetter = "dead_letter" // 不重试，直接转入队列的死信交换机
+	FailurePolicyPark       = "park"        // 转发到停放交换机，等待人工处理后重放
+)
+
+// FailurePolicy 一种消息类型处理失败时的策略
+type FailurePolicy struct {
+	MessageType string `mapstructure:"message_type"`
+	Policy      string `mapstructure:"policy"`      // retry、drop、dead_letter、park
+	Exchange    string `mapstructure:"exchange"`    // park 策略的停放交换机
+	RoutingKey  string `mapstructure:"routing_key"` // park 策略的路由键，为空时沿用消息原路由键
+}
+
+// 消息归档存储
+const (
+	ArchiveSinkDatabase = "database" // 写入主库 message_archive 表
+	ArchiveSinkStorage  = "storage"  // 以 JSON Lines 文件写入对象存储
+)
+
+// MessageArchive 消费消息归档配置，开启后消费者处理的每条消息连同处理结果批量写入归档
+type MessageArchive struct {
+	Enabled       bool          `mapstructure:"enabled"`
+	Sink          string        `mapstructure:"sink"`           // database 或 storage，默认 database
+	Prefix        string        `mapstructure:"prefix"`         // storage 归档的对象前缀
+	BatchSize     int           `mapstructure:"batch_size"`     // 每批写入的消息数量
+	FlushInterval time.Duration `mapstructure:"flush_interval"` // 未攒满一批时的最长写入间隔
+	BufferSize    int           `mapstructure:"buffer_size"`    // 待写入缓冲长度，缓冲满时丢弃新消息，不阻塞消费
+	Retention     time.Duration `mapstructure:"retention"`      // 归档保留时长，过期后由 message_archive_cleanup_job 删除，0 表示不删除
+}
+
+// MessageSchemas 消息载荷 JSON Schema 配置，开启后发布事件和消费消息时按消息类型和信封版本校验载荷
+type MessageSchemas struct {
+	Enabled  bool                  `mapstructure:"enabled"`
+	Dir      string                `mapstructure:"dir"`      // schema 目录，结构为 <message_type>/v<version>.json，为空时使用内置 schema
+	Registry MessageSchemaRegistry `mapstructure:"registry"` // skeleton mq schemas sync 推送的目标
+}
+
+// MessageSchemaRegistry Confluent Schema Registry 兼容的 schema 服务
+type MessageSchemaRegistry struct {
+	URL     string        `mapstructure:"url"`
+	Timeout time.Duration `mapstructure:"timeout"` // 单次请求超时，默认 10s
+}
+
+// EventPublish 通用事件发布接口配置 (POST /api/v1/events/publish)，供内部工具注入事件
+// 只允许发布 types 中声明的事件类型，载荷按 message_schemas 校验，调用方必须登录
+type EventPublish struct {
+	Enabled bool               `mapstructure:"enabled"`
+	Types   []PublishableEvent `mapstructure:"types"`
+}
+
+// PublishableEvent 允许通过通用接口发布的事件类型
+type PublishableEvent struct {
+	Type       string   `mapstructure:"type"`        // 信封中的消息类型
+	Exchange   string   `mapstructure:"exchange"`    // 发布到的交换机
+	RoutingKey string   `mapstructure:"routing_key"` // 路由键，默认与 type 相同
+	Roles      []string `mapstructure:"roles"`       // 允许发布的角色，满足任一即可，为空时登录用户均可发布
+	RateLimit  string   `mapstructure:"rate_limit"`  // 引用 route_policies.rate_limits 中的策略名，按用户和事件类型计数
+}
+
+// RoutingKeyOrType 返回路由键，未配置时使用事件类型
+func (e PublishableEvent) RoutingKeyOrType() string {
+	if e.RoutingKey != "" {
+		return e.RoutingKey
+	}
+	return e.Type
 }
 
 // ExchangeConfig 交换机配置
@@ -68,6 +710,12 @@ type ExchangeConfig struct {
 	Type       string `mapstructure:"type"`
 	Durable    bool   `mapstructure:"durable"`
 	AutoDelete bool   `mapstructure:"auto_delete"`
+	Broker     string `mapstructure:"broker"` // 所属 broker，为空使用 default
+}
+
+// BrokerName 返回交换机所属 broker
+func (e ExchangeConfig) BrokerName() string {
+	return brokerName(e.Broker)
 }
 
 // QueueConfig 队列配置
@@ -78,35 +726,161 @@ type QueueConfig struct {
 	Exclusive   bool     `mapstructure:"exclusive"`
 	Exchange    string   `mapstructure:"exchange"`
 	RoutingKeys []string `mapstructure:"routing_keys"`
+	Broker      string   `mapstructure:"broker"` // 所属 broker，为空使用 default
+	Driver      string   `mapstructure:"driver"` // rabbitmq（默认）、redis 或 nats，非 rabbitmq 时忽略 broker 和队列参数
+
+	DeadLetterExchange   string `mapstructure:"dead_letter_exchange"`    // 死信交换机，校验失败等不可重试的消息转发到这里
+	DeadLetterRoutingKey string `mapstructure:"dead_letter_routing_key"` // 死信路由键，为空时沿用消息原路由键
+	NoConsume            bool   `mapstructure:"no_consume"`              // 只声明不消费，用于死信队列
+
+	MessageTTL     time.Duration `mapstructure:"message_ttl"`      // 消息在队列中的最长存活时间，0 表示不限制
+	MaxLength      int           `mapstructure:"max_length"`       // 队列最大消息数，0 表示不限制
+	MaxLengthBytes int64         `mapstructure:"max_length_bytes"` // 队列消息总字节数上限，0 表示不限制
+	Overflow       string        `mapstructure:"overflow"`         // 超出上限时的行为：drop-head（默认）、reject-publish、reject-publish-dlx
+	Lazy           bool          `mapstructure:"lazy"`             // 惰性队列，消息尽早写入磁盘以降低内存占用
+}
+
+// BrokerName 返回队列所属 broker
+func (q QueueConfig) BrokerName() string {
+	return brokerName(q.Broker)
+}
+
+// DriverName 返回队列所属驱动
+func (q QueueConfig) DriverName() string {
+	if q.Driver == "" {
+		return DriverRabbitMQ
+	}
+	return q.Driver
+}
+
+func brokerName(name string) string {
+	if name == "" {
+		return DefaultBroker
+	}
+	return name
 }
 
 // SchedulerConfig 计划任务配置
 type SchedulerConfig struct {
-	Enabled bool                 `mapstructure:"enabled"`
-	Jobs    []SchedulerJobConfig `mapstructure:"jobs"`
+	Enabled     bool                 `mapstructure:"enabled"`
+	MetricsAddr string               `mapstructure:"metrics_addr"` // 独立调度器进程暴露 /metrics 的监听地址，为空则不启动
+	Timezone    string               `mapstructure:"timezone"`     // cron、daily 任务使用的 IANA 时区，为空时使用服务器本地时区
+	Jobs        []SchedulerJobConfig `mapstructure:"jobs"`
 }
 
 // SchedulerJobConfig 计划任务配置
 type SchedulerJobConfig struct {
-	Name        string `mapstructure:"name"`
-	Type        string `mapstructure:"type"`     // duration, cron, daily, weekly, monthly
-	Schedule    string `mapstructure:"schedule"` // 调度表达式
-	Enabled     bool   `mapstructure:"enabled"`
-	Description string `mapstructure:"description"`
+	Name        string                 `mapstructure:"name"`
+	Job         string                 `mapstructure:"job"`      // 执行的已注册任务，为空时与 name 相同；同一任务按不同参数配置多个调度时使用
+	Type        string                 `mapstructure:"type"`     // duration, cron, daily, weekly, monthly
+	Schedule    string                 `mapstructure:"schedule"` // 调度表达式
+	Timezone    string                 `mapstructure:"timezone"` // 覆盖 scheduler.timezone，对 duration 任务无效
+	Params      map[string]interface{} `mapstructure:"params"`   // 传给任务 Configure 的参数，由任务自行解码和校验
+	After       string                 `mapstructure:"after"`    // 上游任务名称，上游任务成功后执行，不能同时设置 type、schedule 和 timezone
+	Enabled     bool                   `mapstructure:"enabled"`
+	Description string                 `mapstructure:"description"`
+}
+
+// JobName 返回执行的已注册任务名称
+func (c SchedulerJobConfig) JobName() string {
+	if c.Job != "" {
+		return c.Job
+	}
+	return c.Name
 }
 
-// Trace Tracing 配置
+// Trace Tracing 配置，span 通过 OTLP/HTTP 上报
 type Trace struct {
 	Enabled      bool    `mapstructure:"enabled"`
-	Endpoint     string  `mapstructure:"endpoint"`
-	SamplerType  string  `mapstructure:"sampler_type"`
-	SamplerParam float64 `mapstructure:"sampler_param"`
+	Endpoint     string  `mapstructure:"endpoint"`      // OTLP/HTTP 地址，例如 http://127.0.0.1:4318/v1/traces，https 时启用 TLS
+	SamplerType  string  `mapstructure:"sampler_type"`  // const 或 probabilistic，默认 const
+	SamplerParam float64 `mapstructure:"sampler_param"` // const 时大于 0 表示全部采样，probabilistic 时为 0~1 的采样比例
 }
 
 // JWT 认证配置
 type JWT struct {
-	Secret         string        `mapstructure:"secret"`
-	ExpireDuration time.Duration `mapstructure:"expire_duration"`
+	Algorithm        string        `mapstructure:"algorithm"` // HS256、RS256 或 ES256，默认 HS256
+	Secret           string        `mapstructure:"secret"`    // HS256 共享密钥
+	ExpireDuration   time.Duration `mapstructure:"expire_duration"`
+	RefreshDuration  time.Duration `mapstructure:"refresh_duration"`   // 刷新令牌有效期，默认 7 天
+	Issuer           string        `mapstructure:"issuer"`             // 签发时写入 iss，校验时要求一致，默认 go-skeleton
+	Audience         []string      `mapstructure:"audience"`           // 签发时写入 aud，校验时令牌的 aud 至少包含其中一个，为空时不校验
+	Leeway           time.Duration `mapstructure:"leeway"`             // 校验 exp、nbf、iat 时容忍的时钟偏差
+	RequireNotBefore bool          `mapstructure:"require_not_before"` // 拒绝没有 nbf 的令牌，带 nbf 的令牌总是校验
+	KeyID            string        `mapstructure:"key_id"`             // RS256、ES256 签发使用的密钥，写入 kid 头
+	Keys             []JWTKey      `mapstructure:"keys"`               // RS256、ES256 的密钥，轮换后旧密钥只保留公钥继续校验未过期的令牌
+	Encryption       JWTEncryption `mapstructure:"encryption"`
+}
+
+// JWT 签名算法
+const (
+	JWTAlgorithmHS256 = "HS256"
+	JWTAlgorithmRS256 = "RS256"
+	JWTAlgorithmES256 = "ES256"
+)
+
+// Password 密码哈希和强度配置，调整哈希算法或参数后旧哈希在用户下次登录成功时自动升级
+type Password struct {
+	Algorithm  string           `mapstructure:"algorithm"`   // bcrypt 或 argon2id，默认 bcrypt
+	BcryptCost int              `mapstructure:"bcrypt_cost"` // 4-31，默认 10
+	Argon2id   Argon2idParams   `mapstructure:"argon2id"`
+	Strength   PasswordStrength `mapstructure:"strength"`
+}
+
+// 密码哈希算法
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+// Argon2idParams Argon2id 参数，为 0 时使用 RFC 9106 推荐的默认值
+type Argon2idParams struct {
+	Memory      uint32 `mapstructure:"memory"`      // 内存，单位 KiB，默认 65536 (64 MiB)
+	Iterations  uint32 `mapstructure:"iterations"`  // 迭代次数，默认 3
+	Parallelism uint8  `mapstructure:"parallelism"` // 并行度，默认 2
+	SaltLength  uint32 `mapstructure:"salt_length"` // 盐长度，单位字节，默认 16
+	KeyLength   uint32 `mapstructure:"key_length"`  // 哈希长度，单位字节，默认 32
+}
+
+// PasswordStrength 密码强度规则，注册和重置密码时检查，为 0 或 false 的规则不检查
+type PasswordStrength struct {
+	MinLength      int            `mapstructure:"min_length"`       // 最小长度，按字符计
+	MinEntropyBits float64        `mapstructure:"min_entropy_bits"` // 按字符类别估算的最小熵，单位比特
+	RejectCommon   bool           `mapstructure:"reject_common"`    // 拒绝内置常见密码列表中的密码
+	Breach         PasswordBreach `mapstructure:"breach"`
+}
+
+// PasswordBreach 泄露密码检查，按 k-anonymity 方式查询 Pwned Passwords 兼容接口，只发送密码 SHA-1 的前 5 位
+// 接口不可用时放行并记录警告，不影响注册
+type PasswordBreach struct {
+	Enabled  bool          `mapstructure:"enabled"`
+	URL      string        `mapstructure:"url"`       // 默认 https://api.pwnedpasswords.com/range/
+	Timeout  time.Duration `mapstructure:"timeout"`   // 默认 3s
+	MinCount int           `mapstructure:"min_count"` // 出现次数达到该值时拒绝，默认 1
+}
+
+// JWTKey 非对称签名密钥，通过 /.well-known/jwks.json 发布公钥
+type JWTKey struct {
+	ID             string `mapstructure:"id"`               // kid
+	PrivateKeyFile string `mapstructure:"private_key_file"` // PEM 私钥，key_id 指向的密钥必须配置
+	PublicKeyFile  string `mapstructure:"public_key_file"`  // PEM 公钥，只用于校验的旧密钥配置；配置了私钥时由私钥推导
+}
+
+// JWTEncryption 令牌加密配置，开启后签名后的令牌以 JWE（alg=dir, enc=A256GCM）加密，客户端无法读取声明
+type JWTEncryption struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Key     string `mapstructure:"key"` // base64 编码的 32 字节密钥，可通过 JWT_ENCRYPTION_KEY 环境变量设置
+}
+
+// CORS 跨域配置
+type CORS struct {
+	AllowOrigins     []string `mapstructure:"allow_origins"`     // 允许的来源，为空或包含 "*" 时允许所有来源
+	AllowCredentials bool     `mapstructure:"allow_credentials"` // 是否允许携带 cookie
+}
+
+// ProdGuard 生产环境启动检查配置
+type ProdGuard struct {
+	AllowInsecure bool `mapstructure:"allow_insecure"` // 为 true 时只记录违规项，不阻止启动
 }
 
 // IDGeneratorConfig ID生成器配置
@@ -118,6 +892,203 @@ type IDGeneratorConfig struct {
 	TimeUnit      time.Duration `mapstructure:"time_unit"`       // 时间单位
 }
 
+// QueryCounter 请求级 SQL 计数配置（用于发现 N+1 查询）
+type QueryCounter struct {
+	Enabled       bool `mapstructure:"enabled"`
+	MaxQueries    int  `mapstructure:"max_queries"`    // 单个请求 SQL 总数超过该值时告警
+	MaxDuplicates int  `mapstructure:"max_duplicates"` // 同一条 SQL 重复执行达到该次数时告警
+}
+
+// SlowRequest 慢请求检测配置
+type SlowRequest struct {
+	Enabled     bool          `mapstructure:"enabled"`
+	Threshold   time.Duration `mapstructure:"threshold"`    // 请求耗时超过该值时记录慢请求日志
+	MaxCalls    int           `mapstructure:"max_calls"`    // 日志中保留耗时最长的下游调用数量
+	PprofLabels bool          `mapstructure:"pprof_labels"` // 为处理请求的 goroutine 设置 pprof 标签，CPU profile 可按路由过滤
+}
+
+// HTTPMetrics HTTP 请求指标配置，指标通过内部端口的 /metrics 输出
+type HTTPMetrics struct {
+	Enabled         bool      `mapstructure:"enabled"`
+	DurationBuckets []float64 `mapstructure:"duration_buckets"` // 请求耗时分桶，单位秒，默认 prometheus.DefBuckets
+}
+
+// SLO 按路由的服务等级目标配置，错误预算燃烧率通过内部端口的 /metrics 和 GET /api/v1/admin/slo 输出
+type SLO struct {
+	Enabled    bool            `mapstructure:"enabled"`
+	Windows    []time.Duration `mapstructure:"windows"`    // 计算燃烧率的滑动窗口，默认 5m、30m、1h、6h
+	Objectives []SLOObjective  `mapstructure:"objectives"` // 按顺序匹配，第一条匹配的目标生效，未匹配的路由不计入任何目标
+}
+
+// SLOObjective 路由组的服务等级目标
+type SLOObjective struct {
+	Name          string        `mapstructure:"name"`           // 指标 slo 标签和管理接口中的名称，不可重复
+	Path          string        `mapstructure:"path"`           // Gin 路由模板，以 /* 结尾时按前缀匹配
+	Methods       []string      `mapstructure:"methods"`        // 为空时匹配所有方法
+	Availability  float64       `mapstructure:"availability"`   // 可用性目标，如 0.999，5xx 响应计为失败；为 0 时不考核
+	Latency       time.Duration `mapstructure:"latency"`        // 延迟阈值，耗时超过该值计为失败；为 0 时不考核
+	LatencyTarget float64       `mapstructure:"latency_target"` // 耗时不超过 latency 的请求比例目标，如 0.99，为 0 时使用 availability
+}
+
+// DBPool 数据库连接池压力监控配置，所有数据源共用
+type DBPool struct {
+	Enabled           bool          `mapstructure:"enabled"`
+	Interval          time.Duration `mapstructure:"interval"`            // 连接池统计的采样间隔
+	WaitWarnThreshold time.Duration `mapstructure:"wait_warn_threshold"` // 采样周期内获取连接的平均等待超过该值时记录警告
+	Shed              bool          `mapstructure:"shed"`                // 连接池饱和时直接以 503 拒绝新请求
+	ShedUtilization   float64       `mapstructure:"shed_utilization"`    // 使用中连接占最大连接数的比例达到该值且周期内出现等待时视为饱和
+	RetryAfter        time.Duration `mapstructure:"retry_after"`         // 拒绝请求时 Retry-After 响应头的值
+}
+
+// 降载优先级，实例过载时按 low、normal、high 的顺序拒绝请求
+const (
+	LoadShedPriorityCritical = "critical" // 从不拒绝
+	LoadShedPriorityHigh     = "high"
+	LoadShedPriorityNormal   = "normal"
+	LoadShedPriorityLow      = "low"
+)
+
+// LoadShedding 基于实例压力的自适应降载配置，goroutine 数、调度延迟或堆内存超过阈值时拒绝低优先级路由
+type LoadShedding struct {
+	Enabled         bool            `mapstructure:"enabled"`
+	Interval        time.Duration   `mapstructure:"interval"`          // 运行时指标的采样间隔
+	MaxGoroutines   int             `mapstructure:"max_goroutines"`    // goroutine 数阈值，为 0 时不检测
+	MaxSchedLatency time.Duration   `mapstructure:"max_sched_latency"` // 调度延迟 p99 阈值，为 0 时不检测
+	MaxHeapMB       int             `mapstructure:"max_heap_mb"`       // 堆内存阈值 (MB)，为 0 时不检测
+	RetryAfter      time.Duration   `mapstructure:"retry_after"`       // 拒绝请求时 Retry-After 响应头的值
+	DefaultPriority string          `mapstructure:"default_priority"`  // 未匹配 routes 的路由优先级，默认 normal
+	Routes          []LoadShedRoute `mapstructure:"routes"`            // 按顺序匹配，第一条匹配的规则生效
+}
+
+// LoadShedRoute 路由组的降载优先级
+type LoadShedRoute struct {
+	Path     string   `mapstructure:"path"`     // Gin 路由模板，以 /* 结尾时按前缀匹配
+	Methods  []string `mapstructure:"methods"`  // 为空时匹配所有方法
+	Priority string   `mapstructure:"priority"` // critical、high、normal 或 low
+}
+
+// FaultInjection 故障注入配置，仅用于开发和预发环境验证客户端重试与告警，生产环境始终不生效
+type FaultInjection struct {
+	Enabled bool        `mapstructure:"enabled"`
+	Rules   []FaultRule `mapstructure:"rules"` // 启动时的初始规则，运行时可通过 /api/v1/admin/faults 替换
+}
+
+// FaultRule 单条故障注入规则，命中的请求先注入延迟，再按概率断开连接或返回错误
+type FaultRule struct {
+	Path        string        `mapstructure:"path"`         // Gin 路由模板，以 /* 结尾时按前缀匹配
+	Methods     []string      `mapstructure:"methods"`      // 为空时匹配所有方法
+	Latency     time.Duration `mapstructure:"latency"`      // 固定延迟
+	Jitter      time.Duration `mapstructure:"jitter"`       // 在固定延迟上追加 [0, jitter) 的随机延迟
+	ErrorRate   float64       `mapstructure:"error_rate"`   // 返回错误的概率，0-1
+	ErrorStatus int           `mapstructure:"error_status"` // 返回的状态码，默认 503
+	DropRate    float64       `mapstructure:"drop_rate"`    // 不返回响应直接断开连接的概率，0-1
+}
+
+// OpenAPI 校验模式
+const (
+	OpenAPIModeLog  = "log"  // 只记录与规范不一致的请求和响应
+	OpenAPIModeFail = "fail" // 请求不符合规范时返回 400
+)
+
+// OpenAPIValidation 按 OpenAPI 规范校验请求和响应，用于开发和测试环境，生产环境不生效
+type OpenAPIValidation struct {
+	Enabled           bool   `mapstructure:"enabled"`
+	Spec              string `mapstructure:"spec"`               // 规范文件路径，支持 OpenAPI 3 和 Swagger 2.0
+	Mode              string `mapstructure:"mode"`               // log 或 fail，默认 log
+	ValidateResponses bool   `mapstructure:"validate_responses"` // 是否校验响应，响应已写出，不一致时只记录日志
+}
+
+// Decompression 请求体解压配置，用于客户端以 gzip、deflate 压缩提交的批量导入、遥测等大请求体
+type Decompression struct {
+	Enabled   bool     `mapstructure:"enabled"`
+	MaxSize   int64    `mapstructure:"max_size"`  // 解压后请求体最大字节数，超过时返回 413，默认 10MB
+	Encodings []string `mapstructure:"encodings"` // 接受的 Content-Encoding，默认 gzip、deflate，其他编码返回 415
+}
+
+// ProblemDetails RFC 7807 错误响应配置，开启后按请求的 Accept 协商，默认仍使用 code/msg 格式
+type ProblemDetails struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	TypeBaseURI string `mapstructure:"type_base_uri"` // type 的 URI 前缀，拼接错误类型如 validation、not_found，为空时使用 urn:problem-type:
+}
+
+// Cache 响应缓存配置
+type Cache struct {
+	Enabled            bool                     `mapstructure:"enabled"`
+	Prefix             string                   `mapstructure:"prefix"`
+	DefaultTTL         time.Duration            `mapstructure:"default_ttl"`
+	Rules              []CacheRule              `mapstructure:"rules"`               // 需要缓存的 GET 路由
+	Invalidations      []CacheInvalidation      `mapstructure:"invalidations"`       // 写操作成功后需要失效的标签
+	EventInvalidations []CacheEventInvalidation `mapstructure:"event_invalidations"` // 发布或消费领域事件后需要失效的标签
+}
+
+// CacheRule 单个 GET 路由的缓存规则
+type CacheRule struct {
+	Path string        `mapstructure:"path"` // Gin 路由模板，如 /api/v1/users/:id
+	TTL  time.Duration `mapstructure:"ttl"`  // 为空时使用 default_ttl
+	Tags []string      `mapstructure:"tags"` // 失效标签
+}
+
+// CacheInvalidation 写操作触发的缓存失效规则
+type CacheInvalidation struct {
+	Method string   `mapstructure:"method"`
+	Path   string   `mapstructure:"path"`
+	Tags   []string `mapstructure:"tags"`
+}
+
+// CacheEventInvalidation 领域事件触发的缓存失效规则，覆盖不经过 HTTP 写路由的修改
+type CacheEventInvalidation struct {
+	Event string   `mapstructure:"event"` // 事件类型，如 user.updated
+	Tags  []string `mapstructure:"tags"`
+}
+
+// BloomFilter 存在性检查布隆过滤器配置
+type BloomFilter struct {
+	Enabled           bool    `mapstructure:"enabled"`
+	Prefix            string  `mapstructure:"prefix"`
+	ExpectedItems     uint64  `mapstructure:"expected_items"`      // 预期元素数量
+	FalsePositiveRate float64 `mapstructure:"false_positive_rate"` // 期望误判率
+}
+
+// Uniqueness 用户名/邮箱唯一性占用配置
+// 写入期间在 Redis 中短暂占用用户名和邮箱，缩小并发请求同时通过存在性检查的窗口，最终由数据库唯一索引兜底
+type Uniqueness struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Prefix         string        `mapstructure:"prefix"`
+	LockTTL        time.Duration `mapstructure:"lock_ttl"`        // 创建/更新期间的占用时长
+	ReservationTTL time.Duration `mapstructure:"reservation_ttl"` // 多步骤注册时预占的保留时长
+}
+
+// Health 依赖健康检查配置 (GET /api/v1/admin/health)
+type Health struct {
+	Timeout time.Duration          `mapstructure:"timeout"` // 单项检查默认超时，同时用于 /ready 的就绪检查
+	Checks  map[string]HealthCheck `mapstructure:"checks"`  // 按检查名称覆盖级别和超时: database、redis、rabbitmq、migrations；/ready 只使用其中的超时
+}
+
+// HealthCheck 单项健康检查配置
+type HealthCheck struct {
+	Level   string        `mapstructure:"level"`   // critical: 失败时整体 unhealthy; optional: 失败时整体 degraded
+	Timeout time.Duration `mapstructure:"timeout"` // 为空时使用 health.timeout
+}
+
+// Warmup 启动预热配置，预热完成前 /ready 返回 503
+type Warmup struct {
+	Enabled          bool                  `mapstructure:"enabled"`
+	Timeout          time.Duration         `mapstructure:"timeout"`           // 单个任务默认超时
+	DBConnections    int                   `mapstructure:"db_connections"`    // 每个数据源预先建立的连接数，为 0 时不预热数据库
+	RedisConnections int                   `mapstructure:"redis_connections"` // 预先建立的 Redis 连接数，为 0 时不预热 Redis
+	Tasks            map[string]WarmupTask `mapstructure:"tasks"`             // 按任务名称覆盖: database、redis、templates
+}
+
+// WarmupTask 单个预热任务配置
+type WarmupTask struct {
+	Disabled bool          `mapstructure:"disabled"` // 跳过该任务
+	Required bool          `mapstructure:"required"` // 失败时保持未就绪，否则只记录日志
+	Timeout  time.Duration `mapstructure:"timeout"`  // 为空时使用 warmup.timeout
+}
+
+// defaultConfigFile 未设置 CONFIG_FILE 时读取的配置文件
+const defaultConfigFile = "configs/config.dev.yaml"
+
 // LoadConfig 加载配置并返回 Config 实例
 func LoadConfig() (*Config, error) {
 	v := viper.New()
@@ -127,17 +1098,30 @@ func LoadConfig() (*Config, error) {
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
 	// 从环境变量获取配置文件路径，默认使用 config.dev.yaml
-	configFile := "configs/config.dev.yaml"
-	if envConfigFile := v.GetString("CONFIG_FILE"); envConfigFile != "" {
+	configFile := defaultConfigFile
+	envConfigFile := v.GetString("CONFIG_FILE")
+	if envConfigFile != "" {
 		configFile = envConfigFile
 	}
 
-	v.SetConfigFile(configFile)
 	v.SetConfigType("yaml")
 
-	// 读取配置文件
-	if err := v.ReadInConfig(); err != nil {
-		return nil, err
+	// 未指定 CONFIG_FILE 且默认配置文件不存在时（如单独分发的二进制）使用内置的开发环境配置
+	if _, err := os.Stat(configFile); envConfigFile == "" && errors.Is(err, fs.ErrNotExist) {
+		data, err := configs.Default("dev")
+		if err != nil {
+			return nil, err
+		}
+		if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+			return nil, err
+		}
+	} else {
+		v.SetConfigFile(configFile)
+
+		// 读取配置文件
+		if err := v.ReadInConfig(); err != nil {
+			return nil, err
+		}
 	}
 
 	var cfg Config
@@ -145,6 +1129,19 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	// 日志服务信息未配置时沿用应用名称和环境
+	if cfg.Logger.Service.Name == "" {
+		cfg.Logger.Service.Name = cfg.App.Name
+	}
+	if cfg.Logger.Service.Env == "" {
+		cfg.Logger.Service.Env = cfg.App.Env
+	}
+
+	// 生产环境拒绝使用不安全的配置启动
+	if err := cfg.checkProdGuard(); err != nil {
+		return nil, err
+	}
+
 	return &cfg, nil
 }
 
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/configs"
+
+	"github.com/spf13/viper"
+)
+
+// loadEmbeddedConfig 解析内置的环境配置
+func loadEmbeddedConfig(t *testing.T, env string) *Config {
+	t.Helper()
+	data, err := configs.Default(env)
+	if err != nil {
+		t.Fatalf("missing embedded config: %v", err)
+	}
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+		t.Fatalf("invalid yaml: %v", err)
+	}
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	return &cfg
+}
+
+func TestEmbeddedConfigs(t *testing.T) {
+	for _, env := range configs.Envs {
+		t.Run(env, func(t *testing.T) {
+			cfg := loadEmbeddedConfig(t, env)
+			if cfg.App.Name == "" || len(cfg.Databases) == 0 || len(cfg.RabbitMQ.Queues) == 0 || len(cfg.Scheduler.Jobs) == 0 {
+				t.Fatalf("embedded %s config is missing sections: %+v", env, cfg.App)
+			}
+		})
+	}
+
+	if _, err := configs.Default("staging"); err == nil {
+		t.Fatal("expected error for unknown env")
+	}
+}
+
+// TestUserWritesInvalidateCache 修改用户信息的路由都要失效 GET /api/v1/users/:id 的缓存
+func TestUserWritesInvalidateCache(t *testing.T) {
+	writes := []struct{ method, path string }{
+		{"PUT", "/api/v1/users/:id"},
+		{"PATCH", "/api/v1/users/:id"},
+		{"DELETE", "/api/v1/users/:id"},
+		{"POST", "/api/v1/users/:id/email-change/confirm"},
+		{"DELETE", "/api/v1/users/:id/email-change"},
+		{"POST", "/api/v1/users/:id/password-reset/confirm"},
+		{"POST", "/api/v1/users/:id/deletion"},
+		{"DELETE", "/api/v1/users/:id/deletion"},
+		{"POST", "/api/v1/admin/users/:id/password-reset"},
+		{"POST", "/api/v1/admin/users/:id/disable"},
+		{"POST", "/api/v1/admin/users/:id/enable"},
+		{"PUT", "/api/v1/admin/users/:id/roles"},
+	}
+	for _, env := range configs.Envs {
+		t.Run(env, func(t *testing.T) {
+			cfg := loadEmbeddedConfig(t, env)
+			invalidated := map[string]bool{}
+			for _, inv := range cfg.Cache.Invalidations {
+				if slices.Contains(inv.Tags, "users") {
+					invalidated[inv.Method+" "+inv.Path] = true
+				}
+			}
+			for _, w := range writes {
+				if !invalidated[w.method+" "+w.path] {
+					t.Errorf("%s %s does not invalidate the users cache tag", w.method, w.path)
+				}
+			}
+		})
+	}
+}
+
+func TestAuditProduction(t *testing.T) {
+	cfg := &Config{
+		App:     App{Env: "production"},
+		JWT:     JWT{Secret: "${JWT_SECRET}"},
+		CORS:    CORS{AllowCredentials: true},
+		Storage: Storage{SigningSecret: "${STORAGE_SIGNING_SECRET}"},
+		Faults:  FaultInjection{Enabled: true},
+		Databases: map[string]Database{
+			"primary":   {DSN: "root:secret@tcp(127.0.0.1:3306)/app"},
+			"analytics": {DSN: "host=db.internal port=5432 dbname=analytics"},
+			"reporting": {DSN: "postgres://user@localhost:5432/reporting"},
+		},
+	}
+
+	err := cfg.checkProdGuard()
+	guardErr, ok := err.(*ProdGuardError)
+	if !ok {
+		t.Fatalf("expected ProdGuardError, got %v", err)
+	}
+	if len(guardErr.Violations) != 6 {
+		t.Fatalf("expected 6 violations, got %v", guardErr.Violations)
+	}
+
+	// 非对称签名不使用 jwt.secret
+	cfg.JWT.Algorithm = JWTAlgorithmRS256
+	if violations := cfg.AuditProduction(); len(violations) != 5 {
+		t.Fatalf("expected jwt.secret to be skipped for RS256, got %v", violations)
+	}
+
+	cfg.ProdGuard.AllowInsecure = true
+	if err := cfg.checkProdGuard(); err != nil {
+		t.Fatalf("override should allow start: %v", err)
+	}
+
+	cfg.App.Env = "dev"
+	if violations := cfg.AuditProduction(); violations != nil {
+		t.Fatalf("non-production config should not be audited: %v", violations)
+	}
+}
+
+func TestConsumedQueues(t *testing.T) {
+	queues := []QueueConfig{
+		{Name: "hello.queue"},
+		{Name: "hello.dlq", NoConsume: true},
+		{Name: "search.indexer"},
+	}
+
+	consumed, err := ConsumerConfig{}.ConsumedQueues(queues)
+	if err != nil || len(consumed) != 2 || consumed[0].Name != "hello.queue" || consumed[1].Name != "search.indexer" {
+		t.Fatalf("empty selection should consume every queue except no_consume: %v, %v", consumed, err)
+	}
+
+	selected := ConsumerConfig{Queues: []ConsumerQueue{{Name: "search.indexer", Workers: 8}}}
+	consumed, err = selected.ConsumedQueues(queues)
+	if err != nil || len(consumed) != 1 || consumed[0].Name != "search.indexer" {
+		t.Fatalf("expected only search.indexer, got %v, %v", consumed, err)
+	}
+
+	for _, names := range [][]string{{"missing.queue"}, {"hello.dlq"}, {"hello.queue", "hello.queue"}} {
+		cfg := ConsumerConfig{}
+		for _, name := range names {
+			cfg.Queues = append(cfg.Queues, ConsumerQueue{Name: name})
+		}
+		if _, err := cfg.ConsumedQueues(queues); err == nil {
+			t.Errorf("selection %v should be rejected", names)
+		}
+	}
+}
+
+func TestListenFileMode(t *testing.T) {
+	tests := []struct {
+		mode    string
+		want    os.FileMode
+		wantErr bool
+	}{
+		{mode: "", want: 0},
+		{mode: "0660", want: 0o660},
+		{mode: "600", want: 0o600},
+		{mode: "0999", wantErr: true},
+		{mode: "01777", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Listen{SocketMode: tt.mode}.FileMode()
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("FileMode(%q) = %v, %v; want %v, error %v", tt.mode, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
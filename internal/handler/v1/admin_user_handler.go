@@ -0,0 +1,236 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AdminUserHandler 管理员用户管理处理器
+type AdminUserHandler struct {
+	adminUserService service.AdminUserService
+	logger           *zap.Logger
+}
+
+// NewAdminUserHandler 创建管理员用户管理处理器实例
+func NewAdminUserHandler(adminUserService service.AdminUserService, logger *zap.Logger) *AdminUserHandler {
+	return &AdminUserHandler{
+		adminUserService: adminUserService,
+		logger:           logger,
+	}
+}
+
+// ForcePasswordReset 强制重置密码
+// @Summary 强制重置密码
+// @Description 吊销用户的全部令牌，向用户邮箱发送重置令牌；用户凭令牌设置新密码前不能登录，不能对自己执行
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param request body model.AdminPasswordResetRequest false "操作原因"
+// @Success 200 {object} response.Response{data=model.UserResponse} "已要求重置密码"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 403 {object} response.Response "不能对自己的账户执行该操作"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/admin/users/{id}/password-reset [post]
+func (h *AdminUserHandler) ForcePasswordReset(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	var req model.AdminPasswordResetRequest
+	if !bindOptionalJSON(c, h.logger, &req) {
+		return
+	}
+
+	user, err := h.adminUserService.ForcePasswordReset(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		h.fail(c, err, "Failed to force password reset", id)
+		return
+	}
+	response.SuccessWithMsg(c, http.StatusOK, "已要求重置密码", user)
+}
+
+// DisableUser 禁用账户
+// @Summary 禁用账户
+// @Description 禁用账户并吊销全部令牌，必须填写原因，不能对自己执行
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param request body model.DisableUserRequest true "禁用原因"
+// @Success 200 {object} response.Response{data=model.UserResponse} "已禁用"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 403 {object} response.Response "不能对自己的账户执行该操作"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/admin/users/{id}/disable [post]
+func (h *AdminUserHandler) DisableUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	var req model.DisableUserRequest
+	if !bindJSON(c, h.logger, &req) {
+		return
+	}
+
+	user, err := h.adminUserService.DisableUser(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		h.fail(c, err, "Failed to disable user", id)
+		return
+	}
+	response.SuccessWithMsg(c, http.StatusOK, "已禁用", user)
+}
+
+// EnableUser 启用账户
+// @Summary 启用账户
+// @Description 启用账户并清除禁用原因，之前吊销的令牌不会恢复
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param request body model.EnableUserRequest false "操作原因"
+// @Success 200 {object} response.Response{data=model.UserResponse} "已启用"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/admin/users/{id}/enable [post]
+func (h *AdminUserHandler) EnableUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	var req model.EnableUserRequest
+	if !bindOptionalJSON(c, h.logger, &req) {
+		return
+	}
+
+	user, err := h.adminUserService.EnableUser(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		h.fail(c, err, "Failed to enable user", id)
+		return
+	}
+	response.SuccessWithMsg(c, http.StatusOK, "已启用", user)
+}
+
+// AssignRoles 分配角色
+// @Summary 分配角色
+// @Description 覆盖用户的角色并吊销全部令牌，重新登录后生效；不能修改自己的角色
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param request body model.AssignRolesRequest true "角色列表"
+// @Success 200 {object} response.Response{data=model.UserResponse} "已分配角色"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 403 {object} response.Response "不能对自己的账户执行该操作"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/admin/users/{id}/roles [put]
+func (h *AdminUserHandler) AssignRoles(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	var req model.AssignRolesRequest
+	if !bindJSON(c, h.logger, &req) {
+		return
+	}
+
+	user, err := h.adminUserService.AssignRoles(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		h.fail(c, err, "Failed to assign roles", id)
+		return
+	}
+	response.SuccessWithMsg(c, http.StatusOK, "已分配角色", user)
+}
+
+// GetSessions 查询会话状态
+// @Summary 查询会话状态
+// @Description 返回最近登录时间、令牌吊销时间和密码重置状态
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=model.UserSessions} "获取成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/admin/users/{id}/sessions [get]
+func (h *AdminUserHandler) GetSessions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	sessions, err := h.adminUserService.GetSessions(c.Request.Context(), uint(id))
+	if err != nil {
+		h.fail(c, err, "Failed to get user sessions", id)
+		return
+	}
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", sessions)
+}
+
+// ListAuditLogs 查询审计日志
+// @Summary 查询用户审计日志
+// @Description 按时间倒序分页返回针对该用户的管理操作记录
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(20)
+// @Success 200 {object} response.Response{data=response.PageResponse{list=[]model.UserAuditLog}} "获取成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/admin/users/{id}/audit-logs [get]
+func (h *AdminUserHandler) ListAuditLogs(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+
+	logs, total, err := h.adminUserService.ListAuditLogs(c.Request.Context(), uint(id), page, pageSize)
+	if err != nil {
+		h.fail(c, err, "Failed to list user audit logs", id)
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", response.PageResponse{
+		List:     logs,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+// fail 记录日志并返回错误响应
+func (h *AdminUserHandler) fail(c *gin.Context, err error, message string, id uint64) {
+	h.logger.Error(message, zap.Uint64("user_id", id), zap.Error(err))
+	if appErr, ok := err.(*errors.AppError); ok {
+		response.AppError(c, appErr)
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, message)
+}
@@ -0,0 +1,120 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	stdErrors "errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/pkg/response"
+	"github.com/hedeqiang/skeleton/pkg/validator"
+
+	"github.com/gin-gonic/gin"
+	playground "github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+// bindJSON 绑定并校验 JSON 请求体，失败时写出 400 响应并返回 false
+// 校验由 pkg/validator 注册的 Gin 校验器完成，开发环境下错误信息包含字段路径、规则和实际值
+func bindJSON(c *gin.Context, logger *zap.Logger, req interface{}) bool {
+	err := c.ShouldBindJSON(req)
+	if err == nil {
+		return true
+	}
+
+	var validationErrs playground.ValidationErrors
+	if stdErrors.As(err, &validationErrs) {
+		logger.Warn("Validation failed", zap.Error(err))
+		response.Error(c, http.StatusBadRequest, "请求参数验证失败: "+validator.Message(validationErrs))
+		return false
+	}
+
+	logger.Error("Failed to bind JSON", zap.Error(err))
+	response.Error(c, http.StatusBadRequest, "请求参数格式错误")
+	return false
+}
+
+// bindOptionalJSON 与 bindJSON 相同，但允许没有请求体，此时 req 保持零值
+func bindOptionalJSON(c *gin.Context, logger *zap.Logger, req interface{}) bool {
+	if c.Request.ContentLength == 0 {
+		return true
+	}
+	return bindJSON(c, logger, req)
+}
+
+// bindPatchJSON 按 JSON merge-patch 语义绑定部分更新请求，req 的字段应为指针类型
+// 只处理 req 中声明的字段：没有任何可识别字段时返回 400；字段值为 null 时返回 400，
+// 因为这些字段都不允许清空；出现的字段按 validate 标签逐一校验（使用 omitnil 跳过未出现的字段）
+func bindPatchJSON(c *gin.Context, logger *zap.Logger, req interface{}) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		logger.Error("Failed to read request body", zap.Error(err))
+		response.Error(c, http.StatusBadRequest, "请求参数格式错误")
+		return false
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		logger.Warn("Failed to parse patch body", zap.Error(err))
+		response.Error(c, http.StatusBadRequest, "请求参数格式错误")
+		return false
+	}
+
+	present := 0
+	for _, name := range jsonFieldNames(req) {
+		raw, ok := fields[name]
+		if !ok {
+			continue
+		}
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			response.Error(c, http.StatusBadRequest, name+"不能为空")
+			return false
+		}
+		present++
+	}
+	if present == 0 {
+		response.Error(c, http.StatusBadRequest, "请求体中没有可更新的字段")
+		return false
+	}
+
+	if err := json.Unmarshal(body, req); err != nil {
+		logger.Warn("Failed to bind patch body", zap.Error(err))
+		response.Error(c, http.StatusBadRequest, "请求参数格式错误")
+		return false
+	}
+
+	if err := validator.Struct(req); err != nil {
+		logger.Warn("Validation failed", zap.Error(err))
+		response.Error(c, http.StatusBadRequest, "请求参数验证失败: "+validator.Message(err))
+		return false
+	}
+	return true
+}
+
+// jsonFieldNames 返回结构体的 JSON 字段名
+func jsonFieldNames(v interface{}) []string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+		names = append(names, name)
+	}
+	return names
+}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestBindPatchJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"valid", `{"email":"new@example.com"}`, http.StatusOK},
+		{"no recognized fields", `{"nickname":"x"}`, http.StatusBadRequest},
+		{"empty object", `{}`, http.StatusBadRequest},
+		{"null field", `{"username":null}`, http.StatusBadRequest},
+		{"empty string", `{"username":""}`, http.StatusBadRequest},
+		{"invalid status", `{"status":2}`, http.StatusBadRequest},
+		{"wrong type", `{"status":"1"}`, http.StatusBadRequest},
+		{"malformed", `{`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPatch, "/users/1", strings.NewReader(tt.body))
+
+			var req model.PatchUserRequest
+			ok := bindPatchJSON(c, zap.NewNop(), &req)
+
+			if tt.status == http.StatusOK {
+				if !ok || req.Email == nil || *req.Email != "new@example.com" || req.Username != nil {
+					t.Fatalf("expected bound request, got ok=%v req=%+v body=%s", ok, req, w.Body.String())
+				}
+				return
+			}
+			if ok || w.Code != tt.status {
+				t.Fatalf("expected %d, got ok=%v code=%d body=%s", tt.status, ok, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package v1
+
+import (
+	stdErrors "errors"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/pkg/response"
+	"github.com/hedeqiang/skeleton/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DownloadHandler 签名下载处理器，凭 URLSigner 签发的地址读取存储中的对象，不需要其他认证
+type DownloadHandler struct {
+	storage storage.Storage
+	signer  *storage.URLSigner
+	logger  *zap.Logger
+}
+
+// NewDownloadHandler 创建签名下载处理器实例，未配置签名密钥时返回 nil，下载路由不会注册
+func NewDownloadHandler(store storage.Storage, signer *storage.URLSigner, logger *zap.Logger) *DownloadHandler {
+	if signer == nil {
+		return nil
+	}
+	return &DownloadHandler{
+		storage: store,
+		signer:  signer,
+		logger:  logger,
+	}
+}
+
+// Download 下载对象
+// @Summary 签名下载
+// @Description 校验签名和过期时间后以附件形式返回存储中的对象，地址由导出等接口生成
+// @Tags 下载
+// @Produce octet-stream
+// @Param key path string true "对象 key"
+// @Param expires query int true "过期时间（Unix 秒）"
+// @Param signature query string true "签名"
+// @Success 200 {file} file "对象内容"
+// @Failure 403 {object} response.Response "签名无效"
+// @Failure 404 {object} response.Response "对象不存在"
+// @Failure 410 {object} response.Response "下载地址已过期"
+// @Router /api/v1/downloads/{key} [get]
+func (h *DownloadHandler) Download(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	err := h.signer.Verify(key, c.Query("expires"), c.Query("signature"))
+	switch {
+	case stdErrors.Is(err, storage.ErrSignatureExpired):
+		response.Error(c, http.StatusGone, "下载地址已过期")
+		return
+	case err != nil:
+		response.Error(c, http.StatusForbidden, "签名无效")
+		return
+	}
+
+	reader, err := h.storage.Get(c.Request.Context(), key)
+	if err != nil {
+		if stdErrors.Is(err, storage.ErrNotFound) {
+			response.Error(c, http.StatusNotFound, "文件不存在")
+			return
+		}
+		h.logger.Error("Failed to open download", zap.String("key", key), zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, "Failed to open download")
+		return
+	}
+	defer reader.Close()
+
+	c.DataFromReader(http.StatusOK, -1, "application/octet-stream", reader, map[string]string{
+		"Content-Disposition": `attachment; filename="` + path.Base(key) + `"`,
+		"Cache-Control":       "no-store",
+	})
+}
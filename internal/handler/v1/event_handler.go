@@ -0,0 +1,77 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// EventHandler 通用事件发布处理器
+type EventHandler struct {
+	eventService service.EventService
+	logger       *zap.Logger
+}
+
+// NewEventHandler 创建通用事件发布处理器实例，未启用 event_publish 时返回 nil，通用发布路由不会注册
+func NewEventHandler(eventService service.EventService, logger *zap.Logger) *EventHandler {
+	if eventService == nil {
+		return nil
+	}
+	return &EventHandler{
+		eventService: eventService,
+		logger:       logger,
+	}
+}
+
+// Publish 发布事件
+// @Summary 发布事件
+// @Description 以事件信封发布 event_publish.types 中允许的事件类型，载荷按消息 schema 校验，供内部工具注入事件；需要登录，按事件类型校验角色并限流
+// @Tags 消息管理
+// @Accept json
+// @Produce json
+// @Param event body model.PublishEventRequest true "事件类型与载荷"
+// @Success 200 {object} response.Response{data=model.PublishEventResponse} "发布成功"
+// @Failure 400 {object} response.Response "请求参数错误或载荷与 schema 不一致"
+// @Failure 401 {object} response.Response "未登录"
+// @Failure 403 {object} response.Response "不允许发布该类型的事件"
+// @Failure 429 {object} response.Response "发布过于频繁"
+// @Failure 500 {object} response.Response "发布失败"
+// @Router /api/v1/events/publish [post]
+func (h *EventHandler) Publish(c *gin.Context) {
+	// 登录由 route_policies 完成，未经认证的请求一律拒绝，避免未开启路由策略时接口对外开放
+	userID, ok := c.Get("UserID")
+	if !ok {
+		response.AppError(c, errors.ErrTokenMissing)
+		return
+	}
+
+	var req model.PublishEventRequest
+	if !bindJSON(c, h.logger, &req) {
+		return
+	}
+
+	actor := service.EventActor{}
+	actor.UserID, _ = userID.(uint)
+	if roles, ok := c.Get("Roles"); ok {
+		actor.Roles, _ = roles.([]string)
+	}
+
+	result, err := h.eventService.Publish(c.Request.Context(), actor, &req)
+	if err != nil {
+		h.logger.Warn("Failed to publish event", zap.String("type", req.Type), zap.Uint("user_id", actor.UserID), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to publish event")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "发布成功", result)
+}
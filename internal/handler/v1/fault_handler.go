@@ -0,0 +1,144 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/fault"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// FaultHandler 故障注入规则管理处理器
+type FaultHandler struct {
+	injector *fault.Injector
+	logger   *zap.Logger
+}
+
+// NewFaultHandler 创建故障注入规则管理处理器实例，未启用故障注入时返回 nil，管理路由不会注册
+func NewFaultHandler(injector *fault.Injector, logger *zap.Logger) *FaultHandler {
+	if injector == nil {
+		return nil
+	}
+	return &FaultHandler{
+		injector: injector,
+		logger:   logger,
+	}
+}
+
+// GetFaults 查询故障注入规则
+// @Summary 查询故障注入规则
+// @Description 返回当前生效的故障注入规则，仅在非生产环境开启 fault_injection 时可用
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]model.FaultRule} "获取成功"
+// @Router /api/v1/admin/faults [get]
+func (h *FaultHandler) GetFaults(c *gin.Context) {
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", h.rules())
+}
+
+// ReplaceFaults 替换故障注入规则
+// @Summary 替换故障注入规则
+// @Description 整体替换故障注入规则并立即生效，规则按顺序匹配，第一条匹配的规则生效；rules 为空时清除所有故障。规则只保存在当前实例内存中，重启后恢复为配置文件中的规则
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param rules body model.FaultRulesRequest true "故障注入规则"
+// @Success 200 {object} response.Response{data=[]model.FaultRule} "更新成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Router /api/v1/admin/faults [put]
+func (h *FaultHandler) ReplaceFaults(c *gin.Context) {
+	var req model.FaultRulesRequest
+	if !bindJSON(c, h.logger, &req) {
+		return
+	}
+
+	rules := make([]config.FaultRule, 0, len(req.Rules))
+	for i, rule := range req.Rules {
+		converted, err := toFaultRuleConfig(rule)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, fmt.Sprintf("请求参数验证失败: rules[%d]: %v", i, err))
+			return
+		}
+		rules = append(rules, converted)
+	}
+	if err := h.injector.SetRules(rules); err != nil {
+		response.Error(c, http.StatusBadRequest, "请求参数验证失败: "+err.Error())
+		return
+	}
+
+	h.logger.Warn("Fault injection rules replaced", zap.Int("rules", len(rules)))
+	response.SuccessWithMsg(c, http.StatusOK, "更新成功", h.rules())
+}
+
+// ClearFaults 清除故障注入规则
+// @Summary 清除故障注入规则
+// @Description 清除当前实例的所有故障注入规则
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response "清除成功"
+// @Router /api/v1/admin/faults [delete]
+func (h *FaultHandler) ClearFaults(c *gin.Context) {
+	_ = h.injector.SetRules(nil)
+	h.logger.Warn("Fault injection rules cleared")
+	response.SuccessWithMsg(c, http.StatusOK, "清除成功", nil)
+}
+
+// rules 返回当前规则
+func (h *FaultHandler) rules() []model.FaultRule {
+	rules := h.injector.Rules()
+	result := make([]model.FaultRule, 0, len(rules))
+	for _, rule := range rules {
+		result = append(result, toFaultRuleModel(rule))
+	}
+	return result
+}
+
+// toFaultRuleConfig 将请求中的规则转换为配置结构，延迟使用 Go duration 格式
+func toFaultRuleConfig(rule model.FaultRule) (config.FaultRule, error) {
+	result := config.FaultRule{
+		Path:        rule.Path,
+		Methods:     rule.Methods,
+		ErrorRate:   rule.ErrorRate,
+		ErrorStatus: rule.ErrorStatus,
+		DropRate:    rule.DropRate,
+	}
+
+	var err error
+	if rule.Latency != "" {
+		if result.Latency, err = time.ParseDuration(rule.Latency); err != nil {
+			return result, fmt.Errorf("invalid latency %q", rule.Latency)
+		}
+	}
+	if rule.Jitter != "" {
+		if result.Jitter, err = time.ParseDuration(rule.Jitter); err != nil {
+			return result, fmt.Errorf("invalid jitter %q", rule.Jitter)
+		}
+	}
+	return result, nil
+}
+
+// toFaultRuleModel 将配置中的规则转换为响应结构
+func toFaultRuleModel(rule config.FaultRule) model.FaultRule {
+	result := model.FaultRule{
+		Path:        rule.Path,
+		Methods:     rule.Methods,
+		ErrorRate:   rule.ErrorRate,
+		ErrorStatus: rule.ErrorStatus,
+		DropRate:    rule.DropRate,
+	}
+	if rule.Latency > 0 {
+		result.Latency = rule.Latency.String()
+	}
+	if rule.Jitter > 0 {
+		result.Jitter = rule.Jitter.String()
+	}
+	return result
+}
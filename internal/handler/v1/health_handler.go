@@ -0,0 +1,54 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hedeqiang/skeleton/pkg/health"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// HealthHandler 依赖健康检查处理器
+type HealthHandler struct {
+	registry *health.Registry
+	logger   *zap.Logger
+}
+
+// NewHealthHandler 创建依赖健康检查处理器实例
+func NewHealthHandler(registry *health.Registry, logger *zap.Logger) *HealthHandler {
+	return &HealthHandler{
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+// GetHealth 查询依赖健康状态
+// @Summary 查询依赖健康状态
+// @Description 并发检查数据库、Redis、RabbitMQ 等依赖，返回每项的状态、级别和耗时；任一 critical 检查失败时整体为 unhealthy，仅 optional 检查失败时为 degraded
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=health.Report} "healthy 或 degraded"
+// @Failure 503 {object} response.Response{data=health.Report} "unhealthy"
+// @Router /api/v1/admin/health [get]
+func (h *HealthHandler) GetHealth(c *gin.Context) {
+	report := h.registry.Check(c.Request.Context())
+
+	for name, result := range report.Checks {
+		if result.Status == health.CheckDown {
+			h.logger.Warn("Health check failed",
+				zap.String("check", name),
+				zap.String("level", string(result.Level)),
+				zap.String("error", result.Error),
+			)
+		}
+	}
+
+	if report.Status == health.StatusUnhealthy {
+		response.ResultWithStatus(http.StatusServiceUnavailable, response.ErrorCode, report.Status, report, c)
+		return
+	}
+	response.SuccessWithMsg(c, http.StatusOK, report.Status, report)
+}
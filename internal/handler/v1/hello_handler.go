@@ -8,7 +8,6 @@ import (
 	"github.com/hedeqiang/skeleton/pkg/response"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 )
 
@@ -16,7 +15,6 @@ import (
 type HelloHandler struct {
 	helloService service.HelloService
 	logger       *zap.Logger
-	validator    *validator.Validate
 }
 
 // NewHelloHandler 创建Hello消息处理器实例
@@ -24,7 +22,6 @@ func NewHelloHandler(helloService service.HelloService, logger *zap.Logger) *Hel
 	return &HelloHandler{
 		helloService: helloService,
 		logger:       logger,
-		validator:    validator.New(),
 	}
 }
 
@@ -41,16 +38,7 @@ func NewHelloHandler(helloService service.HelloService, logger *zap.Logger) *Hel
 // @Router /api/v1/hello/publish [post]
 func (h *HelloHandler) PublishHelloMessage(c *gin.Context) {
 	var req model.PublishHelloRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Failed to bind JSON", zap.Error(err))
-		response.Error(c, http.StatusBadRequest, "请求参数格式错误")
-		return
-	}
-
-	// 参数验证
-	if err := h.validator.Struct(&req); err != nil {
-		h.logger.Error("Validation failed", zap.Error(err))
-		response.Error(c, http.StatusBadRequest, "请求参数验证失败: "+err.Error())
+	if !bindJSON(c, h.logger, &req) {
 		return
 	}
 
@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// IdentityHandler 外部账号关联处理器
+type IdentityHandler struct {
+	identityService service.IdentityService
+	logger          *zap.Logger
+}
+
+// NewIdentityHandler 创建外部账号关联处理器实例
+func NewIdentityHandler(identityService service.IdentityService, logger *zap.Logger) *IdentityHandler {
+	return &IdentityHandler{
+		identityService: identityService,
+		logger:          logger,
+	}
+}
+
+// ListIdentities 获取关联的外部账号
+// @Summary 获取关联的外部账号
+// @Description 返回用户通过 OAuth 登录关联的全部外部账号，不包含外部令牌
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=[]model.UserIdentityResponse} "获取成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/{id}/identities [get]
+func (h *IdentityHandler) ListIdentities(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	identities, err := h.identityService.List(c.Request.Context(), uint(id))
+	if err != nil {
+		h.logger.Error("Failed to list user identities", zap.Uint64("user_id", id), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to list user identities")
+		return
+	}
+
+	response.Success(c, identities)
+}
+
+// UnlinkIdentity 解除外部账号关联
+// @Summary 解除外部账号关联
+// @Description 解除关联并删除保存的外部令牌，之后不能再通过该外部账号登录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param identity_id path int true "关联ID"
+// @Success 200 {object} response.Response "已解除关联"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "关联不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/{id}/identities/{identity_id} [delete]
+func (h *IdentityHandler) UnlinkIdentity(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+	identityID, err := strconv.ParseUint(c.Param("identity_id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "关联ID格式错误")
+		return
+	}
+
+	if err := h.identityService.Unlink(c.Request.Context(), uint(id), uint(identityID)); err != nil {
+		h.logger.Error("Failed to unlink user identity", zap.Uint64("user_id", id), zap.Uint64("identity_id", identityID), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to unlink user identity")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "已解除关联", nil)
+}
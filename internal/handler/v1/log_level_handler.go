@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	loggerpkg "github.com/hedeqiang/skeleton/pkg/logger"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// maxLoggerNameLength logger 名称最大长度
+const maxLoggerNameLength = 64
+
+// LogLevelHandler 日志级别管理处理器
+type LogLevelHandler struct {
+	logger *zap.Logger
+}
+
+// NewLogLevelHandler 创建日志级别管理处理器实例
+func NewLogLevelHandler(logger *zap.Logger) *LogLevelHandler {
+	return &LogLevelHandler{logger: logger}
+}
+
+// GetLogLevels 查询日志级别
+// @Summary 查询日志级别
+// @Description 返回当前实例的全局日志级别和单独设置了级别的命名 logger（如 mq、gorm、scheduler）
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=model.LogLevels} "获取成功"
+// @Router /api/v1/admin/log-levels [get]
+func (h *LogLevelHandler) GetLogLevels(c *gin.Context) {
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", h.levels())
+}
+
+// SetLogLevel 设置命名 logger 的日志级别
+// @Summary 设置命名 logger 的日志级别
+// @Description 立即生效，同时作用于以该名称为前缀的子 logger（如 mq 作用于 mq.consumer）。只影响当前实例，SIGHUP 重新加载配置或重启后恢复为配置文件中的级别
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "logger 名称" example(mq)
+// @Param level body model.LogLevelRequest true "日志级别"
+// @Success 200 {object} response.Response{data=model.LogLevels} "更新成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Router /api/v1/admin/log-levels/{name} [put]
+func (h *LogLevelHandler) SetLogLevel(c *gin.Context) {
+	name, ok := h.loggerName(c)
+	if !ok {
+		return
+	}
+	var req model.LogLevelRequest
+	if !bindJSON(c, h.logger, &req) {
+		return
+	}
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "请求参数验证失败: "+err.Error())
+		return
+	}
+
+	loggerpkg.SetNamedLevel(name, level)
+	h.logger.Warn("Log level changed", zap.String("logger", name), zap.String("level", level.String()))
+	response.SuccessWithMsg(c, http.StatusOK, "更新成功", h.levels())
+}
+
+// ResetLogLevel 恢复命名 logger 的日志级别
+// @Summary 恢复命名 logger 的日志级别
+// @Description 恢复为配置文件 logger.levels 中的级别，未配置时使用全局级别
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param name path string true "logger 名称" example(mq)
+// @Success 200 {object} response.Response{data=model.LogLevels} "恢复成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Router /api/v1/admin/log-levels/{name} [delete]
+func (h *LogLevelHandler) ResetLogLevel(c *gin.Context) {
+	name, ok := h.loggerName(c)
+	if !ok {
+		return
+	}
+
+	loggerpkg.ResetNamedLevel(name)
+	h.logger.Warn("Log level reset", zap.String("logger", name))
+	response.SuccessWithMsg(c, http.StatusOK, "恢复成功", h.levels())
+}
+
+// loggerName 校验路径中的 logger 名称，只接受小写字母、数字和 ._- 字符
+func (h *LogLevelHandler) loggerName(c *gin.Context) (string, bool) {
+	name := c.Param("name")
+	valid := name != "" && len(name) <= maxLoggerNameLength
+	for i := 0; valid && i < len(name); i++ {
+		ch := name[i]
+		valid = ch >= 'a' && ch <= 'z' || ch >= '0' && ch <= '9' || ch == '.' || ch == '_' || ch == '-'
+	}
+	if !valid {
+		response.Error(c, http.StatusBadRequest, "请求参数验证失败: 无效的 logger 名称")
+		return "", false
+	}
+	return name, true
+}
+
+// levels 返回当前日志级别
+func (h *LogLevelHandler) levels() model.LogLevels {
+	named := loggerpkg.NamedLevels()
+	loggers := make(map[string]string, len(named))
+	for name, level := range named {
+		loggers[name] = level.String()
+	}
+	return model.LogLevels{Level: loggerpkg.Level().String(), Loggers: loggers}
+}
@@ -0,0 +1,45 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// MigrationHandler 数据库迁移状态处理器
+type MigrationHandler struct {
+	migrationService service.MigrationService
+	logger           *zap.Logger
+}
+
+// NewMigrationHandler 创建数据库迁移状态处理器实例
+func NewMigrationHandler(migrationService service.MigrationService, logger *zap.Logger) *MigrationHandler {
+	return &MigrationHandler{
+		migrationService: migrationService,
+		logger:           logger,
+	}
+}
+
+// GetMigrations 查询数据库迁移状态
+// @Summary 查询数据库迁移状态
+// @Description 返回每个数据源当前的结构版本、已执行和待执行的迁移
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]model.MigrationStatus} "获取成功"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/admin/migrations [get]
+func (h *MigrationHandler) GetMigrations(c *gin.Context) {
+	statuses, err := h.migrationService.Status(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to get migration status", zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, "Failed to get migration status")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", statuses)
+}
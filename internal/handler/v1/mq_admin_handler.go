@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// MQAdminHandler 消息队列拓扑查看与冒烟测试处理器
+type MQAdminHandler struct {
+	mqAdminService service.MQAdminService
+	logger         *zap.Logger
+}
+
+// NewMQAdminHandler 创建消息队列拓扑查看与冒烟测试处理器实例
+func NewMQAdminHandler(mqAdminService service.MQAdminService, logger *zap.Logger) *MQAdminHandler {
+	return &MQAdminHandler{
+		mqAdminService: mqAdminService,
+		logger:         logger,
+	}
+}
+
+// GetQueues 查询队列
+// @Summary 查询消息队列
+// @Description 返回配置的队列，RabbitMQ 队列通过被动声明查询消息数和消费者数；redis、nats 驱动的队列状态为 unsupported
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]model.MQQueue} "获取成功"
+// @Router /api/v1/admin/mq/queues [get]
+func (h *MQAdminHandler) GetQueues(c *gin.Context) {
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", h.mqAdminService.Queues(c.Request.Context()))
+}
+
+// GetExchanges 查询交换机
+// @Summary 查询消息交换机
+// @Description 返回配置的交换机及绑定的队列，RabbitMQ 交换机通过被动声明检查是否存在
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]model.MQExchange} "获取成功"
+// @Router /api/v1/admin/mq/exchanges [get]
+func (h *MQAdminHandler) GetExchanges(c *gin.Context) {
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", h.mqAdminService.Exchanges(c.Request.Context()))
+}
+
+// PublishTest 发布测试消息
+// @Summary 发布测试消息
+// @Description 以事件信封向配置的交换机发布一条带 x-smoke-test 消息头的消息并等待 broker 确认，用于上线后的冒烟测试；消息会被正常消费
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param message body model.MQTestPublishRequest true "测试消息"
+// @Success 200 {object} response.Response{data=model.MQTestPublishResponse} "发布成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "交换机不存在"
+// @Failure 500 {object} response.Response "发布失败或消息无法路由"
+// @Router /api/v1/admin/mq/publish [post]
+func (h *MQAdminHandler) PublishTest(c *gin.Context) {
+	var req model.MQTestPublishRequest
+	if !bindJSON(c, h.logger, &req) {
+		return
+	}
+
+	result, err := h.mqAdminService.PublishTest(c.Request.Context(), &req)
+	if err != nil {
+		h.logger.Error("Failed to publish test message", zap.String("exchange", req.Exchange), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to publish test message")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "发布成功", result)
+}
@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PrivacyHandler 用户数据导出与账户删除处理器
+type PrivacyHandler struct {
+	privacyService service.PrivacyService
+	logger         *zap.Logger
+}
+
+// NewPrivacyHandler 创建用户数据导出与账户删除处理器实例
+func NewPrivacyHandler(privacyService service.PrivacyService, logger *zap.Logger) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+		logger:         logger,
+	}
+}
+
+// ExportUser 导出用户数据
+// @Summary 导出用户数据
+// @Description 创建后台任务导出用户的全部数据，完成后任务的 result_url 为有时效的 ZIP 下载地址
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 202 {object} response.Response{data=model.TaskResponse} "任务已创建"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误或未配置下载签名密钥"
+// @Router /api/v1/users/{id}/export [post]
+func (h *PrivacyHandler) ExportUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	task, err := h.privacyService.StartExport(c.Request.Context(), uint(id))
+	if err != nil {
+		h.logger.Error("Failed to start user export", zap.Uint64("user_id", id), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to start user export")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusAccepted, "任务已创建", task)
+}
+
+// RequestDeletion 申请删除账户
+// @Summary 申请删除账户
+// @Description 宽限期后彻底删除账户及其关联数据，宽限期内可以撤销，重复申请返回已有的删除时间
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 202 {object} response.Response{data=model.UserDeletion} "已申请删除"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/{id}/deletion [post]
+func (h *PrivacyHandler) RequestDeletion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	deletion, err := h.privacyService.RequestDeletion(c.Request.Context(), uint(id))
+	if err != nil {
+		h.logger.Error("Failed to request user deletion", zap.Uint64("user_id", id), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to request user deletion")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusAccepted, "已申请删除", deletion)
+}
+
+// CancelDeletion 撤销删除申请
+// @Summary 撤销删除申请
+// @Description 在宽限期内撤销账户删除申请
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response "已撤销"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "用户不存在或未申请删除"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/{id}/deletion [delete]
+func (h *PrivacyHandler) CancelDeletion(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	if err := h.privacyService.CancelDeletion(c.Request.Context(), uint(id)); err != nil {
+		h.logger.Error("Failed to cancel user deletion", zap.Uint64("user_id", id), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to cancel user deletion")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "已撤销", nil)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/hedeqiang/skeleton/internal/scheduler"
+	"github.com/hedeqiang/skeleton/pkg/metrics"
 	"github.com/hedeqiang/skeleton/pkg/response"
 
 	"github.com/gin-gonic/gin"
@@ -45,6 +46,23 @@ func (h *SchedulerHandler) GetJobs(c *gin.Context) {
 	})
 }
 
+// GetChains 获取任务链列表
+// @Summary 获取任务链执行状态
+// @Description 获取按 after 配置组成的任务链，以及每条链最近的执行记录和各任务的状态
+// @Tags scheduler
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]scheduler.ChainInfo}
+// @Router /api/v1/scheduler/chains [get]
+func (h *SchedulerHandler) GetChains(c *gin.Context) {
+	chains := h.jobRegistry.GetChainsStatus()
+
+	response.Success(c, gin.H{
+		"chains":       chains,
+		"chains_count": len(chains),
+	})
+}
+
 // StartScheduler 启动调度器
 // @Summary 启动计划任务调度器
 // @Description 启动计划任务调度器服务
@@ -84,3 +102,14 @@ func (h *SchedulerHandler) StopScheduler(c *gin.Context) {
 	h.logger.Info("Scheduler stopped via API")
 	response.Success(c, "Scheduler stopped successfully")
 }
+
+// Metrics 导出 Prometheus 指标
+// @Summary 获取计划任务指标
+// @Description 以 Prometheus 文本格式导出任务执行次数、耗时和最近成功时间
+// @Tags scheduler
+// @Produce plain
+// @Success 200 {string} string
+// @Router /api/v1/scheduler/metrics [get]
+func (h *SchedulerHandler) Metrics(c *gin.Context) {
+	metrics.Handler().ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxSearchQueryLength 搜索关键字的最大长度
+const maxSearchQueryLength = 100
+
+// SearchHandler 搜索处理器
+type SearchHandler struct {
+	searchService service.SearchService
+	logger        *zap.Logger
+}
+
+// NewSearchHandler 创建搜索处理器实例，未启用搜索时返回 nil，搜索路由不会注册
+func NewSearchHandler(searchService service.SearchService, logger *zap.Logger) *SearchHandler {
+	if searchService == nil {
+		return nil
+	}
+	return &SearchHandler{
+		searchService: searchService,
+		logger:        logger,
+	}
+}
+
+// SearchUsers 搜索用户
+// @Summary 搜索用户
+// @Description 按用户名和邮箱全文搜索用户，索引由用户领域事件异步更新
+// @Tags 搜索
+// @Accept json
+// @Produce json
+// @Param q query string false "关键字，为空时按创建时间倒序返回全部用户"
+// @Param status query int false "用户状态 1-正常 0-禁用"
+// @Param page query int false "页码" default(1)
+// @Param page_size query int false "每页数量" default(10)
+// @Success 200 {object} response.Response{data=response.PageResponse{list=[]model.UserResponse}} "获取成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/search/users [get]
+func (h *SearchHandler) SearchUsers(c *gin.Context) {
+	req := &model.SearchUsersRequest{Query: c.Query("q")}
+	if len([]rune(req.Query)) > maxSearchQueryLength {
+		response.Error(c, http.StatusBadRequest, "搜索关键字过长")
+		return
+	}
+	if raw := c.Query("status"); raw != "" {
+		status, err := strconv.Atoi(raw)
+		if err != nil || (status != 0 && status != 1) {
+			response.Error(c, http.StatusBadRequest, "用户状态无效")
+			return
+		}
+		req.Status = &status
+	}
+	req.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	req.PageSize, _ = strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	users, total, err := h.searchService.SearchUsers(c.Request.Context(), req)
+	if err != nil {
+		h.logger.Error("Failed to search users", zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to search users")
+		return
+	}
+
+	pageResp := response.PageResponse{
+		List:     users,
+		Total:    total,
+		Page:     req.Page,
+		PageSize: req.PageSize,
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", pageResp)
+}
+
+// Reindex 重建搜索索引
+// @Summary 重建搜索索引
+// @Description 创建后台任务，从数据库分批读取数据写入新版本索引，完成后原子切换别名并删除旧索引，进度通过任务接口查询
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param index query string false "索引逻辑名称" default(users)
+// @Success 202 {object} response.Response{data=model.TaskResponse} "任务已创建"
+// @Failure 404 {object} response.Response "索引不存在"
+// @Failure 409 {object} response.Response "已有重建任务在执行"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/admin/search/reindex [post]
+func (h *SearchHandler) Reindex(c *gin.Context) {
+	index := c.DefaultQuery("index", service.UserIndex.Name)
+
+	task, err := h.searchService.StartReindex(c.Request.Context(), index)
+	if err != nil {
+		h.logger.Error("Failed to start reindex", zap.String("index", index), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to start reindex")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusAccepted, "任务已创建", task)
+}
@@ -0,0 +1,35 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SLOHandler 服务等级目标处理器
+type SLOHandler struct {
+	slo *metrics.SLOMetrics
+}
+
+// NewSLOHandler 创建服务等级目标处理器实例，未开启 slo 时返回 nil，管理路由不会注册
+func NewSLOHandler(slo *metrics.SLOMetrics) *SLOHandler {
+	if slo == nil {
+		return nil
+	}
+	return &SLOHandler{slo: slo}
+}
+
+// GetSLO 查询错误预算
+// @Summary 查询错误预算
+// @Description 返回每个服务等级目标各 SLI 在各窗口内的请求数、错误率和燃烧率，以及最长窗口内剩余的错误预算；统计只覆盖当前实例
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Success 200 {object} response.Response{data=[]metrics.SLOReport} "获取成功"
+// @Router /api/v1/admin/slo [get]
+func (h *SLOHandler) GetSLO(c *gin.Context) {
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", h.slo.Report())
+}
@@ -0,0 +1,87 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultStatsDays 未指定 from 时返回的天数
+	defaultStatsDays = 30
+	// maxStatsDays 单次查询的最大天数
+	maxStatsDays = 366
+)
+
+// StatsHandler 统计处理器
+type StatsHandler struct {
+	statsService service.StatsService
+	clock        clock.Clock
+	logger       *zap.Logger
+}
+
+// NewStatsHandler 创建统计处理器实例
+func NewStatsHandler(statsService service.StatsService, clk clock.Clock, logger *zap.Logger) *StatsHandler {
+	return &StatsHandler{
+		statsService: statsService,
+		clock:        clk,
+		logger:       logger,
+	}
+}
+
+// GetDailyStats 查询每日统计
+// @Summary 查询每日统计
+// @Description 返回日期范围内由 daily_stats_job 预先计算的每日新增用户、活跃用户、登录次数和消息处理量，未计算的日期不返回
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param from query string false "开始日期 YYYY-MM-DD，默认 to 之前 29 天"
+// @Param to query string false "结束日期 YYYY-MM-DD（包含），默认昨天"
+// @Success 200 {object} response.Response{data=[]model.DailyStats} "获取成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/admin/stats/daily [get]
+func (h *StatsHandler) GetDailyStats(c *gin.Context) {
+	to := h.clock.Now().AddDate(0, 0, -1)
+	if raw := c.Query("to"); raw != "" {
+		parsed, err := time.ParseInLocation(model.StatsDateLayout, raw, time.Local)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "结束日期格式应为 YYYY-MM-DD")
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(defaultStatsDays - 1))
+	if raw := c.Query("from"); raw != "" {
+		parsed, err := time.ParseInLocation(model.StatsDateLayout, raw, time.Local)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, "开始日期格式应为 YYYY-MM-DD")
+			return
+		}
+		from = parsed
+	}
+	if from.After(to) {
+		response.Error(c, http.StatusBadRequest, "开始日期不能晚于结束日期")
+		return
+	}
+	if to.Sub(from) >= maxStatsDays*24*time.Hour {
+		response.Error(c, http.StatusBadRequest, "查询范围不能超过 366 天")
+		return
+	}
+
+	stats, err := h.statsService.ListDaily(c.Request.Context(), from, to)
+	if err != nil {
+		h.logger.Error("Failed to list daily stats", zap.Error(err))
+		response.Error(c, http.StatusInternalServerError, "Failed to list daily stats")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", stats)
+}
@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// rangeStatsService 记录查询的日期范围
+type rangeStatsService struct {
+	service.StatsService
+	from, to string
+}
+
+func (s *rangeStatsService) ListDaily(ctx context.Context, from, to time.Time) ([]*model.DailyStats, error) {
+	s.from, s.to = from.Format(model.StatsDateLayout), to.Format(model.StatsDateLayout)
+	return []*model.DailyStats{}, nil
+}
+
+func TestGetDailyStats(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	now := time.Date(2025, time.March, 15, 8, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name     string
+		query    string
+		status   int
+		from, to string
+	}{
+		{"defaults to last 30 days", "", http.StatusOK, "2025-02-13", "2025-03-14"},
+		{"explicit range", "?from=2025-01-01&to=2025-01-31", http.StatusOK, "2025-01-01", "2025-01-31"},
+		{"invalid date", "?from=2025/01/01", http.StatusBadRequest, "", ""},
+		{"from after to", "?from=2025-02-01&to=2025-01-01", http.StatusBadRequest, "", ""},
+		{"range too long", "?from=2023-01-01&to=2025-01-01", http.StatusBadRequest, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stats := &rangeStatsService{}
+			h := NewStatsHandler(stats, clock.NewFake(now), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/admin/stats/daily"+tt.query, nil)
+			h.GetDailyStats(c)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d, body = %s", w.Code, tt.status, w.Body.String())
+			}
+			if stats.from != tt.from || stats.to != tt.to {
+				t.Errorf("range = %s..%s, want %s..%s", stats.from, stats.to, tt.from, tt.to)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// TaskHandler 后台任务处理器
+type TaskHandler struct {
+	taskService service.TaskService
+	logger      *zap.Logger
+}
+
+// NewTaskHandler 创建后台任务处理器实例
+func NewTaskHandler(taskService service.TaskService, logger *zap.Logger) *TaskHandler {
+	return &TaskHandler{
+		taskService: taskService,
+		logger:      logger,
+	}
+}
+
+// GetTask 查询后台任务进度
+// @Summary 查询后台任务进度
+// @Description 根据任务ID查询长耗时任务的状态、完成百分比、结果地址和错误信息；只有发起任务的用户和管理员可以查看，结果下载地址在查询时签发
+// @Tags 后台任务
+// @Accept json
+// @Produce json
+// @Param id path string true "任务ID"
+// @Success 200 {object} response.Response{data=model.TaskResponse} "获取成功"
+// @Failure 401 {object} response.Response "未登录"
+// @Failure 403 {object} response.Response "无权查看"
+// @Failure 404 {object} response.Response "任务不存在"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/tasks/{id} [get]
+func (h *TaskHandler) GetTask(c *gin.Context) {
+	id := c.Param("id")
+
+	task, err := h.taskService.GetTask(c.Request.Context(), id)
+	if err != nil {
+		h.logger.Error("Failed to get task", zap.String("task_id", id), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to get task")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", task)
+}
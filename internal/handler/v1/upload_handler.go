@@ -0,0 +1,207 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ChunkChecksumHeader 分片 SHA-256 校验和请求头
+const ChunkChecksumHeader = "X-Chunk-Checksum"
+
+// UploadHandler 分片上传处理器
+type UploadHandler struct {
+	uploadService service.UploadService
+	logger        *zap.Logger
+}
+
+// NewUploadHandler 创建分片上传处理器实例
+func NewUploadHandler(uploadService service.UploadService, logger *zap.Logger) *UploadHandler {
+	return &UploadHandler{
+		uploadService: uploadService,
+		logger:        logger,
+	}
+}
+
+// CreateUpload 创建分片上传会话
+// @Summary 创建分片上传会话
+// @Description 声明文件名、大小和可选的 SHA-256，返回上传 ID 和分片大小；同一用户未完成的会话数和总大小有上限
+// @Tags 文件上传
+// @Accept json
+// @Produce json
+// @Param upload body model.CreateUploadRequest true "文件信息"
+// @Success 201 {object} response.Response{data=model.UploadSession} "创建成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "未登录"
+// @Failure 429 {object} response.Response "未完成的上传过多"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/uploads [post]
+func (h *UploadHandler) CreateUpload(c *gin.Context) {
+	userID, ok := uploadUser(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateUploadRequest
+	if !bindJSON(c, h.logger, &req) {
+		return
+	}
+
+	session, err := h.uploadService.CreateUpload(c.Request.Context(), userID, &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to create upload")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusCreated, "上传会话创建成功", session)
+}
+
+// GetUpload 查询上传进度
+// @Summary 查询上传进度
+// @Description 返回已上传的分片序号，客户端据此续传缺失的分片
+// @Tags 文件上传
+// @Produce json
+// @Param id path string true "上传ID"
+// @Success 200 {object} response.Response{data=model.UploadSession} "获取成功"
+// @Failure 401 {object} response.Response "未登录"
+// @Failure 404 {object} response.Response "上传会话不存在"
+// @Router /api/v1/uploads/{id} [get]
+func (h *UploadHandler) GetUpload(c *gin.Context) {
+	userID, ok := uploadUser(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.uploadService.GetUpload(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to get upload")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "获取成功", session)
+}
+
+// UploadChunk 上传分片
+// @Summary 上传分片
+// @Description 请求体为分片原始内容，可通过 X-Chunk-Checksum 头提供分片 SHA-256
+// @Tags 文件上传
+// @Accept application/octet-stream
+// @Produce json
+// @Param id path string true "上传ID"
+// @Param index path int true "分片序号（从 0 开始）"
+// @Param X-Chunk-Checksum header string false "分片 SHA-256"
+// @Success 200 {object} response.Response{data=model.UploadSession} "上传成功"
+// @Failure 400 {object} response.Response "分片大小或校验和错误"
+// @Failure 401 {object} response.Response "未登录"
+// @Failure 404 {object} response.Response "上传会话不存在"
+// @Router /api/v1/uploads/{id}/chunks/{index} [put]
+func (h *UploadHandler) UploadChunk(c *gin.Context) {
+	userID, ok := uploadUser(c)
+	if !ok {
+		return
+	}
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "分片序号格式错误")
+		return
+	}
+
+	session, err := h.uploadService.UploadChunk(
+		c.Request.Context(),
+		userID,
+		c.Param("id"),
+		index,
+		c.GetHeader(ChunkChecksumHeader),
+		c.Request.Body,
+	)
+	if err != nil {
+		h.handleError(c, err, "Failed to upload chunk")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "上传成功", session)
+}
+
+// CompleteUpload 完成上传
+// @Summary 完成上传
+// @Description 合并所有分片并校验整个文件的 SHA-256，合并后的文件保留到 expires_at
+// @Tags 文件上传
+// @Produce json
+// @Param id path string true "上传ID"
+// @Success 200 {object} response.Response{data=model.UploadResult} "上传完成"
+// @Failure 400 {object} response.Response "文件校验失败"
+// @Failure 401 {object} response.Response "未登录"
+// @Failure 404 {object} response.Response "上传会话不存在"
+// @Failure 409 {object} response.Response "分片未上传完整"
+// @Router /api/v1/uploads/{id}/complete [post]
+func (h *UploadHandler) CompleteUpload(c *gin.Context) {
+	userID, ok := uploadUser(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.uploadService.CompleteUpload(c.Request.Context(), userID, c.Param("id"))
+	if err != nil {
+		h.handleError(c, err, "Failed to complete upload")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "上传完成", result)
+}
+
+// AbortUpload 取消上传
+// @Summary 取消上传
+// @Description 删除上传会话和已上传的分片
+// @Tags 文件上传
+// @Produce json
+// @Param id path string true "上传ID"
+// @Success 200 {object} response.Response "取消成功"
+// @Failure 401 {object} response.Response "未登录"
+// @Failure 404 {object} response.Response "上传会话不存在"
+// @Router /api/v1/uploads/{id} [delete]
+func (h *UploadHandler) AbortUpload(c *gin.Context) {
+	userID, ok := uploadUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.uploadService.AbortUpload(c.Request.Context(), userID, c.Param("id")); err != nil {
+		h.handleError(c, err, "Failed to abort upload")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "取消成功", nil)
+}
+
+// uploadUser 返回认证中间件写入的用户 ID，未认证时返回 401
+// 会话按用户隔离，未开启认证时也不允许匿名上传
+func uploadUser(c *gin.Context) (uint, bool) {
+	userID, ok := c.Get("UserID")
+	if !ok {
+		response.AppError(c, errors.ErrTokenMissing)
+		return 0, false
+	}
+	id, _ := userID.(uint)
+	return id, true
+}
+
+// handleError 输出上传相关错误
+func (h *UploadHandler) handleError(c *gin.Context, err error, message string) {
+	if appErr, ok := err.(*errors.AppError); ok {
+		if appErr.StatusCode() >= http.StatusInternalServerError {
+			h.logger.Error(message, zap.String("upload_id", c.Param("id")), zap.Error(err))
+		}
+		response.AppError(c, appErr)
+		return
+	}
+	h.logger.Error(message, zap.String("upload_id", c.Param("id")), zap.Error(err))
+	response.Error(c, http.StatusInternalServerError, message)
+}
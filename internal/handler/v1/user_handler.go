@@ -9,7 +9,6 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 	"go.uber.org/zap"
 )
 
@@ -17,7 +16,6 @@ import (
 type UserHandler struct {
 	userService service.UserService
 	logger      *zap.Logger
-	validator   *validator.Validate
 }
 
 // NewUserHandler 创建用户处理器实例
@@ -25,7 +23,6 @@ func NewUserHandler(userService service.UserService, logger *zap.Logger) *UserHa
 	return &UserHandler{
 		userService: userService,
 		logger:      logger,
-		validator:   validator.New(),
 	}
 }
 
@@ -38,20 +35,12 @@ func NewUserHandler(userService service.UserService, logger *zap.Logger) *UserHa
 // @Param user body model.CreateUserRequest true "用户信息"
 // @Success 201 {object} response.Response{data=model.UserResponse} "创建成功"
 // @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 409 {object} response.Response "用户已存在"
 // @Failure 500 {object} response.Response "服务器内部错误"
 // @Router /api/v1/users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var req model.CreateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Failed to bind JSON", zap.Error(err))
-		response.Error(c, http.StatusBadRequest, "请求参数格式错误")
-		return
-	}
-
-	// 参数验证
-	if err := h.validator.Struct(&req); err != nil {
-		h.logger.Error("Validation failed", zap.Error(err))
-		response.Error(c, http.StatusBadRequest, "请求参数验证失败: "+err.Error())
+	if !bindJSON(c, h.logger, &req) {
 		return
 	}
 
@@ -59,7 +48,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 	if err != nil {
 		h.logger.Error("Failed to create user", zap.Error(err))
 		if appErr, ok := err.(*errors.AppError); ok {
-			response.Error(c, appErr.StatusCode(), appErr.Message)
+			response.AppError(c, appErr)
 			return
 		}
 		response.Error(c, http.StatusInternalServerError, "Failed to create user")
@@ -69,6 +58,38 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 	response.SuccessWithMsg(c, http.StatusCreated, "用户创建成功", user)
 }
 
+// ReserveUser 预占用户名和邮箱
+// @Summary 预占用户名和邮箱
+// @Description 多步骤注册时预占用户名和邮箱，在有效期内其他请求无法使用；创建用户时通过 reservation_token 携带返回的 token
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param reservation body model.ReserveUserRequest true "用户名和邮箱"
+// @Success 201 {object} response.Response{data=model.UserReservation} "预占成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 409 {object} response.Response "用户名或邮箱已被使用"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/reservations [post]
+func (h *UserHandler) ReserveUser(c *gin.Context) {
+	var req model.ReserveUserRequest
+	if !bindJSON(c, h.logger, &req) {
+		return
+	}
+
+	reservation, err := h.userService.ReserveUser(c.Request.Context(), &req)
+	if err != nil {
+		h.logger.Error("Failed to reserve user", zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to reserve user")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusCreated, "预占成功", reservation)
+}
+
 // GetUser 获取用户信息
 // @Summary 获取用户信息
 // @Description 根据用户ID获取用户信息
@@ -93,12 +114,8 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 	user, err := h.userService.GetUser(c.Request.Context(), uint(id))
 	if err != nil {
 		h.logger.Error("Failed to get user", zap.Error(err))
-		if errors.IsNotFoundError(err) {
-			response.Error(c, http.StatusNotFound, "用户不存在")
-			return
-		}
 		if appErr, ok := err.(*errors.AppError); ok {
-			response.Error(c, appErr.StatusCode(), appErr.Message)
+			response.AppError(c, appErr)
 			return
 		}
 		response.Error(c, http.StatusInternalServerError, "Failed to get user")
@@ -110,7 +127,7 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 
 // UpdateUser 更新用户信息
 // @Summary 更新用户信息
-// @Description 根据用户ID更新用户信息
+// @Description 根据用户ID更新用户信息，修改邮箱时向新邮箱发送确认令牌，确认后才生效，响应中的 pending_email 为待确认的新邮箱
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -130,28 +147,56 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	}
 
 	var req model.UpdateUserRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Failed to bind JSON", zap.Error(err))
-		response.Error(c, http.StatusBadRequest, "请求参数格式错误")
-		return
-	}
-
-	// 参数验证
-	if err := h.validator.Struct(&req); err != nil {
-		h.logger.Error("Validation failed", zap.Error(err))
-		response.Error(c, http.StatusBadRequest, "请求参数验证失败: "+err.Error())
+	if !bindJSON(c, h.logger, &req) {
 		return
 	}
 
 	user, err := h.userService.UpdateUser(c.Request.Context(), uint(id), &req)
 	if err != nil {
 		h.logger.Error("Failed to update user", zap.Error(err))
-		if errors.IsNotFoundError(err) {
-			response.Error(c, http.StatusNotFound, "用户不存在")
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
 			return
 		}
+		response.Error(c, http.StatusInternalServerError, "Failed to update user")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "更新成功", user)
+}
+
+// PatchUser 部分更新用户信息
+// @Summary 部分更新用户信息
+// @Description 按 JSON merge-patch 语义更新用户，只修改请求体中出现的字段，字段不允许为 null；修改邮箱需要确认，同 PUT
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param user body model.PatchUserRequest true "需要修改的字段"
+// @Success 200 {object} response.Response{data=model.UserResponse} "更新成功"
+// @Failure 400 {object} response.Response "请求参数错误或没有可更新的字段"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 409 {object} response.Response "用户名或邮箱已被使用"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/{id} [patch]
+func (h *UserHandler) PatchUser(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	var req model.PatchUserRequest
+	if !bindPatchJSON(c, h.logger, &req) {
+		return
+	}
+
+	user, err := h.userService.PatchUser(c.Request.Context(), uint(id), &req)
+	if err != nil {
+		h.logger.Error("Failed to patch user", zap.Error(err))
 		if appErr, ok := err.(*errors.AppError); ok {
-			response.Error(c, appErr.StatusCode(), appErr.Message)
+			response.AppError(c, appErr)
 			return
 		}
 		response.Error(c, http.StatusInternalServerError, "Failed to update user")
@@ -161,6 +206,116 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	response.SuccessWithMsg(c, http.StatusOK, "更新成功", user)
 }
 
+// ConfirmEmailChange 确认修改邮箱
+// @Summary 确认修改邮箱
+// @Description 使用发送到新邮箱的令牌确认修改，成功后新邮箱生效并通知原邮箱
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param confirmation body model.ConfirmEmailChangeRequest true "确认令牌"
+// @Success 200 {object} response.Response{data=model.UserResponse} "邮箱已修改"
+// @Failure 400 {object} response.Response "请求参数错误、令牌无效或已过期"
+// @Failure 404 {object} response.Response "用户不存在或未申请修改邮箱"
+// @Failure 409 {object} response.Response "新邮箱已被其他用户使用"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/{id}/email-change/confirm [post]
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	var req model.ConfirmEmailChangeRequest
+	if !bindJSON(c, h.logger, &req) {
+		return
+	}
+
+	user, err := h.userService.ConfirmEmailChange(c.Request.Context(), uint(id), req.Token)
+	if err != nil {
+		h.logger.Error("Failed to confirm email change", zap.Uint64("user_id", id), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to confirm email change")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "邮箱已修改", user)
+}
+
+// CancelEmailChange 撤销修改邮箱
+// @Summary 撤销修改邮箱
+// @Description 撤销待确认的邮箱修改，已发出的确认令牌失效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response "已撤销"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 404 {object} response.Response "用户不存在或未申请修改邮箱"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/{id}/email-change [delete]
+func (h *UserHandler) CancelEmailChange(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	if err := h.userService.CancelEmailChange(c.Request.Context(), uint(id)); err != nil {
+		h.logger.Error("Failed to cancel email change", zap.Uint64("user_id", id), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to cancel email change")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "已撤销", nil)
+}
+
+// ConfirmPasswordReset 重置密码
+// @Summary 重置密码
+// @Description 管理员强制重置密码后，凭邮件中的令牌设置新密码，之后可以用新密码登录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param id path int true "用户ID"
+// @Param request body model.ConfirmPasswordResetRequest true "重置令牌和新密码"
+// @Success 200 {object} response.Response "密码已重置"
+// @Failure 400 {object} response.Response "请求参数错误或令牌无效、已过期"
+// @Failure 404 {object} response.Response "用户不存在或未要求重置密码"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/users/{id}/password-reset/confirm [post]
+func (h *UserHandler) ConfirmPasswordReset(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, "用户ID格式错误")
+		return
+	}
+
+	var req model.ConfirmPasswordResetRequest
+	if !bindJSON(c, h.logger, &req) {
+		return
+	}
+
+	if err := h.userService.ConfirmPasswordReset(c.Request.Context(), uint(id), req.Token, req.Password); err != nil {
+		h.logger.Error("Failed to confirm password reset", zap.Uint64("user_id", id), zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to confirm password reset")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "密码已重置", nil)
+}
+
 // DeleteUser 删除用户
 // @Summary 删除用户
 // @Description 根据用户ID删除用户
@@ -184,12 +339,8 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	err = h.userService.DeleteUser(c.Request.Context(), uint(id))
 	if err != nil {
 		h.logger.Error("Failed to delete user", zap.Error(err))
-		if errors.IsNotFoundError(err) {
-			response.Error(c, http.StatusNotFound, "用户不存在")
-			return
-		}
 		if appErr, ok := err.(*errors.AppError); ok {
-			response.Error(c, appErr.StatusCode(), appErr.Message)
+			response.AppError(c, appErr)
 			return
 		}
 		response.Error(c, http.StatusInternalServerError, "Failed to delete user")
@@ -218,7 +369,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 	if err != nil {
 		h.logger.Error("Failed to list users", zap.Error(err))
 		if appErr, ok := err.(*errors.AppError); ok {
-			response.Error(c, appErr.StatusCode(), appErr.Message)
+			response.AppError(c, appErr)
 			return
 		}
 		response.Error(c, http.StatusInternalServerError, "Failed to list users")
@@ -237,28 +388,19 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 
 // Login 用户登录
 // @Summary 用户登录
-// @Description 用户登录验证
+// @Description 校验用户名和密码，返回用户信息、访问令牌和刷新令牌
 // @Tags 用户管理
 // @Accept json
 // @Produce json
 // @Param login body LoginRequest true "登录信息"
-// @Success 200 {object} response.Response{data=model.UserResponse} "登录成功"
+// @Success 200 {object} response.Response{data=model.LoginResponse} "登录成功"
 // @Failure 400 {object} response.Response "请求参数错误"
 // @Failure 401 {object} response.Response "用户名或密码错误"
 // @Failure 500 {object} response.Response "服务器内部错误"
 // @Router /api/v1/auth/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
 	var req LoginRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Failed to bind JSON", zap.Error(err))
-		response.Error(c, http.StatusBadRequest, "请求参数格式错误")
-		return
-	}
-
-	// 参数验证
-	if err := h.validator.Struct(&req); err != nil {
-		h.logger.Error("Validation failed", zap.Error(err))
-		response.Error(c, http.StatusBadRequest, "请求参数验证失败: "+err.Error())
+	if !bindJSON(c, h.logger, &req) {
 		return
 	}
 
@@ -270,7 +412,7 @@ func (h *UserHandler) Login(c *gin.Context) {
 			return
 		}
 		if appErr, ok := err.(*errors.AppError); ok {
-			response.Error(c, appErr.StatusCode(), appErr.Message)
+			response.AppError(c, appErr)
 			return
 		}
 		response.Error(c, http.StatusInternalServerError, "Failed to login")
@@ -280,8 +422,46 @@ func (h *UserHandler) Login(c *gin.Context) {
 	response.SuccessWithMsg(c, http.StatusOK, "登录成功", user)
 }
 
+// RefreshToken 刷新令牌
+// @Summary 刷新令牌
+// @Description 凭刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌随之失效，只能使用一次
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body RefreshTokenRequest true "刷新令牌"
+// @Success 200 {object} response.Response{data=model.LoginResponse} "刷新成功"
+// @Failure 400 {object} response.Response "请求参数错误"
+// @Failure 401 {object} response.Response "刷新令牌无效或已过期"
+// @Failure 403 {object} response.Response "账户已禁用或需要重置密码"
+// @Failure 500 {object} response.Response "服务器内部错误"
+// @Router /api/v1/auth/refresh [post]
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+	if !bindJSON(c, h.logger, &req) {
+		return
+	}
+
+	tokens, err := h.userService.RefreshToken(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		h.logger.Warn("Failed to refresh token", zap.Error(err))
+		if appErr, ok := err.(*errors.AppError); ok {
+			response.AppError(c, appErr)
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, "Failed to refresh token")
+		return
+	}
+
+	response.SuccessWithMsg(c, http.StatusOK, "刷新成功", tokens)
+}
+
 // LoginRequest 登录请求
 type LoginRequest struct {
 	Username string `json:"username" validate:"required"`
 	Password string `json:"password" validate:"required"`
 }
+
+// RefreshTokenRequest 刷新令牌请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required,max=128"`
+}
@@ -0,0 +1,9 @@
+package locales
+
+import "embed"
+
+// FS 内置的多语言消息文件，文件名为语言标签
+// 配置 i18n.dir 后可从磁盘加载同样格式的文件覆盖内置消息
+//
+//go:embed *.json
+var FS embed.FS
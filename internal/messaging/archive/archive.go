@@ -0,0 +1,197 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// 归档配置默认值
+const (
+	defaultBatchSize     = 100
+	defaultFlushInterval = 5 * time.Second
+	defaultBufferSize    = 10000
+
+	// flushTimeout 单批写入的超时时间
+	flushTimeout = 30 * time.Second
+)
+
+// Sink 归档存储
+type Sink interface {
+	// Write 写入一批归档消息
+	Write(ctx context.Context, messages []*model.ArchivedMessage) error
+	// Replay 按归档顺序对满足条件的消息调用 fn，fn 返回错误时停止并返回该错误
+	Replay(ctx context.Context, query *model.MessageArchiveQuery, fn func(*model.ArchivedMessage) error) error
+	// Purge 删除归档时间早于 before 的消息，返回删除的数量
+	Purge(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Archiver 将消费者处理过的消息异步批量写入 Sink
+// Observe 作为 mq.DeliveryObserver 挂到消费者上，缓冲满或写入失败时丢弃消息并记录日志，不影响消费
+type Archiver struct {
+	sink          Sink
+	clock         clock.Clock
+	logger        *zap.Logger
+	batchSize     int
+	flushInterval time.Duration
+
+	records   chan *model.ArchivedMessage
+	stop      chan struct{}
+	done      chan struct{}
+	startOnce sync.Once
+	stopOnce  sync.Once
+}
+
+// NewArchiver 创建归档器，调用 Start 后开始写入
+func NewArchiver(sink Sink, cfg *config.MessageArchive, clk clock.Clock, logger *zap.Logger) *Archiver {
+	a := &Archiver{
+		sink:          sink,
+		clock:         clk,
+		logger:        logger,
+		batchSize:     cfg.BatchSize,
+		flushInterval: cfg.FlushInterval,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+	if a.batchSize <= 0 {
+		a.batchSize = defaultBatchSize
+	}
+	if a.flushInterval <= 0 {
+		a.flushInterval = defaultFlushInterval
+	}
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+	a.records = make(chan *model.ArchivedMessage, bufferSize)
+	return a
+}
+
+// Sink 返回归档存储
+func (a *Archiver) Sink() Sink {
+	return a.sink
+}
+
+// Observe 记录一条消息的处理结果，签名与 mq.DeliveryObserver 一致
+func (a *Archiver) Observe(queue string, d amqp.Delivery, outcome string, err error) {
+	record := newRecord(queue, d, outcome, err, a.clock.Now())
+	select {
+	case <-a.stop:
+		a.logger.Warn("Message archiver stopped, dropping record", zap.String("message_id", record.MessageID))
+	case a.records <- record:
+	default:
+		a.logger.Warn("Message archive buffer full, dropping record", zap.String("message_id", record.MessageID))
+	}
+}
+
+// Start 启动后台写入协程
+func (a *Archiver) Start() {
+	a.startOnce.Do(func() {
+		go a.run()
+	})
+}
+
+// Close 停止接收新消息并写入缓冲中剩余的消息，ctx 到期时直接返回
+func (a *Archiver) Close(ctx context.Context) error {
+	a.Start()
+	a.stopOnce.Do(func() {
+		close(a.stop)
+	})
+	select {
+	case <-a.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 攒满一批或到达写入间隔时写入
+func (a *Archiver) run() {
+	defer close(a.done)
+
+	ticker := time.NewTicker(a.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*model.ArchivedMessage, 0, a.batchSize)
+	for {
+		select {
+		case record := <-a.records:
+			batch = append(batch, record)
+			if len(batch) >= a.batchSize {
+				batch = a.flush(batch)
+			}
+		case <-ticker.C:
+			batch = a.flush(batch)
+		case <-a.stop:
+			for {
+				select {
+				case record := <-a.records:
+					batch = append(batch, record)
+					if len(batch) >= a.batchSize {
+						batch = a.flush(batch)
+					}
+				default:
+					a.flush(batch)
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush 写入一批消息，返回清空后的切片
+func (a *Archiver) flush(batch []*model.ArchivedMessage) []*model.ArchivedMessage {
+	if len(batch) == 0 {
+		return batch
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancel()
+	if err := a.sink.Write(ctx, batch); err != nil {
+		a.logger.Error("Failed to archive messages", zap.Int("count", len(batch)), zap.Error(err))
+	}
+	return make([]*model.ArchivedMessage, 0, a.batchSize)
+}
+
+// newRecord 构造归档消息，消息 ID 和类型优先取 AMQP 属性，缺失时从信封中解析
+func newRecord(queue string, d amqp.Delivery, outcome string, err error, now time.Time) *model.ArchivedMessage {
+	record := &model.ArchivedMessage{
+		MessageID:   d.MessageId,
+		MessageType: d.Type,
+		Queue:       queue,
+		Exchange:    d.Exchange,
+		RoutingKey:  d.RoutingKey,
+		Body:        string(d.Body),
+		Outcome:     outcome,
+		ArchivedAt:  now,
+	}
+	if record.MessageID == "" || record.MessageType == "" {
+		var envelope mq.Envelope
+		if json.Unmarshal(d.Body, &envelope) == nil {
+			if record.MessageID == "" {
+				record.MessageID = envelope.MessageID
+			}
+			if record.MessageType == "" {
+				record.MessageType = envelope.MessageType
+			}
+		}
+	}
+	if len(d.Headers) > 0 {
+		if headers, err := json.Marshal(d.Headers); err == nil {
+			record.Headers = string(headers)
+		}
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	return record
+}
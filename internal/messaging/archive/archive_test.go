@@ -0,0 +1,110 @@
+package archive
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+	"github.com/hedeqiang/skeleton/pkg/storage"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// memorySink 记录写入的批次
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]*model.ArchivedMessage
+}
+
+func (s *memorySink) Write(ctx context.Context, messages []*model.ArchivedMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, messages)
+	return nil
+}
+
+func (s *memorySink) Replay(ctx context.Context, query *model.MessageArchiveQuery, fn func(*model.ArchivedMessage) error) error {
+	return nil
+}
+
+func (s *memorySink) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestArchiverBatchesAndFlushesOnClose(t *testing.T) {
+	sink := &memorySink{}
+	archiver := NewArchiver(sink, &config.MessageArchive{BatchSize: 2, FlushInterval: time.Hour}, clock.Frozen(), zap.NewNop())
+	archiver.Start()
+
+	body := []byte(`{"message_id":"m1","message_type":"hello","payload":{}}`)
+	for i := 0; i < 3; i++ {
+		archiver.Observe("hello.queue", amqp.Delivery{
+			Body:       body,
+			Exchange:   "hello.exchange",
+			RoutingKey: "hello",
+			Headers:    amqp.Table{"traceparent": "00-abc"},
+		}, mq.OutcomeDeadLettered, errors.New("invalid payload"))
+	}
+	if err := archiver.Close(context.Background()); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	if len(sink.batches) != 2 || len(sink.batches[0]) != 2 || len(sink.batches[1]) != 1 {
+		t.Fatalf("unexpected batches: %d", len(sink.batches))
+	}
+	record := sink.batches[0][0]
+	if record.MessageID != "m1" || record.MessageType != "hello" || record.Queue != "hello.queue" ||
+		record.Outcome != mq.OutcomeDeadLettered || record.Error != "invalid payload" ||
+		record.Headers != `{"traceparent":"00-abc"}` || !record.ArchivedAt.Equal(clock.FrozenTime) {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+}
+
+func TestStorageSinkReplayAndPurge(t *testing.T) {
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal failed: %v", err)
+	}
+	sink := NewStorageSink(store, "")
+	ctx := context.Background()
+
+	day1 := time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Hour)
+	err = sink.Write(ctx, []*model.ArchivedMessage{
+		{MessageID: "a", MessageType: "hello", Outcome: mq.OutcomeAcked, ArchivedAt: day1},
+		{MessageID: "b", MessageType: "other", Outcome: mq.OutcomeAcked, ArchivedAt: day1},
+		{MessageID: "c", MessageType: "hello", Outcome: mq.OutcomeAcked, ArchivedAt: day2},
+	})
+	if err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	var ids []string
+	collect := func(m *model.ArchivedMessage) error {
+		ids = append(ids, m.MessageID)
+		return nil
+	}
+	if err := sink.Replay(ctx, &model.MessageArchiveQuery{MessageType: "hello"}, collect); err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "a" || ids[1] != "c" {
+		t.Fatalf("unexpected replay order: %v", ids)
+	}
+
+	// 只删除整天早于 before 的文件
+	deleted, err := sink.Purge(ctx, day2)
+	if err != nil || deleted != 1 {
+		t.Fatalf("Purge: deleted=%d err=%v", deleted, err)
+	}
+	ids = nil
+	if err := sink.Replay(ctx, &model.MessageArchiveQuery{}, collect); err != nil || len(ids) != 1 || ids[0] != "c" {
+		t.Fatalf("unexpected messages after purge: %v, %v", ids, err)
+	}
+}
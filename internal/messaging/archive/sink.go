@@ -0,0 +1,200 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/storage"
+)
+
+// 分批重放时每次从数据库读取的数量
+const replayPageSize = 500
+
+// defaultPrefix storage 归档默认的对象前缀
+const defaultPrefix = "archive/messages"
+
+// dayLayout storage 归档按天分目录
+const dayLayout = "2006-01-02"
+
+// NewSink 根据配置创建归档存储
+func NewSink(cfg *config.MessageArchive, repo repository.MessageArchiveRepository, store storage.Storage) (Sink, error) {
+	switch cfg.Sink {
+	case config.ArchiveSinkDatabase, "":
+		return NewDatabaseSink(repo), nil
+	case config.ArchiveSinkStorage:
+		return NewStorageSink(store, cfg.Prefix), nil
+	default:
+		return nil, fmt.Errorf("unsupported message archive sink: %s", cfg.Sink)
+	}
+}
+
+// databaseSink 写入 message_archive 表
+type databaseSink struct {
+	repo repository.MessageArchiveRepository
+}
+
+// NewDatabaseSink 创建数据库归档存储
+func NewDatabaseSink(repo repository.MessageArchiveRepository) Sink {
+	return &databaseSink{repo: repo}
+}
+
+// Write 批量插入
+func (s *databaseSink) Write(ctx context.Context, messages []*model.ArchivedMessage) error {
+	return s.repo.CreateBatch(ctx, messages)
+}
+
+// Replay 按 ID 分页读取
+func (s *databaseSink) Replay(ctx context.Context, query *model.MessageArchiveQuery, fn func(*model.ArchivedMessage) error) error {
+	var afterID uint64
+	for {
+		messages, err := s.repo.FindAfter(ctx, query, afterID, replayPageSize)
+		if err != nil {
+			return err
+		}
+		for _, message := range messages {
+			if err := fn(message); err != nil {
+				return err
+			}
+			afterID = message.ID
+		}
+		if len(messages) < replayPageSize {
+			return nil
+		}
+	}
+}
+
+// Purge 删除过期记录
+func (s *databaseSink) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return s.repo.DeleteBefore(ctx, before)
+}
+
+// storageSink 以 JSON Lines 文件写入对象存储，key 为 <prefix>/<UTC 日期>/<首条消息归档时间纳秒>-<序号>.jsonl
+// 同一天的文件按 key 字典序即为写入顺序；过期清理以天为单位，Purge 返回删除的文件数
+type storageSink struct {
+	store  storage.Storage
+	prefix string
+	seq    atomic.Uint64
+}
+
+// NewStorageSink 创建对象存储归档
+func NewStorageSink(store storage.Storage, prefix string) Sink {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		prefix = defaultPrefix
+	}
+	return &storageSink{store: store, prefix: prefix}
+}
+
+// Write 按归档日期分组，每组写入一个文件
+func (s *storageSink) Write(ctx context.Context, messages []*model.ArchivedMessage) error {
+	groups := make(map[string]*bytes.Buffer)
+	var keys []string
+	for _, message := range messages {
+		day := message.ArchivedAt.UTC().Format(dayLayout)
+		buf, ok := groups[day]
+		if !ok {
+			buf = &bytes.Buffer{}
+			groups[day] = buf
+			keys = append(keys, fmt.Sprintf("%s/%s/%019d-%06d.jsonl", s.prefix, day, message.ArchivedAt.UnixNano(), s.seq.Add(1)))
+		}
+		if err := json.NewEncoder(buf).Encode(message); err != nil {
+			return fmt.Errorf("failed to encode archived message: %w", err)
+		}
+	}
+
+	for _, key := range keys {
+		day := path.Base(path.Dir(key))
+		if _, err := s.store.Put(ctx, key, groups[day]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Replay 按 key 顺序读取归档文件，跳过日期不在查询范围内的文件
+func (s *storageSink) Replay(ctx context.Context, query *model.MessageArchiveQuery, fn func(*model.ArchivedMessage) error) error {
+	keys, err := s.store.List(ctx, s.prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		day, ok := s.day(key)
+		if !ok {
+			continue
+		}
+		if (!query.Since.IsZero() && !day.Add(24*time.Hour).After(query.Since)) ||
+			(!query.Until.IsZero() && !day.Before(query.Until)) {
+			continue
+		}
+		if err := s.replayFile(ctx, key, query, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replayFile 读取一个归档文件
+func (s *storageSink) replayFile(ctx context.Context, key string, query *model.MessageArchiveQuery, fn func(*model.ArchivedMessage) error) error {
+	r, err := s.store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	decoder := json.NewDecoder(r)
+	for {
+		var message model.ArchivedMessage
+		if err := decoder.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("failed to decode archive file %s: %w", key, err)
+		}
+		if !query.Match(&message) {
+			continue
+		}
+		if err := fn(&message); err != nil {
+			return err
+		}
+	}
+}
+
+// Purge 删除整天早于 before 的归档文件
+func (s *storageSink) Purge(ctx context.Context, before time.Time) (int64, error) {
+	keys, err := s.store.List(ctx, s.prefix)
+	if err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	for _, key := range keys {
+		day, ok := s.day(key)
+		if !ok || day.Add(24*time.Hour).After(before) {
+			continue
+		}
+		if err := s.store.Delete(ctx, key); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// day 从 key 中解析归档日期
+func (s *storageSink) day(key string) (time.Time, bool) {
+	rel := strings.TrimPrefix(key, s.prefix+"/")
+	day, err := time.Parse(dayLayout, path.Dir(rel))
+	return day, err == nil
+}
@@ -2,10 +2,11 @@ package consumer
 
 import (
 	"context"
+	"fmt"
 
-	"github.com/hedeqiang/skeleton/internal/app"
 	"github.com/hedeqiang/skeleton/internal/messaging"
-	"github.com/hedeqiang/skeleton/internal/messaging/processors"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+	"github.com/hedeqiang/skeleton/pkg/mq"
 
 	"go.uber.org/zap"
 )
@@ -14,54 +15,70 @@ import (
 type MessageConsumerService struct {
 	processorRegistry *messaging.ProcessorRegistry
 	logger            *zap.Logger
-	app               *app.App
 }
 
-// NewMessageConsumerService 创建消息消费服务
-func NewMessageConsumerService(app *app.App) *MessageConsumerService {
-	service := &MessageConsumerService{
-		processorRegistry: messaging.NewProcessorRegistry(app.Logger()),
-		logger:            app.Logger(),
-		app:               app,
-	}
+// Processors 消费服务注册的消息处理器，由 Wire 注入器按构造函数装配
+type Processors []messaging.MessageProcessor
 
-	// 注册所有事件处理器
-	service.registerEventProcessors()
+// Without 返回去掉指定消息类型处理器后的列表，messageTypes 中有未注册的类型时返回错误，避免配置拼写错误被忽略
+func (p Processors) Without(messageTypes []string) (Processors, error) {
+	if len(messageTypes) == 0 {
+		return p, nil
+	}
 
-	return service
+	disabled := make(map[string]bool, len(messageTypes))
+	for _, messageType := range messageTypes {
+		disabled[messageType] = true
+	}
+	enabled := make(Processors, 0, len(p))
+	for _, processor := range p {
+		messageType := processor.GetSupportedMessageType()
+		if disabled[messageType] {
+			delete(disabled, messageType)
+			continue
+		}
+		enabled = append(enabled, processor)
+	}
+	for _, messageType := range messageTypes {
+		if !disabled[messageType] {
+			continue
+		}
+		return nil, fmt.Errorf("consumer.disabled_processors: no processor handles message type %q", messageType)
+	}
+	return enabled, nil
 }
 
-// registerEventProcessors 注册事件处理器
-func (s *MessageConsumerService) registerEventProcessors() {
-	// 注册Hello处理器（示例）
-	s.processorRegistry.RegisterProcessor(
-		processors.NewHelloProcessor(s.logger),
-	)
+// NewMessageConsumerService 创建消息消费服务并注册所有处理器
+// schemas 非空时处理前按声明的 schema 校验载荷，处理失败时按 policies 中消息类型的失败策略处理
+func NewMessageConsumerService(logger *zap.Logger, processors Processors, schemas *mq.SchemaRegistry, policies *messaging.FailurePolicies) *MessageConsumerService {
+	logger = logger.Named(mq.LoggerName)
+	service := &MessageConsumerService{
+		processorRegistry: messaging.NewProcessorRegistry(logger, schemas, policies),
+		logger:            logger,
+	}
 
-	// TODO: 在这里添加其他消息处理器
-	// s.processorRegistry.RegisterProcessor(
-	//     processors.NewUserEventProcessor(s.logger),
-	// )
-	// s.processorRegistry.RegisterProcessor(
-	//     processors.NewOrderEventProcessor(s.logger),
-	// )
+	for _, processor := range processors {
+		service.processorRegistry.RegisterProcessor(processor)
+	}
+	logger.Info("Event processors registered successfully", zap.Int("count", len(processors)))
 
-	s.logger.Info("Event processors registered successfully")
+	return service
 }
 
 // ConsumeMessage 消费消息的统一入口
 func (s *MessageConsumerService) ConsumeMessage(ctx context.Context, messageBody []byte) error {
-	s.logger.Info("Message consumer service received message",
+	log := logger.FromContext(ctx, s.logger)
+	log.Info("Message consumer service received message",
 		zap.Int("body_size", len(messageBody)),
 	)
 
 	// 委托给处理器注册表进行具体处理
-	if err := s.processorRegistry.ProcessIncomingMessage(ctx, messageBody, s.app); err != nil {
-		s.logger.Error("Failed to process incoming message", zap.Error(err))
+	if err := s.processorRegistry.ProcessIncomingMessage(ctx, messageBody); err != nil {
+		log.Error("Failed to process incoming message", zap.Error(err))
 		return err
 	}
 
-	s.logger.Info("Message processed successfully by consumer service")
+	log.Info("Message processed successfully by consumer service")
 	return nil
 }
 
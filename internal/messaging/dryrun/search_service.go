@@ -0,0 +1,53 @@
+// Package dryrun 消费者 dry-run 模式下替代处理器外部依赖的实现，只记录处理器将要执行的写操作
+package dryrun
+
+import (
+	"context"
+	"errors"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// errNotSupported dry-run 模式不支持的操作
+var errNotSupported = errors.New("not supported in dry-run mode")
+
+// searchService 不访问搜索引擎的搜索服务
+type searchService struct {
+	logger *zap.Logger
+}
+
+// NewSearchService 创建 dry-run 搜索服务，索引写入只记录日志，查询返回空结果
+func NewSearchService(logger *zap.Logger) service.SearchService {
+	return &searchService{logger: logger}
+}
+
+// SearchUsers 返回空结果
+func (s *searchService) SearchUsers(ctx context.Context, req *model.SearchUsersRequest) ([]*model.UserResponse, int64, error) {
+	return []*model.UserResponse{}, 0, nil
+}
+
+// IndexUser 记录将要写入索引的用户
+func (s *searchService) IndexUser(ctx context.Context, id uint) error {
+	logger.FromContext(ctx, s.logger).Info("Dry run: skip indexing user", zap.Uint("user_id", id))
+	return nil
+}
+
+// RemoveUser 记录将要从索引删除的用户
+func (s *searchService) RemoveUser(ctx context.Context, id uint) error {
+	logger.FromContext(ctx, s.logger).Info("Dry run: skip removing user from index", zap.Uint("user_id", id))
+	return nil
+}
+
+// StartReindex 不支持
+func (s *searchService) StartReindex(ctx context.Context, name string) (*model.TaskResponse, error) {
+	return nil, errNotSupported
+}
+
+// Reindex 不支持
+func (s *searchService) Reindex(ctx context.Context, name, taskID string) (*service.ReindexResult, error) {
+	return nil, errNotSupported
+}
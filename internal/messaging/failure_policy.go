@@ -0,0 +1,83 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+
+	"go.uber.org/zap"
+)
+
+// DeadLetterFailurePolicy 按 dead_letter 策略转入死信队列时的原因
+const DeadLetterFailurePolicy = "failure_policy"
+
+// FailurePolicies 按消息类型决定处理失败后的去向，未配置的类型按 retry 处理
+type FailurePolicies struct {
+	policies map[string]config.FailurePolicy
+}
+
+// NewFailurePolicies 校验并创建失败策略，策略名未知、park 未指定交换机或消息类型重复时返回错误
+func NewFailurePolicies(cfgs []config.FailurePolicy) (*FailurePolicies, error) {
+	p := &FailurePolicies{policies: make(map[string]config.FailurePolicy, len(cfgs))}
+	for _, cfg := range cfgs {
+		if cfg.MessageType == "" {
+			return nil, fmt.Errorf("failure policy requires message_type")
+		}
+		if _, ok := p.policies[cfg.MessageType]; ok {
+			return nil, fmt.Errorf("duplicate failure policy for message type %s", cfg.MessageType)
+		}
+		switch cfg.Policy {
+		case config.FailurePolicyRetry, config.FailurePolicyDrop, config.FailurePolicyDeadLetter:
+		case config.FailurePolicyPark:
+			if cfg.Exchange == "" {
+				return nil, fmt.Errorf("failure policy park for message type %s requires exchange", cfg.MessageType)
+			}
+		default:
+			return nil, fmt.Errorf("unknown failure policy %q for message type %s", cfg.Policy, cfg.MessageType)
+		}
+		p.policies[cfg.MessageType] = cfg
+	}
+	return p, nil
+}
+
+// Policy 返回消息类型的失败策略
+func (p *FailurePolicies) Policy(messageType string) string {
+	if p == nil {
+		return config.FailurePolicyRetry
+	}
+	if policy, ok := p.policies[messageType]; ok {
+		return policy.Policy
+	}
+	return config.FailurePolicyRetry
+}
+
+// Apply 按消息类型的策略包装处理失败的错误，由消费者决定确认、重新入队、转入死信队列或停放
+// retry 保持原错误；drop 和 park 对包括死信在内的所有失败生效；dead_letter 将可重试的错误转为死信
+func (p *FailurePolicies) Apply(log *zap.Logger, messageType string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch p.Policy(messageType) {
+	case config.FailurePolicyDrop:
+		log.Warn("Message processing failed, dropping by failure policy", zap.Error(err))
+		return mq.Drop(err)
+	case config.FailurePolicyDeadLetter:
+		var dlErr *mq.DeadLetterError
+		if errors.As(err, &dlErr) {
+			return err
+		}
+		return mq.DeadLetter(err, DeadLetterFailurePolicy, nil)
+	case config.FailurePolicyPark:
+		policy := p.policies[messageType]
+		log.Warn("Message processing failed, parking by failure policy",
+			zap.String("exchange", policy.Exchange),
+			zap.Error(err),
+		)
+		return mq.Park(err, policy.Exchange, policy.RoutingKey)
+	default:
+		return err
+	}
+}
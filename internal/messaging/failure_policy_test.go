@@ -0,0 +1,100 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+
+	"go.uber.org/zap"
+)
+
+// failingProcessor 处理任何消息都返回 err
+type failingProcessor struct {
+	messageType string
+	err         error
+}
+
+func (p *failingProcessor) GetSupportedMessageType() string { return p.messageType }
+
+func (p *failingProcessor) ProcessMessage(ctx context.Context, envelope *MessageEnvelope) error {
+	return p.err
+}
+
+func TestNewFailurePoliciesInvalid(t *testing.T) {
+	tests := map[string][]config.FailurePolicy{
+		"missing message type":  {{Policy: config.FailurePolicyDrop}},
+		"unknown policy":        {{MessageType: "hello", Policy: "ignore"}},
+		"park without exchange": {{MessageType: "hello", Policy: config.FailurePolicyPark}},
+		"duplicate": {
+			{MessageType: "hello", Policy: config.FailurePolicyDrop},
+			{MessageType: "hello", Policy: config.FailurePolicyRetry},
+		},
+	}
+	for name, cfgs := range tests {
+		if _, err := NewFailurePolicies(cfgs); err == nil {
+			t.Errorf("%s: NewFailurePolicies() error = nil", name)
+		}
+	}
+}
+
+func TestFailurePolicies(t *testing.T) {
+	policies, err := NewFailurePolicies([]config.FailurePolicy{
+		{MessageType: "analytics", Policy: config.FailurePolicyDrop},
+		{MessageType: "billing", Policy: config.FailurePolicyDeadLetter},
+		{MessageType: "orders", Policy: config.FailurePolicyPark, Exchange: "orders.parking"},
+	})
+	if err != nil {
+		t.Fatalf("NewFailurePolicies() error = %v", err)
+	}
+
+	failure := errors.New("downstream unavailable")
+	registry := NewProcessorRegistry(zap.NewNop(), nil, policies)
+	for _, messageType := range []string{"analytics", "billing", "orders", "hello"} {
+		registry.RegisterProcessor(&failingProcessor{messageType: messageType, err: failure})
+	}
+	process := func(messageType string) error {
+		body := []byte(`{"message_id":"m1","message_type":"` + messageType + `","payload":{}}`)
+		return registry.ProcessIncomingMessage(context.Background(), body)
+	}
+
+	var dropErr *mq.DropError
+	if err := process("analytics"); !errors.As(err, &dropErr) || !errors.Is(err, failure) {
+		t.Errorf("drop policy error = %v, want DropError", err)
+	}
+
+	var dlErr *mq.DeadLetterError
+	if err := process("billing"); !errors.As(err, &dlErr) || dlErr.Reason != DeadLetterFailurePolicy {
+		t.Errorf("dead_letter policy error = %v, want DeadLetterError", err)
+	}
+
+	var parkErr *mq.ParkError
+	if err := process("orders"); !errors.As(err, &parkErr) || parkErr.Exchange != "orders.parking" {
+		t.Errorf("park policy error = %v, want ParkError to orders.parking", err)
+	}
+
+	// 未配置策略的类型保持原错误，由消费者重新入队
+	if err := process("hello"); err != failure {
+		t.Errorf("default policy error = %v, want %v", err, failure)
+	}
+}
+
+func TestFailurePoliciesKeepDeadLetterReason(t *testing.T) {
+	policies, err := NewFailurePolicies([]config.FailurePolicy{
+		{MessageType: "billing", Policy: config.FailurePolicyDeadLetter},
+	})
+	if err != nil {
+		t.Fatalf("NewFailurePolicies() error = %v", err)
+	}
+
+	original := mq.DeadLetter(errors.New("invalid"), DeadLetterValidationFailed, nil)
+	var dlErr *mq.DeadLetterError
+	if err := policies.Apply(zap.NewNop(), "billing", original); !errors.As(err, &dlErr) || dlErr.Reason != DeadLetterValidationFailed {
+		t.Errorf("Apply() error = %v, want original dead letter reason", err)
+	}
+	if err := policies.Apply(zap.NewNop(), "billing", nil); err != nil {
+		t.Errorf("Apply(nil) error = %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package messaging
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// maxMessageLineSize 消息文件单行的最大长度
+const maxMessageLineSize = 16 * 1024 * 1024
+
+// ReadMessageFile 逐行读取 NDJSON 消息文件，对每条消息体调用 fn，fn 返回错误时停止并返回该错误
+// 每行可以是消息信封，也可以是 storage 归档导出的 model.ArchivedMessage（取其 body 字段），空行跳过
+func ReadMessageFile(r io.Reader, fn func(line int, body []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		body, err := messageBody(raw)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+		if err := fn(line, body); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("line %d: %w", line+1, err)
+	}
+	return nil
+}
+
+// messageBody 返回一行中的消息体，归档记录的 body 是字符串形式的信封
+func messageBody(raw []byte) ([]byte, error) {
+	var record struct {
+		Body *string `json:"body"`
+	}
+	if err := json.Unmarshal(raw, &record); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if record.Body != nil {
+		return []byte(*record.Body), nil
+	}
+	// 复制一份，scanner 的缓冲区会在下一次 Scan 时被覆盖
+	return append([]byte(nil), raw...), nil
+}
@@ -0,0 +1,51 @@
+package messaging
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestReadMessageFile(t *testing.T) {
+	input := strings.Join([]string{
+		`{"message_id":"m1","message_type":"hello","payload":{"content":"hi"}}`,
+		``,
+		`{"id":7,"message_id":"m2","queue":"hello.queue","body":"{\"message_id\":\"m2\",\"message_type\":\"hello\"}","outcome":"requeued"}`,
+	}, "\n")
+
+	var lines []int
+	var bodies []string
+	err := ReadMessageFile(strings.NewReader(input), func(line int, body []byte) error {
+		lines = append(lines, line)
+		bodies = append(bodies, string(body))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadMessageFile() error = %v", err)
+	}
+
+	if len(lines) != 2 || lines[0] != 1 || lines[1] != 3 {
+		t.Fatalf("lines = %v, want [1 3]", lines)
+	}
+	if bodies[0] != `{"message_id":"m1","message_type":"hello","payload":{"content":"hi"}}` {
+		t.Errorf("envelope body = %s", bodies[0])
+	}
+	if bodies[1] != `{"message_id":"m2","message_type":"hello"}` {
+		t.Errorf("archived body = %s", bodies[1])
+	}
+}
+
+func TestReadMessageFileErrors(t *testing.T) {
+	err := ReadMessageFile(strings.NewReader("{}\nnot json\n"), func(int, []byte) error { return nil })
+	if err == nil || !strings.HasPrefix(err.Error(), "line 2:") {
+		t.Errorf("invalid line error = %v, want line 2", err)
+	}
+
+	stop := errors.New("stop")
+	err = ReadMessageFile(strings.NewReader("{}\n{}\n"), func(line int, body []byte) error {
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Errorf("callback error = %v, want %v", err, stop)
+	}
+}
@@ -1,11 +1,18 @@
 package messaging
 
 import (
-	"github.com/hedeqiang/skeleton/internal/app"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+	"github.com/hedeqiang/skeleton/pkg/validator"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 
+	playground "github.com/go-playground/validator/v10"
+	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
@@ -16,21 +23,59 @@ type BusinessMessage interface {
 }
 
 // MessageProcessor 消息处理器接口
+// 处理器需要的仓储、服务、缓存等依赖通过构造函数注入，由消费者的 Wire 注入器装配
 type MessageProcessor interface {
-	ProcessMessage(ctx context.Context, msg BusinessMessage, app *app.App) error
+	ProcessMessage(ctx context.Context, envelope *MessageEnvelope) error
 	GetSupportedMessageType() string
 }
 
+// PayloadValidator 处理器可选实现的接口，返回用于解析载荷的结构体指针
+// 注册表在调用 ProcessMessage 之前解析载荷并按 validate 标签校验，校验失败的消息转入死信队列
+type PayloadValidator interface {
+	NewPayload() interface{}
+}
+
+// 转入死信队列的原因
+const (
+	DeadLetterMalformed        = "malformed_message"
+	DeadLetterValidationFailed = "validation_failed"
+	DeadLetterSchemaMismatch   = "schema_mismatch"
+)
+
+// HeaderValidationReport 死信消息中携带校验报告的消息头
+const HeaderValidationReport = "x-validation-report"
+
+// ValidationError 消息载荷校验失败
+type ValidationError struct {
+	MessageID   string            `json:"message_id"`
+	MessageType string            `json:"message_type"`
+	Errors      map[string]string `json:"errors"`
+}
+
+// Error 实现 error 接口
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Errors))
+	for field := range e.Errors {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fmt.Sprintf("invalid payload for message %s (%s): %s", e.MessageID, e.MessageType, strings.Join(fields, ", "))
+}
+
 // ProcessorRegistry 消息处理器注册表
 type ProcessorRegistry struct {
 	processors map[string]MessageProcessor
+	schemas    *mq.SchemaRegistry
+	policies   *FailurePolicies
 	logger     *zap.Logger
 }
 
-// NewProcessorRegistry 创建新的处理器注册表
-func NewProcessorRegistry(logger *zap.Logger) *ProcessorRegistry {
+// NewProcessorRegistry 创建新的处理器注册表，schemas 为 nil 时不按 schema 校验载荷，policies 为 nil 时所有消息类型按 retry 处理
+func NewProcessorRegistry(logger *zap.Logger, schemas *mq.SchemaRegistry, policies *FailurePolicies) *ProcessorRegistry {
 	return &ProcessorRegistry{
 		processors: make(map[string]MessageProcessor),
+		schemas:    schemas,
+		policies:   policies,
 		logger:     logger,
 	}
 }
@@ -46,59 +91,91 @@ func (r *ProcessorRegistry) RegisterProcessor(processor MessageProcessor) {
 }
 
 // ProcessIncomingMessage 处理接收到的消息
-func (r *ProcessorRegistry) ProcessIncomingMessage(ctx context.Context, body []byte, app *app.App) error {
+// 解析信封后将 message_id、message_type 写入 ctx 的日志字段，处理器通过 logger.FromContext 取得的 logger 自动携带
+func (r *ProcessorRegistry) ProcessIncomingMessage(ctx context.Context, body []byte) error {
 	// 先尝试解析基础消息结构
 	var envelope MessageEnvelope
 	if err := json.Unmarshal(body, &envelope); err != nil {
-		r.logger.Error("Failed to unmarshal message envelope", zap.Error(err))
-		return fmt.Errorf("failed to unmarshal message envelope: %w", err)
+		logger.FromContext(ctx, r.logger).Error("Failed to unmarshal message envelope", zap.Error(err))
+		// 无法解析的消息重试也不会成功，直接转入死信队列
+		return mq.DeadLetter(fmt.Errorf("failed to unmarshal message envelope: %w", err), DeadLetterMalformed, nil)
 	}
 
-	r.logger.Info("Received business message",
+	ctx = logger.WithFields(ctx,
 		zap.String("message_id", envelope.MessageID),
 		zap.String("message_type", envelope.MessageType),
-		zap.ByteString("payload", body),
 	)
+	log := logger.FromContext(ctx, r.logger)
+
+	log.Info("Received business message", zap.ByteString("payload", body))
 
+	// 处理失败时按消息类型的失败策略决定重试、丢弃、转入死信队列或停放
+	return r.policies.Apply(log, envelope.MessageType, r.process(ctx, log, &envelope))
+}
+
+// process 校验载荷并交给消息类型对应的处理器
+func (r *ProcessorRegistry) process(ctx context.Context, log *zap.Logger, envelope *MessageEnvelope) error {
 	// 查找对应的处理器
 	processor, exists := r.processors[envelope.MessageType]
 	if !exists {
-		r.logger.Warn("No processor found for message type",
-			zap.String("message_type", envelope.MessageType),
-		)
+		log.Warn("No processor found for message type")
 		// 可以选择返回错误或者忽略
 		return nil
 	}
 
+	// 载荷与声明的 schema 不一致或版本未声明时，重试也不会成功，直接转入死信队列
+	if err := r.schemas.Validate(envelope.MessageType, envelope.Version, envelope.Payload); err != nil {
+		log.Warn("Message payload does not match schema", zap.String("version", envelope.Version), zap.Error(err))
+		return mq.DeadLetter(err, DeadLetterSchemaMismatch, nil)
+	}
+
+	// 校验载荷，避免格式错误的消息处理到一半失败
+	if err := r.validatePayload(log, processor, envelope); err != nil {
+		return err
+	}
+
 	// 让具体的处理器解析和处理消息
-	return processor.ProcessMessage(ctx, &envelope, app)
+	return processor.ProcessMessage(ctx, envelope)
 }
 
-// MessageEnvelope 消息信封结构
-type MessageEnvelope struct {
-	MessageID   string          `json:"message_id"`
-	MessageType string          `json:"message_type"`
-	Payload     json.RawMessage `json:"payload"` // 使用 RawMessage 延迟解析
-	Timestamp   int64           `json:"timestamp"`
-	Source      string          `json:"source,omitempty"`
-	Version     string          `json:"version,omitempty"`
-}
+// validatePayload 对实现了 PayloadValidator 的处理器校验消息载荷
+// 校验失败时返回死信错误，校验报告以 JSON 写入 x-validation-report 消息头
+func (r *ProcessorRegistry) validatePayload(log *zap.Logger, processor MessageProcessor, envelope *MessageEnvelope) error {
+	pv, ok := processor.(PayloadValidator)
+	if !ok {
+		return nil
+	}
 
-// GetMessageType 实现 BusinessMessage 接口
-func (e *MessageEnvelope) GetMessageType() string {
-	return e.MessageType
-}
+	report := &ValidationError{
+		MessageID:   envelope.MessageID,
+		MessageType: envelope.MessageType,
+	}
 
-// GetMessageID 实现 BusinessMessage 接口
-func (e *MessageEnvelope) GetMessageID() string {
-	return e.MessageID
-}
+	payload := pv.NewPayload()
+	if err := envelope.UnmarshalPayload(payload); err != nil {
+		report.Errors = map[string]string{"payload": err.Error()}
+	} else if err := validator.Struct(payload); err != nil {
+		var fieldErrs playground.ValidationErrors
+		if !errors.As(err, &fieldErrs) {
+			return fmt.Errorf("failed to validate payload: %w", err)
+		}
+		report.Errors = validator.Translate(fieldErrs)
+	}
+	if len(report.Errors) == 0 {
+		return nil
+	}
+
+	log.Warn("Message payload validation failed", zap.Any("errors", report.Errors))
 
-// UnmarshalPayload 解析消息载荷到具体结构
-func (e *MessageEnvelope) UnmarshalPayload(v interface{}) error {
-	return json.Unmarshal(e.Payload, v)
+	reportJSON, _ := json.Marshal(report)
+	return mq.DeadLetter(report, DeadLetterValidationFailed, amqp.Table{
+		HeaderValidationReport: string(reportJSON),
+	})
 }
 
+// MessageEnvelope 消息信封结构，与生产端 mq.EventPublisher 使用同一定义
+type MessageEnvelope = mq.Envelope
+
 // GetRegisteredTypes 获取所有已注册的消息处理器类型
 func (r *ProcessorRegistry) GetRegisteredTypes() []string {
 	r.logger.Debug("Getting registered processor types")
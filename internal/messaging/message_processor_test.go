@@ -0,0 +1,105 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hedeqiang/skeleton/pkg/mq"
+
+	"go.uber.org/zap"
+)
+
+type testEvent struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type testProcessor struct {
+	processed bool
+}
+
+func (p *testProcessor) GetSupportedMessageType() string { return "test" }
+
+func (p *testProcessor) NewPayload() interface{} { return &testEvent{} }
+
+func (p *testProcessor) ProcessMessage(ctx context.Context, envelope *MessageEnvelope) error {
+	p.processed = true
+	return nil
+}
+
+func TestProcessIncomingMessageValidation(t *testing.T) {
+	processor := &testProcessor{}
+	registry := NewProcessorRegistry(zap.NewNop(), nil, nil)
+	registry.RegisterProcessor(processor)
+
+	body := []byte(`{"message_id":"m1","message_type":"test","payload":{"name":""}}`)
+	err := registry.ProcessIncomingMessage(context.Background(), body)
+
+	var dlErr *mq.DeadLetterError
+	if !errors.As(err, &dlErr) || dlErr.Reason != DeadLetterValidationFailed {
+		t.Fatalf("expected validation dead letter, got %v", err)
+	}
+	if processor.processed {
+		t.Fatal("invalid payload should not reach the processor")
+	}
+
+	var report ValidationError
+	if err := json.Unmarshal([]byte(dlErr.Headers[HeaderValidationReport].(string)), &report); err != nil {
+		t.Fatalf("invalid validation report: %v", err)
+	}
+	if report.MessageID != "m1" || report.Errors["name"] == "" {
+		t.Fatalf("unexpected validation report: %+v", report)
+	}
+
+	body = []byte(`{"message_id":"m2","message_type":"test","payload":{"name":"ok"}}`)
+	if err := registry.ProcessIncomingMessage(context.Background(), body); err != nil {
+		t.Fatalf("valid message failed: %v", err)
+	}
+	if !processor.processed {
+		t.Fatal("valid payload should reach the processor")
+	}
+}
+
+func TestProcessIncomingMessageMalformed(t *testing.T) {
+	registry := NewProcessorRegistry(zap.NewNop(), nil, nil)
+
+	err := registry.ProcessIncomingMessage(context.Background(), []byte(`not json`))
+
+	var dlErr *mq.DeadLetterError
+	if !errors.As(err, &dlErr) || dlErr.Reason != DeadLetterMalformed {
+		t.Fatalf("expected malformed dead letter, got %v", err)
+	}
+}
+
+func TestProcessIncomingMessageSchemaMismatch(t *testing.T) {
+	schemas, err := mq.LoadSchemas(fstest.MapFS{
+		"test/v1.json": {Data: []byte(`{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}`)},
+	})
+	if err != nil {
+		t.Fatalf("LoadSchemas() error = %v", err)
+	}
+	processor := &testProcessor{}
+	registry := NewProcessorRegistry(zap.NewNop(), schemas, nil)
+	registry.RegisterProcessor(processor)
+
+	for _, body := range []string{
+		`{"message_id":"m1","message_type":"test","version":"1","payload":{"name":1}}`,
+		`{"message_id":"m2","message_type":"test","version":"2","payload":{"name":"ok"}}`,
+	} {
+		err := registry.ProcessIncomingMessage(context.Background(), []byte(body))
+		var dlErr *mq.DeadLetterError
+		if !errors.As(err, &dlErr) || dlErr.Reason != DeadLetterSchemaMismatch {
+			t.Fatalf("expected schema mismatch dead letter for %s, got %v", body, err)
+		}
+	}
+	if processor.processed {
+		t.Fatal("message not matching the schema should not reach the processor")
+	}
+
+	body := []byte(`{"message_id":"m3","message_type":"test","payload":{"name":"ok"}}`)
+	if err := registry.ProcessIncomingMessage(context.Background(), body); err != nil {
+		t.Fatalf("valid message failed: %v", err)
+	}
+}
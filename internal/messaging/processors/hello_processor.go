@@ -1,23 +1,27 @@
 package processors
 
 import (
-	"github.com/hedeqiang/skeleton/internal/app"
-	"github.com/hedeqiang/skeleton/internal/messaging"
 	"context"
 	"time"
 
+	"github.com/hedeqiang/skeleton/internal/messaging"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
 // HelloProcessor Hello World消息处理器
 type HelloProcessor struct {
 	logger *zap.Logger
+	redis  *redis.Client
 }
 
-// NewHelloProcessor 创建Hello处理器
-func NewHelloProcessor(logger *zap.Logger) *HelloProcessor {
+// NewHelloProcessor 创建Hello处理器，redis 为 nil 时不记录消息
+func NewHelloProcessor(logger *zap.Logger, redis *redis.Client) *HelloProcessor {
 	return &HelloProcessor{
 		logger: logger,
+		redis:  redis,
 	}
 }
 
@@ -26,52 +30,59 @@ func (p *HelloProcessor) GetSupportedMessageType() string {
 	return "hello"
 }
 
+// NewPayload 返回用于校验的载荷结构，实现 messaging.PayloadValidator 接口
+func (p *HelloProcessor) NewPayload() interface{} {
+	return &HelloEvent{}
+}
+
 // ProcessMessage 处理Hello消息
-func (p *HelloProcessor) ProcessMessage(ctx context.Context, msg messaging.BusinessMessage, app *app.App) error {
-	p.logger.Info("Processing hello message", zap.String("message_id", msg.GetMessageID()))
+// ctx 中已携带 message_id、message_type、queue 等日志字段
+func (p *HelloProcessor) ProcessMessage(ctx context.Context, envelope *messaging.MessageEnvelope) error {
+	log := logger.FromContext(ctx, p.logger)
+	log.Info("Processing hello message")
 
 	// 解析具体的消息数据
 	var event HelloEvent
-	if envelope, ok := msg.(*messaging.MessageEnvelope); ok {
-		if err := envelope.UnmarshalPayload(&event); err != nil {
-			p.logger.Error("Failed to unmarshal hello event", zap.Error(err))
-			return err
-		}
+	if err := envelope.UnmarshalPayload(&event); err != nil {
+		log.Error("Failed to unmarshal hello event", zap.Error(err))
+		return err
 	}
 
-	p.logger.Info("Hello event details",
+	log.Info("Hello event details",
 		zap.String("content", event.Content),
 		zap.String("sender", event.Sender),
 		zap.Int64("timestamp", event.Timestamp),
 	)
 
 	// 简单的业务处理逻辑
-	if err := p.handleHelloMessage(ctx, &event, app); err != nil {
-		p.logger.Error("Failed to handle hello message", zap.Error(err))
+	if err := p.handleHelloMessage(ctx, &event); err != nil {
+		log.Error("Failed to handle hello message", zap.Error(err))
 		return err
 	}
 
-	p.logger.Info("Hello message processed successfully", zap.String("message_id", msg.GetMessageID()))
+	log.Info("Hello message processed successfully")
 	return nil
 }
 
 // handleHelloMessage 处理Hello消息的业务逻辑
-func (p *HelloProcessor) handleHelloMessage(ctx context.Context, event *HelloEvent, app *app.App) error {
+func (p *HelloProcessor) handleHelloMessage(ctx context.Context, event *HelloEvent) error {
+	log := logger.FromContext(ctx, p.logger)
+
 	// 1. 记录到Redis (可选)
-	if app.Redis != nil {
+	if p.redis != nil {
 		key := "hello:messages:" + time.Now().Format("20060102")
-		err := app.Redis.LPush(ctx, key, event.Content).Err()
+		err := p.redis.LPush(ctx, key, event.Content).Err()
 		if err != nil {
-			p.logger.Warn("Failed to save hello message to Redis", zap.Error(err))
+			log.Warn("Failed to save hello message to Redis", zap.Error(err))
 		} else {
 			// 设置过期时间为7天
-			app.Redis.Expire(ctx, key, 7*24*time.Hour)
-			p.logger.Info("Hello message saved to Redis", zap.String("key", key))
+			p.redis.Expire(ctx, key, 7*24*time.Hour)
+			log.Info("Hello message saved to Redis", zap.String("key", key))
 		}
 	}
 
 	// 2. 简单的响应逻辑
-	p.logger.Info("Hello World response",
+	log.Info("Hello World response",
 		zap.String("original_content", event.Content),
 		zap.String("response", "Hello back from processor!"),
 		zap.String("sender", event.Sender),
@@ -82,7 +93,7 @@ func (p *HelloProcessor) handleHelloMessage(ctx context.Context, event *HelloEve
 
 // HelloEvent Hello事件结构
 type HelloEvent struct {
-	Content   string `json:"content"`
-	Sender    string `json:"sender"`
-	Timestamp int64  `json:"timestamp"`
+	Content   string `json:"content" validate:"required,max=1000"`
+	Sender    string `json:"sender" validate:"required,max=100"`
+	Timestamp int64  `json:"timestamp" validate:"gt=0"`
 }
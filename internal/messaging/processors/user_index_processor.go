@@ -0,0 +1,85 @@
+package processors
+
+import (
+	"context"
+
+	"github.com/hedeqiang/skeleton/internal/messaging"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/cache"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// UserIndexProcessors 用户索引处理器，每种用户领域事件对应一个
+type UserIndexProcessors []*UserIndexProcessor
+
+// UserIndexProcessor 将用户领域事件投影到搜索索引
+// 创建和更新事件读取用户最新数据写入索引，重复或乱序投递不会写入旧数据；
+// 索引更新后按 cache.event_invalidations 失效响应缓存，其他服务发布的事件同样生效
+type UserIndexProcessor struct {
+	messageType string
+	search      service.SearchService
+	cache       *cache.ResponseCache
+	logger      *zap.Logger
+}
+
+// NewUserIndexProcessors 创建用户索引处理器，searchService 为 nil（未启用搜索）时返回 nil
+// responseCache 为 nil 或未启用时不失效缓存
+func NewUserIndexProcessors(searchService service.SearchService, responseCache *cache.ResponseCache, logger *zap.Logger) UserIndexProcessors {
+	if searchService == nil {
+		return nil
+	}
+
+	eventTypes := []string{model.EventUserCreated, model.EventUserUpdated, model.EventUserDeleted, model.EventUserErased}
+	processors := make(UserIndexProcessors, len(eventTypes))
+	for i, eventType := range eventTypes {
+		processors[i] = &UserIndexProcessor{
+			messageType: eventType,
+			search:      searchService,
+			cache:       responseCache,
+			logger:      logger,
+		}
+	}
+	return processors
+}
+
+// GetSupportedMessageType 返回支持的消息类型
+func (p *UserIndexProcessor) GetSupportedMessageType() string {
+	return p.messageType
+}
+
+// NewPayload 返回用于校验的载荷结构，实现 messaging.PayloadValidator 接口
+func (p *UserIndexProcessor) NewPayload() interface{} {
+	return &model.UserEvent{}
+}
+
+// ProcessMessage 更新或删除索引中的用户文档，失败时返回错误由消费者重试
+func (p *UserIndexProcessor) ProcessMessage(ctx context.Context, envelope *messaging.MessageEnvelope) error {
+	var event model.UserEvent
+	if err := envelope.UnmarshalPayload(&event); err != nil {
+		return err
+	}
+
+	log := logger.FromContext(ctx, p.logger).With(zap.Uint("user_id", event.UserID))
+
+	var err error
+	if p.messageType == model.EventUserDeleted || p.messageType == model.EventUserErased {
+		err = p.search.RemoveUser(ctx, event.UserID)
+	} else {
+		err = p.search.IndexUser(ctx, event.UserID)
+	}
+	if err != nil {
+		log.Error("Failed to update user index", zap.Error(err))
+		return err
+	}
+
+	log.Debug("User index updated")
+
+	// 索引已经更新，失效失败只记录警告，缓存按 TTL 过期
+	if err := p.cache.InvalidateEvent(ctx, p.messageType); err != nil {
+		log.Warn("Failed to invalidate response cache for user event", zap.Error(err))
+	}
+	return nil
+}
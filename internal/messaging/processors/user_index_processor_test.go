@@ -0,0 +1,69 @@
+package processors
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/messaging"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// recordingSearchService 记录索引操作
+type recordingSearchService struct {
+	service.SearchService
+	indexed []uint
+	removed []uint
+}
+
+func (s *recordingSearchService) IndexUser(ctx context.Context, id uint) error {
+	s.indexed = append(s.indexed, id)
+	return nil
+}
+
+func (s *recordingSearchService) RemoveUser(ctx context.Context, id uint) error {
+	s.removed = append(s.removed, id)
+	return nil
+}
+
+func TestUserIndexProcessorInvalidatesResponseCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	rc := cache.NewResponseCache(client, &config.Cache{
+		Enabled: true,
+		EventInvalidations: []config.CacheEventInvalidation{
+			{Event: model.EventUserUpdated, Tags: []string{"users"}},
+			{Event: model.EventUserDeleted, Tags: []string{"users"}},
+		},
+	})
+	search := &recordingSearchService{}
+	processors := map[string]*UserIndexProcessor{}
+	for _, p := range NewUserIndexProcessors(search, rc, zap.NewNop()) {
+		processors[p.GetSupportedMessageType()] = p
+	}
+	ctx := context.Background()
+
+	for _, eventType := range []string{model.EventUserUpdated, model.EventUserDeleted} {
+		if err := rc.Set(ctx, "user-7", &cache.CachedResponse{Status: 200}, time.Minute, []string{"users"}); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		envelope := &messaging.MessageEnvelope{MessageType: eventType, Payload: []byte(`{"user_id":7}`)}
+		if err := processors[eventType].ProcessMessage(ctx, envelope); err != nil {
+			t.Fatalf("ProcessMessage(%s) failed: %v", eventType, err)
+		}
+		if _, err := rc.Get(ctx, "user-7"); err != cache.ErrCacheMiss {
+			t.Fatalf("%s did not invalidate the cached user, Get = %v", eventType, err)
+		}
+	}
+	if len(search.indexed) != 1 || len(search.removed) != 1 {
+		t.Fatalf("indexed = %v, removed = %v", search.indexed, search.removed)
+	}
+}
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/jwt"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ClaimsKey 认证通过后令牌声明在 gin.Context 中的键
+const ClaimsKey = "Claims"
+
+// authRoutes 解析后的认证路由
+type authRoutes struct {
+	protected []routeMatcher
+	skip      []routeMatcher
+}
+
+// NewAuth 创建认证中间件，命中 auth.protected 且不在 auth.skip_paths 中的路由必须携带有效的 Bearer Token
+// 认证通过后写入 UserID、Username、Roles 和 Claims，之后的路由策略不再重复解析令牌；revocations 为 nil 时不检查令牌是否已吊销
+func NewAuth(logger *zap.Logger, cfg *config.Auth, tokens *jwt.JWT, revocations *jwt.Revocations) gin.HandlerFunc {
+	routes := compileAuthRoutes(cfg)
+
+	return func(c *gin.Context) {
+		if !routes.requires(c.Request.Method, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		if appErr := authenticate(c, logger, tokens, revocations); appErr != nil {
+			response.AppError(c, appErr)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// Claims 返回认证中间件写入的令牌声明，未认证时返回 false
+func Claims(c *gin.Context) (*jwt.CustomClaims, bool) {
+	value, exists := c.Get(ClaimsKey)
+	if !exists {
+		return nil, false
+	}
+	claims, ok := value.(*jwt.CustomClaims)
+	return claims, ok
+}
+
+// DescribeAuth 返回描述路由是否要求认证的函数，用于路由清单
+func DescribeAuth(cfg *config.Auth) func(method, fullPath string) []string {
+	routes := compileAuthRoutes(cfg)
+
+	return func(method, fullPath string) []string {
+		if !routes.requires(method, fullPath) {
+			return nil
+		}
+		return []string{"auth"}
+	}
+}
+
+// compileAuthRoutes 预处理受保护路由和跳过路由
+func compileAuthRoutes(cfg *config.Auth) *authRoutes {
+	routes := &authRoutes{
+		protected: make([]routeMatcher, 0, len(cfg.Protected)),
+		skip:      make([]routeMatcher, 0, len(cfg.SkipPaths)),
+	}
+	for _, route := range cfg.Protected {
+		routes.protected = append(routes.protected, newRouteMatcher(route.Path, route.Methods))
+	}
+	for _, route := range cfg.SkipPaths {
+		routes.skip = append(routes.skip, newRouteMatcher(route.Path, route.Methods))
+	}
+	return routes
+}
+
+// requires 判断路由是否需要认证，未匹配到路由（404）时不需要，由后续处理返回
+func (r *authRoutes) requires(method, fullPath string) bool {
+	if fullPath == "" {
+		return false
+	}
+	for _, m := range r.skip {
+		if m.matches(method, fullPath) {
+			return false
+		}
+	}
+	for _, m := range r.protected {
+		if m.matches(method, fullPath) {
+			return true
+		}
+	}
+	return false
+}
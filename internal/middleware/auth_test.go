@@ -0,0 +1,183 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/jwt"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestTokens 创建使用 HS256 签名的令牌工具
+func newTestTokens(t *testing.T) *jwt.JWT {
+	t.Helper()
+	tokens, err := jwt.NewJWT(&config.Config{JWT: config.JWT{Secret: "test-secret", ExpireDuration: time.Hour}}, clock.Frozen())
+	if err != nil {
+		t.Fatalf("NewJWT failed: %v", err)
+	}
+	return tokens
+}
+
+// newTestRedis 启动 miniredis 并返回连接它的客户端
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	return server, client
+}
+
+// bearer 为用户签发令牌并返回 Authorization 头的值
+func bearer(t *testing.T, tokens *jwt.JWT, userID uint, roles ...string) string {
+	t.Helper()
+	token, err := tokens.GenerateToken(userID, "user", roles...)
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	return "Bearer " + token
+}
+
+// serve 发送请求并返回响应
+func serve(engine *gin.Engine, method, path, authorization string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+// decodeResponse 解析统一响应格式
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder) response.Response {
+	t.Helper()
+	var resp response.Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", w.Body.String(), err)
+	}
+	return resp
+}
+
+func TestAuth(t *testing.T) {
+	tokens := newTestTokens(t)
+	_, client := newTestRedis(t)
+	revocations := jwt.NewRevocations(client, &config.Config{JWT: config.JWT{ExpireDuration: time.Hour}})
+	if err := revocations.RevokeUser(context.Background(), 2, clock.Frozen().Now()); err != nil {
+		t.Fatalf("RevokeUser failed: %v", err)
+	}
+
+	engine := gin.New()
+	engine.Use(NewAuth(zap.NewNop(), &config.Auth{
+		Enabled: true,
+		Protected: []config.AuthRoute{
+			{Path: "/api/v1/users"},
+			{Path: "/api/v1/users/*"},
+		},
+		SkipPaths: []config.AuthRoute{
+			{Path: "/api/v1/users", Methods: []string{"POST"}},
+			{Path: "/api/v1/users/:id/confirm"},
+		},
+	}, tokens, revocations))
+	handler := func(c *gin.Context) {
+		userID, _ := c.Get("UserID")
+		c.JSON(http.StatusOK, gin.H{"user_id": userID})
+	}
+	engine.GET("/api/v1/users", handler)
+	engine.POST("/api/v1/users", handler)
+	engine.GET("/api/v1/users/:id", handler)
+	engine.POST("/api/v1/users/:id/confirm", handler)
+	engine.GET("/api/v1/public", handler)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		wantStatus    int
+		wantMessageID string
+		wantBody      string
+	}{
+		{name: "skip path takes precedence over protected", method: "POST", path: "/api/v1/users", wantStatus: http.StatusOK},
+		{name: "skip path under protected prefix", method: "POST", path: "/api/v1/users/1/confirm", wantStatus: http.StatusOK},
+		{name: "skip path matches method", method: "GET", path: "/api/v1/users", wantStatus: http.StatusUnauthorized, wantMessageID: "auth.token_missing"},
+		{name: "missing token", method: "GET", path: "/api/v1/users/1", wantStatus: http.StatusUnauthorized, wantMessageID: "auth.token_missing"},
+		{name: "not a bearer token", method: "GET", path: "/api/v1/users/1", authorization: "Basic dXNlcjpwYXNz", wantStatus: http.StatusUnauthorized, wantMessageID: "auth.token_missing"},
+		{name: "invalid token", method: "GET", path: "/api/v1/users/1", authorization: "Bearer not-a-token", wantStatus: http.StatusUnauthorized},
+		{name: "revoked token", method: "GET", path: "/api/v1/users/2", authorization: bearer(t, tokens, 2), wantStatus: http.StatusUnauthorized, wantMessageID: "auth.token_revoked"},
+		{name: "valid token", method: "GET", path: "/api/v1/users/1", authorization: bearer(t, tokens, 1), wantStatus: http.StatusOK, wantBody: `{"user_id":1}`},
+		{name: "unprotected route", method: "GET", path: "/api/v1/public", wantStatus: http.StatusOK},
+		{name: "unmatched route passes through to 404", method: "GET", path: "/api/v1/users/1/unknown", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(engine, tt.method, tt.path, tt.authorization)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantMessageID != "" {
+				if resp := decodeResponse(t, w); resp.MessageID != tt.wantMessageID {
+					t.Fatalf("message_id = %q, want %q", resp.MessageID, tt.wantMessageID)
+				}
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Fatalf("body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestAuthRevocationCheckFailure(t *testing.T) {
+	tokens := newTestTokens(t)
+	server, client := newTestRedis(t)
+	server.Close()
+
+	tests := []struct {
+		name          string
+		failOpen      bool
+		wantStatus    int
+		wantMessageID string
+	}{
+		{name: "fail closed by default", wantStatus: http.StatusServiceUnavailable, wantMessageID: "auth.unavailable"},
+		{name: "fail open when enabled", failOpen: true, wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				JWT: config.JWT{ExpireDuration: time.Hour},
+				Auth: config.Auth{
+					Enabled:            true,
+					Protected:          []config.AuthRoute{{Path: "/api/v1/users/*"}},
+					RevocationFailOpen: tt.failOpen,
+				},
+			}
+			engine := gin.New()
+			engine.Use(NewAuth(zap.NewNop(), &cfg.Auth, tokens, jwt.NewRevocations(client, cfg)))
+			engine.GET("/api/v1/users/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := serve(engine, "GET", "/api/v1/users/1", bearer(t, tokens, 1))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantMessageID != "" {
+				if resp := decodeResponse(t, w); resp.MessageID != tt.wantMessageID {
+					t.Fatalf("message_id = %q, want %q", resp.MessageID, tt.wantMessageID)
+				}
+			}
+		})
+	}
+}
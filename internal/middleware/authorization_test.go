@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/policy"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestAuthorization(t *testing.T) {
+	tokens := newTestTokens(t)
+	registry := policy.NewRegistry()
+	registry.Register(policy.ResourceUser, policy.NewUserPolicy("admin"))
+	registry.Register("order", policy.NewOwnerPolicy(func(ctx context.Context, id string) (uint, bool, error) {
+		return 0, false, fmt.Errorf("owner lookup failed")
+	}))
+
+	engine := gin.New()
+	engine.Use(NewAuthorization(zap.NewNop(), &config.Authorization{
+		Enabled: true,
+		Rules: []config.AuthorizationRule{
+			{Path: "/users/:id", Methods: []string{"GET"}, Resource: policy.ResourceUser, Action: "view"},
+			{Path: "/users/:id/orders/:order_id", Resource: "order", Action: "view", Param: "order_id"},
+			{Path: "/widgets/:id", Resource: "widget", Action: "view"},
+			{Path: "/users/:id/avatar", Resource: policy.ResourceUser, Action: "delete"},
+		},
+	}, registry, tokens, nil))
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	engine.GET("/users/:id", ok)
+	engine.PUT("/users/:id", ok)
+	engine.GET("/users/:id/orders/:order_id", ok)
+	engine.GET("/widgets/:id", ok)
+	engine.GET("/users/:id/avatar", ok)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		wantStatus    int
+		wantMessageID string
+	}{
+		{name: "no rule matched", method: "PUT", path: "/users/1", wantStatus: http.StatusOK},
+		{name: "unauthenticated", method: "GET", path: "/users/1", wantStatus: http.StatusUnauthorized, wantMessageID: "auth.token_missing"},
+		{name: "owner allowed", method: "GET", path: "/users/1", authorization: bearer(t, tokens, 1), wantStatus: http.StatusOK},
+		{name: "non-owner forbidden", method: "GET", path: "/users/2", authorization: bearer(t, tokens, 1), wantStatus: http.StatusForbidden, wantMessageID: "auth.permission_denied"},
+		{name: "admin allowed", method: "GET", path: "/users/2", authorization: bearer(t, tokens, 1, "admin"), wantStatus: http.StatusOK},
+		{name: "unknown resource denied", method: "GET", path: "/widgets/1", authorization: bearer(t, tokens, 1, "admin"), wantStatus: http.StatusForbidden},
+		{name: "unknown action denied", method: "GET", path: "/users/1/avatar", authorization: bearer(t, tokens, 1), wantStatus: http.StatusForbidden},
+		{name: "policy error", method: "GET", path: "/users/1/orders/9", authorization: bearer(t, tokens, 1), wantStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(engine, tt.method, tt.path, tt.authorization)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantMessageID != "" {
+				if resp := decodeResponse(t, w); resp.MessageID != tt.wantMessageID {
+					t.Fatalf("message_id = %q, want %q", resp.MessageID, tt.wantMessageID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package middleware
+
+import (
+	"bytes"
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/pkg/cache"
+	"github.com/hedeqiang/skeleton/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CacheStatusHeader 响应缓存状态头 (RFC 9211)
+const CacheStatusHeader = "Cache-Status"
+
+const cacheStatusName = "skeleton"
+
+// NewResponseCache 创建响应缓存中间件
+// 只缓存配置了规则的 GET 路由的 200 响应；写操作成功后按配置失效对应标签
+// 缓存按登录用户区分，携带 Authorization 头但未经认证中间件解析的请求不使用缓存
+// 注意：命中缓存时响应体中的 request_id 为首次生成该响应的请求 ID
+func NewResponseCache(logger *zap.Logger, rc *cache.ResponseCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := c.FullPath()
+
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			invalidateResponseCache(c, logger, rc, path)
+			return
+		}
+
+		rule, ok := rc.Rule(path)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if strings.Contains(c.GetHeader("Cache-Control"), "no-cache") {
+			c.Header(CacheStatusHeader, cacheStatusName+"; fwd=bypass")
+			c.Next()
+			return
+		}
+
+		// 登录用户和匿名用户的响应分开缓存
+		var user string
+		if userID, exists := c.Get("UserID"); exists {
+			user = fmt.Sprint(userID)
+		} else if c.GetHeader("Authorization") != "" {
+			// 携带了凭证但路由没有经过认证，无法确定响应属于哪个用户，不读写缓存
+			c.Header(CacheStatusHeader, cacheStatusName+"; fwd=bypass")
+			c.Next()
+			return
+		}
+		// 响应中的时间按请求时区输出，时区与语言一起区分缓存
+		locale := c.GetHeader("Accept-Language")
+		if tz := c.GetHeader(i18n.TimezoneHeader); tz != "" {
+			locale += "|" + tz
+		}
+		key := rc.Key(path, c.Request.URL.Query().Encode(), user, locale)
+
+		cached, err := rc.Get(c.Request.Context(), key)
+		if err == nil {
+			c.Header(CacheStatusHeader, cacheStatusName+"; hit")
+			c.Data(cached.Status, cached.ContentType, cached.Body)
+			c.Abort()
+			return
+		}
+		if !stdErrors.Is(err, cache.ErrCacheMiss) {
+			logger.Warn("Failed to read response cache", zap.String("path", path), zap.Error(err))
+		}
+
+		c.Header(CacheStatusHeader, cacheStatusName+"; fwd=miss")
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		if writer.Status() != http.StatusOK {
+			return
+		}
+		resp := &cache.CachedResponse{
+			Status:      writer.Status(),
+			ContentType: writer.Header().Get("Content-Type"),
+			Body:        writer.body.Bytes(),
+		}
+		if err := rc.Set(c.Request.Context(), key, resp, rule.TTL, rule.Tags); err != nil {
+			logger.Warn("Failed to store response cache", zap.String("path", path), zap.Error(err))
+		}
+	}
+}
+
+// invalidateResponseCache 写操作成功后失效配置的缓存标签
+func invalidateResponseCache(c *gin.Context, logger *zap.Logger, rc *cache.ResponseCache, path string) {
+	if c.Writer.Status() >= http.StatusBadRequest {
+		return
+	}
+	tags := rc.InvalidationTags(c.Request.Method, path)
+	if len(tags) == 0 {
+		return
+	}
+	if err := rc.Invalidate(c.Request.Context(), tags...); err != nil {
+		logger.Warn("Failed to invalidate response cache",
+			zap.String("path", path),
+			zap.Strings("tags", tags),
+			zap.Error(err),
+		)
+	}
+}
+
+// bodyCaptureWriter 在写出响应的同时保留一份响应体
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write 写出并记录响应体
+func (w *bodyCaptureWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 写出并记录响应体
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/cache"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// newCacheTestEngine 创建带响应缓存的路由，/items 返回调用次数，status 查询参数指定状态码
+// 携带有效令牌的请求先经过认证，模拟可选登录的公开接口
+func newCacheTestEngine(t *testing.T) (*gin.Engine, *int) {
+	t.Helper()
+	_, client := newTestRedis(t)
+	rc := cache.NewResponseCache(client, &config.Cache{
+		Enabled:    true,
+		DefaultTTL: time.Minute,
+		Rules:      []config.CacheRule{{Path: "/items", Tags: []string{"items"}}},
+		Invalidations: []config.CacheInvalidation{
+			{Method: "POST", Path: "/items", Tags: []string{"items"}},
+		},
+	})
+	tokens := newTestTokens(t)
+
+	calls := 0
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		if strings.HasPrefix(c.GetHeader("Authorization"), "Bearer ") {
+			// 令牌无效时不认证，由缓存中间件按未认证的凭证处理
+			_ = authenticate(c, zap.NewNop(), tokens, nil)
+		}
+	})
+	engine.Use(NewResponseCache(zap.NewNop(), rc))
+	engine.GET("/items", func(c *gin.Context) {
+		calls++
+		status := http.StatusOK
+		if c.Query("status") == "500" {
+			status = http.StatusInternalServerError
+		}
+		userID, _ := c.Get("UserID")
+		c.JSON(status, gin.H{"calls": calls, "user_id": userID, "page": c.Query("page")})
+	})
+	engine.POST("/items", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	return engine, &calls
+}
+
+type cacheRequest struct {
+	method        string
+	path          string
+	authorization string
+	cacheControl  string
+	wantStatus    string // 期望的 Cache-Status
+	wantBody      string // 响应体应包含的片段
+}
+
+func TestResponseCache(t *testing.T) {
+	tokens := newTestTokens(t)
+	alice, bob := bearer(t, tokens, 1), bearer(t, tokens, 2)
+
+	tests := []struct {
+		name      string
+		requests  []cacheRequest
+		wantCalls int
+	}{
+		{
+			name: "repeated request hits",
+			requests: []cacheRequest{
+				{path: "/items?page=1", wantStatus: "skeleton; fwd=miss"},
+				{path: "/items?page=1", wantStatus: "skeleton; hit"},
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "query string is part of the key",
+			requests: []cacheRequest{
+				{path: "/items?page=1", wantStatus: "skeleton; fwd=miss", wantBody: `"page":"1"`},
+				{path: "/items?page=2", wantStatus: "skeleton; fwd=miss", wantBody: `"page":"2"`},
+				{path: "/items", wantStatus: "skeleton; fwd=miss"},
+				// 参数顺序不同的相同查询共用缓存
+				{path: "/items?page=1&sort=name", wantStatus: "skeleton; fwd=miss"},
+				{path: "/items?sort=name&page=1", wantStatus: "skeleton; hit"},
+			},
+			wantCalls: 4,
+		},
+		{
+			name: "non-2xx responses are not cached",
+			requests: []cacheRequest{
+				{path: "/items?status=500", wantStatus: "skeleton; fwd=miss"},
+				{path: "/items?status=500", wantStatus: "skeleton; fwd=miss"},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "no-cache bypasses the cache",
+			requests: []cacheRequest{
+				{path: "/items", wantStatus: "skeleton; fwd=miss"},
+				{path: "/items", cacheControl: "no-cache", wantStatus: "skeleton; fwd=bypass"},
+				{path: "/items", wantStatus: "skeleton; hit"},
+			},
+			wantCalls: 2,
+		},
+		{
+			name: "authenticated and anonymous responses are cached separately",
+			requests: []cacheRequest{
+				{path: "/items", wantStatus: "skeleton; fwd=miss", wantBody: `"user_id":null`},
+				{path: "/items", authorization: alice, wantStatus: "skeleton; fwd=miss", wantBody: `"user_id":1`},
+				{path: "/items", authorization: bob, wantStatus: "skeleton; fwd=miss", wantBody: `"user_id":2`},
+				{path: "/items", authorization: alice, wantStatus: "skeleton; hit", wantBody: `"user_id":1`},
+				{path: "/items", wantStatus: "skeleton; hit", wantBody: `"user_id":null`},
+			},
+			wantCalls: 3,
+		},
+		{
+			name: "unauthenticated credentials bypass the cache",
+			requests: []cacheRequest{
+				{path: "/items", wantStatus: "skeleton; fwd=miss"},
+				{path: "/items", authorization: "Bearer invalid", wantStatus: "skeleton; fwd=bypass"},
+				{path: "/items", authorization: "Basic dXNlcjpwYXNz", wantStatus: "skeleton; fwd=bypass"},
+			},
+			wantCalls: 3,
+		},
+		{
+			name: "successful write invalidates tags",
+			requests: []cacheRequest{
+				{path: "/items", wantStatus: "skeleton; fwd=miss"},
+				{method: "POST", path: "/items"},
+				{path: "/items", wantStatus: "skeleton; fwd=miss"},
+			},
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, calls := newCacheTestEngine(t)
+			for i, r := range tt.requests {
+				method := r.method
+				if method == "" {
+					method = "GET"
+				}
+				req := httptest.NewRequest(method, r.path, nil)
+				if r.authorization != "" {
+					req.Header.Set("Authorization", r.authorization)
+				}
+				if r.cacheControl != "" {
+					req.Header.Set("Cache-Control", r.cacheControl)
+				}
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, req)
+
+				if got := w.Header().Get(CacheStatusHeader); got != r.wantStatus {
+					t.Fatalf("request %d: Cache-Status = %q, want %q", i+1, got, r.wantStatus)
+				}
+				if !strings.Contains(w.Body.String(), r.wantBody) {
+					t.Fatalf("request %d: body = %s, want it to contain %s", i+1, w.Body.String(), r.wantBody)
+				}
+			}
+			if *calls != tt.wantCalls {
+				t.Fatalf("handler calls = %d, want %d", *calls, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// defaultDBPoolRetryAfter 未配置时拒绝请求的 Retry-After
+const defaultDBPoolRetryAfter = 5 * time.Second
+
+// shedExemptPaths 不参与降载的系统路由，避免连接池饱和或实例过载时探活失败导致实例被重启，
+// 或其他服务无法获取 JWKS 校验令牌
+var shedExemptPaths = []string{"/health", "/ready", "/ping", "/version", "/metrics", "/debug/", "/.well-known/"}
+
+// NewDBPoolShedding 创建连接池降载中间件
+// 连接池监控判定饱和时直接返回 503，避免请求在连接池上排队直到超时；饱和状态按采样间隔更新
+func NewDBPoolShedding(logger *zap.Logger, monitor *database.PoolMonitor, cfg config.DBPool) gin.HandlerFunc {
+	retryAfter := cfg.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = defaultDBPoolRetryAfter
+	}
+	retryAfterSeconds := strconv.Itoa(int(retryAfter.Seconds()))
+
+	return func(c *gin.Context) {
+		if !monitor.Saturated() || shedExempt(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		monitor.RecordShed()
+		logger.Debug("Request shed due to database pool saturation",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+		)
+		c.Header("Retry-After", retryAfterSeconds)
+		response.Error(c, http.StatusServiceUnavailable, "服务繁忙，请稍后再试")
+		c.Abort()
+	}
+}
+
+// shedExempt 以 / 结尾的路径按前缀匹配，其余精确匹配
+func shedExempt(path string) bool {
+	for _, exempt := range shedExemptPaths {
+		if path == exempt || (strings.HasSuffix(exempt, "/") && strings.HasPrefix(path, exempt)) {
+			return true
+		}
+	}
+	return false
+}
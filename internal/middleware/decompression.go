@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// defaultDecompressedMaxSize 未配置时解压后请求体的最大字节数
+const defaultDecompressedMaxSize = 10 << 20
+
+// errDecompressedTooLarge 解压后的请求体超过上限
+var errDecompressedTooLarge = errors.New("decompressed request body too large")
+
+// decompressors 支持的 Content-Encoding，deflate 按 HTTP 规范为 zlib 格式
+var decompressors = map[string]func(io.Reader) (io.Reader, error){
+	"gzip":    func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+	"deflate": func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+}
+
+// NewRequestDecompression 创建请求体解压中间件
+// 按 Content-Encoding 解压请求体后替换 c.Request.Body，并移除 Content-Encoding 头，后续中间件和 handler 读到的是原始数据。
+// 解压在内存中完成，解压后超过 max_size 返回 413，防止压缩炸弹；数据损坏返回 400；
+// 不支持的编码或多层编码返回 415，并在 Accept-Encoding 响应头中列出支持的编码
+func NewRequestDecompression(logger *zap.Logger, cfg config.Decompression) gin.HandlerFunc {
+	maxSize := cfg.MaxSize
+	if maxSize <= 0 {
+		maxSize = defaultDecompressedMaxSize
+	}
+	encodings := cfg.Encodings
+	if len(encodings) == 0 {
+		encodings = []string{"gzip", "deflate"}
+	}
+	accepted := make(map[string]func(io.Reader) (io.Reader, error), len(encodings))
+	names := make([]string, 0, len(encodings))
+	for _, encoding := range encodings {
+		encoding = strings.ToLower(encoding)
+		decompress, ok := decompressors[encoding]
+		if !ok {
+			logger.Warn("Unsupported request decompression encoding ignored", zap.String("encoding", encoding))
+			continue
+		}
+		accepted[encoding] = decompress
+		names = append(names, encoding)
+	}
+	acceptEncoding := strings.Join(names, ", ")
+
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" || encoding == "identity" {
+			c.Next()
+			return
+		}
+
+		decompress, ok := accepted[encoding]
+		if !ok {
+			c.Header("Accept-Encoding", acceptEncoding)
+			response.Error(c, http.StatusUnsupportedMediaType, "不支持的请求体编码: "+encoding)
+			c.Abort()
+			return
+		}
+
+		body, err := decompressBody(c.Request.Body, decompress, maxSize)
+		switch {
+		case errors.Is(err, errDecompressedTooLarge):
+			response.Error(c, http.StatusRequestEntityTooLarge, "解压后的请求体超过 "+strconv.FormatInt(maxSize, 10)+" 字节")
+			c.Abort()
+			return
+		case err != nil:
+			logger.Debug("Failed to decompress request body",
+				zap.String("encoding", encoding),
+				zap.String("path", c.Request.URL.Path),
+				zap.Error(err),
+			)
+			response.Error(c, http.StatusBadRequest, "请求体解压失败")
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		c.Request.Header.Del("Content-Encoding")
+		c.Next()
+	}
+}
+
+// decompressBody 读取并解压请求体，最多读取 maxSize+1 字节以判断是否超限
+func decompressBody(body io.ReadCloser, decompress func(io.Reader) (io.Reader, error), maxSize int64) ([]byte, error) {
+	defer body.Close()
+
+	reader, err := decompress(body)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, errDecompressedTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func gzipBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatalf("gzip write failed: %v", err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func zlibBytes(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatalf("zlib write failed: %v", err)
+	}
+	w.Close()
+	return buf.Bytes()
+}
+
+func TestRequestDecompression(t *testing.T) {
+	engine := gin.New()
+	engine.Use(NewRequestDecompression(zap.NewNop(), config.Decompression{MaxSize: 64}))
+	engine.POST("/echo", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.Header("X-Content-Encoding", c.GetHeader("Content-Encoding"))
+		c.Header("X-Content-Length", c.GetHeader("Content-Length"))
+		c.String(http.StatusOK, string(body))
+	})
+
+	payload := `{"name":"alice"}`
+	corrupt := gzipBytes(t, payload)
+	corrupt = corrupt[:len(corrupt)-6]
+
+	tests := []struct {
+		name          string
+		encoding      string
+		body          []byte
+		wantStatus    int
+		wantBody      string
+		wantAcceptEnc bool
+	}{
+		{name: "gzip", encoding: "gzip", body: gzipBytes(t, payload), wantStatus: http.StatusOK, wantBody: payload},
+		{name: "deflate", encoding: "deflate", body: zlibBytes(t, payload), wantStatus: http.StatusOK, wantBody: payload},
+		{name: "encoding is case insensitive", encoding: "GZIP", body: gzipBytes(t, payload), wantStatus: http.StatusOK, wantBody: payload},
+		{name: "identity passes through", encoding: "identity", body: []byte(payload), wantStatus: http.StatusOK, wantBody: payload},
+		{name: "uncompressed passes through", body: []byte(payload), wantStatus: http.StatusOK, wantBody: payload},
+		{name: "exactly max size", encoding: "gzip", body: gzipBytes(t, strings.Repeat("a", 64)), wantStatus: http.StatusOK, wantBody: strings.Repeat("a", 64)},
+		{name: "over max size", encoding: "gzip", body: gzipBytes(t, strings.Repeat("a", 65)), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "corrupt stream", encoding: "gzip", body: corrupt, wantStatus: http.StatusBadRequest},
+		{name: "not compressed", encoding: "deflate", body: []byte(payload), wantStatus: http.StatusBadRequest},
+		{name: "unsupported encoding", encoding: "br", body: []byte(payload), wantStatus: http.StatusUnsupportedMediaType, wantAcceptEnc: true},
+		{name: "stacked encodings", encoding: "gzip, deflate", body: zlibBytes(t, string(gzipBytes(t, payload))), wantStatus: http.StatusUnsupportedMediaType, wantAcceptEnc: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/echo", bytes.NewReader(tt.body))
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantBody != "" {
+				if w.Body.String() != tt.wantBody {
+					t.Fatalf("body = %q, want %q", w.Body.String(), tt.wantBody)
+				}
+				// 解压后移除 Content-Encoding 并按解压后的长度设置 Content-Length
+				if tt.encoding != "" && tt.encoding != "identity" {
+					if got := w.Header().Get("X-Content-Encoding"); got != "" {
+						t.Fatalf("Content-Encoding = %q, want removed", got)
+					}
+					if got := w.Header().Get("X-Content-Length"); got != strconv.Itoa(len(tt.wantBody)) {
+						t.Fatalf("Content-Length = %q, want %d", got, len(tt.wantBody))
+					}
+				}
+			}
+			if got := w.Header().Get("Accept-Encoding"); tt.wantAcceptEnc != (got != "") {
+				t.Fatalf("Accept-Encoding = %q", got)
+			} else if tt.wantAcceptEnc && got != "gzip, deflate" {
+				t.Fatalf("Accept-Encoding = %q, want %q", got, "gzip, deflate")
+			}
+		})
+	}
+}
+
+func TestRequestDecompressionEncodings(t *testing.T) {
+	// 只开启 gzip 时 deflate 返回 415，未知的配置项被忽略
+	engine := gin.New()
+	engine.Use(NewRequestDecompression(zap.NewNop(), config.Decompression{Encodings: []string{"gzip", "zstd"}}))
+	engine.POST("/echo", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	req := httptest.NewRequest("POST", "/echo", bytes.NewReader(zlibBytes(t, "{}")))
+	req.Header.Set("Content-Encoding", "deflate")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	if w.Code != http.StatusUnsupportedMediaType || w.Header().Get("Accept-Encoding") != "gzip" {
+		t.Fatalf("status = %d, Accept-Encoding = %q", w.Code, w.Header().Get("Accept-Encoding"))
+	}
+}
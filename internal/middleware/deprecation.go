@@ -0,0 +1,202 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// 废弃接口响应头
+const (
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+	LinkHeader        = "Link"
+)
+
+const (
+	defaultDeprecationClientHeader = "X-Client-Name"
+	deprecationDateLayout          = "2006-01-02"
+	// maxDeprecationClients 指标中记录的客户端数量上限，超出后计入 other，避免请求头取值过多导致指标膨胀
+	maxDeprecationClients = 100
+	maxClientNameLength   = 64
+)
+
+var deprecatedRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "http",
+	Name:      "deprecated_requests_total",
+	Help:      "Total number of calls to deprecated endpoints by route and client.",
+}, []string{"method", "route", "client"})
+
+func init() {
+	metrics.Registry.MustRegister(deprecatedRequestsTotal)
+}
+
+// deprecatedRoute 解析后的废弃接口规则
+type deprecatedRoute struct {
+	routeMatcher
+	deprecation string
+	sunset      string
+	sunsetDate  string
+	link        string
+}
+
+// NewDeprecation 创建废弃接口中间件
+// 命中 deprecations.routes 的请求照常处理，响应携带 Deprecation (RFC 9745)、Sunset (RFC 8594) 和 Link 头，
+// 并按客户端计数，接口负责人据此判断何时可以安全下线
+func NewDeprecation(logger *zap.Logger, cfg *config.Deprecations) gin.HandlerFunc {
+	routes := compileDeprecatedRoutes(logger, cfg)
+	header := cfg.ClientHeader
+	if header == "" {
+		header = defaultDeprecationClientHeader
+	}
+	clients := &clientLabels{seen: make(map[string]struct{})}
+
+	return func(c *gin.Context) {
+		route := matchDeprecatedRoute(routes, c.Request.Method, c.FullPath())
+		if route == nil {
+			c.Next()
+			return
+		}
+
+		c.Header(DeprecationHeader, route.deprecation)
+		if route.sunset != "" {
+			c.Header(SunsetHeader, route.sunset)
+		}
+		if route.link != "" {
+			c.Header(LinkHeader, route.link)
+		}
+
+		client := clients.label(c.GetHeader(header))
+		deprecatedRequestsTotal.WithLabelValues(c.Request.Method, c.FullPath(), client).Inc()
+		logger.Debug("Deprecated endpoint called",
+			zap.String("method", c.Request.Method),
+			zap.String("route", c.FullPath()),
+			zap.String("client", client),
+		)
+
+		c.Next()
+	}
+}
+
+// DescribeDeprecation 返回描述路由废弃状态的函数，用于路由清单
+// 结果形如 ["deprecated", "sunset=2026-06-30"]
+func DescribeDeprecation(cfg *config.Deprecations) func(method, fullPath string) []string {
+	routes := compileDeprecatedRoutes(zap.NewNop(), cfg)
+
+	return func(method, fullPath string) []string {
+		route := matchDeprecatedRoute(routes, method, fullPath)
+		if route == nil {
+			return nil
+		}
+		result := []string{"deprecated"}
+		if route.sunsetDate != "" {
+			result = append(result, "sunset="+route.sunsetDate)
+		}
+		return result
+	}
+}
+
+// compileDeprecatedRoutes 预处理规则，日期格式错误时记录错误并忽略对应的日期
+func compileDeprecatedRoutes(logger *zap.Logger, cfg *config.Deprecations) []*deprecatedRoute {
+	routes := make([]*deprecatedRoute, 0, len(cfg.Routes))
+	for _, rule := range cfg.Routes {
+		route := &deprecatedRoute{
+			routeMatcher: newRouteMatcher(rule.Path, rule.Methods),
+			deprecation:  "true",
+		}
+
+		if since, ok := parseDeprecationDate(logger, rule.Path, "since", rule.Since); ok {
+			route.deprecation = "@" + strconv.FormatInt(since.Unix(), 10)
+		}
+		if sunset, ok := parseDeprecationDate(logger, rule.Path, "sunset", rule.Sunset); ok {
+			route.sunset = sunset.Format(http.TimeFormat)
+			route.sunsetDate = rule.Sunset
+		}
+		if rule.Link != "" {
+			route.link = "<" + rule.Link + `>; rel="deprecation"`
+		}
+
+		routes = append(routes, route)
+	}
+	return routes
+}
+
+// parseDeprecationDate 解析 UTC 日期，为空或格式错误时返回 false
+func parseDeprecationDate(logger *zap.Logger, path, field, value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	date, err := time.Parse(deprecationDateLayout, value)
+	if err != nil {
+		logger.Error("Deprecated route has invalid date",
+			zap.String("path", path),
+			zap.String("field", field),
+			zap.String("value", value),
+		)
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// matchDeprecatedRoute 返回第一条匹配的规则
+func matchDeprecatedRoute(routes []*deprecatedRoute, method, fullPath string) *deprecatedRoute {
+	if fullPath == "" {
+		return nil
+	}
+	for _, route := range routes {
+		if route.matches(method, fullPath) {
+			return route
+		}
+	}
+	return nil
+}
+
+// clientLabels 将客户端请求头转换为指标标签，限制取值数量
+type clientLabels struct {
+	mu   sync.Mutex
+	seen map[string]struct{}
+}
+
+// label 未携带请求头时为 unknown，超过上限的新客户端计入 other
+func (l *clientLabels) label(value string) string {
+	client := sanitizeClientName(value)
+	if client == "" {
+		return "unknown"
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.seen[client]; ok {
+		return client
+	}
+	if len(l.seen) >= maxDeprecationClients {
+		return "other"
+	}
+	l.seen[client] = struct{}{}
+	return client
+}
+
+// sanitizeClientName 只保留字母、数字和 .-_/ 字符并截断长度
+func sanitizeClientName(value string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimSpace(value) {
+		if b.Len() >= maxClientNameLength {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', strings.ContainsRune(".-_/", r):
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
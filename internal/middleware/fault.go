@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/fault"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// faultAdminPath 故障注入管理接口，不参与注入，避免规则覆盖 /api/v1/* 时无法再清除
+const faultAdminPath = "/api/v1/admin/faults"
+
+// NewFaultInjection 创建故障注入中间件，按规则为命中的路由注入延迟、错误响应或断开连接
+func NewFaultInjection(logger *zap.Logger, injector *fault.Injector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.FullPath() == faultAdminPath {
+			c.Next()
+			return
+		}
+		decision, ok := injector.Decide(c.Request.Method, c.FullPath())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		if decision.Delay > 0 {
+			timer := time.NewTimer(decision.Delay)
+			select {
+			case <-timer.C:
+			case <-c.Request.Context().Done():
+				timer.Stop()
+				c.Abort()
+				return
+			}
+		}
+
+		switch {
+		case decision.Drop:
+			logger.Info("Fault injected: connection dropped",
+				zap.String("method", c.Request.Method),
+				zap.String("route", c.FullPath()),
+			)
+			dropConnection(c)
+			c.Abort()
+		case decision.Status != 0:
+			logger.Info("Fault injected: error response",
+				zap.String("method", c.Request.Method),
+				zap.String("route", c.FullPath()),
+				zap.Int("status", decision.Status),
+			)
+			response.Error(c, decision.Status, "Injected fault")
+			c.Abort()
+		default:
+			c.Next()
+		}
+	}
+}
+
+// dropConnection 接管并关闭底层连接，客户端看到的是连接被重置；HTTP/2 等不支持接管时返回 502
+func dropConnection(c *gin.Context) {
+	conn, _, err := c.Writer.Hijack()
+	if err != nil {
+		response.Error(c, http.StatusBadGateway, "Injected fault")
+		return
+	}
+	conn.Close()
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/i18n"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewI18n 创建多语言中间件
+// 根据 Accept-Language 匹配请求语言，与 I18n 实例一起写入请求 context，
+// response.AppError 据此翻译错误消息；未注册该中间件时错误消息保持原样。
+// 同时将语言、地区和 X-Timezone 指定的时区作为 i18n.Locale 写入 context，
+// 时区无法识别时以请求语言返回 400
+func NewI18n(bundle *i18n.I18n) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		locale, err := bundle.Locale(c.GetHeader("Accept-Language"), c.GetHeader(i18n.TimezoneHeader))
+		ctx := i18n.NewContext(c.Request.Context(), bundle, locale.Language)
+		c.Request = c.Request.WithContext(i18n.WithLocale(ctx, locale))
+		c.Header("Content-Language", locale.Language.String())
+
+		if err != nil {
+			response.AppError(c, errors.ErrInvalidTimezone)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
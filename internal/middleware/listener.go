@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// internalRoutePaths 启用内部端口后只在内部端口暴露的路由，以 / 结尾的按前缀匹配，其余精确匹配
+var internalRoutePaths = []string{
+	"/health", "/ready", "/ping", "/version", "/metrics", "/debug/",
+	"/api/v1/admin/", "/api/v1/scheduler/",
+}
+
+// internalListenerKey 标记请求来自内部端口的 context key
+type internalListenerKey struct{}
+
+// WithInternalListener 标记请求来自内部端口，用作内部 http.Server 的 BaseContext
+func WithInternalListener(ctx context.Context) context.Context {
+	return context.WithValue(ctx, internalListenerKey{}, true)
+}
+
+// FromInternalListener 请求是否来自内部端口
+func FromInternalListener(ctx context.Context) bool {
+	internal, _ := ctx.Value(internalListenerKey{}).(bool)
+	return internal
+}
+
+// InternalRoute 路由模板是否只在内部端口暴露
+func InternalRoute(path string) bool {
+	for _, internal := range internalRoutePaths {
+		if path == internal || (strings.HasSuffix(internal, "/") && strings.HasPrefix(path, internal)) {
+			return true
+		}
+	}
+	return false
+}
+
+// NewListenerScope 创建端口隔离中间件，公开端口和内部端口共用同一个路由
+// 公开端口上的系统路由、管理接口，以及内部端口上的业务接口和未匹配的路径都返回 404，
+// 需放在降载、认证等中间件之前，被拒绝的请求不再消耗这些资源
+func NewListenerScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if InternalRoute(c.FullPath()) != FromInternalListener(c.Request.Context()) {
+			response.NoRoute(c)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,87 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/loadshed"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// defaultLoadShedRetryAfter 未配置时拒绝请求的 Retry-After
+const defaultLoadShedRetryAfter = 5 * time.Second
+
+// loadShedRoute 解析后的路由优先级
+type loadShedRoute struct {
+	routeMatcher
+	priority string
+}
+
+// NewLoadShedding 创建自适应降载中间件
+// 实例过载时按路由优先级以 503 拒绝请求，系统路由和 critical 优先级的路由始终放行；过载等级按采样间隔更新
+func NewLoadShedding(logger *zap.Logger, monitor *loadshed.Monitor, cfg config.LoadShedding) gin.HandlerFunc {
+	retryAfter := cfg.RetryAfter
+	if retryAfter <= 0 {
+		retryAfter = defaultLoadShedRetryAfter
+	}
+	retryAfterSeconds := strconv.Itoa(int(retryAfter.Seconds()))
+
+	defaultPriority := cfg.DefaultPriority
+	if defaultPriority == "" {
+		defaultPriority = config.LoadShedPriorityNormal
+	}
+	routes := make([]loadShedRoute, 0, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		if !validLoadShedPriority(route.Priority) {
+			logger.Error("Load shedding route has invalid priority, treated as normal",
+				zap.String("path", route.Path),
+				zap.String("priority", route.Priority),
+			)
+		}
+		routes = append(routes, loadShedRoute{routeMatcher: newRouteMatcher(route.Path, route.Methods), priority: route.Priority})
+	}
+
+	return func(c *gin.Context) {
+		if monitor.Level() == 0 || shedExempt(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		priority := defaultPriority
+		for _, route := range routes {
+			if route.matches(c.Request.Method, c.FullPath()) {
+				priority = route.priority
+				break
+			}
+		}
+		if !monitor.Shed(priority) {
+			c.Next()
+			return
+		}
+
+		monitor.RecordShed(priority)
+		logger.Debug("Request shed due to instance overload",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("priority", priority),
+			zap.Int("level", monitor.Level()),
+		)
+		c.Header("Retry-After", retryAfterSeconds)
+		response.Error(c, http.StatusServiceUnavailable, "服务繁忙，请稍后再试")
+		c.Abort()
+	}
+}
+
+// validLoadShedPriority 判断优先级是否合法
+func validLoadShedPriority(priority string) bool {
+	switch priority {
+	case config.LoadShedPriorityCritical, config.LoadShedPriorityHigh, config.LoadShedPriorityNormal, config.LoadShedPriorityLow:
+		return true
+	}
+	return false
+}
@@ -22,6 +22,7 @@ func NewLogger(logger *zap.Logger) gin.HandlerFunc {
 
 		// 从 context 中获取 request id
 		requestID, _ := c.Get("RequestID")
+		traceID, _ := c.Get("TraceID")
 
 		// 记录日志
 		logger.Info("Request",
@@ -33,6 +34,7 @@ func NewLogger(logger *zap.Logger) gin.HandlerFunc {
 			zap.String("user_agent", c.Request.UserAgent()),
 			zap.Duration("latency", latency),
 			zap.Any("request_id", requestID),
+			zap.Any("trace_id", traceID),
 		)
 	}
 }
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NewHTTPMetrics 创建 HTTP 请求指标中间件
+// 按路由模板而不是原始路径记录，未匹配路由的请求记为 unmatched；需在 Recovery 之前注册，panic 的请求同样会被记录
+func NewHTTPMetrics(m *metrics.HTTPMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		m.Observe(c.Request.Method, c.FullPath(), c.Writer.Status(), time.Since(start), c.Request.ContentLength, int64(c.Writer.Size()))
+	}
+}
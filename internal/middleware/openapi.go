@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	stdErrors "errors"
+	"net/http"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/openapi"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// NewOpenAPIValidation 创建 OpenAPI 契约校验中间件
+// 请求不符合规范时记录日志，fail 模式下返回 400；开启 validate_responses 时同时校验响应，
+// 响应已写出，不一致时只记录日志。未在规范中定义的路由只记录 debug 日志
+func NewOpenAPIValidation(logger *zap.Logger, validator *openapi.Validator, cfg config.OpenAPIValidation) gin.HandlerFunc {
+	fail := cfg.Mode == config.OpenAPIModeFail
+
+	return func(c *gin.Context) {
+		// 未匹配路由的请求交给 NoRoute 处理
+		if c.FullPath() == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("route", c.FullPath()),
+			zap.String("request_id", c.GetString("RequestID")),
+		}
+
+		input, err := validator.ValidateRequest(ctx, c.Request)
+		if stdErrors.Is(err, openapi.ErrRouteNotFound) {
+			logger.Debug("Route not documented in OpenAPI spec", fields...)
+			c.Next()
+			return
+		}
+		if err != nil {
+			logger.Warn("Request does not match OpenAPI spec", append(fields, zap.Error(err))...)
+			if fail {
+				response.Error(c, http.StatusBadRequest, "请求与接口文档不符: "+err.Error())
+				c.Abort()
+				return
+			}
+		}
+
+		if !cfg.ValidateResponses {
+			c.Next()
+			return
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		if err := validator.ValidateResponse(ctx, input, writer.Status(), writer.Header(), writer.body.Bytes()); err != nil {
+			logger.Warn("Response does not match OpenAPI spec",
+				append(fields, zap.Int("status", writer.Status()), zap.Error(err))...)
+		}
+	}
+}
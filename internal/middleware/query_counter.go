@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/database"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// QueryCountHeader 调试模式下返回本次请求 SQL 数量的响应头
+const QueryCountHeader = "X-DB-Query-Count"
+
+// NewQueryCounter 创建请求级 SQL 计数中间件
+// exposeHeader 为 true 时（开发环境）在响应头中返回 SQL 数量
+func NewQueryCounter(logger *zap.Logger, cfg config.QueryCounter, exposeHeader bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 慢请求检测已挂载统计时直接复用
+		stats := database.QueryStatsFromContext(c.Request.Context())
+		if stats == nil {
+			var ctx context.Context
+			ctx, stats = database.WithQueryStats(c.Request.Context())
+			c.Request = c.Request.WithContext(ctx)
+		}
+
+		if exposeHeader {
+			c.Writer = &queryCountWriter{ResponseWriter: c.Writer, stats: stats}
+		}
+
+		c.Next()
+
+		requestID, _ := c.Get("RequestID")
+		count := stats.Count()
+
+		if cfg.MaxQueries > 0 && count > cfg.MaxQueries {
+			logger.Warn("Too many queries in single request",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.FullPath()),
+				zap.Int("query_count", count),
+				zap.Int("threshold", cfg.MaxQueries),
+				zap.Any("request_id", requestID),
+			)
+		}
+
+		if cfg.MaxDuplicates > 1 {
+			for sql, n := range stats.Duplicates(cfg.MaxDuplicates) {
+				logger.Warn("Repeated identical query detected, possible N+1",
+					zap.String("method", c.Request.Method),
+					zap.String("path", c.FullPath()),
+					zap.String("sql", sql),
+					zap.Int("times", n),
+					zap.Any("request_id", requestID),
+				)
+			}
+		}
+	}
+}
+
+// queryCountWriter 在写出响应头之前补充 SQL 数量
+type queryCountWriter struct {
+	gin.ResponseWriter
+	stats *database.QueryStats
+}
+
+// WriteHeader 写状态码前设置计数头
+func (w *queryCountWriter) WriteHeader(code int) {
+	w.setHeader()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write 未显式调用 WriteHeader 时同样设置计数头
+func (w *queryCountWriter) Write(data []byte) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 未显式调用 WriteHeader 时同样设置计数头
+func (w *queryCountWriter) WriteString(s string) (int, error) {
+	w.setHeader()
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *queryCountWriter) setHeader() {
+	if !w.Written() {
+		w.Header().Set(QueryCountHeader, strconv.Itoa(w.stats.Count()))
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/database"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// queryCounterUser 查询使用的模型，DryRun 模式下只生成 SQL
+type queryCounterUser struct {
+	ID uint
+}
+
+func (queryCounterUser) TableName() string { return "users" }
+
+func TestQueryCounter(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("Failed to open dry run database: %v", err)
+	}
+	if err := db.Use(&database.QueryCounterPlugin{}); err != nil {
+		t.Fatalf("Failed to register plugin: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		queries        int
+		distinct       bool
+		cfg            config.QueryCounter
+		wantTooMany    bool
+		wantDuplicates bool
+	}{
+		{name: "below threshold", queries: 3, distinct: true, cfg: config.QueryCounter{MaxQueries: 5}},
+		{name: "at threshold", queries: 5, distinct: true, cfg: config.QueryCounter{MaxQueries: 5}},
+		{name: "above threshold", queries: 6, distinct: true, cfg: config.QueryCounter{MaxQueries: 5}, wantTooMany: true},
+		{name: "repeated query", queries: 3, cfg: config.QueryCounter{MaxDuplicates: 3}, wantDuplicates: true},
+		{name: "repeated query below duplicate threshold", queries: 2, cfg: config.QueryCounter{MaxDuplicates: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.WarnLevel)
+			engine := gin.New()
+			engine.Use(NewQueryCounter(zap.New(core), tt.cfg, true))
+			engine.GET("/users", func(c *gin.Context) {
+				tx := db.WithContext(c.Request.Context())
+				for i := 0; i < tt.queries; i++ {
+					var users []queryCounterUser
+					query := tx.Where("id = ?", i)
+					if tt.distinct {
+						// 附加不同的常量条件使 SQL 文本不同，不计为重复查询
+						query = tx.Where("id = ? AND "+strconv.Itoa(i)+" = "+strconv.Itoa(i), i)
+					}
+					if err := query.Find(&users).Error; err != nil {
+						t.Errorf("query failed: %v", err)
+					}
+				}
+				c.String(http.StatusOK, "ok")
+			})
+
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest("GET", "/users", nil))
+
+			if got := w.Header().Get(QueryCountHeader); got != strconv.Itoa(tt.queries) {
+				t.Fatalf("%s = %q, want %d", QueryCountHeader, got, tt.queries)
+			}
+			if got := logs.FilterMessage("Too many queries in single request").Len(); (got > 0) != tt.wantTooMany {
+				t.Fatalf("too many queries warnings = %d, want %v", got, tt.wantTooMany)
+			}
+			if got := logs.FilterMessage("Repeated identical query detected, possible N+1").Len(); (got > 0) != tt.wantDuplicates {
+				t.Fatalf("duplicate query warnings = %d, want %v", got, tt.wantDuplicates)
+			}
+			if tt.wantTooMany {
+				entry := logs.FilterMessage("Too many queries in single request").All()[0]
+				if fields := entry.ContextMap(); fields["query_count"] != int64(tt.queries) || fields["path"] != "/users" {
+					t.Fatalf("unexpected warning fields: %v", fields)
+				}
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"github.com/hedeqiang/skeleton/pkg/trace"
+
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -8,14 +10,19 @@ import (
 // RequestIDHeader is the default header name for request id.
 const RequestIDHeader = "X-Request-ID"
 
+// maxRequestIDLength 上游传入的 request id 最大长度
+const maxRequestIDLength = 128
+
 // RequestID is a middleware that injects a request id into the context of each request.
+// 同时处理 W3C traceparent：沿用上游链路的 trace id 并为本次请求生成新的 span，
+// 上游未传入或格式非法时开启新链路。request id 和 traceparent 都会写入响应头。
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 尝试从 header 中获取 request id
+		// 尝试从 header 中获取 request id，非法的 request id 直接丢弃，避免日志注入
 		requestID := c.Request.Header.Get(RequestIDHeader)
 
 		// 如果 header 中没有，则生成一个新的
-		if requestID == "" {
+		if !validRequestID(requestID) {
 			requestID = uuid.New().String()
 		}
 
@@ -25,7 +32,33 @@ func RequestID() gin.HandlerFunc {
 		// 设置到 response header 中，方便前端或调用方追踪
 		c.Header(RequestIDHeader, requestID)
 
+		// 链路上下文，调用下游服务时通过 trace.FromContext 取出并传递
+		span := trace.New()
+		if parent, err := trace.Parse(c.Request.Header.Get(trace.Header)); err == nil {
+			span = parent.Child()
+		}
+		c.Set("TraceID", span.TraceID)
+		c.Request = c.Request.WithContext(trace.NewContext(c.Request.Context(), span))
+		c.Header(trace.Header, span.String())
+
 		// 继续处理请求
 		c.Next()
 	}
 }
+
+// validRequestID 只接受长度受限的字母、数字和 -_.: 字符
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.', c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,345 @@
+package middleware
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/policy"
+	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/jwt"
+	"github.com/hedeqiang/skeleton/pkg/ratelimit"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 限流响应头
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+)
+
+// routeMatcher 按 Gin 路由模板和方法匹配路由，模板以 /* 结尾时按前缀匹配
+type routeMatcher struct {
+	path    string
+	prefix  bool
+	methods map[string]struct{}
+}
+
+// newRouteMatcher 创建路由匹配器，methods 为空时匹配所有方法
+func newRouteMatcher(path string, methods []string) routeMatcher {
+	m := routeMatcher{path: path}
+	if strings.HasSuffix(path, "/*") {
+		m.prefix = true
+		m.path = strings.TrimSuffix(path, "*")
+	}
+	if len(methods) > 0 {
+		m.methods = make(map[string]struct{}, len(methods))
+		for _, method := range methods {
+			m.methods[strings.ToUpper(method)] = struct{}{}
+		}
+	}
+	return m
+}
+
+// matches 判断是否匹配当前路由
+func (m routeMatcher) matches(method, fullPath string) bool {
+	if len(m.methods) > 0 {
+		if _, ok := m.methods[method]; !ok {
+			return false
+		}
+	}
+	if m.prefix {
+		return strings.HasPrefix(fullPath, m.path)
+	}
+	return fullPath == m.path
+}
+
+// routePolicy 解析后的路由策略
+type routePolicy struct {
+	config.RoutePolicy
+	routeMatcher
+	rateLimit   *config.RateLimitPolicy
+	concurrency *config.ConcurrencyPolicy
+}
+
+// NewRoutePolicy 创建路由策略中间件，按 route_policies.rules 的顺序匹配，第一条匹配的规则生效
+// 缓存 TTL 由响应缓存中间件处理，这里负责认证、角色、限流、并发和超时；revocations 为 nil 时不检查令牌是否已吊销
+func NewRoutePolicy(logger *zap.Logger, cfg *config.RoutePolicies, tokens *jwt.JWT, revocations *jwt.Revocations, limiter *ratelimit.Limiter) gin.HandlerFunc {
+	policies := compileRoutePolicies(logger, cfg)
+	concurrency := ratelimit.NewConcurrencyLimiter()
+
+	return func(c *gin.Context) {
+		rule := matchRoutePolicy(policies, c.Request.Method, c.FullPath())
+		if rule == nil {
+			c.Next()
+			return
+		}
+
+		if rule.requiresAuth() {
+			if appErr := authenticate(c, logger, tokens, revocations); appErr != nil {
+				response.AppError(c, appErr)
+				c.Abort()
+				return
+			}
+			if len(rule.Roles) > 0 && !policy.HasAnyRole(c.GetStringSlice("Roles"), rule.Roles...) {
+				response.Error(c, http.StatusForbidden, "没有访问权限")
+				c.Abort()
+				return
+			}
+			if rule.Owner != "" && !isOwner(c, rule.Owner, cfg.AdminRoles) {
+				response.AppError(c, errors.ErrPermissionDenied)
+				c.Abort()
+				return
+			}
+		}
+
+		if rule.rateLimit != nil && limiter != nil {
+			if !allowRequest(c, logger, limiter, rule) {
+				response.Error(c, http.StatusTooManyRequests, "请求过于频繁，请稍后再试")
+				c.Abort()
+				return
+			}
+		}
+
+		if rule.concurrency != nil {
+			release, ok := acquireSlot(c, concurrency, rule)
+			if !ok {
+				c.Header("Retry-After", "1")
+				if rule.concurrency.Key == config.ConcurrencyKeyRoute {
+					response.Error(c, http.StatusServiceUnavailable, "服务繁忙，请稍后再试")
+				} else {
+					response.Error(c, http.StatusTooManyRequests, "同时进行的请求过多，请稍后再试")
+				}
+				c.Abort()
+				return
+			}
+			defer release()
+		}
+
+		if rule.Timeout > 0 {
+			ctx, cancel := context.WithTimeout(c.Request.Context(), rule.Timeout)
+			defer cancel()
+			c.Request = c.Request.WithContext(ctx)
+
+			c.Next()
+
+			if stdErrors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+				response.Error(c, http.StatusGatewayTimeout, "请求处理超时")
+			}
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// DescribeRoutePolicy 返回描述路由命中策略的函数，用于路由清单审计
+// 结果形如 ["auth", "roles=admin", "owner=id", "rate_limit=default", "concurrency=export", "timeout=30s"]
+func DescribeRoutePolicy(cfg *config.RoutePolicies) func(method, fullPath string) []string {
+	policies := compileRoutePolicies(zap.NewNop(), cfg)
+
+	return func(method, fullPath string) []string {
+		rule := matchRoutePolicy(policies, method, fullPath)
+		if rule == nil {
+			return nil
+		}
+
+		var result []string
+		if rule.requiresAuth() {
+			result = append(result, "auth")
+		}
+		if len(rule.Roles) > 0 {
+			result = append(result, "roles="+strings.Join(rule.Roles, ","))
+		}
+		if rule.Owner != "" {
+			result = append(result, "owner="+rule.Owner)
+		}
+		if rule.rateLimit != nil {
+			result = append(result, "rate_limit="+rule.RateLimit)
+		}
+		if rule.concurrency != nil {
+			result = append(result, "concurrency="+rule.Concurrency)
+		}
+		if rule.Timeout > 0 {
+			result = append(result, "timeout="+rule.Timeout.String())
+		}
+		return result
+	}
+}
+
+// requiresAuth 判断是否需要认证，roles 和 owner 隐含 auth
+func (p *routePolicy) requiresAuth() bool {
+	return p.Auth || len(p.Roles) > 0 || p.Owner != ""
+}
+
+// compileRoutePolicies 预处理策略，引用了不存在的限流或并发策略时记录错误并忽略该限制
+func compileRoutePolicies(logger *zap.Logger, cfg *config.RoutePolicies) []*routePolicy {
+	policies := make([]*routePolicy, 0, len(cfg.Rules))
+	for _, rule := range cfg.Rules {
+		policy := &routePolicy{RoutePolicy: rule, routeMatcher: newRouteMatcher(rule.Path, rule.Methods)}
+
+		if rule.RateLimit != "" {
+			limit, ok := cfg.RateLimits[rule.RateLimit]
+			if !ok || limit.Requests <= 0 || limit.Window <= 0 {
+				logger.Error("Route policy references invalid rate limit",
+					zap.String("path", rule.Path),
+					zap.String("rate_limit", rule.RateLimit),
+				)
+			} else {
+				policy.rateLimit = &limit
+			}
+		}
+
+		if rule.Concurrency != "" {
+			limit, ok := cfg.ConcurrencyLimits[rule.Concurrency]
+			if !ok || limit.Limit <= 0 || !validConcurrencyKey(limit.Key) {
+				logger.Error("Route policy references invalid concurrency limit",
+					zap.String("path", rule.Path),
+					zap.String("concurrency", rule.Concurrency),
+				)
+			} else {
+				if limit.Key == "" {
+					limit.Key = config.ConcurrencyKeyRoute
+				}
+				if limit.APIKeyHeader == "" {
+					limit.APIKeyHeader = "X-API-Key"
+				}
+				policy.concurrency = &limit
+			}
+		}
+
+		policies = append(policies, policy)
+	}
+	return policies
+}
+
+// matchRoutePolicy 返回第一条匹配的策略
+func matchRoutePolicy(policies []*routePolicy, method, fullPath string) *routePolicy {
+	if fullPath == "" {
+		return nil
+	}
+	for _, policy := range policies {
+		if policy.matches(method, fullPath) {
+			return policy
+		}
+	}
+	return nil
+}
+
+// authenticate 校验 Bearer Token 并将用户信息写入 gin.Context，失败时返回对应的 AppError
+// 管理员禁用账户或强制重置密码后吊销的令牌被拒绝；Redis 不可用时返回 503，仅在开启 auth.revocation_fail_open 时放行并记录日志
+func authenticate(c *gin.Context, logger *zap.Logger, tokens *jwt.JWT, revocations *jwt.Revocations) *errors.AppError {
+	if _, exists := c.Get("UserID"); exists {
+		return nil
+	}
+	if tokens == nil {
+		return errors.ErrTokenMissing
+	}
+
+	header := c.GetHeader("Authorization")
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return errors.ErrTokenMissing
+	}
+
+	claims, err := tokens.ParseToken(token)
+	if err != nil {
+		return jwt.TokenError(err)
+	}
+	revoked, err := revocations.Revoked(c.Request.Context(), claims)
+	if err != nil {
+		if !revocations.FailOpen() {
+			logger.Error("Token revocation check failed, request rejected", zap.Uint("user_id", claims.UserID), zap.Error(err))
+			return errors.ErrAuthUnavailable
+		}
+		logger.Warn("Token revocation check failed, token accepted", zap.Uint("user_id", claims.UserID), zap.Error(err))
+	}
+	if revoked {
+		return errors.ErrTokenRevoked
+	}
+
+	c.Set("UserID", claims.UserID)
+	c.Set("Username", claims.Username)
+	c.Set("Roles", claims.Roles)
+	c.Set(ClaimsKey, claims)
+	// 写操作由审计插件据此填充 CreatedBy、UpdatedBy，资源级授权策略据此判断所有者和角色
+	ctx := database.WithActor(c.Request.Context(), claims.UserID)
+	ctx = policy.WithSubject(ctx, policy.Subject{UserID: claims.UserID, Roles: claims.Roles})
+	c.Request = c.Request.WithContext(ctx)
+	return nil
+}
+
+// isOwner 判断当前用户是否为路由参数 param 指定的用户本人，或拥有 adminRoles 中的任一角色
+func isOwner(c *gin.Context, param string, adminRoles []string) bool {
+	subject, ok := policy.SubjectFromContext(c.Request.Context())
+	if !ok {
+		return false
+	}
+	return c.Param(param) == strconv.FormatUint(uint64(subject.UserID), 10) || subject.HasAnyRole(adminRoles...)
+}
+
+// allowRequest 执行限流，Redis 不可用时放行并记录日志
+func allowRequest(c *gin.Context, logger *zap.Logger, limiter *ratelimit.Limiter, policy *routePolicy) bool {
+	subject := "ip:" + c.ClientIP()
+	if policy.rateLimit.Key == "user" {
+		if userID, exists := c.Get("UserID"); exists {
+			subject = fmt.Sprintf("user:%v", userID)
+		}
+	}
+	key := policy.RateLimit + ":" + subject
+
+	result, err := limiter.Allow(c.Request.Context(), key, policy.rateLimit.Requests, policy.rateLimit.Window)
+	if err != nil {
+		logger.Warn("Rate limit check failed, request allowed",
+			zap.String("rate_limit", policy.RateLimit),
+			zap.Error(err),
+		)
+		return true
+	}
+
+	c.Header(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+	c.Header(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+	if !result.Allowed {
+		c.Header("Retry-After", strconv.Itoa(int(result.ResetIn.Seconds())+1))
+	}
+	return result.Allowed
+}
+
+// validConcurrencyKey 判断并发维度是否合法，为空时使用 route
+func validConcurrencyKey(key string) bool {
+	switch key {
+	case "", config.ConcurrencyKeyRoute, config.ConcurrencyKeyUser, config.ConcurrencyKeyIP, config.ConcurrencyKeyAPIKey:
+		return true
+	}
+	return false
+}
+
+// acquireSlot 按策略维度获取并发槽位，排队期间客户端断开时同样视为失败
+func acquireSlot(c *gin.Context, limiter *ratelimit.ConcurrencyLimiter, policy *routePolicy) (func(), bool) {
+	subject := "route"
+	switch policy.concurrency.Key {
+	case config.ConcurrencyKeyUser:
+		subject = "ip:" + c.ClientIP()
+		if userID, exists := c.Get("UserID"); exists {
+			subject = fmt.Sprintf("user:%v", userID)
+		}
+	case config.ConcurrencyKeyAPIKey:
+		subject = "ip:" + c.ClientIP()
+		if apiKey := c.GetHeader(policy.concurrency.APIKeyHeader); apiKey != "" {
+			subject = "api_key:" + apiKey
+		}
+	case config.ConcurrencyKeyIP:
+		subject = "ip:" + c.ClientIP()
+	}
+	key := policy.Concurrency + ":" + subject
+
+	return limiter.Acquire(c.Request.Context(), key, policy.concurrency.Limit, policy.concurrency.QueueTimeout)
+}
@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// testRoutePolicies 覆盖各类策略的路由配置
+func testRoutePolicies() *config.RoutePolicies {
+	return &config.RoutePolicies{
+		Enabled: true,
+		RateLimits: map[string]config.RateLimitPolicy{
+			"tight": {Requests: 2, Window: time.Minute, Key: "ip"},
+		},
+		ConcurrencyLimits: map[string]config.ConcurrencyPolicy{
+			"single": {Limit: 1},
+		},
+		AdminRoles: []string{"admin"},
+		Rules: []config.RoutePolicy{
+			{Path: "/api/v1/admin/health"},
+			{Path: "/api/v1/admin/*", Roles: []string{"admin"}},
+			{Path: "/api/v1/items", Methods: []string{"POST"}, Auth: true},
+			{Path: "/api/v1/users/:id/export", Owner: "id"},
+			{Path: "/api/v1/limited", RateLimit: "tight"},
+			{Path: "/api/v1/single", Concurrency: "single"},
+			{Path: "/api/v1/slow", Timeout: 20 * time.Millisecond},
+			{Path: "/api/v1/*", Auth: true},
+		},
+	}
+}
+
+func TestRoutePolicyAccess(t *testing.T) {
+	tokens := newTestTokens(t)
+	engine := gin.New()
+	engine.Use(NewRoutePolicy(zap.NewNop(), testRoutePolicies(), tokens, nil, nil))
+	ok := func(c *gin.Context) { c.String(http.StatusOK, "ok") }
+	engine.GET("/api/v1/admin/health", ok)
+	engine.GET("/api/v1/admin/users", ok)
+	engine.GET("/api/v1/items", ok)
+	engine.POST("/api/v1/items", ok)
+	engine.POST("/api/v1/users/:id/export", ok)
+	engine.GET("/public", ok)
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		wantStatus    int
+	}{
+		{name: "earlier rule wins over prefix rule", method: "GET", path: "/api/v1/admin/health", wantStatus: http.StatusOK},
+		{name: "roles imply auth", method: "GET", path: "/api/v1/admin/users", wantStatus: http.StatusUnauthorized},
+		{name: "missing role", method: "GET", path: "/api/v1/admin/users", authorization: bearer(t, tokens, 1), wantStatus: http.StatusForbidden},
+		{name: "matching role", method: "GET", path: "/api/v1/admin/users", authorization: bearer(t, tokens, 1, "admin"), wantStatus: http.StatusOK},
+		{name: "method-specific rule", method: "POST", path: "/api/v1/items", wantStatus: http.StatusUnauthorized},
+		{name: "other methods fall through to later rules", method: "GET", path: "/api/v1/items", wantStatus: http.StatusUnauthorized},
+		{name: "auth with valid token", method: "GET", path: "/api/v1/items", authorization: bearer(t, tokens, 1), wantStatus: http.StatusOK},
+		{name: "invalid token", method: "GET", path: "/api/v1/items", authorization: "Bearer invalid", wantStatus: http.StatusUnauthorized},
+		{name: "owner implies auth", method: "POST", path: "/api/v1/users/1/export", wantStatus: http.StatusUnauthorized},
+		{name: "owner allowed", method: "POST", path: "/api/v1/users/1/export", authorization: bearer(t, tokens, 1), wantStatus: http.StatusOK},
+		{name: "non-owner forbidden", method: "POST", path: "/api/v1/users/2/export", authorization: bearer(t, tokens, 1), wantStatus: http.StatusForbidden},
+		{name: "admin role bypasses owner", method: "POST", path: "/api/v1/users/2/export", authorization: bearer(t, tokens, 1, "admin"), wantStatus: http.StatusOK},
+		{name: "no rule matched", method: "GET", path: "/public", wantStatus: http.StatusOK},
+		{name: "unmatched route", method: "GET", path: "/api/v1/unknown", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(engine, tt.method, tt.path, tt.authorization)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRoutePolicyRateLimitPerKey(t *testing.T) {
+	_, client := newTestRedis(t)
+	engine := gin.New()
+	engine.Use(NewRoutePolicy(zap.NewNop(), testRoutePolicies(), nil, nil, ratelimit.New(client)))
+	engine.GET("/api/v1/limited", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+	request := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/limited", nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := request("10.0.0.1"); w.Code != http.StatusOK {
+			t.Fatalf("request %d status = %d", i+1, w.Code)
+		}
+	}
+	w := request("10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" || w.Header().Get(RateLimitRemainingHeader) != "0" {
+		t.Fatalf("unexpected rate limit headers: %v", w.Header())
+	}
+	// 按 IP 计数，其他客户端不受影响
+	if w := request("10.0.0.2"); w.Code != http.StatusOK {
+		t.Fatalf("other client status = %d, want 200", w.Code)
+	}
+}
+
+func TestRoutePolicyConcurrencyLimit(t *testing.T) {
+	engine := gin.New()
+	engine.Use(NewRoutePolicy(zap.NewNop(), testRoutePolicies(), nil, nil, nil))
+	entered, release := make(chan struct{}), make(chan struct{})
+	engine.GET("/api/v1/single", func(c *gin.Context) {
+		entered <- struct{}{}
+		<-release
+		c.String(http.StatusOK, "ok")
+	})
+
+	first := make(chan int)
+	go func() { first <- serve(engine, "GET", "/api/v1/single", "").Code }()
+	<-entered
+
+	// route 维度的槽位占满且不排队时立即返回 503
+	w := serve(engine, "GET", "/api/v1/single", "")
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("status = %d, Retry-After = %q; want 503 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(release)
+	if code := <-first; code != http.StatusOK {
+		t.Fatalf("first request status = %d", code)
+	}
+	// 槽位释放后可以再次进入
+	go func() { <-entered }()
+	if w := serve(engine, "GET", "/api/v1/single", ""); w.Code != http.StatusOK {
+		t.Fatalf("status after release = %d", w.Code)
+	}
+}
+
+func TestRoutePolicyTimeout(t *testing.T) {
+	engine := gin.New()
+	engine.Use(NewRoutePolicy(zap.NewNop(), testRoutePolicies(), nil, nil, nil))
+	engine.GET("/api/v1/slow", func(c *gin.Context) {
+		<-c.Request.Context().Done()
+	})
+
+	if w := serve(engine, "GET", "/api/v1/slow", ""); w.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504", w.Code)
+	}
+}
+
+func TestDescribeRoutePolicy(t *testing.T) {
+	describe := DescribeRoutePolicy(testRoutePolicies())
+
+	tests := []struct {
+		method, path string
+		want         []string
+	}{
+		{"GET", "/api/v1/admin/users", []string{"auth", "roles=admin"}},
+		{"POST", "/api/v1/users/:id/export", []string{"auth", "owner=id"}},
+		{"GET", "/api/v1/limited", []string{"rate_limit=tight"}},
+		{"GET", "/api/v1/slow", []string{"timeout=20ms"}},
+		{"GET", "/public", nil},
+	}
+	for _, tt := range tests {
+		if got := describe(tt.method, tt.path); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("describe(%s %s) = %v, want %v", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
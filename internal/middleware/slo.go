@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sloRoute 路由与服务等级目标的对应关系
+type sloRoute struct {
+	routeMatcher
+	name string
+}
+
+// NewSLO 创建服务等级目标统计中间件，按 slo.objectives 的顺序匹配路由模板，第一条匹配的目标生效
+// 未匹配任何目标和未匹配路由的请求不计入；与 http_metrics 一样需在 Recovery 之前注册，panic 的请求计为失败
+func NewSLO(m *metrics.SLOMetrics, cfg config.SLO) gin.HandlerFunc {
+	routes := make([]sloRoute, 0, len(cfg.Objectives))
+	for _, objective := range cfg.Objectives {
+		routes = append(routes, sloRoute{routeMatcher: newRouteMatcher(objective.Path, objective.Methods), name: objective.Name})
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			return
+		}
+		for _, r := range routes {
+			if r.matches(c.Request.Method, route) {
+				m.Observe(r.name, c.Writer.Status(), time.Since(start))
+				return
+			}
+		}
+	}
+}
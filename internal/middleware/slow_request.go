@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"runtime/pprof"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/timing"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 慢请求检测默认值
+const (
+	defaultSlowRequestThreshold = time.Second
+	defaultSlowRequestMaxCalls  = 5
+)
+
+// NewSlowRequest 创建慢请求检测中间件
+// 请求耗时超过阈值时记录完整路由、SQL 数量和下游调用耗时，仅凭日志即可定位慢在哪里
+func NewSlowRequest(logger *zap.Logger, cfg config.SlowRequest) gin.HandlerFunc {
+	threshold := cfg.Threshold
+	if threshold <= 0 {
+		threshold = defaultSlowRequestThreshold
+	}
+	maxCalls := cfg.MaxCalls
+	if maxCalls <= 0 {
+		maxCalls = defaultSlowRequestMaxCalls
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		ctx := c.Request.Context()
+
+		// 与 QueryCounter 共用同一份 SQL 统计
+		stats := database.QueryStatsFromContext(ctx)
+		if stats == nil {
+			ctx, stats = database.WithQueryStats(ctx)
+		}
+		ctx, recorder := timing.WithRecorder(ctx)
+
+		var labels pprof.LabelSet
+		if cfg.PprofLabels {
+			labels = pprof.Labels("route", routeOf(c), "method", c.Request.Method)
+			ctx = pprof.WithLabels(ctx, labels)
+			pprof.SetGoroutineLabels(ctx)
+			defer pprof.SetGoroutineLabels(context.Background())
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		latency := time.Since(start)
+		if latency < threshold {
+			return
+		}
+
+		requestID, _ := c.Get("RequestID")
+		traceID, _ := c.Get("TraceID")
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("route", routeOf(c)),
+			zap.String("path", c.Request.URL.Path),
+			zap.String("query", c.Request.URL.RawQuery),
+			zap.Int("status", c.Writer.Status()),
+			zap.Duration("latency", latency),
+			zap.Duration("threshold", threshold),
+			zap.Int("query_count", stats.Count()),
+			zap.Any("downstream", recorder.Summaries()),
+			zap.Any("slowest_calls", recorder.Slowest(maxCalls)),
+			zap.Any("request_id", requestID),
+			zap.Any("trace_id", traceID),
+		}
+		if cfg.PprofLabels {
+			pprofLabels := make(map[string]string)
+			pprof.ForLabels(ctx, func(key, value string) bool {
+				pprofLabels[key] = value
+				return true
+			})
+			fields = append(fields, zap.Any("pprof_labels", pprofLabels))
+		}
+		logger.Warn("Slow request", fields...)
+	}
+}
+
+// routeOf 返回匹配的路由模板，未匹配到路由时返回请求路径
+func routeOf(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return c.Request.URL.Path
+}
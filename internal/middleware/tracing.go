@@ -0,0 +1,64 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/hedeqiang/skeleton/pkg/trace"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// tracingInstrumentation HTTP 服务端 span 的 instrumentation 名称
+const tracingInstrumentation = "github.com/hedeqiang/skeleton/internal/middleware"
+
+// NewTracing 创建链路追踪中间件，为每个请求创建 server span
+// 沿用上游 traceparent 中的链路，并用 span 覆盖 RequestID 中间件生成的 traceparent，
+// 使日志中的 trace_id、响应头和导出的 span 一致；需在 RequestID 之后、Logger 之前注册
+func NewTracing(p *trace.Provider) gin.HandlerFunc {
+	tracer := p.Tracer(tracingInstrumentation)
+	propagator := propagation.TraceContext{}
+
+	return func(c *gin.Context) {
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		// 按路由模板命名，避免路径参数导致 span 名称过多
+		route := c.FullPath()
+		name := c.Request.Method
+		if route != "" {
+			name += " " + route
+		}
+		ctx, span := tracer.Start(ctx, name,
+			oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+			oteltrace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		if tp, ok := trace.FromSpanContext(span.SpanContext()); ok {
+			ctx = trace.NewContext(ctx, tp)
+			c.Set("TraceID", tp.TraceID)
+			c.Header(trace.Header, tp.String())
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+		if err := c.Errors.Last(); err != nil {
+			span.RecordError(err.Err)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package migrations
+
+import (
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+// registry 各数据源的迁移列表，键为 databases 配置中的数据源名称
+// 新增迁移时在对应列表末尾追加，已发布的迁移不要修改
+var registry = map[string][]migrate.Migration{
+	"primary": {
+		{
+			Version: "20250101000000",
+			Name:    "create_users",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.User{})
+			},
+		},
+		{
+			Version: "20250601000000",
+			Name:    "create_tasks",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.Task{})
+			},
+		},
+		{
+			Version: "20251015000000",
+			Name:    "add_users_auditing_columns",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.User{})
+			},
+		},
+		{
+			Version: "20251020000000",
+			Name:    "create_webhook_deliveries",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.WebhookDelivery{})
+			},
+		},
+		{
+			Version: "20251025000000",
+			Name:    "create_message_archive",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.ArchivedMessage{})
+			},
+		},
+		{
+			Version: "20251101000000",
+			Name:    "create_daily_stats",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.DailyStats{})
+			},
+		},
+		{
+			Version: "20251105000000",
+			Name:    "add_users_deletion_scheduled_at",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.User{})
+			},
+		},
+		{
+			// 原唯一索引包含软删除的用户，删除后用户名和邮箱永远无法再注册
+			Version: "20251110000000",
+			Name:    "soft_delete_aware_users_unique_indexes",
+			Up: func(tx *gorm.DB) error {
+				for _, index := range []string{"idx_users_username", "idx_users_email"} {
+					if err := migrate.DropIndexIfExists(tx, &model.User{}, index); err != nil {
+						return err
+					}
+				}
+				// 重新创建用于查询的普通索引
+				if err := tx.AutoMigrate(&model.User{}); err != nil {
+					return err
+				}
+				if err := migrate.CreateActiveUniqueIndex(tx, "users", "uk_users_username_active", "username"); err != nil {
+					return err
+				}
+				return migrate.CreateActiveUniqueIndex(tx, "users", "uk_users_email_active", "email")
+			},
+		},
+		{
+			Version: "20251115000000",
+			Name:    "add_users_pending_email",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.User{})
+			},
+		},
+		{
+			Version: "20251120000000",
+			Name:    "add_users_roles_and_password_reset",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.User{})
+			},
+		},
+		{
+			Version: "20251121000000",
+			Name:    "create_user_audit_logs",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.UserAuditLog{})
+			},
+		},
+		{
+			Version: "20251125000000",
+			Name:    "create_user_identities",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.UserIdentity{})
+			},
+		},
+		{
+			// 用户发起的任务只有本人和管理员可以查看，结果只保存存储 key，查询时签发下载地址
+			Version: "20251201000000",
+			Name:    "add_tasks_user_id_and_result_key",
+			Up: func(tx *gorm.DB) error {
+				return tx.AutoMigrate(&model.Task{})
+			},
+		},
+	},
+}
+
+// For 返回数据源的迁移列表，未注册迁移的数据源返回 nil
+func For(dataSource string) []migrate.Migration {
+	return registry[dataSource]
+}
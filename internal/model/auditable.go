@@ -0,0 +1,8 @@
+package model
+
+// Auditable 记录创建人和最后修改人的用户 ID，嵌入到模型后由 database.AuditingPlugin 根据 context 中的操作人自动填充
+// 没有操作人的写入（例如计划任务、消费者、注册）保持为 0
+type Auditable struct {
+	CreatedBy uint `json:"created_by" gorm:"not null;default:0;comment:创建人用户 ID"`
+	UpdatedBy uint `json:"updated_by" gorm:"not null;default:0;comment:最后修改人用户 ID"`
+}
@@ -0,0 +1,18 @@
+package model
+
+import "encoding/json"
+
+// PublishEventRequest 通用事件发布请求
+type PublishEventRequest struct {
+	Type    string          `json:"type" validate:"required,max=100" example:"hello"`
+	Version string          `json:"version" validate:"omitempty,max=20" example:"1"`  // 信封版本，默认 1，按该版本的 schema 校验载荷
+	Payload json.RawMessage `json:"payload" validate:"required" swaggertype:"object"` // 信封中的载荷
+}
+
+// PublishEventResponse 通用事件发布结果
+type PublishEventResponse struct {
+	MessageID  string `json:"message_id"`
+	Type       string `json:"type"`
+	Exchange   string `json:"exchange"`
+	RoutingKey string `json:"routing_key"`
+}
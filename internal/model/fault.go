@@ -0,0 +1,17 @@
+package model
+
+// FaultRule 故障注入规则，命中的请求先注入延迟，再按概率断开连接或返回错误
+type FaultRule struct {
+	Path        string   `json:"path" validate:"required,max=255" example:"/api/v1/users/:id"` // Gin 路由模板，以 /* 结尾时按前缀匹配
+	Methods     []string `json:"methods,omitempty" example:"GET"`                              // 为空时匹配所有方法
+	Latency     string   `json:"latency,omitempty" example:"200ms"`                            // 固定延迟
+	Jitter      string   `json:"jitter,omitempty" example:"100ms"`                             // 追加 [0, jitter) 的随机延迟
+	ErrorRate   float64  `json:"error_rate" validate:"gte=0,lte=1" example:"0.1"`              // 返回错误的概率
+	ErrorStatus int      `json:"error_status,omitempty" validate:"omitempty,gte=400,lte=599" example:"503"`
+	DropRate    float64  `json:"drop_rate" validate:"gte=0,lte=1" example:"0"` // 断开连接的概率
+}
+
+// FaultRulesRequest 替换故障注入规则请求，rules 为空时清除所有故障
+type FaultRulesRequest struct {
+	Rules []FaultRule `json:"rules" validate:"dive"`
+}
@@ -0,0 +1,12 @@
+package model
+
+// LogLevels 当前实例的日志级别
+type LogLevels struct {
+	Level   string            `json:"level" example:"info"`                 // 全局级别，未单独设置级别的 logger 使用该级别
+	Loggers map[string]string `json:"loggers" example:"mq:debug,gorm:info"` // 单独设置了级别的命名 logger
+}
+
+// LogLevelRequest 设置命名 logger 日志级别请求
+type LogLevelRequest struct {
+	Level string `json:"level" validate:"required,oneof=debug info warn error" example:"debug"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// ArchivedMessage 归档的消费消息，记录信封原文、消息头和处理结果，用于重放和排查历史事件
+// Body 和 Headers 未指定列类型，MySQL 下为 longtext，PostgreSQL 下为 text
+type ArchivedMessage struct {
+	ID          uint64    `json:"id" gorm:"primarykey"`
+	MessageID   string    `json:"message_id" gorm:"index;size:64"`
+	MessageType string    `json:"message_type" gorm:"index;size:100"`
+	Queue       string    `json:"queue" gorm:"size:255"`
+	Exchange    string    `json:"exchange" gorm:"size:255"`
+	RoutingKey  string    `json:"routing_key" gorm:"size:255"`
+	Headers     string    `json:"headers"`
+	Body        string    `json:"body"`
+	Outcome     string    `json:"outcome" gorm:"size:20;comment:acked, dead_lettered, requeued, rejected"`
+	Error       string    `json:"error,omitempty" gorm:"type:text"`
+	ArchivedAt  time.Time `json:"archived_at" gorm:"index"`
+}
+
+// TableName 指定表名
+func (ArchivedMessage) TableName() string {
+	return "message_archive"
+}
+
+// MessageArchiveQuery 归档消息查询条件，零值字段不参与过滤
+type MessageArchiveQuery struct {
+	MessageType string
+	Queue       string
+	Outcome     string
+	Since       time.Time // 归档时间 >= Since
+	Until       time.Time // 归档时间 < Until
+}
+
+// Match 判断消息是否满足查询条件
+func (q *MessageArchiveQuery) Match(m *ArchivedMessage) bool {
+	return (q.MessageType == "" || m.MessageType == q.MessageType) &&
+		(q.Queue == "" || m.Queue == q.Queue) &&
+		(q.Outcome == "" || m.Outcome == q.Outcome) &&
+		(q.Since.IsZero() || !m.ArchivedAt.Before(q.Since)) &&
+		(q.Until.IsZero() || m.ArchivedAt.Before(q.Until))
+}
@@ -0,0 +1,11 @@
+package model
+
+import "github.com/hedeqiang/skeleton/pkg/migrate"
+
+// MigrationStatus 数据源迁移状态
+type MigrationStatus struct {
+	DataSource string              `json:"data_source"`
+	Version    string              `json:"version"`
+	Applied    []migrate.Record    `json:"applied"`
+	Pending    []migrate.Migration `json:"pending"`
+}
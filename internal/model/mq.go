@@ -0,0 +1,57 @@
+package model
+
+import "encoding/json"
+
+// 队列和交换机的实时状态
+const (
+	MQStatusOK          = "ok"          // 存在于 broker 中
+	MQStatusMissing     = "missing"     // 配置了但 broker 中不存在
+	MQStatusUnavailable = "unavailable" // 无法连接 broker
+	MQStatusUnsupported = "unsupported" // 非 RabbitMQ 驱动，不查询实时状态
+)
+
+// MQQueue 队列配置及实时状态
+type MQQueue struct {
+	Name               string   `json:"name"`
+	Driver             string   `json:"driver"`
+	Broker             string   `json:"broker,omitempty"` // 仅 rabbitmq 驱动
+	Exchange           string   `json:"exchange,omitempty"`
+	RoutingKeys        []string `json:"routing_keys,omitempty"`
+	Durable            bool     `json:"durable"`
+	DeadLetterExchange string   `json:"dead_letter_exchange,omitempty"`
+	NoConsume          bool     `json:"no_consume"`
+	Status             string   `json:"status" example:"ok"` // ok, missing, unavailable, unsupported
+	Messages           *int     `json:"messages,omitempty"`  // 队列中待投递的消息数，状态为 ok 时返回
+	Consumers          *int     `json:"consumers,omitempty"` // 消费者数，状态为 ok 时返回
+	Error              string   `json:"error,omitempty"`
+}
+
+// MQExchange 交换机配置及实时状态
+type MQExchange struct {
+	Name       string   `json:"name"`
+	Type       string   `json:"type"`
+	Driver     string   `json:"driver"`
+	Broker     string   `json:"broker,omitempty"` // 仅 rabbitmq 驱动
+	Durable    bool     `json:"durable"`
+	AutoDelete bool     `json:"auto_delete"`
+	Queues     []string `json:"queues"`              // 配置中绑定到该交换机的队列
+	Status     string   `json:"status" example:"ok"` // ok, missing, unavailable, unsupported
+	Error      string   `json:"error,omitempty"`
+}
+
+// MQTestPublishRequest 发布测试消息请求
+type MQTestPublishRequest struct {
+	Exchange    string          `json:"exchange" validate:"required,max=255" example:"hello.exchange"`
+	RoutingKey  string          `json:"routing_key" validate:"max=255" example:"hello"`
+	MessageType string          `json:"message_type" validate:"omitempty,max=100" example:"hello"` // 信封中的消息类型，默认 mq.test
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`                              // 信封中的载荷，默认 {}
+}
+
+// MQTestPublishResponse 发布测试消息结果
+type MQTestPublishResponse struct {
+	MessageID  string `json:"message_id"`
+	Exchange   string `json:"exchange"`
+	RoutingKey string `json:"routing_key"`
+	Driver     string `json:"driver"`
+	Broker     string `json:"broker,omitempty"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// StatsDateLayout 统计日期格式
+const StatsDateLayout = "2006-01-02"
+
+// DailyStats 每日统计，由 daily_stats_job 在次日计算写入，重复计算同一天时覆盖
+type DailyStats struct {
+	Date              string    `json:"date" gorm:"primarykey;size:10;comment:统计日期 YYYY-MM-DD"`
+	NewUsers          int64     `json:"new_users" gorm:"not null;default:0"`
+	ActiveUsers       int64     `json:"active_users" gorm:"not null;default:0;comment:当天登录过的去重用户数"`
+	Logins            int64     `json:"logins" gorm:"not null;default:0"`
+	MessagesProcessed int64     `json:"messages_processed" gorm:"not null;default:0;comment:消费者成功确认的消息数"`
+	ComputedAt        time.Time `json:"computed_at"`
+}
+
+// TableName 指定表名
+func (DailyStats) TableName() string {
+	return "daily_stats"
+}
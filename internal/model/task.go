@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// 后台任务状态
+const (
+	TaskStatusPending   = "pending"
+	TaskStatusRunning   = "running"
+	TaskStatusSucceeded = "succeeded"
+	TaskStatusFailed    = "failed"
+)
+
+// Task 后台任务模型
+// 记录导出、导入、匿名化等长耗时操作的执行进度，供客户端轮询；用户发起的任务只有本人和管理员可以查看
+type Task struct {
+	ID         string     `json:"id" gorm:"primarykey;size:32"`
+	Type       string     `json:"type" gorm:"index;not null;size:50"`
+	UserID     uint       `json:"user_id" gorm:"index;not null;default:0;comment:发起任务的用户，系统任务为 0"`
+	Status     string     `json:"status" gorm:"index;not null;size:20;default:pending"`
+	Progress   int        `json:"progress" gorm:"not null;default:0;comment:完成百分比 0-100"`
+	ResultURL  string     `json:"result_url" gorm:"size:500"`
+	ResultKey  string     `json:"-" gorm:"size:500;comment:结果在存储中的 key，查询时签发下载地址，不保存签名"`
+	Error      string     `json:"error" gorm:"type:text"`
+	StartedAt  *time.Time `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at"`
+	ExpiresAt  time.Time  `json:"expires_at" gorm:"index;comment:过期后由清理任务删除"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (Task) TableName() string {
+	return "tasks"
+}
+
+// IsFinished 任务是否已结束
+func (t *Task) IsFinished() bool {
+	return t.Status == TaskStatusSucceeded || t.Status == TaskStatusFailed
+}
+
+// TaskResponse 后台任务响应
+type TaskResponse struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`
+	UserID     uint       `json:"user_id,omitempty"`
+	Status     string     `json:"status"`
+	Progress   int        `json:"progress"`
+	ResultURL  string     `json:"result_url,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// CreateUploadRequest 创建分片上传会话请求
+type CreateUploadRequest struct {
+	Filename string `json:"filename" validate:"required,max=255"`
+	Size     int64  `json:"size" validate:"required,gt=0"`
+	Checksum string `json:"checksum" validate:"omitempty,len=64,hexadecimal"` // 整个文件的 SHA-256，完成时校验
+}
+
+// UploadSession 分片上传会话，只有创建会话的用户可以继续上传
+type UploadSession struct {
+	ID             string    `json:"id"`
+	UserID         uint      `json:"user_id"`
+	Filename       string    `json:"filename"`
+	Size           int64     `json:"size"`
+	Checksum       string    `json:"checksum,omitempty"`
+	ChunkSize      int64     `json:"chunk_size"`
+	TotalChunks    int       `json:"total_chunks"`
+	UploadedChunks []int     `json:"uploaded_chunks"`
+	ExpiresAt      time.Time `json:"expires_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ChunkLength 返回第 index 个分片的期望长度
+func (s *UploadSession) ChunkLength(index int) int64 {
+	if index == s.TotalChunks-1 {
+		return s.Size - s.ChunkSize*int64(s.TotalChunks-1)
+	}
+	return s.ChunkSize
+}
+
+// UploadResult 分片上传完成结果
+type UploadResult struct {
+	Key       string    `json:"key"`
+	Filename  string    `json:"filename"`
+	Size      int64     `json:"size"`
+	Checksum  string    `json:"checksum"`
+	ExpiresAt time.Time `json:"expires_at"` // 合并后的文件保留到该时间，之后由清理任务删除
+}
@@ -7,15 +7,41 @@ import (
 )
 
 // User 用户模型
+// 用户名和邮箱的唯一索引只约束未软删除的用户（见 soft_delete_aware_users_unique_indexes 迁移），
+// 删除后可以重新注册；这里的普通索引用于按用户名、邮箱查询
 type User struct {
 	ID        uint           `json:"id" gorm:"primarykey"`
-	Username  string         `json:"username" gorm:"uniqueIndex;not null;size:50" validate:"required,min=3,max=50"`
-	Email     string         `json:"email" gorm:"uniqueIndex;not null;size:100" validate:"required,email"`
+	Username  string         `json:"username" gorm:"index;not null;size:50" validate:"required,min=3,max=50"`
+	Email     string         `json:"email" gorm:"index;not null;size:100" validate:"required,email"`
 	Password  string         `json:"-" gorm:"not null;size:255" validate:"required,min=6"`
 	Status    int            `json:"status" gorm:"default:1;comment:用户状态 1-正常 0-禁用"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+
+	// DeletionScheduledAt 申请删除账户后的彻底删除时间，宽限期内可以撤销，到期后由 user_erasure_job 删除
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty" gorm:"index"`
+
+	// PendingEmail 待确认的新邮箱，确认前 Email 保持不变；确认令牌只保存 SHA-256 哈希
+	PendingEmail         string     `json:"pending_email,omitempty" gorm:"size:100"`
+	EmailChangeTokenHash string     `json:"-" gorm:"size:64"`
+	EmailChangeExpiresAt *time.Time `json:"email_change_expires_at,omitempty"`
+
+	// Roles 管理员分配的角色，签发令牌时写入 roles 声明，供 route_policies 校验
+	Roles []string `json:"roles,omitempty" gorm:"serializer:json;size:255"`
+	// DisabledReason 管理员禁用账户的原因，启用后清空
+	DisabledReason string `json:"disabled_reason,omitempty" gorm:"size:255"`
+
+	// PasswordResetRequired 管理员强制重置密码后为 true，凭重置令牌设置新密码前不能登录；令牌只保存 SHA-256 哈希
+	PasswordResetRequired  bool       `json:"password_reset_required" gorm:"not null;default:false"`
+	PasswordResetTokenHash string     `json:"-" gorm:"size:64"`
+	PasswordResetExpiresAt *time.Time `json:"password_reset_expires_at,omitempty"`
+
+	// LastLoginAt 最近一次登录时间，TokensRevokedAt 最近一次吊销该用户全部令牌的时间
+	LastLoginAt     *time.Time `json:"last_login_at,omitempty"`
+	TokensRevokedAt *time.Time `json:"tokens_revoked_at,omitempty"`
+
+	Auditable
 }
 
 // TableName 指定表名
@@ -28,15 +54,36 @@ type CreateUserRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
+	// ReservationToken 预占用户名和邮箱时返回的 token，可选
+	ReservationToken string `json:"reservation_token" validate:"omitempty,max=64"`
+}
+
+// ReserveUserRequest 预占用户名和邮箱请求，用于多步骤注册
+type ReserveUserRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=50"`
+	Email    string `json:"email" validate:"required,email"`
+}
+
+// UserReservation 预占结果，创建用户时通过 reservation_token 使用
+type UserReservation struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 // UpdateUserRequest 更新用户请求
 type UpdateUserRequest struct {
 	Username string `json:"username" validate:"omitempty,min=3,max=50"`
-	Email    string `json:"email" validate:"omitempty,email"`
+	Email    string `json:"email" validate:"omitempty,email"` // 新邮箱确认后才生效
 	Status   *int   `json:"status" validate:"omitempty,oneof=0 1"`
 }
 
+// PatchUserRequest 部分更新用户请求，只更新请求体中出现的字段
+type PatchUserRequest struct {
+	Username *string `json:"username" validate:"omitnil,min=3,max=50"`
+	Email    *string `json:"email" validate:"omitnil,email"` // 新邮箱确认后才生效
+	Status   *int    `json:"status" validate:"omitnil,oneof=0 1"`
+}
+
 // UserResponse 用户响应
 type UserResponse struct {
 	ID        uint      `json:"id"`
@@ -45,4 +92,80 @@ type UserResponse struct {
 	Status    int       `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+
+	// PendingEmail 已申请修改、等待确认的新邮箱
+	PendingEmail         string     `json:"pending_email,omitempty"`
+	EmailChangeExpiresAt *time.Time `json:"email_change_expires_at,omitempty"`
+
+	Roles                 []string `json:"roles,omitempty"`
+	DisabledReason        string   `json:"disabled_reason,omitempty"`
+	PasswordResetRequired bool     `json:"password_reset_required,omitempty"`
+}
+
+// AuthTokens 登录或刷新后签发的令牌
+// 访问令牌放在 Authorization: Bearer 头中调用接口，过期后凭刷新令牌换取新令牌；刷新令牌只能使用一次
+type AuthTokens struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresIn        int64     `json:"expires_in"` // 访问令牌剩余有效秒数
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// LoginResponse 登录和刷新令牌的响应
+type LoginResponse struct {
+	User *UserResponse `json:"user"`
+	AuthTokens
+}
+
+// 用户领域事件类型，同时作为发布时的路由键
+const (
+	EventUserCreated = "user.created"
+	EventUserUpdated = "user.updated"
+	EventUserDeleted = "user.deleted"
+	// EventUserErased 账户数据已彻底删除，下游系统收到后应删除各自保存的该用户数据
+	EventUserErased = "user.erased"
+)
+
+// ConfirmEmailChangeRequest 确认修改邮箱请求
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" validate:"required,max=64"`
+}
+
+// ConfirmPasswordResetRequest 凭重置令牌设置新密码请求
+type ConfirmPasswordResetRequest struct {
+	Token    string `json:"token" validate:"required,max=64"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+// UserDeletion 账户删除申请
+type UserDeletion struct {
+	UserID      uint      `json:"user_id"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+// UserEvent 用户领域事件载荷，只携带用户 ID，消费方按需读取最新数据，避免乱序投递覆盖新数据
+type UserEvent struct {
+	UserID uint `json:"user_id" validate:"required"`
+}
+
+// UserDocument 搜索索引中的用户文档
+type UserDocument struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SearchUsersRequest 用户搜索请求
+type SearchUsersRequest struct {
+	Query    string // 匹配用户名和邮箱的关键字，为空时返回全部用户
+	Status   *int   // 按用户状态过滤，nil 表示不过滤
+	Page     int
+	PageSize int
 }
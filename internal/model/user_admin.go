@@ -0,0 +1,62 @@
+package model
+
+import "time"
+
+// 管理员或用户本人对账户执行的操作，记录在用户审计日志中
+const (
+	UserAuditPasswordResetForced    = "password_reset_forced"
+	UserAuditPasswordResetCompleted = "password_reset_completed"
+	UserAuditDisabled               = "disabled"
+	UserAuditEnabled                = "enabled"
+	UserAuditRolesAssigned          = "roles_assigned"
+	UserAuditIdentityLinked         = "identity_linked"
+	UserAuditIdentityUnlinked       = "identity_unlinked"
+)
+
+// UserAuditLog 用户账户的管理操作记录，只追加不修改
+type UserAuditLog struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	UserID    uint      `json:"user_id" gorm:"index:idx_user_audit_logs_user,priority:1;not null"`
+	ActorID   uint      `json:"actor_id" gorm:"index;not null;default:0;comment:操作人用户 ID，用户本人完成重置时为用户 ID"`
+	Action    string    `json:"action" gorm:"not null;size:50"`
+	Reason    string    `json:"reason,omitempty" gorm:"size:255"`
+	Detail    string    `json:"detail,omitempty" gorm:"size:500;comment:操作内容，例如分配后的角色"`
+	CreatedAt time.Time `json:"created_at" gorm:"index:idx_user_audit_logs_user,priority:2"`
+}
+
+// TableName 指定表名
+func (UserAuditLog) TableName() string {
+	return "user_audit_logs"
+}
+
+// AdminPasswordResetRequest 管理员强制重置密码请求
+type AdminPasswordResetRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=255"`
+}
+
+// DisableUserRequest 管理员禁用账户请求
+type DisableUserRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// EnableUserRequest 管理员启用账户请求
+type EnableUserRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=255"`
+}
+
+// AssignRolesRequest 管理员分配角色请求，覆盖用户现有的角色，空列表 [] 表示移除全部角色
+type AssignRolesRequest struct {
+	Roles  []string `json:"roles" validate:"required,max=10,dive,required,max=32,printascii"`
+	Reason string   `json:"reason" validate:"omitempty,max=255"`
+}
+
+// UserSessions 用户的登录会话状态
+// 令牌是无状态的 JWT，不单独保存会话；吊销后签发时间不晚于 TokensRevokedAt 的令牌全部失效
+type UserSessions struct {
+	UserID                uint       `json:"user_id"`
+	Status                int        `json:"status"`
+	LastLoginAt           *time.Time `json:"last_login_at,omitempty"`
+	TokensRevokedAt       *time.Time `json:"tokens_revoked_at,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
+	PasswordResetExpires  *time.Time `json:"password_reset_expires_at,omitempty"`
+}
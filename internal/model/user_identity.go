@@ -0,0 +1,44 @@
+package model
+
+import "time"
+
+// UserIdentity 用户关联的外部账号，由 OAuth 登录流程写入
+// 同一外部账号只能关联一个用户，同一用户在每个提供方最多关联一个外部账号；
+// 外部令牌以 identities.token_encryption_key 加密保存，未配置密钥时不保存
+type UserIdentity struct {
+	ID             uint       `json:"id" gorm:"primarykey"`
+	UserID         uint       `json:"user_id" gorm:"not null;uniqueIndex:uk_user_identities_user_provider,priority:1"`
+	Provider       string     `json:"provider" gorm:"not null;size:32;uniqueIndex:uk_user_identities_provider_subject,priority:1;uniqueIndex:uk_user_identities_user_provider,priority:2"`
+	ProviderUserID string     `json:"provider_user_id" gorm:"not null;size:191;uniqueIndex:uk_user_identities_provider_subject,priority:2;comment:提供方的用户标识，例如 OIDC sub"`
+	Email          string     `json:"email,omitempty" gorm:"size:100;comment:提供方返回的邮箱，只用于展示和冲突检查"`
+	AccessToken    string     `json:"-" gorm:"type:text;comment:加密后的访问令牌"`
+	RefreshToken   string     `json:"-" gorm:"type:text;comment:加密后的刷新令牌"`
+	TokenExpiresAt *time.Time `json:"token_expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// ExternalIdentity OAuth 登录流程从提供方获取的外部账号信息，令牌为明文
+type ExternalIdentity struct {
+	Provider       string
+	ProviderUserID string
+	Email          string
+	AccessToken    string
+	RefreshToken   string
+	TokenExpiresAt *time.Time
+}
+
+// UserIdentityResponse 外部账号关联信息，不包含令牌
+type UserIdentityResponse struct {
+	ID             uint      `json:"id"`
+	Provider       string    `json:"provider"`
+	ProviderUserID string    `json:"provider_user_id"`
+	Email          string    `json:"email,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// Webhook 投递状态
+const (
+	WebhookStatusPending   = "pending"
+	WebhookStatusFailed    = "failed"
+	WebhookStatusDelivered = "delivered"
+	WebhookStatusDead      = "dead"
+)
+
+// WebhookDelivery 出站 Webhook 投递记录
+// 先写入记录再由 webhook_relay_job 投递，收到 2xx 响应前按指数退避重试，保证至少一次投递
+type WebhookDelivery struct {
+	ID             uint       `json:"id" gorm:"primarykey"`
+	EventType      string     `json:"event_type" gorm:"index;not null;size:100"`
+	URL            string     `json:"url" gorm:"not null;size:500"`
+	Payload        string     `json:"payload" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"index:idx_webhook_deliveries_due,priority:1;not null;size:20;default:pending"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"index:idx_webhook_deliveries_due,priority:2;comment:下次投递时间"`
+	LastError      string     `json:"last_error" gorm:"type:text"`
+	ResponseStatus int        `json:"response_status" gorm:"comment:最近一次投递的 HTTP 状态码"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// TableName 指定表名
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,54 @@
+package policy
+
+import (
+	"context"
+	"strconv"
+)
+
+// OwnerFunc 返回资源所有者的用户 ID，资源不存在时返回 false
+type OwnerFunc func(ctx context.Context, id string) (uint, bool, error)
+
+// OwnerPolicy 所有者或管理员可以查看和修改资源
+// 资源不存在时管理员放行，由处理器返回 404；其他用户一律拒绝，不泄露资源是否存在
+type OwnerPolicy struct {
+	owner      OwnerFunc
+	adminRoles []string
+}
+
+// NewOwnerPolicy 创建所有者策略，拥有 adminRoles 中任一角色的用户视为管理员
+func NewOwnerPolicy(owner OwnerFunc, adminRoles ...string) *OwnerPolicy {
+	return &OwnerPolicy{
+		owner:      owner,
+		adminRoles: adminRoles,
+	}
+}
+
+// CanView 所有者或管理员可以查看
+func (p *OwnerPolicy) CanView(ctx context.Context, subject Subject, id string) (bool, error) {
+	return p.ownerOrAdmin(ctx, subject, id)
+}
+
+// CanEdit 所有者或管理员可以修改
+func (p *OwnerPolicy) CanEdit(ctx context.Context, subject Subject, id string) (bool, error) {
+	return p.ownerOrAdmin(ctx, subject, id)
+}
+
+func (p *OwnerPolicy) ownerOrAdmin(ctx context.Context, subject Subject, id string) (bool, error) {
+	if subject.HasAnyRole(p.adminRoles...) {
+		return true, nil
+	}
+	owner, ok, err := p.owner(ctx, id)
+	if err != nil || !ok {
+		return false, err
+	}
+	return owner == subject.UserID, nil
+}
+
+// SelfOwner 资源 ID 即用户 ID 时的 OwnerFunc，用于 /users/:id 等路由，不需要查询数据库
+func SelfOwner(ctx context.Context, id string) (uint, bool, error) {
+	userID, err := strconv.ParseUint(id, 10, 32)
+	if err != nil {
+		return 0, false, nil
+	}
+	return uint(userID), true, nil
+}
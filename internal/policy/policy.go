@@ -0,0 +1,112 @@
+// Package policy 资源级授权策略
+// 每种资源注册一个 Policy，判断当前用户能否查看或修改某个资源；处理器直接调用 Registry.Authorize，
+// 或由 authorization 中间件按路由配置在进入处理器之前调用，服务层不再各自判断所有者或管理员
+package policy
+
+import (
+	"context"
+	"slices"
+
+	"github.com/hedeqiang/skeleton/pkg/errors"
+)
+
+// Action 授权动作
+type Action string
+
+// 授权动作
+const (
+	ActionView Action = "view"
+	ActionEdit Action = "edit"
+)
+
+// Subject 发起请求的已认证用户
+type Subject struct {
+	UserID uint
+	Roles  []string
+}
+
+// HasAnyRole 判断是否拥有任一角色
+func (s Subject) HasAnyRole(roles ...string) bool {
+	return HasAnyRole(s.Roles, roles...)
+}
+
+// HasAnyRole 判断 owned 中是否包含 roles 中的任一角色，roles 为空时返回 false
+// 路由策略、事件发布等按角色放行的检查都使用该函数，未要求角色时是否放行由调用方决定
+func HasAnyRole(owned []string, roles ...string) bool {
+	return slices.ContainsFunc(owned, func(role string) bool {
+		return slices.Contains(roles, role)
+	})
+}
+
+type subjectKey struct{}
+
+// WithSubject 将已认证用户写入 context，由认证中间件调用
+func WithSubject(ctx context.Context, subject Subject) context.Context {
+	return context.WithValue(ctx, subjectKey{}, subject)
+}
+
+// SubjectFromContext 获取已认证用户，未认证时返回 false
+func SubjectFromContext(ctx context.Context) (Subject, bool) {
+	if ctx == nil {
+		return Subject{}, false
+	}
+	subject, ok := ctx.Value(subjectKey{}).(Subject)
+	return subject, ok
+}
+
+// Policy 资源授权策略，id 为路由参数中的资源 ID
+// 返回错误表示无法判断（例如查询所有者失败），调用方按内部错误处理
+type Policy interface {
+	CanView(ctx context.Context, subject Subject, id string) (bool, error)
+	CanEdit(ctx context.Context, subject Subject, id string) (bool, error)
+}
+
+// Registry 按资源名称注册的授权策略
+type Registry struct {
+	policies map[string]Policy
+}
+
+// NewRegistry 创建授权策略注册表
+func NewRegistry() *Registry {
+	return &Registry{policies: make(map[string]Policy)}
+}
+
+// Register 注册资源的授权策略，同名资源后注册的覆盖先注册的
+func (r *Registry) Register(resource string, policy Policy) {
+	r.policies[resource] = policy
+}
+
+// Get 获取资源的授权策略
+func (r *Registry) Get(resource string) (Policy, bool) {
+	policy, ok := r.policies[resource]
+	return policy, ok
+}
+
+// Authorize 判断 context 中的用户能否对资源执行 action
+// 未认证时返回 ErrTokenMissing，没有权限时返回 ErrPermissionDenied；未注册的资源一律拒绝
+func (r *Registry) Authorize(ctx context.Context, resource string, action Action, id string) error {
+	subject, ok := SubjectFromContext(ctx)
+	if !ok {
+		return errors.ErrTokenMissing
+	}
+	policy, ok := r.Get(resource)
+	if !ok {
+		return errors.ErrPermissionDenied
+	}
+
+	var allowed bool
+	var err error
+	switch action {
+	case ActionView:
+		allowed, err = policy.CanView(ctx, subject, id)
+	case ActionEdit:
+		allowed, err = policy.CanEdit(ctx, subject, id)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeInternal, "failed to authorize request")
+	}
+	if !allowed {
+		return errors.ErrPermissionDenied
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package policy
+
+import (
+	"context"
+	stdErrors "errors"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/pkg/errors"
+)
+
+func TestAuthorizeUser(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(ResourceUser, NewUserPolicy("admin"))
+
+	alice := WithSubject(context.Background(), Subject{UserID: 7})
+	admin := WithSubject(context.Background(), Subject{UserID: 1, Roles: []string{"editor", "admin"}})
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		action Action
+		id     string
+		want   error
+	}{
+		{"owner views", alice, ActionView, "7", nil},
+		{"owner edits", alice, ActionEdit, "7", nil},
+		{"other user", alice, ActionEdit, "8", errors.ErrPermissionDenied},
+		{"malformed id", alice, ActionView, "abc", errors.ErrPermissionDenied},
+		{"admin edits other user", admin, ActionEdit, "8", nil},
+		{"unknown action", alice, Action("delete"), "7", errors.ErrPermissionDenied},
+		{"unauthenticated", context.Background(), ActionView, "7", errors.ErrTokenMissing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := registry.Authorize(tt.ctx, ResourceUser, tt.action, tt.id); err != tt.want {
+				t.Fatalf("Authorize = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := registry.Authorize(admin, "invoice", ActionView, "1"); err != errors.ErrPermissionDenied {
+		t.Fatalf("Authorize(unregistered resource) = %v, want ErrPermissionDenied", err)
+	}
+}
+
+func TestOwnerPolicyLookup(t *testing.T) {
+	owners := map[string]uint{"doc-1": 7}
+	lookupErr := stdErrors.New("database unavailable")
+	p := NewOwnerPolicy(func(ctx context.Context, id string) (uint, bool, error) {
+		if id == "broken" {
+			return 0, false, lookupErr
+		}
+		owner, ok := owners[id]
+		return owner, ok, nil
+	}, "admin")
+
+	ctx := context.Background()
+	if ok, err := p.CanView(ctx, Subject{UserID: 7}, "doc-1"); !ok || err != nil {
+		t.Fatalf("owner CanView = %v, %v", ok, err)
+	}
+	if ok, err := p.CanEdit(ctx, Subject{UserID: 8}, "doc-1"); ok || err != nil {
+		t.Fatalf("non-owner CanEdit = %v, %v", ok, err)
+	}
+	// 资源不存在时管理员放行，由处理器返回 404
+	if ok, _ := p.CanView(ctx, Subject{UserID: 7}, "missing"); ok {
+		t.Fatal("missing resource must be denied to non-admins")
+	}
+	if ok, _ := p.CanView(ctx, Subject{UserID: 1, Roles: []string{"admin"}}, "missing"); !ok {
+		t.Fatal("missing resource must be allowed to admins")
+	}
+	if _, err := p.CanView(ctx, Subject{UserID: 7}, "broken"); err != lookupErr {
+		t.Fatalf("lookup error = %v, want %v", err, lookupErr)
+	}
+}
+
+func TestHasAnyRole(t *testing.T) {
+	tests := []struct {
+		owned, roles []string
+		want         bool
+	}{
+		{owned: []string{"user", "admin"}, roles: []string{"admin"}, want: true},
+		{owned: []string{"user"}, roles: []string{"admin", "ops"}, want: false},
+		{owned: nil, roles: []string{"admin"}, want: false},
+		// 未要求角色时不视为满足，是否放行由调用方决定
+		{owned: []string{"admin"}, roles: nil, want: false},
+	}
+	for _, tt := range tests {
+		if got := HasAnyRole(tt.owned, tt.roles...); got != tt.want {
+			t.Errorf("HasAnyRole(%v, %v) = %v, want %v", tt.owned, tt.roles, got, tt.want)
+		}
+	}
+	if !(Subject{Roles: []string{"admin"}}).HasAnyRole("admin") {
+		t.Error("Subject.HasAnyRole must delegate to HasAnyRole")
+	}
+}
@@ -0,0 +1,9 @@
+package policy
+
+// ResourceTask 后台任务资源，路由参数中的 ID 为任务 ID
+const ResourceTask = "task"
+
+// NewTaskPolicy 创建后台任务的授权策略，用户只能查看自己发起的任务，系统任务只有管理员可以查看
+func NewTaskPolicy(owner OwnerFunc, adminRoles ...string) Policy {
+	return NewOwnerPolicy(owner, adminRoles...)
+}
@@ -0,0 +1,9 @@
+package policy
+
+// ResourceUser 用户资源，路由参数中的 ID 即用户 ID
+const ResourceUser = "user"
+
+// NewUserPolicy 创建用户资源的授权策略，用户只能查看和修改自己的账户，管理员不受限制
+func NewUserPolicy(adminRoles ...string) Policy {
+	return NewOwnerPolicy(SelfOwner, adminRoles...)
+}
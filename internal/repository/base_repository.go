@@ -3,12 +3,14 @@ package repository
 import (
 	"context"
 	"gorm.io/gorm"
+	"github.com/hedeqiang/skeleton/pkg/database"
 	"github.com/hedeqiang/skeleton/pkg/errors"
 )
 
 // BaseRepository 基础仓储
 type BaseRepository struct {
-	db *gorm.DB
+	db   *gorm.DB
+	opts []QueryOption
 }
 
 // NewBaseRepository 创建基础仓储
@@ -21,9 +23,34 @@ func (r *BaseRepository) DB() *gorm.DB {
 	return r.db
 }
 
-// WithContext 创建带上下文的数据库会话
+// With 返回附加了查询选项的仓储副本，原仓储不受影响
+// 例如 r.With(WithPreload("Profile"), SelectFields("id", "username")).FindByID(ctx, &user, id)
+func (r *BaseRepository) With(opts ...QueryOption) *BaseRepository {
+	merged := make([]QueryOption, 0, len(r.opts)+len(opts))
+	merged = append(merged, r.opts...)
+	merged = append(merged, opts...)
+	return &BaseRepository{db: r.db, opts: merged}
+}
+
+// WithContext 创建带上下文的数据库会话，并应用查询选项
+// ctx 中有 TxManager 开启的事务时使用事务连接
 func (r *BaseRepository) WithContext(ctx context.Context) *gorm.DB {
-	return r.db.WithContext(ctx)
+	db := database.Conn(ctx, r.db)
+	for _, opt := range r.opts {
+		db = opt.scope(db)
+	}
+	return db
+}
+
+// countContext 创建用于统计的会话，只应用影响总数的查询选项
+func (r *BaseRepository) countContext(ctx context.Context) *gorm.DB {
+	db := database.Conn(ctx, r.db)
+	for _, opt := range r.opts {
+		if opt.countable {
+			db = opt.scope(db)
+		}
+	}
+	return db
 }
 
 // Create 创建记录
@@ -77,7 +104,7 @@ func (r *BaseRepository) FindMany(ctx context.Context, models interface{}, query
 // Count 统计记录数
 func (r *BaseRepository) Count(ctx context.Context, model interface{}, query interface{}, args ...interface{}) (int64, error) {
 	var count int64
-	if err := r.WithContext(ctx).Model(model).Where(query, args...).Count(&count).Error; err != nil {
+	if err := r.countContext(ctx).Model(model).Where(query, args...).Count(&count).Error; err != nil {
 		return 0, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to count records")
 	}
 	return count, nil
@@ -86,7 +113,7 @@ func (r *BaseRepository) Count(ctx context.Context, model interface{}, query int
 // Exists 检查记录是否存在
 func (r *BaseRepository) Exists(ctx context.Context, model interface{}, query interface{}, args ...interface{}) (bool, error) {
 	var count int64
-	if err := r.WithContext(ctx).Model(model).Where(query, args...).Count(&count).Error; err != nil {
+	if err := r.countContext(ctx).Model(model).Where(query, args...).Count(&count).Error; err != nil {
 		return false, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to check record existence")
 	}
 	return count > 0, nil
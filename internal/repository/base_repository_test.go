@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB 只生成 SQL 不执行，无需真实数据库
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("Failed to open dry run database: %v", err)
+	}
+	return db
+}
+
+func TestBaseRepositoryQueryOptions(t *testing.T) {
+	base := NewBaseRepository(dryRunDB(t))
+	repo := base.With(
+		SelectFields("id", "username"),
+		WithJoins("JOIN user_roles ON user_roles.user_id = users.id"),
+		WithOrder("id"),
+		WithPagination(20, 10),
+	)
+
+	var users []*model.User
+	stmt := repo.WithContext(context.Background()).Where("status = ?", 1).Find(&users).Statement
+	sql := stmt.SQL.String()
+	for _, want := range []string{`SELECT "id","username"`, "JOIN user_roles", "ORDER BY id", "LIMIT", "OFFSET"} {
+		if !strings.Contains(sql, want) {
+			t.Errorf("expected %q in %s", want, sql)
+		}
+	}
+	if vars := stmt.Vars; len(vars) != 3 || vars[1] != 10 || vars[2] != 20 {
+		t.Errorf("unexpected vars: %v", vars)
+	}
+
+	// 统计总数时只保留连接
+	var count int64
+	stmt = repo.countContext(context.Background()).Model(&model.User{}).Count(&count).Statement
+	sql = stmt.SQL.String()
+	if !strings.Contains(sql, "JOIN user_roles") || strings.Contains(sql, "LIMIT") || strings.Contains(sql, "username") {
+		t.Errorf("unexpected count SQL: %s", sql)
+	}
+
+	// 原仓储不受影响
+	stmt = base.WithContext(context.Background()).Find(&users).Statement
+	if sql := stmt.SQL.String(); strings.Contains(sql, "LIMIT") {
+		t.Errorf("base repository should not inherit options: %s", sql)
+	}
+}
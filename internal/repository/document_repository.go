@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	stderrors "errors"
+
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	mongopkg "github.com/hedeqiang/skeleton/pkg/mongo"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DocumentRepository MongoDB 文档仓储基类，T 为文档结构体，字段使用 bson 标签映射
+// 用于日志、灵活元数据等不适合关系表的数据，具体仓储嵌入 *DocumentRepository[T] 后补充业务查询
+type DocumentRepository[T any] struct {
+	collection *mongo.Collection
+}
+
+// NewDocumentRepository 创建文档仓储，collection 为默认数据库中的集合名称
+func NewDocumentRepository[T any](client *mongopkg.Client, collection string) *DocumentRepository[T] {
+	return &DocumentRepository[T]{collection: client.Collection(collection)}
+}
+
+// Collection 返回集合，用于聚合、索引等基类未覆盖的操作
+func (r *DocumentRepository[T]) Collection() *mongo.Collection {
+	return r.collection
+}
+
+// Insert 插入文档，返回文档 ID
+func (r *DocumentRepository[T]) Insert(ctx context.Context, doc *T) (interface{}, error) {
+	result, err := r.collection.InsertOne(ctx, doc)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to insert document")
+	}
+	return result.InsertedID, nil
+}
+
+// InsertMany 批量插入文档
+func (r *DocumentRepository[T]) InsertMany(ctx context.Context, docs []*T) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	if _, err := r.collection.InsertMany(ctx, docs); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to insert documents")
+	}
+	return nil
+}
+
+// FindByID 根据 _id 查找文档，不存在时返回 not_found 错误
+func (r *DocumentRepository[T]) FindByID(ctx context.Context, id interface{}) (*T, error) {
+	return r.FindOne(ctx, bson.D{{Key: "_id", Value: id}})
+}
+
+// FindOne 查找单个文档，不存在时返回 not_found 错误
+func (r *DocumentRepository[T]) FindOne(ctx context.Context, filter interface{}) (*T, error) {
+	var doc T
+	if err := r.collection.FindOne(ctx, documentFilter(filter)).Decode(&doc); err != nil {
+		if stderrors.Is(err, mongo.ErrNoDocuments) {
+			return nil, errors.Wrap(err, errors.ErrorTypeNotFound, "document not found")
+		}
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to find document")
+	}
+	return &doc, nil
+}
+
+// Find 查找多个文档，opts 可设置排序、投影等
+func (r *DocumentRepository[T]) Find(ctx context.Context, filter interface{}, opts ...options.Lister[options.FindOptions]) ([]*T, error) {
+	cursor, err := r.collection.Find(ctx, documentFilter(filter), opts...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to find documents")
+	}
+	docs := make([]*T, 0)
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to decode documents")
+	}
+	return docs, nil
+}
+
+// FindPage 分页查找文档并返回总数，sort 为空时按 _id 排序
+func (r *DocumentRepository[T]) FindPage(ctx context.Context, filter interface{}, sort interface{}, offset, limit int) ([]*T, int64, error) {
+	total, err := r.Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if sort == nil {
+		sort = bson.D{{Key: "_id", Value: 1}}
+	}
+	opts := options.Find().SetSort(sort)
+	if offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	docs, err := r.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return docs, total, nil
+}
+
+// Count 统计满足条件的文档数
+func (r *DocumentRepository[T]) Count(ctx context.Context, filter interface{}) (int64, error) {
+	count, err := r.collection.CountDocuments(ctx, documentFilter(filter))
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to count documents")
+	}
+	return count, nil
+}
+
+// UpdateByID 根据 _id 更新文档，update 需使用 $set 等更新操作符，文档不存在时返回 not_found 错误
+func (r *DocumentRepository[T]) UpdateByID(ctx context.Context, id interface{}, update interface{}) error {
+	result, err := r.collection.UpdateByID(ctx, id, update)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to update document")
+	}
+	if result.MatchedCount == 0 {
+		return errors.NotFoundError("document not found")
+	}
+	return nil
+}
+
+// DeleteByID 根据 _id 删除文档，文档不存在时返回 not_found 错误
+func (r *DocumentRepository[T]) DeleteByID(ctx context.Context, id interface{}) error {
+	result, err := r.collection.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to delete document")
+	}
+	if result.DeletedCount == 0 {
+		return errors.NotFoundError("document not found")
+	}
+	return nil
+}
+
+// DeleteMany 删除满足条件的文档，返回删除数量
+func (r *DocumentRepository[T]) DeleteMany(ctx context.Context, filter interface{}) (int64, error) {
+	result, err := r.collection.DeleteMany(ctx, documentFilter(filter))
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to delete documents")
+	}
+	return result.DeletedCount, nil
+}
+
+// documentFilter nil 过滤条件表示匹配全部文档
+func documentFilter(filter interface{}) interface{} {
+	if filter == nil {
+		return bson.D{}
+	}
+	return filter
+}
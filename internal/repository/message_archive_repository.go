@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// MessageArchiveRepository 归档消息仓储接口
+type MessageArchiveRepository interface {
+	CreateBatch(ctx context.Context, messages []*model.ArchivedMessage) error
+	// FindAfter 按 ID 顺序查询 afterID 之后满足条件的消息，用于分批重放
+	FindAfter(ctx context.Context, query *model.MessageArchiveQuery, afterID uint64, limit int) ([]*model.ArchivedMessage, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// messageArchiveRepository 归档消息仓储实现
+type messageArchiveRepository struct {
+	*BaseRepository
+}
+
+// NewMessageArchiveRepository 创建归档消息仓储实例
+func NewMessageArchiveRepository(db *gorm.DB) MessageArchiveRepository {
+	return &messageArchiveRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// CreateBatch 批量写入归档消息
+func (r *messageArchiveRepository) CreateBatch(ctx context.Context, messages []*model.ArchivedMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	if err := r.WithContext(ctx).CreateInBatches(messages, len(messages)).Error; err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to archive messages")
+	}
+	return nil
+}
+
+// FindAfter 按 ID 顺序查询 afterID 之后满足条件的消息
+func (r *messageArchiveRepository) FindAfter(ctx context.Context, query *model.MessageArchiveQuery, afterID uint64, limit int) ([]*model.ArchivedMessage, error) {
+	db := r.WithContext(ctx).Where("id > ?", afterID)
+	if query.MessageType != "" {
+		db = db.Where("message_type = ?", query.MessageType)
+	}
+	if query.Queue != "" {
+		db = db.Where("queue = ?", query.Queue)
+	}
+	if query.Outcome != "" {
+		db = db.Where("outcome = ?", query.Outcome)
+	}
+	if !query.Since.IsZero() {
+		db = db.Where("archived_at >= ?", query.Since)
+	}
+	if !query.Until.IsZero() {
+		db = db.Where("archived_at < ?", query.Until)
+	}
+
+	var messages []*model.ArchivedMessage
+	if err := db.Order("id").Limit(limit).Find(&messages).Error; err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to find archived messages")
+	}
+	return messages, nil
+}
+
+// DeleteBefore 删除归档时间早于 before 的消息
+func (r *messageArchiveRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.WithContext(ctx).Where("archived_at < ?", before).Delete(&model.ArchivedMessage{})
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, errors.ErrorTypeDatabase, "failed to delete archived messages")
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import "gorm.io/gorm"
+
+// QueryOption 仓储查询选项，通过 BaseRepository.With 附加到之后的查询上
+type QueryOption struct {
+	scope func(*gorm.DB) *gorm.DB
+	// countable 统计总数时是否生效，预加载、字段裁剪和分页不影响总数
+	countable bool
+}
+
+// WithPreload 预加载关联，args 可以是查询条件或 func(*gorm.DB) *gorm.DB，
+// 例如只加载最近的 10 条记录：WithPreload("Orders", func(db *gorm.DB) *gorm.DB { return db.Order("id DESC").Limit(10) })
+func WithPreload(query string, args ...interface{}) QueryOption {
+	return QueryOption{scope: func(db *gorm.DB) *gorm.DB {
+		return db.Preload(query, args...)
+	}}
+}
+
+// WithJoins 连接关联或表，统计总数时同样生效，以便按关联表的字段过滤
+func WithJoins(query string, args ...interface{}) QueryOption {
+	return QueryOption{countable: true, scope: func(db *gorm.DB) *gorm.DB {
+		return db.Joins(query, args...)
+	}}
+}
+
+// SelectFields 只查询指定字段，避免加载大字段
+func SelectFields(fields ...string) QueryOption {
+	return QueryOption{scope: func(db *gorm.DB) *gorm.DB {
+		return db.Select(fields)
+	}}
+}
+
+// WithOrder 指定排序，例如 "id DESC"
+func WithOrder(order string) QueryOption {
+	return QueryOption{scope: func(db *gorm.DB) *gorm.DB {
+		return db.Order(order)
+	}}
+}
+
+// WithPagination 分页查询，limit 小于等于 0 时不限制条数
+func WithPagination(offset, limit int) QueryOption {
+	return QueryOption{scope: func(db *gorm.DB) *gorm.DB {
+		if offset > 0 {
+			db = db.Offset(offset)
+		}
+		if limit > 0 {
+			db = db.Limit(limit)
+		}
+		return db
+	}}
+}
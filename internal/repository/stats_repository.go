@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StatsRepository 每日统计仓储接口
+type StatsRepository interface {
+	// Save 写入一天的统计，已存在时覆盖
+	Save(ctx context.Context, stats *model.DailyStats) error
+	// ListDaily 按日期升序返回 [from, to] 范围内的统计，日期格式 YYYY-MM-DD
+	ListDaily(ctx context.Context, from, to string) ([]*model.DailyStats, error)
+}
+
+// statsRepository 每日统计仓储实现
+type statsRepository struct {
+	*BaseRepository
+}
+
+// NewStatsRepository 创建每日统计仓储实例
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// Save 写入一天的统计
+func (r *statsRepository) Save(ctx context.Context, stats *model.DailyStats) error {
+	err := r.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "date"}},
+		UpdateAll: true,
+	}).Create(stats).Error
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to save daily stats")
+	}
+	return nil
+}
+
+// ListDaily 查询日期范围内的统计
+func (r *statsRepository) ListDaily(ctx context.Context, from, to string) ([]*model.DailyStats, error) {
+	var list []*model.DailyStats
+	if err := r.WithContext(ctx).Where("date >= ? AND date <= ?", from, to).Order("date").Find(&list).Error; err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to list daily stats")
+	}
+	return list, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// TaskRepository 后台任务仓储接口
+type TaskRepository interface {
+	Create(ctx context.Context, task *model.Task) error
+	GetByID(ctx context.Context, id string) (*model.Task, error)
+	Updates(ctx context.Context, id string, values map[string]interface{}) error
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
+
+// taskRepository 后台任务仓储实现
+type taskRepository struct {
+	*BaseRepository
+}
+
+// NewTaskRepository 创建后台任务仓储实例
+func NewTaskRepository(db *gorm.DB) TaskRepository {
+	return &taskRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// Create 创建任务
+func (r *taskRepository) Create(ctx context.Context, task *model.Task) error {
+	return r.BaseRepository.Create(ctx, task)
+}
+
+// GetByID 根据ID获取任务
+func (r *taskRepository) GetByID(ctx context.Context, id string) (*model.Task, error) {
+	var task model.Task
+	if err := r.BaseRepository.FindOne(ctx, &task, "id = ?", id); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Updates 更新任务的部分字段
+func (r *taskRepository) Updates(ctx context.Context, id string, values map[string]interface{}) error {
+	result := r.WithContext(ctx).Model(&model.Task{}).Where("id = ?", id).Updates(values)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, errors.ErrorTypeDatabase, "failed to update task")
+	}
+	if result.RowsAffected == 0 {
+		return errors.Wrap(gorm.ErrRecordNotFound, errors.ErrorTypeDatabase, "failed to update task")
+	}
+	return nil
+}
+
+// DeleteExpired 删除过期时间早于 before 的任务
+func (r *taskRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result := r.WithContext(ctx).Where("expires_at < ?", before).Delete(&model.Task{})
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, errors.ErrorTypeDatabase, "failed to delete expired tasks")
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// UserAuditLogRepository 用户审计日志仓储接口
+type UserAuditLogRepository interface {
+	Create(ctx context.Context, log *model.UserAuditLog) error
+	ListByUser(ctx context.Context, userID uint, offset, limit int) ([]*model.UserAuditLog, int64, error)
+	DeleteByUser(ctx context.Context, userID uint) error
+	ClearActor(ctx context.Context, actorID uint) (int64, error)
+}
+
+// userAuditLogRepository 用户审计日志仓储实现
+type userAuditLogRepository struct {
+	*BaseRepository
+}
+
+// NewUserAuditLogRepository 创建用户审计日志仓储实例
+func NewUserAuditLogRepository(db *gorm.DB) UserAuditLogRepository {
+	return &userAuditLogRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// Create 追加一条审计日志
+func (r *userAuditLogRepository) Create(ctx context.Context, log *model.UserAuditLog) error {
+	return r.BaseRepository.Create(ctx, log)
+}
+
+// ListByUser 按时间倒序分页查询用户的审计日志，limit 小于等于 0 时返回全部
+func (r *userAuditLogRepository) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]*model.UserAuditLog, int64, error) {
+	total, err := r.BaseRepository.Count(ctx, &model.UserAuditLog{}, "user_id = ?", userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var logs []*model.UserAuditLog
+	err = r.With(WithOrder("created_at DESC, id DESC"), WithPagination(offset, limit)).FindMany(ctx, &logs, "user_id = ?", userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, total, nil
+}
+
+// DeleteByUser 删除用户的全部审计日志，用于彻底删除账户
+func (r *userAuditLogRepository) DeleteByUser(ctx context.Context, userID uint) error {
+	if err := r.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.UserAuditLog{}).Error; err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to delete user audit logs")
+	}
+	return nil
+}
+
+// ClearActor 将其他用户审计日志中的操作人 actorID 清零，返回修改的行数
+func (r *userAuditLogRepository) ClearActor(ctx context.Context, actorID uint) (int64, error) {
+	result := r.WithContext(ctx).Model(&model.UserAuditLog{}).Where("actor_id = ?", actorID).UpdateColumn("actor_id", 0)
+	if result.Error != nil {
+		return 0, errors.Wrap(result.Error, errors.ErrorTypeDatabase, "failed to clear audit log actor")
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/bloom"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// UserExistenceFilter 用户名/邮箱存在性过滤器
+// 用于在注册等场景下快速排除一定不存在的用户名和邮箱，避免每次都查询数据库
+type UserExistenceFilter struct {
+	db        *gorm.DB
+	usernames *bloom.Filter
+	emails    *bloom.Filter
+}
+
+// NewUserExistenceFilter 创建用户存在性过滤器，未启用时返回 nil
+func NewUserExistenceFilter(db *gorm.DB, client *redis.Client, cfg *config.BloomFilter) *UserExistenceFilter {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = "bloom"
+	}
+
+	return &UserExistenceFilter{
+		db:        db,
+		usernames: bloom.New(client, prefix+":users:username", cfg.ExpectedItems, cfg.FalsePositiveRate),
+		emails:    bloom.New(client, prefix+":users:email", cfg.ExpectedItems, cfg.FalsePositiveRate),
+	}
+}
+
+// Add 记录新的用户名和邮箱
+func (f *UserExistenceFilter) Add(ctx context.Context, username, email string) error {
+	if err := f.usernames.Add(ctx, username); err != nil {
+		return err
+	}
+	return f.emails.Add(ctx, email)
+}
+
+// Rebuild 从数据库全量重建过滤器
+// 布隆过滤器不支持删除，定期重建可以清除已删除用户带来的误判
+func (f *UserExistenceFilter) Rebuild(ctx context.Context) error {
+	if err := f.usernames.Rebuild(ctx, f.columnBatches(ctx, "username")); err != nil {
+		return err
+	}
+	return f.emails.Rebuild(ctx, f.columnBatches(ctx, "email"))
+}
+
+// columnBatches 按主键分批读取未删除用户的某一列
+func (f *UserExistenceFilter) columnBatches(ctx context.Context, column string) func() ([]string, error) {
+	var lastID uint
+	return func() ([]string, error) {
+		var rows []struct {
+			ID    uint
+			Value string
+		}
+		err := f.db.WithContext(ctx).
+			Model(&model.User{}).
+			Select("id, "+column+" AS value").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(1000).
+			Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+
+		values := make([]string, len(rows))
+		for i, row := range rows {
+			values[i] = row.Value
+			lastID = row.ID
+		}
+		return values, nil
+	}
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// UserIdentityRepository 外部账号关联仓储接口
+type UserIdentityRepository interface {
+	Create(ctx context.Context, identity *model.UserIdentity) error
+	Update(ctx context.Context, identity *model.UserIdentity) error
+	// GetByProviderUserID 按提供方和提供方用户标识查询关联
+	GetByProviderUserID(ctx context.Context, provider, providerUserID string) (*model.UserIdentity, error)
+	// GetByUserProvider 查询用户在提供方关联的外部账号
+	GetByUserProvider(ctx context.Context, userID uint, provider string) (*model.UserIdentity, error)
+	// GetByUserAndID 查询用户的一个关联，关联不属于该用户时返回 gorm.ErrRecordNotFound
+	GetByUserAndID(ctx context.Context, userID, id uint) (*model.UserIdentity, error)
+	ListByUser(ctx context.Context, userID uint) ([]*model.UserIdentity, error)
+	Delete(ctx context.Context, id uint) error
+	DeleteByUser(ctx context.Context, userID uint) error
+}
+
+// userIdentityRepository 外部账号关联仓储实现
+type userIdentityRepository struct {
+	*BaseRepository
+}
+
+// NewUserIdentityRepository 创建外部账号关联仓储实例
+func NewUserIdentityRepository(db *gorm.DB) UserIdentityRepository {
+	return &userIdentityRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// Create 创建关联
+func (r *userIdentityRepository) Create(ctx context.Context, identity *model.UserIdentity) error {
+	return r.BaseRepository.Create(ctx, identity)
+}
+
+// Update 更新关联
+func (r *userIdentityRepository) Update(ctx context.Context, identity *model.UserIdentity) error {
+	return r.BaseRepository.Update(ctx, identity)
+}
+
+// GetByProviderUserID 按提供方和提供方用户标识查询关联
+func (r *userIdentityRepository) GetByProviderUserID(ctx context.Context, provider, providerUserID string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.FindOne(ctx, &identity, "provider = ? AND provider_user_id = ?", provider, providerUserID)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// GetByUserProvider 查询用户在提供方关联的外部账号
+func (r *userIdentityRepository) GetByUserProvider(ctx context.Context, userID uint, provider string) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.FindOne(ctx, &identity, "user_id = ? AND provider = ?", userID, provider)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// GetByUserAndID 查询用户的一个关联
+func (r *userIdentityRepository) GetByUserAndID(ctx context.Context, userID, id uint) (*model.UserIdentity, error) {
+	var identity model.UserIdentity
+	err := r.FindOne(ctx, &identity, "id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// ListByUser 按关联时间查询用户的全部外部账号
+func (r *userIdentityRepository) ListByUser(ctx context.Context, userID uint) ([]*model.UserIdentity, error) {
+	var identities []*model.UserIdentity
+	err := r.With(WithOrder("id ASC")).FindMany(ctx, &identities, "user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// Delete 删除关联
+func (r *userIdentityRepository) Delete(ctx context.Context, id uint) error {
+	return r.BaseRepository.Delete(ctx, &model.UserIdentity{ID: id})
+}
+
+// DeleteByUser 删除用户的全部关联，用于彻底删除账户
+func (r *userIdentityRepository) DeleteByUser(ctx context.Context, userID uint) error {
+	if err := r.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.UserIdentity{}).Error; err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to delete user identities")
+	}
+	return nil
+}
@@ -2,7 +2,9 @@ package repository
 
 import (
 	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/errors"
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -16,25 +18,43 @@ type UserRepository interface {
 	Update(ctx context.Context, user *model.User) error
 	Delete(ctx context.Context, id uint) error
 	List(ctx context.Context, offset, limit int) ([]*model.User, int64, error)
+	Count(ctx context.Context) (int64, error)
+	CountCreatedBetween(ctx context.Context, from, to time.Time) (int64, error)
+	ListAfterID(ctx context.Context, afterID uint, limit int) ([]*model.User, error)
+	ListChangedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]*model.User, error)
 	ExistsByUsername(ctx context.Context, username string) (bool, error)
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	SetDeletionSchedule(ctx context.Context, id uint, at *time.Time) error
+	SetPendingEmail(ctx context.Context, id uint, email, tokenHash string, expiresAt *time.Time) error
+	SetLastLogin(ctx context.Context, id uint, at time.Time) error
+	SetPassword(ctx context.Context, id uint, hash string) error
+	ListDeletionDue(ctx context.Context, before time.Time, afterID uint, limit int) ([]*model.User, error)
+	HardDelete(ctx context.Context, id uint) error
+	ClearAuditReferences(ctx context.Context, userID uint) (int64, error)
 }
 
 // userRepository 用户仓储实现
 type userRepository struct {
 	*BaseRepository
+	existence *UserExistenceFilter
 }
 
 // NewUserRepository 创建用户仓储实例
-func NewUserRepository(db *gorm.DB) UserRepository {
+// existence 为 nil 时存在性检查直接查询数据库
+func NewUserRepository(db *gorm.DB, existence *UserExistenceFilter) UserRepository {
 	return &userRepository{
 		BaseRepository: NewBaseRepository(db),
+		existence:      existence,
 	}
 }
 
 // Create 创建用户
 func (r *userRepository) Create(ctx context.Context, user *model.User) error {
-	return r.BaseRepository.Create(ctx, user)
+	if err := r.BaseRepository.Create(ctx, user); err != nil {
+		return err
+	}
+	r.rememberUser(ctx, user)
+	return nil
 }
 
 // GetByID 根据ID获取用户
@@ -69,7 +89,11 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.U
 
 // Update 更新用户
 func (r *userRepository) Update(ctx context.Context, user *model.User) error {
-	return r.BaseRepository.Update(ctx, user)
+	if err := r.BaseRepository.Update(ctx, user); err != nil {
+		return err
+	}
+	r.rememberUser(ctx, user)
+	return nil
 }
 
 // Delete 删除用户（软删除）
@@ -88,7 +112,7 @@ func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*model.
 	}
 
 	// 获取分页数据
-	err = r.BaseRepository.FindMany(ctx, &users, "", "")
+	err = r.BaseRepository.With(WithOrder("id"), WithPagination(offset, limit)).FindMany(ctx, &users, "")
 	if err != nil {
 		return nil, 0, err
 	}
@@ -96,12 +120,143 @@ func (r *userRepository) List(ctx context.Context, offset, limit int) ([]*model.
 	return users, total, nil
 }
 
+// Count 统计用户数
+func (r *userRepository) Count(ctx context.Context) (int64, error) {
+	return r.BaseRepository.Count(ctx, &model.User{}, "")
+}
+
+// ListAfterID 按 ID 升序返回 ID 大于 afterID 的用户，用于分批遍历全部用户，不受遍历期间新增数据影响
+func (r *userRepository) ListAfterID(ctx context.Context, afterID uint, limit int) ([]*model.User, error) {
+	var users []*model.User
+	err := r.BaseRepository.With(WithOrder("id"), WithPagination(0, limit)).FindMany(ctx, &users, "id > ?", afterID)
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// CountCreatedBetween 统计创建时间在 [from, to) 内的用户数，包含之后被删除的用户
+func (r *userRepository) CountCreatedBetween(ctx context.Context, from, to time.Time) (int64, error) {
+	var count int64
+	err := r.countContext(ctx).Unscoped().Model(&model.User{}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Count(&count).Error
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to count new users")
+	}
+	return count, nil
+}
+
+// ListChangedSince 按 ID 升序返回 since 之后更新或软删除的用户（包含已删除用户），用于增量同步
+func (r *userRepository) ListChangedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]*model.User, error) {
+	var users []*model.User
+	err := r.WithContext(ctx).Unscoped().
+		Where("id > ? AND (updated_at >= ? OR deleted_at >= ?)", afterID, since, since).
+		Order("id").Limit(limit).Find(&users).Error
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to list changed users")
+	}
+	return users, nil
+}
+
 // ExistsByUsername 检查用户名是否存在
 func (r *userRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	if r.existence != nil {
+		// 过滤器确认不存在时无需查询数据库；Redis 异常时回退到数据库
+		if maybe, err := r.existence.usernames.MightContain(ctx, username); err == nil && !maybe {
+			return false, nil
+		}
+	}
 	return r.BaseRepository.Exists(ctx, &model.User{}, "username = ?", username)
 }
 
 // ExistsByEmail 检查邮箱是否存在
 func (r *userRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	if r.existence != nil {
+		if maybe, err := r.existence.emails.MightContain(ctx, email); err == nil && !maybe {
+			return false, nil
+		}
+	}
 	return r.BaseRepository.Exists(ctx, &model.User{}, "email = ?", email)
 }
+
+// rememberUser 将用户名和邮箱写入存在性过滤器
+// 写入失败只会导致后续存在性检查漏判，最终仍由数据库唯一索引兜底
+func (r *userRepository) rememberUser(ctx context.Context, user *model.User) {
+	if r.existence != nil {
+		_ = r.existence.Add(ctx, user.Username, user.Email)
+	}
+}
+
+// SetDeletionSchedule 设置账户彻底删除的时间，at 为 nil 时撤销删除申请
+func (r *userRepository) SetDeletionSchedule(ctx context.Context, id uint, at *time.Time) error {
+	err := r.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Update("deletion_scheduled_at", at).Error
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to update deletion schedule")
+	}
+	return nil
+}
+
+// SetPendingEmail 保存待确认的新邮箱和确认令牌哈希，email 为空时清除待确认的修改
+func (r *userRepository) SetPendingEmail(ctx context.Context, id uint, email, tokenHash string, expiresAt *time.Time) error {
+	err := r.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"pending_email":           email,
+		"email_change_token_hash": tokenHash,
+		"email_change_expires_at": expiresAt,
+	}).Error
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to update pending email")
+	}
+	return nil
+}
+
+// SetLastLogin 记录最近一次登录时间，不修改 updated_at
+func (r *userRepository) SetLastLogin(ctx context.Context, id uint, at time.Time) error {
+	err := r.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).UpdateColumn("last_login_at", at).Error
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to update last login")
+	}
+	return nil
+}
+
+// SetPassword 只更新密码哈希，不修改 updated_at，用于登录时升级哈希参数
+func (r *userRepository) SetPassword(ctx context.Context, id uint, hash string) error {
+	err := r.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).UpdateColumn("password", hash).Error
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to update password hash")
+	}
+	return nil
+}
+
+// ListDeletionDue 按 ID 升序返回 ID 大于 afterID、删除时间不晚于 before 的用户（包含已软删除的用户）
+func (r *userRepository) ListDeletionDue(ctx context.Context, before time.Time, afterID uint, limit int) ([]*model.User, error) {
+	var users []*model.User
+	err := r.WithContext(ctx).Unscoped().
+		Where("id > ? AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", afterID, before).
+		Order("id").Limit(limit).Find(&users).Error
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to list users due for deletion")
+	}
+	return users, nil
+}
+
+// HardDelete 从数据库中彻底删除用户
+func (r *userRepository) HardDelete(ctx context.Context, id uint) error {
+	if err := r.WithContext(ctx).Unscoped().Delete(&model.User{}, id).Error; err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to hard delete user")
+	}
+	return nil
+}
+
+// ClearAuditReferences 将其他用户记录中指向 userID 的创建人、最后修改人清零，返回修改的行数
+func (r *userRepository) ClearAuditReferences(ctx context.Context, userID uint) (int64, error) {
+	var affected int64
+	for _, column := range []string{"created_by", "updated_by"} {
+		result := r.WithContext(ctx).Unscoped().Model(&model.User{}).Where(column+" = ?", userID).UpdateColumn(column, 0)
+		if result.Error != nil {
+			return affected, errors.Wrap(result.Error, errors.ErrorTypeDatabase, "failed to clear audit references")
+		}
+		affected += result.RowsAffected
+	}
+	return affected, nil
+}
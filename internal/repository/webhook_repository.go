@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// WebhookDeliveryRepository 出站 Webhook 投递记录仓储接口
+type WebhookDeliveryRepository interface {
+	Create(ctx context.Context, delivery *model.WebhookDelivery) error
+	FindDue(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error)
+	Updates(ctx context.Context, id uint, values map[string]interface{}) error
+}
+
+// webhookDeliveryRepository 出站 Webhook 投递记录仓储实现
+type webhookDeliveryRepository struct {
+	*BaseRepository
+}
+
+// NewWebhookDeliveryRepository 创建出站 Webhook 投递记录仓储实例
+func NewWebhookDeliveryRepository(db *gorm.DB) WebhookDeliveryRepository {
+	return &webhookDeliveryRepository{
+		BaseRepository: NewBaseRepository(db),
+	}
+}
+
+// Create 创建投递记录
+func (r *webhookDeliveryRepository) Create(ctx context.Context, delivery *model.WebhookDelivery) error {
+	return r.BaseRepository.Create(ctx, delivery)
+}
+
+// FindDue 查询待投递或投递失败且已到重试时间的记录，按下次投递时间排序
+func (r *webhookDeliveryRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	err := r.With(WithOrder("next_attempt_at"), WithPagination(0, limit)).FindMany(ctx, &deliveries,
+		"status IN ? AND next_attempt_at <= ?",
+		[]string{model.WebhookStatusPending, model.WebhookStatusFailed}, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// Updates 更新投递记录的部分字段
+func (r *webhookDeliveryRepository) Updates(ctx context.Context, id uint, values map[string]interface{}) error {
+	result := r.WithContext(ctx).Model(&model.WebhookDelivery{}).Where("id = ?", id).Updates(values)
+	if result.Error != nil {
+		return errors.Wrap(result.Error, errors.ErrorTypeDatabase, "failed to update webhook delivery")
+	}
+	if result.RowsAffected == 0 {
+		return errors.Wrap(gorm.ErrRecordNotFound, errors.ErrorTypeDatabase, "failed to update webhook delivery")
+	}
+	return nil
+}
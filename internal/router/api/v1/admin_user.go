@@ -0,0 +1,19 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterAdminUserRoutes 注册用户管理路由，与用户自助接口分开，由 route_policies 限定管理员访问
+func RegisterAdminUserRoutes(group *gin.RouterGroup, adminUserHandler *handlers.AdminUserHandler) {
+	users := group.Group("/admin/users")
+	{
+		users.POST("/:id/password-reset", adminUserHandler.ForcePasswordReset) // 强制重置密码并吊销全部令牌
+		users.POST("/:id/disable", adminUserHandler.DisableUser)               // 禁用账户并吊销全部令牌
+		users.POST("/:id/enable", adminUserHandler.EnableUser)                 // 启用账户
+		users.PUT("/:id/roles", adminUserHandler.AssignRoles)                  // 分配角色
+		users.GET("/:id/sessions", adminUserHandler.GetSessions)               // 查询会话状态
+		users.GET("/:id/audit-logs", adminUserHandler.ListAuditLogs)           // 查询审计日志
+	}
+}
@@ -0,0 +1,11 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterEventRoutes 注册通用事件发布路由
+func RegisterEventRoutes(group *gin.RouterGroup, eventHandler *handlers.EventHandler) {
+	group.POST("/events/publish", eventHandler.Publish) // 发布允许列表中的事件类型
+}
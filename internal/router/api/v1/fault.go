@@ -0,0 +1,16 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterFaultRoutes 注册故障注入规则管理路由
+func RegisterFaultRoutes(group *gin.RouterGroup, faultHandler *handlers.FaultHandler) {
+	admin := group.Group("/admin")
+	{
+		admin.GET("/faults", faultHandler.GetFaults)      // 查询故障注入规则
+		admin.PUT("/faults", faultHandler.ReplaceFaults)  // 替换故障注入规则
+		admin.DELETE("/faults", faultHandler.ClearFaults) // 清除故障注入规则
+	}
+}
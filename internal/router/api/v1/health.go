@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterHealthRoutes 注册依赖健康检查路由
+func RegisterHealthRoutes(group *gin.RouterGroup, healthHandler *handlers.HealthHandler) {
+	admin := group.Group("/admin")
+	{
+		admin.GET("/health", healthHandler.GetHealth) // 查询依赖健康状态
+	}
+}
@@ -0,0 +1,15 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterIdentityRoutes 注册外部账号关联路由
+func RegisterIdentityRoutes(group *gin.RouterGroup, identityHandler *handlers.IdentityHandler) {
+	users := group.Group("/users")
+	{
+		users.GET("/:id/identities", identityHandler.ListIdentities)                 // 获取关联的外部账号
+		users.DELETE("/:id/identities/:identity_id", identityHandler.UnlinkIdentity) // 解除外部账号关联
+	}
+}
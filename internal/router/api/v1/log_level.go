@@ -0,0 +1,16 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterLogLevelRoutes 注册日志级别管理路由
+func RegisterLogLevelRoutes(group *gin.RouterGroup, logLevelHandler *handlers.LogLevelHandler) {
+	admin := group.Group("/admin")
+	{
+		admin.GET("/log-levels", logLevelHandler.GetLogLevels)           // 查询日志级别
+		admin.PUT("/log-levels/:name", logLevelHandler.SetLogLevel)      // 设置命名 logger 的日志级别
+		admin.DELETE("/log-levels/:name", logLevelHandler.ResetLogLevel) // 恢复命名 logger 的日志级别
+	}
+}
@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterMigrationRoutes 注册数据库迁移管理路由
+func RegisterMigrationRoutes(group *gin.RouterGroup, migrationHandler *handlers.MigrationHandler) {
+	admin := group.Group("/admin")
+	{
+		admin.GET("/migrations", migrationHandler.GetMigrations) // 查询迁移状态
+	}
+}
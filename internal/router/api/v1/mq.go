@@ -0,0 +1,16 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterMQAdminRoutes 注册消息队列管理路由
+func RegisterMQAdminRoutes(group *gin.RouterGroup, mqAdminHandler *handlers.MQAdminHandler) {
+	admin := group.Group("/admin/mq")
+	{
+		admin.GET("/queues", mqAdminHandler.GetQueues)       // 查询队列及消息数、消费者数
+		admin.GET("/exchanges", mqAdminHandler.GetExchanges) // 查询交换机
+		admin.POST("/publish", mqAdminHandler.PublishTest)   // 发布测试消息
+	}
+}
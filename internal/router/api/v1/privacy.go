@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterPrivacyRoutes 注册用户数据导出与账户删除路由
+func RegisterPrivacyRoutes(group *gin.RouterGroup, privacyHandler *handlers.PrivacyHandler) {
+	users := group.Group("/users")
+	{
+		users.POST("/:id/export", privacyHandler.ExportUser)         // 导出用户数据，返回后台任务
+		users.POST("/:id/deletion", privacyHandler.RequestDeletion)  // 申请删除账户
+		users.DELETE("/:id/deletion", privacyHandler.CancelDeletion) // 撤销删除申请
+	}
+}
+
+// RegisterDownloadRoutes 注册签名下载路由
+func RegisterDownloadRoutes(group *gin.RouterGroup, downloadHandler *handlers.DownloadHandler) {
+	group.GET("/downloads/*key", downloadHandler.Download) // 凭签名下载存储中的对象
+}
@@ -11,8 +11,10 @@ func RegisterSchedulerRoutes(group *gin.RouterGroup, schedulerHandler *handlers.
 	{
 		// 基础管理
 		scheduler.GET("/jobs", schedulerHandler.GetJobs)          // 获取任务列表
+		scheduler.GET("/chains", schedulerHandler.GetChains)      // 获取任务链执行状态
 		scheduler.POST("/start", schedulerHandler.StartScheduler) // 启动调度器
 		scheduler.POST("/stop", schedulerHandler.StopScheduler)   // 停止调度器
+		scheduler.GET("/metrics", schedulerHandler.Metrics)       // Prometheus 任务指标
 
 		// 未来可以添加更多调度器功能
 		// scheduler.POST("/jobs", schedulerHandler.CreateJob)        // 创建任务
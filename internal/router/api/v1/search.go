@@ -0,0 +1,19 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterSearchRoutes 注册搜索相关路由
+func RegisterSearchRoutes(group *gin.RouterGroup, searchHandler *handlers.SearchHandler) {
+	search := group.Group("/search")
+	{
+		search.GET("/users", searchHandler.SearchUsers) // 搜索用户
+	}
+
+	admin := group.Group("/admin")
+	{
+		admin.POST("/search/reindex", searchHandler.Reindex) // 重建搜索索引，返回后台任务
+	}
+}
@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterSLORoutes 注册服务等级目标路由
+func RegisterSLORoutes(group *gin.RouterGroup, sloHandler *handlers.SLOHandler) {
+	admin := group.Group("/admin")
+	{
+		admin.GET("/slo", sloHandler.GetSLO) // 查询错误预算
+	}
+}
@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterStatsRoutes 注册统计路由
+func RegisterStatsRoutes(group *gin.RouterGroup, statsHandler *handlers.StatsHandler) {
+	admin := group.Group("/admin")
+	{
+		admin.GET("/stats/daily", statsHandler.GetDailyStats) // 查询每日统计
+	}
+}
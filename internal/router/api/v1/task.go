@@ -0,0 +1,14 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterTaskRoutes 注册后台任务相关路由
+func RegisterTaskRoutes(group *gin.RouterGroup, taskHandler *handlers.TaskHandler) {
+	tasks := group.Group("/tasks")
+	{
+		tasks.GET("/:id", taskHandler.GetTask) // 查询任务进度
+	}
+}
@@ -0,0 +1,18 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
+)
+
+// RegisterUploadRoutes 注册分片上传相关路由
+func RegisterUploadRoutes(group *gin.RouterGroup, uploadHandler *handlers.UploadHandler) {
+	uploads := group.Group("/uploads")
+	{
+		uploads.POST("", uploadHandler.CreateUpload)                 // 创建上传会话
+		uploads.GET("/:id", uploadHandler.GetUpload)                 // 查询上传进度
+		uploads.PUT("/:id/chunks/:index", uploadHandler.UploadChunk) // 上传分片
+		uploads.POST("/:id/complete", uploadHandler.CompleteUpload)  // 合并分片
+		uploads.DELETE("/:id", uploadHandler.AbortUpload)            // 取消上传
+	}
+}
@@ -9,11 +9,18 @@ import (
 func RegisterUserRoutes(group *gin.RouterGroup, userHandler *handlers.UserHandler) {
 	users := group.Group("/users")
 	{
-		users.POST("", userHandler.CreateUser)       // 创建用户
-		users.GET("/:id", userHandler.GetUser)       // 获取用户信息
-		users.PUT("/:id", userHandler.UpdateUser)    // 更新用户信息
-		users.DELETE("/:id", userHandler.DeleteUser) // 删除用户
-		users.GET("", userHandler.ListUsers)         // 获取用户列表
+		users.POST("", userHandler.CreateUser)               // 创建用户
+		users.POST("/reservations", userHandler.ReserveUser) // 预占用户名和邮箱
+		users.GET("/:id", userHandler.GetUser)               // 获取用户信息
+		users.PUT("/:id", userHandler.UpdateUser)            // 更新用户信息，空字符串表示不修改
+		users.PATCH("/:id", userHandler.PatchUser)           // 部分更新用户信息
+		users.DELETE("/:id", userHandler.DeleteUser)         // 删除用户
+		users.GET("", userHandler.ListUsers)                 // 获取用户列表
+
+		users.POST("/:id/email-change/confirm", userHandler.ConfirmEmailChange) // 凭令牌确认修改邮箱
+		users.DELETE("/:id/email-change", userHandler.CancelEmailChange)        // 撤销待确认的邮箱修改
+
+		users.POST("/:id/password-reset/confirm", userHandler.ConfirmPasswordReset) // 凭管理员强制重置时发出的令牌设置新密码
 	}
 }
 
@@ -21,12 +28,12 @@ func RegisterUserRoutes(group *gin.RouterGroup, userHandler *handlers.UserHandle
 func RegisterAuthRoutes(group *gin.RouterGroup, userHandler *handlers.UserHandler) {
 	auth := group.Group("/auth")
 	{
-		auth.POST("/login", userHandler.Login) // 用户登录
+		auth.POST("/login", userHandler.Login)          // 用户登录，签发访问令牌和刷新令牌
+		auth.POST("/refresh", userHandler.RefreshToken) // 凭刷新令牌轮换令牌
 
 		// 未来可以添加其他认证相关路由
 		// auth.POST("/register", userHandler.Register)     // 用户注册
 		// auth.POST("/logout", userHandler.Logout)         // 用户登出
-		// auth.POST("/refresh", userHandler.RefreshToken)  // 刷新令牌
 		// auth.GET("/profile", userHandler.GetProfile)     // 获取用户档案
 	}
 }
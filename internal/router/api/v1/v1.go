@@ -2,38 +2,53 @@ package v1
 
 import (
 	"github.com/gin-gonic/gin"
-	handlers "github.com/hedeqiang/skeleton/internal/handler/v1"
 )
 
-// Handlers 包含所有处理器的结构体
-type Handlers struct {
-	UserHandler      *handlers.UserHandler
-	HelloHandler     *handlers.HelloHandler
-	SchedulerHandler *handlers.SchedulerHandler
+// RouteFunc 业务模块的路由挂载函数，参数为 /api/v1 分组
+type RouteFunc func(group *gin.RouterGroup)
+
+// Registry v1 路由注册表
+// 业务模块通过 RegisterRoutes 注册自己的路由挂载函数，由 Wire 在 ProvideRouteRegistry 中统一收集，
+// 新增业务模块时无需修改路由层和 App
+type Registry struct {
+	routes []RouteFunc
 }
 
-// RegisterV1Routes 注册 v1 版本的 API 路由
-func RegisterV1Routes(apiGroup *gin.RouterGroup, handlers *Handlers) {
-	v1Group := apiGroup.Group("/v1")
-	{
-		// 用户相关路由
-		if handlers.UserHandler != nil {
-			RegisterUserRoutes(v1Group, handlers.UserHandler)
-			RegisterAuthRoutes(v1Group, handlers.UserHandler)
-		}
+// NewRegistry 创建路由注册表
+func NewRegistry(routes ...RouteFunc) *Registry {
+	r := &Registry{}
+	for _, fn := range routes {
+		r.RegisterRoutes(fn)
+	}
+	return r
+}
 
-		// 消息队列路由
-		if handlers.HelloHandler != nil {
-			RegisterMessageRoutes(v1Group, handlers.HelloHandler)
-		}
+// RegisterRoutes 注册路由挂载函数，nil 会被忽略
+func (r *Registry) RegisterRoutes(fn RouteFunc) {
+	if fn != nil {
+		r.routes = append(r.routes, fn)
+	}
+}
 
-		// 计划任务路由
-		if handlers.SchedulerHandler != nil {
-			RegisterSchedulerRoutes(v1Group, handlers.SchedulerHandler)
-		}
+// Bind 将处理器绑定到路由注册函数，处理器为 nil 时返回 nil，对应模块的路由不会注册
+//
+//	registry.RegisterRoutes(v1.Bind(orderHandler, v1.RegisterOrderRoutes))
+func Bind[H any](handler *H, register func(*gin.RouterGroup, *H)) RouteFunc {
+	if handler == nil {
+		return nil
+	}
+	return func(group *gin.RouterGroup) {
+		register(group, handler)
+	}
+}
 
-		// 未来可以在这里添加其他业务模块路由
-		// RegisterOrderRoutes(v1Group, handlers.OrderHandler)
-		// RegisterPaymentRoutes(v1Group, handlers.PaymentHandler)
+// RegisterV1Routes 注册 v1 版本的 API 路由，按注册顺序挂载
+func RegisterV1Routes(apiGroup *gin.RouterGroup, registry *Registry) {
+	v1Group := apiGroup.Group("/v1")
+	if registry == nil {
+		return
+	}
+	for _, fn := range registry.routes {
+		fn(v1Group)
 	}
 }
@@ -1,50 +1,263 @@
 package router
 
 import (
-	v1 "github.com/hedeqiang/skeleton/internal/handler/v1"
+	"net/http"
+	"slices"
+
+	"github.com/hedeqiang/skeleton/internal/config"
 	"github.com/hedeqiang/skeleton/internal/middleware"
+	"github.com/hedeqiang/skeleton/internal/policy"
 	"github.com/hedeqiang/skeleton/internal/router/api"
+	apiv1 "github.com/hedeqiang/skeleton/internal/router/api/v1"
+	"github.com/hedeqiang/skeleton/internal/router/static"
 	"github.com/hedeqiang/skeleton/internal/router/system"
+	"github.com/hedeqiang/skeleton/pkg/cache"
+	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/fault"
+	"github.com/hedeqiang/skeleton/pkg/i18n"
+	"github.com/hedeqiang/skeleton/pkg/jwt"
+	"github.com/hedeqiang/skeleton/pkg/loadshed"
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+	"github.com/hedeqiang/skeleton/pkg/openapi"
+	"github.com/hedeqiang/skeleton/pkg/ratelimit"
+	"github.com/hedeqiang/skeleton/pkg/response"
+	"github.com/hedeqiang/skeleton/pkg/trace"
+	"github.com/hedeqiang/skeleton/pkg/validator"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-// Handlers 包含所有处理器的结构体
-type Handlers struct {
-	UserHandler      *v1.UserHandler
-	HelloHandler     *v1.HelloHandler
-	SchedulerHandler *v1.SchedulerHandler
+// Middlewares 包含需要外部依赖的中间件组件
+type Middlewares struct {
+	ResponseCache *cache.ResponseCache
+	JWT           *jwt.JWT
+	Revocations   *jwt.Revocations
+	Policies      *policy.Registry
+	RateLimiter   *ratelimit.Limiter
+	I18n          *i18n.I18n
+	OpenAPI       *openapi.Validator    // 未开启 OpenAPI 校验时为 nil
+	DBPool        *database.PoolMonitor // 未开启连接池监控时为 nil
+	LoadShed      *loadshed.Monitor     // 未开启自适应降载时为 nil
+	Faults        *fault.Injector       // 未开启故障注入或生产环境时为 nil
+	HTTPMetrics   *metrics.HTTPMetrics  // 未开启 HTTP 请求指标时为 nil
+	SLO           *metrics.SLOMetrics   // 未开启 SLO 时为 nil
+	Tracing       *trace.Provider       // 未开启链路追踪时为 nil
 }
 
 // SetupRouter 设置路由
 // 路由层只负责路由配置，不负责依赖创建
-func SetupRouter(logger *zap.Logger, handlers *Handlers) *gin.Engine {
-	// 设置 Gin 模式
-	gin.SetMode(gin.ReleaseMode)
+func SetupRouter(cfg *config.Config, logger *zap.Logger, routes *apiv1.Registry, readinessChecks system.ReadinessChecks, middlewares *Middlewares) *gin.Engine {
+	// 根据运行环境设置 Gin 模式和调试工具
+	setupMode(cfg, logger)
+
+	// 错误响应按 Accept 协商 RFC 7807 problem+json
+	response.SetProblemDetails(cfg.Problem.Enabled, cfg.Problem.TypeBaseURI)
 
 	r := gin.New()
 
+	// 未匹配路由和方法不允许时返回统一的 JSON 响应
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(response.NoRoute)
+	r.NoMethod(response.NoMethod)
+
 	// 注册中间件
-	setupMiddleware(r, logger)
+	global := setupMiddleware(r, cfg, logger, middlewares)
 
 	// 注册系统路由（健康检查等）
-	system.RegisterSystemRoutes(r, logger)
+	system.RegisterSystemRoutes(r, logger, readinessChecks)
+
+	// 指标和 pprof 只在启用内部端口时注册，避免通过公开端口暴露
+	if cfg.App.SplitListeners() {
+		system.RegisterMetricsRoutes(r)
+		system.RegisterDebugRoutes(r)
+	}
+
+	// 注册 JWKS（仅 RS256、ES256）
+	system.RegisterJWKSRoutes(r, middlewares.JWT)
 
 	// 注册 API 路由
-	api.RegisterAPIRoutes(r, &api.Handlers{
-		UserHandler:      handlers.UserHandler,
-		HelloHandler:     handlers.HelloHandler,
-		SchedulerHandler: handlers.SchedulerHandler,
-	})
+	api.RegisterAPIRoutes(r, routes)
+
+	// 注册静态文件和 SPA 回退（需在 API 路由之后）
+	static.RegisterStaticRoutes(r, &cfg.Static, logger)
+
+	// 注册路由清单（需在所有路由之后），通过 route_policies 中 /api/v1/admin/* 的规则限制访问
+	system.RegisterRouteListRoutes(r, "/api/v1/admin/routes", global, routeDescriber(cfg, middlewares))
 
 	return r
 }
 
-// setupMiddleware 设置中间件
-func setupMiddleware(r *gin.Engine, logger *zap.Logger) {
+// routeDescriber 返回路由的认证要求、命中的策略、缓存规则和废弃状态，用于路由清单
+func routeDescriber(cfg *config.Config, middlewares *Middlewares) system.RouteDescriber {
+	describeAuth := middleware.DescribeAuth(&cfg.Auth)
+	describePolicy := middleware.DescribeRoutePolicy(&cfg.RoutePolicies)
+	describeAuthorization := middleware.DescribeAuthorization(&cfg.Authorization)
+	describeDeprecation := middleware.DescribeDeprecation(&cfg.Deprecations)
+	return func(method, path string) []string {
+		var policies []string
+		if cfg.Auth.Enabled {
+			policies = append(policies, describeAuth(method, path)...)
+		}
+		var described []string
+		if cfg.RoutePolicies.Enabled {
+			described = append(described, describePolicy(method, path)...)
+		}
+		if cfg.Authorization.Enabled {
+			described = append(described, describeAuthorization(method, path)...)
+		}
+		for _, policy := range described {
+			if !slices.Contains(policies, policy) {
+				policies = append(policies, policy)
+			}
+		}
+		if cfg.Deprecations.Enabled {
+			policies = append(policies, describeDeprecation(method, path)...)
+		}
+		if cfg.App.SplitListeners() && middleware.InternalRoute(path) {
+			policies = append(policies, "listener=internal")
+		}
+		if method == http.MethodGet && middlewares.ResponseCache.Enabled() {
+			if rule, ok := middlewares.ResponseCache.Rule(path); ok {
+				policies = append(policies, "cache_ttl="+rule.TTL.String())
+			}
+		}
+		return policies
+	}
+}
+
+// setupMode 根据运行环境设置 Gin 模式，开发环境输出路由注册日志和详细的参数校验错误
+func setupMode(cfg *config.Config, logger *zap.Logger) {
+	gin.SetMode(cfg.App.GinMode())
+
+	dev := cfg.App.IsDevelopment()
+	validator.SetVerbose(dev)
+	if dev {
+		gin.DebugPrintRouteFunc = func(method, path, handler string, handlers int) {
+			logger.Debug("Route registered",
+				zap.String("method", method),
+				zap.String("path", path),
+				zap.String("handler", handler),
+				zap.Int("handlers", handlers),
+			)
+		}
+	}
+}
+
+// setupMiddleware 设置中间件，返回按执行顺序排列的全局中间件名称
+func setupMiddleware(r *gin.Engine, cfg *config.Config, logger *zap.Logger, middlewares *Middlewares) []string {
 	r.Use(middleware.RequestID())
+	names := []string{"request_id"}
+
+	// 链路追踪，放在 Logger 之前使请求日志中的 trace_id 与导出的 span 一致
+	if middlewares.Tracing != nil {
+		r.Use(middleware.NewTracing(middlewares.Tracing))
+		names = append(names, "tracing")
+	}
+
 	r.Use(middleware.NewLogger(logger))
+	names = append(names, "logger")
+
+	// HTTP 请求指标，放在 Recovery 之前使 panic 的请求同样被记录，放在降载、认证之前以统计被拒绝的请求
+	if middlewares.HTTPMetrics != nil {
+		r.Use(middleware.NewHTTPMetrics(middlewares.HTTPMetrics))
+		names = append(names, "http_metrics")
+	}
+
+	// 服务等级目标，与 http_metrics 相同放在 Recovery 之前，被降载、限流拒绝的请求同样计入
+	if middlewares.SLO != nil {
+		r.Use(middleware.NewSLO(middlewares.SLO, cfg.SLO))
+		names = append(names, "slo")
+	}
+
 	r.Use(middleware.NewRecovery(logger))
-	r.Use(middleware.CORS())
+	r.Use(middleware.CORS(cfg.CORS))
+	names = append(names, "recovery", "cors")
+
+	// 端口隔离，公开端口只暴露业务接口，内部端口只暴露系统路由和管理接口
+	if cfg.App.SplitListeners() {
+		r.Use(middleware.NewListenerScope())
+		names = append(names, "listener_scope")
+	}
+
+	// 按请求语言翻译错误消息，需在路由策略之前注册以覆盖其返回的错误
+	if cfg.I18n.Enabled {
+		r.Use(middleware.NewI18n(middlewares.I18n))
+		names = append(names, "i18n")
+	}
+
+	// 连接池饱和时降载，放在慢请求检测和路由策略之前，被拒绝的请求不再消耗认证、限流等资源
+	if cfg.DBPool.Shed && middlewares.DBPool != nil {
+		r.Use(middleware.NewDBPoolShedding(logger, middlewares.DBPool, cfg.DBPool))
+		names = append(names, "db_pool_shedding")
+	}
+
+	// 实例过载时按路由优先级降载，与连接池降载同理放在认证、限流之前
+	if middlewares.LoadShed != nil {
+		r.Use(middleware.NewLoadShedding(logger, middlewares.LoadShed, cfg.LoadShedding))
+		names = append(names, "load_shedding")
+	}
+
+	// 慢请求检测，放在路由策略之前以包含认证、限流的耗时
+	if cfg.SlowRequest.Enabled {
+		r.Use(middleware.NewSlowRequest(logger, cfg.SlowRequest))
+		names = append(names, "slow_request")
+	}
+
+	// 废弃接口响应头与调用统计，放在路由策略之前，被拒绝的请求也能看到废弃提示
+	if cfg.Deprecations.Enabled {
+		r.Use(middleware.NewDeprecation(logger, &cfg.Deprecations))
+		names = append(names, "deprecation")
+	}
+
+	// 路由组认证，放在路由策略之前，策略中要求认证的路由复用这里解析的令牌
+	if cfg.Auth.Enabled {
+		r.Use(middleware.NewAuth(logger, &cfg.Auth, middlewares.JWT, middlewares.Revocations))
+		names = append(names, "auth")
+	}
+
+	// 路由级策略（认证、角色、限流、超时），需在响应缓存之前执行
+	if cfg.RoutePolicies.Enabled {
+		r.Use(middleware.NewRoutePolicy(logger, &cfg.RoutePolicies, middlewares.JWT, middlewares.Revocations, middlewares.RateLimiter))
+		names = append(names, "route_policy")
+	}
+
+	// 资源级授权，放在路由策略之后，被限流或超时的请求不再查询资源所有者
+	if cfg.Authorization.Enabled {
+		r.Use(middleware.NewAuthorization(logger, &cfg.Authorization, middlewares.Policies, middlewares.JWT, middlewares.Revocations))
+		names = append(names, "authorization")
+	}
+
+	// 故障注入，放在路由策略之后，认证和限流照常生效，注入的延迟计入路由超时
+	if middlewares.Faults != nil {
+		r.Use(middleware.NewFaultInjection(logger, middlewares.Faults))
+		names = append(names, "fault_injection")
+		logger.Warn("Fault injection enabled", zap.Int("rules", len(middlewares.Faults.Rules())))
+	}
+
+	// 请求级 SQL 计数，开发环境下通过响应头暴露
+	if cfg.QueryCounter.Enabled {
+		r.Use(middleware.NewQueryCounter(logger, cfg.QueryCounter, cfg.App.IsDevelopment()))
+		names = append(names, "query_counter")
+	}
+
+	// 请求体解压，放在路由策略之后，未认证的请求不消耗解压资源；需在 OpenAPI 校验之前以校验解压后的请求体
+	if cfg.Decompression.Enabled {
+		r.Use(middleware.NewRequestDecompression(logger, cfg.Decompression))
+		names = append(names, "request_decompression")
+	}
+
+	// OpenAPI 契约校验，放在响应缓存之前以校验命中缓存的响应
+	if middlewares.OpenAPI != nil {
+		r.Use(middleware.NewOpenAPIValidation(logger, middlewares.OpenAPI, cfg.OpenAPI))
+		names = append(names, "openapi_validation")
+	}
+
+	// 响应缓存
+	if middlewares.ResponseCache.Enabled() {
+		r.Use(middleware.NewResponseCache(logger, middlewares.ResponseCache))
+		names = append(names, "response_cache")
+	}
+
+	return names
 }
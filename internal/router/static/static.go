@@ -0,0 +1,112 @@
+package static
+
+import (
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RegisterStaticRoutes 注册静态文件路由和 SPA 回退处理
+// 需要在 API 路由之后注册，SPA 回退只作用于未匹配任何路由的请求
+func RegisterStaticRoutes(router *gin.Engine, cfg *config.Static, logger *zap.Logger) {
+	if !cfg.Enabled {
+		return
+	}
+
+	for _, mount := range cfg.Mounts {
+		prefix := "/" + strings.Trim(mount.Prefix, "/")
+		if prefix == "/" {
+			// gin 不允许根路径通配与其他路由共存，根目录应通过 SPA 回退托管
+			logger.Warn("Static mount on root path is not supported, use spa instead", zap.String("dir", mount.Dir))
+			continue
+		}
+
+		group := router.Group(prefix, cacheControl(mount.MaxAge.Seconds()))
+		group.Static("/", mount.Dir)
+
+		logger.Info("Static directory mounted",
+			zap.String("prefix", prefix),
+			zap.String("dir", mount.Dir),
+			zap.Duration("max_age", mount.MaxAge),
+		)
+	}
+
+	if cfg.SPA.Enabled {
+		// 回退未命中时交给 response.NoRoute 返回 JSON 404
+		router.NoRoute(spaFallback(cfg.SPA), response.NoRoute)
+		logger.Info("SPA fallback enabled", zap.String("root", cfg.SPA.Root))
+	}
+}
+
+// cacheControl 为静态资源设置缓存头
+func cacheControl(maxAge float64) gin.HandlerFunc {
+	value := "no-cache"
+	if maxAge > 0 {
+		value = fmt.Sprintf("public, max-age=%d", int64(maxAge))
+	}
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", value)
+		c.Next()
+	}
+}
+
+// spaFallback 未匹配路由时优先返回 root 下的同名文件，否则返回入口文件，由前端路由接管
+// 不处理的请求直接返回，由后续的 NoRoute 处理器响应
+func spaFallback(cfg config.SPA) gin.HandlerFunc {
+	fs := http.Dir(cfg.Root)
+	index := "/" + strings.TrimPrefix(cfg.Index, "/")
+	if index == "/" {
+		index = "/index.html"
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			return
+		}
+
+		requestPath := c.Request.URL.Path
+		for _, prefix := range cfg.ExcludePrefixes {
+			if strings.HasPrefix(requestPath, prefix) {
+				return
+			}
+		}
+
+		if name := path.Clean(requestPath); name != "/" && name != index && serveFile(c, fs, name) {
+			c.Abort()
+			return
+		}
+
+		// 入口文件不缓存，保证发布新版本后立即生效
+		c.Header("Cache-Control", "no-cache")
+		if serveFile(c, fs, index) {
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Cache-Control")
+	}
+}
+
+// serveFile 输出 root 下的普通文件，文件不存在或为目录时返回 false
+// 不使用 c.FileFromFS，因为 http.FileServer 会把 /index.html 重定向到目录
+func serveFile(c *gin.Context, fs http.FileSystem, name string) bool {
+	f, err := fs.Open(name)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil || info.IsDir() {
+		return false
+	}
+
+	http.ServeContent(c.Writer, c.Request, info.Name(), info.ModTime(), f)
+	return true
+}
@@ -0,0 +1,29 @@
+package system
+
+import (
+	"net/http/pprof"
+
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterMetricsRoutes 注册 Prometheus 指标路由，输出 metrics.Registry 中的所有指标
+func RegisterMetricsRoutes(router *gin.Engine) {
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+}
+
+// RegisterDebugRoutes 注册 pprof 路由，只应在内部端口暴露
+func RegisterDebugRoutes(router *gin.Engine) {
+	debug := router.Group("/debug/pprof")
+	{
+		debug.GET("/", gin.WrapF(pprof.Index))
+		debug.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+		debug.GET("/profile", gin.WrapF(pprof.Profile))
+		debug.GET("/symbol", gin.WrapF(pprof.Symbol))
+		debug.POST("/symbol", gin.WrapF(pprof.Symbol))
+		debug.GET("/trace", gin.WrapF(pprof.Trace))
+		// heap、goroutine、allocs 等命名 profile 由 pprof.Index 按路径分发
+		debug.GET("/:profile", gin.WrapF(pprof.Index))
+	}
+}
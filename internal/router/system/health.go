@@ -2,43 +2,77 @@ package system
 
 import (
 	"net/http"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/buildinfo"
+	"github.com/hedeqiang/skeleton/pkg/health"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// ReadinessChecks /ready 使用的就绪检查，各项检查并发执行且有各自的超时，任一失败时返回 503
+// 与 /api/v1/admin/health 的注册表相互独立，注册的检查都按 critical 处理
+type ReadinessChecks struct {
+	*health.Registry
+}
+
+// NewReadinessChecks 创建空的就绪检查集合
+func NewReadinessChecks() ReadinessChecks {
+	return ReadinessChecks{Registry: health.NewRegistry()}
+}
+
+// Add 注册就绪检查，timeout 为 0 时使用默认超时
+func (r ReadinessChecks) Add(name string, timeout time.Duration, check health.CheckFunc) {
+	r.Register(name, health.Critical, timeout, check)
+}
+
 // RegisterSystemRoutes 注册系统路由
-func RegisterSystemRoutes(router *gin.Engine, logger *zap.Logger) {
+func RegisterSystemRoutes(router *gin.Engine, logger *zap.Logger, checks ReadinessChecks) {
 	// 健康检查路由
-	RegisterHealthRoutes(router, logger)
+	RegisterHealthRoutes(router, logger, checks)
 
-	// 可以在这里添加其他系统路由
-	// RegisterMetricsRoutes(router, logger)
-	// RegisterDebugRoutes(router, logger)
+	// 构建信息路由
+	RegisterVersionRoutes(router)
 }
 
 // RegisterHealthRoutes 注册健康检查路由
-func RegisterHealthRoutes(router *gin.Engine, logger *zap.Logger) {
-	health := router.Group("/")
+func RegisterHealthRoutes(router *gin.Engine, logger *zap.Logger, checks ReadinessChecks) {
+	group := router.Group("/")
 	{
 		// 健康检查端点
-		health.GET("/health", func(c *gin.Context) {
+		group.GET("/health", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{
 				"status":  "healthy",
 				"service": "skeleton",
-				"version": "1.0.0",
+				"version": buildinfo.Version,
 			})
 		})
 
 		// 就绪检查端点
-		health.GET("/ready", func(c *gin.Context) {
+		group.GET("/ready", func(c *gin.Context) {
+			report := checks.Check(c.Request.Context())
+			if report.Status != health.StatusHealthy {
+				for name, result := range report.Checks {
+					if result.Status != health.CheckUp {
+						logger.Warn("Readiness check failed", zap.String("check", name), zap.String("error", result.Error))
+					}
+				}
+				c.JSON(http.StatusServiceUnavailable, gin.H{
+					"status": "not_ready",
+					"checks": report.Checks,
+				})
+				return
+			}
+
 			c.JSON(http.StatusOK, gin.H{
 				"status": "ready",
+				"checks": report.Checks,
 			})
 		})
 
 		// 存活检查端点
-		health.GET("/ping", func(c *gin.Context) {
+		group.GET("/ping", func(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{
 				"message": "pong",
 			})
@@ -47,3 +81,10 @@ func RegisterHealthRoutes(router *gin.Engine, logger *zap.Logger) {
 
 	logger.Info("Health check routes registered")
 }
+
+// RegisterVersionRoutes 注册构建信息路由
+func RegisterVersionRoutes(router *gin.Engine) {
+	router.GET("/version", func(c *gin.Context) {
+		c.JSON(http.StatusOK, buildinfo.Get())
+	})
+}
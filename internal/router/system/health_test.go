@@ -0,0 +1,91 @@
+package system
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/health"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// readyResponse /ready 的响应
+type readyResponse struct {
+	Status string                        `json:"status"`
+	Checks map[string]health.CheckResult `json:"checks"`
+}
+
+func getReady(t *testing.T, checks ReadinessChecks) (int, readyResponse) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	RegisterHealthRoutes(engine, zap.NewNop(), checks)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+
+	var resp readyResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", w.Body.String(), err)
+	}
+	return w.Code, resp
+}
+
+func TestReady(t *testing.T) {
+	checks := NewReadinessChecks()
+	checks.Add("database", time.Second, func(ctx context.Context) error { return nil })
+	checks.Add("redis", time.Second, func(ctx context.Context) error { return nil })
+
+	code, resp := getReady(t, checks)
+	if code != http.StatusOK || resp.Status != "ready" {
+		t.Fatalf("status = %d %q, want 200 ready", code, resp.Status)
+	}
+	if len(resp.Checks) != 2 || resp.Checks["database"].Status != health.CheckUp || resp.Checks["redis"].Status != health.CheckUp {
+		t.Fatalf("unexpected checks: %+v", resp.Checks)
+	}
+}
+
+func TestReadyReportsFailingDependencies(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	checks := NewReadinessChecks()
+	checks.Add("database", time.Second, func(ctx context.Context) error { return nil })
+	checks.Add("redis", time.Second, func(ctx context.Context) error { return errors.New("connection refused") })
+	// 不响应 ctx 取消的检查按各自的超时失败，不拖慢其他检查
+	checks.Add("rabbitmq", 50*time.Millisecond, func(ctx context.Context) error {
+		<-release
+		return nil
+	})
+
+	start := time.Now()
+	code, resp := getReady(t, checks)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("/ready took %s", elapsed)
+	}
+	if code != http.StatusServiceUnavailable || resp.Status != "not_ready" {
+		t.Fatalf("status = %d %q, want 503 not_ready", code, resp.Status)
+	}
+
+	if got := resp.Checks["database"]; got.Status != health.CheckUp {
+		t.Fatalf("database = %+v, want up", got)
+	}
+	if got := resp.Checks["redis"]; got.Status != health.CheckDown || got.Error != "connection refused" {
+		t.Fatalf("redis = %+v, want down with error", got)
+	}
+	if got := resp.Checks["rabbitmq"]; got.Status != health.CheckDown || !strings.Contains(got.Error, "timed out") {
+		t.Fatalf("rabbitmq = %+v, want timed out", got)
+	}
+	for name, result := range resp.Checks {
+		if result.Level != health.Critical {
+			t.Fatalf("%s level = %q, want critical", name, result.Level)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package system
+
+import (
+	"net/http"
+
+	"github.com/hedeqiang/skeleton/pkg/jwt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jwksMaxAge JWKS 的缓存时间，轮换密钥时新公钥需先发布并等待缓存过期后再切换 key_id
+const jwksMaxAge = "public, max-age=300"
+
+// RegisterJWKSRoutes 注册 /.well-known/jwks.json，供其他服务校验令牌
+// 使用 HS256 时没有可公开的密钥，不注册
+func RegisterJWKSRoutes(router *gin.Engine, tokens *jwt.JWT) {
+	jwks := tokens.JWKS()
+	if jwks == nil {
+		return
+	}
+
+	router.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.Header("Cache-Control", jwksMaxAge)
+		c.JSON(http.StatusOK, jwks)
+	})
+}
@@ -0,0 +1,54 @@
+package system
+
+import (
+	"sort"
+
+	"github.com/hedeqiang/skeleton/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteInfo 路由信息
+type RouteInfo struct {
+	Method      string   `json:"method"`
+	Path        string   `json:"path"`
+	Handler     string   `json:"handler"`
+	Middlewares []string `json:"middlewares"`        // 按执行顺序排列的全局中间件
+	Policies    []string `json:"policies,omitempty"` // 路由命中的策略，如认证、限流、缓存
+}
+
+// RouteDescriber 返回路由命中的策略描述
+type RouteDescriber func(method, path string) []string
+
+// RegisterRouteListRoutes 注册路由清单端点，用于审计对外暴露的接口
+// 路由清单在请求时生成，需在所有路由注册之后调用以便端点本身也出现在清单中
+func RegisterRouteListRoutes(router *gin.Engine, path string, middlewares []string, describe RouteDescriber) {
+	router.GET(path, func(c *gin.Context) {
+		routes := router.Routes()
+		result := make([]RouteInfo, 0, len(routes))
+		for _, route := range routes {
+			info := RouteInfo{
+				Method:      route.Method,
+				Path:        route.Path,
+				Handler:     route.Handler,
+				Middlewares: middlewares,
+			}
+			if describe != nil {
+				info.Policies = describe(route.Method, route.Path)
+			}
+			result = append(result, info)
+		}
+
+		sort.Slice(result, func(i, j int) bool {
+			if result[i].Path != result[j].Path {
+				return result[i].Path < result[j].Path
+			}
+			return result[i].Method < result[j].Method
+		})
+
+		response.Success(c, gin.H{
+			"routes":       result,
+			"routes_count": len(result),
+		})
+	})
+}
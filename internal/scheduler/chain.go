@@ -0,0 +1,375 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 任务链及其步骤的状态，成功、失败沿用任务执行结果
+const (
+	chainStatusPending = "pending"
+	chainStatusRunning = "running"
+	chainStatusSkipped = "skipped"
+)
+
+// maxChainRuns 每条任务链保留的最近执行记录数
+const maxChainRuns = 20
+
+// chainNode 任务链中的一个任务，next 为该任务成功后依次执行的下游任务
+type chainNode struct {
+	config config.SchedulerJobConfig
+	job    Job
+	index  int // 在任务链步骤中的位置
+	next   []*chainNode
+}
+
+// ChainInfo 任务链定义及最近的执行记录
+type ChainInfo struct {
+	Name string     `json:"name"`
+	Jobs []string   `json:"jobs"`
+	Runs []ChainRun `json:"runs"` // 最近的执行记录，新的在前
+}
+
+// ChainRun 任务链的一次执行
+type ChainRun struct {
+	ID         string      `json:"id"`
+	Chain      string      `json:"chain"`
+	Status     string      `json:"status"` // running, success, failure
+	StartedAt  time.Time   `json:"started_at"`
+	FinishedAt *time.Time  `json:"finished_at,omitempty"`
+	Steps      []ChainStep `json:"steps"`
+}
+
+// ChainStep 任务链中一个任务的执行状态
+type ChainStep struct {
+	Job        string     `json:"job"`
+	After      string     `json:"after,omitempty"`
+	Status     string     `json:"status"` // pending, running, success, failure, skipped
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+}
+
+// buildChains 按 after 字段组装任务链，返回以独立调度的任务为起点的任务树
+// after 指向未知任务或形成环时返回错误；上游任务被禁用时下游任务不会执行，记录警告后跳过
+func (r *JobRegistry) buildChains(jobConfigs []config.SchedulerJobConfig) ([]*chainNode, error) {
+	byName := make(map[string]config.SchedulerJobConfig, len(jobConfigs))
+	for _, jobConfig := range jobConfigs {
+		if _, exists := byName[jobConfig.Name]; exists {
+			return nil, fmt.Errorf("duplicate job name: %s", jobConfig.Name)
+		}
+		byName[jobConfig.Name] = jobConfig
+	}
+
+	followers := make(map[string][]config.SchedulerJobConfig)
+	var roots []config.SchedulerJobConfig
+	for _, jobConfig := range jobConfigs {
+		if !jobConfig.Enabled {
+			r.logger.Info("Job is disabled, skipping",
+				zap.String("job_name", jobConfig.Name))
+			continue
+		}
+		if jobConfig.After == "" {
+			roots = append(roots, jobConfig)
+			continue
+		}
+
+		if jobConfig.Type != "" || jobConfig.Schedule != "" || jobConfig.Timezone != "" {
+			return nil, fmt.Errorf("job %s runs after %s and must not set type, schedule or timezone", jobConfig.Name, jobConfig.After)
+		}
+		disabled, err := disabledUpstream(byName, jobConfig)
+		if err != nil {
+			return nil, err
+		}
+		if disabled != "" {
+			r.logger.Warn("Upstream job is disabled, skipping",
+				zap.String("job_name", jobConfig.Name),
+				zap.String("upstream", disabled),
+			)
+			continue
+		}
+		followers[jobConfig.After] = append(followers[jobConfig.After], jobConfig)
+	}
+
+	nodes := make([]*chainNode, 0, len(roots))
+	for _, jobConfig := range roots {
+		node, err := r.buildChainNode(jobConfig, followers, new(int))
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// disabledUpstream 沿 after 向上查找，返回第一个被禁用的上游任务，after 指向未知任务或形成环时返回错误
+func disabledUpstream(byName map[string]config.SchedulerJobConfig, jobConfig config.SchedulerJobConfig) (string, error) {
+	visited := map[string]bool{jobConfig.Name: true}
+	for current := jobConfig; current.After != ""; {
+		upstream, exists := byName[current.After]
+		if !exists {
+			return "", fmt.Errorf("job %s runs after unknown job %s", current.Name, current.After)
+		}
+		if visited[upstream.Name] {
+			return "", fmt.Errorf("job %s has a dependency cycle through %s", jobConfig.Name, upstream.Name)
+		}
+		if !upstream.Enabled {
+			return upstream.Name, nil
+		}
+		visited[upstream.Name] = true
+		current = upstream
+	}
+	return "", nil
+}
+
+// buildChainNode 创建任务及其下游任务，index 按深度优先顺序编号
+func (r *JobRegistry) buildChainNode(jobConfig config.SchedulerJobConfig, followers map[string][]config.SchedulerJobConfig, index *int) (*chainNode, error) {
+	job, err := r.newJob(jobConfig)
+	if err != nil {
+		return nil, err
+	}
+	node := &chainNode{config: jobConfig, job: job, index: *index}
+	*index++
+
+	for _, follower := range followers[jobConfig.Name] {
+		next, err := r.buildChainNode(follower, followers, index)
+		if err != nil {
+			return nil, err
+		}
+		node.next = append(node.next, next)
+	}
+	return node, nil
+}
+
+// chainSteps 按深度优先顺序返回任务链中的全部任务
+func chainSteps(root *chainNode) []*chainNode {
+	steps := []*chainNode{root}
+	for _, next := range root.next {
+		steps = append(steps, chainSteps(next)...)
+	}
+	return steps
+}
+
+// chainRunner 返回任务链每次执行的入口
+// 起点任务的错误和 panic 照常交给 gocron 记录指标；上游任务失败时其下游任务全部跳过
+// 链中所有任务的 ctx 都带有 chain、chain_run_id 日志字段
+func (r *JobRegistry) chainRunner(root *chainNode) func(ctx context.Context) error {
+	return func(ctx context.Context) (err error) {
+		runID := uuid.NewString()
+		ctx = logger.WithFields(ctx,
+			zap.String("chain", root.config.Name),
+			zap.String("chain_run_id", runID),
+		)
+		r.chains.start(root, runID)
+
+		r.chains.stepStarted(root.config.Name, runID, root.index)
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				r.chains.stepFinished(root.config.Name, runID, root.index, fmt.Errorf("panic: %v", recovered))
+				r.chains.skip(root.config.Name, runID, root)
+				r.chains.finish(root.config.Name, runID)
+				panic(recovered)
+			}
+		}()
+		err = r.runner(root.config.Name, root.job)(ctx)
+		r.chains.stepFinished(root.config.Name, runID, root.index, err)
+
+		if err != nil {
+			r.chains.skip(root.config.Name, runID, root)
+		} else {
+			r.runFollowers(ctx, root.config.Name, runID, root)
+		}
+		r.chains.finish(root.config.Name, runID)
+		return err
+	}
+}
+
+// runFollowers 依次执行 node 的下游任务，下游任务失败时跳过它自己的下游任务
+func (r *JobRegistry) runFollowers(ctx context.Context, chain, runID string, node *chainNode) {
+	for _, next := range node.next {
+		r.chains.stepStarted(chain, runID, next.index)
+		err := r.runStep(ctx, next)
+		r.chains.stepFinished(chain, runID, next.index, err)
+
+		if err != nil {
+			r.chains.skip(chain, runID, next)
+			continue
+		}
+		r.runFollowers(ctx, chain, runID, next)
+	}
+}
+
+// runStep 执行下游任务或 RunOnce 指定的任务
+// 这些任务不经过 gocron，这里自行记录执行指标并恢复 panic，避免影响同一条链的其他任务
+func (r *JobRegistry) runStep(ctx context.Context, node *chainNode) (err error) {
+	stepID := uuid.New()
+	r.metrics.before(stepID, node.config.Name)
+	defer func() {
+		status := jobStatusSuccess
+		if recovered := recover(); recovered != nil {
+			r.logger.Error("Scheduled job panicked",
+				zap.String("job_name", node.config.Name),
+				zap.Any("panic", recovered),
+			)
+			err = fmt.Errorf("%w: %v", gocron.ErrPanicRecovered, recovered)
+			status = jobStatusPanic
+		} else if err != nil {
+			status = jobStatusFailure
+		}
+		if err != nil {
+			r.logger.Error("Scheduled job failed",
+				zap.String("job_name", node.config.Name),
+				zap.String("status", status),
+				zap.Error(err),
+			)
+		}
+		r.metrics.after(stepID, node.config.Name, status)
+	}()
+
+	return r.runner(node.config.Name, node.job)(ctx)
+}
+
+// chainHistory 任务链定义和最近的执行记录，保存在内存中，重启后清空
+type chainHistory struct {
+	mu     sync.Mutex
+	clock  clock.Clock
+	names  []string
+	chains map[string]*chainRecord
+}
+
+type chainRecord struct {
+	root *chainNode
+	runs []*ChainRun // 新的在前
+}
+
+func newChainHistory(clk clock.Clock) *chainHistory {
+	return &chainHistory{clock: clk, chains: make(map[string]*chainRecord)}
+}
+
+// register 记录任务链定义，重复注册时保留已有的执行记录
+func (h *chainHistory) register(root *chainNode) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if record, ok := h.chains[root.config.Name]; ok {
+		record.root = root
+		return
+	}
+	h.names = append(h.names, root.config.Name)
+	h.chains[root.config.Name] = &chainRecord{root: root}
+}
+
+func (h *chainHistory) start(root *chainNode, runID string) {
+	steps := chainSteps(root)
+	run := &ChainRun{
+		ID:        runID,
+		Chain:     root.config.Name,
+		Status:    chainStatusRunning,
+		StartedAt: h.clock.Now(),
+		Steps:     make([]ChainStep, len(steps)),
+	}
+	for _, step := range steps {
+		run.Steps[step.index] = ChainStep{Job: step.config.Name, After: step.config.After, Status: chainStatusPending}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	record := h.chains[root.config.Name]
+	record.runs = append([]*ChainRun{run}, record.runs...)
+	if len(record.runs) > maxChainRuns {
+		record.runs = record.runs[:maxChainRuns]
+	}
+}
+
+func (h *chainHistory) stepStarted(chain, runID string, index int) {
+	now := h.clock.Now()
+	h.update(chain, runID, func(run *ChainRun) {
+		run.Steps[index].Status = chainStatusRunning
+		run.Steps[index].StartedAt = &now
+	})
+}
+
+func (h *chainHistory) stepFinished(chain, runID string, index int, err error) {
+	now := h.clock.Now()
+	h.update(chain, runID, func(run *ChainRun) {
+		step := &run.Steps[index]
+		step.FinishedAt = &now
+		step.Status = jobStatusSuccess
+		if err != nil {
+			step.Status = jobStatusFailure
+			step.Error = err.Error()
+		}
+	})
+}
+
+// skip 将 node 的全部下游任务标记为跳过
+func (h *chainHistory) skip(chain, runID string, node *chainNode) {
+	h.update(chain, runID, func(run *ChainRun) {
+		for _, next := range node.next {
+			for _, step := range chainSteps(next) {
+				run.Steps[step.index].Status = chainStatusSkipped
+			}
+		}
+	})
+}
+
+// finish 结束执行，任一任务失败时整条链记为失败
+func (h *chainHistory) finish(chain, runID string) {
+	now := h.clock.Now()
+	h.update(chain, runID, func(run *ChainRun) {
+		run.FinishedAt = &now
+		run.Status = jobStatusSuccess
+		for _, step := range run.Steps {
+			if step.Status == jobStatusFailure {
+				run.Status = jobStatusFailure
+				break
+			}
+		}
+	})
+}
+
+func (h *chainHistory) update(chain, runID string, fn func(run *ChainRun)) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	record, ok := h.chains[chain]
+	if !ok {
+		return
+	}
+	for _, run := range record.runs {
+		if run.ID == runID {
+			fn(run)
+			return
+		}
+	}
+}
+
+// snapshot 返回全部任务链及其执行记录的副本
+func (h *chainHistory) snapshot() []ChainInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	chains := make([]ChainInfo, 0, len(h.names))
+	for _, name := range h.names {
+		record := h.chains[name]
+		info := ChainInfo{Name: name, Runs: make([]ChainRun, 0, len(record.runs))}
+		for _, step := range chainSteps(record.root) {
+			info.Jobs = append(info.Jobs, step.config.Name)
+		}
+		for _, run := range record.runs {
+			copied := *run
+			copied.Steps = append([]ChainStep(nil), run.Steps...)
+			info.Runs = append(info.Runs, copied)
+		}
+		chains = append(chains, info)
+	}
+	return chains
+}
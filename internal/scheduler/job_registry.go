@@ -1,14 +1,19 @@
 package scheduler
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/hedeqiang/skeleton/internal/config"
 	"github.com/hedeqiang/skeleton/internal/scheduler/jobs"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/logger"
 )
 
 // JobRegistry 任务注册器，负责任务的注册、初始化和生命周期管理
@@ -17,6 +22,16 @@ type JobRegistry struct {
 	logger         *zap.Logger
 	config         config.SchedulerConfig
 	registeredJobs map[string]JobFactory
+	metrics        *jobMetrics
+	chains         *chainHistory
+	scheduled      []scheduledJob
+}
+
+// scheduledJob 已添加到调度器的任务及其时区，用于启动后记录下次运行时间
+type scheduledJob struct {
+	name     string
+	job      gocron.Job
+	location *time.Location
 }
 
 // JobFactory 任务工厂函数类型
@@ -29,13 +44,37 @@ type Job interface {
 	Description() string
 }
 
+// ErrorJob 可返回错误的任务
+// 实现该接口的任务由调度器调用 Run 代替 Execute，返回的错误计入失败指标
+type ErrorJob interface {
+	Job
+	Run() error
+}
+
+// ContextJob 接收 context 的任务，优先于 ErrorJob 和 Execute 被调用
+// ctx 携带 job_name、run_id 日志字段，任务通过 logger.FromContext 取得的 logger 自动带上
+type ContextJob interface {
+	Job
+	RunContext(ctx context.Context) error
+}
+
+// ConfigurableJob 接收配置参数的任务，添加到调度器前以任务配置中的 params 调用 Configure，返回错误时启动失败
+// 每个调度使用独立的任务实例，同一个任务可以通过 job 字段按不同参数配置多个调度
+type ConfigurableJob interface {
+	Job
+	Configure(params jobs.Params) error
+}
+
 // NewJobRegistry 创建任务注册器
-func NewJobRegistry(schedulerService *SchedulerService, logger *zap.Logger, config config.SchedulerConfig) *JobRegistry {
+func NewJobRegistry(schedulerService *SchedulerService, logger *zap.Logger, clk clock.Clock, config config.SchedulerConfig) *JobRegistry {
+	logger = logger.Named(LoggerName)
 	registry := &JobRegistry{
 		scheduler:      schedulerService,
 		logger:         logger,
 		config:         config,
 		registeredJobs: make(map[string]JobFactory),
+		metrics:        newJobMetrics(logger, clk),
+		chains:         newChainHistory(clk),
 	}
 
 	// 注册默认任务
@@ -64,59 +103,165 @@ func (r *JobRegistry) InitializeJobs() error {
 		return nil
 	}
 
-	for _, jobConfig := range r.config.Jobs {
-		if !jobConfig.Enabled {
-			r.logger.Info("Job is disabled, skipping",
-				zap.String("job_name", jobConfig.Name))
-			continue
-		}
+	roots, err := r.buildChains(r.config.Jobs)
+	if err != nil {
+		return err
+	}
 
-		if err := r.addJob(jobConfig); err != nil {
-			return fmt.Errorf("failed to add job %s: %w", jobConfig.Name, err)
+	for _, root := range roots {
+		if err := r.addJob(root); err != nil {
+			return fmt.Errorf("failed to add job %s: %w", root.config.Name, err)
 		}
 	}
 
 	return nil
 }
 
-// addJob 根据配置添加单个任务
-func (r *JobRegistry) addJob(jobConfig config.SchedulerJobConfig) error {
-	factory, exists := r.registeredJobs[jobConfig.Name]
+// newJob 创建配置对应的任务实例并传入任务参数
+func (r *JobRegistry) newJob(jobConfig config.SchedulerJobConfig) (Job, error) {
+	factory, exists := r.registeredJobs[jobConfig.JobName()]
 	if !exists {
-		return fmt.Errorf("job factory not found for: %s", jobConfig.Name)
+		return nil, fmt.Errorf("job factory not found for: %s", jobConfig.JobName())
 	}
 
 	job := factory(r.logger)
 
+	// 传入任务参数
+	if configurable, ok := job.(ConfigurableJob); ok {
+		if err := configurable.Configure(jobs.Params(jobConfig.Params)); err != nil {
+			return nil, fmt.Errorf("job %s: %w", jobConfig.Name, err)
+		}
+	} else if len(jobConfig.Params) > 0 {
+		return nil, fmt.Errorf("job %s does not accept params", jobConfig.JobName())
+	}
+
+	return job, nil
+}
+
+// addJob 将独立调度的任务添加到调度器，有下游任务时按任务链执行
+func (r *JobRegistry) addJob(root *chainNode) error {
+	jobConfig := root.config
+
+	// 解析任务时区
+	location, err := r.jobLocation(jobConfig)
+	if err != nil {
+		return err
+	}
+
 	// 创建任务定义
-	jobDefinition, err := r.createJobDefinition(jobConfig)
+	jobDefinition, err := r.createJobDefinition(jobConfig, location)
 	if err != nil {
 		return fmt.Errorf("failed to create job definition: %w", err)
 	}
 
 	// 创建任务
-	task := gocron.NewTask(job.Execute)
+	task := gocron.NewTask(r.runner(jobConfig.Name, root.job))
+	if len(root.next) > 0 {
+		task = gocron.NewTask(r.chainRunner(root))
+		r.chains.register(root)
+	}
 
 	// 添加到调度器
-	if err := r.scheduler.AddJob(jobDefinition, task,
+	for _, step := range chainSteps(root) {
+		r.metrics.register(step.config.Name)
+	}
+	cronJob, err := r.scheduler.AddJob(jobDefinition, task,
 		gocron.WithTags(jobConfig.Name, jobConfig.Type),
 		gocron.WithName(jobConfig.Name),
-	); err != nil {
+		r.metrics.listeners(),
+	)
+	if err != nil {
 		return fmt.Errorf("failed to add job to scheduler: %w", err)
 	}
+	r.scheduled = append(r.scheduled, scheduledJob{name: jobConfig.Name, job: cronJob, location: location})
 
 	r.logger.Info("Job initialized successfully",
 		zap.String("job_name", jobConfig.Name),
+		zap.String("job", jobConfig.JobName()),
 		zap.String("job_type", jobConfig.Type),
 		zap.String("schedule", jobConfig.Schedule),
+		zap.String("timezone", location.String()),
 		zap.String("description", jobConfig.Description),
 	)
+	for _, step := range chainSteps(root)[1:] {
+		r.logger.Info("Job initialized successfully",
+			zap.String("job_name", step.config.Name),
+			zap.String("job", step.config.JobName()),
+			zap.String("after", step.config.After),
+			zap.String("chain", jobConfig.Name),
+			zap.String("description", step.config.Description),
+		)
+	}
 
 	return nil
 }
 
-// createJobDefinition 根据配置创建任务定义
-func (r *JobRegistry) createJobDefinition(jobConfig config.SchedulerJobConfig) (gocron.JobDefinition, error) {
+// jobLocation 返回任务使用的时区：任务配置的 timezone、cron 表达式中的 CRON_TZ，否则为调度器时区
+func (r *JobRegistry) jobLocation(jobConfig config.SchedulerJobConfig) (*time.Location, error) {
+	name := jobConfig.Timezone
+	if jobConfig.Type == "cron" {
+		if inline, _, ok := cutCronTimezone(jobConfig.Schedule); ok {
+			if name != "" {
+				return nil, fmt.Errorf("job %s sets both timezone and a CRON_TZ prefix", jobConfig.Name)
+			}
+			name = inline
+		}
+	}
+
+	if name == "" {
+		return r.scheduler.Location(), nil
+	}
+	if jobConfig.Type == "duration" {
+		r.logger.Warn("Timezone has no effect on duration jobs",
+			zap.String("job_name", jobConfig.Name),
+			zap.String("timezone", name),
+		)
+		return r.scheduler.Location(), nil
+	}
+
+	location, err := LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("job %s: %w", jobConfig.Name, err)
+	}
+	return location, nil
+}
+
+// cutCronTimezone 拆分 cron 表达式开头的 CRON_TZ= 或 TZ= 时区前缀
+func cutCronTimezone(schedule string) (timezone, rest string, ok bool) {
+	schedule = strings.TrimSpace(schedule)
+	for _, prefix := range []string{"CRON_TZ=", "TZ="} {
+		if after, found := strings.CutPrefix(schedule, prefix); found {
+			timezone, rest, _ = strings.Cut(after, " ")
+			return timezone, strings.TrimSpace(rest), true
+		}
+	}
+	return "", schedule, false
+}
+
+// runner 返回任务每次执行的入口，为每次执行生成 run_id 并写入 ctx 的日志字段
+// 作为 gocron 任务时 ctx 由 gocron 传入，调度器停止时取消
+func (r *JobRegistry) runner(name string, job Job) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		ctx = logger.WithFields(ctx,
+			zap.String("job_name", name),
+			zap.String("run_id", uuid.NewString()),
+		)
+
+		switch j := job.(type) {
+		case ContextJob:
+			return j.RunContext(ctx)
+		case ErrorJob:
+			return j.Run()
+		default:
+			j.Execute()
+			return nil
+		}
+	}
+}
+
+// createJobDefinition 根据配置创建任务定义，location 为 jobLocation 解析的任务时区
+// gocron 的 daily 任务固定使用调度器时区，时区与调度器不同的 daily 任务转换为等价的 cron 任务
+func (r *JobRegistry) createJobDefinition(jobConfig config.SchedulerJobConfig, location *time.Location) (gocron.JobDefinition, error) {
 	switch jobConfig.Type {
 	case "duration":
 		duration, err := time.ParseDuration(jobConfig.Schedule)
@@ -126,7 +271,8 @@ func (r *JobRegistry) createJobDefinition(jobConfig config.SchedulerJobConfig) (
 		return gocron.DurationJob(duration), nil
 
 	case "cron":
-		return gocron.CronJob(jobConfig.Schedule, false), nil
+		_, schedule, _ := cutCronTimezone(jobConfig.Schedule)
+		return gocron.CronJob(withCronTimezone(schedule, location), false), nil
 
 	case "daily":
 		// 解析时间格式，例如 "14:30" 表示每天14:30
@@ -134,6 +280,9 @@ func (r *JobRegistry) createJobDefinition(jobConfig config.SchedulerJobConfig) (
 		if err != nil {
 			return nil, fmt.Errorf("invalid daily time format (should be HH:MM): %w", err)
 		}
+		if location.String() != r.scheduler.Location().String() {
+			return gocron.CronJob(withCronTimezone(fmt.Sprintf("%d %d * * *", t.Minute(), t.Hour()), location), false), nil
+		}
 		return gocron.DailyJob(1, gocron.NewAtTimes(
 			gocron.NewAtTime(uint(t.Hour()), uint(t.Minute()), 0),
 		)), nil
@@ -143,6 +292,11 @@ func (r *JobRegistry) createJobDefinition(jobConfig config.SchedulerJobConfig) (
 	}
 }
 
+// withCronTimezone 为 cron 表达式加上 CRON_TZ 前缀
+func withCronTimezone(schedule string, location *time.Location) string {
+	return "CRON_TZ=" + location.String() + " " + schedule
+}
+
 // Start 启动任务注册器
 func (r *JobRegistry) Start() error {
 	if !r.config.Enabled {
@@ -155,15 +309,66 @@ func (r *JobRegistry) Start() error {
 	}
 
 	r.scheduler.Start()
+	r.logNextRuns()
 	return nil
 }
 
+// logNextRuns 记录各任务的下次运行时间，同时输出 UTC 和任务时区的时间便于核对
+func (r *JobRegistry) logNextRuns() {
+	for _, scheduled := range r.scheduled {
+		nextRun, err := scheduled.job.NextRun()
+		if err != nil || nextRun.IsZero() {
+			continue
+		}
+		r.logger.Info("Job scheduled",
+			zap.String("job_name", scheduled.name),
+			zap.String("timezone", scheduled.location.String()),
+			zap.String("next_run_utc", nextRun.UTC().Format(time.RFC3339)),
+			zap.String("next_run_local", nextRun.In(scheduled.location).Format(time.RFC3339)),
+		)
+	}
+}
+
 // Stop 停止任务注册器
 func (r *JobRegistry) Stop() error {
 	return r.scheduler.Stop()
 }
 
+// RunOnce 不经过调度器立即执行一次任务，供命令行调试和 Kubernetes CronJob 等不常驻调度器的部署使用
+// name 为 scheduler.jobs 中配置的任务名称时沿用其 job 和 params，否则按注册的任务名称执行；params 覆盖配置中的同名参数
+// 只执行该任务本身，不执行任务链中的下游任务
+func (r *JobRegistry) RunOnce(ctx context.Context, name string, params map[string]interface{}) error {
+	jobConfig := config.SchedulerJobConfig{Name: name}
+	for _, configured := range r.config.Jobs {
+		if configured.Name == name {
+			jobConfig = configured
+			break
+		}
+	}
+
+	merged := make(map[string]interface{}, len(jobConfig.Params)+len(params))
+	for key, value := range jobConfig.Params {
+		merged[key] = value
+	}
+	for key, value := range params {
+		merged[key] = value
+	}
+	jobConfig.Params = merged
+
+	job, err := r.newJob(jobConfig)
+	if err != nil {
+		return err
+	}
+	r.metrics.register(jobConfig.Name)
+	return r.runStep(ctx, &chainNode{config: jobConfig, job: job})
+}
+
 // GetJobsStatus 获取任务状态
 func (r *JobRegistry) GetJobsStatus() []JobInfo {
 	return r.scheduler.GetJobs()
 }
+
+// GetChainsStatus 获取任务链及其最近的执行记录
+func (r *JobRegistry) GetChainsStatus() []ChainInfo {
+	return r.chains.snapshot()
+}
@@ -0,0 +1,65 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// DailyStatsJob 每天计算前一天的统计写入 daily_stats 表
+type DailyStatsJob struct {
+	logger       *zap.Logger
+	statsService service.StatsService
+	clock        clock.Clock
+}
+
+// NewDailyStatsJob 创建每日统计任务
+func NewDailyStatsJob(logger *zap.Logger, statsService service.StatsService, clk clock.Clock) *DailyStatsJob {
+	return &DailyStatsJob{
+		logger:       logger,
+		statsService: statsService,
+		clock:        clk,
+	}
+}
+
+// Execute 执行任务
+func (j *DailyStatsJob) Execute() {
+	_ = j.RunContext(context.Background())
+}
+
+// RunContext 执行任务，日志携带 ctx 中的 job_name、run_id 字段
+func (j *DailyStatsJob) RunContext(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+	log := logger.FromContext(ctx, j.logger)
+
+	stats, err := j.statsService.ComputeDaily(ctx, j.clock.Now().AddDate(0, 0, -1))
+	if err != nil {
+		log.Error("Failed to compute daily stats", zap.Error(err))
+		return err
+	}
+
+	log.Info("Daily stats computed",
+		zap.String("date", stats.Date),
+		zap.Int64("new_users", stats.NewUsers),
+		zap.Int64("active_users", stats.ActiveUsers),
+		zap.Int64("logins", stats.Logins),
+		zap.Int64("messages_processed", stats.MessagesProcessed),
+	)
+	return nil
+}
+
+// Name 任务名称
+func (j *DailyStatsJob) Name() string {
+	return "daily_stats_job"
+}
+
+// Description 任务描述
+func (j *DailyStatsJob) Description() string {
+	return "Compute yesterday's aggregates into the daily_stats table"
+}
@@ -1,8 +1,11 @@
 package jobs
 
 import (
+	"context"
 	"time"
 
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
 	"go.uber.org/zap"
 )
 
@@ -20,10 +23,16 @@ func NewHelloJob(logger *zap.Logger) *HelloJob {
 
 // Execute 执行任务
 func (j *HelloJob) Execute() {
-	j.logger.Info("Hello scheduled job executed",
+	_ = j.RunContext(context.Background())
+}
+
+// RunContext 执行任务，日志携带 ctx 中的 job_name、run_id 字段
+func (j *HelloJob) RunContext(ctx context.Context) error {
+	logger.FromContext(ctx, j.logger).Info("Hello scheduled job executed",
 		zap.Time("executed_at", time.Now()),
 		zap.String("job_type", "hello"),
 	)
+	return nil
 }
 
 // Name 任务名称
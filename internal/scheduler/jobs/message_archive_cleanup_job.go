@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/messaging/archive"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// MessageArchiveCleanupJob 定期删除超过保留时长的归档消息
+type MessageArchiveCleanupJob struct {
+	logger    *zap.Logger
+	sink      archive.Sink
+	clock     clock.Clock
+	retention time.Duration
+}
+
+// NewMessageArchiveCleanupJob 创建归档消息清理任务，retention 为 0 时不删除
+func NewMessageArchiveCleanupJob(logger *zap.Logger, sink archive.Sink, clk clock.Clock, retention time.Duration) *MessageArchiveCleanupJob {
+	return &MessageArchiveCleanupJob{
+		logger:    logger,
+		sink:      sink,
+		clock:     clk,
+		retention: retention,
+	}
+}
+
+// messageArchiveCleanupParams 归档清理任务参数
+type messageArchiveCleanupParams struct {
+	OlderThan time.Duration `mapstructure:"older_than"` // 覆盖 message_archive.retention
+}
+
+// Configure 解析任务参数，older_than 覆盖全局保留时长，便于按不同保留时长配置多个清理调度
+func (j *MessageArchiveCleanupJob) Configure(params Params) error {
+	var p messageArchiveCleanupParams
+	if err := params.Decode(&p); err != nil {
+		return err
+	}
+	if p.OlderThan < 0 {
+		return fmt.Errorf("older_than must not be negative: %s", p.OlderThan)
+	}
+	if p.OlderThan > 0 {
+		j.retention = p.OlderThan
+	}
+	return nil
+}
+
+// Execute 执行任务
+func (j *MessageArchiveCleanupJob) Execute() {
+	_ = j.RunContext(context.Background())
+}
+
+// RunContext 执行任务，日志携带 ctx 中的 job_name、run_id 字段
+func (j *MessageArchiveCleanupJob) RunContext(ctx context.Context) error {
+	log := logger.FromContext(ctx, j.logger)
+	if j.retention <= 0 {
+		log.Info("Message archive retention not set, skipping cleanup")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+
+	before := j.clock.Now().Add(-j.retention)
+	deleted, err := j.sink.Purge(ctx, before)
+	if err != nil {
+		log.Error("Failed to purge archived messages", zap.Error(err))
+		return err
+	}
+
+	log.Info("Archived messages purged", zap.Int64("deleted", deleted), zap.Time("before", before))
+	return nil
+}
+
+// Name 任务名称
+func (j *MessageArchiveCleanupJob) Name() string {
+	return "message_archive_cleanup_job"
+}
+
+// Description 任务描述
+func (j *MessageArchiveCleanupJob) Description() string {
+	return "Delete archived messages past their retention"
+}
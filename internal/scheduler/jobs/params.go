@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"fmt"
+
+	"github.com/go-viper/mapstructure/v2"
+)
+
+// Params 任务配置中的 params，同一个任务实现可以按不同参数配置多个调度
+// 配置文件中的键会被转换为小写，参数名统一使用 snake_case
+type Params map[string]interface{}
+
+// Decode 将参数解码到 out 指向的结构体，字段使用 mapstructure 标签
+// 时长参数支持 "720h" 形式的字符串；出现结构体中没有的参数时返回错误，避免拼写错误被静默忽略
+func (p Params) Decode(out interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       mapstructure.StringToTimeDurationHookFunc(),
+		ErrorUnused:      true,
+		WeaklyTypedInput: true,
+		Result:           out,
+	})
+	if err != nil {
+		return err
+	}
+	if err := decoder.Decode(map[string]interface{}(p)); err != nil {
+		return fmt.Errorf("invalid job params: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// defaultRetentionDataSource 策略未指定数据源时使用的数据源
+const defaultRetentionDataSource = "primary"
+
+// RetentionJob 按数据保留策略分批删除或归档过期数据
+type RetentionJob struct {
+	logger      *zap.Logger
+	dataSources map[string]*gorm.DB
+	clock       clock.Clock
+	cfg         config.Retention
+	policy      string
+}
+
+// NewRetentionJob 创建数据保留任务
+func NewRetentionJob(logger *zap.Logger, dataSources map[string]*gorm.DB, clk clock.Clock, cfg config.Retention) *RetentionJob {
+	return &RetentionJob{
+		logger:      logger,
+		dataSources: dataSources,
+		clock:       clk,
+		cfg:         cfg,
+	}
+}
+
+// retentionParams 数据保留任务参数
+type retentionParams struct {
+	DryRun *bool  `mapstructure:"dry_run"` // 覆盖 retention.dry_run
+	Policy string `mapstructure:"policy"`  // 只执行指定的策略
+}
+
+// Configure 解析任务参数并校验启用的策略，配置错误时启动失败
+func (j *RetentionJob) Configure(params Params) error {
+	var p retentionParams
+	if err := params.Decode(&p); err != nil {
+		return err
+	}
+	if p.DryRun != nil {
+		j.cfg.DryRun = *p.DryRun
+	}
+	if p.Policy != "" {
+		if _, ok := j.cfg.Policies[p.Policy]; !ok {
+			return fmt.Errorf("retention policy not found: %s", p.Policy)
+		}
+		j.policy = p.Policy
+	}
+
+	for _, name := range j.policyNames() {
+		policy := j.cfg.Policies[name]
+		if err := database.ValidateRetentionPolicy(policy); err != nil {
+			return fmt.Errorf("retention policy %s: %w", name, err)
+		}
+		if _, ok := j.dataSources[dataSourceOf(policy)]; !ok {
+			return fmt.Errorf("retention policy %s: data source not found: %s", name, dataSourceOf(policy))
+		}
+	}
+	return nil
+}
+
+// Execute 执行任务
+func (j *RetentionJob) Execute() {
+	_ = j.RunContext(context.Background())
+}
+
+// RunContext 执行任务，日志携带 ctx 中的 job_name、run_id 字段
+// 按策略名称顺序执行，某个策略失败不影响其他策略，错误合并返回
+func (j *RetentionJob) RunContext(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, time.Hour)
+	defer cancel()
+	log := logger.FromContext(ctx, j.logger)
+
+	now := j.clock.Now()
+	var failed []error
+	for _, name := range j.policyNames() {
+		policy := j.cfg.Policies[name]
+		before := now.Add(-policy.After)
+		purger := database.NewPurger(j.dataSources[dataSourceOf(policy)], j.cfg.BatchSize)
+		fields := []zap.Field{zap.String("policy", name), zap.String("table", policy.Table), zap.Time("before", before)}
+
+		if j.cfg.DryRun {
+			count, err := purger.Count(ctx, policy, before)
+			if err != nil {
+				log.Error("Failed to count expired rows", append(fields, zap.Error(err))...)
+				failed = append(failed, fmt.Errorf("%s: %w", name, err))
+				continue
+			}
+			log.Info("Retention dry run", append(fields, zap.Int64("expired", count))...)
+			continue
+		}
+
+		purged, err := purger.Purge(ctx, name, policy, before)
+		if err != nil {
+			log.Error("Failed to apply retention policy", append(fields, zap.Int64("purged", purged), zap.Error(err))...)
+			failed = append(failed, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		log.Info("Retention policy applied", append(fields, zap.Int64("purged", purged))...)
+	}
+	return errors.Join(failed...)
+}
+
+// policyNames 返回需要执行的策略名称，按名称排序
+func (j *RetentionJob) policyNames() []string {
+	if j.policy != "" {
+		return []string{j.policy}
+	}
+	names := make([]string, 0, len(j.cfg.Policies))
+	for name, policy := range j.cfg.Policies {
+		if !policy.Disabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// dataSourceOf 返回策略使用的数据源名称
+func dataSourceOf(policy config.RetentionPolicy) string {
+	if policy.DataSource == "" {
+		return defaultRetentionDataSource
+	}
+	return policy.DataSource
+}
+
+// Name 任务名称
+func (j *RetentionJob) Name() string {
+	return "retention_job"
+}
+
+// Description 任务描述
+func (j *RetentionJob) Description() string {
+	return "Delete or archive rows past their retention window"
+}
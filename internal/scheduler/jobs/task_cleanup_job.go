@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// TaskCleanupJob 定期删除过期的后台任务记录
+type TaskCleanupJob struct {
+	logger      *zap.Logger
+	taskService service.TaskService
+}
+
+// NewTaskCleanupJob 创建后台任务清理任务
+func NewTaskCleanupJob(logger *zap.Logger, taskService service.TaskService) *TaskCleanupJob {
+	return &TaskCleanupJob{
+		logger:      logger,
+		taskService: taskService,
+	}
+}
+
+// Execute 执行任务
+func (j *TaskCleanupJob) Execute() {
+	_ = j.RunContext(context.Background())
+}
+
+// RunContext 执行任务，日志携带 ctx 中的 job_name、run_id 字段
+func (j *TaskCleanupJob) RunContext(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	log := logger.FromContext(ctx, j.logger)
+
+	deleted, err := j.taskService.CleanupExpired(ctx)
+	if err != nil {
+		log.Error("Failed to cleanup expired tasks", zap.Error(err))
+		return err
+	}
+
+	log.Info("Expired tasks cleaned up", zap.Int64("deleted", deleted))
+	return nil
+}
+
+// Name 任务名称
+func (j *TaskCleanupJob) Name() string {
+	return "task_cleanup_job"
+}
+
+// Description 任务描述
+func (j *TaskCleanupJob) Description() string {
+	return "Delete background task records past their TTL"
+}
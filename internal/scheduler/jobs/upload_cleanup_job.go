@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// UploadCleanupJob 定期清理被放弃的分片上传
+type UploadCleanupJob struct {
+	logger        *zap.Logger
+	uploadService service.UploadService
+}
+
+// NewUploadCleanupJob 创建分片上传清理任务
+func NewUploadCleanupJob(logger *zap.Logger, uploadService service.UploadService) *UploadCleanupJob {
+	return &UploadCleanupJob{
+		logger:        logger,
+		uploadService: uploadService,
+	}
+}
+
+// Execute 执行任务
+func (j *UploadCleanupJob) Execute() {
+	_ = j.RunContext(context.Background())
+}
+
+// RunContext 执行任务，日志携带 ctx 中的 job_name、run_id 字段
+func (j *UploadCleanupJob) RunContext(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	log := logger.FromContext(ctx, j.logger)
+
+	cleaned, err := j.uploadService.CleanupExpired(ctx)
+	if err != nil {
+		log.Error("Failed to cleanup expired uploads", zap.Int("cleaned", cleaned), zap.Error(err))
+		return err
+	}
+
+	log.Info("Expired uploads cleaned up", zap.Int("cleaned", cleaned))
+	return nil
+}
+
+// Name 任务名称
+func (j *UploadCleanupJob) Name() string {
+	return "upload_cleanup_job"
+}
+
+// Description 任务描述
+func (j *UploadCleanupJob) Description() string {
+	return "Delete chunks of abandoned resumable uploads"
+}
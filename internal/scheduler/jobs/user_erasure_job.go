@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// UserErasureJob 彻底删除宽限期已过的账户，并清理过期的用户数据导出文件
+type UserErasureJob struct {
+	logger         *zap.Logger
+	privacyService service.PrivacyService
+}
+
+// NewUserErasureJob 创建账户彻底删除任务
+func NewUserErasureJob(logger *zap.Logger, privacyService service.PrivacyService) *UserErasureJob {
+	return &UserErasureJob{
+		logger:         logger,
+		privacyService: privacyService,
+	}
+}
+
+// Execute 执行任务
+func (j *UserErasureJob) Execute() {
+	_ = j.RunContext(context.Background())
+}
+
+// RunContext 执行任务，日志携带 ctx 中的 job_name、run_id 字段
+// 删除账户失败不影响清理导出文件，两者的错误合并返回
+func (j *UserErasureJob) RunContext(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Minute)
+	defer cancel()
+	log := logger.FromContext(ctx, j.logger)
+
+	erased, eraseErr := j.privacyService.EraseDue(ctx)
+	if eraseErr != nil {
+		log.Error("Failed to erase users", zap.Int("erased", erased), zap.Error(eraseErr))
+	}
+
+	purged, purgeErr := j.privacyService.PurgeExpiredExports(ctx)
+	if purgeErr != nil {
+		log.Error("Failed to purge expired exports", zap.Int("purged", purged), zap.Error(purgeErr))
+	}
+
+	log.Info("User erasure completed", zap.Int("erased", erased), zap.Int("purged_exports", purged))
+	return errors.Join(eraseErr, purgeErr)
+}
+
+// Name 任务名称
+func (j *UserErasureJob) Name() string {
+	return "user_erasure_job"
+}
+
+// Description 任务描述
+func (j *UserErasureJob) Description() string {
+	return "Erase accounts past their deletion grace period and purge expired data exports"
+}
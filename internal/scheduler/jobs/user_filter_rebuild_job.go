@@ -0,0 +1,63 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// UserFilterRebuildJob 定期重建用户存在性过滤器，控制误判率
+type UserFilterRebuildJob struct {
+	logger *zap.Logger
+	filter *repository.UserExistenceFilter
+}
+
+// NewUserFilterRebuildJob 创建用户存在性过滤器重建任务
+func NewUserFilterRebuildJob(logger *zap.Logger, filter *repository.UserExistenceFilter) *UserFilterRebuildJob {
+	return &UserFilterRebuildJob{
+		logger: logger,
+		filter: filter,
+	}
+}
+
+// Execute 执行任务
+func (j *UserFilterRebuildJob) Execute() {
+	_ = j.RunContext(context.Background())
+}
+
+// RunContext 执行任务，日志携带 ctx 中的 job_name、run_id 字段
+func (j *UserFilterRebuildJob) RunContext(ctx context.Context) error {
+	log := logger.FromContext(ctx, j.logger)
+	if j.filter == nil {
+		log.Debug("User existence filter disabled, skipping rebuild")
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	if err := j.filter.Rebuild(ctx); err != nil {
+		log.Error("Failed to rebuild user existence filter", zap.Error(err))
+		return err
+	}
+
+	log.Info("User existence filter rebuilt",
+		zap.Duration("duration", time.Since(start)),
+	)
+	return nil
+}
+
+// Name 任务名称
+func (j *UserFilterRebuildJob) Name() string {
+	return "user_filter_rebuild_job"
+}
+
+// Description 任务描述
+func (j *UserFilterRebuildJob) Description() string {
+	return "Rebuild username/email bloom filters from database"
+}
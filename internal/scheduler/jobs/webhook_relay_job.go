@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// WebhookRelayJob 定期投递待发送和失败的出站 Webhook
+type WebhookRelayJob struct {
+	logger         *zap.Logger
+	webhookService service.WebhookService
+}
+
+// NewWebhookRelayJob 创建出站 Webhook 重试任务
+func NewWebhookRelayJob(logger *zap.Logger, webhookService service.WebhookService) *WebhookRelayJob {
+	return &WebhookRelayJob{
+		logger:         logger,
+		webhookService: webhookService,
+	}
+}
+
+// Execute 执行任务
+func (j *WebhookRelayJob) Execute() {
+	_ = j.RunContext(context.Background())
+}
+
+// RunContext 执行任务，日志携带 ctx 中的 job_name、run_id 字段
+func (j *WebhookRelayJob) RunContext(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	log := logger.FromContext(ctx, j.logger)
+
+	result, err := j.webhookService.RelayDue(ctx)
+	if err != nil {
+		log.Error("Failed to relay webhook deliveries", zap.Error(err))
+		return err
+	}
+
+	log.Info("Webhook deliveries relayed",
+		zap.Int("delivered", result.Delivered),
+		zap.Int("failed", result.Failed),
+		zap.Int("dead", result.Dead),
+	)
+	return nil
+}
+
+// Name 任务名称
+func (j *WebhookRelayJob) Name() string {
+	return "webhook_relay_job"
+}
+
+// Description 任务描述
+func (j *WebhookRelayJob) Description() string {
+	return "Retry pending and failed outgoing webhook deliveries"
+}
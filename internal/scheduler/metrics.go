@@ -0,0 +1,123 @@
+package scheduler
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// 任务执行结果
+const (
+	jobStatusSuccess = "success"
+	jobStatusFailure = "failure"
+	jobStatusPanic   = "panic"
+)
+
+var (
+	jobRunsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "scheduler",
+		Name:      "job_runs_total",
+		Help:      "Total number of scheduled job runs by result.",
+	}, []string{"job", "status"})
+
+	jobDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "scheduler",
+		Name:      "job_duration_seconds",
+		Help:      "Duration of scheduled job runs.",
+		Buckets:   []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 600},
+	}, []string{"job"})
+
+	jobLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "scheduler",
+		Name:      "job_last_success_timestamp_seconds",
+		Help:      "Unix timestamp of the last successful run of each job.",
+	}, []string{"job"})
+
+	jobRunning = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "scheduler",
+		Name:      "job_running",
+		Help:      "Number of currently running instances of each job.",
+	}, []string{"job"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(jobRunsTotal, jobDuration, jobLastSuccess, jobRunning)
+}
+
+// jobMetrics 通过 gocron 事件监听器记录任务执行指标
+type jobMetrics struct {
+	logger *zap.Logger
+	clock  clock.Clock
+	starts sync.Map // jobID -> time.Time
+}
+
+// newJobMetrics 创建任务指标记录器
+func newJobMetrics(logger *zap.Logger, clk clock.Clock) *jobMetrics {
+	return &jobMetrics{logger: logger, clock: clk}
+}
+
+// register 初始化任务的指标序列，保证任务从未运行时也能在告警规则中被发现
+func (m *jobMetrics) register(jobName string) {
+	for _, status := range []string{jobStatusSuccess, jobStatusFailure, jobStatusPanic} {
+		jobRunsTotal.WithLabelValues(jobName, status)
+	}
+	jobRunning.WithLabelValues(jobName)
+}
+
+// listeners 返回需要挂载到任务上的事件监听器
+func (m *jobMetrics) listeners() gocron.JobOption {
+	return gocron.WithEventListeners(
+		gocron.BeforeJobRuns(m.before),
+		gocron.AfterJobRuns(func(jobID uuid.UUID, jobName string) {
+			m.after(jobID, jobName, jobStatusSuccess)
+		}),
+		gocron.AfterJobRunsWithError(func(jobID uuid.UUID, jobName string, err error) {
+			status := jobStatusFailure
+			if errors.Is(err, gocron.ErrPanicRecovered) {
+				status = jobStatusPanic
+			}
+			m.logger.Error("Scheduled job failed",
+				zap.String("job_name", jobName),
+				zap.String("status", status),
+				zap.Error(err),
+			)
+			m.after(jobID, jobName, status)
+		}),
+		gocron.AfterJobRunsWithPanic(func(jobID uuid.UUID, jobName string, recoverData any) {
+			// 之后还会触发 AfterJobRunsWithError，这里只记录 panic 内容
+			m.logger.Error("Scheduled job panicked",
+				zap.String("job_name", jobName),
+				zap.Any("panic", recoverData),
+			)
+		}),
+	)
+}
+
+func (m *jobMetrics) before(jobID uuid.UUID, jobName string) {
+	m.starts.Store(jobID, m.clock.Now())
+	jobRunning.WithLabelValues(jobName).Inc()
+}
+
+func (m *jobMetrics) after(jobID uuid.UUID, jobName string, status string) {
+	jobRunning.WithLabelValues(jobName).Dec()
+	jobRunsTotal.WithLabelValues(jobName, status).Inc()
+
+	if start, ok := m.starts.LoadAndDelete(jobID); ok {
+		jobDuration.WithLabelValues(jobName).Observe(m.clock.Since(start.(time.Time)).Seconds())
+	}
+	if status == jobStatusSuccess {
+		jobLastSuccess.WithLabelValues(jobName).Set(float64(m.clock.Now().Unix()))
+	}
+}
@@ -12,13 +12,25 @@ import (
 type SchedulerService struct {
 	scheduler gocron.Scheduler
 	logger    *zap.Logger
+	location  *time.Location
 	jobs      []gocron.Job
 }
 
+// LoggerName 调度器和任务的 logger 名称，通过 logger.levels.scheduler 单独调整级别
+const LoggerName = "scheduler"
+
 // NewSchedulerService 创建调度器服务实例
-func NewSchedulerService(logger *zap.Logger) (*SchedulerService, error) {
+// timezone 为 cron、daily 任务使用的 IANA 时区，为空时使用服务器本地时区，无法识别时返回错误
+func NewSchedulerService(logger *zap.Logger, timezone string) (*SchedulerService, error) {
+	location, err := LoadLocation(timezone)
+	if err != nil {
+		return nil, err
+	}
+	logger = logger.Named(LoggerName)
+
 	scheduler, err := gocron.NewScheduler(
 		gocron.WithLogger(NewCronLogger(logger)),
+		gocron.WithLocation(location),
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create scheduler: %w", err)
@@ -27,38 +39,51 @@ func NewSchedulerService(logger *zap.Logger) (*SchedulerService, error) {
 	return &SchedulerService{
 		scheduler: scheduler,
 		logger:    logger,
+		location:  location,
 		jobs:      make([]gocron.Job, 0),
 	}, nil
 }
 
-// AddJob 添加任务
-func (s *SchedulerService) AddJob(jobDefinition gocron.JobDefinition, task gocron.Task, options ...gocron.JobOption) error {
-	job, err := s.scheduler.NewJob(jobDefinition, task, options...)
+// LoadLocation 解析 IANA 时区名，为空时返回服务器本地时区
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(name)
 	if err != nil {
-		return fmt.Errorf("failed to create job: %w", err)
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
 	}
+	return location, nil
+}
 
-	s.jobs = append(s.jobs, job)
+// Location 返回调度器的时区
+func (s *SchedulerService) Location() *time.Location {
+	return s.location
+}
 
-	// 获取下次运行时间
-	nextRun, err := job.NextRun()
-	nextRunStr := "unknown"
-	if err == nil {
-		nextRunStr = nextRun.Format(time.RFC3339)
+// AddJob 添加任务，下次运行时间在调度器启动后才确定
+func (s *SchedulerService) AddJob(jobDefinition gocron.JobDefinition, task gocron.Task, options ...gocron.JobOption) (gocron.Job, error) {
+	job, err := s.scheduler.NewJob(jobDefinition, task, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create job: %w", err)
 	}
 
+	s.jobs = append(s.jobs, job)
+
 	s.logger.Info("Job added successfully",
 		zap.String("job_id", job.ID().String()),
-		zap.String("next_run", nextRunStr),
 	)
 
-	return nil
+	return job, nil
 }
 
 // Start 启动调度器
 func (s *SchedulerService) Start() {
 	s.scheduler.Start()
-	s.logger.Info("Scheduler started", zap.Int("jobs_count", len(s.jobs)))
+	s.logger.Info("Scheduler started",
+		zap.Int("jobs_count", len(s.jobs)),
+		zap.String("timezone", s.location.String()),
+	)
 }
 
 // Stop 停止调度器
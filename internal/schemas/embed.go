@@ -0,0 +1,9 @@
+package schemas
+
+import "embed"
+
+// FS 内置的消息载荷 JSON Schema，路径为 messages/<message_type>/v<version>.json
+// 配置 message_schemas.dir 后可从磁盘加载同样结构的目录（不含 messages 前缀）覆盖内置 schema
+//
+//go:embed messages
+var FS embed.FS
@@ -0,0 +1,20 @@
+package schemas
+
+import (
+	"io/fs"
+	"os"
+
+	"github.com/hedeqiang/skeleton/pkg/mq"
+)
+
+// Load 加载消息载荷 schema，dir 为空时使用内置 schema
+func Load(dir string) (*mq.SchemaRegistry, error) {
+	if dir != "" {
+		return mq.LoadSchemas(os.DirFS(dir))
+	}
+	messages, err := fs.Sub(FS, "messages")
+	if err != nil {
+		return nil, err
+	}
+	return mq.LoadSchemas(messages)
+}
@@ -0,0 +1,30 @@
+package schemas
+
+import (
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+)
+
+func TestLoadBuiltin(t *testing.T) {
+	registry, err := Load("")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if err := registry.Validate("hello", "1", []byte(`{"content":"hi","sender":"dev","timestamp":1700000000}`)); err != nil {
+		t.Errorf("hello payload error = %v", err)
+	}
+	if err := registry.Validate("hello", "1", []byte(`{"content":"hi"}`)); err == nil {
+		t.Error("hello payload without sender should not match")
+	}
+
+	for _, eventType := range []string{model.EventUserCreated, model.EventUserUpdated, model.EventUserDeleted, model.EventUserErased} {
+		if err := registry.Validate(eventType, "1", []byte(`{"user_id":7}`)); err != nil {
+			t.Errorf("%s payload error = %v", eventType, err)
+		}
+		if err := registry.Validate(eventType, "1", []byte(`{"user_id":"7"}`)); err == nil {
+			t.Errorf("%s payload with string user_id should not match", eventType)
+		}
+	}
+}
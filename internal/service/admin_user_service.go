@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/i18n"
+	"github.com/hedeqiang/skeleton/pkg/jwt"
+
+	"go.uber.org/zap"
+)
+
+// 强制重置密码邮件模板
+const passwordResetPage = "emails/password_reset"
+
+const defaultPasswordResetTTL = 24 * time.Hour
+
+// AdminUserService 管理员用户管理服务接口
+// 与用户自助接口分开，调用方由 route_policies 的 /api/v1/admin/* 规则限定为管理员；操作人取自 context 中的认证用户，
+// 每次操作都写入目标用户的审计日志
+type AdminUserService interface {
+	// ForcePasswordReset 吊销用户的全部令牌并要求重置密码，向用户邮箱发送重置令牌，重置前不能登录
+	ForcePasswordReset(ctx context.Context, id uint, req *model.AdminPasswordResetRequest) (*model.UserResponse, error)
+	// DisableUser 禁用账户并吊销全部令牌
+	DisableUser(ctx context.Context, id uint, req *model.DisableUserRequest) (*model.UserResponse, error)
+	// EnableUser 启用账户，之前吊销的令牌不会恢复
+	EnableUser(ctx context.Context, id uint, req *model.EnableUserRequest) (*model.UserResponse, error)
+	// AssignRoles 覆盖用户的角色并吊销全部令牌，重新登录后签发的令牌携带新角色
+	AssignRoles(ctx context.Context, id uint, req *model.AssignRolesRequest) (*model.UserResponse, error)
+	// GetSessions 查询用户的登录会话状态
+	GetSessions(ctx context.Context, id uint) (*model.UserSessions, error)
+	// ListAuditLogs 按时间倒序分页查询用户的审计日志
+	ListAuditLogs(ctx context.Context, id uint, page, pageSize int) ([]*model.UserAuditLog, int64, error)
+}
+
+// adminUserService 管理员用户管理服务实现
+type adminUserService struct {
+	userRepo    repository.UserRepository
+	auditRepo   repository.UserAuditLogRepository
+	tx          *database.TxManager
+	revocations *jwt.Revocations
+	events      *UserEvents
+	mail        MailService
+	clock       clock.Clock
+	logger      *zap.Logger
+
+	resetTTL time.Duration
+	resetURL string
+}
+
+// NewAdminUserService 创建管理员用户管理服务实例
+func NewAdminUserService(
+	userRepo repository.UserRepository,
+	auditRepo repository.UserAuditLogRepository,
+	tx *database.TxManager,
+	revocations *jwt.Revocations,
+	events *UserEvents,
+	mail MailService,
+	clk clock.Clock,
+	cfg *config.Config,
+	logger *zap.Logger,
+) AdminUserService {
+	s := &adminUserService{
+		userRepo:    userRepo,
+		auditRepo:   auditRepo,
+		tx:          tx,
+		revocations: revocations,
+		events:      events,
+		mail:        mail,
+		clock:       clk,
+		logger:      logger,
+		resetTTL:    cfg.PasswordReset.TokenTTL,
+		resetURL:    cfg.PasswordReset.ResetURL,
+	}
+	if s.resetTTL <= 0 {
+		s.resetTTL = defaultPasswordResetTTL
+	}
+	return s
+}
+
+// ForcePasswordReset 强制重置密码
+// 先吊销令牌再保存重置要求，吊销失败时不修改用户，管理员可以直接重试；重复发起时生成新令牌，之前的令牌失效
+func (s *adminUserService) ForcePasswordReset(ctx context.Context, id uint, req *model.AdminPasswordResetRequest) (*model.UserResponse, error) {
+	user, err := s.getTarget(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newConfirmationToken()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to generate password reset token")
+	}
+	now := s.clock.Now()
+	if err := s.revoke(ctx, user, now); err != nil {
+		return nil, err
+	}
+
+	expiresAt := now.Add(s.resetTTL)
+	user.PasswordResetRequired = true
+	user.PasswordResetTokenHash = hashConfirmationToken(token)
+	user.PasswordResetExpiresAt = &expiresAt
+	if err := s.save(ctx, user, model.UserAuditPasswordResetForced, req.Reason, ""); err != nil {
+		return nil, err
+	}
+
+	// 重置要求已生效，邮件发送失败时管理员可以重新发起
+	if err := s.mail.SendTemplate(ctx, []string{user.Email}, s.translate(ctx, "Reset your password"), passwordResetPage, map[string]interface{}{
+		"Username":  user.Username,
+		"Token":     token,
+		"ResetURL":  s.passwordResetURL(user.ID, token),
+		"ExpiresAt": i18n.InLocation(ctx, expiresAt),
+	}); err != nil {
+		return nil, err
+	}
+	return toUserResponse(ctx, user), nil
+}
+
+// DisableUser 禁用账户，已禁用的账户更新禁用原因
+func (s *adminUserService) DisableUser(ctx context.Context, id uint, req *model.DisableUserRequest) (*model.UserResponse, error) {
+	user, err := s.getTarget(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.revoke(ctx, user, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	user.Status = 0
+	user.DisabledReason = req.Reason
+	if err := s.save(ctx, user, model.UserAuditDisabled, req.Reason, ""); err != nil {
+		return nil, err
+	}
+	return toUserResponse(ctx, user), nil
+}
+
+// EnableUser 启用账户
+func (s *adminUserService) EnableUser(ctx context.Context, id uint, req *model.EnableUserRequest) (*model.UserResponse, error) {
+	user, err := s.getTarget(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	user.Status = 1
+	user.DisabledReason = ""
+	if err := s.save(ctx, user, model.UserAuditEnabled, req.Reason, ""); err != nil {
+		return nil, err
+	}
+	return toUserResponse(ctx, user), nil
+}
+
+// AssignRoles 分配角色，角色去重并排序后保存
+// 令牌中的角色在签发时确定，吊销令牌后移除的角色立即失效
+func (s *adminUserService) AssignRoles(ctx context.Context, id uint, req *model.AssignRolesRequest) (*model.UserResponse, error) {
+	user, err := s.getTarget(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make([]string, 0, len(req.Roles))
+	for _, role := range req.Roles {
+		if role = strings.TrimSpace(role); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	slices.Sort(roles)
+	roles = slices.Compact(roles)
+
+	if err := s.revoke(ctx, user, s.clock.Now()); err != nil {
+		return nil, err
+	}
+	user.Roles = roles
+	if err := s.save(ctx, user, model.UserAuditRolesAssigned, req.Reason, strings.Join(roles, ",")); err != nil {
+		return nil, err
+	}
+	return toUserResponse(ctx, user), nil
+}
+
+// GetSessions 查询会话状态
+// 令牌不在服务端保存，无法列出单个会话；吊销时间之前签发的令牌均已失效
+func (s *adminUserService) GetSessions(ctx context.Context, id uint) (*model.UserSessions, error) {
+	user, err := getUser(ctx, s.userRepo, id)
+	if err != nil {
+		return nil, err
+	}
+
+	locale, _ := i18n.LocaleFromContext(ctx)
+	in := func(t *time.Time) *time.Time {
+		if t == nil {
+			return nil
+		}
+		at := locale.In(*t)
+		return &at
+	}
+
+	sessions := &model.UserSessions{
+		UserID:                user.ID,
+		Status:                user.Status,
+		LastLoginAt:           in(user.LastLoginAt),
+		TokensRevokedAt:       in(user.TokensRevokedAt),
+		PasswordResetRequired: user.PasswordResetRequired,
+		PasswordResetExpires:  in(user.PasswordResetExpiresAt),
+	}
+	return sessions, nil
+}
+
+// ListAuditLogs 分页查询审计日志，已删除的用户同样可以查询
+func (s *adminUserService) ListAuditLogs(ctx context.Context, id uint, page, pageSize int) ([]*model.UserAuditLog, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	logs, total, err := s.auditRepo.ListByUser(ctx, id, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to list user audit logs")
+	}
+
+	locale, _ := i18n.LocaleFromContext(ctx)
+	for _, log := range logs {
+		log.CreatedAt = locale.In(log.CreatedAt)
+	}
+	return logs, total, nil
+}
+
+// getTarget 获取被操作的用户，管理员不能对自己执行操作，避免误操作锁死自己的账户
+func (s *adminUserService) getTarget(ctx context.Context, id uint) (*model.User, error) {
+	if actor, ok := database.ActorFromContext(ctx); ok && actor == id {
+		return nil, errors.ErrAdminSelfAction
+	}
+	return getUser(ctx, s.userRepo, id)
+}
+
+// revoke 吊销用户在 at 之前签发的全部令牌，并记录在用户上，由调用方保存
+func (s *adminUserService) revoke(ctx context.Context, user *model.User, at time.Time) error {
+	if err := s.revocations.RevokeUser(ctx, user.ID, at); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeExternal, "failed to revoke user tokens")
+	}
+	user.TokensRevokedAt = &at
+	return nil
+}
+
+// save 在同一事务中保存用户并写入审计日志，提交后发布用户更新事件
+func (s *adminUserService) save(ctx context.Context, user *model.User, action, reason, detail string) error {
+	actor, _ := database.ActorFromContext(ctx)
+	err := s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to update user")
+		}
+		return s.auditRepo.Create(ctx, &model.UserAuditLog{
+			UserID:  user.ID,
+			ActorID: actor,
+			Action:  action,
+			Reason:  reason,
+			Detail:  detail,
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("User updated by administrator",
+		zap.Uint("user_id", user.ID),
+		zap.Uint("actor_id", actor),
+		zap.String("action", action),
+	)
+	s.events.Publish(ctx, model.EventUserUpdated, user.ID)
+	return nil
+}
+
+// passwordResetURL 生成重置链接，未配置 password_reset.reset_url 时返回空字符串
+func (s *adminUserService) passwordResetURL(id uint, token string) string {
+	if s.resetURL == "" {
+		return ""
+	}
+	query := url.Values{
+		"user_id": {strconv.FormatUint(uint64(id), 10)},
+		"token":   {token},
+	}
+	return s.resetURL + "?" + query.Encode()
+}
+
+// translate 按请求语言翻译邮件标题，没有翻译时使用原文
+func (s *adminUserService) translate(ctx context.Context, text string) string {
+	if message, ok := i18n.Localize(ctx, text, nil); ok {
+		return message
+	}
+	return text
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	stdErrors "errors"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// memoryAuditLogRepository 内存中的审计日志仓储
+type memoryAuditLogRepository struct {
+	logs []*model.UserAuditLog
+}
+
+func (r *memoryAuditLogRepository) Create(ctx context.Context, log *model.UserAuditLog) error {
+	r.logs = append(r.logs, log)
+	return nil
+}
+
+func (r *memoryAuditLogRepository) ListByUser(ctx context.Context, userID uint, offset, limit int) ([]*model.UserAuditLog, int64, error) {
+	var logs []*model.UserAuditLog
+	for _, log := range r.logs {
+		if log.UserID == userID {
+			logs = append(logs, log)
+		}
+	}
+	return logs, int64(len(logs)), nil
+}
+
+func (r *memoryAuditLogRepository) DeleteByUser(ctx context.Context, userID uint) error { return nil }
+
+func (r *memoryAuditLogRepository) ClearActor(ctx context.Context, actorID uint) (int64, error) {
+	return 0, nil
+}
+
+// noopConnector 只支持开启和提交事务的驱动，仓储由内存实现替代
+type noopConnector struct{}
+
+func (noopConnector) Connect(ctx context.Context) (driver.Conn, error) { return noopConn{}, nil }
+func (noopConnector) Driver() driver.Driver                            { return nil }
+
+type noopConn struct{}
+
+func (noopConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, stdErrors.New("prepare not supported")
+}
+func (noopConn) Close() error              { return nil }
+func (noopConn) Begin() (driver.Tx, error) { return noopConn{}, nil }
+func (noopConn) Commit() error             { return nil }
+func (noopConn) Rollback() error           { return nil }
+
+func newTestAdminUserService(t *testing.T, clk clock.Clock) (AdminUserService, UserService, *emailChangeUserRepository, *memoryAuditLogRepository, *recordingMailService) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(noopConnector{})}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	repo := &emailChangeUserRepository{users: map[uint]*model.User{
+		1: {ID: 1, Username: "admin", Email: "admin@example.com", Status: 1, Roles: []string{"admin"}},
+		7: {ID: 7, Username: "alice", Email: "alice@example.com", Status: 1, Password: "old"},
+	}}
+	audit := &memoryAuditLogRepository{}
+	mail := &recordingMailService{}
+	cfg := &config.Config{PasswordReset: config.PasswordReset{TokenTTL: time.Hour, ResetURL: "https://app.example.com/reset"}}
+
+	admin := NewAdminUserService(repo, audit, database.NewTxManager(db), nil, nil, mail, clk, cfg, zap.NewNop())
+	users := NewUserService(repo, audit, nil, nil, nil, mail, newTestPasswords(t, config.Password{}), nil, nil, clk, &config.EmailChange{}, zap.NewNop())
+	return admin, users, repo, audit, mail
+}
+
+func TestForcePasswordReset(t *testing.T) {
+	clk := clock.Frozen()
+	admin, users, repo, audit, mail := newTestAdminUserService(t, clk)
+	ctx := database.WithActor(context.Background(), 1)
+
+	resp, err := admin.ForcePasswordReset(ctx, 7, &model.AdminPasswordResetRequest{Reason: "credential leak"})
+	if err != nil {
+		t.Fatalf("ForcePasswordReset failed: %v", err)
+	}
+	if !resp.PasswordResetRequired {
+		t.Fatal("response should report the required reset")
+	}
+	user := repo.users[7]
+	if user.TokensRevokedAt == nil || !user.TokensRevokedAt.Equal(clk.Now()) {
+		t.Fatalf("tokens revoked at %v, want %v", user.TokensRevokedAt, clk.Now())
+	}
+	if len(mail.sent) != 1 || mail.sent[0].to != "alice@example.com" || mail.sent[0].page != passwordResetPage {
+		t.Fatalf("unexpected mails: %+v", mail.sent)
+	}
+	token := mail.sent[0].data["Token"].(string)
+	if user.PasswordResetTokenHash == token {
+		t.Fatal("the token must be stored hashed")
+	}
+	if len(audit.logs) != 1 || audit.logs[0].ActorID != 1 || audit.logs[0].Action != model.UserAuditPasswordResetForced || audit.logs[0].Reason != "credential leak" {
+		t.Fatalf("unexpected audit logs: %+v", audit.logs)
+	}
+
+	if err := users.ConfirmPasswordReset(context.Background(), 7, "wrong", "new-password"); err != errors.ErrResetTokenInvalid {
+		t.Fatalf("ConfirmPasswordReset with wrong token = %v, want ErrResetTokenInvalid", err)
+	}
+	if err := users.ConfirmPasswordReset(context.Background(), 7, token, "new-password"); err != nil {
+		t.Fatalf("ConfirmPasswordReset failed: %v", err)
+	}
+	user = repo.users[7]
+	if user.PasswordResetRequired || user.PasswordResetTokenHash != "" || user.Password == "old" {
+		t.Fatalf("reset not completed: %+v", user)
+	}
+	if last := audit.logs[len(audit.logs)-1]; last.Action != model.UserAuditPasswordResetCompleted || last.ActorID != 7 {
+		t.Fatalf("unexpected audit log: %+v", last)
+	}
+
+	// 令牌只能使用一次
+	if err := users.ConfirmPasswordReset(context.Background(), 7, token, "another"); err != errors.ErrResetNotRequired {
+		t.Fatalf("reusing the token = %v, want ErrResetNotRequired", err)
+	}
+}
+
+func TestConfirmPasswordResetExpired(t *testing.T) {
+	clk := clock.Frozen()
+	admin, users, repo, _, mail := newTestAdminUserService(t, clk)
+
+	if _, err := admin.ForcePasswordReset(database.WithActor(context.Background(), 1), 7, &model.AdminPasswordResetRequest{}); err != nil {
+		t.Fatalf("ForcePasswordReset failed: %v", err)
+	}
+	clk.Advance(time.Hour)
+
+	token := mail.sent[0].data["Token"].(string)
+	if err := users.ConfirmPasswordReset(context.Background(), 7, token, "new-password"); err != errors.ErrResetTokenExpired {
+		t.Fatalf("ConfirmPasswordReset = %v, want ErrResetTokenExpired", err)
+	}
+	// 过期后仍要求重置，需要管理员重新发起
+	if !repo.users[7].PasswordResetRequired {
+		t.Fatal("expired reset must still block login")
+	}
+}
+
+func TestAdminUserStatusAndRoles(t *testing.T) {
+	admin, _, repo, audit, _ := newTestAdminUserService(t, clock.Frozen())
+	ctx := database.WithActor(context.Background(), 1)
+
+	if _, err := admin.DisableUser(ctx, 7, &model.DisableUserRequest{Reason: "spam"}); err != nil {
+		t.Fatalf("DisableUser failed: %v", err)
+	}
+	if user := repo.users[7]; user.Status != 0 || user.DisabledReason != "spam" || user.TokensRevokedAt == nil {
+		t.Fatalf("user not disabled: %+v", user)
+	}
+	if _, err := admin.EnableUser(ctx, 7, &model.EnableUserRequest{}); err != nil {
+		t.Fatalf("EnableUser failed: %v", err)
+	}
+	if user := repo.users[7]; user.Status != 1 || user.DisabledReason != "" {
+		t.Fatalf("user not enabled: %+v", user)
+	}
+
+	resp, err := admin.AssignRoles(ctx, 7, &model.AssignRolesRequest{Roles: []string{"editor", " admin", "editor"}})
+	if err != nil {
+		t.Fatalf("AssignRoles failed: %v", err)
+	}
+	if len(resp.Roles) != 2 || resp.Roles[0] != "admin" || resp.Roles[1] != "editor" {
+		t.Fatalf("roles = %v, want [admin editor]", resp.Roles)
+	}
+
+	logs, total, err := admin.ListAuditLogs(ctx, 7, 1, 20)
+	if err != nil || total != 3 {
+		t.Fatalf("ListAuditLogs = %d logs, %v; want 3", total, err)
+	}
+	if logs[2].Action != model.UserAuditRolesAssigned || logs[2].Detail != "admin,editor" {
+		t.Fatalf("unexpected audit log: %+v", logs[2])
+	}
+
+	// 管理员不能对自己执行操作
+	if _, err := admin.DisableUser(ctx, 1, &model.DisableUserRequest{Reason: "oops"}); err != errors.ErrAdminSelfAction {
+		t.Fatalf("DisableUser(self) = %v, want ErrAdminSelfAction", err)
+	}
+	if _, err := admin.AssignRoles(ctx, 1, &model.AssignRolesRequest{Roles: []string{}}); err != errors.ErrAdminSelfAction {
+		t.Fatalf("AssignRoles(self) = %v, want ErrAdminSelfAction", err)
+	}
+	if len(audit.logs) != 3 {
+		t.Fatalf("rejected actions must not be audited, got %d logs", len(audit.logs))
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"strconv"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/policy"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+	"github.com/hedeqiang/skeleton/pkg/ratelimit"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// EventPublishedByHeader 通用接口发布的事件携带的消息头，值为发布者的用户 ID，便于消费者和审计追溯来源
+const EventPublishedByHeader = "x-published-by"
+
+// EventActor 通过通用接口发布事件的登录用户
+type EventActor struct {
+	UserID uint
+	Roles  []string
+}
+
+// EventService 通用事件发布服务接口
+type EventService interface {
+	// Publish 发布允许列表中的事件，载荷按 schema 注册表校验
+	Publish(ctx context.Context, actor EventActor, req *model.PublishEventRequest) (*model.PublishEventResponse, error)
+}
+
+// publishableEvent 允许发布的事件类型及其限流策略
+type publishableEvent struct {
+	config.PublishableEvent
+	rateLimit *config.RateLimitPolicy
+}
+
+// eventService 通用事件发布服务实现
+type eventService struct {
+	publisher *mq.EventPublisher
+	limiter   *ratelimit.Limiter
+	events    map[string]*publishableEvent
+	logger    *zap.Logger
+}
+
+// NewEventService 创建通用事件发布服务实例，未启用 event_publish 时返回 nil，通用发布路由不会注册
+// 事件类型重复、缺少交换机或引用了未声明的限流策略时返回错误；limiter 为 nil 时不限流
+func NewEventService(cfg *config.Config, publisher *mq.EventPublisher, limiter *ratelimit.Limiter, logger *zap.Logger) (EventService, error) {
+	if !cfg.EventPublish.Enabled {
+		return nil, nil
+	}
+
+	events := make(map[string]*publishableEvent, len(cfg.EventPublish.Types))
+	for _, eventCfg := range cfg.EventPublish.Types {
+		if eventCfg.Type == "" || eventCfg.Exchange == "" {
+			return nil, fmt.Errorf("event_publish: type and exchange are required (type %q)", eventCfg.Type)
+		}
+		if _, ok := events[eventCfg.Type]; ok {
+			return nil, fmt.Errorf("event_publish: duplicate type %q", eventCfg.Type)
+		}
+		event := &publishableEvent{PublishableEvent: eventCfg}
+		if eventCfg.RateLimit != "" {
+			policy, ok := cfg.RoutePolicies.RateLimits[eventCfg.RateLimit]
+			if !ok {
+				return nil, fmt.Errorf("event_publish: type %q references unknown rate limit %q", eventCfg.Type, eventCfg.RateLimit)
+			}
+			event.rateLimit = &policy
+		}
+		events[eventCfg.Type] = event
+	}
+
+	return &eventService{
+		publisher: publisher,
+		limiter:   limiter,
+		events:    events,
+		logger:    logger,
+	}, nil
+}
+
+// Publish 依次检查事件类型是否允许发布、发布者角色和限流，通过后以事件信封发布
+// 未在允许列表中的类型与角色不符时返回相同的错误，不暴露允许发布的类型
+func (s *eventService) Publish(ctx context.Context, actor EventActor, req *model.PublishEventRequest) (*model.PublishEventResponse, error) {
+	event, ok := s.events[req.Type]
+	if !ok || (len(event.Roles) > 0 && !policy.HasAnyRole(actor.Roles, event.Roles...)) {
+		return nil, errors.ErrEventTypeNotAllowed
+	}
+	if err := s.allow(ctx, actor, event); err != nil {
+		return nil, err
+	}
+
+	opts := []mq.EventOption{
+		mq.WithHeaders(amqp.Table{EventPublishedByHeader: strconv.FormatUint(uint64(actor.UserID), 10)}),
+	}
+	if req.Version != "" {
+		opts = append(opts, mq.WithEventVersion(req.Version))
+	}
+	routingKey := event.RoutingKeyOrType()
+	messageID, err := s.publisher.PublishEvent(ctx, event.Exchange, routingKey, event.Type, req.Payload, opts...)
+	var schemaErr *mq.SchemaError
+	if stdErrors.As(err, &schemaErr) {
+		return nil, errors.Wrap(err, errors.ErrorTypeValidation, "payload does not match message schema").WithDetails(schemaErr.Error())
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to publish event")
+	}
+
+	s.logger.Info("Event published",
+		zap.String("message_id", messageID),
+		zap.String("type", event.Type),
+		zap.String("exchange", event.Exchange),
+		zap.String("routing_key", routingKey),
+		zap.Uint("user_id", actor.UserID),
+	)
+	return &model.PublishEventResponse{
+		MessageID:  messageID,
+		Type:       event.Type,
+		Exchange:   event.Exchange,
+		RoutingKey: routingKey,
+	}, nil
+}
+
+// allow 按用户和事件类型限流，Redis 不可用时放行并记录日志
+func (s *eventService) allow(ctx context.Context, actor EventActor, event *publishableEvent) error {
+	if event.rateLimit == nil || s.limiter == nil {
+		return nil
+	}
+
+	key := fmt.Sprintf("event_publish:%s:user:%d", event.Type, actor.UserID)
+	result, err := s.limiter.Allow(ctx, key, event.rateLimit.Requests, event.rateLimit.Window)
+	if err != nil {
+		s.logger.Warn("Rate limit check failed, event publish allowed",
+			zap.String("type", event.Type),
+			zap.String("rate_limit", event.RateLimit),
+			zap.Error(err),
+		)
+		return nil
+	}
+	if !result.Allowed {
+		return errors.ErrEventRateLimited.WithData(map[string]interface{}{
+			"retry_after": int(result.ResetIn.Seconds()) + 1,
+		})
+	}
+	return nil
+}
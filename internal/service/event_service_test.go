@@ -0,0 +1,142 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/idgen"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+
+	"go.uber.org/zap"
+)
+
+func newTestEventService(t *testing.T, types ...config.PublishableEvent) (EventService, *capturingPublisher) {
+	t.Helper()
+	schemas, err := mq.LoadSchemas(fstest.MapFS{
+		"user.created/v1.json": {Data: []byte(`{"type": "object", "required": ["user_id"]}`)},
+	})
+	if err != nil {
+		t.Fatalf("LoadSchemas() error = %v", err)
+	}
+	generator, err := idgen.NewSonyflakeGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create id generator: %v", err)
+	}
+	publisher := &capturingPublisher{}
+	events := mq.NewEventPublisher(publisher, generator, clock.Frozen(), "skeleton", mq.WithSchemaRegistry(schemas))
+
+	cfg := &config.Config{EventPublish: config.EventPublish{Enabled: true, Types: types}}
+	svc, err := NewEventService(cfg, events, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewEventService() error = %v", err)
+	}
+	return svc, publisher
+}
+
+func TestEventServicePublish(t *testing.T) {
+	svc, publisher := newTestEventService(t,
+		config.PublishableEvent{Type: "user.created", Exchange: "domain.events"},
+		config.PublishableEvent{Type: "hello", Exchange: "hello.exchange", RoutingKey: "greeting", Roles: []string{"admin"}},
+	)
+
+	result, err := svc.Publish(context.Background(), EventActor{UserID: 7}, &model.PublishEventRequest{
+		Type:    "user.created",
+		Payload: json.RawMessage(`{"user_id":1}`),
+	})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if result.RoutingKey != "user.created" || result.MessageID == "" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if publisher.exchange != "domain.events" || publisher.routingKey != "user.created" {
+		t.Fatalf("published to %s/%s", publisher.exchange, publisher.routingKey)
+	}
+	if publisher.message.Headers[EventPublishedByHeader] != "7" {
+		t.Fatalf("missing %s header: %v", EventPublishedByHeader, publisher.message.Headers)
+	}
+	var envelope mq.Envelope
+	if err := json.Unmarshal(publisher.message.Body, &envelope); err != nil {
+		t.Fatalf("body is not an envelope: %v", err)
+	}
+	if envelope.MessageType != "user.created" || envelope.MessageID != result.MessageID {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+
+	result, err = svc.Publish(context.Background(), EventActor{UserID: 1, Roles: []string{"admin"}}, &model.PublishEventRequest{
+		Type:    "hello",
+		Payload: json.RawMessage(`{"content":"hi"}`),
+	})
+	if err != nil || result.RoutingKey != "greeting" || publisher.routingKey != "greeting" {
+		t.Fatalf("Publish() = %+v, %v, want routing key greeting", result, err)
+	}
+}
+
+func TestEventServicePublishRejected(t *testing.T) {
+	svc, publisher := newTestEventService(t,
+		config.PublishableEvent{Type: "user.created", Exchange: "domain.events"},
+		config.PublishableEvent{Type: "hello", Exchange: "hello.exchange", Roles: []string{"admin"}},
+	)
+
+	tests := []struct {
+		name     string
+		actor    EventActor
+		req      *model.PublishEventRequest
+		wantType errors.ErrorType
+	}{
+		{"not in allow-list", EventActor{UserID: 1}, &model.PublishEventRequest{Type: "user.deleted", Payload: json.RawMessage(`{}`)}, errors.ErrorTypeForbidden},
+		{"missing role", EventActor{UserID: 1, Roles: []string{"user"}}, &model.PublishEventRequest{Type: "hello", Payload: json.RawMessage(`{}`)}, errors.ErrorTypeForbidden},
+		{"schema mismatch", EventActor{UserID: 1}, &model.PublishEventRequest{Type: "user.created", Payload: json.RawMessage(`{"id":1}`)}, errors.ErrorTypeValidation},
+		{"undeclared version", EventActor{UserID: 1}, &model.PublishEventRequest{Type: "user.created", Version: "2", Payload: json.RawMessage(`{"user_id":1}`)}, errors.ErrorTypeValidation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.Publish(context.Background(), tt.actor, tt.req)
+			appErr, ok := err.(*errors.AppError)
+			if !ok || appErr.Type != tt.wantType {
+				t.Fatalf("Publish() error = %v, want %s", err, tt.wantType)
+			}
+		})
+	}
+	if publisher.exchange != "" {
+		t.Error("rejected events should not be published")
+	}
+}
+
+func TestNewEventServiceConfig(t *testing.T) {
+	cfg := &config.Config{
+		RoutePolicies: config.RoutePolicies{RateLimits: map[string]config.RateLimitPolicy{
+			"event_publish": {Requests: 1, Window: time.Minute, Key: "user"},
+		}},
+	}
+	if svc, err := NewEventService(cfg, nil, nil, zap.NewNop()); svc != nil || err != nil {
+		t.Fatalf("disabled event_publish should return nil service, got %v, %v", svc, err)
+	}
+
+	cfg.EventPublish.Enabled = true
+	tests := []struct {
+		name    string
+		types   []config.PublishableEvent
+		wantErr bool
+	}{
+		{"valid", []config.PublishableEvent{{Type: "hello", Exchange: "hello.exchange", RateLimit: "event_publish"}}, false},
+		{"missing exchange", []config.PublishableEvent{{Type: "hello"}}, true},
+		{"duplicate type", []config.PublishableEvent{{Type: "hello", Exchange: "a"}, {Type: "hello", Exchange: "b"}}, true},
+		{"unknown rate limit", []config.PublishableEvent{{Type: "hello", Exchange: "hello.exchange", RateLimit: "missing"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.EventPublish.Types = tt.types
+			if _, err := NewEventService(cfg, nil, nil, zap.NewNop()); (err != nil) != tt.wantErr {
+				t.Fatalf("NewEventService() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/secretbox"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// IdentityService 外部账号关联服务接口
+// Link 和 Resolve 由 OAuth 登录流程在取得提供方的用户信息后调用，List 和 Unlink 供用户管理自己的关联
+type IdentityService interface {
+	// Link 将外部账号关联到用户，已关联到该用户时更新邮箱和令牌
+	// 外部账号已关联其他用户时返回 ErrIdentityLinked，用户已关联该提供方的其他账号时返回 ErrIdentityProviderLinked
+	Link(ctx context.Context, userID uint, external *model.ExternalIdentity) (*model.UserIdentityResponse, error)
+	// Resolve 返回外部账号关联的用户并更新令牌，用户状态由调用方按登录规则检查
+	// 未关联时，外部邮箱已被本地账户使用返回 ErrIdentityEmailConflict，否则返回 ErrIdentityNotFound；
+	// 不按邮箱自动合并，避免提供方的未验证邮箱被用来接管本地账户，用户需要登录本地账户后再关联
+	Resolve(ctx context.Context, external *model.ExternalIdentity) (*model.User, error)
+	// List 返回用户关联的全部外部账号，不包含令牌
+	List(ctx context.Context, userID uint) ([]*model.UserIdentityResponse, error)
+	// Unlink 解除关联并删除保存的令牌
+	Unlink(ctx context.Context, userID, identityID uint) error
+	// Tokens 返回用户在提供方关联的外部账号，令牌已解密；未配置加密密钥时令牌为空
+	Tokens(ctx context.Context, userID uint, provider string) (*model.ExternalIdentity, error)
+}
+
+// identityService 外部账号关联服务实现
+type identityService struct {
+	repo      repository.UserIdentityRepository
+	userRepo  repository.UserRepository
+	auditRepo repository.UserAuditLogRepository
+	tx        *database.TxManager
+	box       *secretbox.Box
+	logger    *zap.Logger
+}
+
+// NewIdentityService 创建外部账号关联服务实例
+// 未配置 identities.token_encryption_key 时只保存关联关系，不保存外部令牌；密钥格式错误时返回错误
+func NewIdentityService(
+	repo repository.UserIdentityRepository,
+	userRepo repository.UserRepository,
+	auditRepo repository.UserAuditLogRepository,
+	tx *database.TxManager,
+	cfg *config.Identities,
+	logger *zap.Logger,
+) (IdentityService, error) {
+	s := &identityService{
+		repo:      repo,
+		userRepo:  userRepo,
+		auditRepo: auditRepo,
+		tx:        tx,
+		logger:    logger,
+	}
+	if cfg.TokenEncryptionKey != "" {
+		box, err := secretbox.New(cfg.TokenEncryptionKey)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrorTypeInternal, "invalid identities.token_encryption_key")
+		}
+		s.box = box
+	}
+	return s, nil
+}
+
+// Link 关联外部账号
+func (s *identityService) Link(ctx context.Context, userID uint, external *model.ExternalIdentity) (*model.UserIdentityResponse, error) {
+	provider, err := normalizeExternalIdentity(external)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := getUser(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+
+	identity, err := s.repo.GetByProviderUserID(ctx, provider, external.ProviderUserID)
+	switch {
+	case err == nil && identity.UserID != userID:
+		return nil, errors.ErrIdentityLinked
+	case err == nil:
+		if err := s.refresh(ctx, identity, external); err != nil {
+			return nil, err
+		}
+		return toIdentityResponse(identity), nil
+	case !stdErrors.Is(err, gorm.ErrRecordNotFound):
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get user identity")
+	}
+
+	if _, err := s.repo.GetByUserProvider(ctx, userID, provider); err == nil {
+		return nil, errors.ErrIdentityProviderLinked.WithData(map[string]interface{}{"provider": provider})
+	} else if !stdErrors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get user identity")
+	}
+
+	identity = &model.UserIdentity{
+		UserID:         userID,
+		Provider:       provider,
+		ProviderUserID: external.ProviderUserID,
+		Email:          external.Email,
+	}
+	if err := s.sealTokens(identity, external); err != nil {
+		return nil, err
+	}
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, identity); err != nil {
+			return err
+		}
+		return s.audit(ctx, userID, model.UserAuditIdentityLinked, provider)
+	})
+	if err != nil {
+		// 并发关联时由唯一索引拒绝后写入的一方
+		if database.IsDuplicateKey(err) {
+			return nil, errors.ErrIdentityLinked
+		}
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to link user identity")
+	}
+
+	s.logger.Info("User identity linked", zap.Uint("user_id", userID), zap.String("provider", provider))
+	return toIdentityResponse(identity), nil
+}
+
+// Resolve 查找外部账号关联的用户
+func (s *identityService) Resolve(ctx context.Context, external *model.ExternalIdentity) (*model.User, error) {
+	provider, err := normalizeExternalIdentity(external)
+	if err != nil {
+		return nil, err
+	}
+
+	identity, err := s.repo.GetByProviderUserID(ctx, provider, external.ProviderUserID)
+	if err != nil {
+		if !stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get user identity")
+		}
+		if external.Email != "" {
+			exists, err := s.userRepo.ExistsByEmail(ctx, external.Email)
+			if err != nil {
+				return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to check email")
+			}
+			if exists {
+				return nil, errors.ErrIdentityEmailConflict.WithData(map[string]interface{}{"provider": provider})
+			}
+		}
+		return nil, errors.ErrIdentityNotFound
+	}
+
+	user, err := getUser(ctx, s.userRepo, identity.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refresh(ctx, identity, external); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// List 返回用户关联的外部账号
+func (s *identityService) List(ctx context.Context, userID uint) ([]*model.UserIdentityResponse, error) {
+	if _, err := getUser(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	identities, err := s.repo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to list user identities")
+	}
+
+	responses := make([]*model.UserIdentityResponse, len(identities))
+	for i, identity := range identities {
+		responses[i] = toIdentityResponse(identity)
+	}
+	return responses, nil
+}
+
+// Unlink 解除关联
+func (s *identityService) Unlink(ctx context.Context, userID, identityID uint) error {
+	identity, err := s.repo.GetByUserAndID(ctx, userID, identityID)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.ErrIdentityNotFound
+		}
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get user identity")
+	}
+
+	err = s.tx.Transaction(ctx, func(ctx context.Context) error {
+		if err := s.repo.Delete(ctx, identity.ID); err != nil {
+			return err
+		}
+		return s.audit(ctx, userID, model.UserAuditIdentityUnlinked, identity.Provider)
+	})
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to unlink user identity")
+	}
+
+	s.logger.Info("User identity unlinked", zap.Uint("user_id", userID), zap.String("provider", identity.Provider))
+	return nil
+}
+
+// Tokens 返回解密后的外部令牌
+func (s *identityService) Tokens(ctx context.Context, userID uint, provider string) (*model.ExternalIdentity, error) {
+	identity, err := s.repo.GetByUserProvider(ctx, userID, strings.ToLower(strings.TrimSpace(provider)))
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrIdentityNotFound
+		}
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get user identity")
+	}
+
+	external := &model.ExternalIdentity{
+		Provider:       identity.Provider,
+		ProviderUserID: identity.ProviderUserID,
+		Email:          identity.Email,
+		TokenExpiresAt: identity.TokenExpiresAt,
+	}
+	if s.box == nil {
+		return external, nil
+	}
+	additional := tokenAdditionalData(identity)
+	if external.AccessToken, err = s.box.Open(identity.AccessToken, additional); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to decrypt access token")
+	}
+	if external.RefreshToken, err = s.box.Open(identity.RefreshToken, additional); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to decrypt refresh token")
+	}
+	return external, nil
+}
+
+// refresh 用提供方最新返回的邮箱和令牌更新关联
+// 提供方刷新令牌时可能不返回新的刷新令牌，此时保留原值
+func (s *identityService) refresh(ctx context.Context, identity *model.UserIdentity, external *model.ExternalIdentity) error {
+	previousRefresh := identity.RefreshToken
+	identity.Email = external.Email
+	if err := s.sealTokens(identity, external); err != nil {
+		return err
+	}
+	if external.RefreshToken == "" {
+		identity.RefreshToken = previousRefresh
+	}
+	if err := s.repo.Update(ctx, identity); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to update user identity")
+	}
+	return nil
+}
+
+// sealTokens 加密外部令牌并写入关联，未配置密钥时不保存令牌
+// 附加认证数据绑定提供方和提供方用户标识，密文被复制到其他记录后无法解密
+func (s *identityService) sealTokens(identity *model.UserIdentity, external *model.ExternalIdentity) error {
+	identity.TokenExpiresAt = external.TokenExpiresAt
+	if s.box == nil {
+		identity.AccessToken, identity.RefreshToken = "", ""
+		return nil
+	}
+
+	additional := tokenAdditionalData(identity)
+	var err error
+	if identity.AccessToken, err = s.box.Seal(external.AccessToken, additional); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeInternal, "failed to encrypt access token")
+	}
+	if identity.RefreshToken, err = s.box.Seal(external.RefreshToken, additional); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeInternal, "failed to encrypt refresh token")
+	}
+	return nil
+}
+
+// audit 写入审计日志，操作人为当前登录用户，OAuth 回调等未登录的场景记为用户本人
+func (s *identityService) audit(ctx context.Context, userID uint, action, provider string) error {
+	actor, ok := database.ActorFromContext(ctx)
+	if !ok {
+		actor = userID
+	}
+	return s.auditRepo.Create(ctx, &model.UserAuditLog{
+		UserID:  userID,
+		ActorID: actor,
+		Action:  action,
+		Detail:  provider,
+	})
+}
+
+// normalizeExternalIdentity 校验外部账号并返回小写的提供方名称
+func normalizeExternalIdentity(external *model.ExternalIdentity) (string, error) {
+	provider := strings.ToLower(strings.TrimSpace(external.Provider))
+	if provider == "" || external.ProviderUserID == "" {
+		return "", errors.ValidationError("provider and provider user id are required")
+	}
+	return provider, nil
+}
+
+// tokenAdditionalData 外部令牌的附加认证数据
+func tokenAdditionalData(identity *model.UserIdentity) string {
+	return identity.Provider + ":" + identity.ProviderUserID
+}
+
+// toIdentityResponse 转换为响应，不包含令牌
+func toIdentityResponse(identity *model.UserIdentity) *model.UserIdentityResponse {
+	return &model.UserIdentityResponse{
+		ID:             identity.ID,
+		Provider:       identity.Provider,
+		ProviderUserID: identity.ProviderUserID,
+		Email:          identity.Email,
+		CreatedAt:      identity.CreatedAt,
+		UpdatedAt:      identity.UpdatedAt,
+	}
+}
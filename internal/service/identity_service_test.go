@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	stdErrors "errors"
+	"strings"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// memoryIdentityRepository 内存中的外部账号关联仓储
+type memoryIdentityRepository struct {
+	repository.UserIdentityRepository
+	identities map[uint]*model.UserIdentity
+	nextID     uint
+}
+
+func (r *memoryIdentityRepository) find(match func(*model.UserIdentity) bool) (*model.UserIdentity, error) {
+	for _, identity := range r.identities {
+		if match(identity) {
+			copied := *identity
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryIdentityRepository) Create(ctx context.Context, identity *model.UserIdentity) error {
+	r.nextID++
+	identity.ID = r.nextID
+	copied := *identity
+	r.identities[identity.ID] = &copied
+	return nil
+}
+
+func (r *memoryIdentityRepository) Update(ctx context.Context, identity *model.UserIdentity) error {
+	copied := *identity
+	r.identities[identity.ID] = &copied
+	return nil
+}
+
+func (r *memoryIdentityRepository) GetByProviderUserID(ctx context.Context, provider, providerUserID string) (*model.UserIdentity, error) {
+	return r.find(func(i *model.UserIdentity) bool { return i.Provider == provider && i.ProviderUserID == providerUserID })
+}
+
+func (r *memoryIdentityRepository) GetByUserProvider(ctx context.Context, userID uint, provider string) (*model.UserIdentity, error) {
+	return r.find(func(i *model.UserIdentity) bool { return i.UserID == userID && i.Provider == provider })
+}
+
+func (r *memoryIdentityRepository) GetByUserAndID(ctx context.Context, userID, id uint) (*model.UserIdentity, error) {
+	return r.find(func(i *model.UserIdentity) bool { return i.UserID == userID && i.ID == id })
+}
+
+func (r *memoryIdentityRepository) ListByUser(ctx context.Context, userID uint) ([]*model.UserIdentity, error) {
+	var identities []*model.UserIdentity
+	for _, identity := range r.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (r *memoryIdentityRepository) Delete(ctx context.Context, id uint) error {
+	delete(r.identities, id)
+	return nil
+}
+
+func newTestIdentityService(t *testing.T, key string) (IdentityService, *memoryIdentityRepository, *memoryAuditLogRepository) {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(noopConnector{})}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	users := &emailChangeUserRepository{users: map[uint]*model.User{
+		7: {ID: 7, Username: "alice", Email: "alice@example.com", Status: 1},
+		8: {ID: 8, Username: "bob", Email: "bob@example.com", Status: 1},
+	}}
+	repo := &memoryIdentityRepository{identities: map[uint]*model.UserIdentity{}}
+	audit := &memoryAuditLogRepository{}
+	s, err := NewIdentityService(repo, users, audit, database.NewTxManager(db), &config.Identities{TokenEncryptionKey: key}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewIdentityService failed: %v", err)
+	}
+	return s, repo, audit
+}
+
+func newTestTokenKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+// assertIdentityError 比较消息 ID，AppError 的 Is 只比较错误类型
+func assertIdentityError(t *testing.T, err error, want *errors.AppError) {
+	t.Helper()
+	var appErr *errors.AppError
+	if !stdErrors.As(err, &appErr) || appErr.MessageID != want.MessageID {
+		t.Fatalf("error = %v, want %s", err, want.MessageID)
+	}
+}
+
+func TestIdentityLinkEncryptsTokens(t *testing.T) {
+	ctx := context.Background()
+	s, repo, audit := newTestIdentityService(t, newTestTokenKey(t))
+
+	resp, err := s.Link(ctx, 7, &model.ExternalIdentity{Provider: "GitHub", ProviderUserID: "42", Email: "alice@users.github.com", AccessToken: "gho_access", RefreshToken: "ghr_refresh"})
+	if err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+	if resp.Provider != "github" {
+		t.Fatalf("provider = %q, want it normalized to github", resp.Provider)
+	}
+
+	stored := repo.identities[resp.ID]
+	if strings.Contains(stored.AccessToken, "gho_access") || strings.Contains(stored.RefreshToken, "ghr_refresh") || stored.AccessToken == "" {
+		t.Fatalf("tokens are not encrypted: %q, %q", stored.AccessToken, stored.RefreshToken)
+	}
+	if len(audit.logs) != 1 || audit.logs[0].Action != model.UserAuditIdentityLinked || audit.logs[0].Detail != "github" || audit.logs[0].ActorID != 7 {
+		t.Fatalf("unexpected audit logs %+v", audit.logs)
+	}
+
+	// 重新关联时更新令牌，提供方未返回新的刷新令牌时保留原值
+	if _, err := s.Link(ctx, 7, &model.ExternalIdentity{Provider: "github", ProviderUserID: "42", AccessToken: "gho_rotated"}); err != nil {
+		t.Fatalf("relink failed: %v", err)
+	}
+	tokens, err := s.Tokens(ctx, 7, "github")
+	if err != nil {
+		t.Fatalf("Tokens failed: %v", err)
+	}
+	if tokens.AccessToken != "gho_rotated" || tokens.RefreshToken != "ghr_refresh" {
+		t.Fatalf("tokens = %q, %q", tokens.AccessToken, tokens.RefreshToken)
+	}
+	if len(audit.logs) != 1 {
+		t.Fatal("relinking the same identity must not write another audit log")
+	}
+
+	// 密文被复制到其他记录后无法解密
+	if _, err := s.Link(ctx, 8, &model.ExternalIdentity{Provider: "github", ProviderUserID: "43", AccessToken: "other"}); err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+	for _, identity := range repo.identities {
+		if identity.UserID == 8 {
+			identity.AccessToken = stored.AccessToken
+		}
+	}
+	if _, err := s.Tokens(ctx, 8, "github"); err == nil {
+		t.Fatal("ciphertext moved to another identity must not decrypt")
+	}
+}
+
+func TestIdentityLinkConflicts(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestIdentityService(t, "")
+
+	if _, err := s.Link(ctx, 7, &model.ExternalIdentity{Provider: "github", ProviderUserID: "42"}); err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+
+	_, err := s.Link(ctx, 8, &model.ExternalIdentity{Provider: "github", ProviderUserID: "42"})
+	assertIdentityError(t, err, errors.ErrIdentityLinked)
+
+	_, err = s.Link(ctx, 7, &model.ExternalIdentity{Provider: "github", ProviderUserID: "99"})
+	assertIdentityError(t, err, errors.ErrIdentityProviderLinked)
+
+	_, err = s.Link(ctx, 404, &model.ExternalIdentity{Provider: "github", ProviderUserID: "100"})
+	assertIdentityError(t, err, errors.ErrUserNotFound)
+}
+
+func TestIdentityResolve(t *testing.T) {
+	ctx := context.Background()
+	s, repo, _ := newTestIdentityService(t, "")
+
+	if _, err := s.Link(ctx, 7, &model.ExternalIdentity{Provider: "github", ProviderUserID: "42", AccessToken: "dropped"}); err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+	for _, identity := range repo.identities {
+		if identity.AccessToken != "" {
+			t.Fatal("tokens must not be stored without an encryption key")
+		}
+	}
+
+	user, err := s.Resolve(ctx, &model.ExternalIdentity{Provider: "github", ProviderUserID: "42", Email: "alice@users.github.com"})
+	if err != nil || user.ID != 7 {
+		t.Fatalf("Resolve = %v, %v; want user 7", user, err)
+	}
+
+	// 外部邮箱与本地账户相同时不自动合并
+	_, err = s.Resolve(ctx, &model.ExternalIdentity{Provider: "google", ProviderUserID: "g-1", Email: "bob@example.com"})
+	assertIdentityError(t, err, errors.ErrIdentityEmailConflict)
+
+	_, err = s.Resolve(ctx, &model.ExternalIdentity{Provider: "google", ProviderUserID: "g-2", Email: "carol@example.com"})
+	assertIdentityError(t, err, errors.ErrIdentityNotFound)
+}
+
+func TestIdentityListAndUnlink(t *testing.T) {
+	ctx := context.Background()
+	s, _, audit := newTestIdentityService(t, "")
+
+	linked, err := s.Link(ctx, 7, &model.ExternalIdentity{Provider: "github", ProviderUserID: "42"})
+	if err != nil {
+		t.Fatalf("Link failed: %v", err)
+	}
+
+	// 不能解除其他用户的关联
+	assertIdentityError(t, s.Unlink(ctx, 8, linked.ID), errors.ErrIdentityNotFound)
+
+	if err := s.Unlink(database.WithActor(ctx, 1), 7, linked.ID); err != nil {
+		t.Fatalf("Unlink failed: %v", err)
+	}
+	identities, err := s.List(ctx, 7)
+	if err != nil || len(identities) != 0 {
+		t.Fatalf("List = %v, %v; want empty", identities, err)
+	}
+	last := audit.logs[len(audit.logs)-1]
+	if last.Action != model.UserAuditIdentityUnlinked || last.ActorID != 1 {
+		t.Fatalf("unexpected audit log %+v", last)
+	}
+}
+
+func TestNewIdentityServiceRejectsInvalidKey(t *testing.T) {
+	if _, err := NewIdentityService(nil, nil, nil, nil, &config.Identities{TokenEncryptionKey: "short"}, zap.NewNop()); err == nil {
+		t.Fatal("invalid key must be rejected")
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/mailer"
+	"github.com/hedeqiang/skeleton/pkg/template"
+)
+
+// 邮件模板使用的布局和页面目录
+const (
+	emailLayout = "email"
+	emailDir    = "emails"
+)
+
+// MailService 邮件服务接口
+type MailService interface {
+	// SendTemplate 使用 internal/templates/emails 下的模板发送邮件
+	// data 中的 Subject 字段会被设置为邮件标题
+	SendTemplate(ctx context.Context, to []string, subject, page string, data map[string]interface{}) error
+	// Preload 预先解析全部邮件模板，供启动预热使用
+	Preload(ctx context.Context) error
+}
+
+// mailService 邮件服务实现
+type mailService struct {
+	mailer   mailer.Mailer
+	template *template.Engine
+}
+
+// NewMailService 创建邮件服务实例
+func NewMailService(m mailer.Mailer, engine *template.Engine) MailService {
+	return &mailService{
+		mailer:   m,
+		template: engine,
+	}
+}
+
+// SendTemplate 渲染模板并发送邮件
+func (s *mailService) SendTemplate(ctx context.Context, to []string, subject, page string, data map[string]interface{}) error {
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	data["Subject"] = subject
+
+	html, err := s.template.RenderString(ctx, emailLayout, page, data)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeInternal, "failed to render mail template")
+	}
+
+	if err := s.mailer.Send(ctx, &mailer.Message{To: to, Subject: subject, HTML: html}); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeExternal, "failed to send mail")
+	}
+	return nil
+}
+
+// Preload 预先解析全部邮件模板
+func (s *mailService) Preload(_ context.Context) error {
+	if err := s.template.Preload(emailLayout, emailDir); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeInternal, "failed to preload mail templates")
+	}
+	return nil
+}
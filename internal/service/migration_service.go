@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hedeqiang/skeleton/internal/migrations"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/migrate"
+
+	"gorm.io/gorm"
+)
+
+// MigrationService 数据库迁移状态服务接口
+type MigrationService interface {
+	// Status 返回各数据源已执行和待执行的迁移，按数据源名称排序
+	Status(ctx context.Context) ([]*model.MigrationStatus, error)
+	// PendingCount 返回所有数据源待执行迁移的总数
+	PendingCount(ctx context.Context) (int, error)
+}
+
+// migrationService 数据库迁移状态服务实现
+type migrationService struct {
+	dataSources map[string]*gorm.DB
+}
+
+// NewMigrationService 创建数据库迁移状态服务实例
+func NewMigrationService(dataSources map[string]*gorm.DB) MigrationService {
+	return &migrationService{dataSources: dataSources}
+}
+
+// Status 查询各数据源迁移状态
+func (s *migrationService) Status(ctx context.Context) ([]*model.MigrationStatus, error) {
+	names := make([]string, 0, len(s.dataSources))
+	for name := range s.dataSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]*model.MigrationStatus, 0, len(names))
+	for _, name := range names {
+		status, err := migrate.New(s.dataSources[name], migrations.For(name)).Status(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to load migration status")
+		}
+		result = append(result, &model.MigrationStatus{
+			DataSource: name,
+			Version:    status.Version,
+			Applied:    status.Applied,
+			Pending:    status.Pending,
+		})
+	}
+	return result, nil
+}
+
+// PendingCount 统计待执行迁移数量
+func (s *migrationService) PendingCount(ctx context.Context) (int, error) {
+	statuses, err := s.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, status := range statuses {
+		count += len(status.Pending)
+	}
+	return count, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/idgen"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+const (
+	// MQTestMessageType 测试消息未指定类型时信封中的消息类型
+	MQTestMessageType = "mq.test"
+	// MQTestHeader 测试消息携带的消息头，消费者可据此识别冒烟测试消息
+	MQTestHeader = "x-smoke-test"
+)
+
+// MQAdminService 消息队列拓扑查看与冒烟测试服务接口
+type MQAdminService interface {
+	// Queues 返回配置的队列及 broker 中的消息数和消费者数
+	Queues(ctx context.Context) []*model.MQQueue
+	// Exchanges 返回配置的交换机及其在 broker 中是否存在
+	Exchanges(ctx context.Context) []*model.MQExchange
+	// PublishTest 向配置的交换机发布一条测试消息，等待 broker 确认后返回
+	PublishTest(ctx context.Context, req *model.MQTestPublishRequest) (*model.MQTestPublishResponse, error)
+}
+
+// mqAdminService 消息队列拓扑查看与冒烟测试服务实现
+type mqAdminService struct {
+	cfg         *config.RabbitMQ
+	brokers     *mq.Brokers
+	publisher   mq.Publisher
+	idGenerator idgen.IDGenerator
+	clock       clock.Clock
+	schemas     *mq.SchemaRegistry
+	source      string
+	logger      *zap.Logger
+}
+
+// NewMQAdminService 创建消息队列拓扑查看与冒烟测试服务实例
+// publisher 用于 redis、nats 驱动的交换机，rabbitmq 交换机按所属 broker 发布；schemas 非空时发布前校验载荷
+func NewMQAdminService(cfg *config.Config, brokers *mq.Brokers, publisher mq.Publisher, idGenerator idgen.IDGenerator, clk clock.Clock, schemas *mq.SchemaRegistry, logger *zap.Logger) MQAdminService {
+	return &mqAdminService{
+		cfg:         &cfg.RabbitMQ,
+		brokers:     brokers,
+		publisher:   publisher,
+		idGenerator: idGenerator,
+		clock:       clk,
+		schemas:     schemas,
+		source:      cfg.App.Name,
+		logger:      logger,
+	}
+}
+
+// Queues 按配置顺序返回队列，rabbitmq 队列通过被动声明查询实时状态
+func (s *mqAdminService) Queues(ctx context.Context) []*model.MQQueue {
+	queues := make([]*model.MQQueue, 0, len(s.cfg.Queues))
+	for _, queueCfg := range s.cfg.Queues {
+		queue := &model.MQQueue{
+			Name:               queueCfg.Name,
+			Driver:             queueCfg.DriverName(),
+			Exchange:           queueCfg.Exchange,
+			RoutingKeys:        queueCfg.RoutingKeys,
+			Durable:            queueCfg.Durable,
+			DeadLetterExchange: queueCfg.DeadLetterExchange,
+			NoConsume:          queueCfg.NoConsume,
+			Status:             model.MQStatusUnsupported,
+		}
+		if queue.Driver == config.DriverRabbitMQ {
+			queue.Broker = queueCfg.BrokerName()
+			state, err := s.brokers.InspectQueue(queue.Broker, queue.Name)
+			queue.Status, queue.Error = inspectStatus(err)
+			if err == nil {
+				queue.Messages = &state.Messages
+				queue.Consumers = &state.Consumers
+			}
+		}
+		queues = append(queues, queue)
+	}
+	return queues
+}
+
+// Exchanges 按配置顺序返回交换机，rabbitmq 交换机通过被动声明检查是否存在
+func (s *mqAdminService) Exchanges(ctx context.Context) []*model.MQExchange {
+	exchanges := make([]*model.MQExchange, 0, len(s.cfg.Exchanges))
+	for _, exchangeCfg := range s.cfg.Exchanges {
+		exchange := &model.MQExchange{
+			Name:       exchangeCfg.Name,
+			Type:       exchangeCfg.Type,
+			Driver:     s.cfg.ExchangeDriver(exchangeCfg.Name),
+			Durable:    exchangeCfg.Durable,
+			AutoDelete: exchangeCfg.AutoDelete,
+			Queues:     []string{},
+			Status:     model.MQStatusUnsupported,
+		}
+		for _, queueCfg := range s.cfg.Queues {
+			if queueCfg.Exchange == exchangeCfg.Name {
+				exchange.Queues = append(exchange.Queues, queueCfg.Name)
+			}
+		}
+		if exchange.Driver == config.DriverRabbitMQ {
+			exchange.Broker = exchangeCfg.BrokerName()
+			err := s.brokers.InspectExchange(exchange.Broker, exchange.Name, exchange.Type)
+			exchange.Status, exchange.Error = inspectStatus(err)
+		}
+		exchanges = append(exchanges, exchange)
+	}
+	return exchanges
+}
+
+// inspectStatus 将被动声明的结果转换为状态，broker 返回 404 时为 missing，其他错误为 unavailable
+func inspectStatus(err error) (string, string) {
+	if err == nil {
+		return model.MQStatusOK, ""
+	}
+	var amqpErr *amqp.Error
+	if stdErrors.As(err, &amqpErr) && amqpErr.Code == amqp.NotFound {
+		return model.MQStatusMissing, err.Error()
+	}
+	return model.MQStatusUnavailable, err.Error()
+}
+
+// PublishTest 以事件信封发布测试消息并附带 x-smoke-test 消息头，消息无法路由到任何队列时返回错误
+func (s *mqAdminService) PublishTest(ctx context.Context, req *model.MQTestPublishRequest) (*model.MQTestPublishResponse, error) {
+	exchangeCfg, ok := s.exchange(req.Exchange)
+	if !ok {
+		return nil, errors.ErrExchangeNotFound
+	}
+
+	result := &model.MQTestPublishResponse{
+		Exchange:   req.Exchange,
+		RoutingKey: req.RoutingKey,
+		Driver:     s.cfg.ExchangeDriver(req.Exchange),
+	}
+	publisher := s.publisher
+	if result.Driver == config.DriverRabbitMQ {
+		result.Broker = exchangeCfg.BrokerName()
+		producer, err := s.brokers.Producer(result.Broker)
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to get broker producer")
+		}
+		publisher = producer
+	}
+
+	messageType := req.MessageType
+	if messageType == "" {
+		messageType = MQTestMessageType
+	}
+	payload := req.Payload
+	if len(payload) == 0 {
+		payload = json.RawMessage("{}")
+	}
+
+	events := mq.NewEventPublisher(publisher, s.idGenerator, s.clock, s.source, mq.WithSchemaRegistry(s.schemas))
+	messageID, err := events.PublishEvent(ctx, req.Exchange, req.RoutingKey, messageType, payload,
+		mq.WithHeaders(amqp.Table{MQTestHeader: true}),
+	)
+	var schemaErr *mq.SchemaError
+	if stdErrors.As(err, &schemaErr) {
+		return nil, errors.Wrap(err, errors.ErrorTypeValidation, "payload does not match message schema")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to publish test message")
+	}
+	result.MessageID = messageID
+
+	s.logger.Info("Test message published",
+		zap.String("message_id", messageID),
+		zap.String("exchange", req.Exchange),
+		zap.String("routing_key", req.RoutingKey),
+		zap.String("message_type", messageType),
+	)
+	return result, nil
+}
+
+// exchange 查找配置的交换机
+func (s *mqAdminService) exchange(name string) (config.ExchangeConfig, bool) {
+	for _, exchange := range s.cfg.Exchanges {
+		if exchange.Name == name {
+			return exchange, true
+		}
+	}
+	return config.ExchangeConfig{}, false
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/idgen"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// capturingPublisher 记录发布的消息
+type capturingPublisher struct {
+	exchange, routingKey string
+	message              amqp.Publishing
+}
+
+func (p *capturingPublisher) Publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) error {
+	p.exchange, p.routingKey, p.message = exchange, routingKey, message
+	return nil
+}
+
+func newTestMQAdminService(t *testing.T, schemas *mq.SchemaRegistry) (MQAdminService, *capturingPublisher) {
+	t.Helper()
+	cfg := &config.Config{
+		App: config.App{Name: "skeleton"},
+		RabbitMQ: config.RabbitMQ{
+			Exchanges: []config.ExchangeConfig{{Name: "user.events", Type: "topic"}},
+			Queues:    []config.QueueConfig{{Name: "user.index", Exchange: "user.events", Driver: config.DriverRedis}},
+		},
+	}
+	// 未配置 default broker，不会建立连接
+	brokers, err := mq.NewBrokers(&cfg.RabbitMQ)
+	if err != nil {
+		t.Fatalf("Failed to create brokers: %v", err)
+	}
+	generator, err := idgen.NewSonyflakeGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create id generator: %v", err)
+	}
+	publisher := &capturingPublisher{}
+	return NewMQAdminService(cfg, brokers, publisher, generator, clock.Frozen(), schemas, zap.NewNop()), publisher
+}
+
+func TestMQAdminPublishTest(t *testing.T) {
+	svc, publisher := newTestMQAdminService(t, nil)
+
+	result, err := svc.PublishTest(context.Background(), &model.MQTestPublishRequest{
+		Exchange:   "user.events",
+		RoutingKey: "user.created",
+		Payload:    json.RawMessage(`{"id":1}`),
+	})
+	if err != nil {
+		t.Fatalf("PublishTest failed: %v", err)
+	}
+	if result.Driver != config.DriverRedis || result.Broker != "" || result.MessageID == "" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if publisher.exchange != "user.events" || publisher.routingKey != "user.created" {
+		t.Fatalf("published to %s/%s", publisher.exchange, publisher.routingKey)
+	}
+	if publisher.message.Headers[MQTestHeader] != true {
+		t.Fatalf("missing %s header: %v", MQTestHeader, publisher.message.Headers)
+	}
+
+	var envelope mq.Envelope
+	if err := json.Unmarshal(publisher.message.Body, &envelope); err != nil {
+		t.Fatalf("body is not an envelope: %v", err)
+	}
+	if envelope.MessageType != MQTestMessageType || string(envelope.Payload) != `{"id":1}` || envelope.MessageID != result.MessageID {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+
+	if _, err := svc.PublishTest(context.Background(), &model.MQTestPublishRequest{Exchange: "missing"}); err != errors.ErrExchangeNotFound {
+		t.Fatalf("expected ErrExchangeNotFound, got %v", err)
+	}
+}
+
+func TestMQAdminPublishTestSchemaMismatch(t *testing.T) {
+	schemas, err := mq.LoadSchemas(fstest.MapFS{
+		"user.created/v1.json": {Data: []byte(`{"type": "object", "required": ["user_id"]}`)},
+	})
+	if err != nil {
+		t.Fatalf("LoadSchemas() error = %v", err)
+	}
+	svc, publisher := newTestMQAdminService(t, schemas)
+
+	_, err = svc.PublishTest(context.Background(), &model.MQTestPublishRequest{
+		Exchange:    "user.events",
+		RoutingKey:  "user.created",
+		MessageType: "user.created",
+		Payload:     json.RawMessage(`{"id":1}`),
+	})
+	appErr, ok := err.(*errors.AppError)
+	if !ok || appErr.Type != errors.ErrorTypeValidation {
+		t.Fatalf("PublishTest() error = %v, want validation error", err)
+	}
+	if publisher.exchange != "" {
+		t.Error("message not matching the schema should not be published")
+	}
+}
+
+func TestMQAdminTopology(t *testing.T) {
+	svc, _ := newTestMQAdminService(t, nil)
+
+	queues := svc.Queues(context.Background())
+	if len(queues) != 1 || queues[0].Status != model.MQStatusUnsupported || queues[0].Messages != nil {
+		t.Fatalf("unexpected queues: %+v", queues[0])
+	}
+	exchanges := svc.Exchanges(context.Background())
+	if len(exchanges) != 1 || exchanges[0].Driver != config.DriverRedis || len(exchanges[0].Queues) != 1 {
+		t.Fatalf("unexpected exchanges: %+v", exchanges[0])
+	}
+}
+
+func TestInspectStatus(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, model.MQStatusOK},
+		{fmt.Errorf("inspect: %w", &amqp.Error{Code: amqp.NotFound, Reason: "NOT_FOUND"}), model.MQStatusMissing},
+		{mq.ErrBrokerNotFound, model.MQStatusUnavailable},
+	}
+	for _, tt := range tests {
+		if got, _ := inspectStatus(tt.err); got != tt.want {
+			t.Errorf("inspectStatus(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+	"github.com/hedeqiang/skeleton/pkg/password"
+	"github.com/hedeqiang/skeleton/pkg/timing"
+
+	"go.uber.org/zap"
+)
+
+// defaultBreachTimeout 泄露密码查询的默认超时时间
+const defaultBreachTimeout = 3 * time.Second
+
+// PasswordStrengthService 密码强度服务接口，注册和重置密码时检查新密码
+type PasswordStrengthService interface {
+	// Validate 检查密码强度，identities 为用户名、邮箱等，密码不能包含它们
+	// 未通过时返回 ErrPasswordTooShort、ErrPasswordCommon、ErrPasswordHasIdentity、ErrPasswordWeak 或 ErrPasswordBreached
+	Validate(ctx context.Context, password string, identities ...string) error
+}
+
+// passwordStrengthService 密码强度服务实现
+type passwordStrengthService struct {
+	rules          password.StrengthRules
+	breach         *password.BreachChecker
+	breachMinCount int
+	logger         *zap.Logger
+}
+
+// NewPasswordStrengthService 创建密码强度服务实例，未启用泄露检查时不发起外部请求
+func NewPasswordStrengthService(cfg *config.Config, logger *zap.Logger) PasswordStrengthService {
+	c := cfg.Password.Strength
+	s := &passwordStrengthService{
+		rules: password.StrengthRules{
+			MinLength:      c.MinLength,
+			MinEntropyBits: c.MinEntropyBits,
+			RejectCommon:   c.RejectCommon,
+		},
+		breachMinCount: c.Breach.MinCount,
+		logger:         logger,
+	}
+	if c.Breach.Enabled {
+		timeout := c.Breach.Timeout
+		if timeout <= 0 {
+			timeout = defaultBreachTimeout
+		}
+		client := &http.Client{Timeout: timeout, Transport: timing.Transport(nil)}
+		s.breach = password.NewBreachChecker(c.Breach.URL, client)
+	}
+	if s.breachMinCount <= 0 {
+		s.breachMinCount = 1
+	}
+	return s
+}
+
+// Validate 先检查本地规则，通过后再查询泄露数据
+// 泄露查询失败时放行并记录警告，外部接口不可用不应阻止用户注册
+func (s *passwordStrengthService) Validate(ctx context.Context, pwd string, identities ...string) error {
+	if weakness, ok := s.rules.Check(pwd, identities...); !ok {
+		switch weakness {
+		case password.WeaknessTooShort:
+			return errors.ErrPasswordTooShort.WithData(map[string]interface{}{"min": s.rules.MinLength})
+		case password.WeaknessCommon:
+			return errors.ErrPasswordCommon
+		case password.WeaknessContainsIdentity:
+			return errors.ErrPasswordHasIdentity
+		default:
+			return errors.ErrPasswordWeak
+		}
+	}
+
+	if s.breach == nil {
+		return nil
+	}
+	count, err := s.breach.Count(ctx, pwd)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Warn("Password breach check failed, skipping", zap.Error(err))
+		return nil
+	}
+	if count >= s.breachMinCount {
+		return errors.ErrPasswordBreached
+	}
+	return nil
+}
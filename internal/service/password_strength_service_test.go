@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	stdErrors "errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"go.uber.org/zap"
+)
+
+func newTestStrengthService(breach config.PasswordBreach) PasswordStrengthService {
+	return NewPasswordStrengthService(&config.Config{Password: config.Password{Strength: config.PasswordStrength{
+		MinLength:      8,
+		MinEntropyBits: 36,
+		RejectCommon:   true,
+		Breach:         breach,
+	}}}, zap.NewNop())
+}
+
+func TestPasswordStrengthValidate(t *testing.T) {
+	s := newTestStrengthService(config.PasswordBreach{})
+	tests := []struct {
+		password string
+		want     *errors.AppError
+	}{
+		{"k9x2", errors.ErrPasswordTooShort},
+		{"qwerty123", errors.ErrPasswordCommon},
+		{"alice-k9x2", errors.ErrPasswordHasIdentity},
+		{"aaaaaaaaaa", errors.ErrPasswordWeak},
+		{"k9x2m4q7", nil},
+	}
+	for _, tt := range tests {
+		err := s.Validate(context.Background(), tt.password, "alice", "alice@example.com")
+		if tt.want == nil {
+			if err != nil {
+				t.Errorf("Validate(%q) = %v, want nil", tt.password, err)
+			}
+			continue
+		}
+		var appErr *errors.AppError
+		if !stdErrors.As(err, &appErr) || appErr.MessageID != tt.want.MessageID {
+			t.Errorf("Validate(%q) = %v, want %v", tt.password, err, tt.want)
+		}
+	}
+
+	// 长度不足时携带最小长度供翻译
+	var appErr *errors.AppError
+	if !stdErrors.As(s.Validate(context.Background(), "k9x2"), &appErr) || appErr.Data["min"] != 8 {
+		t.Errorf("too short data = %v, want min 8", appErr.Data)
+	}
+}
+
+func TestPasswordStrengthBreach(t *testing.T) {
+	sum := sha1.Sum([]byte("Xq7#pLm2vR"))
+	breached := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	available := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !available {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, "%s:3\r\n", breached[5:])
+	}))
+	defer server.Close()
+
+	s := newTestStrengthService(config.PasswordBreach{Enabled: true, URL: server.URL})
+	// AppError 按类型比较，这里比较消息 ID 区分具体原因
+	var appErr *errors.AppError
+	if err := s.Validate(context.Background(), "Xq7#pLm2vR"); !stdErrors.As(err, &appErr) || appErr.MessageID != errors.ErrPasswordBreached.MessageID {
+		t.Fatalf("breached password error = %v, want ErrPasswordBreached", err)
+	}
+	if err := s.Validate(context.Background(), "k9x2m4q7"); err != nil {
+		t.Fatalf("unlisted password rejected: %v", err)
+	}
+
+	// 接口不可用时放行
+	available = false
+	if err := s.Validate(context.Background(), "Xq7#pLm2vR"); err != nil {
+		t.Fatalf("breach check failure must fail open, got %v", err)
+	}
+}
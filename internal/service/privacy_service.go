@@ -0,0 +1,525 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/pool"
+	"github.com/hedeqiang/skeleton/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// TaskTypeUserExport 用户数据导出任务类型
+	TaskTypeUserExport = "user.export"
+
+	// DownloadPathPrefix 签名下载路由的路径前缀，对象 key 拼接在其后
+	DownloadPathPrefix = "/api/v1/downloads/"
+
+	// userExportPrefix 导出文件的存储前缀，按日期分目录便于清理
+	userExportPrefix = "exports/users/"
+	exportDateLayout = "20060102"
+
+	defaultExportTTL           = 24 * time.Hour
+	defaultExportWorkers       = 2
+	defaultExportQueueSize     = 16
+	defaultExportPerUser       = 2
+	defaultDeletionGracePeriod = 30 * 24 * time.Hour
+	erasureBatchSize           = 100
+)
+
+// UserDataSection 保存用户数据的一部分，导出和彻底删除时按注册顺序处理
+// 新增保存用户数据的表时实现该接口并在 ProvideUserDataSections 中注册，导出文件和彻底删除随之覆盖该表
+type UserDataSection interface {
+	// Name 导出文件中的文件名（不含扩展名）
+	Name() string
+	// Export 返回写入导出文件的数据，nil 表示该部分没有需要导出的数据
+	Export(ctx context.Context, user *model.User) (interface{}, error)
+	// Erase 删除或匿名化用户数据，所有部分在同一事务中执行
+	Erase(ctx context.Context, user *model.User) error
+}
+
+// UserDataSections 按注册顺序处理的用户数据，引用用户的部分应排在用户资料之前
+type UserDataSections []UserDataSection
+
+// PrivacyService 用户数据导出与账户删除服务接口
+type PrivacyService interface {
+	// StartExport 创建后台任务异步导出用户的全部数据，完成后任务的 result_url 为签名下载地址
+	StartExport(ctx context.Context, userID uint) (*model.TaskResponse, error)
+	// RequestDeletion 申请删除账户，宽限期后彻底删除，重复申请返回已有的删除时间
+	RequestDeletion(ctx context.Context, userID uint) (*model.UserDeletion, error)
+	// CancelDeletion 在宽限期内撤销删除申请
+	CancelDeletion(ctx context.Context, userID uint) error
+	// EraseDue 彻底删除宽限期已过的账户，返回删除的账户数
+	EraseDue(ctx context.Context) (int, error)
+	// PurgeExpiredExports 删除下载地址已过期的导出文件，返回删除的文件数
+	PurgeExpiredExports(ctx context.Context) (int, error)
+}
+
+// ExportPool 执行用户数据导出的有界工作池，应用关闭时等待执行中的导出结束
+type ExportPool struct {
+	*pool.Pool
+}
+
+// NewExportPool 按 privacy.export_workers 和 export_queue_size 创建导出工作池，导出 panic 时记录日志，不影响其他导出
+func NewExportPool(cfg *config.Privacy, logger *zap.Logger) ExportPool {
+	workers := cfg.ExportWorkers
+	if workers <= 0 {
+		workers = defaultExportWorkers
+	}
+	queueSize := cfg.ExportQueueSize
+	if queueSize <= 0 {
+		queueSize = defaultExportQueueSize
+	}
+
+	return ExportPool{Pool: pool.New(pool.Config{
+		Name:      "user_export",
+		Workers:   workers,
+		QueueSize: queueSize,
+	}, pool.WithPanicHandler(func(recovered interface{}, stack []byte) {
+		logger.Error("User data export panicked",
+			zap.Any("panic", recovered),
+			zap.ByteString("stack", stack),
+		)
+	}))}
+}
+
+// privacyService 用户数据导出与账户删除服务实现
+type privacyService struct {
+	userRepo repository.UserRepository
+	sections UserDataSections
+	tasks    TaskService
+	storage  storage.Storage
+	signer   *storage.URLSigner
+	tx       *database.TxManager
+	events   *UserEvents
+	exports  ExportPool
+	clock    clock.Clock
+	logger   *zap.Logger
+
+	exportTTL     time.Duration
+	gracePeriod   time.Duration
+	exportPerUser int
+
+	// exporting 各用户排队和执行中的导出数
+	mu        sync.Mutex
+	exporting map[uint]int
+}
+
+// NewPrivacyService 创建用户数据导出与账户删除服务实例
+// signer 为 nil（未配置 storage.signing_secret）时不能导出数据，账户删除不受影响；
+// events 为 nil 时彻底删除后不发布 user.erased 事件；导出在 exports 工作池中执行
+func NewPrivacyService(
+	userRepo repository.UserRepository,
+	sections UserDataSections,
+	tasks TaskService,
+	store storage.Storage,
+	signer *storage.URLSigner,
+	tx *database.TxManager,
+	events *UserEvents,
+	exports ExportPool,
+	clk clock.Clock,
+	cfg *config.Privacy,
+	logger *zap.Logger,
+) PrivacyService {
+	s := &privacyService{
+		userRepo:      userRepo,
+		sections:      sections,
+		tasks:         tasks,
+		storage:       store,
+		signer:        signer,
+		tx:            tx,
+		events:        events,
+		exports:       exports,
+		clock:         clk,
+		logger:        logger,
+		exportTTL:     cfg.ExportTTL,
+		gracePeriod:   cfg.DeletionGracePeriod,
+		exportPerUser: cfg.ExportPerUser,
+		exporting:     make(map[uint]int),
+	}
+	if s.exportTTL <= 0 {
+		s.exportTTL = defaultExportTTL
+	}
+	if s.gracePeriod <= 0 {
+		s.gracePeriod = defaultDeletionGracePeriod
+	}
+	if s.exportPerUser <= 0 {
+		s.exportPerUser = defaultExportPerUser
+	}
+	return s
+}
+
+// StartExport 创建导出任务并提交到导出工作池
+// 同一用户排队和执行中的导出达到 export_per_user 时返回 ErrExportInProgress；
+// 队列已满时等待空位直到请求的 ctx 结束
+func (s *privacyService) StartExport(ctx context.Context, userID uint) (*model.TaskResponse, error) {
+	if s.signer == nil {
+		return nil, errors.ErrExportUnavailable
+	}
+	if !s.acquireExport(userID) {
+		return nil, errors.ErrExportInProgress
+	}
+	submitted := false
+	defer func() {
+		if !submitted {
+			s.releaseExport(userID)
+		}
+	}()
+
+	if _, err := getUser(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+
+	task, err := s.tasks.CreateTask(ctx, TaskTypeUserExport, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.exports.Submit(ctx, func() {
+		defer s.releaseExport(userID)
+		// 导出在请求返回后执行，不继承请求的 ctx
+		if err := s.export(context.Background(), userID, task.ID); err != nil {
+			s.logger.Error("User data export failed", zap.Uint("user_id", userID), zap.String("task_id", task.ID), zap.Error(err))
+		}
+	})
+	if err != nil {
+		_ = s.tasks.FailTask(context.Background(), task.ID, err)
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to schedule export")
+	}
+	submitted = true
+	return task, nil
+}
+
+// acquireExport 占用用户的一个导出名额，达到上限时返回 false
+func (s *privacyService) acquireExport(userID uint) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.exporting[userID] >= s.exportPerUser {
+		return false
+	}
+	s.exporting[userID]++
+	return true
+}
+
+// releaseExport 归还用户的导出名额
+func (s *privacyService) releaseExport(userID uint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.exporting[userID] <= 1 {
+		delete(s.exporting, userID)
+		return
+	}
+	s.exporting[userID]--
+}
+
+// export 将各部分数据写入 ZIP 并上传到存储
+// 任务只记录文件的 key，查询任务时才签发下载地址，任务记录中不保存签名
+func (s *privacyService) export(ctx context.Context, userID uint, taskID string) error {
+	if err := s.tasks.StartTask(ctx, taskID); err != nil {
+		return err
+	}
+
+	key, err := s.writeExport(ctx, userID, taskID)
+	if err != nil {
+		_ = s.tasks.FailTask(ctx, taskID, err)
+		return err
+	}
+	return s.tasks.CompleteTaskWithDownload(ctx, taskID, key)
+}
+
+// writeExport 边生成 ZIP 边写入存储并返回文件的 key，导出文件不在内存中完整缓存
+func (s *privacyService) writeExport(ctx context.Context, userID uint, taskID string) (string, error) {
+	user, err := getUser(ctx, s.userRepo, userID)
+	if err != nil {
+		return "", err
+	}
+
+	now := s.clock.Now()
+	key := fmt.Sprintf("%s%s/%d-%s.zip", userExportPrefix, now.Format(exportDateLayout), userID, taskID)
+
+	reader, writer := io.Pipe()
+	stored := make(chan error, 1)
+	go func() {
+		_, err := s.storage.Put(ctx, key, reader)
+		// 写入失败时让生成 ZIP 的一方停止
+		reader.CloseWithError(err)
+		stored <- err
+	}()
+
+	err = s.writeArchive(ctx, writer, user, now)
+	writer.CloseWithError(err)
+	if storeErr := <-stored; err == nil && storeErr != nil {
+		err = fmt.Errorf("failed to store export: %w", storeErr)
+	}
+	if err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// writeArchive 将各部分数据和 manifest.json 写入 ZIP
+func (s *privacyService) writeArchive(ctx context.Context, w io.Writer, user *model.User, now time.Time) error {
+	archive := zip.NewWriter(w)
+
+	names := make([]string, 0, len(s.sections))
+	for _, section := range s.sections {
+		data, err := section.Export(ctx, user)
+		if err != nil {
+			return fmt.Errorf("failed to export %s: %w", section.Name(), err)
+		}
+		if data == nil {
+			continue
+		}
+		if err := writeZipJSON(archive, section.Name()+".json", data); err != nil {
+			return err
+		}
+		names = append(names, section.Name())
+	}
+
+	manifest := map[string]interface{}{
+		"user_id":      user.ID,
+		"generated_at": now,
+		"sections":     names,
+	}
+	if err := writeZipJSON(archive, "manifest.json", manifest); err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish export archive: %w", err)
+	}
+	return nil
+}
+
+// writeZipJSON 以缩进 JSON 写入 ZIP 中的一个文件
+func writeZipJSON(archive *zip.Writer, name string, data interface{}) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return nil
+}
+
+// RequestDeletion 申请删除账户
+func (s *privacyService) RequestDeletion(ctx context.Context, userID uint) (*model.UserDeletion, error) {
+	user, err := getUser(ctx, s.userRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionScheduledAt != nil {
+		return &model.UserDeletion{UserID: userID, ScheduledAt: *user.DeletionScheduledAt}, nil
+	}
+
+	at := s.clock.Now().Add(s.gracePeriod)
+	if err := s.userRepo.SetDeletionSchedule(ctx, userID, &at); err != nil {
+		return nil, err
+	}
+	return &model.UserDeletion{UserID: userID, ScheduledAt: at}, nil
+}
+
+// CancelDeletion 撤销删除申请
+func (s *privacyService) CancelDeletion(ctx context.Context, userID uint) error {
+	user, err := getUser(ctx, s.userRepo, userID)
+	if err != nil {
+		return err
+	}
+	if user.DeletionScheduledAt == nil {
+		return errors.ErrDeletionNotRequested
+	}
+	return s.userRepo.SetDeletionSchedule(ctx, userID, nil)
+}
+
+// EraseDue 分批彻底删除到期的账户，单个账户失败时记录错误并继续处理其余账户
+func (s *privacyService) EraseDue(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+	erased := 0
+	var failed []error
+	var afterID uint
+
+	for {
+		// 失败的账户仍然到期，按 ID 翻页避免本轮重复处理
+		users, err := s.userRepo.ListDeletionDue(ctx, now, afterID, erasureBatchSize)
+		if err != nil {
+			return erased, err
+		}
+
+		for _, user := range users {
+			afterID = user.ID
+			if err := s.erase(ctx, user); err != nil {
+				s.logger.Error("Failed to erase user", zap.Uint("user_id", user.ID), zap.Error(err))
+				failed = append(failed, err)
+				continue
+			}
+			erased++
+		}
+		if len(users) < erasureBatchSize {
+			break
+		}
+	}
+
+	if len(failed) > 0 {
+		return erased, errors.Wrap(stdErrors.Join(failed...), errors.ErrorTypeDatabase, fmt.Sprintf("failed to erase %d users", len(failed)))
+	}
+	return erased, nil
+}
+
+// erase 在同一事务中删除用户的全部数据，提交后发布 user.erased 事件
+// 批量删除与用户自身的写入可能死锁，冲突时重新执行整个事务
+func (s *privacyService) erase(ctx context.Context, user *model.User) error {
+	err := s.tx.WithRetryableTx(ctx, func(ctx context.Context) error {
+		for _, section := range s.sections {
+			if err := section.Erase(ctx, user); err != nil {
+				return fmt.Errorf("failed to erase %s: %w", section.Name(), err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.logger.Info("User erased", zap.Uint("user_id", user.ID))
+	s.events.Publish(ctx, model.EventUserErased, user.ID)
+	return nil
+}
+
+// PurgeExpiredExports 按日期目录删除超过保留时长的导出文件
+func (s *privacyService) PurgeExpiredExports(ctx context.Context) (int, error) {
+	keys, err := s.storage.List(ctx, userExportPrefix)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrorTypeInternal, "failed to list exports")
+	}
+
+	// 目录日期是导出当天，当天最后导出的文件在次日零点加有效期后过期
+	cutoff := s.clock.Now().Add(-s.exportTTL).AddDate(0, 0, -1).Format(exportDateLayout)
+	deleted := 0
+	for _, key := range keys {
+		date, _, ok := strings.Cut(strings.TrimPrefix(key, userExportPrefix), "/")
+		if !ok || date > cutoff {
+			continue
+		}
+		if err := s.storage.Delete(ctx, key); err != nil {
+			return deleted, errors.Wrap(err, errors.ErrorTypeInternal, "failed to delete export")
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// userProfileSection 用户资料，彻底删除时删除 users 表中的记录
+type userProfileSection struct {
+	userRepo repository.UserRepository
+}
+
+// NewUserProfileSection 创建用户资料数据部分
+func NewUserProfileSection(userRepo repository.UserRepository) UserDataSection {
+	return &userProfileSection{userRepo: userRepo}
+}
+
+func (s *userProfileSection) Name() string { return "profile" }
+
+// Export 导出用户记录，密码哈希不导出
+func (s *userProfileSection) Export(ctx context.Context, user *model.User) (interface{}, error) {
+	return user, nil
+}
+
+func (s *userProfileSection) Erase(ctx context.Context, user *model.User) error {
+	return s.userRepo.HardDelete(ctx, user.ID)
+}
+
+// auditReferenceSection 其他记录中指向该用户的创建人、最后修改人，彻底删除时清零
+type auditReferenceSection struct {
+	userRepo repository.UserRepository
+}
+
+// NewAuditReferenceSection 创建审计字段引用数据部分
+func NewAuditReferenceSection(userRepo repository.UserRepository) UserDataSection {
+	return &auditReferenceSection{userRepo: userRepo}
+}
+
+func (s *auditReferenceSection) Name() string { return "audit_references" }
+
+// Export 审计字段只是其他记录的元数据，不导出
+func (s *auditReferenceSection) Export(ctx context.Context, user *model.User) (interface{}, error) {
+	return nil, nil
+}
+
+func (s *auditReferenceSection) Erase(ctx context.Context, user *model.User) error {
+	_, err := s.userRepo.ClearAuditReferences(ctx, user.ID)
+	return err
+}
+
+// userAuditLogSection 用户账户的管理操作记录，彻底删除时删除该用户的记录，并将其作为操作人的记录清零
+type userAuditLogSection struct {
+	auditRepo repository.UserAuditLogRepository
+}
+
+// NewUserAuditLogSection 创建用户审计日志数据部分
+func NewUserAuditLogSection(auditRepo repository.UserAuditLogRepository) UserDataSection {
+	return &userAuditLogSection{auditRepo: auditRepo}
+}
+
+func (s *userAuditLogSection) Name() string { return "audit_logs" }
+
+// Export 导出针对该用户的管理操作记录
+func (s *userAuditLogSection) Export(ctx context.Context, user *model.User) (interface{}, error) {
+	logs, _, err := s.auditRepo.ListByUser(ctx, user.ID, 0, 0)
+	if err != nil || len(logs) == 0 {
+		return nil, err
+	}
+	return logs, nil
+}
+
+func (s *userAuditLogSection) Erase(ctx context.Context, user *model.User) error {
+	if err := s.auditRepo.DeleteByUser(ctx, user.ID); err != nil {
+		return err
+	}
+	_, err := s.auditRepo.ClearActor(ctx, user.ID)
+	return err
+}
+
+// userIdentitySection 用户关联的外部账号，彻底删除时删除关联和保存的外部令牌
+type userIdentitySection struct {
+	repo repository.UserIdentityRepository
+}
+
+// NewUserIdentitySection 创建外部账号关联数据部分
+func NewUserIdentitySection(repo repository.UserIdentityRepository) UserDataSection {
+	return &userIdentitySection{repo: repo}
+}
+
+func (s *userIdentitySection) Name() string { return "identities" }
+
+// Export 导出关联的外部账号，外部令牌不导出
+func (s *userIdentitySection) Export(ctx context.Context, user *model.User) (interface{}, error) {
+	identities, err := s.repo.ListByUser(ctx, user.ID)
+	if err != nil || len(identities) == 0 {
+		return nil, err
+	}
+	return identities, nil
+}
+
+func (s *userIdentitySection) Erase(ctx context.Context, user *model.User) error {
+	return s.repo.DeleteByUser(ctx, user.ID)
+}
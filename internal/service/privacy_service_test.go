@@ -0,0 +1,286 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/storage"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// privacyUserRepository 内存中的用户仓储，只实现导出和删除申请用到的方法
+type privacyUserRepository struct {
+	repository.UserRepository
+	users map[uint]*model.User
+}
+
+func (r *privacyUserRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *privacyUserRepository) SetDeletionSchedule(ctx context.Context, id uint, at *time.Time) error {
+	r.users[id].DeletionScheduledAt = at
+	return nil
+}
+
+func newTestPrivacyService(t *testing.T, clk clock.Clock, signer *storage.URLSigner) (*privacyService, *privacyUserRepository, storage.Storage) {
+	t.Helper()
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	repo := &privacyUserRepository{users: map[uint]*model.User{
+		7: {ID: 7, Username: "alice", Email: "alice@example.com", Password: "hash"},
+	}}
+	sections := UserDataSections{NewAuditReferenceSection(repo), NewUserProfileSection(repo)}
+	svc := NewPrivacyService(repo, sections, &recordingTaskService{}, store, signer, nil, nil, newTestExportPool(t, &config.Privacy{}), clk, &config.Privacy{}, zap.NewNop())
+	return svc.(*privacyService), repo, store
+}
+
+// newTestExportPool 创建导出工作池，测试结束时等待导出完成
+func newTestExportPool(t *testing.T, cfg *config.Privacy) ExportPool {
+	t.Helper()
+	exports := NewExportPool(cfg, zap.NewNop())
+	t.Cleanup(func() { _ = exports.Shutdown(context.Background()) })
+	return exports
+}
+
+// exportTaskService 记录导出任务的最终状态，任务结束时通知 done
+type exportTaskService struct {
+	TaskService
+	mu     sync.Mutex
+	nextID int
+	status map[string]string
+	done   chan string
+}
+
+func (s *exportTaskService) CreateTask(ctx context.Context, taskType string, userID uint) (*model.TaskResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	id := fmt.Sprintf("task-%d", s.nextID)
+	s.status[id] = model.TaskStatusPending
+	return &model.TaskResponse{ID: id, Type: taskType, UserID: userID, Status: model.TaskStatusPending}, nil
+}
+
+func (s *exportTaskService) StartTask(ctx context.Context, id string) error {
+	return s.set(id, model.TaskStatusRunning)
+}
+
+func (s *exportTaskService) CompleteTaskWithDownload(ctx context.Context, id string, key string) error {
+	defer func() { s.done <- id }()
+	return s.set(id, model.TaskStatusSucceeded)
+}
+
+func (s *exportTaskService) FailTask(ctx context.Context, id string, taskErr error) error {
+	defer func() { s.done <- id }()
+	return s.set(id, model.TaskStatusFailed)
+}
+
+func (s *exportTaskService) set(id, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status[id] = status
+	return nil
+}
+
+// blockingSection 导出时等待 release，用于让导出保持执行中
+type blockingSection struct {
+	release chan struct{}
+}
+
+func (s *blockingSection) Name() string { return "blocking" }
+
+func (s *blockingSection) Export(ctx context.Context, user *model.User) (interface{}, error) {
+	<-s.release
+	return map[string]string{"ok": "true"}, nil
+}
+
+func (s *blockingSection) Erase(ctx context.Context, user *model.User) error { return nil }
+
+func TestStartExportLimitsPerUser(t *testing.T) {
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	repo := &privacyUserRepository{users: map[uint]*model.User{
+		7: {ID: 7, Username: "alice"},
+		8: {ID: 8, Username: "bob"},
+	}}
+	section := &blockingSection{release: make(chan struct{})}
+	tasks := &exportTaskService{status: map[string]string{}, done: make(chan string, 4)}
+	cfg := &config.Privacy{ExportWorkers: 2, ExportPerUser: 1}
+	svc := NewPrivacyService(repo, UserDataSections{section}, tasks, store, storage.NewURLSigner("test-secret"), nil, nil,
+		newTestExportPool(t, cfg), clock.NewFake(time.Now()), cfg, zap.NewNop())
+	ctx := context.Background()
+
+	first, err := svc.StartExport(ctx, 7)
+	if err != nil {
+		t.Fatalf("StartExport failed: %v", err)
+	}
+	// 同一用户的导出未结束时拒绝，其他用户不受影响
+	if _, err := svc.StartExport(ctx, 7); err != errors.ErrExportInProgress {
+		t.Fatalf("expected ErrExportInProgress, got %v", err)
+	}
+	second, err := svc.StartExport(ctx, 8)
+	if err != nil {
+		t.Fatalf("StartExport for another user failed: %v", err)
+	}
+
+	close(section.release)
+	finished := map[string]bool{<-tasks.done: true, <-tasks.done: true}
+	if !finished[first.ID] || !finished[second.ID] {
+		t.Fatalf("finished tasks = %v", finished)
+	}
+	tasks.mu.Lock()
+	status := tasks.status[first.ID]
+	tasks.mu.Unlock()
+	if status != model.TaskStatusSucceeded {
+		t.Fatalf("export status = %s", status)
+	}
+
+	// 导出结束后归还名额，任务完成后才归还，这里稍作等待
+	deadline := time.Now().Add(time.Second)
+	for {
+		_, err := svc.StartExport(ctx, 7)
+		if err == nil {
+			break
+		}
+		if err != errors.ErrExportInProgress || time.Now().After(deadline) {
+			t.Fatalf("StartExport after completion failed: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	<-tasks.done
+}
+
+func TestWriteExport(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	svc, _, store := newTestPrivacyService(t, clk, storage.NewURLSigner("test-secret"))
+
+	// 返回存储 key，下载地址在查询任务时签发
+	key, err := svc.writeExport(context.Background(), 7, "task-1")
+	if err != nil {
+		t.Fatalf("writeExport failed: %v", err)
+	}
+	if key != "exports/users/20250301/7-task-1.zip" {
+		t.Fatalf("key = %q", key)
+	}
+
+	reader, err := store.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("export not stored: %v", err)
+	}
+	defer reader.Close()
+	data, _ := io.ReadAll(reader)
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("export is not a zip: %v", err)
+	}
+
+	files := map[string]string{}
+	for _, f := range archive.File {
+		rc, _ := f.Open()
+		content, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+	// 审计引用没有导出数据，不生成文件
+	if len(files) != 2 || files["manifest.json"] == "" {
+		t.Fatalf("unexpected export files: %v", files)
+	}
+	profile := files["profile.json"]
+	if !strings.Contains(profile, `"alice@example.com"`) || strings.Contains(profile, "hash") {
+		t.Fatalf("unexpected profile export: %s", profile)
+	}
+}
+
+func TestStartExportWithoutSigner(t *testing.T) {
+	svc, _, _ := newTestPrivacyService(t, clock.Frozen(), nil)
+	if _, err := svc.StartExport(context.Background(), 7); err != errors.ErrExportUnavailable {
+		t.Fatalf("expected ErrExportUnavailable, got %v", err)
+	}
+}
+
+func TestRequestAndCancelDeletion(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	svc, repo, _ := newTestPrivacyService(t, clk, nil)
+	ctx := context.Background()
+
+	deletion, err := svc.RequestDeletion(ctx, 7)
+	if err != nil {
+		t.Fatalf("RequestDeletion failed: %v", err)
+	}
+	want := clk.Now().Add(defaultDeletionGracePeriod)
+	if !deletion.ScheduledAt.Equal(want) {
+		t.Fatalf("scheduled at %v, want %v", deletion.ScheduledAt, want)
+	}
+
+	// 重复申请不推迟删除时间
+	clk.Advance(time.Hour)
+	again, err := svc.RequestDeletion(ctx, 7)
+	if err != nil || !again.ScheduledAt.Equal(want) {
+		t.Fatalf("repeated request = %v, %v; want %v", again, err, want)
+	}
+
+	if err := svc.CancelDeletion(ctx, 7); err != nil {
+		t.Fatalf("CancelDeletion failed: %v", err)
+	}
+	if repo.users[7].DeletionScheduledAt != nil {
+		t.Fatal("deletion schedule should be cleared")
+	}
+	if err := svc.CancelDeletion(ctx, 7); err != errors.ErrDeletionNotRequested {
+		t.Fatalf("expected ErrDeletionNotRequested, got %v", err)
+	}
+
+	if _, err := svc.RequestDeletion(ctx, 404); err != errors.ErrUserNotFound {
+		t.Fatalf("expected ErrUserNotFound, got %v", err)
+	}
+}
+
+func TestPurgeExpiredExports(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 3, 10, 1, 0, 0, 0, time.UTC))
+	svc, _, store := newTestPrivacyService(t, clk, storage.NewURLSigner("test-secret"))
+	ctx := context.Background()
+
+	for _, key := range []string{
+		"exports/users/20250307/1-a.zip",
+		"exports/users/20250308/2-b.zip", // 3 月 8 日最后导出的文件 3 月 10 日零点过期
+		"exports/users/20250309/3-c.zip", // 3 月 9 日导出的文件可能还未过期
+	} {
+		if _, err := store.Put(ctx, key, strings.NewReader("zip")); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	purged, err := svc.PurgeExpiredExports(ctx)
+	if err != nil {
+		t.Fatalf("PurgeExpiredExports failed: %v", err)
+	}
+	if purged != 2 {
+		t.Fatalf("purged = %d, want 2", purged)
+	}
+	keys, _ := store.List(ctx, userExportPrefix)
+	if len(keys) != 1 || keys[0] != "exports/users/20250309/3-c.zip" {
+		t.Fatalf("remaining exports = %v", keys)
+	}
+}
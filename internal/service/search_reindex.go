@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/search"
+
+	"go.uber.org/zap"
+)
+
+// TaskTypeSearchReindex 重建搜索索引的后台任务类型
+const TaskTypeSearchReindex = "search.reindex"
+
+// defaultReindexBatchSize 未配置时重建索引每批处理的文档数
+const defaultReindexBatchSize = 500
+
+// ReindexResult 重建索引结果
+type ReindexResult struct {
+	Index    string        `json:"index"`           // 别名当前指向的新物理索引
+	Indexed  int           `json:"indexed"`         // 写入的文档数，包含增量同步的文档
+	Removed  []string      `json:"removed_indices"` // 切换后删除的旧物理索引
+	Duration time.Duration `json:"duration"`
+}
+
+// StartReindex 创建后台任务并异步重建索引，进度通过任务接口查询
+// 同一进程内同时只允许一个重建任务
+func (s *searchService) StartReindex(ctx context.Context, name string) (*model.TaskResponse, error) {
+	def, err := s.indexDefinition(name)
+	if err != nil {
+		return nil, err
+	}
+	if !s.reindexing.CompareAndSwap(false, true) {
+		return nil, errors.ErrReindexRunning
+	}
+
+	task, err := s.tasks.CreateTask(ctx, TaskTypeSearchReindex, 0)
+	if err != nil {
+		s.reindexing.Store(false)
+		return nil, err
+	}
+
+	go func() {
+		defer s.reindexing.Store(false)
+		// 重建耗时远超请求生命周期，不继承请求的 ctx
+		if _, err := s.reindex(context.Background(), def, task.ID); err != nil {
+			s.logger.Error("Search reindex failed", zap.String("index", name), zap.String("task_id", task.ID), zap.Error(err))
+		}
+	}()
+	return task, nil
+}
+
+// Reindex 同步重建索引，taskID 非空时上报任务进度
+func (s *searchService) Reindex(ctx context.Context, name, taskID string) (*ReindexResult, error) {
+	def, err := s.indexDefinition(name)
+	if err != nil {
+		return nil, err
+	}
+	if !s.reindexing.CompareAndSwap(false, true) {
+		return nil, errors.ErrReindexRunning
+	}
+	defer s.reindexing.Store(false)
+
+	return s.reindex(ctx, def, taskID)
+}
+
+// indexDefinition 根据逻辑名称查找索引定义
+func (s *searchService) indexDefinition(name string) (*search.IndexDefinition, error) {
+	if name == UserIndex.Name {
+		return UserIndex, nil
+	}
+	return nil, errors.ErrUnknownIndex
+}
+
+// reindex 将全部数据写入新版本索引后切换别名并删除旧索引
+// 全量写入期间的变更由事件写入旧索引，切换前按更新时间增量同步一次到新索引
+func (s *searchService) reindex(ctx context.Context, def *search.IndexDefinition, taskID string) (*ReindexResult, error) {
+	log := s.logger.With(zap.String("index", def.Name), zap.String("task_id", taskID))
+	start := time.Now()
+	// 数据库时间精度可能低于本地时钟，回退一秒避免漏掉边界上的变更
+	since := start.Add(-time.Second)
+
+	if taskID != "" {
+		if err := s.tasks.StartTask(ctx, taskID); err != nil {
+			return nil, err
+		}
+	}
+
+	index, err := s.indices.CreateVersion(ctx, def)
+	if err != nil {
+		return nil, s.failReindex(ctx, taskID, "", errors.Wrap(err, errors.ErrorTypeExternal, "failed to create index"))
+	}
+	log = log.With(zap.String("physical_index", index))
+	log.Info("Search reindex started")
+
+	result := &ReindexResult{Index: index}
+	if err := s.copyUsers(ctx, index, taskID, result); err != nil {
+		return nil, s.failReindex(ctx, taskID, index, err)
+	}
+	if err := s.syncChangedUsers(ctx, index, since, result); err != nil {
+		return nil, s.failReindex(ctx, taskID, index, err)
+	}
+
+	old, err := s.indices.Swap(ctx, def, index)
+	if err != nil {
+		return nil, s.failReindex(ctx, taskID, index, errors.Wrap(err, errors.ErrorTypeExternal, "failed to swap index alias"))
+	}
+	for _, name := range old {
+		if err := s.indices.Engine().DeleteIndex(ctx, name); err != nil {
+			log.Warn("Failed to delete previous index", zap.String("previous_index", name), zap.Error(err))
+			continue
+		}
+		result.Removed = append(result.Removed, name)
+	}
+	result.Duration = time.Since(start)
+
+	if taskID != "" {
+		if err := s.tasks.CompleteTask(ctx, taskID, ""); err != nil {
+			log.Warn("Failed to complete reindex task", zap.Error(err))
+		}
+	}
+	log.Info("Search reindex completed",
+		zap.Int("indexed", result.Indexed),
+		zap.Strings("removed_indices", result.Removed),
+		zap.Duration("duration", result.Duration),
+	)
+	return result, nil
+}
+
+// copyUsers 按 ID 分批读取全部用户写入 index，按批上报进度（完成切换前最多 99%）
+func (s *searchService) copyUsers(ctx context.Context, index, taskID string, result *ReindexResult) error {
+	total, err := s.userRepo.Count(ctx)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to count users")
+	}
+
+	throttle := newThrottle(s.reindexRate)
+	reported := -1
+	var afterID uint
+	for {
+		users, err := s.userRepo.ListAfterID(ctx, afterID, s.reindexBatchSize)
+		if err != nil {
+			return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to list users")
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		if err := throttle.wait(ctx, len(users)); err != nil {
+			return err
+		}
+		docs := make([]search.Document, len(users))
+		for i, user := range users {
+			docs[i] = search.Document{ID: userDocumentID(user.ID), Source: NewUserDocument(user)}
+		}
+		if err := s.indices.Engine().Bulk(ctx, index, docs); err != nil {
+			return errors.Wrap(err, errors.ErrorTypeExternal, "failed to bulk index users")
+		}
+		result.Indexed += len(users)
+		afterID = users[len(users)-1].ID
+
+		if progress := reindexProgress(result.Indexed, total); taskID != "" && progress != reported {
+			if err := s.tasks.UpdateProgress(ctx, taskID, progress); err != nil {
+				s.logger.Warn("Failed to update reindex progress", zap.String("task_id", taskID), zap.Error(err))
+			}
+			reported = progress
+		}
+	}
+}
+
+// syncChangedUsers 将 since 之后变更的用户同步到 index，已删除的用户从 index 移除
+func (s *searchService) syncChangedUsers(ctx context.Context, index string, since time.Time, result *ReindexResult) error {
+	var afterID uint
+	for {
+		users, err := s.userRepo.ListChangedSince(ctx, since, afterID, s.reindexBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		docs := make([]search.Document, 0, len(users))
+		for _, user := range users {
+			if user.DeletedAt.Valid {
+				if err := s.indices.Engine().Delete(ctx, index, userDocumentID(user.ID)); err != nil {
+					return errors.Wrap(err, errors.ErrorTypeExternal, "failed to remove deleted user")
+				}
+				continue
+			}
+			docs = append(docs, search.Document{ID: userDocumentID(user.ID), Source: NewUserDocument(user)})
+		}
+		if err := s.indices.Engine().Bulk(ctx, index, docs); err != nil {
+			return errors.Wrap(err, errors.ErrorTypeExternal, "failed to bulk index changed users")
+		}
+		result.Indexed += len(docs)
+		afterID = users[len(users)-1].ID
+	}
+}
+
+// failReindex 删除未切换的新索引并将任务标记为失败，返回原错误
+func (s *searchService) failReindex(ctx context.Context, taskID, index string, err error) error {
+	// 原 ctx 可能已取消，清理使用独立的超时
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	if index != "" {
+		if delErr := s.indices.Engine().DeleteIndex(cleanupCtx, index); delErr != nil {
+			s.logger.Warn("Failed to delete abandoned index", zap.String("physical_index", index), zap.Error(delErr))
+		}
+	}
+	if taskID != "" {
+		if taskErr := s.tasks.FailTask(cleanupCtx, taskID, err); taskErr != nil {
+			s.logger.Warn("Failed to mark reindex task as failed", zap.String("task_id", taskID), zap.Error(taskErr))
+		}
+	}
+	return err
+}
+
+// reindexProgress 全量写入的完成百分比，切换别名前最多报告 99
+func reindexProgress(indexed int, total int64) int {
+	if total <= 0 {
+		return 99
+	}
+	return min(int(int64(indexed)*100/total), 99)
+}
+
+// throttle 按每秒文档数限制写入速率，rate <= 0 时不限制
+type throttle struct {
+	rate  int
+	start time.Time
+	sent  int
+}
+
+func newThrottle(rate int) *throttle {
+	return &throttle{rate: rate, start: time.Now()}
+}
+
+// wait 写入 n 个文档前等待，使累计速率不超过 rate
+func (t *throttle) wait(ctx context.Context, n int) error {
+	if t.rate <= 0 {
+		return nil
+	}
+	delay := time.Until(t.start.Add(time.Duration(t.sent) * time.Second / time.Duration(t.rate)))
+	t.sent += n
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/search"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// memorySearchEngine 内存中的搜索引擎，记录各物理索引的文档和别名指向
+type memorySearchEngine struct {
+	indices map[string]map[string]interface{}
+	aliases map[string]string
+	deleted []string
+}
+
+func newMemorySearchEngine() *memorySearchEngine {
+	return &memorySearchEngine{indices: map[string]map[string]interface{}{}, aliases: map[string]string{}}
+}
+
+func (e *memorySearchEngine) Ping(ctx context.Context) error { return nil }
+
+func (e *memorySearchEngine) IndexExists(ctx context.Context, index string) (bool, error) {
+	_, isIndex := e.indices[index]
+	_, isAlias := e.aliases[index]
+	return isIndex || isAlias, nil
+}
+
+func (e *memorySearchEngine) CreateIndex(ctx context.Context, index string, def *search.IndexDefinition) error {
+	e.indices[index] = map[string]interface{}{}
+	return nil
+}
+
+func (e *memorySearchEngine) DeleteIndex(ctx context.Context, index string) error {
+	delete(e.indices, index)
+	e.deleted = append(e.deleted, index)
+	return nil
+}
+
+func (e *memorySearchEngine) AliasIndices(ctx context.Context, alias string) ([]string, error) {
+	if index, ok := e.aliases[alias]; ok {
+		return []string{index}, nil
+	}
+	return nil, nil
+}
+
+func (e *memorySearchEngine) SwapAlias(ctx context.Context, alias, index string) error {
+	e.aliases[alias] = index
+	return nil
+}
+
+func (e *memorySearchEngine) resolve(index string) map[string]interface{} {
+	if target, ok := e.aliases[index]; ok {
+		index = target
+	}
+	return e.indices[index]
+}
+
+func (e *memorySearchEngine) Index(ctx context.Context, index, id string, doc interface{}) error {
+	e.resolve(index)[id] = doc
+	return nil
+}
+
+func (e *memorySearchEngine) Bulk(ctx context.Context, index string, docs []search.Document) error {
+	for _, doc := range docs {
+		e.resolve(index)[doc.ID] = doc.Source
+	}
+	return nil
+}
+
+func (e *memorySearchEngine) Delete(ctx context.Context, index, id string) error {
+	delete(e.resolve(index), id)
+	return nil
+}
+
+func (e *memorySearchEngine) Search(ctx context.Context, index string, req *search.Request) (*search.Result, error) {
+	return &search.Result{}, nil
+}
+
+// memoryUserRepository 内存中的用户仓储，只实现重建索引用到的方法
+type memoryUserRepository struct {
+	repository.UserRepository
+	users []*model.User
+	// changed ListChangedSince 返回的用户
+	changed []*model.User
+}
+
+func (r *memoryUserRepository) Count(ctx context.Context) (int64, error) {
+	return int64(len(r.users)), nil
+}
+
+func (r *memoryUserRepository) ListAfterID(ctx context.Context, afterID uint, limit int) ([]*model.User, error) {
+	return after(r.users, afterID, limit), nil
+}
+
+func (r *memoryUserRepository) ListChangedSince(ctx context.Context, since time.Time, afterID uint, limit int) ([]*model.User, error) {
+	return after(r.changed, afterID, limit), nil
+}
+
+func after(users []*model.User, afterID uint, limit int) []*model.User {
+	var page []*model.User
+	for _, u := range users {
+		if u.ID > afterID && len(page) < limit {
+			page = append(page, u)
+		}
+	}
+	return page
+}
+
+// recordingTaskService 记录任务进度上报
+type recordingTaskService struct {
+	TaskService
+	progress []int
+	status   string
+}
+
+func (s *recordingTaskService) StartTask(ctx context.Context, id string) error {
+	s.status = model.TaskStatusRunning
+	return nil
+}
+
+func (s *recordingTaskService) UpdateProgress(ctx context.Context, id string, progress int) error {
+	s.progress = append(s.progress, progress)
+	return nil
+}
+
+func (s *recordingTaskService) CompleteTask(ctx context.Context, id string, resultURL string) error {
+	s.status = model.TaskStatusSucceeded
+	return nil
+}
+
+func (s *recordingTaskService) FailTask(ctx context.Context, id string, taskErr error) error {
+	s.status = model.TaskStatusFailed
+	return nil
+}
+
+func TestReindexSwapsAliasToNewIndex(t *testing.T) {
+	engine := newMemorySearchEngine()
+	engine.indices["app_users_old"] = map[string]interface{}{"99": "stale"}
+	engine.aliases["app_users"] = "app_users_old"
+
+	repo := &memoryUserRepository{
+		users: []*model.User{
+			{ID: 1, Username: "alice"},
+			{ID: 2, Username: "bob"},
+			{ID: 3, Username: "carol"},
+			{ID: 4, Username: "dave"},
+			{ID: 5, Username: "erin"},
+		},
+		changed: []*model.User{
+			{ID: 2, Username: "bobby"},
+			{ID: 4, Username: "dave", DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}},
+		},
+	}
+	tasks := &recordingTaskService{}
+
+	svc, err := NewSearchService(search.NewManager(engine, "app_"), repo, tasks, &config.Search{ReindexBatchSize: 2}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewSearchService failed: %v", err)
+	}
+
+	result, err := svc.Reindex(context.Background(), "users", "task-1")
+	if err != nil {
+		t.Fatalf("Reindex failed: %v", err)
+	}
+
+	if engine.aliases["app_users"] != result.Index || result.Index == "app_users_old" {
+		t.Errorf("alias points to %s, result index %s", engine.aliases["app_users"], result.Index)
+	}
+	if _, ok := engine.indices["app_users_old"]; ok || len(result.Removed) != 1 {
+		t.Errorf("old index not removed, removed = %v", result.Removed)
+	}
+
+	docs := engine.indices[result.Index]
+	ids := make([]string, 0, len(docs))
+	for id := range docs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	if len(ids) != 4 || ids[0] != "1" || ids[3] != "5" {
+		t.Errorf("indexed ids = %v, want deleted user 4 removed", ids)
+	}
+	if doc := docs["2"].(*model.UserDocument); doc.Username != "bobby" {
+		t.Errorf("changed user not synced, username = %s", doc.Username)
+	}
+
+	if want := []int{40, 80, 99}; len(tasks.progress) != len(want) || tasks.progress[0] != want[0] || tasks.progress[2] != want[2] {
+		t.Errorf("progress = %v, want %v", tasks.progress, want)
+	}
+	if tasks.status != model.TaskStatusSucceeded {
+		t.Errorf("task status = %s", tasks.status)
+	}
+}
+
+func TestReindexUnknownIndex(t *testing.T) {
+	svc, err := NewSearchService(search.NewManager(newMemorySearchEngine(), ""), &memoryUserRepository{}, &recordingTaskService{}, &config.Search{}, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewSearchService failed: %v", err)
+	}
+	if _, err := svc.Reindex(context.Background(), "articles", ""); err == nil {
+		t.Error("expected error for unknown index")
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	th := newThrottle(1000)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := th.wait(context.Background(), 50); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 前 100 个文档按 1000/s 需要 100ms
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("throttle waited %s, want about 100ms", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	slow := newThrottle(1)
+	slow.wait(ctx, 10)
+	if err := slow.wait(ctx, 10); err == nil {
+		t.Error("expected context error while throttled")
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/i18n"
+	"github.com/hedeqiang/skeleton/pkg/search"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ensureIndicesTimeout 启动时创建索引的超时
+const ensureIndicesTimeout = 30 * time.Second
+
+// UserIndex 用户索引定义，username 和 email 同时保留 keyword 子字段用于精确匹配和排序
+var UserIndex = &search.IndexDefinition{
+	Name: "users",
+	Mappings: map[string]interface{}{
+		"dynamic": "strict",
+		"properties": map[string]interface{}{
+			"id": map[string]string{"type": "long"},
+			"username": map[string]interface{}{
+				"type":   "text",
+				"fields": map[string]interface{}{"keyword": map[string]string{"type": "keyword"}},
+			},
+			"email": map[string]interface{}{
+				"type":   "text",
+				"fields": map[string]interface{}{"keyword": map[string]string{"type": "keyword"}},
+			},
+			"status":     map[string]string{"type": "integer"},
+			"created_at": map[string]string{"type": "date"},
+			"updated_at": map[string]string{"type": "date"},
+		},
+	},
+}
+
+// SearchService 搜索服务接口
+// 索引由消费者根据用户领域事件异步更新，搜索结果相对数据库存在短暂延迟
+type SearchService interface {
+	SearchUsers(ctx context.Context, req *model.SearchUsersRequest) ([]*model.UserResponse, int64, error)
+	IndexUser(ctx context.Context, id uint) error
+	RemoveUser(ctx context.Context, id uint) error
+	StartReindex(ctx context.Context, name string) (*model.TaskResponse, error)
+	Reindex(ctx context.Context, name, taskID string) (*ReindexResult, error)
+}
+
+// searchService 搜索服务实现
+type searchService struct {
+	indices          *search.Manager
+	userRepo         repository.UserRepository
+	tasks            TaskService
+	logger           *zap.Logger
+	reindexBatchSize int
+	reindexRate      int
+	reindexing       atomic.Bool
+}
+
+// NewSearchService 创建搜索服务实例并确保索引存在，indices 为 nil（未启用搜索）时返回 nil
+func NewSearchService(indices *search.Manager, userRepo repository.UserRepository, tasks TaskService, cfg *config.Search, logger *zap.Logger) (SearchService, error) {
+	if indices == nil {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ensureIndicesTimeout)
+	defer cancel()
+	if err := indices.Ensure(ctx, UserIndex); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to ensure user index")
+	}
+
+	s := &searchService{
+		indices:          indices,
+		userRepo:         userRepo,
+		tasks:            tasks,
+		logger:           logger,
+		reindexBatchSize: cfg.ReindexBatchSize,
+		reindexRate:      cfg.ReindexRate,
+	}
+	if s.reindexBatchSize <= 0 {
+		s.reindexBatchSize = defaultReindexBatchSize
+	}
+	return s, nil
+}
+
+// SearchUsers 按用户名和邮箱搜索用户
+func (s *searchService) SearchUsers(ctx context.Context, req *model.SearchUsersRequest) ([]*model.UserResponse, int64, error) {
+	page, pageSize := req.Page, req.PageSize
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	query := &search.Request{
+		Query:  req.Query,
+		Fields: []string{"username^2", "email"},
+		From:   (page - 1) * pageSize,
+		Size:   pageSize,
+	}
+	if req.Query == "" {
+		query.Sort = []string{"-created_at"}
+	}
+	if req.Status != nil {
+		query.Filters = map[string]interface{}{"status": *req.Status}
+	}
+
+	result, err := s.indices.Engine().Search(ctx, s.indices.Alias(UserIndex.Name), query)
+	if err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrorTypeExternal, "failed to search users")
+	}
+
+	users := make([]*model.UserResponse, 0, len(result.Hits))
+	if err := result.Decode(&users); err != nil {
+		return nil, 0, errors.Wrap(err, errors.ErrorTypeInternal, "failed to decode user documents")
+	}
+
+	// 与 UserService 一致，请求指定了时区时时间按该时区输出
+	if locale, ok := i18n.LocaleFromContext(ctx); ok && locale.Location != nil {
+		for _, user := range users {
+			user.CreatedAt = locale.In(user.CreatedAt)
+			user.UpdatedAt = locale.In(user.UpdatedAt)
+		}
+	}
+	return users, result.Total, nil
+}
+
+// IndexUser 读取用户最新数据写入索引，用户已删除时从索引移除
+func (s *searchService) IndexUser(ctx context.Context, id uint) error {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return s.RemoveUser(ctx, id)
+		}
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get user")
+	}
+
+	if err := s.indices.Engine().Index(ctx, s.indices.Alias(UserIndex.Name), userDocumentID(id), NewUserDocument(user)); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeExternal, "failed to index user")
+	}
+	return nil
+}
+
+// RemoveUser 从索引移除用户
+func (s *searchService) RemoveUser(ctx context.Context, id uint) error {
+	if err := s.indices.Engine().Delete(ctx, s.indices.Alias(UserIndex.Name), userDocumentID(id)); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeExternal, "failed to remove user from index")
+	}
+	return nil
+}
+
+// NewUserDocument 将用户投影为索引文档，不包含密码等敏感字段
+func NewUserDocument(user *model.User) *model.UserDocument {
+	return &model.UserDocument{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Status:    user.Status,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+func userDocumentID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
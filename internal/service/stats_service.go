@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"strconv"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	statsKeyPrefix = "stats:daily:"
+	// statsCounterTTL 每日计数保留时长，计算任务漏跑时可以在保留期内补算
+	statsCounterTTL = 8 * 24 * time.Hour
+	// statsRecordTimeout 记录计数的超时时间，计数失败不影响业务
+	statsRecordTimeout = time.Second
+)
+
+// StatsService 每日统计服务接口
+// 登录次数、活跃用户和消息处理量按天计入 Redis，由 daily_stats_job 每天汇总写入 daily_stats 表，
+// 查询接口只读统计表，避免按需执行耗时的 COUNT 查询
+type StatsService interface {
+	// RecordLogin 记录一次登录，同时计入当天活跃用户
+	RecordLogin(ctx context.Context, userID uint)
+	// RecordMessageProcessed 记录一条消费者成功处理的消息
+	RecordMessageProcessed(ctx context.Context)
+	// ComputeDaily 计算 day 所在自然日的统计并写入统计表
+	ComputeDaily(ctx context.Context, day time.Time) (*model.DailyStats, error)
+	// ListDaily 返回 [from, to] 日期范围内已计算的统计
+	ListDaily(ctx context.Context, from, to time.Time) ([]*model.DailyStats, error)
+}
+
+// statsService 每日统计服务实现
+type statsService struct {
+	statsRepo repository.StatsRepository
+	userRepo  repository.UserRepository
+	redis     *redis.Client
+	clock     clock.Clock
+	logger    *zap.Logger
+}
+
+// NewStatsService 创建每日统计服务实例
+func NewStatsService(statsRepo repository.StatsRepository, userRepo repository.UserRepository, redisClient *redis.Client, clk clock.Clock, logger *zap.Logger) StatsService {
+	return &statsService{
+		statsRepo: statsRepo,
+		userRepo:  userRepo,
+		redis:     redisClient,
+		clock:     clk,
+		logger:    logger,
+	}
+}
+
+// RecordLogin 记录一次登录
+func (s *statsService) RecordLogin(ctx context.Context, userID uint) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statsRecordTimeout)
+	defer cancel()
+
+	date := s.clock.Now().Format(model.StatsDateLayout)
+	logins, active := statsKey(date, "logins"), statsKey(date, "active")
+
+	pipe := s.redis.TxPipeline()
+	pipe.Incr(ctx, logins)
+	pipe.PFAdd(ctx, active, strconv.FormatUint(uint64(userID), 10))
+	pipe.Expire(ctx, logins, statsCounterTTL)
+	pipe.Expire(ctx, active, statsCounterTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warn("Failed to record login stats", zap.Uint("user_id", userID), zap.Error(err))
+	}
+}
+
+// RecordMessageProcessed 记录一条成功处理的消息
+func (s *statsService) RecordMessageProcessed(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statsRecordTimeout)
+	defer cancel()
+
+	key := statsKey(s.clock.Now().Format(model.StatsDateLayout), "messages")
+	pipe := s.redis.TxPipeline()
+	pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, statsCounterTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Warn("Failed to record message stats", zap.Error(err))
+	}
+}
+
+// ComputeDaily 计算一天的统计，日期边界按 day 的时区划分
+func (s *statsService) ComputeDaily(ctx context.Context, day time.Time) (*model.DailyStats, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	date := start.Format(model.StatsDateLayout)
+
+	newUsers, err := s.userRepo.CountCreatedBetween(ctx, start, start.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+
+	logins, err := s.counter(ctx, statsKey(date, "logins"))
+	if err != nil {
+		return nil, err
+	}
+	active, err := s.redis.PFCount(ctx, statsKey(date, "active")).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to count active users")
+	}
+	messages, err := s.counter(ctx, statsKey(date, "messages"))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &model.DailyStats{
+		Date:              date,
+		NewUsers:          newUsers,
+		ActiveUsers:       active,
+		Logins:            logins,
+		MessagesProcessed: messages,
+		ComputedAt:        s.clock.Now(),
+	}
+	if err := s.statsRepo.Save(ctx, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListDaily 查询日期范围内的统计
+func (s *statsService) ListDaily(ctx context.Context, from, to time.Time) ([]*model.DailyStats, error) {
+	return s.statsRepo.ListDaily(ctx, from.Format(model.StatsDateLayout), to.Format(model.StatsDateLayout))
+}
+
+// counter 读取计数，不存在时为 0
+func (s *statsService) counter(ctx context.Context, key string) (int64, error) {
+	value, err := s.redis.Get(ctx, key).Int64()
+	if stdErrors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrorTypeInternal, "failed to read stats counter")
+	}
+	return value, nil
+}
+
+// statsKey 每日计数的 Redis 键，例如 stats:daily:2025-01-01:logins
+func statsKey(date, name string) string {
+	return statsKeyPrefix + date + ":" + name
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/idgen"
+	"github.com/hedeqiang/skeleton/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultTaskTTL 未配置时任务记录的保留时长
+	defaultTaskTTL = 7 * 24 * time.Hour
+	// defaultTaskDownloadTTL 未配置时查询任务签发的下载地址有效期
+	defaultTaskDownloadTTL = 15 * time.Minute
+)
+
+// TaskService 后台任务服务接口
+// 业务代码创建任务后将任务 ID 返回给客户端，由队列消费者在执行过程中上报进度
+// 用户发起的任务记录 userID，查询接口由授权策略限制为本人和管理员
+type TaskService interface {
+	CreateTask(ctx context.Context, taskType string, userID uint) (*model.TaskResponse, error)
+	GetTask(ctx context.Context, id string) (*model.TaskResponse, error)
+	TaskOwner(ctx context.Context, id string) (uint, bool, error)
+	StartTask(ctx context.Context, id string) error
+	UpdateProgress(ctx context.Context, id string, progress int) error
+	CompleteTask(ctx context.Context, id string, resultURL string) error
+	CompleteTaskWithDownload(ctx context.Context, id string, key string) error
+	FailTask(ctx context.Context, id string, taskErr error) error
+	CleanupExpired(ctx context.Context) (int64, error)
+}
+
+// taskService 后台任务服务实现
+type taskService struct {
+	taskRepo    repository.TaskRepository
+	idGenerator idgen.IDGenerator
+	signer      *storage.URLSigner
+	clock       clock.Clock
+	ttl         time.Duration
+	downloadTTL time.Duration
+}
+
+// NewTaskService 创建后台任务服务实例
+// signer 为 nil（未配置 storage.signing_secret）时查询结果不包含存储对象的下载地址
+func NewTaskService(taskRepo repository.TaskRepository, idGenerator idgen.IDGenerator, signer *storage.URLSigner, clk clock.Clock, cfg *config.Tasks) TaskService {
+	s := &taskService{
+		taskRepo:    taskRepo,
+		idGenerator: idGenerator,
+		signer:      signer,
+		clock:       clk,
+		ttl:         cfg.TTL,
+		downloadTTL: cfg.DownloadTTL,
+	}
+	if s.ttl <= 0 {
+		s.ttl = defaultTaskTTL
+	}
+	if s.downloadTTL <= 0 {
+		s.downloadTTL = defaultTaskDownloadTTL
+	}
+	return s
+}
+
+// CreateTask 创建待执行的任务，userID 为发起任务的用户，系统任务传 0
+func (s *taskService) CreateTask(ctx context.Context, taskType string, userID uint) (*model.TaskResponse, error) {
+	id, err := s.idGenerator.NextIDString()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to generate task id")
+	}
+
+	task := &model.Task{
+		ID:        id,
+		Type:      taskType,
+		UserID:    userID,
+		Status:    model.TaskStatusPending,
+		ExpiresAt: s.clock.Now().Add(s.ttl),
+	}
+	if err := s.taskRepo.Create(ctx, task); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to create task")
+	}
+
+	return s.toTaskResponse(task), nil
+}
+
+// GetTask 获取任务
+func (s *taskService) GetTask(ctx context.Context, id string) (*model.TaskResponse, error) {
+	task, err := s.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, s.wrapError(err, "failed to get task")
+	}
+	return s.toTaskResponse(task), nil
+}
+
+// TaskOwner 返回发起任务的用户，作为任务资源授权策略的 OwnerFunc
+// 任务不存在或为系统任务时返回 false，只有管理员可以查看
+func (s *taskService) TaskOwner(ctx context.Context, id string) (uint, bool, error) {
+	task, err := s.taskRepo.GetByID(ctx, id)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, false, nil
+		}
+		return 0, false, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get task owner")
+	}
+	return task.UserID, task.UserID != 0, nil
+}
+
+// StartTask 标记任务开始执行
+func (s *taskService) StartTask(ctx context.Context, id string) error {
+	err := s.taskRepo.Updates(ctx, id, map[string]interface{}{
+		"status":     model.TaskStatusRunning,
+		"started_at": s.clock.Now(),
+	})
+	return s.wrapError(err, "failed to start task")
+}
+
+// UpdateProgress 更新任务完成百分比
+func (s *taskService) UpdateProgress(ctx context.Context, id string, progress int) error {
+	progress = min(max(progress, 0), 100)
+	err := s.taskRepo.Updates(ctx, id, map[string]interface{}{
+		"status":   model.TaskStatusRunning,
+		"progress": progress,
+	})
+	return s.wrapError(err, "failed to update task progress")
+}
+
+// CompleteTask 标记任务成功，resultURL 为结果下载地址（可为空）
+func (s *taskService) CompleteTask(ctx context.Context, id string, resultURL string) error {
+	now := s.clock.Now()
+	err := s.taskRepo.Updates(ctx, id, map[string]interface{}{
+		"status":      model.TaskStatusSucceeded,
+		"progress":    100,
+		"result_url":  resultURL,
+		"finished_at": now,
+		"expires_at":  now.Add(s.ttl),
+	})
+	return s.wrapError(err, "failed to complete task")
+}
+
+// CompleteTaskWithDownload 标记任务成功，key 为结果在存储中的 key
+// 任务记录只保存 key，查询任务时才签发有时效的下载地址
+func (s *taskService) CompleteTaskWithDownload(ctx context.Context, id string, key string) error {
+	now := s.clock.Now()
+	err := s.taskRepo.Updates(ctx, id, map[string]interface{}{
+		"status":      model.TaskStatusSucceeded,
+		"progress":    100,
+		"result_key":  key,
+		"finished_at": now,
+		"expires_at":  now.Add(s.ttl),
+	})
+	return s.wrapError(err, "failed to complete task")
+}
+
+// FailTask 标记任务失败并记录错误信息
+func (s *taskService) FailTask(ctx context.Context, id string, taskErr error) error {
+	now := s.clock.Now()
+	values := map[string]interface{}{
+		"status":      model.TaskStatusFailed,
+		"finished_at": now,
+		"expires_at":  now.Add(s.ttl),
+	}
+	if taskErr != nil {
+		values["error"] = taskErr.Error()
+	}
+	err := s.taskRepo.Updates(ctx, id, values)
+	return s.wrapError(err, "failed to mark task as failed")
+}
+
+// CleanupExpired 删除已过期的任务记录
+func (s *taskService) CleanupExpired(ctx context.Context) (int64, error) {
+	deleted, err := s.taskRepo.DeleteExpired(ctx, s.clock.Now())
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to cleanup expired tasks")
+	}
+	return deleted, nil
+}
+
+// wrapError 将记录不存在转换为 ErrTaskNotFound
+func (s *taskService) wrapError(err error, message string) error {
+	if err == nil {
+		return nil
+	}
+	if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.ErrTaskNotFound
+	}
+	return errors.Wrap(err, errors.ErrorTypeDatabase, message)
+}
+
+// toTaskResponse 转换为任务响应，结果保存在存储中时签发下载地址
+func (s *taskService) toTaskResponse(task *model.Task) *model.TaskResponse {
+	resultURL := task.ResultURL
+	if task.ResultKey != "" && s.signer != nil {
+		resultURL = s.downloadURL(task.ResultKey)
+	}
+	return &model.TaskResponse{
+		ID:         task.ID,
+		Type:       task.Type,
+		UserID:     task.UserID,
+		Status:     task.Status,
+		Progress:   task.Progress,
+		ResultURL:  resultURL,
+		Error:      task.Error,
+		StartedAt:  task.StartedAt,
+		FinishedAt: task.FinishedAt,
+		ExpiresAt:  task.ExpiresAt,
+		CreatedAt:  task.CreatedAt,
+		UpdatedAt:  task.UpdatedAt,
+	}
+}
+
+// downloadURL 签发存储对象的下载地址，有效期为 download_ttl
+func (s *taskService) downloadURL(key string) string {
+	expires := s.clock.Now().Add(s.downloadTTL)
+	query := url.Values{
+		"expires":   {strconv.FormatInt(expires.Unix(), 10)},
+		"signature": {s.signer.Sign(key, expires)},
+	}
+	return DownloadPathPrefix + key + "?" + query.Encode()
+}
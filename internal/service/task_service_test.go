@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/idgen"
+	"github.com/hedeqiang/skeleton/pkg/storage"
+
+	"gorm.io/gorm"
+)
+
+// memoryTaskRepository 内存中的任务仓储
+type memoryTaskRepository struct {
+	repository.TaskRepository
+	mu    sync.Mutex
+	tasks map[string]*model.Task
+}
+
+func (r *memoryTaskRepository) Create(ctx context.Context, task *model.Task) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *task
+	r.tasks[task.ID] = &copied
+	return nil
+}
+
+func (r *memoryTaskRepository) GetByID(ctx context.Context, id string) (*model.Task, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.tasks[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *task
+	return &copied, nil
+}
+
+func (r *memoryTaskRepository) Updates(ctx context.Context, id string, values map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	task, ok := r.tasks[id]
+	if !ok {
+		return gorm.ErrRecordNotFound
+	}
+	for column, value := range values {
+		switch column {
+		case "status":
+			task.Status = value.(string)
+		case "progress":
+			task.Progress = value.(int)
+		case "result_url":
+			task.ResultURL = value.(string)
+		case "result_key":
+			task.ResultKey = value.(string)
+		}
+	}
+	return nil
+}
+
+// newTestIDGenerator 创建测试用的 ID 生成器
+func newTestIDGenerator(t *testing.T) idgen.IDGenerator {
+	t.Helper()
+	generator, err := idgen.NewSonyflakeGenerator()
+	if err != nil {
+		t.Fatalf("Failed to create id generator: %v", err)
+	}
+	return generator
+}
+
+func TestTaskServiceSignsDownloadOnRead(t *testing.T) {
+	repo := &memoryTaskRepository{tasks: map[string]*model.Task{}}
+	signer := storage.NewURLSigner("test-secret")
+	// 签名器按真实时间校验过期，这里使用当前时间
+	svc := NewTaskService(repo, newTestIDGenerator(t), signer, clock.NewFake(time.Now()), &config.Tasks{})
+	ctx := context.Background()
+
+	task, err := svc.CreateTask(ctx, TaskTypeUserExport, 7)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	key := "exports/users/20250301/7-" + task.ID + ".zip"
+	if err := svc.CompleteTaskWithDownload(ctx, task.ID, key); err != nil {
+		t.Fatalf("CompleteTaskWithDownload failed: %v", err)
+	}
+
+	// 任务记录只保存 key，不保存签名
+	stored := repo.tasks[task.ID]
+	if stored.UserID != 7 || stored.ResultKey != key || stored.ResultURL != "" {
+		t.Fatalf("stored task = %+v", stored)
+	}
+
+	got, err := svc.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	u, err := url.Parse(got.ResultURL)
+	if err != nil {
+		t.Fatalf("Invalid result url %q: %v", got.ResultURL, err)
+	}
+	if path := strings.TrimPrefix(u.Path, DownloadPathPrefix); path != key {
+		t.Fatalf("result url key = %q, want %q", path, key)
+	}
+	if err := signer.Verify(key, u.Query().Get("expires"), u.Query().Get("signature")); err != nil {
+		t.Fatalf("result url signature rejected: %v", err)
+	}
+
+	// 未配置签名器时不返回下载地址
+	unsigned := NewTaskService(repo, newTestIDGenerator(t), nil, clock.Frozen(), &config.Tasks{})
+	if got, err := unsigned.GetTask(ctx, task.ID); err != nil || got.ResultURL != "" {
+		t.Fatalf("GetTask without signer = %+v, %v", got, err)
+	}
+}
+
+func TestTaskOwner(t *testing.T) {
+	repo := &memoryTaskRepository{tasks: map[string]*model.Task{
+		"export":  {ID: "export", UserID: 7},
+		"reindex": {ID: "reindex"},
+	}}
+	svc := NewTaskService(repo, newTestIDGenerator(t), nil, clock.Frozen(), &config.Tasks{})
+
+	tests := []struct {
+		id        string
+		wantOwner uint
+		wantOK    bool
+	}{
+		{"export", 7, true},
+		{"reindex", 0, false}, // 系统任务只有管理员可以查看
+		{"missing", 0, false},
+	}
+	for _, tt := range tests {
+		owner, ok, err := svc.TaskOwner(context.Background(), tt.id)
+		if err != nil || owner != tt.wantOwner || ok != tt.wantOK {
+			t.Fatalf("TaskOwner(%s) = %d, %v, %v; want %d, %v", tt.id, owner, ok, err, tt.wantOwner, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	stdErrors "errors"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/jwt"
+
+	"go.uber.org/zap"
+)
+
+// tokenTypeBearer 访问令牌的类型
+const tokenTypeBearer = "Bearer"
+
+// TokenService 令牌服务接口，负责签发访问令牌和刷新令牌
+type TokenService interface {
+	// Issue 为用户签发访问令牌和刷新令牌，访问令牌携带用户当前的角色
+	Issue(ctx context.Context, user *model.User) (*model.AuthTokens, error)
+	// Consume 校验并作废刷新令牌，返回令牌所属的用户 ID
+	// 令牌不存在、已使用、已过期或在签发后被吊销时返回 ErrRefreshTokenInvalid
+	Consume(ctx context.Context, refreshToken string) (uint, error)
+}
+
+// tokenService 令牌服务实现
+type tokenService struct {
+	tokens      *jwt.JWT
+	refresh     *jwt.RefreshTokens
+	revocations *jwt.Revocations
+	clock       clock.Clock
+	cfg         *config.JWT
+	logger      *zap.Logger
+}
+
+// NewTokenService 创建令牌服务实例，revocations 为 nil 时不检查刷新令牌是否已吊销
+func NewTokenService(tokens *jwt.JWT, refresh *jwt.RefreshTokens, revocations *jwt.Revocations, clk clock.Clock, cfg *config.Config, logger *zap.Logger) TokenService {
+	return &tokenService{
+		tokens:      tokens,
+		refresh:     refresh,
+		revocations: revocations,
+		clock:       clk,
+		cfg:         &cfg.JWT,
+		logger:      logger,
+	}
+}
+
+// Issue 签发令牌
+func (s *tokenService) Issue(ctx context.Context, user *model.User) (*model.AuthTokens, error) {
+	now := s.clock.Now()
+	accessToken, err := s.tokens.GenerateToken(user.ID, user.Username, user.Roles...)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to generate access token")
+	}
+	refreshToken, err := s.refresh.Issue(ctx, user.ID, now)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to store refresh token")
+	}
+
+	return &model.AuthTokens{
+		AccessToken:      accessToken,
+		TokenType:        tokenTypeBearer,
+		ExpiresIn:        int64(s.cfg.ExpireDuration.Seconds()),
+		ExpiresAt:        now.Add(s.cfg.ExpireDuration),
+		RefreshToken:     refreshToken.Token,
+		RefreshExpiresAt: refreshToken.ExpiresAt,
+	}, nil
+}
+
+// Consume 作废刷新令牌
+// 管理员吊销用户令牌时同样吊销此前签发的刷新令牌；吊销记录读取失败时与访问令牌的校验一致，默认返回 503，开启 auth.revocation_fail_open 时放行并记录日志
+func (s *tokenService) Consume(ctx context.Context, refreshToken string) (uint, error) {
+	token, err := s.refresh.Consume(ctx, refreshToken)
+	if err != nil {
+		if stdErrors.Is(err, jwt.ErrRefreshTokenNotFound) {
+			return 0, errors.ErrRefreshTokenInvalid
+		}
+		return 0, errors.Wrap(err, errors.ErrorTypeExternal, "failed to consume refresh token")
+	}
+
+	revokedAt, err := s.revocations.RevokedAt(ctx, token.UserID)
+	if err != nil {
+		if !s.revocations.FailOpen() {
+			s.logger.Error("Token revocation check failed, refresh token rejected", zap.Uint("user_id", token.UserID), zap.Error(err))
+			return 0, errors.ErrAuthUnavailable
+		}
+		s.logger.Warn("Token revocation check failed, refresh token accepted", zap.Uint("user_id", token.UserID), zap.Error(err))
+	}
+	if !revokedAt.IsZero() && !token.IssuedAt.After(revokedAt) {
+		return 0, errors.ErrRefreshTokenInvalid
+	}
+	return token.UserID, nil
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/reservation"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// 唯一字段
+const (
+	UniqueUsername = "username"
+	UniqueEmail    = "email"
+)
+
+// 占用配置默认值
+const (
+	defaultUniquenessLockTTL        = 10 * time.Second
+	defaultUniquenessReservationTTL = 10 * time.Minute
+)
+
+// UniqueClaim 需要保证唯一的字段值
+type UniqueClaim struct {
+	Field string
+	Value string
+}
+
+// UniquenessService 用户名/邮箱唯一性服务
+// 写入前先在 Redis 中占用字段值再检查数据库，写入失败时将唯一索引冲突转换为 ErrUserExists
+// Redis 不可用时跳过占用，仅依赖数据库唯一索引
+type UniquenessService interface {
+	// Reserve 为多步骤注册预占用户名和邮箱，创建用户时携带返回的 token
+	Reserve(ctx context.Context, req *model.ReserveUserRequest) (*model.UserReservation, error)
+	// Claim 以 token 占用字段值并检查是否已被其他用户使用，userID 为当前用户，创建时传 0
+	Claim(ctx context.Context, token string, userID uint, claims ...UniqueClaim) (*Claims, error)
+	// TranslateError 将唯一索引冲突转换为 ErrUserExists，其他错误包装为数据库错误
+	TranslateError(err error, message string) error
+}
+
+// uniquenessService 唯一性服务实现
+type uniquenessService struct {
+	userRepo       repository.UserRepository
+	store          *reservation.Store
+	lockTTL        time.Duration
+	reservationTTL time.Duration
+	clock          clock.Clock
+	logger         *zap.Logger
+}
+
+// NewUniquenessService 创建唯一性服务，未启用时只做数据库检查和冲突转换
+func NewUniquenessService(userRepo repository.UserRepository, client *redis.Client, cfg *config.Uniqueness, clk clock.Clock, logger *zap.Logger) UniquenessService {
+	s := &uniquenessService{
+		userRepo:       userRepo,
+		lockTTL:        cfg.LockTTL,
+		reservationTTL: cfg.ReservationTTL,
+		clock:          clk,
+		logger:         logger,
+	}
+	if s.lockTTL <= 0 {
+		s.lockTTL = defaultUniquenessLockTTL
+	}
+	if s.reservationTTL <= 0 {
+		s.reservationTTL = defaultUniquenessReservationTTL
+	}
+
+	if cfg.Enabled {
+		prefix := cfg.Prefix
+		if prefix == "" {
+			prefix = "uniq"
+		}
+		s.store = reservation.New(client, prefix+":users")
+	}
+	return s
+}
+
+// Reserve 预占用户名和邮箱
+func (s *uniquenessService) Reserve(ctx context.Context, req *model.ReserveUserRequest) (*model.UserReservation, error) {
+	if s.store == nil {
+		return nil, errors.ValidationError("未启用用户名预占")
+	}
+
+	token := uuid.NewString()
+	if _, err := s.claim(ctx, token, 0, s.reservationTTL, []UniqueClaim{
+		{Field: UniqueUsername, Value: req.Username},
+		{Field: UniqueEmail, Value: req.Email},
+	}); err != nil {
+		return nil, err
+	}
+
+	return &model.UserReservation{
+		Token:     token,
+		ExpiresAt: s.clock.Now().Add(s.reservationTTL),
+	}, nil
+}
+
+// Claim 占用字段值并检查数据库
+func (s *uniquenessService) Claim(ctx context.Context, token string, userID uint, claims ...UniqueClaim) (*Claims, error) {
+	if token == "" {
+		token = uuid.NewString()
+	}
+	return s.claim(ctx, token, userID, s.lockTTL, claims)
+}
+
+// claim 依次占用每个字段值，任一字段冲突时释放本次新占用的字段
+func (s *uniquenessService) claim(ctx context.Context, token string, userID uint, ttl time.Duration, claims []UniqueClaim) (*Claims, error) {
+	held := &Claims{store: s.store, token: token, logger: s.logger}
+
+	for _, claim := range claims {
+		if s.store != nil {
+			key := claim.Field + ":" + strings.ToLower(claim.Value)
+			result, err := s.store.Acquire(ctx, key, token, ttl)
+			switch {
+			case err != nil:
+				s.logger.Warn("Failed to reserve unique value, falling back to database constraint",
+					zap.String("field", claim.Field), zap.Error(err))
+			case result == reservation.Taken:
+				held.Abort(ctx)
+				return nil, errors.ErrUserReserved
+			case result == reservation.Acquired:
+				held.acquired = append(held.acquired, key)
+				held.keys = append(held.keys, key)
+			default:
+				held.keys = append(held.keys, key)
+			}
+		}
+
+		if err := s.ensureAvailable(ctx, claim, userID); err != nil {
+			held.Abort(ctx)
+			return nil, err
+		}
+	}
+
+	return held, nil
+}
+
+// ensureAvailable 检查字段值是否已被其他用户使用
+func (s *uniquenessService) ensureAvailable(ctx context.Context, claim UniqueClaim, userID uint) error {
+	var (
+		exists bool
+		err    error
+	)
+	switch claim.Field {
+	case UniqueUsername:
+		exists, err = s.userRepo.ExistsByUsername(ctx, claim.Value)
+	case UniqueEmail:
+		exists, err = s.userRepo.ExistsByEmail(ctx, claim.Value)
+	default:
+		return errors.InternalError("unknown unique field: " + claim.Field)
+	}
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to check "+claim.Field)
+	}
+	if !exists {
+		return nil
+	}
+	if userID == 0 {
+		return errors.ErrUserExists
+	}
+
+	existingUser, err := s.getExistingUser(ctx, claim)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get existing user")
+	}
+	if existingUser.ID != userID {
+		return errors.ErrUserExists
+	}
+	return nil
+}
+
+// getExistingUser 获取占用字段值的用户
+func (s *uniquenessService) getExistingUser(ctx context.Context, claim UniqueClaim) (*model.User, error) {
+	if claim.Field == UniqueUsername {
+		return s.userRepo.GetByUsername(ctx, claim.Value)
+	}
+	return s.userRepo.GetByEmail(ctx, claim.Value)
+}
+
+// TranslateError 转换写入错误
+func (s *uniquenessService) TranslateError(err error, message string) error {
+	if database.IsDuplicateKey(err) {
+		return errors.ErrUserExists
+	}
+	return errors.Wrap(err, errors.ErrorTypeDatabase, message)
+}
+
+// Claims 一次占用持有的字段值
+type Claims struct {
+	store    *reservation.Store
+	token    string
+	keys     []string // 当前 token 持有的全部字段
+	acquired []string // 本次新占用的字段
+	logger   *zap.Logger
+}
+
+// Commit 写入成功后释放全部占用（包括预占），此后由数据库唯一索引保证唯一
+func (c *Claims) Commit(ctx context.Context) {
+	c.release(ctx, c.keys)
+}
+
+// Abort 写入失败后只释放本次新占用的字段，保留预占以便客户端重试
+func (c *Claims) Abort(ctx context.Context) {
+	c.release(ctx, c.acquired)
+}
+
+// release 释放占用，请求已取消时仍然执行
+func (c *Claims) release(ctx context.Context, keys []string) {
+	if c == nil || c.store == nil {
+		return
+	}
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range keys {
+		if err := c.store.Release(ctx, key, c.token); err != nil {
+			c.logger.Warn("Failed to release unique value reservation", zap.String("key", key), zap.Error(err))
+		}
+	}
+}
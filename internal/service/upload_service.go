@@ -0,0 +1,461 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	stdErrors "errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/storage"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	defaultUploadChunkSize  = 5 << 20
+	defaultUploadSessionTTL = 24 * time.Hour
+	defaultUploadFileTTL    = 7 * 24 * time.Hour
+
+	// uploadIDBytes 会话 ID 的随机字节数，ID 不可猜测
+	uploadIDBytes = 16
+
+	uploadKeyPrefix     = "upload:session:"
+	uploadUserKeyPrefix = "upload:user:"  // 哈希，记录用户未完成的会话及其声明的大小，用于配额
+	uploadExpiryKey     = "upload:expiry" // 有序集合，记录各会话的过期时间，用于清理分片
+	uploadFilesKey      = "upload:files"  // 有序集合，记录合并后文件的过期时间，用于清理文件
+)
+
+// createUploadScript 在用户配额内原子地保存会话
+// 先剔除已过期的会话，再检查会话数和声明的总大小；返回 0 表示成功，1 表示超出会话数，2 表示超出总大小
+var createUploadScript = redis.NewScript(`
+local count, total = 0, 0
+local sessions = redis.call("HGETALL", KEYS[1])
+for i = 1, #sessions, 2 do
+	if redis.call("EXISTS", ARGV[1] .. sessions[i]) == 1 then
+		count = count + 1
+		total = total + tonumber(sessions[i + 1])
+	else
+		redis.call("HDEL", KEYS[1], sessions[i])
+	end
+end
+local maxSessions, maxBytes, size = tonumber(ARGV[5]), tonumber(ARGV[6]), tonumber(ARGV[7])
+if maxSessions > 0 and count + 1 > maxSessions then
+	return 1
+end
+if maxBytes > 0 and total + size > maxBytes then
+	return 2
+end
+redis.call("SET", ARGV[1] .. ARGV[2], ARGV[3], "PX", ARGV[4])
+redis.call("HSET", KEYS[1], ARGV[2], size)
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return 0
+`)
+
+// UploadService 分片上传服务接口
+// 客户端先创建会话，再按任意顺序上传分片，中断后可通过 GetUpload 查询已上传分片并续传
+// 会话属于创建它的用户，其他用户查询、上传分片、完成或取消时按会话不存在处理
+type UploadService interface {
+	CreateUpload(ctx context.Context, userID uint, req *model.CreateUploadRequest) (*model.UploadSession, error)
+	GetUpload(ctx context.Context, userID uint, id string) (*model.UploadSession, error)
+	UploadChunk(ctx context.Context, userID uint, id string, index int, checksum string, r io.Reader) (*model.UploadSession, error)
+	CompleteUpload(ctx context.Context, userID uint, id string) (*model.UploadResult, error)
+	AbortUpload(ctx context.Context, userID uint, id string) error
+	CleanupExpired(ctx context.Context) (int, error)
+}
+
+// uploadService 分片上传服务实现
+type uploadService struct {
+	redis              *redis.Client
+	storage            storage.Storage
+	clock              clock.Clock
+	chunkSize          int64
+	maxSize            int64
+	maxSessionsPerUser int
+	maxBytesPerUser    int64
+	ttl                time.Duration
+	fileTTL            time.Duration
+}
+
+// NewUploadService 创建分片上传服务实例
+func NewUploadService(redisClient *redis.Client, store storage.Storage, clk clock.Clock, cfg *config.Upload) UploadService {
+	s := &uploadService{
+		redis:              redisClient,
+		storage:            store,
+		clock:              clk,
+		chunkSize:          cfg.ChunkSize,
+		maxSize:            cfg.MaxSize,
+		maxSessionsPerUser: cfg.MaxSessionsPerUser,
+		maxBytesPerUser:    cfg.MaxBytesPerUser,
+		ttl:                cfg.SessionTTL,
+		fileTTL:            cfg.FileTTL,
+	}
+	if s.chunkSize <= 0 {
+		s.chunkSize = defaultUploadChunkSize
+	}
+	if s.ttl <= 0 {
+		s.ttl = defaultUploadSessionTTL
+	}
+	if s.fileTTL <= 0 {
+		s.fileTTL = defaultUploadFileTTL
+	}
+	return s
+}
+
+// CreateUpload 创建上传会话
+// 用户未完成的会话数或声明的总大小超出配额时返回 ErrUploadQuotaExceeded
+func (s *uploadService) CreateUpload(ctx context.Context, userID uint, req *model.CreateUploadRequest) (*model.UploadSession, error) {
+	if s.maxSize > 0 && req.Size > s.maxSize {
+		return nil, errors.ValidationError(fmt.Sprintf("文件大小超过限制 %d 字节", s.maxSize))
+	}
+
+	id, err := newUploadID()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to generate upload id")
+	}
+
+	now := s.clock.Now()
+	session := &model.UploadSession{
+		ID:             id,
+		UserID:         userID,
+		Filename:       sanitizeFilename(req.Filename),
+		Size:           req.Size,
+		Checksum:       strings.ToLower(req.Checksum),
+		ChunkSize:      s.chunkSize,
+		TotalChunks:    int((req.Size + s.chunkSize - 1) / s.chunkSize),
+		UploadedChunks: []int{},
+		CreatedAt:      now,
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to encode upload session")
+	}
+	result, err := createUploadScript.Run(ctx, s.redis, []string{s.userKey(userID)},
+		uploadKeyPrefix, id, data, s.ttl.Milliseconds(), s.maxSessionsPerUser, s.maxBytesPerUser, req.Size).Int()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to save upload session")
+	}
+	if result != 0 {
+		return nil, errors.ErrUploadQuotaExceeded
+	}
+	if err := s.touch(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+// GetUpload 获取用户的上传会话及已上传的分片
+func (s *uploadService) GetUpload(ctx context.Context, userID uint, id string) (*model.UploadSession, error) {
+	session, err := s.load(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.redis.SMembers(ctx, s.chunksKey(id)).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to load uploaded chunks")
+	}
+	session.UploadedChunks = make([]int, 0, len(members))
+	for _, member := range members {
+		if index, err := strconv.Atoi(member); err == nil {
+			session.UploadedChunks = append(session.UploadedChunks, index)
+		}
+	}
+	sort.Ints(session.UploadedChunks)
+
+	ttl, err := s.redis.TTL(ctx, s.sessionKey(id)).Result()
+	if err == nil && ttl > 0 {
+		session.ExpiresAt = s.clock.Now().Add(ttl)
+	}
+
+	return session, nil
+}
+
+// load 读取会话，会话不存在或不属于 userID 时返回 ErrUploadNotFound，不泄露会话是否存在
+func (s *uploadService) load(ctx context.Context, userID uint, id string) (*model.UploadSession, error) {
+	data, err := s.redis.Get(ctx, s.sessionKey(id)).Bytes()
+	if err != nil {
+		if stdErrors.Is(err, redis.Nil) {
+			return nil, errors.ErrUploadNotFound
+		}
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to load upload session")
+	}
+
+	var session model.UploadSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to decode upload session")
+	}
+	if session.UserID != userID {
+		return nil, errors.ErrUploadNotFound
+	}
+	return &session, nil
+}
+
+// UploadChunk 上传单个分片，checksum 为分片的 SHA-256（可选）
+// 重复上传同一分片会覆盖之前的内容
+func (s *uploadService) UploadChunk(ctx context.Context, userID uint, id string, index int, checksum string, r io.Reader) (*model.UploadSession, error) {
+	session, err := s.load(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= session.TotalChunks {
+		return nil, errors.ValidationError(fmt.Sprintf("分片序号超出范围 [0, %d)", session.TotalChunks))
+	}
+
+	expected := session.ChunkLength(index)
+	hash := sha256.New()
+	key := s.chunkObjectKey(id, index)
+
+	// 多读一个字节用于检测分片超长
+	written, err := s.storage.Put(ctx, key, io.TeeReader(io.LimitReader(r, expected+1), hash))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to store chunk")
+	}
+	if written != expected {
+		_ = s.storage.Delete(ctx, key)
+		return nil, errors.ValidationError(fmt.Sprintf("分片大小错误，期望 %d 字节，实际 %d 字节", expected, written))
+	}
+	if checksum != "" && !strings.EqualFold(checksum, hex.EncodeToString(hash.Sum(nil))) {
+		_ = s.storage.Delete(ctx, key)
+		return nil, errors.ValidationError("分片校验和不匹配")
+	}
+
+	if err := s.redis.SAdd(ctx, s.chunksKey(id), index).Err(); err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to record chunk")
+	}
+	if err := s.touch(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.GetUpload(ctx, userID, id)
+}
+
+// CompleteUpload 合并所有分片并校验整个文件的校验和
+// 合并后的文件保留 file_ttl，需要长期保存的文件由业务代码在此之前转存，过期后由清理任务删除
+func (s *uploadService) CompleteUpload(ctx context.Context, userID uint, id string) (*model.UploadResult, error) {
+	session, err := s.GetUpload(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(session.UploadedChunks) != session.TotalChunks {
+		return nil, errors.ConflictError(fmt.Sprintf("分片未上传完整 (%d/%d)", len(session.UploadedChunks), session.TotalChunks))
+	}
+
+	hash := sha256.New()
+	key := path.Join(s.filePrefix(id), session.Filename)
+	reader := &chunkReader{
+		ctx:     ctx,
+		storage: s.storage,
+		keyFn:   func(i int) string { return s.chunkObjectKey(id, i) },
+		total:   session.TotalChunks,
+	}
+	defer reader.Close()
+
+	written, err := s.storage.Put(ctx, key, io.TeeReader(reader, hash))
+	if err != nil {
+		_ = s.storage.Delete(ctx, key)
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to assemble chunks")
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if written != session.Size || (session.Checksum != "" && sum != session.Checksum) {
+		_ = s.storage.Delete(ctx, key)
+		return nil, errors.ValidationError("文件校验失败，请重新上传")
+	}
+
+	expiresAt := s.clock.Now().Add(s.fileTTL)
+	if err := s.redis.ZAdd(ctx, uploadFilesKey, redis.Z{Score: float64(expiresAt.Unix()), Member: id}).Err(); err != nil {
+		_ = s.storage.Delete(ctx, key)
+		return nil, errors.Wrap(err, errors.ErrorTypeExternal, "failed to record uploaded file")
+	}
+	if err := s.remove(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return &model.UploadResult{
+		Key:       key,
+		Filename:  session.Filename,
+		Size:      written,
+		Checksum:  sum,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// AbortUpload 取消上传并删除已上传的分片
+func (s *uploadService) AbortUpload(ctx context.Context, userID uint, id string) error {
+	session, err := s.load(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	return s.remove(ctx, session)
+}
+
+// CleanupExpired 清理已过期会话遗留的分片和超过保留时长的合并文件，返回清理的会话和文件数量
+func (s *uploadService) CleanupExpired(ctx context.Context) (int, error) {
+	expired := &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(s.clock.Now().Unix(), 10),
+	}
+	ids, err := s.redis.ZRangeByScore(ctx, uploadExpiryKey, expired).Result()
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrorTypeExternal, "failed to list expired uploads")
+	}
+
+	cleaned := 0
+	for _, id := range ids {
+		// 会话已过期，配额中的记录在用户下次创建会话时剔除
+		if err := s.remove(ctx, &model.UploadSession{ID: id}); err != nil {
+			return cleaned, err
+		}
+		cleaned++
+	}
+
+	files, err := s.redis.ZRangeByScore(ctx, uploadFilesKey, expired).Result()
+	if err != nil {
+		return cleaned, errors.Wrap(err, errors.ErrorTypeExternal, "failed to list expired upload files")
+	}
+	for _, id := range files {
+		if err := s.storage.DeletePrefix(ctx, s.filePrefix(id)); err != nil {
+			return cleaned, errors.Wrap(err, errors.ErrorTypeInternal, "failed to delete uploaded file")
+		}
+		if err := s.redis.ZRem(ctx, uploadFilesKey, id).Err(); err != nil {
+			return cleaned, errors.Wrap(err, errors.ErrorTypeExternal, "failed to delete uploaded file record")
+		}
+		cleaned++
+	}
+	return cleaned, nil
+}
+
+// touch 刷新会话过期时间
+func (s *uploadService) touch(ctx context.Context, session *model.UploadSession) error {
+	pipe := s.redis.TxPipeline()
+	pipe.Expire(ctx, s.sessionKey(session.ID), s.ttl)
+	pipe.Expire(ctx, s.chunksKey(session.ID), s.ttl)
+	pipe.Expire(ctx, s.userKey(session.UserID), s.ttl)
+	pipe.ZAdd(ctx, uploadExpiryKey, redis.Z{
+		Score:  float64(s.clock.Now().Add(s.ttl).Unix()),
+		Member: session.ID,
+	})
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeExternal, "failed to refresh upload session")
+	}
+	return nil
+}
+
+// remove 删除会话和分片，并归还用户的配额
+func (s *uploadService) remove(ctx context.Context, session *model.UploadSession) error {
+	id := session.ID
+	if err := s.storage.DeletePrefix(ctx, s.chunkPrefix(id)); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeInternal, "failed to delete chunks")
+	}
+	pipe := s.redis.TxPipeline()
+	pipe.Del(ctx, s.sessionKey(id), s.chunksKey(id))
+	pipe.HDel(ctx, s.userKey(session.UserID), id)
+	pipe.ZRem(ctx, uploadExpiryKey, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeExternal, "failed to delete upload session")
+	}
+	return nil
+}
+
+func (s *uploadService) sessionKey(id string) string {
+	return uploadKeyPrefix + id
+}
+
+func (s *uploadService) chunksKey(id string) string {
+	return uploadKeyPrefix + id + ":chunks"
+}
+
+func (s *uploadService) userKey(userID uint) string {
+	return uploadUserKeyPrefix + strconv.FormatUint(uint64(userID), 10)
+}
+
+func (s *uploadService) filePrefix(id string) string {
+	return path.Join("uploads/files", id)
+}
+
+func (s *uploadService) chunkPrefix(id string) string {
+	return path.Join("uploads/chunks", id)
+}
+
+func (s *uploadService) chunkObjectKey(id string, index int) string {
+	return path.Join(s.chunkPrefix(id), strconv.Itoa(index))
+}
+
+// newUploadID 生成随机会话 ID，十六进制编码
+func newUploadID() (string, error) {
+	buf := make([]byte, uploadIDBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// sanitizeFilename 去掉客户端文件名中的目录部分
+func sanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	if name == "." || name == ".." || name == "/" {
+		return "file"
+	}
+	return name
+}
+
+// chunkReader 按顺序读取所有分片，每次只打开一个分片
+type chunkReader struct {
+	ctx     context.Context
+	storage storage.Storage
+	keyFn   func(int) string
+	total   int
+	next    int
+	current io.ReadCloser
+}
+
+// Read 实现 io.Reader
+func (r *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if r.next >= r.total {
+				return 0, io.EOF
+			}
+			rc, err := r.storage.Get(r.ctx, r.keyFn(r.next))
+			if err != nil {
+				return 0, fmt.Errorf("failed to open chunk %d: %w", r.next, err)
+			}
+			r.current = rc
+			r.next++
+		}
+
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+// Close 关闭当前打开的分片
+func (r *chunkReader) Close() error {
+	if r.current != nil {
+		return r.current.Close()
+	}
+	return nil
+}
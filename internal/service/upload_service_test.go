@@ -0,0 +1,149 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/storage"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestUploadService(t *testing.T, clk clock.Clock, cfg *config.Upload) (UploadService, *miniredis.Miniredis, storage.Storage) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	return NewUploadService(client, store, clk, cfg), mr, store
+}
+
+func TestUploadSessionBelongsToCreator(t *testing.T) {
+	svc, _, store := newTestUploadService(t, clock.Frozen(), &config.Upload{ChunkSize: 4})
+	ctx := context.Background()
+
+	session, err := svc.CreateUpload(ctx, 7, &model.CreateUploadRequest{Filename: "a.txt", Size: 6})
+	if err != nil {
+		t.Fatalf("CreateUpload failed: %v", err)
+	}
+	if _, err := hex.DecodeString(session.ID); err != nil || len(session.ID) != 2*uploadIDBytes {
+		t.Fatalf("upload id %q is not random hex", session.ID)
+	}
+	if session.UserID != 7 {
+		t.Fatalf("session user = %d, want 7", session.UserID)
+	}
+
+	// 其他用户按会话不存在处理
+	if _, err := svc.GetUpload(ctx, 8, session.ID); err != errors.ErrUploadNotFound {
+		t.Fatalf("GetUpload by another user = %v", err)
+	}
+	if _, err := svc.UploadChunk(ctx, 8, session.ID, 0, "", bytes.NewReader([]byte("evil"))); err != errors.ErrUploadNotFound {
+		t.Fatalf("UploadChunk by another user = %v", err)
+	}
+	if _, err := svc.CompleteUpload(ctx, 8, session.ID); err != errors.ErrUploadNotFound {
+		t.Fatalf("CompleteUpload by another user = %v", err)
+	}
+	if err := svc.AbortUpload(ctx, 8, session.ID); err != errors.ErrUploadNotFound {
+		t.Fatalf("AbortUpload by another user = %v", err)
+	}
+
+	for i, chunk := range []string{"abcd", "ef"} {
+		if _, err := svc.UploadChunk(ctx, 7, session.ID, i, "", bytes.NewReader([]byte(chunk))); err != nil {
+			t.Fatalf("UploadChunk(%d) failed: %v", i, err)
+		}
+	}
+	result, err := svc.CompleteUpload(ctx, 7, session.ID)
+	if err != nil {
+		t.Fatalf("CompleteUpload failed: %v", err)
+	}
+	if result.Size != 6 || !result.ExpiresAt.Equal(clock.Frozen().Now().Add(defaultUploadFileTTL)) {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if _, err := store.Get(ctx, result.Key); err != nil {
+		t.Fatalf("assembled file not stored: %v", err)
+	}
+}
+
+func TestUploadQuota(t *testing.T) {
+	svc, mr, _ := newTestUploadService(t, clock.Frozen(), &config.Upload{
+		SessionTTL:         time.Hour,
+		MaxSessionsPerUser: 2,
+		MaxBytesPerUser:    100,
+	})
+	ctx := context.Background()
+	create := func(userID uint, size int64) (*model.UploadSession, error) {
+		return svc.CreateUpload(ctx, userID, &model.CreateUploadRequest{Filename: "f", Size: size})
+	}
+
+	first, err := create(7, 40)
+	if err != nil {
+		t.Fatalf("CreateUpload failed: %v", err)
+	}
+	if _, err := create(7, 70); err != errors.ErrUploadQuotaExceeded {
+		t.Fatalf("CreateUpload over byte quota = %v, want ErrUploadQuotaExceeded", err)
+	}
+	if _, err := create(7, 60); err != nil {
+		t.Fatalf("CreateUpload within quota failed: %v", err)
+	}
+	if _, err := create(7, 1); err != errors.ErrUploadQuotaExceeded {
+		t.Fatalf("CreateUpload over session quota = %v, want ErrUploadQuotaExceeded", err)
+	}
+	// 配额按用户计算
+	if _, err := create(8, 100); err != nil {
+		t.Fatalf("CreateUpload for another user failed: %v", err)
+	}
+
+	// 取消上传归还配额
+	if err := svc.AbortUpload(ctx, 7, first.ID); err != nil {
+		t.Fatalf("AbortUpload failed: %v", err)
+	}
+	if _, err := create(7, 40); err != nil {
+		t.Fatalf("CreateUpload after abort failed: %v", err)
+	}
+
+	// 过期的会话不再占用配额
+	mr.FastForward(2 * time.Hour)
+	if _, err := create(7, 100); err != nil {
+		t.Fatalf("CreateUpload after sessions expired failed: %v", err)
+	}
+}
+
+func TestUploadCleanupRemovesExpiredFiles(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	svc, _, store := newTestUploadService(t, clk, &config.Upload{FileTTL: time.Hour})
+	ctx := context.Background()
+
+	session, err := svc.CreateUpload(ctx, 7, &model.CreateUploadRequest{Filename: "a.txt", Size: 3})
+	if err != nil {
+		t.Fatalf("CreateUpload failed: %v", err)
+	}
+	if _, err := svc.UploadChunk(ctx, 7, session.ID, 0, "", bytes.NewReader([]byte("abc"))); err != nil {
+		t.Fatalf("UploadChunk failed: %v", err)
+	}
+	result, err := svc.CompleteUpload(ctx, 7, session.ID)
+	if err != nil {
+		t.Fatalf("CompleteUpload failed: %v", err)
+	}
+
+	if cleaned, err := svc.CleanupExpired(ctx); err != nil || cleaned != 0 {
+		t.Fatalf("CleanupExpired before expiry = %d, %v", cleaned, err)
+	}
+	clk.Advance(2 * time.Hour)
+	if cleaned, err := svc.CleanupExpired(ctx); err != nil || cleaned != 1 {
+		t.Fatalf("CleanupExpired = %d, %v; want 1", cleaned, err)
+	}
+	if keys, _ := store.List(ctx, "uploads/"); len(keys) != 0 {
+		t.Fatalf("files left after cleanup: %v (result %s)", keys, result.Key)
+	}
+}
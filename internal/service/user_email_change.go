@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/i18n"
+	"github.com/hedeqiang/skeleton/pkg/password"
+
+	"go.uber.org/zap"
+)
+
+// 修改邮箱相关邮件模板
+const (
+	emailChangeConfirmPage   = "emails/email_change_confirm"
+	emailChangeRequestedPage = "emails/email_change_requested"
+	emailChangedPage         = "emails/email_changed"
+)
+
+const (
+	defaultEmailChangeTTL  = 24 * time.Hour
+	confirmationTokenBytes = 32
+)
+
+// startEmailChange 检查新邮箱是否可用并在 user 上记录待确认的修改，返回确认令牌，由调用方保存用户后发送确认邮件
+// 重复申请时生成新令牌，之前发出的令牌失效
+func (s *userService) startEmailChange(ctx context.Context, user *model.User, email string) (string, error) {
+	// 只检查新邮箱是否可用，确认时再次占用，期间被他人注册则确认失败
+	held, err := s.uniqueness.Claim(ctx, "", user.ID, UniqueClaim{Field: UniqueEmail, Value: email})
+	if err != nil {
+		return "", err
+	}
+	held.Abort(ctx)
+
+	token, err := newConfirmationToken()
+	if err != nil {
+		return "", errors.Wrap(err, errors.ErrorTypeInternal, "failed to generate email change token")
+	}
+	expiresAt := s.clock.Now().Add(s.emailChangeTTL)
+	user.PendingEmail = email
+	user.EmailChangeTokenHash = hashConfirmationToken(token)
+	user.EmailChangeExpiresAt = &expiresAt
+	return token, nil
+}
+
+// sendEmailChange 向新邮箱发送确认令牌并通知原邮箱
+func (s *userService) sendEmailChange(ctx context.Context, user *model.User, token string) error {
+	expiresAt := i18n.InLocation(ctx, *user.EmailChangeExpiresAt)
+	subject := s.translate(ctx, "Confirm your new email address")
+	if err := s.mail.SendTemplate(ctx, []string{user.PendingEmail}, subject, emailChangeConfirmPage, map[string]interface{}{
+		"Username":   user.Username,
+		"NewEmail":   user.PendingEmail,
+		"Token":      token,
+		"ConfirmURL": s.emailChangeConfirmURL(user.ID, token),
+		"ExpiresAt":  expiresAt,
+	}); err != nil {
+		return err
+	}
+
+	// 原邮箱的通知不包含令牌，账户所有者据此发现并撤销非本人发起的修改
+	s.notify(ctx, user, emailChangeRequestedPage, s.translate(ctx, "Email change requested"), map[string]interface{}{
+		"Username":  user.Username,
+		"NewEmail":  user.PendingEmail,
+		"ExpiresAt": expiresAt,
+	})
+	return nil
+}
+
+// ConfirmEmailChange 校验确认令牌并将待确认的新邮箱设为账户邮箱，成功后通知原邮箱
+func (s *userService) ConfirmEmailChange(ctx context.Context, id uint, token string) (*model.UserResponse, error) {
+	user, err := getUser(ctx, s.userRepo, id)
+	if err != nil {
+		return nil, err
+	}
+	if user.PendingEmail == "" {
+		return nil, errors.ErrEmailChangeNotRequested
+	}
+
+	if !password.Equal(hashConfirmationToken(token), user.EmailChangeTokenHash) {
+		return nil, errors.ErrEmailChangeTokenInvalid
+	}
+	if user.EmailChangeExpiresAt == nil || !s.clock.Now().Before(*user.EmailChangeExpiresAt) {
+		if err := s.userRepo.SetPendingEmail(ctx, id, "", "", nil); err != nil {
+			return nil, err
+		}
+		return nil, errors.ErrEmailChangeExpired
+	}
+
+	previous := *user
+	user.Email = user.PendingEmail
+	user.PendingEmail = ""
+	user.EmailChangeTokenHash = ""
+	user.EmailChangeExpiresAt = nil
+	if err := s.saveUser(ctx, user, []UniqueClaim{{Field: UniqueEmail, Value: user.Email}}); err != nil {
+		return nil, err
+	}
+
+	s.notify(ctx, &previous, emailChangedPage, s.translate(ctx, "Your email address has been changed"), map[string]interface{}{
+		"Username": user.Username,
+		"NewEmail": user.Email,
+	})
+	return toUserResponse(ctx, user), nil
+}
+
+// CancelEmailChange 撤销待确认的邮箱修改，已发出的确认令牌随之失效
+func (s *userService) CancelEmailChange(ctx context.Context, id uint) error {
+	user, err := getUser(ctx, s.userRepo, id)
+	if err != nil {
+		return err
+	}
+	if user.PendingEmail == "" {
+		return errors.ErrEmailChangeNotRequested
+	}
+	return s.userRepo.SetPendingEmail(ctx, id, "", "", nil)
+}
+
+// notify 向账户当前邮箱发送通知，发送失败只记录日志，不影响邮箱修改本身
+func (s *userService) notify(ctx context.Context, user *model.User, page, subject string, data map[string]interface{}) {
+	if err := s.mail.SendTemplate(ctx, []string{user.Email}, subject, page, data); err != nil {
+		s.logger.Warn("Failed to send email change notification", zap.Uint("user_id", user.ID), zap.String("template", page), zap.Error(err))
+	}
+}
+
+// emailChangeConfirmURL 生成确认链接，未配置 email_change.confirm_url 时返回空字符串
+func (s *userService) emailChangeConfirmURL(id uint, token string) string {
+	if s.emailChangeURL == "" {
+		return ""
+	}
+	query := url.Values{
+		"user_id": {strconv.FormatUint(uint64(id), 10)},
+		"token":   {token},
+	}
+	return s.emailChangeURL + "?" + query.Encode()
+}
+
+// translate 按请求语言翻译邮件标题，没有翻译时使用原文
+func (s *userService) translate(ctx context.Context, text string) string {
+	if message, ok := i18n.Localize(ctx, text, nil); ok {
+		return message
+	}
+	return text
+}
+
+// newConfirmationToken 生成随机确认令牌，十六进制编码，修改邮箱和重置密码共用
+func newConfirmationToken() (string, error) {
+	buf := make([]byte, confirmationTokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// hashConfirmationToken 数据库中只保存令牌的 SHA-256 哈希，泄露数据库不会泄露可用的令牌
+func hashConfirmationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// emailChangeUserRepository 内存中的用户仓储，只实现修改邮箱用到的方法
+type emailChangeUserRepository struct {
+	repository.UserRepository
+	users map[uint]*model.User
+}
+
+func (r *emailChangeUserRepository) GetByID(ctx context.Context, id uint) (*model.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *emailChangeUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *emailChangeUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	_, err := r.GetByEmail(ctx, email)
+	return err == nil, nil
+}
+
+func (r *emailChangeUserRepository) Update(ctx context.Context, user *model.User) error {
+	copied := *user
+	r.users[user.ID] = &copied
+	return nil
+}
+
+func (r *emailChangeUserRepository) SetPendingEmail(ctx context.Context, id uint, email, tokenHash string, expiresAt *time.Time) error {
+	user := r.users[id]
+	user.PendingEmail, user.EmailChangeTokenHash, user.EmailChangeExpiresAt = email, tokenHash, expiresAt
+	return nil
+}
+
+// sentMail 一封已发送的邮件
+type sentMail struct {
+	to   string
+	page string
+	data map[string]interface{}
+}
+
+// recordingMailService 记录发送的邮件，不渲染模板
+type recordingMailService struct {
+	sent []sentMail
+}
+
+func (m *recordingMailService) SendTemplate(ctx context.Context, to []string, subject, page string, data map[string]interface{}) error {
+	m.sent = append(m.sent, sentMail{to: to[0], page: page, data: data})
+	return nil
+}
+
+func (m *recordingMailService) Preload(ctx context.Context) error { return nil }
+
+func newTestEmailChangeService(clk clock.Clock) (UserService, *emailChangeUserRepository, *recordingMailService) {
+	repo := &emailChangeUserRepository{users: map[uint]*model.User{
+		7: {ID: 7, Username: "alice", Email: "alice@example.com"},
+		8: {ID: 8, Username: "bob", Email: "bob@example.com"},
+	}}
+	mail := &recordingMailService{}
+	uniqueness := NewUniquenessService(repo, nil, &config.Uniqueness{}, clk, zap.NewNop())
+	cfg := &config.EmailChange{TokenTTL: time.Hour, ConfirmURL: "https://app.example.com/confirm"}
+	return NewUserService(repo, nil, uniqueness, nil, nil, mail, nil, nil, nil, clk, cfg, zap.NewNop()), repo, mail
+}
+
+// requestChange 通过 PatchUser 发起修改，返回发送到新邮箱的令牌
+func requestChange(t *testing.T, svc UserService, mail *recordingMailService, email string) string {
+	t.Helper()
+	resp, err := svc.PatchUser(context.Background(), 7, &model.PatchUserRequest{Email: &email})
+	if err != nil {
+		t.Fatalf("PatchUser failed: %v", err)
+	}
+	if resp.Email != "alice@example.com" || resp.PendingEmail != email {
+		t.Fatalf("email = %q, pending = %q; change must wait for confirmation", resp.Email, resp.PendingEmail)
+	}
+
+	if len(mail.sent) != 2 {
+		t.Fatalf("sent %d mails, want confirmation and notice", len(mail.sent))
+	}
+	confirm, notice := mail.sent[0], mail.sent[1]
+	if confirm.to != email || confirm.page != emailChangeConfirmPage {
+		t.Fatalf("unexpected confirmation mail: %+v", confirm)
+	}
+	if notice.to != "alice@example.com" || notice.page != emailChangeRequestedPage {
+		t.Fatalf("unexpected notice mail: %+v", notice)
+	}
+	if _, leaked := notice.data["Token"]; leaked {
+		t.Fatal("notice to the old address must not contain the token")
+	}
+	mail.sent = nil
+	return confirm.data["Token"].(string)
+}
+
+func TestConfirmEmailChange(t *testing.T) {
+	svc, repo, mail := newTestEmailChangeService(clock.Frozen())
+	ctx := context.Background()
+	token := requestChange(t, svc, mail, "alice@new.example.com")
+
+	if repo.users[7].EmailChangeTokenHash == token {
+		t.Fatal("token must be stored hashed")
+	}
+	if _, err := svc.ConfirmEmailChange(ctx, 7, "wrong"); err != errors.ErrEmailChangeTokenInvalid {
+		t.Fatalf("expected ErrEmailChangeTokenInvalid, got %v", err)
+	}
+
+	resp, err := svc.ConfirmEmailChange(ctx, 7, token)
+	if err != nil {
+		t.Fatalf("ConfirmEmailChange failed: %v", err)
+	}
+	if resp.Email != "alice@new.example.com" || resp.PendingEmail != "" || repo.users[7].EmailChangeTokenHash != "" {
+		t.Fatalf("change not applied: %+v", repo.users[7])
+	}
+	if len(mail.sent) != 1 || mail.sent[0].to != "alice@example.com" || mail.sent[0].page != emailChangedPage {
+		t.Fatalf("old address not notified: %+v", mail.sent)
+	}
+
+	// 令牌只能使用一次
+	if _, err := svc.ConfirmEmailChange(ctx, 7, token); err != errors.ErrEmailChangeNotRequested {
+		t.Fatalf("expected ErrEmailChangeNotRequested, got %v", err)
+	}
+}
+
+func TestConfirmEmailChangeExpired(t *testing.T) {
+	clk := clock.NewFake(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	svc, repo, mail := newTestEmailChangeService(clk)
+	token := requestChange(t, svc, mail, "alice@new.example.com")
+
+	clk.Advance(time.Hour)
+	if _, err := svc.ConfirmEmailChange(context.Background(), 7, token); err != errors.ErrEmailChangeExpired {
+		t.Fatalf("expected ErrEmailChangeExpired, got %v", err)
+	}
+	if user := repo.users[7]; user.Email != "alice@example.com" || user.PendingEmail != "" {
+		t.Fatalf("expired change should be discarded: %+v", user)
+	}
+}
+
+func TestCancelEmailChange(t *testing.T) {
+	svc, repo, mail := newTestEmailChangeService(clock.Frozen())
+	ctx := context.Background()
+	token := requestChange(t, svc, mail, "alice@new.example.com")
+
+	if err := svc.CancelEmailChange(ctx, 7); err != nil {
+		t.Fatalf("CancelEmailChange failed: %v", err)
+	}
+	if _, err := svc.ConfirmEmailChange(ctx, 7, token); err != errors.ErrEmailChangeNotRequested {
+		t.Fatalf("expected ErrEmailChangeNotRequested after cancel, got %v", err)
+	}
+	if err := svc.CancelEmailChange(ctx, 7); err != errors.ErrEmailChangeNotRequested {
+		t.Fatalf("expected ErrEmailChangeNotRequested, got %v", err)
+	}
+	if repo.users[7].Email != "alice@example.com" {
+		t.Fatalf("email changed after cancel: %+v", repo.users[7])
+	}
+}
+
+func TestEmailChangeRejectsTakenEmail(t *testing.T) {
+	svc, repo, mail := newTestEmailChangeService(clock.Frozen())
+
+	email := "bob@example.com"
+	if _, err := svc.PatchUser(context.Background(), 7, &model.PatchUserRequest{Email: &email}); err != errors.ErrUserExists {
+		t.Fatalf("expected ErrUserExists, got %v", err)
+	}
+	if len(mail.sent) != 0 || repo.users[7].PendingEmail != "" {
+		t.Fatalf("taken email should not be recorded or mailed: %+v", repo.users[7])
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/cache"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+
+	"go.uber.org/zap"
+)
+
+// UserEvents 用户领域事件发布器，路由键为事件类型
+// 发布前按 cache.event_invalidations 失效响应缓存，管理后台和定时任务的修改同样生效；
+// 未启用搜索或未配置 events_exchange 时只失效缓存不发布，两者都未启用时为 nil
+type UserEvents struct {
+	publisher *mq.EventPublisher
+	exchange  string
+	cache     *cache.ResponseCache
+	logger    *zap.Logger
+}
+
+// NewUserEvents 创建用户领域事件发布器
+func NewUserEvents(publisher *mq.EventPublisher, responseCache *cache.ResponseCache, cfg *config.Search, logger *zap.Logger) *UserEvents {
+	events := &UserEvents{logger: logger}
+	if cfg.Enabled && cfg.EventsExchange != "" {
+		events.publisher = publisher
+		events.exchange = cfg.EventsExchange
+	}
+	if responseCache.Enabled() {
+		events.cache = responseCache
+	}
+	if events.publisher == nil && events.cache == nil {
+		return nil
+	}
+	return events
+}
+
+// Publish 失效响应缓存并发布用户领域事件，nil 接收者不做任何事
+// 用户数据已经写入数据库，失效或发布失败只记录警告，不影响请求结果；缓存按 TTL 过期，索引可通过重建恢复
+func (e *UserEvents) Publish(ctx context.Context, eventType string, userID uint) {
+	if e == nil {
+		return
+	}
+	if err := e.cache.InvalidateEvent(ctx, eventType); err != nil {
+		logger.FromContext(ctx, e.logger).Warn("Failed to invalidate response cache for user event",
+			zap.String("event_type", eventType),
+			zap.Uint("user_id", userID),
+			zap.Error(err),
+		)
+	}
+	if e.publisher == nil {
+		return
+	}
+	if _, err := e.publisher.PublishEvent(ctx, e.exchange, eventType, eventType, &model.UserEvent{UserID: userID}); err != nil {
+		logger.FromContext(ctx, e.logger).Warn("Failed to publish user event",
+			zap.String("event_type", eventType),
+			zap.Uint("user_id", userID),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/cache"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+func TestUserEventsInvalidateResponseCache(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { _ = client.Close() })
+	rc := cache.NewResponseCache(client, &config.Cache{
+		Enabled:    true,
+		DefaultTTL: time.Minute,
+		EventInvalidations: []config.CacheEventInvalidation{
+			{Event: model.EventUserUpdated, Tags: []string{"users"}},
+		},
+	})
+	ctx := context.Background()
+
+	cached := func(key string) bool {
+		_, err := rc.Get(ctx, key)
+		return err == nil
+	}
+	store := func(key string, tags ...string) {
+		if err := rc.Set(ctx, key, &cache.CachedResponse{Status: 200}, time.Minute, tags); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+	}
+
+	// 未启用搜索时不发布事件，仍然失效缓存
+	events := NewUserEvents(nil, rc, &config.Search{}, zap.NewNop())
+	if events == nil {
+		t.Fatal("NewUserEvents returned nil with response cache enabled")
+	}
+
+	store("user", "users")
+	store("other", "orders")
+	events.Publish(ctx, model.EventUserCreated, 7) // 没有对应规则
+	if !cached("user") {
+		t.Fatal("event without invalidation rule removed cached response")
+	}
+
+	events.Publish(ctx, model.EventUserUpdated, 7)
+	if cached("user") {
+		t.Fatal("user.updated did not invalidate the users tag")
+	}
+	if !cached("other") {
+		t.Fatal("user.updated invalidated an unrelated tag")
+	}
+
+	if NewUserEvents(nil, cache.NewResponseCache(client, &config.Cache{}), &config.Search{}, zap.NewNop()) != nil {
+		t.Fatal("NewUserEvents should be nil when neither publishing nor caching is enabled")
+	}
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/password"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// loginUserRepository 在修改邮箱测试仓储的基础上实现登录用到的方法
+type loginUserRepository struct {
+	*emailChangeUserRepository
+}
+
+func (r *loginUserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *loginUserRepository) SetLastLogin(ctx context.Context, id uint, at time.Time) error {
+	r.users[id].LastLoginAt = &at
+	return nil
+}
+
+func (r *loginUserRepository) SetPassword(ctx context.Context, id uint, hash string) error {
+	r.users[id].Password = hash
+	return nil
+}
+
+// nopStatsService 不记录统计
+type nopStatsService struct {
+	StatsService
+}
+
+func (nopStatsService) RecordLogin(ctx context.Context, userID uint) {}
+
+// newTestPasswords 创建测试使用的低成本密码哈希器
+func newTestPasswords(t *testing.T, cfg config.Password) *password.Hasher {
+	t.Helper()
+	if cfg.BcryptCost == 0 {
+		cfg.BcryptCost = bcrypt.MinCost
+	}
+	passwords, err := password.New(&config.Config{Password: cfg})
+	if err != nil {
+		t.Fatalf("Failed to create password hasher: %v", err)
+	}
+	return passwords
+}
+
+// memoryTokenService 内存中的令牌服务，刷新令牌只能使用一次
+type memoryTokenService struct {
+	refresh map[string]uint
+	issued  int
+}
+
+func (s *memoryTokenService) Issue(ctx context.Context, user *model.User) (*model.AuthTokens, error) {
+	s.issued++
+	token := user.Username + "-" + strconv.Itoa(s.issued)
+	s.refresh[token] = user.ID
+	return &model.AuthTokens{AccessToken: "access-" + token, TokenType: tokenTypeBearer, RefreshToken: token}, nil
+}
+
+func (s *memoryTokenService) Consume(ctx context.Context, refreshToken string) (uint, error) {
+	id, ok := s.refresh[refreshToken]
+	if !ok {
+		return 0, errors.ErrRefreshTokenInvalid
+	}
+	delete(s.refresh, refreshToken)
+	return id, nil
+}
+
+func TestRefreshToken(t *testing.T) {
+	repo := &emailChangeUserRepository{users: map[uint]*model.User{
+		7: {ID: 7, Username: "alice", Status: 1, Roles: []string{"editor"}},
+		8: {ID: 8, Username: "bob", Status: 1},
+	}}
+	tokens := &memoryTokenService{refresh: map[string]uint{"alice-0": 7, "bob-0": 8, "ghost-0": 9}}
+	users := NewUserService(repo, nil, nil, nil, nil, nil, nil, nil, tokens, clock.Frozen(), &config.EmailChange{}, zap.NewNop())
+	ctx := context.Background()
+
+	resp, err := users.RefreshToken(ctx, "alice-0")
+	if err != nil {
+		t.Fatalf("RefreshToken failed: %v", err)
+	}
+	if resp.User.ID != 7 || resp.RefreshToken == "alice-0" || resp.AccessToken == "" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+
+	// 刷新令牌轮换后旧令牌失效
+	if _, err := users.RefreshToken(ctx, "alice-0"); err != errors.ErrRefreshTokenInvalid {
+		t.Fatalf("reusing the refresh token = %v, want ErrRefreshTokenInvalid", err)
+	}
+	if _, err := users.RefreshToken(ctx, resp.RefreshToken); err != nil {
+		t.Fatalf("RefreshToken with the rotated token failed: %v", err)
+	}
+
+	repo.users[8].Status = 0
+	if _, err := users.RefreshToken(ctx, "bob-0"); err != errors.ErrAccountDisabled {
+		t.Fatalf("RefreshToken for a disabled user = %v, want ErrAccountDisabled", err)
+	}
+	if _, err := users.RefreshToken(ctx, "ghost-0"); err != errors.ErrRefreshTokenInvalid {
+		t.Fatalf("RefreshToken for a deleted user = %v, want ErrRefreshTokenInvalid", err)
+	}
+}
+
+func TestLoginUpgradesPasswordHash(t *testing.T) {
+	oldHash, _ := newTestPasswords(t, config.Password{}).Hash("secret")
+	repo := &loginUserRepository{&emailChangeUserRepository{users: map[uint]*model.User{
+		7: {ID: 7, Username: "alice", Status: 1, Password: oldHash},
+	}}}
+	passwords := newTestPasswords(t, config.Password{
+		Algorithm: config.PasswordAlgorithmArgon2id,
+		Argon2id:  config.Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1},
+	})
+	tokens := &memoryTokenService{refresh: map[string]uint{}}
+	users := NewUserService(repo, nil, nil, nil, nopStatsService{}, nil, passwords, nil, tokens, clock.Frozen(), &config.EmailChange{}, zap.NewNop())
+	ctx := context.Background()
+
+	if _, err := users.Login(ctx, "alice", "wrong"); err != errors.ErrInvalidPassword {
+		t.Fatalf("Login with wrong password = %v, want ErrInvalidPassword", err)
+	}
+	if repo.users[7].Password != oldHash {
+		t.Fatal("failed login must not upgrade the hash")
+	}
+
+	resp, err := users.Login(ctx, "alice", "secret")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if resp.AccessToken == "" || resp.User.ID != 7 {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+	upgraded := repo.users[7].Password
+	if !strings.HasPrefix(upgraded, "$argon2id$") {
+		t.Fatalf("hash not upgraded: %q", upgraded)
+	}
+
+	// 升级后的哈希可以继续登录，不再重复升级
+	if _, err := users.Login(ctx, "alice", "secret"); err != nil {
+		t.Fatalf("Login after upgrade failed: %v", err)
+	}
+	if repo.users[7].Password != upgraded {
+		t.Fatal("an up-to-date hash must not be rewritten")
+	}
+}
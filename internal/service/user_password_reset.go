@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/password"
+
+	"go.uber.org/zap"
+)
+
+// ConfirmPasswordReset 校验管理员强制重置时发出的令牌并设置新密码，成功后用户可以用新密码登录
+// 令牌过期后保留重置要求，需要管理员重新发起
+func (s *userService) ConfirmPasswordReset(ctx context.Context, id uint, token, newPassword string) error {
+	user, err := getUser(ctx, s.userRepo, id)
+	if err != nil {
+		return err
+	}
+	if !user.PasswordResetRequired || user.PasswordResetTokenHash == "" {
+		return errors.ErrResetNotRequired
+	}
+
+	if !password.Equal(hashConfirmationToken(token), user.PasswordResetTokenHash) {
+		return errors.ErrResetTokenInvalid
+	}
+	if user.PasswordResetExpiresAt == nil || !s.clock.Now().Before(*user.PasswordResetExpiresAt) {
+		return errors.ErrResetTokenExpired
+	}
+	if err := s.validatePassword(ctx, newPassword, user.Username, user.Email); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.passwords.Hash(newPassword)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrorTypeInternal, "failed to hash password")
+	}
+	user.Password = hashedPassword
+	user.PasswordResetRequired = false
+	user.PasswordResetTokenHash = ""
+	user.PasswordResetExpiresAt = nil
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to update password")
+	}
+
+	// 密码已修改，审计日志写入失败只记录日志
+	if err := s.auditRepo.Create(ctx, &model.UserAuditLog{
+		UserID:  user.ID,
+		ActorID: user.ID,
+		Action:  model.UserAuditPasswordResetCompleted,
+	}); err != nil {
+		s.logger.Warn("Failed to write user audit log", zap.Uint("user_id", user.ID), zap.String("action", model.UserAuditPasswordResetCompleted), zap.Error(err))
+	}
+	return nil
+}
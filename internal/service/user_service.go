@@ -1,61 +1,115 @@
 package service
 
 import (
+	"github.com/hedeqiang/skeleton/internal/config"
 	"github.com/hedeqiang/skeleton/internal/model"
 	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/clock"
 	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/i18n"
+	"github.com/hedeqiang/skeleton/pkg/password"
 	"context"
 	stdErrors "errors"
+	"time"
 
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 // UserService 用户服务接口
 type UserService interface {
 	CreateUser(ctx context.Context, req *model.CreateUserRequest) (*model.UserResponse, error)
+	ReserveUser(ctx context.Context, req *model.ReserveUserRequest) (*model.UserReservation, error)
 	GetUser(ctx context.Context, id uint) (*model.UserResponse, error)
 	UpdateUser(ctx context.Context, id uint, req *model.UpdateUserRequest) (*model.UserResponse, error)
+	PatchUser(ctx context.Context, id uint, req *model.PatchUserRequest) (*model.UserResponse, error)
+	// ConfirmEmailChange 凭邮件中的令牌确认修改邮箱
+	ConfirmEmailChange(ctx context.Context, id uint, token string) (*model.UserResponse, error)
+	// CancelEmailChange 撤销待确认的邮箱修改
+	CancelEmailChange(ctx context.Context, id uint) error
+	// ConfirmPasswordReset 凭管理员强制重置时邮件中的令牌设置新密码
+	ConfirmPasswordReset(ctx context.Context, id uint, token, password string) error
 	DeleteUser(ctx context.Context, id uint) error
 	ListUsers(ctx context.Context, page, pageSize int) ([]*model.UserResponse, int64, error)
-	Login(ctx context.Context, username, password string) (*model.UserResponse, error)
+	// Login 校验用户名和密码，签发访问令牌和刷新令牌
+	Login(ctx context.Context, username, password string) (*model.LoginResponse, error)
+	// RefreshToken 凭刷新令牌换取新的访问令牌和刷新令牌，旧的刷新令牌随之失效
+	RefreshToken(ctx context.Context, refreshToken string) (*model.LoginResponse, error)
 }
 
 // userService 用户服务实现
 type userService struct {
-	userRepo repository.UserRepository
+	userRepo   repository.UserRepository
+	auditRepo  repository.UserAuditLogRepository
+	uniqueness UniquenessService
+	events     *UserEvents
+	stats      StatsService
+	mail       MailService
+	passwords  *password.Hasher
+	strength   PasswordStrengthService
+	tokens     TokenService
+	clock      clock.Clock
+	logger     *zap.Logger
+
+	emailChangeTTL time.Duration
+	emailChangeURL string
 }
 
-// NewUserService 创建用户服务实例
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{
-		userRepo: userRepo,
+// NewUserService 创建用户服务实例，events 为 nil 时不发布用户领域事件，strength 为 nil 时不检查密码强度
+func NewUserService(
+	userRepo repository.UserRepository,
+	auditRepo repository.UserAuditLogRepository,
+	uniqueness UniquenessService,
+	events *UserEvents,
+	stats StatsService,
+	mail MailService,
+	passwords *password.Hasher,
+	strength PasswordStrengthService,
+	tokens TokenService,
+	clk clock.Clock,
+	cfg *config.EmailChange,
+	logger *zap.Logger,
+) UserService {
+	s := &userService{
+		userRepo:       userRepo,
+		auditRepo:      auditRepo,
+		uniqueness:     uniqueness,
+		events:         events,
+		stats:          stats,
+		mail:           mail,
+		passwords:      passwords,
+		strength:       strength,
+		tokens:         tokens,
+		clock:          clk,
+		logger:         logger,
+		emailChangeTTL: cfg.TokenTTL,
+		emailChangeURL: cfg.ConfirmURL,
+	}
+	if s.emailChangeTTL <= 0 {
+		s.emailChangeTTL = defaultEmailChangeTTL
 	}
+	return s
 }
 
 // CreateUser 创建用户
 func (s *userService) CreateUser(ctx context.Context, req *model.CreateUserRequest) (*model.UserResponse, error) {
-	// 检查用户名是否已存在
-	exists, err := s.userRepo.ExistsByUsername(ctx, req.Username)
-	if err != nil {
-		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to check username")
-	}
-	if exists {
-		return nil, errors.ErrUserExists
+	if err := s.validatePassword(ctx, req.Password, req.Username, req.Email); err != nil {
+		return nil, err
 	}
 
-	// 检查邮箱是否已存在
-	exists, err = s.userRepo.ExistsByEmail(ctx, req.Email)
+	// 占用用户名和邮箱并检查是否已存在，携带预占 token 时沿用预占
+	claims, err := s.uniqueness.Claim(ctx, req.ReservationToken, 0,
+		UniqueClaim{Field: UniqueUsername, Value: req.Username},
+		UniqueClaim{Field: UniqueEmail, Value: req.Email},
+	)
 	if err != nil {
-		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to check email")
-	}
-	if exists {
-		return nil, errors.ErrUserExists
+		return nil, err
 	}
 
 	// 加密密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.passwords.Hash(req.Password)
 	if err != nil {
+		claims.Abort(ctx)
 		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to hash password")
 	}
 
@@ -63,15 +117,24 @@ func (s *userService) CreateUser(ctx context.Context, req *model.CreateUserReque
 	user := &model.User{
 		Username: req.Username,
 		Email:    req.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Status:   1,
 	}
 
+	// 并发请求同时通过检查时由唯一索引拒绝
 	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to create user")
+		claims.Abort(ctx)
+		return nil, s.uniqueness.TranslateError(err, "failed to create user")
 	}
+	claims.Commit(ctx)
+	s.events.Publish(ctx, model.EventUserCreated, user.ID)
 
-	return s.toUserResponse(user), nil
+	return toUserResponse(ctx, user), nil
+}
+
+// ReserveUser 为多步骤注册预占用户名和邮箱
+func (s *userService) ReserveUser(ctx context.Context, req *model.ReserveUserRequest) (*model.UserReservation, error) {
+	return s.uniqueness.Reserve(ctx, req)
 }
 
 // GetUser 获取用户
@@ -84,7 +147,7 @@ func (s *userService) GetUser(ctx context.Context, id uint) (*model.UserResponse
 		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get user")
 	}
 
-	return s.toUserResponse(user), nil
+	return toUserResponse(ctx, user), nil
 }
 
 // UpdateUser 更新用户
@@ -97,54 +160,91 @@ func (s *userService) UpdateUser(ctx context.Context, id uint, req *model.Update
 		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get user")
 	}
 
-	// 更新字段
+	// 检查用户名是否已被其他用户使用
+	var claims []UniqueClaim
 	if req.Username != "" {
-		// 检查用户名是否已被其他用户使用
-		exists, err := s.userRepo.ExistsByUsername(ctx, req.Username)
-		if err != nil {
-			return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to check username")
-		}
-		// 检查是否存在其他用户使用此用户名
-		if exists {
-			existingUser, err := s.userRepo.GetByUsername(ctx, req.Username)
-			if err != nil {
-				return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get existing user")
-			}
-			if existingUser.ID != id {
-				return nil, errors.ErrUserExists
-			}
-		}
+		claims = append(claims, UniqueClaim{Field: UniqueUsername, Value: req.Username})
 		user.Username = req.Username
 	}
 
-	if req.Email != "" {
-		// 检查邮箱是否已被其他用户使用
-		exists, err := s.userRepo.ExistsByEmail(ctx, req.Email)
-		if err != nil {
-			return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to check email")
+	if req.Status != nil {
+		user.Status = *req.Status
+	}
+
+	// 新邮箱确认后才生效
+	var emailToken string
+	if req.Email != "" && req.Email != user.Email {
+		if emailToken, err = s.startEmailChange(ctx, user, req.Email); err != nil {
+			return nil, err
 		}
-		// 检查是否存在其他用户使用此邮箱
-		if exists {
-			existingUser, err := s.userRepo.GetByEmail(ctx, req.Email)
-			if err != nil {
-				return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get existing user")
-			}
-			if existingUser.ID != id {
-				return nil, errors.ErrUserExists
-			}
+	}
+
+	if err := s.saveUser(ctx, user, claims); err != nil {
+		return nil, err
+	}
+	if emailToken != "" {
+		if err := s.sendEmailChange(ctx, user, emailToken); err != nil {
+			return nil, err
 		}
-		user.Email = req.Email
+	}
+
+	return toUserResponse(ctx, user), nil
+}
+
+// PatchUser 部分更新用户，只修改请求中出现的字段
+func (s *userService) PatchUser(ctx context.Context, id uint, req *model.PatchUserRequest) (*model.UserResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, id)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get user")
+	}
+
+	var claims []UniqueClaim
+	if req.Username != nil && *req.Username != user.Username {
+		claims = append(claims, UniqueClaim{Field: UniqueUsername, Value: *req.Username})
+		user.Username = *req.Username
 	}
 
 	if req.Status != nil {
 		user.Status = *req.Status
 	}
 
-	if err := s.userRepo.Update(ctx, user); err != nil {
-		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to update user")
+	// 新邮箱确认后才生效
+	var emailToken string
+	if req.Email != nil && *req.Email != user.Email {
+		if emailToken, err = s.startEmailChange(ctx, user, *req.Email); err != nil {
+			return nil, err
+		}
 	}
 
-	return s.toUserResponse(user), nil
+	if err := s.saveUser(ctx, user, claims); err != nil {
+		return nil, err
+	}
+	if emailToken != "" {
+		if err := s.sendEmailChange(ctx, user, emailToken); err != nil {
+			return nil, err
+		}
+	}
+
+	return toUserResponse(ctx, user), nil
+}
+
+// saveUser 占用修改后的用户名和邮箱并保存用户
+func (s *userService) saveUser(ctx context.Context, user *model.User, claims []UniqueClaim) error {
+	held, err := s.uniqueness.Claim(ctx, "", user.ID, claims...)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		held.Abort(ctx)
+		return s.uniqueness.TranslateError(err, "failed to update user")
+	}
+	held.Commit(ctx)
+	s.events.Publish(ctx, model.EventUserUpdated, user.ID)
+	return nil
 }
 
 // DeleteUser 删除用户
@@ -161,6 +261,7 @@ func (s *userService) DeleteUser(ctx context.Context, id uint) error {
 	if err := s.userRepo.Delete(ctx, id); err != nil {
 		return errors.Wrap(err, errors.ErrorTypeDatabase, "failed to delete user")
 	}
+	s.events.Publish(ctx, model.EventUserDeleted, id)
 
 	return nil
 }
@@ -182,14 +283,14 @@ func (s *userService) ListUsers(ctx context.Context, page, pageSize int) ([]*mod
 
 	responses := make([]*model.UserResponse, len(users))
 	for i, user := range users {
-		responses[i] = s.toUserResponse(user)
+		responses[i] = toUserResponse(ctx, user)
 	}
 
 	return responses, total, nil
 }
 
 // Login 用户登录
-func (s *userService) Login(ctx context.Context, username, password string) (*model.UserResponse, error) {
+func (s *userService) Login(ctx context.Context, username, password string) (*model.LoginResponse, error) {
 	user, err := s.userRepo.GetByUsername(ctx, username)
 	if err != nil {
 		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
@@ -204,21 +305,119 @@ func (s *userService) Login(ctx context.Context, username, password string) (*mo
 	}
 
 	// 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+	match, rehash, err := s.passwords.Verify(password, user.Password)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to verify password")
+	}
+	if !match {
 		return nil, errors.ErrInvalidPassword
 	}
 
-	return s.toUserResponse(user), nil
+	// 管理员强制重置密码后，原密码不再可用
+	if user.PasswordResetRequired {
+		return nil, errors.ErrPasswordResetRequired
+	}
+
+	tokens, err := s.tokens.Issue(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if rehash {
+		s.upgradePasswordHash(ctx, user.ID, password)
+	}
+	if err := s.userRepo.SetLastLogin(ctx, user.ID, s.clock.Now()); err != nil {
+		s.logger.Warn("Failed to record last login", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+	s.stats.RecordLogin(ctx, user.ID)
+	return &model.LoginResponse{User: toUserResponse(ctx, user), AuthTokens: *tokens}, nil
+}
+
+// RefreshToken 刷新令牌
+// 重新读取用户，新的访问令牌携带当前的角色；账户已删除、禁用或被要求重置密码时不再签发
+func (s *userService) RefreshToken(ctx context.Context, refreshToken string) (*model.LoginResponse, error) {
+	id, err := s.tokens.Consume(ctx, refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := getUser(ctx, s.userRepo, id)
+	if err != nil {
+		if stdErrors.Is(err, errors.ErrUserNotFound) {
+			return nil, errors.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+	if user.Status != 1 {
+		return nil, errors.ErrAccountDisabled
+	}
+	if user.PasswordResetRequired {
+		return nil, errors.ErrPasswordResetRequired
+	}
+
+	tokens, err := s.tokens.Issue(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LoginResponse{User: toUserResponse(ctx, user), AuthTokens: *tokens}, nil
 }
 
-// toUserResponse 转换为响应格式
-func (s *userService) toUserResponse(user *model.User) *model.UserResponse {
-	return &model.UserResponse{
+// upgradePasswordHash 哈希算法或参数调整后，用登录时校验通过的明文密码重新哈希并保存，失败只记录日志，下次登录再升级
+func (s *userService) upgradePasswordHash(ctx context.Context, id uint, plain string) {
+	hash, err := s.passwords.Hash(plain)
+	if err == nil {
+		err = s.userRepo.SetPassword(ctx, id, hash)
+	}
+	if err != nil {
+		s.logger.Warn("Failed to upgrade password hash", zap.Uint("user_id", id), zap.Error(err))
+		return
+	}
+	s.logger.Info("Password hash upgraded", zap.Uint("user_id", id))
+}
+
+// toUserResponse 转换为响应格式，请求指定了时区时时间按该时区输出
+func toUserResponse(ctx context.Context, user *model.User) *model.UserResponse {
+	locale, _ := i18n.LocaleFromContext(ctx)
+	resp := &model.UserResponse{
 		ID:        user.ID,
 		Username:  user.Username,
 		Email:     user.Email,
 		Status:    user.Status,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		CreatedAt: locale.In(user.CreatedAt),
+		UpdatedAt: locale.In(user.UpdatedAt),
+
+		Roles:                 user.Roles,
+		DisabledReason:        user.DisabledReason,
+		PasswordResetRequired: user.PasswordResetRequired,
+	}
+	if user.DeletionScheduledAt != nil {
+		at := locale.In(*user.DeletionScheduledAt)
+		resp.DeletionScheduledAt = &at
+	}
+	if user.PendingEmail != "" && user.EmailChangeExpiresAt != nil {
+		at := locale.In(*user.EmailChangeExpiresAt)
+		resp.PendingEmail = user.PendingEmail
+		resp.EmailChangeExpiresAt = &at
+	}
+	return resp
+}
+
+// getUser 通过 userRepo 获取用户，不存在时返回 ErrUserNotFound，供各个服务共用
+func getUser(ctx context.Context, userRepo repository.UserRepository, id uint) (*model.User, error) {
+	user, err := userRepo.GetByID(ctx, id)
+	if err != nil {
+		if stdErrors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.Wrap(err, errors.ErrorTypeDatabase, "failed to get user")
+	}
+	return user, nil
+}
+
+// validatePassword 检查新密码强度，未配置密码强度服务时不检查
+func (s *userService) validatePassword(ctx context.Context, pwd string, identities ...string) error {
+	if s.strength == nil {
+		return nil
 	}
+	return s.strength.Validate(ctx, pwd, identities...)
 }
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+	"github.com/hedeqiang/skeleton/pkg/pool"
+
+	"go.uber.org/zap"
+)
+
+// Webhook 投递配置默认值
+const (
+	defaultWebhookTimeout     = 10 * time.Second
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookBatchSize   = 100
+	defaultWebhookBackoffBase = 30 * time.Second
+	defaultWebhookBackoffMax  = 6 * time.Hour
+	defaultWebhookConcurrency = 4
+)
+
+// Webhook 请求头
+const (
+	HeaderWebhookEvent    = "X-Webhook-Event"
+	HeaderWebhookDelivery = "X-Webhook-Delivery"
+	HeaderWebhookAttempt  = "X-Webhook-Attempt"
+)
+
+// WebhookRelayResult 一次重试扫描的结果
+type WebhookRelayResult struct {
+	Delivered int `json:"delivered"`
+	Failed    int `json:"failed"`
+	Dead      int `json:"dead"`
+}
+
+// WebhookService 出站 Webhook 服务接口
+// Enqueue 只写入投递记录，由 webhook_relay_job 调用 RelayDue 实际发送；
+// 收到 2xx 响应才标记为已投递，记录状态更新失败时会再次发送，接收方需按 X-Webhook-Delivery 去重
+type WebhookService interface {
+	Enqueue(ctx context.Context, eventType, url string, payload interface{}) (*model.WebhookDelivery, error)
+	RelayDue(ctx context.Context) (*WebhookRelayResult, error)
+}
+
+// webhookService 出站 Webhook 服务实现
+type webhookService struct {
+	repo        repository.WebhookDeliveryRepository
+	client      *http.Client
+	clock       clock.Clock
+	logger      *zap.Logger
+	maxAttempts int
+	batchSize   int
+	backoffBase time.Duration
+	backoffMax  time.Duration
+	concurrency int
+}
+
+// NewWebhookService 创建出站 Webhook 服务实例
+func NewWebhookService(repo repository.WebhookDeliveryRepository, clk clock.Clock, cfg *config.Webhook, logger *zap.Logger) WebhookService {
+	s := &webhookService{
+		repo:        repo,
+		client:      &http.Client{Timeout: cfg.Timeout},
+		clock:       clk,
+		logger:      logger,
+		maxAttempts: cfg.MaxAttempts,
+		batchSize:   cfg.BatchSize,
+		backoffBase: cfg.BackoffBase,
+		backoffMax:  cfg.BackoffMax,
+		concurrency: cfg.Concurrency,
+	}
+	if s.client.Timeout <= 0 {
+		s.client.Timeout = defaultWebhookTimeout
+	}
+	if s.maxAttempts <= 0 {
+		s.maxAttempts = defaultWebhookMaxAttempts
+	}
+	if s.batchSize <= 0 {
+		s.batchSize = defaultWebhookBatchSize
+	}
+	if s.backoffBase <= 0 {
+		s.backoffBase = defaultWebhookBackoffBase
+	}
+	if s.backoffMax <= 0 {
+		s.backoffMax = defaultWebhookBackoffMax
+	}
+	if s.concurrency <= 0 {
+		s.concurrency = defaultWebhookConcurrency
+	}
+	return s
+}
+
+// Enqueue 创建待投递记录，payload 以 JSON 作为请求体发送
+func (s *webhookService) Enqueue(ctx context.Context, eventType, url string, payload interface{}) (*model.WebhookDelivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrorTypeInternal, "failed to encode webhook payload")
+	}
+
+	delivery := &model.WebhookDelivery{
+		EventType:     eventType,
+		URL:           url,
+		Payload:       string(body),
+		Status:        model.WebhookStatusPending,
+		NextAttemptAt: s.clock.Now(),
+	}
+	if err := s.repo.Create(ctx, delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// RelayDue 投递已到重试时间的记录，失败的记录按指数退避安排下次投递，达到最大次数后标记为 dead
+// 同一批记录由有界工作池并发投递，并发数为 webhook.concurrency，返回前等待本批投递全部结束
+func (s *webhookService) RelayDue(ctx context.Context) (*WebhookRelayResult, error) {
+	deliveries, err := s.repo.FindDue(ctx, s.clock.Now(), s.batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := pool.New(pool.Config{Name: "webhook", Workers: s.concurrency}, pool.WithPanicHandler(func(recovered interface{}, stack []byte) {
+		logger.FromContext(ctx, s.logger).Error("Webhook delivery panicked",
+			zap.Any("panic", recovered),
+			zap.ByteString("stack", stack),
+		)
+	}))
+
+	var (
+		mu       sync.Mutex
+		result   = &WebhookRelayResult{}
+		firstErr error
+	)
+	fail := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	for _, delivery := range deliveries {
+		err := workers.Submit(ctx, func() {
+			if err := ctx.Err(); err != nil {
+				fail(err)
+				return
+			}
+			status, err := s.relay(ctx, delivery)
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch status {
+			case model.WebhookStatusDelivered:
+				result.Delivered++
+			case model.WebhookStatusDead:
+				result.Dead++
+			default:
+				result.Failed++
+			}
+		})
+		if err != nil {
+			fail(err)
+			break
+		}
+	}
+	// 已提交的投递受 ctx 和请求超时约束，这里等待它们全部结束
+	_ = workers.Shutdown(context.Background())
+
+	return result, firstErr
+}
+
+// relay 投递一条记录并更新投递状态，返回更新后的状态
+func (s *webhookService) relay(ctx context.Context, delivery *model.WebhookDelivery) (string, error) {
+	attempts := delivery.Attempts + 1
+	code, sendErr := s.send(ctx, delivery, attempts)
+	now := s.clock.Now()
+
+	values := map[string]interface{}{
+		"attempts":        attempts,
+		"response_status": code,
+	}
+	status := model.WebhookStatusDelivered
+	switch {
+	case sendErr == nil:
+		values["last_error"] = ""
+		values["delivered_at"] = now
+	case attempts >= s.maxAttempts:
+		status = model.WebhookStatusDead
+		values["last_error"] = sendErr.Error()
+	default:
+		status = model.WebhookStatusFailed
+		values["last_error"] = sendErr.Error()
+		values["next_attempt_at"] = now.Add(s.backoff(attempts))
+	}
+	values["status"] = status
+
+	if sendErr != nil {
+		logger.FromContext(ctx, s.logger).Warn("Webhook delivery failed",
+			zap.Uint("delivery_id", delivery.ID),
+			zap.String("event_type", delivery.EventType),
+			zap.Int("attempts", attempts),
+			zap.String("status", status),
+			zap.Error(sendErr),
+		)
+	}
+
+	if err := s.repo.Updates(ctx, delivery.ID, values); err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// send 发送一次投递请求，非 2xx 响应视为失败，返回响应状态码（请求未完成时为 0）
+func (s *webhookService) send(ctx context.Context, delivery *model.WebhookDelivery, attempt int) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookEvent, delivery.EventType)
+	req.Header.Set(HeaderWebhookDelivery, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(HeaderWebhookAttempt, strconv.Itoa(attempt))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// 读完响应体以复用连接
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff 返回第 attempts 次投递失败后的重试间隔，从 backoffBase 开始每次翻倍，不超过 backoffMax
+func (s *webhookService) backoff(attempts int) time.Duration {
+	delay := s.backoffBase
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= s.backoffMax || delay <= 0 {
+			return s.backoffMax
+		}
+	}
+	return min(delay, s.backoffMax)
+}
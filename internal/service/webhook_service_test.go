@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+
+	"go.uber.org/zap"
+)
+
+// memoryWebhookRepository 内存中的投递记录仓储
+type memoryWebhookRepository struct {
+	mu         sync.Mutex
+	deliveries []*model.WebhookDelivery
+}
+
+func (r *memoryWebhookRepository) Create(ctx context.Context, delivery *model.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delivery.ID = uint(len(r.deliveries) + 1)
+	r.deliveries = append(r.deliveries, delivery)
+	return nil
+}
+
+func (r *memoryWebhookRepository) FindDue(ctx context.Context, now time.Time, limit int) ([]*model.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var due []*model.WebhookDelivery
+	for _, d := range r.deliveries {
+		if (d.Status == model.WebhookStatusPending || d.Status == model.WebhookStatusFailed) && !d.NextAttemptAt.After(now) {
+			copied := *d
+			due = append(due, &copied)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryWebhookRepository) Updates(ctx context.Context, id uint, values map[string]interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	d := r.deliveries[id-1]
+	d.Status = values["status"].(string)
+	d.Attempts = values["attempts"].(int)
+	if next, ok := values["next_attempt_at"].(time.Time); ok {
+		d.NextAttemptAt = next
+	}
+	return nil
+}
+
+func TestWebhookRelayRetriesUntilDead(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get(HeaderWebhookDelivery) != "1" || r.Header.Get(HeaderWebhookEvent) != "user.created" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	clk := clock.Frozen()
+	repo := &memoryWebhookRepository{}
+	svc := NewWebhookService(repo, clk, &config.Webhook{
+		MaxAttempts: 3,
+		BackoffBase: time.Minute,
+		BackoffMax:  time.Hour,
+	}, zap.NewNop())
+
+	ctx := context.Background()
+	if _, err := svc.Enqueue(ctx, "user.created", server.URL, map[string]uint{"id": 1}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	result, err := svc.RelayDue(ctx)
+	if err != nil || result.Failed != 1 {
+		t.Fatalf("first relay: %+v, %v", result, err)
+	}
+	if want := clk.Now().Add(time.Minute); !repo.deliveries[0].NextAttemptAt.Equal(want) {
+		t.Fatalf("next attempt = %v, want %v", repo.deliveries[0].NextAttemptAt, want)
+	}
+
+	// 未到重试时间不投递
+	if result, _ := svc.RelayDue(ctx); result.Failed+result.Dead != 0 {
+		t.Fatalf("delivery should wait for backoff: %+v", result)
+	}
+
+	clk.Advance(time.Minute)
+	if result, _ := svc.RelayDue(ctx); result.Failed != 1 {
+		t.Fatalf("second relay: %+v", result)
+	}
+	if want := clk.Now().Add(2 * time.Minute); !repo.deliveries[0].NextAttemptAt.Equal(want) {
+		t.Fatalf("backoff should double: got %v, want %v", repo.deliveries[0].NextAttemptAt, want)
+	}
+
+	clk.Advance(2 * time.Minute)
+	if result, _ := svc.RelayDue(ctx); result.Dead != 1 {
+		t.Fatalf("third relay: %+v", result)
+	}
+	if repo.deliveries[0].Status != model.WebhookStatusDead || requests != 3 {
+		t.Fatalf("delivery should be dead after 3 attempts: %+v, requests = %d", repo.deliveries[0], requests)
+	}
+}
+
+func TestWebhookRelayDelivered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	repo := &memoryWebhookRepository{}
+	svc := NewWebhookService(repo, clock.Frozen(), &config.Webhook{}, zap.NewNop())
+
+	ctx := context.Background()
+	if _, err := svc.Enqueue(ctx, "user.created", server.URL, nil); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	result, err := svc.RelayDue(ctx)
+	if err != nil || result.Delivered != 1 || repo.deliveries[0].Status != model.WebhookStatusDelivered {
+		t.Fatalf("unexpected relay result: %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,9 @@
+package templates
+
+import "embed"
+
+// FS 内置的 HTML 模板（布局、片段、邮件）
+// 配置 template.dir 后可从磁盘加载同样结构的目录覆盖内置模板
+//
+//go:embed layouts partials emails
+var FS embed.FS
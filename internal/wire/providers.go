@@ -1,21 +1,63 @@
 package wire
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/hedeqiang/skeleton/internal/app"
 	"github.com/hedeqiang/skeleton/internal/config"
 	v1 "github.com/hedeqiang/skeleton/internal/handler/v1"
+	"github.com/hedeqiang/skeleton/internal/locales"
+	"github.com/hedeqiang/skeleton/internal/messaging"
+	"github.com/hedeqiang/skeleton/internal/messaging/archive"
+	"github.com/hedeqiang/skeleton/internal/messaging/consumer"
+	"github.com/hedeqiang/skeleton/internal/messaging/dryrun"
+	"github.com/hedeqiang/skeleton/internal/messaging/processors"
+	"github.com/hedeqiang/skeleton/internal/migrations"
+	"github.com/hedeqiang/skeleton/internal/policy"
 	"github.com/hedeqiang/skeleton/internal/repository"
+	"github.com/hedeqiang/skeleton/internal/router"
+	apiv1 "github.com/hedeqiang/skeleton/internal/router/api/v1"
+	"github.com/hedeqiang/skeleton/internal/router/system"
 	"github.com/hedeqiang/skeleton/internal/scheduler"
+	"github.com/hedeqiang/skeleton/internal/scheduler/jobs"
+	"github.com/hedeqiang/skeleton/internal/schemas"
 	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/internal/templates"
+	lifecycle "github.com/hedeqiang/skeleton/pkg/app"
+	"github.com/hedeqiang/skeleton/pkg/cache"
+	"github.com/hedeqiang/skeleton/pkg/clock"
 	"github.com/hedeqiang/skeleton/pkg/database"
+	"github.com/hedeqiang/skeleton/pkg/fault"
+	"github.com/hedeqiang/skeleton/pkg/health"
+	"github.com/hedeqiang/skeleton/pkg/i18n"
 	"github.com/hedeqiang/skeleton/pkg/idgen"
+	"github.com/hedeqiang/skeleton/pkg/jwt"
+	"github.com/hedeqiang/skeleton/pkg/loadshed"
 	"github.com/hedeqiang/skeleton/pkg/logger"
+	"github.com/hedeqiang/skeleton/pkg/mailer"
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+	"github.com/hedeqiang/skeleton/pkg/migrate"
+	mongopkg "github.com/hedeqiang/skeleton/pkg/mongo"
 	"github.com/hedeqiang/skeleton/pkg/mq"
+	"github.com/hedeqiang/skeleton/pkg/openapi"
+	"github.com/hedeqiang/skeleton/pkg/password"
+	"github.com/hedeqiang/skeleton/pkg/ratelimit"
 	redispkg "github.com/hedeqiang/skeleton/pkg/redis"
+	"github.com/hedeqiang/skeleton/pkg/search"
+	"github.com/hedeqiang/skeleton/pkg/storage"
+	"github.com/hedeqiang/skeleton/pkg/template"
+	"github.com/hedeqiang/skeleton/pkg/trace"
 
 	"github.com/google/wire"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 
@@ -35,21 +77,90 @@ var InfrastructureSet = wire.NewSet(
 	ProvideLoggerConfig,
 	ProvideDatabasesConfig,
 	ProvideRedisConfig,
+	ProvideMongoConfig,
+	ProvideSearchConfig,
 	ProvideRabbitMQConfig,
+	ProvideRedisStreamsConfig,
+	ProvideNATSConfig,
+	ProvideCacheConfig,
+	ProvideBloomFilterConfig,
+	ProvideUniquenessConfig,
+	ProvideTasksConfig,
+	ProvideStorageConfig,
+	ProvideUploadConfig,
+	ProvidePrivacyConfig,
+	ProvideEmailChangeConfig,
+	ProvideIdentitiesConfig,
+	ProvideMailerConfig,
+	ProvideWebhookConfig,
+	ProvideMessageArchiveConfig,
 
 	// 日志
 	logger.New,
 
+	// 指标
+	metrics.Registerer,
+	ProvideHTTPMetrics,
+	ProvideSLOMetrics,
+
+	// 链路追踪
+	ProvideTracing,
+
+	// 时钟
+	clock.New,
+
 	// 数据库
-	database.NewDatabases,
+	ProvideDataSources,
 	ProvideMainDatabase,
+	database.NewTxManager,
+	ProvideDBPoolConfig,
+	database.NewPoolMonitor,
+
+	// 自适应降载
+	ProvideLoadSheddingConfig,
+	loadshed.NewMonitor,
+
+	// 故障注入，生产环境始终不启用
+	fault.New,
 
 	// Redis
 	redispkg.NewRedis,
 
-	// RabbitMQ
-	mq.NewRabbitMQ,
+	// MongoDB
+	ProvideMongo,
+
+	// 搜索引擎
+	ProvideSearchIndices,
+
+	// 响应缓存
+	cache.NewResponseCache,
+
+	// JWT、令牌吊销、密码哈希与限流
+	jwt.NewJWT,
+	jwt.NewRevocations,
+	jwt.NewRefreshTokens,
+	password.New,
+	ratelimit.New,
+
+	// 对象存储与签名下载
+	storage.New,
+	ProvideURLSigner,
+
+	// 多语言、模板与邮件
+	ProvideI18n,
+	ProvideTemplateEngine,
+	mailer.New,
+
+	// 消息队列：RabbitMQ、Redis Streams 与 NATS JetStream
+	mq.NewBrokers,
+	ProvideRabbitMQConnection,
 	ProvideProducer,
+	mq.NewRedisStreamProducer,
+	ProvideJetStream,
+	mq.NewJetStreamProducer,
+	ProvidePublisher,
+	ProvideSchemaRegistry,
+	ProvideEventPublisher,
 
 	// ID生成器
 	ProvideIDGenerator,
@@ -57,20 +168,62 @@ var InfrastructureSet = wire.NewSet(
 
 // RepositorySet Repository 层提供者集合
 var RepositorySet = wire.NewSet(
+	repository.NewUserExistenceFilter,
 	repository.NewUserRepository,
+	repository.NewUserAuditLogRepository,
+	repository.NewUserIdentityRepository,
+	repository.NewTaskRepository,
+	repository.NewWebhookDeliveryRepository,
+	repository.NewMessageArchiveRepository,
+	repository.NewStatsRepository,
+	ProvideMessageArchiveSink,
 )
 
 // ServiceSet Service 层提供者集合
 var ServiceSet = wire.NewSet(
+	service.NewUniquenessService,
+	service.NewUserEvents,
 	service.NewUserService,
+	service.NewTokenService,
+	service.NewPasswordStrengthService,
+	service.NewAdminUserService,
+	service.NewIdentityService,
 	service.NewHelloService,
+	service.NewTaskService,
+	service.NewUploadService,
+	service.NewWebhookService,
+	service.NewMailService,
+	service.NewMigrationService,
+	service.NewSearchService,
+	service.NewStatsService,
+	ProvideUserDataSections,
+	service.NewExportPool,
+	service.NewPrivacyService,
+	service.NewMQAdminService,
+	service.NewEventService,
 )
 
 // HandlerSet Handler 层提供者集合
 var HandlerSet = wire.NewSet(
 	v1.NewUserHandler,
+	v1.NewAdminUserHandler,
+	v1.NewIdentityHandler,
 	v1.NewHelloHandler,
 	v1.NewSchedulerHandler,
+	v1.NewTaskHandler,
+	v1.NewUploadHandler,
+	v1.NewMigrationHandler,
+	v1.NewHealthHandler,
+	v1.NewSearchHandler,
+	v1.NewStatsHandler,
+	v1.NewPrivacyHandler,
+	v1.NewDownloadHandler,
+	v1.NewMQAdminHandler,
+	v1.NewEventHandler,
+	v1.NewFaultHandler,
+	v1.NewLogLevelHandler,
+	v1.NewSLOHandler,
+	ProvideRouteRegistry,
 )
 
 // SchedulerSet 调度器相关依赖
@@ -79,11 +232,49 @@ var SchedulerSet = wire.NewSet(
 	ProvideJobRegistry,
 )
 
+// MiddlewareSet 中间件依赖集合
+var MiddlewareSet = wire.NewSet(
+	wire.Struct(new(router.Middlewares), "*"),
+	ProvideReadinessChecks,
+	ProvideHealthRegistry,
+	ProvideOpenAPIValidator,
+	ProvidePolicyRegistry,
+)
+
 // AppSet App 层提供者集合
 var AppSet = wire.NewSet(
+	ProvideWarmup,
 	ProvideApp,
 )
 
+// ConsumerSet 消费者进程的消息处理器与消费服务
+var ConsumerSet = wire.NewSet(
+	processors.NewHelloProcessor,
+	processors.NewUserIndexProcessors,
+	ProvideProcessors,
+	ProvideFailurePolicies,
+	consumer.NewMessageConsumerService,
+	ProvideMessageArchiver,
+	wire.Struct(new(ConsumerApplication), "*"),
+)
+
+// DryRunConsumerSet 消费者 dry-run 模式，只加载配置和日志，处理器的外部依赖替换为 dryrun 实现
+var DryRunConsumerSet = wire.NewSet(
+	config.LoadConfig,
+	ProvideLoggerConfig,
+	logger.New,
+	ProvideDryRunProcessors,
+	ProvideSchemaRegistry,
+	ProvideFailurePolicies,
+	consumer.NewMessageConsumerService,
+	wire.Struct(new(DryRunConsumerApplication), "*"),
+)
+
+// CommandSet 命令行工具依赖
+var CommandSet = wire.NewSet(
+	wire.Struct(new(CommandApplication), "*"),
+)
+
 // AllSet 所有提供者的集合
 var AllSet = wire.NewSet(
 	InfrastructureSet,
@@ -91,9 +282,98 @@ var AllSet = wire.NewSet(
 	ServiceSet,
 	HandlerSet,
 	SchedulerSet,
+	MiddlewareSet,
 	AppSet,
 )
 
+// ConsumerApplication 消费者进程依赖，包含应用和注册了全部处理器的消费服务
+type ConsumerApplication struct {
+	App       *app.App
+	Service   *consumer.MessageConsumerService
+	Archiver  *archive.Archiver    // 未启用消息归档时为 nil
+	Publisher mq.Publisher         // 按驱动路由的发布器，Redis Streams 消费者用于转发死信
+	Stats     service.StatsService // 记录成功处理的消息数，供每日统计
+}
+
+// DryRunConsumerApplication 消费者 dry-run 模式依赖，不连接数据库、缓存和消息队列
+type DryRunConsumerApplication struct {
+	Config  *config.Config
+	Logger  *zap.Logger
+	Service *consumer.MessageConsumerService
+}
+
+// CommandApplication 命令行工具 cmd/skeleton 的依赖
+type CommandApplication struct {
+	App     *app.App
+	MQAdmin service.MQAdminService // 按交换机所属 broker 发布事件信封
+}
+
+// ProvideProcessors 提供消费服务注册的消息处理器，新增处理器时在此添加构造函数参数，并在 ProvideDryRunProcessors 中传入替代外部依赖的实例
+// consumer.disabled_processors 中的消息类型不注册处理器
+func ProvideProcessors(cfg *config.Config, hello *processors.HelloProcessor, userIndex processors.UserIndexProcessors) (consumer.Processors, error) {
+	list := consumer.Processors{hello}
+	for _, p := range userIndex {
+		list = append(list, p)
+	}
+	return list.Without(cfg.Consumer.DisabledProcessors)
+}
+
+// ProvideFailurePolicies 提供按消息类型配置的处理失败策略
+func ProvideFailurePolicies(cfg *config.Config) (*messaging.FailurePolicies, error) {
+	return messaging.NewFailurePolicies(cfg.Consumer.FailurePolicies)
+}
+
+// ProvideDryRunProcessors 提供 dry-run 模式的消息处理器，与 ProvideProcessors 注册相同的处理器
+// Hello 处理器不写 Redis，用户索引处理器只记录将要执行的索引操作
+func ProvideDryRunProcessors(cfg *config.Config, logger *zap.Logger) (consumer.Processors, error) {
+	return ProvideProcessors(cfg,
+		processors.NewHelloProcessor(logger, nil),
+		processors.NewUserIndexProcessors(dryrun.NewSearchService(logger), nil, logger),
+	)
+}
+
+// startupMigrationLockTimeout 启动迁移等待其他实例释放迁移锁的最长时间
+const startupMigrationLockTimeout = 10 * time.Minute
+
+// ProvideDataSources 初始化所有数据源，开启 auto_migrate_on_start 的数据源在返回前执行待执行的迁移
+func ProvideDataSources(dbConfigs map[string]config.Database, logger *zap.Logger) (map[string]*gorm.DB, error) {
+	dataSources, err := database.NewDatabases(dbConfigs, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(dataSources))
+	for name := range dataSources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if !dbConfigs[name].AutoMigrateOnStart {
+			continue
+		}
+
+		logger.Info("Running startup migrations", zap.String("data_source", name))
+		// 迁移不受数据源 query_timeout 限制
+		ctx := database.WithQueryTimeout(context.Background(), 0)
+		applied, err := migrate.New(dataSources[name], migrations.For(name)).UpLocked(ctx, startupMigrationLockTimeout)
+		for _, m := range applied {
+			logger.Info("Migration applied",
+				zap.String("data_source", name),
+				zap.String("version", m.Version),
+				zap.String("name", m.Name),
+			)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to run startup migrations for data source [%s]: %w", name, err)
+		}
+		if len(applied) == 0 {
+			logger.Info("No pending migrations", zap.String("data_source", name))
+		}
+	}
+	return dataSources, nil
+}
+
 // ProvideMainDatabase 提供主数据库连接
 func ProvideMainDatabase(dataSources map[string]*gorm.DB) (*gorm.DB, error) {
 	db, exists := dataSources["primary"]
@@ -118,24 +398,523 @@ func ProvideRedisConfig(cfg *config.Config) *config.Redis {
 	return &cfg.Redis
 }
 
+// ProvideDBPoolConfig 提供连接池监控配置
+func ProvideDBPoolConfig(cfg *config.Config) *config.DBPool {
+	return &cfg.DBPool
+}
+
+// ProvideLoadSheddingConfig 提供自适应降载配置
+func ProvideLoadSheddingConfig(cfg *config.Config) *config.LoadShedding {
+	return &cfg.LoadShedding
+}
+
+// ProvideMongoConfig 提供 MongoDB 配置
+func ProvideMongoConfig(cfg *config.Config) *config.Mongo {
+	return &cfg.Mongo
+}
+
+// ProvideSearchConfig 提供搜索引擎配置
+func ProvideSearchConfig(cfg *config.Config) *config.Search {
+	return &cfg.Search
+}
+
 // ProvideRabbitMQConfig 提供RabbitMQ配置
 func ProvideRabbitMQConfig(cfg *config.Config) *config.RabbitMQ {
 	return &cfg.RabbitMQ
 }
 
-// ProvideProducer 提供 MQ Producer
-func ProvideProducer(conn *amqp.Connection) *mq.Producer {
-	return mq.NewProducer(conn)
+// ProvideRedisStreamsConfig 提供 Redis Streams 消息驱动配置
+func ProvideRedisStreamsConfig(cfg *config.Config) *config.RedisStreams {
+	return &cfg.RedisStreams
+}
+
+// ProvideNATSConfig 提供 NATS JetStream 消息驱动配置
+func ProvideNATSConfig(cfg *config.Config) *config.NATS {
+	return &cfg.NATS
+}
+
+// ProvideCacheConfig 提供响应缓存配置
+// route_policies 中声明了 cache_ttl 的 GET 路由会合并为缓存规则，已有同路径规则时以 cache 配置为准
+func ProvideCacheConfig(cfg *config.Config) *config.Cache {
+	cacheCfg := cfg.Cache
+	if !cfg.RoutePolicies.Enabled {
+		return &cacheCfg
+	}
+
+	rules := append([]config.CacheRule{}, cacheCfg.Rules...)
+	for _, policy := range cfg.RoutePolicies.Rules {
+		if policy.CacheTTL <= 0 || strings.HasSuffix(policy.Path, "/*") || !allowsMethod(policy.Methods, http.MethodGet) {
+			continue
+		}
+		exists := false
+		for _, rule := range rules {
+			if rule.Path == policy.Path {
+				exists = true
+				break
+			}
+		}
+		if !exists {
+			rules = append(rules, config.CacheRule{Path: policy.Path, TTL: policy.CacheTTL})
+		}
+	}
+	cacheCfg.Rules = rules
+	return &cacheCfg
+}
+
+// allowsMethod 方法列表为空时匹配所有方法
+func allowsMethod(methods []string, method string) bool {
+	if len(methods) == 0 {
+		return true
+	}
+	for _, m := range methods {
+		if strings.EqualFold(m, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// ProvideBloomFilterConfig 提供布隆过滤器配置
+func ProvideBloomFilterConfig(cfg *config.Config) *config.BloomFilter {
+	return &cfg.BloomFilter
+}
+
+// ProvideUniquenessConfig 提供唯一性占用配置
+func ProvideUniquenessConfig(cfg *config.Config) *config.Uniqueness {
+	return &cfg.Uniqueness
+}
+
+// ProvideTasksConfig 提供后台任务配置
+func ProvideTasksConfig(cfg *config.Config) *config.Tasks {
+	return &cfg.Tasks
+}
+
+// ProvideStorageConfig 提供对象存储配置
+func ProvideStorageConfig(cfg *config.Config) *config.Storage {
+	return &cfg.Storage
+}
+
+// ProvideUploadConfig 提供分片上传配置
+func ProvideUploadConfig(cfg *config.Config) *config.Upload {
+	return &cfg.Upload
 }
 
-// ProvideSchedulerService 提供调度器服务
-func ProvideSchedulerService(logger *zap.Logger) (*scheduler.SchedulerService, error) {
-	return scheduler.NewSchedulerService(logger)
+// ProvidePrivacyConfig 提供用户数据导出与账户删除配置
+func ProvidePrivacyConfig(cfg *config.Config) *config.Privacy {
+	return &cfg.Privacy
+}
+
+// ProvideEmailChangeConfig 提供修改邮箱确认配置
+func ProvideEmailChangeConfig(cfg *config.Config) *config.EmailChange {
+	return &cfg.EmailChange
+}
+
+// ProvideIdentitiesConfig 提供外部账号关联配置
+func ProvideIdentitiesConfig(cfg *config.Config) *config.Identities {
+	return &cfg.Identities
+}
+
+// ProvideHTTPMetrics 提供 HTTP 请求指标，未开启 http_metrics 时为 nil
+func ProvideHTTPMetrics(cfg *config.Config, reg prometheus.Registerer) (*metrics.HTTPMetrics, error) {
+	if !cfg.HTTPMetrics.Enabled {
+		return nil, nil
+	}
+	return metrics.NewHTTPMetrics(reg, cfg.HTTPMetrics.DurationBuckets)
+}
+
+// ProvideSLOMetrics 提供按路由的服务等级目标指标，未开启 slo 时为 nil
+func ProvideSLOMetrics(cfg *config.Config, reg prometheus.Registerer) (*metrics.SLOMetrics, error) {
+	if !cfg.SLO.Enabled {
+		return nil, nil
+	}
+	objectives := make([]metrics.Objective, 0, len(cfg.SLO.Objectives))
+	for _, o := range cfg.SLO.Objectives {
+		objectives = append(objectives, metrics.Objective{
+			Name:          o.Name,
+			Availability:  o.Availability,
+			Latency:       o.Latency,
+			LatencyTarget: o.LatencyTarget,
+		})
+	}
+	return metrics.NewSLOMetrics(reg, objectives, cfg.SLO.Windows)
+}
+
+// ProvideTracing 提供 OpenTelemetry TracerProvider，未开启 trace 时为 nil，span 由全局的 no-op Provider 丢弃
+func ProvideTracing(cfg *config.Config) (*trace.Provider, error) {
+	if !cfg.Trace.Enabled {
+		return nil, nil
+	}
+	return trace.NewProvider(&cfg.Trace, &cfg.App)
+}
+
+// ProvideURLSigner 提供下载地址签名器，未配置 storage.signing_secret 时为 nil
+func ProvideURLSigner(cfg *config.Storage) *storage.URLSigner {
+	return storage.NewURLSigner(cfg.SigningSecret)
+}
+
+// ProvideUserDataSections 提供保存用户数据的各部分，导出和彻底删除按顺序处理
+// 新增保存用户数据的表时在这里注册，引用用户的部分排在用户资料之前
+func ProvideUserDataSections(userRepo repository.UserRepository, auditRepo repository.UserAuditLogRepository, identityRepo repository.UserIdentityRepository) service.UserDataSections {
+	return service.UserDataSections{
+		service.NewAuditReferenceSection(userRepo),
+		service.NewUserAuditLogSection(auditRepo),
+		service.NewUserIdentitySection(identityRepo),
+		service.NewUserProfileSection(userRepo),
+	}
+}
+
+// ProvideMailerConfig 提供邮件发送配置
+func ProvideMailerConfig(cfg *config.Config) *config.Mailer {
+	return &cfg.Mailer
+}
+
+// ProvideWebhookConfig 提供出站 Webhook 配置
+func ProvideWebhookConfig(cfg *config.Config) *config.Webhook {
+	return &cfg.Webhook
+}
+
+// ProvideMessageArchiveConfig 提供消息归档配置
+func ProvideMessageArchiveConfig(cfg *config.Config) *config.MessageArchive {
+	return &cfg.Archive
+}
+
+// ProvideMessageArchiveSink 提供消息归档存储，未启用时返回 nil
+func ProvideMessageArchiveSink(cfg *config.MessageArchive, repo repository.MessageArchiveRepository, store storage.Storage) (archive.Sink, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	return archive.NewSink(cfg, repo, store)
+}
+
+// ProvideMessageArchiver 提供消费消息归档器，未启用时返回 nil
+func ProvideMessageArchiver(sink archive.Sink, cfg *config.MessageArchive, clk clock.Clock, logger *zap.Logger) *archive.Archiver {
+	if sink == nil {
+		return nil
+	}
+	return archive.NewArchiver(sink, cfg, clk, logger)
+}
+
+// ProvideI18n 提供多语言消息目录
+// 配置了 i18n.dir 时从磁盘加载，否则使用内置消息文件
+func ProvideI18n(cfg *config.Config) (*i18n.I18n, error) {
+	var fsys fs.FS = locales.FS
+	if cfg.I18n.Dir != "" {
+		fsys = os.DirFS(cfg.I18n.Dir)
+	}
+	lang := cfg.I18n.DefaultLanguage
+	if lang == "" {
+		lang = "zh"
+	}
+	return i18n.New(fsys, lang,
+		i18n.WithSupportedLanguages(cfg.I18n.SupportedLanguages...),
+		i18n.WithFallbacks(cfg.I18n.Fallbacks),
+	)
+}
+
+// ProvideOpenAPIValidator 提供 OpenAPI 契约校验器
+// 未开启或运行在生产环境时返回 nil，不注册校验中间件
+func ProvideOpenAPIValidator(cfg *config.Config) (*openapi.Validator, error) {
+	if !cfg.OpenAPI.Enabled || cfg.App.IsProduction() {
+		return nil, nil
+	}
+	return openapi.Load(cfg.OpenAPI.Spec)
+}
+
+// ProvideTemplateEngine 提供 HTML 模板引擎
+// 配置了 template.dir 时从磁盘加载，否则使用内置模板；
+// 模板中的 t、formatNumber、formatCurrency、formatDate 函数按请求语言翻译和格式化
+func ProvideTemplateEngine(cfg *config.Config, bundle *i18n.I18n) *template.Engine {
+	var fsys fs.FS = templates.FS
+	if cfg.Template.Dir != "" {
+		fsys = os.DirFS(cfg.Template.Dir)
+	}
+	return template.New(fsys,
+		template.WithReload(cfg.Template.Reload),
+		template.WithTranslator(bundle.T),
+		template.WithContextFuncs(bundle.Funcs),
+	)
+}
+
+// ProvideRabbitMQConnection 提供 default broker 的生产连接，未配置 default broker（只使用 Redis Streams）时为 nil
+func ProvideRabbitMQConnection(brokers *mq.Brokers) (*amqp.Connection, error) {
+	if !brokers.Has(config.DefaultBroker) {
+		return nil, nil
+	}
+	return brokers.Connection(config.DefaultBroker, mq.RoleProducer)
+}
+
+// ProvideSchemaRegistry 提供消息载荷 schema 注册表，未开启 message_schemas 时为 nil
+func ProvideSchemaRegistry(cfg *config.Config) (*mq.SchemaRegistry, error) {
+	if !cfg.Schemas.Enabled {
+		return nil, nil
+	}
+	return schemas.Load(cfg.Schemas.Dir)
+}
+
+// ProvideEventPublisher 提供业务事件发布器，信封 source 为应用名称，开启 message_schemas 时发布前校验载荷
+func ProvideEventPublisher(publisher mq.Publisher, idGenerator idgen.IDGenerator, clk clock.Clock, cfg *config.Config, schemas *mq.SchemaRegistry) *mq.EventPublisher {
+	return mq.NewEventPublisher(publisher, idGenerator, clk, cfg.App.Name, mq.WithSchemaRegistry(schemas))
+}
+
+// ProvideProducer 提供 default broker 的 MQ Producer，连接断开后自动重连；未配置 default broker 时为 nil
+func ProvideProducer(brokers *mq.Brokers) (*mq.Producer, error) {
+	if !brokers.Has(config.DefaultBroker) {
+		return nil, nil
+	}
+	return brokers.Producer(config.DefaultBroker)
+}
+
+// ProvideMongo 提供 MongoDB 客户端，未启用时为 nil
+func ProvideMongo(cfg *config.Config, mongoCfg *config.Mongo) (*mongopkg.Client, error) {
+	if !mongoCfg.Enabled {
+		return nil, nil
+	}
+	return mongopkg.New(mongoCfg, cfg.App.Name)
+}
+
+// ProvideSearchIndices 提供搜索索引管理器，未启用搜索时为 nil
+func ProvideSearchIndices(searchCfg *config.Search) (*search.Manager, error) {
+	if !searchCfg.Enabled {
+		return nil, nil
+	}
+	engine, err := search.New(searchCfg)
+	if err != nil {
+		return nil, err
+	}
+	return search.NewManager(engine, searchCfg.IndexPrefix), nil
+}
+
+// ProvideJetStream 提供 NATS JetStream 连接，没有 driver 为 nats 的队列时为 nil
+func ProvideJetStream(cfg *config.Config, natsCfg *config.NATS) (*mq.JetStream, error) {
+	if !cfg.RabbitMQ.UsesDriver(config.DriverNATS) {
+		return nil, nil
+	}
+	return mq.NewJetStream(natsCfg, cfg.App.Name)
+}
+
+// ProvidePublisher 提供按交换机所属驱动路由的发布器
+func ProvidePublisher(cfg *config.RabbitMQ, producer *mq.Producer, streams *mq.RedisStreamProducer, jetStream *mq.JetStreamProducer) mq.Publisher {
+	return mq.NewRoutedPublisher(cfg, producer, streams, jetStream)
+}
+
+// ProvideSchedulerService 提供调度器服务，scheduler.timezone 无法识别时启动失败
+func ProvideSchedulerService(logger *zap.Logger, cfg *config.Config) (*scheduler.SchedulerService, error) {
+	return scheduler.NewSchedulerService(logger, cfg.Scheduler.Timezone)
 }
 
 // ProvideJobRegistry 提供任务注册器
-func ProvideJobRegistry(schedulerService *scheduler.SchedulerService, logger *zap.Logger, cfg *config.Config) *scheduler.JobRegistry {
-	return scheduler.NewJobRegistry(schedulerService, logger, cfg.Scheduler)
+func ProvideJobRegistry(
+	schedulerService *scheduler.SchedulerService,
+	logger *zap.Logger,
+	clk clock.Clock,
+	cfg *config.Config,
+	userExistenceFilter *repository.UserExistenceFilter,
+	taskService service.TaskService,
+	uploadService service.UploadService,
+	webhookService service.WebhookService,
+	statsService service.StatsService,
+	privacyService service.PrivacyService,
+	archiveSink archive.Sink,
+	dataSources map[string]*gorm.DB,
+) *scheduler.JobRegistry {
+	registry := scheduler.NewJobRegistry(schedulerService, logger, clk, cfg.Scheduler)
+
+	// 注册依赖基础设施的任务
+	registry.RegisterJob("user_filter_rebuild_job", func(logger *zap.Logger) scheduler.Job {
+		return jobs.NewUserFilterRebuildJob(logger, userExistenceFilter)
+	})
+	registry.RegisterJob("task_cleanup_job", func(logger *zap.Logger) scheduler.Job {
+		return jobs.NewTaskCleanupJob(logger, taskService)
+	})
+	registry.RegisterJob("upload_cleanup_job", func(logger *zap.Logger) scheduler.Job {
+		return jobs.NewUploadCleanupJob(logger, uploadService)
+	})
+	registry.RegisterJob("webhook_relay_job", func(logger *zap.Logger) scheduler.Job {
+		return jobs.NewWebhookRelayJob(logger, webhookService)
+	})
+	registry.RegisterJob("daily_stats_job", func(logger *zap.Logger) scheduler.Job {
+		return jobs.NewDailyStatsJob(logger, statsService, clk)
+	})
+	registry.RegisterJob("user_erasure_job", func(logger *zap.Logger) scheduler.Job {
+		return jobs.NewUserErasureJob(logger, privacyService)
+	})
+	registry.RegisterJob("retention_job", func(logger *zap.Logger) scheduler.Job {
+		return jobs.NewRetentionJob(logger, dataSources, clk, cfg.Retention)
+	})
+	if archiveSink != nil {
+		registry.RegisterJob("message_archive_cleanup_job", func(logger *zap.Logger) scheduler.Job {
+			return jobs.NewMessageArchiveCleanupJob(logger, archiveSink, clk, cfg.Archive.Retention)
+		})
+	}
+
+	return registry
+}
+
+// ProvideRouteRegistry 提供 v1 路由注册表
+// 新增业务模块时在 HandlerSet 中添加处理器构造函数，并在这里注册对应的路由
+func ProvideRouteRegistry(
+	userHandler *v1.UserHandler,
+	adminUserHandler *v1.AdminUserHandler,
+	identityHandler *v1.IdentityHandler,
+	helloHandler *v1.HelloHandler,
+	schedulerHandler *v1.SchedulerHandler,
+	taskHandler *v1.TaskHandler,
+	uploadHandler *v1.UploadHandler,
+	migrationHandler *v1.MigrationHandler,
+	healthHandler *v1.HealthHandler,
+	searchHandler *v1.SearchHandler,
+	statsHandler *v1.StatsHandler,
+	privacyHandler *v1.PrivacyHandler,
+	downloadHandler *v1.DownloadHandler,
+	mqAdminHandler *v1.MQAdminHandler,
+	eventHandler *v1.EventHandler,
+	faultHandler *v1.FaultHandler,
+	logLevelHandler *v1.LogLevelHandler,
+	sloHandler *v1.SLOHandler,
+) *apiv1.Registry {
+	return apiv1.NewRegistry(
+		apiv1.Bind(userHandler, apiv1.RegisterUserRoutes),           // 用户相关路由
+		apiv1.Bind(userHandler, apiv1.RegisterAuthRoutes),           // 认证相关路由
+		apiv1.Bind(adminUserHandler, apiv1.RegisterAdminUserRoutes), // 用户管理路由
+		apiv1.Bind(identityHandler, apiv1.RegisterIdentityRoutes),   // 外部账号关联路由
+		apiv1.Bind(helloHandler, apiv1.RegisterMessageRoutes),       // 消息队列路由
+		apiv1.Bind(schedulerHandler, apiv1.RegisterSchedulerRoutes), // 计划任务路由
+		apiv1.Bind(taskHandler, apiv1.RegisterTaskRoutes),           // 后台任务路由
+		apiv1.Bind(uploadHandler, apiv1.RegisterUploadRoutes),       // 分片上传路由
+		apiv1.Bind(migrationHandler, apiv1.RegisterMigrationRoutes), // 数据库迁移管理路由
+		apiv1.Bind(healthHandler, apiv1.RegisterHealthRoutes),       // 依赖健康检查路由
+		apiv1.Bind(searchHandler, apiv1.RegisterSearchRoutes),       // 搜索路由，未启用搜索时不注册
+		apiv1.Bind(statsHandler, apiv1.RegisterStatsRoutes),         // 每日统计路由
+		apiv1.Bind(privacyHandler, apiv1.RegisterPrivacyRoutes),     // 用户数据导出与账户删除路由
+		apiv1.Bind(downloadHandler, apiv1.RegisterDownloadRoutes),   // 签名下载路由，未配置签名密钥时不注册
+		apiv1.Bind(mqAdminHandler, apiv1.RegisterMQAdminRoutes),     // 消息队列管理路由
+		apiv1.Bind(eventHandler, apiv1.RegisterEventRoutes),         // 通用事件发布路由，未启用 event_publish 时不注册
+		apiv1.Bind(faultHandler, apiv1.RegisterFaultRoutes),         // 故障注入管理路由，未启用故障注入时不注册
+		apiv1.Bind(logLevelHandler, apiv1.RegisterLogLevelRoutes),   // 日志级别管理路由
+		apiv1.Bind(sloHandler, apiv1.RegisterSLORoutes),             // 错误预算查询路由，未开启 slo 时不注册
+	)
+}
+
+// ProvidePolicyRegistry 提供资源级授权策略注册表
+// 新增需要按所有者授权的资源时在这里注册策略，并在 authorization.rules 中映射路由
+func ProvidePolicyRegistry(cfg *config.Config, taskService service.TaskService) *policy.Registry {
+	adminRoles := cfg.Authorization.AdminRoles
+	if len(adminRoles) == 0 {
+		adminRoles = []string{"admin"}
+	}
+
+	registry := policy.NewRegistry()
+	registry.Register(policy.ResourceUser, policy.NewUserPolicy(adminRoles...))
+	registry.Register(policy.ResourceTask, policy.NewTaskPolicy(taskService.TaskOwner, adminRoles...))
+	return registry
+}
+
+// ProvideReadinessChecks 提供 /ready 使用的就绪检查，超时与健康检查共用 health.timeout 和 health.checks.<name>.timeout
+// 数据库、Redis 和默认 RabbitMQ broker 任一不可用时拒绝流量
+func ProvideReadinessChecks(cfg *config.Config, mainDB *gorm.DB, redisClient *redis.Client, brokers *mq.Brokers, mongoClient *mongopkg.Client, migrationService service.MigrationService, warmup *lifecycle.Warmup) system.ReadinessChecks {
+	checks := system.NewReadinessChecks()
+	add := func(name string, fn health.CheckFunc) {
+		checks.Add(name, healthCheckTimeout(cfg, name), fn)
+	}
+
+	// 启动预热完成前拒绝流量
+	if warmup != nil {
+		add("warmup", warmup.Check)
+	}
+
+	add("database", pingDatabase(mainDB))
+	add("redis", pingRedis(redisClient))
+	if brokers.Has(config.DefaultBroker) {
+		add("rabbitmq", pingBroker(brokers, config.DefaultBroker))
+	}
+
+	// 启用 MongoDB 时，连接不可用则拒绝流量
+	if mongoClient != nil {
+		add("mongo", mongoClient.Ping)
+	}
+
+	// 存在待执行迁移时拒绝流量，避免新代码运行在旧表结构上
+	if cfg.Migration.RequireApplied {
+		add("migrations", checkPendingMigrations(migrationService))
+	}
+
+	return checks
+}
+
+// ProvideHealthRegistry 提供依赖健康检查注册表，检查级别和超时可在 health.checks 中按名称覆盖
+func ProvideHealthRegistry(cfg *config.Config, mainDB *gorm.DB, redisClient *redis.Client, mongoClient *mongopkg.Client, searchIndices *search.Manager, brokers *mq.Brokers, jetStream *mq.JetStream, migrationService service.MigrationService) *health.Registry {
+	registry := health.NewRegistry()
+	register := func(name string, level health.Level, fn health.CheckFunc) {
+		if override, ok := cfg.Health.Checks[name]; ok && override.Level != "" {
+			level = health.Level(override.Level)
+		}
+		registry.Register(name, level, healthCheckTimeout(cfg, name), fn)
+	}
+
+	register("database", health.Critical, pingDatabase(mainDB))
+	register("redis", health.Critical, pingRedis(redisClient))
+	if mongoClient != nil {
+		register("mongo", health.Critical, mongoClient.Ping)
+	}
+	if searchIndices != nil {
+		register("search", health.Optional, searchIndices.Engine().Ping)
+	}
+	if brokers.Has(config.DefaultBroker) {
+		register("rabbitmq", health.Optional, pingBroker(brokers, config.DefaultBroker))
+	}
+	if jetStream != nil {
+		register("nats", health.Optional, jetStream.Ping)
+	}
+	register("migrations", health.Optional, checkPendingMigrations(migrationService))
+
+	return registry
+}
+
+// healthCheckTimeout 返回检查的超时时间，health.checks 中未覆盖时使用 health.timeout
+func healthCheckTimeout(cfg *config.Config, name string) time.Duration {
+	if override, ok := cfg.Health.Checks[name]; ok && override.Timeout > 0 {
+		return override.Timeout
+	}
+	return cfg.Health.Timeout
+}
+
+// pingDatabase 检查数据库连接是否可用
+func pingDatabase(db *gorm.DB) health.CheckFunc {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// pingRedis 检查 Redis 连接是否可用
+func pingRedis(client *redis.Client) health.CheckFunc {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// pingBroker 检查 broker 的生产连接是否可用，连接已断开时检查失败并在后台重连，不阻塞检查
+func pingBroker(brokers *mq.Brokers, name string) health.CheckFunc {
+	return func(ctx context.Context) error {
+		return brokers.Ping(ctx, name)
+	}
+}
+
+// checkPendingMigrations 存在待执行迁移时返回错误
+func checkPendingMigrations(migrationService service.MigrationService) health.CheckFunc {
+	return func(ctx context.Context) error {
+		count, err := migrationService.PendingCount(ctx)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return fmt.Errorf("%d pending migrations", count)
+		}
+		return nil
+	}
 }
 
 // ProvideApp 提供应用实例
@@ -145,12 +924,20 @@ func ProvideApp(
 	dataSources map[string]*gorm.DB,
 	mainDB *gorm.DB,
 	redisClient *redis.Client,
+	mongoClient *mongopkg.Client,
 	rabbitMQ *amqp.Connection,
+	brokers *mq.Brokers,
+	jetStream *mq.JetStream,
 	idGenerator idgen.IDGenerator,
-	userHandler *v1.UserHandler,
-	helloHandler *v1.HelloHandler,
-	schedulerHandler *v1.SchedulerHandler,
+	routes *apiv1.Registry,
+	readinessChecks system.ReadinessChecks,
 	jobRegistry *scheduler.JobRegistry,
+	taskService service.TaskService,
+	mailService service.MailService,
+	searchService service.SearchService,
+	exportPool service.ExportPool,
+	middlewares *router.Middlewares,
+	warmup *lifecycle.Warmup,
 ) *app.App {
 	return app.NewApp(
 		logger,
@@ -158,15 +945,68 @@ func ProvideApp(
 		dataSources,
 		mainDB,
 		redisClient,
+		mongoClient,
 		rabbitMQ,
+		brokers,
+		jetStream,
 		idGenerator,
-		userHandler,
-		helloHandler,
-		schedulerHandler,
+		routes,
+		readinessChecks,
 		jobRegistry,
+		taskService,
+		mailService,
+		searchService,
+		exportPool,
+		middlewares,
+		warmup,
 	)
 }
 
+// ProvideWarmup 提供启动预热阶段，未启用时返回 nil
+// 内置任务: database 预先建立数据库连接，redis 预先建立 Redis 连接，templates 预先解析邮件模板
+func ProvideWarmup(cfg *config.Config, logger *zap.Logger, dataSources map[string]*gorm.DB, redisClient *redis.Client, mailService service.MailService) *lifecycle.Warmup {
+	if !cfg.Warmup.Enabled {
+		return nil
+	}
+
+	warmup := lifecycle.NewWarmup(logger, cfg.Warmup.Timeout)
+	register := func(name string, run func(ctx context.Context) error) {
+		override := cfg.Warmup.Tasks[name]
+		if override.Disabled {
+			return
+		}
+		warmup.Register(lifecycle.WarmupTask{
+			Name:     name,
+			Timeout:  override.Timeout,
+			Required: override.Required,
+			Run:      run,
+		})
+	}
+
+	if n := cfg.Warmup.DBConnections; n > 0 {
+		register("database", func(ctx context.Context) error {
+			for name, db := range dataSources {
+				sqlDB, err := db.DB()
+				if err != nil {
+					return err
+				}
+				if err := database.OpenConnections(ctx, sqlDB, n); err != nil {
+					return fmt.Errorf("data source %s: %w", name, err)
+				}
+			}
+			return nil
+		})
+	}
+	if n := cfg.Warmup.RedisConnections; n > 0 && redisClient != nil {
+		register("redis", func(ctx context.Context) error {
+			return redispkg.OpenConnections(ctx, redisClient, n)
+		})
+	}
+	register("templates", mailService.Preload)
+
+	return warmup
+}
+
 // ProvideIDGenerator 提供ID生成器
 func ProvideIDGenerator(cfg *config.Config, logger *zap.Logger) (idgen.IDGenerator, error) {
 	// 如果配置中有ID生成器配置，使用自定义配置
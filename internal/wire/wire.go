@@ -15,3 +15,21 @@ func InitializeApplication() (*app.App, error) {
 	wire.Build(AllSet)
 	return &app.App{}, nil
 }
+
+// InitializeConsumer 初始化消费者进程，消息处理器的依赖通过构造函数注入
+func InitializeConsumer() (*ConsumerApplication, error) {
+	wire.Build(AllSet, ConsumerSet)
+	return &ConsumerApplication{}, nil
+}
+
+// InitializeDryRunConsumer 初始化 dry-run 模式的消息处理器，不创建外部连接
+func InitializeDryRunConsumer() (*DryRunConsumerApplication, error) {
+	wire.Build(DryRunConsumerSet)
+	return &DryRunConsumerApplication{}, nil
+}
+
+// InitializeCommand 初始化命令行工具
+func InitializeCommand() (*CommandApplication, error) {
+	wire.Build(AllSet, CommandSet)
+	return &CommandApplication{}, nil
+}
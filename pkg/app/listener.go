@@ -0,0 +1,83 @@
+package app
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// systemdListenFDsStart systemd socket activation 传入的第一个文件描述符
+const systemdListenFDsStart = 3
+
+// ListenUnix 在 Unix socket 上监听，mode 非 0 时设置 socket 文件权限
+// 上次异常退出遗留的 socket 文件会被删除；路径已存在但不是 socket 时返回错误，避免误删普通文件。
+// 监听器关闭时 socket 文件随之删除
+func ListenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("listen unix %s: path exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("listen unix %s: remove stale socket: %w", path, err)
+		}
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("listen unix %s: chmod: %w", path, err)
+		}
+	}
+	return listener, nil
+}
+
+// SystemdListener 返回 systemd socket activation 传入的监听器
+// name 对应 .socket 单元的 FileDescriptorName，为空时使用第一个传入的 socket。
+// 读取后清除 LISTEN_* 环境变量，避免子进程误认为 socket 是传给自己的
+func SystemdListener(name string) (net.Listener, error) {
+	fd, err := systemdFD(name)
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	if err != nil {
+		return nil, err
+	}
+
+	// FileListener 复制文件描述符，原描述符随即关闭
+	file := os.NewFile(uintptr(fd), "systemd:"+name)
+	defer file.Close()
+	listener, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("listen systemd: %w", err)
+	}
+	return listener, nil
+}
+
+// systemdFD 按 LISTEN_PID、LISTEN_FDS、LISTEN_FDNAMES 查找 socket 对应的文件描述符
+func systemdFD(name string) (int, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return 0, fmt.Errorf("listen systemd: no sockets passed to this process (LISTEN_PID=%q)", os.Getenv("LISTEN_PID"))
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return 0, fmt.Errorf("listen systemd: invalid LISTEN_FDS %q", os.Getenv("LISTEN_FDS"))
+	}
+	if name == "" {
+		return systemdListenFDsStart, nil
+	}
+
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	for i := 0; i < count && i < len(names); i++ {
+		if names[i] == name {
+			return systemdListenFDsStart + i, nil
+		}
+	}
+	return 0, fmt.Errorf("listen systemd: no socket named %q in LISTEN_FDNAMES %q", name, os.Getenv("LISTEN_FDNAMES"))
+}
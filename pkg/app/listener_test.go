@@ -0,0 +1,79 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestListenUnix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.sock")
+
+	listener, err := ListenUnix(path, 0o660)
+	if err != nil {
+		t.Fatalf("ListenUnix failed: %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("socket file not created: %v", err)
+	}
+	if info.Mode()&os.ModeSocket == 0 || info.Mode().Perm() != 0o660 {
+		t.Errorf("mode = %v, want socket with 0660", info.Mode())
+	}
+
+	// 模拟异常退出遗留的 socket 文件：关闭监听器但保留文件
+	listener.(interface{ SetUnlinkOnClose(bool) }).SetUnlinkOnClose(false)
+	listener.Close()
+	listener, err = ListenUnix(path, 0)
+	if err != nil {
+		t.Fatalf("stale socket should be replaced: %v", err)
+	}
+	listener.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("socket file should be removed on close, stat err = %v", err)
+	}
+
+	regular := filepath.Join(t.TempDir(), "regular")
+	if err := os.WriteFile(regular, []byte("data"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ListenUnix(regular, 0); err == nil {
+		t.Error("expected error for a path that is not a socket")
+	}
+}
+
+func TestSystemdFD(t *testing.T) {
+	pid := strconv.Itoa(os.Getpid())
+	tests := []struct {
+		name    string
+		env     [3]string // LISTEN_PID, LISTEN_FDS, LISTEN_FDNAMES
+		fdName  string
+		want    int
+		wantErr bool
+	}{
+		{name: "first socket", env: [3]string{pid, "2", "public:internal"}, want: 3},
+		{name: "named socket", env: [3]string{pid, "2", "public:internal"}, fdName: "internal", want: 4},
+		{name: "unknown name", env: [3]string{pid, "2", "public:internal"}, fdName: "admin", wantErr: true},
+		{name: "name beyond count", env: [3]string{pid, "1", "public:internal"}, fdName: "internal", wantErr: true},
+		{name: "other process", env: [3]string{"1", "1", ""}, wantErr: true},
+		{name: "not activated", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("LISTEN_PID", tt.env[0])
+			t.Setenv("LISTEN_FDS", tt.env[1])
+			t.Setenv("LISTEN_FDNAMES", tt.env[2])
+			fd, err := systemdFD(tt.fdName)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got fd %d", fd)
+				}
+				return
+			}
+			if err != nil || fd != tt.want {
+				t.Fatalf("systemdFD(%q) = %d, %v; want %d", tt.fdName, fd, err, tt.want)
+			}
+		})
+	}
+}
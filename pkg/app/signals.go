@@ -0,0 +1,116 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/pprof"
+	"syscall"
+
+	appconfig "github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// ReloadFunc SIGHUP 时使用重新加载的配置执行的回调
+type ReloadFunc func(cfg *appconfig.Config) error
+
+// SignalOption 信号处理可选参数
+type SignalOption func(*signalHandler)
+
+// WithReload 注册 SIGHUP 重新加载配置后执行的回调，日志级别总是会重新加载
+func WithReload(fn ReloadFunc) SignalOption {
+	return func(h *signalHandler) {
+		h.reloads = append(h.reloads, fn)
+	}
+}
+
+// signalHandler 运行期信号处理
+type signalHandler struct {
+	logger  *zap.Logger
+	reloads []ReloadFunc
+}
+
+// WaitForShutdown 阻塞直到收到 SIGINT 或 SIGTERM，返回收到的信号
+// 等待期间处理运行期信号 (Windows 不支持)：
+//   - SIGHUP: 重新加载配置文件并应用日志级别（包括 logger.levels），然后执行 WithReload 注册的回调
+//   - SIGUSR1: 将所有 goroutine 的调用栈输出到日志
+//   - SIGUSR2: 在 debug 级别和配置的日志级别之间切换，配置了 logger.toggle_debug 时只切换其中的 logger
+func WaitForShutdown(zapLogger *zap.Logger, opts ...SignalOption) os.Signal {
+	h := &signalHandler{logger: zapLogger}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, append([]os.Signal{syscall.SIGINT, syscall.SIGTERM}, runtimeSignals...)...)
+	defer signal.Stop(signals)
+
+	for sig := range signals {
+		if sig == syscall.SIGINT || sig == syscall.SIGTERM {
+			return sig
+		}
+		h.handle(sig)
+	}
+	return nil
+}
+
+// handle 处理运行期信号
+func (h *signalHandler) handle(sig os.Signal) {
+	switch signalAction(sig) {
+	case actionReload:
+		h.reload()
+	case actionDumpStacks:
+		h.dumpStacks()
+	case actionToggleDebug:
+		level := logger.ToggleDebug()
+		h.logger.Info("Log level toggled",
+			zap.String("signal", sig.String()),
+			zap.String("level", level.String()),
+			zap.Any("loggers", logger.NamedLevels()),
+		)
+	}
+}
+
+// reload 重新加载配置并应用日志级别
+func (h *signalHandler) reload() {
+	cfg, err := appconfig.LoadConfig()
+	if err != nil {
+		h.logger.Error("Failed to reload config", zap.Error(err))
+		return
+	}
+
+	if err := logger.Configure(&cfg.Logger); err != nil {
+		h.logger.Error("Invalid log level in reloaded config", zap.String("level", cfg.Logger.Level), zap.Error(err))
+	}
+
+	for _, fn := range h.reloads {
+		if err := fn(cfg); err != nil {
+			h.logger.Error("Config reload hook failed", zap.Error(err))
+		}
+	}
+	h.logger.Info("Config reloaded", zap.String("log_level", logger.Level().String()), zap.Any("loggers", logger.NamedLevels()))
+}
+
+// dumpStacks 输出所有 goroutine 的调用栈
+func (h *signalHandler) dumpStacks() {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 2); err != nil {
+		h.logger.Error("Failed to dump goroutine stacks", zap.Error(err))
+		return
+	}
+	h.logger.Info("Goroutine dump",
+		zap.Int("goroutines", runtime.NumGoroutine()),
+		zap.String("stacks", buf.String()),
+	)
+}
+
+// 运行期信号对应的操作
+const (
+	actionNone = iota
+	actionReload
+	actionDumpStacks
+	actionToggleDebug
+)
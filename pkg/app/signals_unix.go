@@ -0,0 +1,25 @@
+//go:build !windows
+
+package app
+
+import (
+	"os"
+	"syscall"
+)
+
+// runtimeSignals 除退出信号外需要监听的运行期信号
+var runtimeSignals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR1, syscall.SIGUSR2}
+
+// signalAction 返回运行期信号对应的操作
+func signalAction(sig os.Signal) int {
+	switch sig {
+	case syscall.SIGHUP:
+		return actionReload
+	case syscall.SIGUSR1:
+		return actionDumpStacks
+	case syscall.SIGUSR2:
+		return actionToggleDebug
+	default:
+		return actionNone
+	}
+}
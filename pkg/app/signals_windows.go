@@ -0,0 +1,13 @@
+//go:build windows
+
+package app
+
+import "os"
+
+// runtimeSignals Windows 不支持 SIGHUP/SIGUSR1/SIGUSR2
+var runtimeSignals []os.Signal
+
+// signalAction 返回运行期信号对应的操作
+func signalAction(os.Signal) int {
+	return actionNone
+}
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultWarmupTimeout 任务未设置超时时使用的默认值
+const defaultWarmupTimeout = 10 * time.Second
+
+// WarmupTask 启动预热任务，如预先建立连接、解析模板、拉取远程配置
+type WarmupTask struct {
+	Name     string
+	Timeout  time.Duration // 为 0 时使用 Warmup 的默认超时
+	Required bool          // 失败时保持未就绪，否则只记录日志
+	Run      func(ctx context.Context) error
+}
+
+// WarmupResult 单个预热任务的执行结果
+type WarmupResult struct {
+	Name     string
+	Duration time.Duration
+	Err      error
+}
+
+// Warmup 启动预热阶段，所有任务并发执行，完成前就绪检查返回错误，避免冷实例接入流量
+type Warmup struct {
+	logger  *zap.Logger
+	timeout time.Duration
+
+	mu      sync.Mutex
+	tasks   []WarmupTask
+	started bool
+	done    bool
+	results []WarmupResult
+	err     error
+}
+
+// NewWarmup 创建预热阶段，timeout 为任务默认超时
+func NewWarmup(logger *zap.Logger, timeout time.Duration) *Warmup {
+	if timeout <= 0 {
+		timeout = defaultWarmupTimeout
+	}
+	return &Warmup{logger: logger, timeout: timeout}
+}
+
+// Register 注册预热任务，需在 Run 之前调用
+func (w *Warmup) Register(task WarmupTask) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.tasks = append(w.tasks, task)
+}
+
+// Run 并发执行所有任务并等待完成，必须成功的任务失败时返回错误
+// 每个任务的耗时和结果都会记录日志，nil 接收者直接返回
+func (w *Warmup) Run(ctx context.Context) error {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	if w.started {
+		w.mu.Unlock()
+		return fmt.Errorf("warmup already started")
+	}
+	w.started = true
+	tasks := append([]WarmupTask(nil), w.tasks...)
+	w.mu.Unlock()
+
+	start := time.Now()
+	results := make([]WarmupResult, len(tasks))
+	var wg sync.WaitGroup
+	for i, task := range tasks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = w.runTask(ctx, task)
+		}()
+	}
+	wg.Wait()
+
+	var failed []string
+	for i, result := range results {
+		if result.Err != nil && tasks[i].Required {
+			failed = append(failed, result.Name)
+		}
+	}
+	var err error
+	if len(failed) > 0 {
+		err = fmt.Errorf("warmup tasks failed: %s", strings.Join(failed, ", "))
+		w.logger.Error("Warmup failed", zap.Strings("tasks", failed), zap.Duration("duration", time.Since(start)))
+	} else {
+		w.logger.Info("Warmup completed", zap.Int("tasks", len(tasks)), zap.Duration("duration", time.Since(start)))
+	}
+
+	w.mu.Lock()
+	w.done = true
+	w.results = results
+	w.err = err
+	w.mu.Unlock()
+	return err
+}
+
+// runTask 在超时时间内执行单个任务，panic 视为失败
+func (w *Warmup) runTask(ctx context.Context, task WarmupTask) (result WarmupResult) {
+	timeout := task.Timeout
+	if timeout <= 0 {
+		timeout = w.timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result.Name = task.Name
+	defer func() {
+		if r := recover(); r != nil {
+			result.Err = fmt.Errorf("panic: %v", r)
+		}
+		result.Duration = time.Since(start)
+
+		if result.Err != nil {
+			w.logger.Warn("Warmup task failed",
+				zap.String("task", task.Name),
+				zap.Bool("required", task.Required),
+				zap.Duration("duration", result.Duration),
+				zap.Error(result.Err),
+			)
+			return
+		}
+		w.logger.Info("Warmup task completed",
+			zap.String("task", task.Name),
+			zap.Duration("duration", result.Duration),
+		)
+	}()
+
+	result.Err = task.Run(ctx)
+	return result
+}
+
+// Check 就绪检查，预热未完成或必须成功的任务失败时返回错误
+func (w *Warmup) Check(_ context.Context) error {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		return fmt.Errorf("warmup in progress")
+	}
+	return w.err
+}
+
+// Results 返回各任务的执行结果，预热完成前为空
+func (w *Warmup) Results() []WarmupResult {
+	if w == nil {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]WarmupResult(nil), w.results...)
+}
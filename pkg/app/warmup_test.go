@@ -0,0 +1,76 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestWarmupReadiness(t *testing.T) {
+	w := NewWarmup(zap.NewNop(), time.Second)
+	release := make(chan struct{})
+	w.Register(WarmupTask{Name: "slow", Run: func(ctx context.Context) error {
+		<-release
+		return nil
+	}})
+	w.Register(WarmupTask{Name: "optional", Run: func(ctx context.Context) error {
+		return errors.New("unavailable")
+	}})
+
+	if err := w.Check(context.Background()); err == nil {
+		t.Fatal("check should fail before warmup runs")
+	}
+
+	done := make(chan error)
+	go func() { done <- w.Run(context.Background()) }()
+	if err := w.Check(context.Background()); err == nil {
+		t.Error("check should fail while warmup is running")
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("optional task failure should not fail warmup: %v", err)
+	}
+	if err := w.Check(context.Background()); err != nil {
+		t.Errorf("check after warmup = %v", err)
+	}
+
+	results := w.Results()
+	if len(results) != 2 || results[0].Err != nil || results[1].Err == nil {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
+
+func TestWarmupRequiredTask(t *testing.T) {
+	w := NewWarmup(zap.NewNop(), 10*time.Millisecond)
+	w.Register(WarmupTask{Name: "timeout", Required: true, Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+	w.Register(WarmupTask{Name: "panic", Required: true, Run: func(ctx context.Context) error {
+		panic("boom")
+	}})
+
+	if err := w.Run(context.Background()); err == nil || err.Error() != "warmup tasks failed: timeout, panic" {
+		t.Fatalf("Run() = %v", err)
+	}
+	if err := w.Check(context.Background()); err == nil {
+		t.Error("check should fail when required task failed")
+	}
+	if err := w.Run(context.Background()); err == nil {
+		t.Error("second Run should fail")
+	}
+}
+
+func TestWarmupNil(t *testing.T) {
+	var w *Warmup
+	if err := w.Run(context.Background()); err != nil {
+		t.Errorf("nil Run() = %v", err)
+	}
+	if err := w.Check(context.Background()); err != nil {
+		t.Errorf("nil Check() = %v", err)
+	}
+}
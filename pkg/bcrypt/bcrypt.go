@@ -1,15 +1,32 @@
+// Package bcrypt 密码哈希的兼容封装
+//
+// Deprecated: 使用 github.com/hedeqiang/skeleton/pkg/password，支持按配置选择算法和升级旧哈希。
 package bcrypt
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/password"
+)
 
 // HashPassword 使用 bcrypt 对密码进行哈希
-func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+//
+// Deprecated: 使用 password.Hasher.Hash。
+func HashPassword(pw string) (string, error) {
+	hasher, err := password.New(&config.Config{})
+	if err != nil {
+		return "", err
+	}
+	return hasher.Hash(pw)
 }
 
 // CheckPasswordHash 比较哈希后的密码和原始密码是否匹配
-func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+//
+// Deprecated: 使用 password.Hasher.Verify。
+func CheckPasswordHash(pw, hash string) bool {
+	hasher, err := password.New(&config.Config{})
+	if err != nil {
+		return false
+	}
+	match, _, err := hasher.Verify(pw, hash)
+	return err == nil && match
 }
@@ -0,0 +1,105 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Baseline 保存的基线结果，用于对比中间件、序列化等改动前后的性能
+type Baseline struct {
+	CreatedAt time.Time `json:"created_at"`
+	Version   string    `json:"version,omitempty"`
+	RPS       int       `json:"rps"`
+	Duration  string    `json:"duration"`
+	Results   []Result  `json:"results"`
+}
+
+// LoadBaseline 读取基线文件
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read baseline: %w", err)
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to decode baseline: %w", err)
+	}
+	return &baseline, nil
+}
+
+// SaveBaseline 写入基线文件，目录不存在时自动创建
+func SaveBaseline(path string, baseline *Baseline) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create baseline directory: %w", err)
+	}
+	data, err := json.MarshalIndent(baseline, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode baseline: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Regression 相对基线的性能退化
+type Regression struct {
+	Target   string
+	Metric   string
+	Baseline float64
+	Current  float64
+}
+
+// String 返回可读描述
+func (r Regression) String() string {
+	if r.Metric == "error_rate" {
+		return fmt.Sprintf("%s %s: %.2f%% -> %.2f%%", r.Target, r.Metric, r.Baseline*100, r.Current*100)
+	}
+	return fmt.Sprintf("%s %s: %s -> %s", r.Target, r.Metric, time.Duration(r.Baseline), time.Duration(r.Current))
+}
+
+// Compare 对比当前结果和基线
+// 延迟分位数超过基线 (1+tolerance) 倍，或错误率比基线高出 tolerance 以上时视为退化；基线中不存在的目标不参与对比
+func Compare(baseline *Baseline, results []Result, tolerance float64) []Regression {
+	previous := make(map[string]Result, len(baseline.Results))
+	for _, r := range baseline.Results {
+		previous[r.Target] = r
+	}
+
+	var regressions []Regression
+	for _, current := range results {
+		base, ok := previous[current.Target]
+		if !ok {
+			continue
+		}
+
+		latencies := []struct {
+			metric        string
+			base, current time.Duration
+		}{
+			{"p50", base.P50, current.P50},
+			{"p95", base.P95, current.P95},
+			{"p99", base.P99, current.P99},
+		}
+		for _, l := range latencies {
+			if l.base > 0 && float64(l.current) > float64(l.base)*(1+tolerance) {
+				regressions = append(regressions, Regression{
+					Target:   current.Target,
+					Metric:   l.metric,
+					Baseline: float64(l.base),
+					Current:  float64(l.current),
+				})
+			}
+		}
+
+		if current.ErrorRate > base.ErrorRate+tolerance {
+			regressions = append(regressions, Regression{
+				Target:   current.Target,
+				Metric:   "error_rate",
+				Baseline: base.ErrorRate,
+				Current:  current.ErrorRate,
+			})
+		}
+	}
+	return regressions
+}
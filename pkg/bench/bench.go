@@ -0,0 +1,215 @@
+// Package bench 对运行中的服务按固定速率发起 HTTP 请求，统计延迟分位数和错误率
+package bench
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Target 压测目标接口
+type Target struct {
+	Method string            `json:"method"`
+	Path   string            `json:"path"`
+	Body   string            `json:"body,omitempty"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+// Name 返回目标名称，如 GET /api/v1/users
+func (t Target) Name() string {
+	return t.Method + " " + t.Path
+}
+
+// ParseTarget 解析 "METHOD /path" 格式的目标，省略方法时使用 GET
+func ParseTarget(s string) (Target, error) {
+	s = strings.TrimSpace(s)
+	method, path, ok := strings.Cut(s, " ")
+	if !ok {
+		method, path = http.MethodGet, s
+	}
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "/") {
+		return Target{}, fmt.Errorf("invalid target %q: path must start with /", s)
+	}
+	return Target{Method: strings.ToUpper(method), Path: path}, nil
+}
+
+// Options 压测参数
+type Options struct {
+	BaseURL     string
+	Targets     []Target
+	RPS         int           // 每个目标每秒请求数
+	Duration    time.Duration // 压测时长
+	Concurrency int           // 每个目标最大并发请求数
+	Timeout     time.Duration // 单个请求超时
+}
+
+// Result 单个目标的压测结果
+type Result struct {
+	Target    string        `json:"target"`
+	Requests  int           `json:"requests"`
+	Errors    int           `json:"errors"`  // 网络错误和 5xx 响应
+	Dropped   int           `json:"dropped"` // 并发已满未能按时发出的请求
+	ErrorRate float64       `json:"error_rate"`
+	P50       time.Duration `json:"p50"`
+	P95       time.Duration `json:"p95"`
+	P99       time.Duration `json:"p99"`
+	Max       time.Duration `json:"max"`
+}
+
+// Run 并行压测所有目标，每个目标按固定速率开环发送请求，不因服务变慢而降低发送速率
+func Run(ctx context.Context, opts Options) ([]Result, error) {
+	if opts.RPS <= 0 || opts.Duration <= 0 {
+		return nil, fmt.Errorf("rps and duration must be positive")
+	}
+	if len(opts.Targets) == 0 {
+		return nil, fmt.Errorf("no targets")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = opts.RPS
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	client := &http.Client{
+		Timeout: opts.Timeout,
+		Transport: &http.Transport{
+			MaxIdleConnsPerHost: opts.Concurrency,
+		},
+	}
+
+	results := make([]Result, len(opts.Targets))
+	var wg sync.WaitGroup
+	for i, target := range opts.Targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = runTarget(ctx, client, opts, target)
+		}()
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// runTarget 压测单个目标
+func runTarget(ctx context.Context, client *http.Client, opts Options, target Target) Result {
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		errs      int
+		dropped   int
+		wg        sync.WaitGroup
+	)
+	slots := make(chan struct{}, opts.Concurrency)
+	ticker := time.NewTicker(time.Second / time.Duration(opts.RPS))
+	defer ticker.Stop()
+
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			dropped++
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			latency, err := send(client, opts.BaseURL, target)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs++
+				return
+			}
+			latencies = append(latencies, latency)
+		}()
+	}
+	wg.Wait()
+
+	return summarize(target.Name(), latencies, errs, dropped)
+}
+
+// send 发送单个请求并返回耗时，网络错误和 5xx 响应视为失败
+func send(client *http.Client, baseURL string, target Target) (time.Duration, error) {
+	var body io.Reader
+	if target.Body != "" {
+		body = bytes.NewBufferString(target.Body)
+	}
+	req, err := http.NewRequest(target.Method, strings.TrimSuffix(baseURL, "/")+target.Path, body)
+	if err != nil {
+		return 0, err
+	}
+	if target.Body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range target.Header {
+		req.Header.Set(key, value)
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	latency := time.Since(start)
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return latency, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return latency, nil
+}
+
+// summarize 计算延迟分位数和错误率
+func summarize(target string, latencies []time.Duration, errs, dropped int) Result {
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	result := Result{
+		Target:   target,
+		Requests: len(latencies) + errs,
+		Errors:   errs,
+		Dropped:  dropped,
+		P50:      percentile(latencies, 0.50),
+		P95:      percentile(latencies, 0.95),
+		P99:      percentile(latencies, 0.99),
+	}
+	if len(latencies) > 0 {
+		result.Max = latencies[len(latencies)-1]
+	}
+	if result.Requests > 0 {
+		result.ErrorRate = float64(errs) / float64(result.Requests)
+	}
+	return result
+}
+
+// percentile 返回已排序延迟的 p 分位数 (nearest-rank)
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	rank = max(0, min(rank, len(sorted)-1))
+	return sorted[rank]
+}
@@ -0,0 +1,73 @@
+package bench
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	var latencies []time.Duration
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	if p := percentile(latencies, 0.50); p != 50*time.Millisecond {
+		t.Fatalf("p50 = %s", p)
+	}
+	if p := percentile(latencies, 0.99); p != 99*time.Millisecond {
+		t.Fatalf("p99 = %s", p)
+	}
+	if p := percentile(nil, 0.99); p != 0 {
+		t.Fatalf("empty p99 = %s", p)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := &Baseline{Results: []Result{
+		{Target: "GET /ping", P50: 10 * time.Millisecond, P95: 20 * time.Millisecond, P99: 30 * time.Millisecond},
+	}}
+
+	ok := []Result{{Target: "GET /ping", P50: 11 * time.Millisecond, P95: 22 * time.Millisecond, P99: 33 * time.Millisecond}}
+	if regressions := Compare(baseline, ok, 0.2); len(regressions) != 0 {
+		t.Fatalf("unexpected regressions: %v", regressions)
+	}
+
+	slow := []Result{
+		{Target: "GET /ping", P50: 10 * time.Millisecond, P95: 20 * time.Millisecond, P99: 60 * time.Millisecond, ErrorRate: 0.5},
+		{Target: "GET /new", P99: time.Second},
+	}
+	regressions := Compare(baseline, slow, 0.2)
+	if len(regressions) != 2 || regressions[0].Metric != "p99" || regressions[1].Metric != "error_rate" {
+		t.Fatalf("unexpected regressions: %v", regressions)
+	}
+}
+
+func TestRun(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	results, err := Run(context.Background(), Options{
+		BaseURL:  server.URL,
+		Targets:  []Target{{Method: http.MethodGet, Path: "/ping"}, {Method: http.MethodGet, Path: "/fail"}},
+		RPS:      100,
+		Duration: 200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if results[0].Requests == 0 || results[0].Errors != 0 {
+		t.Fatalf("unexpected ping result: %+v", results[0])
+	}
+	if results[1].Requests == 0 || results[1].ErrorRate != 1 {
+		t.Fatalf("unexpected fail result: %+v", results[1])
+	}
+}
@@ -0,0 +1,161 @@
+package bloom
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// rebuildBatchSize 重建时每批写入的元素数量
+const rebuildBatchSize = 1000
+
+// Filter 基于 Redis 位图的布隆过滤器
+// MightContain 返回 false 表示元素一定不存在；返回 true 表示可能存在，需要回源确认
+type Filter struct {
+	client *redis.Client
+	key    string
+	m      uint64 // 位图大小
+	k      uint64 // 哈希函数个数
+}
+
+// New 根据预期元素数量和误判率创建布隆过滤器
+func New(client *redis.Client, key string, expectedItems uint64, falsePositiveRate float64) *Filter {
+	m, k := optimalParams(expectedItems, falsePositiveRate)
+	return &Filter{
+		client: client,
+		key:    key,
+		m:      m,
+		k:      k,
+	}
+}
+
+// Add 添加元素
+// 过滤器尚未构建时不写入，避免生成只包含部分数据的位图；重建进行中时同时写入新位图
+func (f *Filter) Add(ctx context.Context, items ...string) error {
+	pipe := f.client.Pipeline()
+	built := pipe.Exists(ctx, f.key)
+	rebuilding := pipe.Exists(ctx, f.rebuildKey())
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	if built.Val() > 0 {
+		if err := f.add(ctx, f.key, items); err != nil {
+			return err
+		}
+	}
+	if rebuilding.Val() > 0 {
+		return f.add(ctx, f.rebuildKey(), items)
+	}
+	return nil
+}
+
+// MightContain 判断元素是否可能存在
+// 过滤器尚未构建（key 不存在）时无法给出否定结论，统一返回 true
+func (f *Filter) MightContain(ctx context.Context, item string) (bool, error) {
+	pipe := f.client.Pipeline()
+	exists := pipe.Exists(ctx, f.key)
+	bits := make([]*redis.IntCmd, 0, f.k)
+	for _, pos := range f.positions(item) {
+		bits = append(bits, pipe.GetBit(ctx, f.key, int64(pos)))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return true, err
+	}
+
+	if exists.Val() == 0 {
+		return true, nil
+	}
+	for _, bit := range bits {
+		if bit.Val() == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Rebuild 使用全量数据重建过滤器，清除已删除元素带来的误判
+// next 每次返回一批元素，返回空切片表示结束；新位图构建完成后原子替换旧位图
+func (f *Filter) Rebuild(ctx context.Context, next func() ([]string, error)) error {
+	tmpKey := f.rebuildKey()
+	if err := f.client.Del(ctx, tmpKey).Err(); err != nil {
+		return err
+	}
+
+	// 先占位，保证空数据集时也能生成位图
+	if err := f.client.SetBit(ctx, tmpKey, int64(f.m-1), 0).Err(); err != nil {
+		return err
+	}
+
+	for {
+		items, err := next()
+		if err != nil {
+			f.client.Del(ctx, tmpKey)
+			return err
+		}
+		if len(items) == 0 {
+			break
+		}
+		for start := 0; start < len(items); start += rebuildBatchSize {
+			end := min(start+rebuildBatchSize, len(items))
+			if err := f.add(ctx, tmpKey, items[start:end]); err != nil {
+				f.client.Del(ctx, tmpKey)
+				return err
+			}
+		}
+	}
+
+	return f.client.Rename(ctx, tmpKey, f.key).Err()
+}
+
+func (f *Filter) rebuildKey() string {
+	return f.key + ":rebuild"
+}
+
+func (f *Filter) add(ctx context.Context, key string, items []string) error {
+	if len(items) == 0 {
+		return nil
+	}
+	pipe := f.client.Pipeline()
+	for _, item := range items {
+		for _, pos := range f.positions(item) {
+			pipe.SetBit(ctx, key, int64(pos), 1)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// positions 使用双重哈希计算元素对应的 k 个位
+func (f *Filter) positions(item string) []uint64 {
+	h := fnv.New128a()
+	h.Write([]byte(item))
+	sum := h.Sum(nil)
+	h1 := binary.BigEndian.Uint64(sum[:8])
+	h2 := binary.BigEndian.Uint64(sum[8:])
+
+	positions := make([]uint64, f.k)
+	for i := uint64(0); i < f.k; i++ {
+		positions[i] = (h1 + i*h2) % f.m
+	}
+	return positions
+}
+
+// optimalParams 计算最优位图大小和哈希函数个数
+func optimalParams(n uint64, p float64) (m, k uint64) {
+	if n == 0 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+	m = uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k = uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return m, k
+}
@@ -0,0 +1,82 @@
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// 构建信息，通过 ldflags 在构建时注入：
+//
+//	go build -ldflags "-X github.com/hedeqiang/skeleton/pkg/buildinfo.Version=v1.2.0 \
+//	  -X github.com/hedeqiang/skeleton/pkg/buildinfo.GitCommit=$(git rev-parse HEAD) \
+//	  -X github.com/hedeqiang/skeleton/pkg/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+var (
+	Version   = "dev"
+	GitCommit = ""
+	BuildDate = ""
+)
+
+// Info 构建和运行时信息
+type Info struct {
+	Version   string `json:"version"`
+	GitCommit string `json:"git_commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get 返回构建信息，未注入提交和构建时间时尝试从 Go 嵌入的 VCS 信息中读取
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if info.GitCommit == "" {
+					info.GitCommit = setting.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = setting.Value
+				}
+			}
+		}
+	}
+
+	if info.GitCommit == "" {
+		info.GitCommit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+	return info
+}
+
+// ShortCommit 返回 12 位短提交号
+func (i Info) ShortCommit() string {
+	if len(i.GitCommit) > 12 {
+		return i.GitCommit[:12]
+	}
+	return i.GitCommit
+}
+
+// Banner 返回服务启动时打印的横幅
+func Banner(service string) string {
+	info := Get()
+	var b strings.Builder
+	b.WriteString("==================================================\n")
+	fmt.Fprintf(&b, " skeleton %s %s\n", service, info.Version)
+	fmt.Fprintf(&b, " commit: %s  built: %s\n", info.ShortCommit(), info.BuildDate)
+	fmt.Fprintf(&b, " go: %s  platform: %s\n", info.GoVersion, info.Platform)
+	b.WriteString("==================================================\n")
+	return b.String()
+}
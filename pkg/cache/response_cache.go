@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrCacheMiss 缓存未命中
+var ErrCacheMiss = errors.New("cache miss")
+
+// CachedResponse 缓存的完整响应
+type CachedResponse struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"content_type"`
+	Body        []byte `json:"body"`
+}
+
+// ResponseCache 基于 Redis 的响应缓存
+type ResponseCache struct {
+	client *redis.Client
+	cfg    *config.Cache
+}
+
+// NewResponseCache 创建响应缓存
+func NewResponseCache(client *redis.Client, cfg *config.Cache) *ResponseCache {
+	return &ResponseCache{
+		client: client,
+		cfg:    cfg,
+	}
+}
+
+// Enabled 是否启用了响应缓存
+func (c *ResponseCache) Enabled() bool {
+	return c != nil && c.cfg.Enabled && c.client != nil
+}
+
+// Rule 查找 GET 路由对应的缓存规则
+func (c *ResponseCache) Rule(path string) (config.CacheRule, bool) {
+	for _, rule := range c.cfg.Rules {
+		if rule.Path == path {
+			if rule.TTL <= 0 {
+				rule.TTL = c.cfg.DefaultTTL
+			}
+			return rule, rule.TTL > 0
+		}
+	}
+	return config.CacheRule{}, false
+}
+
+// InvalidationTags 查找写操作路由需要失效的缓存标签
+func (c *ResponseCache) InvalidationTags(method, path string) []string {
+	var tags []string
+	for _, inv := range c.cfg.Invalidations {
+		if strings.EqualFold(inv.Method, method) && inv.Path == path {
+			tags = append(tags, inv.Tags...)
+		}
+	}
+	return tags
+}
+
+// EventInvalidationTags 查找领域事件需要失效的缓存标签
+func (c *ResponseCache) EventInvalidationTags(eventType string) []string {
+	var tags []string
+	for _, inv := range c.cfg.EventInvalidations {
+		if inv.Event == eventType {
+			tags = append(tags, inv.Tags...)
+		}
+	}
+	return tags
+}
+
+// InvalidateEvent 按 event_invalidations 失效领域事件对应的标签，未启用缓存或没有对应规则时不做任何事
+func (c *ResponseCache) InvalidateEvent(ctx context.Context, eventType string) error {
+	if !c.Enabled() {
+		return nil
+	}
+	tags := c.EventInvalidationTags(eventType)
+	if len(tags) == 0 {
+		return nil
+	}
+	return c.Invalidate(ctx, tags...)
+}
+
+// Key 根据路由、查询参数、用户和语言生成缓存键
+func (c *ResponseCache) Key(path, query, user, lang string) string {
+	h := sha1.New()
+	for _, part := range []string{path, query, user, lang} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return c.prefix() + "resp:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// Get 读取缓存的响应
+func (c *ResponseCache) Get(ctx context.Context, key string) (*CachedResponse, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrCacheMiss
+		}
+		return nil, err
+	}
+
+	var resp CachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Set 写入响应并登记到各个标签下，便于按标签失效
+func (c *ResponseCache) Set(ctx context.Context, key string, resp *CachedResponse, ttl time.Duration, tags []string) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.client.TxPipeline()
+	pipe.Set(ctx, key, data, ttl)
+	for _, tag := range tags {
+		tagKey := c.tagKey(tag)
+		pipe.SAdd(ctx, tagKey, key)
+		// 标签集合至少要活得和其中的缓存一样久
+		pipe.ExpireGT(ctx, tagKey, ttl)
+		pipe.ExpireNX(ctx, tagKey, ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// Invalidate 按标签删除缓存，供写操作或领域事件处理器调用
+func (c *ResponseCache) Invalidate(ctx context.Context, tags ...string) error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	for _, tag := range tags {
+		tagKey := c.tagKey(tag)
+		keys, err := c.client.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return err
+		}
+		keys = append(keys, tagKey)
+		if err := c.client.Del(ctx, keys...).Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ResponseCache) tagKey(tag string) string {
+	return c.prefix() + "tag:" + tag
+}
+
+func (c *ResponseCache) prefix() string {
+	if c.cfg.Prefix == "" {
+		return "cache:"
+	}
+	return c.cfg.Prefix + ":"
+}
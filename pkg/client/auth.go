@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// TokenSource 提供访问令牌，令牌需要刷新时由实现方负责
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// TokenSourceFunc 函数形式的 TokenSource
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token 实现 TokenSource 接口
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// StaticToken 固定的访问令牌
+type StaticToken string
+
+// Token 实现 TokenSource 接口
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// AuthService 认证接口
+type AuthService struct {
+	client *Client
+}
+
+// LoginRequest 登录请求
+type LoginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// RefreshRequest 刷新令牌请求
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Tokens 登录或刷新后签发的令牌
+type Tokens struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresIn        int64     `json:"expires_in"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+// LoginResponse 登录和刷新令牌的响应
+type LoginResponse struct {
+	User *User `json:"user"`
+	Tokens
+}
+
+// Login 用户登录，POST /api/v1/auth/login
+func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+	var resp LoginResponse
+	if err := s.client.do(ctx, http.MethodPost, "/api/v1/auth/login", nil, req, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Refresh 凭刷新令牌换取新令牌，POST /api/v1/auth/refresh，旧的刷新令牌随之失效
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*LoginResponse, error) {
+	var resp LoginResponse
+	if err := s.client.do(ctx, http.MethodPost, "/api/v1/auth/refresh", nil, &RefreshRequest{RefreshToken: refreshToken}, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
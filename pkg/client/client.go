@@ -0,0 +1,273 @@
+// Package client skeleton REST API 的 Go 客户端
+// 请求和响应模型与 internal/model 及 pkg/response 的 JSON 结构保持一致，新增或修改接口时需同步更新
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 客户端默认值
+const (
+	defaultTimeout   = 30 * time.Second
+	defaultUserAgent = "skeleton-go-client"
+)
+
+// Client API 客户端，可被多个 goroutine 并发使用
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	tokens     TokenSource
+	retry      RetryPolicy
+	userAgent  string
+
+	Users    *UsersService
+	Auth     *AuthService
+	Tasks    *TasksService
+	Messages *MessagesService
+}
+
+// Option 客户端可选参数
+type Option func(*Client)
+
+// WithHTTPClient 使用自定义 http.Client，用于配置代理、TLS 或链路追踪 Transport
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTokenSource 为每个请求附加 Authorization: Bearer 请求头
+func WithTokenSource(tokens TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = tokens
+	}
+}
+
+// WithBearerToken 使用固定的访问令牌
+func WithBearerToken(token string) Option {
+	return WithTokenSource(StaticToken(token))
+}
+
+// WithRetryPolicy 设置重试策略
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithUserAgent 设置 User-Agent 请求头
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// New 创建客户端，baseURL 为服务地址，如 http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url: %q", baseURL)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retry:      DefaultRetryPolicy,
+		userAgent:  defaultUserAgent,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	c.Users = &UsersService{client: c}
+	c.Auth = &AuthService{client: c}
+	c.Tasks = &TasksService{client: c}
+	c.Messages = &MessagesService{client: c}
+	return c, nil
+}
+
+// Response 标准响应结构，对应 pkg/response.Response
+type Response[T any] struct {
+	Code      int    `json:"code"`
+	Msg       string `json:"msg"`
+	Data      T      `json:"data,omitempty"`
+	RequestID string `json:"request_id"`
+}
+
+// APIError 服务端返回的错误响应
+type APIError struct {
+	StatusCode int
+	Code       int
+	Msg        string
+	RequestID  string
+}
+
+// Error 实现 error 接口
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("skeleton api: %d %s (request_id=%s)", e.StatusCode, e.Msg, e.RequestID)
+	}
+	return fmt.Sprintf("skeleton api: %d %s", e.StatusCode, e.Msg)
+}
+
+// IsNotFound 是否为 404 错误
+func IsNotFound(err error) bool {
+	return statusCodeOf(err) == http.StatusNotFound
+}
+
+// IsConflict 是否为 409 错误
+func IsConflict(err error) bool {
+	return statusCodeOf(err) == http.StatusConflict
+}
+
+// IsUnauthorized 是否为 401 错误
+func IsUnauthorized(err error) bool {
+	return statusCodeOf(err) == http.StatusUnauthorized
+}
+
+// statusCodeOf 返回 APIError 的 HTTP 状态码，其他错误返回 0
+func statusCodeOf(err error) int {
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		return 0
+	}
+	return apiErr.StatusCode
+}
+
+// do 发送请求并将响应的 data 字段解析到 out，out 为 nil 时忽略响应数据
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		payload = data
+	}
+
+	u := c.baseURL.JoinPath(path)
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, method, u.String(), payload)
+		if err == nil && !c.retry.retryableStatus(resp.StatusCode) {
+			return decodeResponse(resp, out)
+		}
+
+		var retryAfter time.Duration
+		if err != nil {
+			lastErr = err
+		} else {
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			lastErr = decodeResponse(resp, nil)
+		}
+
+		if attempt >= c.retry.MaxRetries || !c.retry.retryableMethod(method) || ctx.Err() != nil {
+			return lastErr
+		}
+
+		timer := time.NewTimer(c.retry.backoff(attempt, retryAfter))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return lastErr
+		case <-timer.C:
+		}
+	}
+}
+
+// send 构造并发送单次请求
+func (c *Client) send(ctx context.Context, method, rawURL string, payload []byte) (*http.Response, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.tokens != nil {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get access token: %w", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, req.URL.Path, err)
+	}
+	return resp, nil
+}
+
+// decodeResponse 解析标准响应结构，非 2xx 响应或业务码非 0 时返回 APIError
+func decodeResponse(resp *http.Response, out interface{}) error {
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	envelope := Response[json.RawMessage]{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &envelope); err != nil && resp.StatusCode < 300 {
+			return fmt.Errorf("failed to decode response: %w", err)
+		}
+	}
+
+	if resp.StatusCode >= 300 || envelope.Code != 0 {
+		apiErr := &APIError{
+			StatusCode: resp.StatusCode,
+			Code:       envelope.Code,
+			Msg:        envelope.Msg,
+			RequestID:  envelope.RequestID,
+		}
+		if apiErr.Msg == "" {
+			apiErr.Msg = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+
+	if out == nil || len(envelope.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode response data: %w", err)
+	}
+	return nil
+}
+
+// parseRetryAfter 解析以秒为单位的 Retry-After 响应头
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,113 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func writeEnvelope(w http.ResponseWriter, status int, msg string, data interface{}) {
+	code := 0
+	if status >= 300 {
+		code = 1
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(Response[interface{}]{Code: code, Msg: msg, Data: data, RequestID: "req-1"})
+}
+
+func TestClientDecodesEnvelopeAndErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			writeEnvelope(w, http.StatusUnauthorized, "未授权", nil)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/v1/users/1":
+			writeEnvelope(w, http.StatusOK, "获取成功", User{ID: 1, Username: "alice"})
+		default:
+			writeEnvelope(w, http.StatusNotFound, "用户不存在", nil)
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, WithBearerToken("secret"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	user, err := c.Users.Get(context.Background(), 1)
+	if err != nil || user.Username != "alice" {
+		t.Fatalf("unexpected user: %+v, %v", user, err)
+	}
+
+	_, err = c.Users.Get(context.Background(), 2)
+	apiErr, ok := err.(*APIError)
+	if !ok || !IsNotFound(err) || apiErr.Msg != "用户不存在" || apiErr.RequestID != "req-1" {
+		t.Fatalf("expected not found APIError, got %v", err)
+	}
+}
+
+func TestClientRetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			writeEnvelope(w, http.StatusServiceUnavailable, "服务不可用", nil)
+			return
+		}
+		writeEnvelope(w, http.StatusOK, "获取成功", User{ID: 1})
+	}))
+	defer server.Close()
+
+	policy := RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	c, _ := New(server.URL, WithRetryPolicy(policy))
+
+	if _, err := c.Users.Get(context.Background(), 1); err != nil {
+		t.Fatalf("expected success after retries, got %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls.Load())
+	}
+
+	// POST 不是幂等请求，不重试
+	calls.Store(0)
+	if _, err := c.Users.Create(context.Background(), &CreateUserRequest{Username: "bob"}); err == nil {
+		t.Fatal("expected error for non-idempotent request")
+	}
+	if calls.Load() != 1 {
+		t.Fatalf("expected 1 call, got %d", calls.Load())
+	}
+}
+
+func TestUsersAllIteratesPages(t *testing.T) {
+	const total = 5
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		pageSize, _ := strconv.Atoi(r.URL.Query().Get("page_size"))
+
+		var list []User
+		for id := (page-1)*pageSize + 1; id <= min(page*pageSize, total); id++ {
+			list = append(list, User{ID: uint(id)})
+		}
+		writeEnvelope(w, http.StatusOK, "获取成功", Page[User]{List: list, Total: total, Page: page, PageSize: pageSize})
+	}))
+	defer server.Close()
+
+	c, _ := New(server.URL)
+
+	var ids []uint
+	for user, err := range c.Users.All(context.Background(), 2) {
+		if err != nil {
+			t.Fatalf("iteration failed: %v", err)
+		}
+		ids = append(ids, user.ID)
+	}
+	if len(ids) != total || ids[0] != 1 || ids[total-1] != total {
+		t.Fatalf("unexpected ids: %v", ids)
+	}
+}
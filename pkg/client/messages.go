@@ -0,0 +1,28 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// PublishHelloRequest 发布 Hello 消息请求
+type PublishHelloRequest struct {
+	Content string `json:"content"`
+	Sender  string `json:"sender"`
+}
+
+// MessagesService 消息接口
+type MessagesService struct {
+	client *Client
+}
+
+// PublishHello 发布 Hello 消息，POST /api/v1/messages/hello/publish，返回消息 ID
+func (s *MessagesService) PublishHello(ctx context.Context, req *PublishHelloRequest) (string, error) {
+	var result struct {
+		MessageID string `json:"message_id"`
+	}
+	if err := s.client.do(ctx, http.MethodPost, "/api/v1/messages/hello/publish", nil, req, &result); err != nil {
+		return "", err
+	}
+	return result.MessageID, nil
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy 重试策略
+// 只重试幂等方法 (GET/PUT/DELETE/HEAD/OPTIONS)，遇到网络错误或 429/502/503/504 时按指数退避重试
+// 服务端返回 Retry-After 时优先使用其等待时间
+type RetryPolicy struct {
+	MaxRetries int           // 最大重试次数，0 表示不重试
+	MinBackoff time.Duration // 首次重试等待时间
+	MaxBackoff time.Duration // 单次等待时间上限
+}
+
+// DefaultRetryPolicy 默认重试策略
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 200 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+}
+
+// NoRetry 不重试
+var NoRetry = RetryPolicy{}
+
+// retryableMethod 判断请求方法是否可以安全重试
+func (p RetryPolicy) retryableMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryableStatus 判断响应状态码是否需要重试
+func (p RetryPolicy) retryableStatus(status int) bool {
+	if p.MaxRetries <= 0 {
+		return false
+	}
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// backoff 返回第 attempt 次重试前的等待时间，带随机抖动
+func (p RetryPolicy) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultRetryPolicy.MaxBackoff
+	}
+	if retryAfter > 0 {
+		return min(retryAfter, maxBackoff)
+	}
+
+	wait := p.MinBackoff
+	if wait <= 0 {
+		wait = DefaultRetryPolicy.MinBackoff
+	}
+	for i := 0; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	wait = min(wait, maxBackoff)
+	// 在 [wait/2, wait] 之间随机，避免多个客户端同时重试
+	return wait/2 + rand.N(wait/2+1)
+}
@@ -0,0 +1,73 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// 后台任务状态
+const (
+	TaskStatusPending   = "pending"
+	TaskStatusRunning   = "running"
+	TaskStatusSucceeded = "succeeded"
+	TaskStatusFailed    = "failed"
+)
+
+// Task 后台任务，对应 model.TaskResponse
+type Task struct {
+	ID         string     `json:"id"`
+	Type       string     `json:"type"`
+	UserID     uint       `json:"user_id,omitempty"`
+	Status     string     `json:"status"`
+	Progress   int        `json:"progress"`
+	ResultURL  string     `json:"result_url,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// IsFinished 任务是否已结束
+func (t *Task) IsFinished() bool {
+	return t.Status == TaskStatusSucceeded || t.Status == TaskStatusFailed
+}
+
+// TasksService 后台任务接口
+type TasksService struct {
+	client *Client
+}
+
+// Get 查询任务进度，GET /api/v1/tasks/:id
+func (s *TasksService) Get(ctx context.Context, id string) (*Task, error) {
+	var task Task
+	if err := s.client.do(ctx, http.MethodGet, "/api/v1/tasks/"+url.PathEscape(id), nil, nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// Wait 按 interval 轮询任务直到结束或 ctx 取消
+func (s *TasksService) Wait(ctx context.Context, id string, interval time.Duration) (*Task, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		task, err := s.Get(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if task.IsFinished() {
+			return task, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return task, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
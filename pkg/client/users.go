@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// 用户列表分页默认值，与服务端保持一致
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// User 用户，对应 model.UserResponse
+type User struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateUserRequest 创建用户请求
+type CreateUserRequest struct {
+	Username         string `json:"username"`
+	Email            string `json:"email"`
+	Password         string `json:"password"`
+	ReservationToken string `json:"reservation_token,omitempty"`
+}
+
+// ReserveUserRequest 预占用户名和邮箱请求
+type ReserveUserRequest struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// UserReservation 预占结果
+type UserReservation struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// UpdateUserRequest 更新用户请求，空字符串表示不修改
+type UpdateUserRequest struct {
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+	Status   *int   `json:"status,omitempty"`
+}
+
+// PatchUserRequest 部分更新用户请求，只发送非 nil 字段
+type PatchUserRequest struct {
+	Username *string `json:"username,omitempty"`
+	Email    *string `json:"email,omitempty"`
+	Status   *int    `json:"status,omitempty"`
+}
+
+// Identity 关联的外部账号，对应 model.UserIdentityResponse
+type Identity struct {
+	ID             uint      `json:"id"`
+	Provider       string    `json:"provider"`
+	ProviderUserID string    `json:"provider_user_id"`
+	Email          string    `json:"email,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Page 分页结果，对应 response.PageResponse
+type Page[T any] struct {
+	List     []T   `json:"list"`
+	Total    int64 `json:"total"`
+	Page     int   `json:"page"`
+	PageSize int   `json:"page_size"`
+}
+
+// HasNext 是否还有下一页
+func (p *Page[T]) HasNext() bool {
+	return int64(p.Page*p.PageSize) < p.Total && len(p.List) > 0
+}
+
+// UsersService 用户接口
+type UsersService struct {
+	client *Client
+}
+
+// Create 创建用户，POST /api/v1/users
+func (s *UsersService) Create(ctx context.Context, req *CreateUserRequest) (*User, error) {
+	var user User
+	if err := s.client.do(ctx, http.MethodPost, "/api/v1/users", nil, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Reserve 预占用户名和邮箱，POST /api/v1/users/reservations
+func (s *UsersService) Reserve(ctx context.Context, req *ReserveUserRequest) (*UserReservation, error) {
+	var reservation UserReservation
+	if err := s.client.do(ctx, http.MethodPost, "/api/v1/users/reservations", nil, req, &reservation); err != nil {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// Get 获取用户，GET /api/v1/users/:id
+func (s *UsersService) Get(ctx context.Context, id uint) (*User, error) {
+	var user User
+	if err := s.client.do(ctx, http.MethodGet, userPath(id), nil, nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Update 更新用户，PUT /api/v1/users/:id
+func (s *UsersService) Update(ctx context.Context, id uint, req *UpdateUserRequest) (*User, error) {
+	var user User
+	if err := s.client.do(ctx, http.MethodPut, userPath(id), nil, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Patch 部分更新用户，PATCH /api/v1/users/:id
+func (s *UsersService) Patch(ctx context.Context, id uint, req *PatchUserRequest) (*User, error) {
+	var user User
+	if err := s.client.do(ctx, http.MethodPatch, userPath(id), nil, req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Delete 删除用户，DELETE /api/v1/users/:id
+func (s *UsersService) Delete(ctx context.Context, id uint) error {
+	return s.client.do(ctx, http.MethodDelete, userPath(id), nil, nil, nil)
+}
+
+// List 获取一页用户，GET /api/v1/users
+func (s *UsersService) List(ctx context.Context, page, pageSize int) (*Page[User], error) {
+	query := url.Values{}
+	query.Set("page", strconv.Itoa(page))
+	query.Set("page_size", strconv.Itoa(pageSize))
+
+	var result Page[User]
+	if err := s.client.do(ctx, http.MethodGet, "/api/v1/users", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// All 逐页遍历全部用户，出错时产出错误并结束遍历
+//
+//	for user, err := range c.Users.All(ctx, 100) {
+//		if err != nil { ... }
+//	}
+func (s *UsersService) All(ctx context.Context, pageSize int) iter.Seq2[*User, error] {
+	if pageSize < 1 || pageSize > maxPageSize {
+		pageSize = defaultPageSize
+	}
+
+	return func(yield func(*User, error) bool) {
+		for page := 1; ; page++ {
+			result, err := s.List(ctx, page, pageSize)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for i := range result.List {
+				if !yield(&result.List[i], nil) {
+					return
+				}
+			}
+			if !result.HasNext() {
+				return
+			}
+		}
+	}
+}
+
+// Identities 获取用户关联的外部账号，GET /api/v1/users/:id/identities
+func (s *UsersService) Identities(ctx context.Context, id uint) ([]Identity, error) {
+	var identities []Identity
+	if err := s.client.do(ctx, http.MethodGet, userPath(id)+"/identities", nil, nil, &identities); err != nil {
+		return nil, err
+	}
+	return identities, nil
+}
+
+// UnlinkIdentity 解除外部账号关联，DELETE /api/v1/users/:id/identities/:identity_id
+func (s *UsersService) UnlinkIdentity(ctx context.Context, id, identityID uint) error {
+	path := userPath(id) + "/identities/" + strconv.FormatUint(uint64(identityID), 10)
+	return s.client.do(ctx, http.MethodDelete, path, nil, nil, nil)
+}
+
+// userPath 返回单个用户的路径
+func userPath(id uint) string {
+	return "/api/v1/users/" + strconv.FormatUint(uint64(id), 10)
+}
@@ -0,0 +1,75 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 时间来源，依赖当前时间的逻辑通过它取时间，测试中替换为 Fake
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+}
+
+// Real 使用系统时间的 Clock
+type Real struct{}
+
+// New 创建使用系统时间的 Clock，通过 Wire 注入
+func New() Clock {
+	return Real{}
+}
+
+// Now 返回当前系统时间
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+// Since 返回 t 到当前系统时间经过的时长
+func (Real) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+// FrozenTime Frozen 使用的固定时间
+var FrozenTime = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Fake 手动控制的 Clock，时间只在调用 Set 或 Advance 时变化，并发安全
+type Fake struct {
+	mu  sync.RWMutex
+	now time.Time
+}
+
+// NewFake 创建停在 now 的 Fake
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Frozen 创建停在 FrozenTime 的 Fake，测试中用于得到可预期的时间戳
+func Frozen() *Fake {
+	return NewFake(FrozenTime)
+}
+
+// Now 返回当前设置的时间
+func (f *Fake) Now() time.Time {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.now
+}
+
+// Since 返回 t 到当前设置时间经过的时长
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// Set 将时间设置为 now
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = now
+}
+
+// Advance 将时间向前推进 d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now = f.now.Add(d)
+}
@@ -0,0 +1,25 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	clk := Frozen()
+	if !clk.Now().Equal(FrozenTime) {
+		t.Fatalf("Now() = %v, want %v", clk.Now(), FrozenTime)
+	}
+
+	start := clk.Now()
+	clk.Advance(90 * time.Second)
+	if got := clk.Since(start); got != 90*time.Second {
+		t.Fatalf("Since() = %v, want 90s", got)
+	}
+
+	later := FrozenTime.Add(24 * time.Hour)
+	clk.Set(later)
+	if !clk.Now().Equal(later) {
+		t.Fatalf("Now() = %v, want %v", clk.Now(), later)
+	}
+}
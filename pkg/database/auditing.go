@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// 审计字段名，模型嵌入 model.Auditable 或自行声明同名字段即可
+const (
+	createdByField = "CreatedBy"
+	updatedByField = "UpdatedBy"
+)
+
+// actorKey 当前操作人在 context 中的键
+type actorKey struct{}
+
+// WithActor 在 context 中记录当前操作人的用户 ID，由认证中间件写入
+func WithActor(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFromContext 获取当前操作人的用户 ID，不存在时返回 false
+func ActorFromContext(ctx context.Context) (uint, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	userID, ok := ctx.Value(actorKey{}).(uint)
+	return userID, ok
+}
+
+// AuditingPlugin 创建和更新记录时，根据 context 中的操作人填充 CreatedBy、UpdatedBy 字段的 GORM 插件
+// context 中没有操作人（例如计划任务、消费者）或模型没有审计字段时不做任何事情
+type AuditingPlugin struct{}
+
+// Name 实现 gorm.Plugin 接口
+func (p *AuditingPlugin) Name() string {
+	return "auditing"
+}
+
+// Initialize 实现 gorm.Plugin 接口，在创建和更新之前注册回调
+func (p *AuditingPlugin) Initialize(db *gorm.DB) error {
+	if err := db.Callback().Create().Before("gorm:create").Register("auditing:create", p.beforeCreate); err != nil {
+		return err
+	}
+	return db.Callback().Update().Before("gorm:update").Register("auditing:update", p.beforeUpdate)
+}
+
+// beforeCreate 创建时同时填充创建人和最后修改人
+func (p *AuditingPlugin) beforeCreate(db *gorm.DB) {
+	userID, ok := p.actor(db)
+	if !ok {
+		return
+	}
+	if db.Statement.Schema.LookUpField(createdByField) != nil {
+		db.Statement.SetColumn(createdByField, userID)
+	}
+	if db.Statement.Schema.LookUpField(updatedByField) != nil {
+		db.Statement.SetColumn(updatedByField, userID)
+	}
+}
+
+// beforeUpdate 更新时填充最后修改人
+func (p *AuditingPlugin) beforeUpdate(db *gorm.DB) {
+	userID, ok := p.actor(db)
+	if !ok || db.Statement.Schema.LookUpField(updatedByField) == nil {
+		return
+	}
+	db.Statement.SetColumn(updatedByField, userID)
+}
+
+// actor 获取当前操作人，语句出错或没有模型结构时跳过
+func (p *AuditingPlugin) actor(db *gorm.DB) (uint, bool) {
+	if db.Error != nil || db.Statement == nil || db.Statement.Schema == nil {
+		return 0, false
+	}
+	return ActorFromContext(db.Statement.Context)
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+type auditedRecord struct {
+	ID        uint
+	Name      string
+	CreatedBy uint
+	UpdatedBy uint
+}
+
+func TestAuditingPlugin(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true})
+	if err != nil {
+		t.Fatalf("Failed to open dry run database: %v", err)
+	}
+	if err := db.Use(&AuditingPlugin{}); err != nil {
+		t.Fatalf("Failed to register plugin: %v", err)
+	}
+
+	ctx := WithActor(context.Background(), 42)
+
+	record := &auditedRecord{Name: "created"}
+	if err := db.WithContext(ctx).Create(record).Error; err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if record.CreatedBy != 42 || record.UpdatedBy != 42 {
+		t.Fatalf("expected created_by and updated_by to be set, got %+v", record)
+	}
+
+	record = &auditedRecord{ID: 1, Name: "updated", CreatedBy: 7}
+	if err := db.WithContext(ctx).Save(record).Error; err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if record.CreatedBy != 7 || record.UpdatedBy != 42 {
+		t.Fatalf("expected only updated_by to change, got %+v", record)
+	}
+
+	stmt := db.WithContext(ctx).Model(&auditedRecord{ID: 1}).Updates(map[string]interface{}{"name": "patched"}).Statement
+	if sql := stmt.SQL.String(); !strings.Contains(sql, `"updated_by"`) || !slices.Contains(stmt.Vars, interface{}(uint(42))) {
+		t.Fatalf("expected updated_by in map updates, got %s %v", sql, stmt.Vars)
+	}
+
+	// 没有操作人时不填充
+	record = &auditedRecord{Name: "system"}
+	if err := db.Create(record).Error; err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if record.CreatedBy != 0 || record.UpdatedBy != 0 {
+		t.Fatalf("expected empty audit fields without actor, got %+v", record)
+	}
+}
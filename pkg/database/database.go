@@ -3,6 +3,9 @@ package database
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hedeqiang/skeleton/internal/config"
@@ -11,16 +14,17 @@ import (
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	gormlogger "gorm.io/gorm/logger"
+	"go.uber.org/zap"
 	"log"
 	"os"
 )
 
-// NewDatabases 初始化所有在配置中定义的数据源
-func NewDatabases(dbConfigs map[string]config.Database) (map[string]*gorm.DB, error) {
+// NewDatabases 初始化所有在配置中定义的数据源，GORM 日志写入 logger 下名为 gorm 的子 logger
+func NewDatabases(dbConfigs map[string]config.Database, logger *zap.Logger) (map[string]*gorm.DB, error) {
 	dataSources := make(map[string]*gorm.DB)
 
 	for name, cfg := range dbConfigs {
-		db, err := connect(&cfg)
+		db, err := connect(&cfg, logger)
 		if err != nil {
 			return nil, fmt.Errorf("failed to connect to data source [%s]: %w", name, err)
 		}
@@ -31,35 +35,86 @@ func NewDatabases(dbConfigs map[string]config.Database) (map[string]*gorm.DB, er
 	return dataSources, nil
 }
 
-func connect(cfg *config.Database) (*gorm.DB, error) {
+func connect(cfg *config.Database, logger *zap.Logger) (*gorm.DB, error) {
+	pgBouncer := false
+	switch cfg.ConnectionMode {
+	case "", config.ConnectionModeDirect:
+	case config.ConnectionModePgBouncer:
+		pgBouncer = true
+	default:
+		return nil, fmt.Errorf("unsupported connection mode: %s", cfg.ConnectionMode)
+	}
+
+	// PgBouncer 事务模式下连接在事务间复用，服务端预编译语句会错乱
+	if pgBouncer && cfg.PrepareStmt {
+		return nil, fmt.Errorf("prepare_stmt cannot be enabled with connection_mode %q", config.ConnectionModePgBouncer)
+	}
+	// 迁移锁是会话级咨询锁，事务级连接池无法保证加锁和解锁在同一服务端连接上
+	if pgBouncer && cfg.AutoMigrateOnStart {
+		return nil, fmt.Errorf("auto_migrate_on_start cannot be enabled with connection_mode %q", config.ConnectionModePgBouncer)
+	}
+
+	// PgBouncer 不接受 statement_timeout 启动参数，需在数据库角色上设置
+	if pgBouncer && cfg.StatementTimeout > 0 {
+		return nil, fmt.Errorf("statement_timeout cannot be enabled with connection_mode %q", config.ConnectionModePgBouncer)
+	}
+	if cfg.Type != "postgres" && cfg.StatementTimeout > 0 {
+		return nil, fmt.Errorf("statement_timeout is only supported by postgres, use query_timeout instead")
+	}
+
 	var dialector gorm.Dialector
 	switch cfg.Type {
 	case "mysql":
 		dialector = mysql.Open(cfg.DSN)
 	case "postgres":
-		dialector = postgres.Open(cfg.DSN)
+		dsn := cfg.DSN
+		if cfg.StatementTimeout > 0 {
+			var err error
+			if dsn, err = withRuntimeParam(dsn, "statement_timeout", strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)); err != nil {
+				return nil, err
+			}
+		}
+		dialector = postgres.New(postgres.Config{
+			DSN: dsn,
+			// pgx 默认会隐式预编译，经过 PgBouncer 时需改用简单协议
+			PreferSimpleProtocol: pgBouncer,
+		})
 	default:
 		return nil, fmt.Errorf("unsupported database type: %s", cfg.Type)
 	}
 
-	// 配置 GORM logger
-	gormLog := gormlogger.New(
-		log.New(os.Stdout, "\r\n", log.LstdFlags),
-		gormlogger.Config{
-			SlowThreshold:             200 * time.Millisecond,
-			LogLevel:                  gormlogger.Warn,
-			IgnoreRecordNotFoundError: true,
-			Colorful:                  true,
-		},
-	)
+	// GORM 日志写入 zap，SQL 为 debug 级别，可通过 logger.levels.gorm 单独开启
+	gormLog := newGormLogger(logger, slowQueryThreshold)
 
 	db, err := gorm.Open(dialector, &gorm.Config{
-		Logger: gormLog,
+		Logger:                 gormLog,
+		PrepareStmt:            cfg.PrepareStmt,
+		SkipDefaultTransaction: cfg.SkipDefaultTransaction,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	// 注册请求级 SQL 计数插件（未挂载统计的 context 不计数）
+	if err := db.Use(&QueryCounterPlugin{}); err != nil {
+		return nil, err
+	}
+
+	// 注册链路追踪插件（context 中没有正在记录的 span 时不创建）
+	if err := db.Use(&TracingPlugin{}); err != nil {
+		return nil, err
+	}
+
+	// 注册审计字段插件，根据 context 中的操作人填充 CreatedBy、UpdatedBy
+	if err := db.Use(&AuditingPlugin{}); err != nil {
+		return nil, err
+	}
+
+	// 注册 SQL 超时插件，未配置 query_timeout 时仍支持按调用通过 WithQueryTimeout 设置
+	if err := db.Use(&QueryTimeoutPlugin{Timeout: cfg.QueryTimeout}); err != nil {
+		return nil, err
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		return nil, err
@@ -73,6 +128,22 @@ func connect(cfg *config.Database) (*gorm.DB, error) {
 	return db, nil
 }
 
+// withRuntimeParam 在 postgres DSN 中追加连接参数，pgx 将无法识别的参数作为会话参数在建立连接时发送
+// 支持 URL 和 key=value 两种格式
+func withRuntimeParam(dsn, key, value string) (string, error) {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", fmt.Errorf("invalid postgres dsn: %w", err)
+		}
+		query := u.Query()
+		query.Set(key, value)
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+	return strings.TrimSpace(dsn) + " " + key + "=" + value, nil
+}
+
 // DBConfig 数据库配置
 type DBConfig struct {
 	Driver             string
@@ -85,6 +156,8 @@ type DBConfig struct {
 	LoggerLevel        gormlogger.LogLevel
 	DisableColor       bool
 	IgnoreRecordNotFound bool
+	PrepareStmt        bool
+	SkipDefaultTransaction bool
 }
 
 // Database 数据库包装器
@@ -100,6 +173,8 @@ func NewDatabase(config *DBConfig) (*Database, error) {
 	}
 
 	gormConfig := &gorm.Config{
+		PrepareStmt:            config.PrepareStmt,
+		SkipDefaultTransaction: config.SkipDefaultTransaction,
 		Logger: gormlogger.New(
 			log.New(os.Stdout, "\r\n", log.LstdFlags),
 			gormlogger.Config{
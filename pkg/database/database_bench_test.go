@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/internal/model"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 基准测试需要真实数据库，通过环境变量指定：
+//
+//	BENCH_DB_TYPE=postgres BENCH_DB_DSN="host=127.0.0.1 ..." go test -bench=UserCRUD ./pkg/database
+func benchDB(b *testing.B, prepareStmt, skipDefaultTx bool) *gorm.DB {
+	dsn := os.Getenv("BENCH_DB_DSN")
+	if dsn == "" {
+		b.Skip("BENCH_DB_DSN not set, skipping database benchmark")
+	}
+	dbType := os.Getenv("BENCH_DB_TYPE")
+	if dbType == "" {
+		dbType = "mysql"
+	}
+
+	db, err := connect(&config.Database{
+		Type:                   dbType,
+		DSN:                    dsn,
+		MaxOpenConns:           10,
+		MaxIdleConns:           10,
+		PrepareStmt:            prepareStmt,
+		SkipDefaultTransaction: skipDefaultTx,
+	}, zap.NewNop())
+	if err != nil {
+		b.Fatalf("Failed to connect database: %v", err)
+	}
+	if err := db.AutoMigrate(&model.User{}); err != nil {
+		b.Fatalf("Failed to migrate: %v", err)
+	}
+	b.Cleanup(func() {
+		db.Unscoped().Where("username LIKE ?", "bench_%").Delete(&model.User{})
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func benchmarkUserCRUD(b *testing.B, prepareStmt, skipDefaultTx bool) {
+	db := benchDB(b, prepareStmt, skipDefaultTx).WithContext(context.Background())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		user := &model.User{
+			Username: fmt.Sprintf("bench_%d_%d", b.N, i),
+			Email:    fmt.Sprintf("bench_%d_%d@example.com", b.N, i),
+			Password: "benchmark",
+			Status:   1,
+		}
+		if err := db.Create(user).Error; err != nil {
+			b.Fatalf("create: %v", err)
+		}
+
+		var found model.User
+		if err := db.First(&found, user.ID).Error; err != nil {
+			b.Fatalf("get: %v", err)
+		}
+
+		found.Status = 0
+		if err := db.Save(&found).Error; err != nil {
+			b.Fatalf("update: %v", err)
+		}
+
+		if err := db.Unscoped().Delete(&model.User{}, user.ID).Error; err != nil {
+			b.Fatalf("delete: %v", err)
+		}
+	}
+}
+
+func BenchmarkUserCRUD_Default(b *testing.B) {
+	benchmarkUserCRUD(b, false, false)
+}
+
+func BenchmarkUserCRUD_PrepareStmt(b *testing.B) {
+	benchmarkUserCRUD(b, true, false)
+}
+
+func BenchmarkUserCRUD_SkipDefaultTransaction(b *testing.B) {
+	benchmarkUserCRUD(b, false, true)
+}
+
+func BenchmarkUserCRUD_PrepareStmtAndSkipDefaultTransaction(b *testing.B) {
+	benchmarkUserCRUD(b, true, true)
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestIsDuplicateKey(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"gorm translated", gorm.ErrDuplicatedKey, true},
+		{"mysql duplicate entry", fmt.Errorf("create: %w", &mysql.MySQLError{Number: 1062}), true},
+		{"mysql other", &mysql.MySQLError{Number: 1045}, false},
+		{"postgres unique violation", &pgconn.PgError{Code: "23505"}, true},
+		{"postgres other", &pgconn.PgError{Code: "23503"}, false},
+		{"other", gorm.ErrRecordNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsDuplicateKey(tt.err); got != tt.want {
+				t.Fatalf("IsDuplicateKey(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package database
+
+import (
+	"context"
+	stdErrors "errors"
+	"fmt"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/logger"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+	"gorm.io/gorm/utils"
+)
+
+// LoggerName GORM 日志的 logger 名称，通过 logger.levels.gorm 单独调整级别
+const LoggerName = "gorm"
+
+// slowQueryThreshold 超过该耗时的 SQL 以 warn 级别记录
+const slowQueryThreshold = 200 * time.Millisecond
+
+// gormLogger 将 GORM 日志写入 zap，级别由 logger 配置控制而不是 GORM 的 LogMode：
+// 每条 SQL 为 debug，慢查询为 warn，执行出错为 error，未找到记录不视为错误
+type gormLogger struct {
+	logger        *zap.Logger
+	slowThreshold time.Duration
+}
+
+// newGormLogger 创建写入 zap 的 GORM logger，调用位置由 source 字段记录
+func newGormLogger(base *zap.Logger, slowThreshold time.Duration) gormlogger.Interface {
+	return &gormLogger{
+		logger:        base.Named(LoggerName).WithOptions(zap.WithCaller(false)),
+		slowThreshold: slowThreshold,
+	}
+}
+
+// LogMode 实现 gormlogger.Interface 接口，级别由 zap 控制，忽略 GORM 的设置
+func (l *gormLogger) LogMode(gormlogger.LogLevel) gormlogger.Interface {
+	return l
+}
+
+// Info 实现 gormlogger.Interface 接口
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.log(ctx, zapcore.InfoLevel, fmt.Sprintf(msg, data...))
+}
+
+// Warn 实现 gormlogger.Interface 接口
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.log(ctx, zapcore.WarnLevel, fmt.Sprintf(msg, data...))
+}
+
+// Error 实现 gormlogger.Interface 接口
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.log(ctx, zapcore.ErrorLevel, fmt.Sprintf(msg, data...))
+}
+
+// Trace 实现 gormlogger.Interface 接口，级别未开启时不生成 SQL
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+
+	lvl, msg := zapcore.DebugLevel, "SQL"
+	switch {
+	case err != nil && !stdErrors.Is(err, gorm.ErrRecordNotFound):
+		lvl, msg = zapcore.ErrorLevel, "SQL error"
+	case l.slowThreshold > 0 && elapsed > l.slowThreshold:
+		lvl, msg = zapcore.WarnLevel, "Slow SQL"
+	}
+
+	ce := l.logger.Check(lvl, msg)
+	if ce == nil {
+		return
+	}
+	sql, rows := fc()
+	fields := append([]zap.Field{
+		zap.String("sql", sql),
+		zap.Int64("rows", rows),
+		zap.Duration("elapsed", elapsed),
+		zap.String("source", utils.FileWithLineNum()),
+	}, logger.Fields(ctx)...)
+	if lvl == zapcore.ErrorLevel {
+		fields = append(fields, zap.Error(err))
+	}
+	ce.Write(fields...)
+}
+
+// ParamsFilter 实现 gorm.ParamsFilter 接口，只记录未绑定参数的 SQL，避免密码、令牌等敏感数据写入日志
+func (l *gormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func (l *gormLogger) log(ctx context.Context, lvl zapcore.Level, msg string) {
+	if ce := l.logger.Check(lvl, msg); ce != nil {
+		ce.Write(append([]zap.Field{zap.String("source", utils.FileWithLineNum())}, logger.Fields(ctx)...)...)
+	}
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	stdErrors "errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"gorm.io/gorm"
+)
+
+func TestGormLoggerTrace(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	l := newGormLogger(zap.New(core), 100*time.Millisecond)
+	sql := func() (string, int64) { return "SELECT * FROM users WHERE id = $1", 1 }
+
+	l.Trace(context.Background(), time.Now(), sql, nil)
+	l.Trace(context.Background(), time.Now().Add(-time.Second), sql, nil)
+	l.Trace(context.Background(), time.Now(), sql, gorm.ErrRecordNotFound)
+	l.Trace(context.Background(), time.Now(), sql, stdErrors.New("connection reset"))
+
+	want := []struct {
+		level   zapcore.Level
+		message string
+	}{
+		{zapcore.DebugLevel, "SQL"},
+		{zapcore.WarnLevel, "Slow SQL"},
+		{zapcore.DebugLevel, "SQL"}, // 未找到记录不视为错误
+		{zapcore.ErrorLevel, "SQL error"},
+	}
+	entries := logs.AllUntimed()
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(entries), len(want))
+	}
+	for i, entry := range entries {
+		if entry.Level != want[i].level || entry.Message != want[i].message || entry.LoggerName != LoggerName {
+			t.Errorf("entry %d = %s %q (%s), want %s %q", i, entry.Level, entry.Message, entry.LoggerName, want[i].level, want[i].message)
+		}
+	}
+
+	// 不绑定参数
+	if sql, params := l.(*gormLogger).ParamsFilter(context.Background(), "SELECT $1", "secret"); sql != "SELECT $1" || params != nil {
+		t.Fatalf("ParamsFilter = %q, %v", sql, params)
+	}
+}
+
+func TestGormLoggerSkipsDisabledLevels(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	l := newGormLogger(zap.New(core), 0)
+
+	l.Trace(context.Background(), time.Now(), func() (string, int64) {
+		t.Fatal("SQL must not be built when debug is disabled")
+		return "", 0
+	}, nil)
+	if logs.Len() != 0 {
+		t.Fatalf("got %d entries, want none", logs.Len())
+	}
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"database/sql"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// 连接池监控默认值
+const (
+	defaultPoolMonitorInterval   = 10 * time.Second
+	defaultPoolWaitWarnThreshold = 100 * time.Millisecond
+	defaultPoolShedUtilization   = 0.95
+)
+
+var (
+	poolConnections = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db_pool",
+		Name:      "connections",
+		Help:      "Number of connections in the pool by data source and state (in_use, idle, max_open).",
+	}, []string{"data_source", "state"})
+
+	poolWaitTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db_pool",
+		Name:      "wait_total",
+		Help:      "Total number of connection requests that had to wait for a free connection.",
+	}, []string{"data_source"})
+
+	poolWaitSeconds = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db_pool",
+		Name:      "wait_seconds_total",
+		Help:      "Total time spent waiting for a free connection.",
+	}, []string{"data_source"})
+
+	poolSaturated = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db_pool",
+		Name:      "saturated",
+		Help:      "Whether the pool was saturated in the last sampling interval (1) or not (0).",
+	}, []string{"data_source"})
+
+	poolShedTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db_pool",
+		Name:      "shed_requests_total",
+		Help:      "Total number of requests rejected because a database pool was saturated.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(poolConnections, poolWaitTotal, poolWaitSeconds, poolSaturated, poolShedTotal)
+}
+
+// PoolMonitor 定期采样各数据源的连接池统计，记录等待指标，平均等待过长时记录警告，
+// 并标记连接池是否饱和供 HTTP 层降载
+type PoolMonitor struct {
+	sources         map[string]func() sql.DBStats
+	logger          *zap.Logger
+	interval        time.Duration
+	warnThreshold   time.Duration
+	shedUtilization float64
+
+	last      map[string]sql.DBStats
+	saturated atomic.Bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewPoolMonitor 创建并启动连接池监控，未启用时返回 nil
+func NewPoolMonitor(dataSources map[string]*gorm.DB, cfg *config.DBPool, logger *zap.Logger) (*PoolMonitor, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	sources := make(map[string]func() sql.DBStats, len(dataSources))
+	for name, db := range dataSources {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sources[name] = sqlDB.Stats
+	}
+
+	m := newPoolMonitor(sources, cfg, logger)
+	go m.run()
+	return m, nil
+}
+
+func newPoolMonitor(sources map[string]func() sql.DBStats, cfg *config.DBPool, logger *zap.Logger) *PoolMonitor {
+	m := &PoolMonitor{
+		sources:         sources,
+		logger:          logger,
+		interval:        cfg.Interval,
+		warnThreshold:   cfg.WaitWarnThreshold,
+		shedUtilization: cfg.ShedUtilization,
+		last:            make(map[string]sql.DBStats, len(sources)),
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+	if m.interval <= 0 {
+		m.interval = defaultPoolMonitorInterval
+	}
+	if m.warnThreshold <= 0 {
+		m.warnThreshold = defaultPoolWaitWarnThreshold
+	}
+	if m.shedUtilization <= 0 || m.shedUtilization > 1 {
+		m.shedUtilization = defaultPoolShedUtilization
+	}
+	// 以启动时的累计值为基准，只统计监控期间的等待
+	for name, stats := range sources {
+		m.last[name] = stats()
+	}
+	return m
+}
+
+// Saturated 最近一次采样中是否有数据源的连接池饱和，nil 接收者返回 false
+func (m *PoolMonitor) Saturated() bool {
+	return m != nil && m.saturated.Load()
+}
+
+// RecordShed 记录一次因连接池饱和被拒绝的请求
+func (m *PoolMonitor) RecordShed() {
+	poolShedTotal.Inc()
+}
+
+// Stop 停止采样，可重复调用
+func (m *PoolMonitor) Stop() {
+	if m == nil {
+		return
+	}
+	m.stopOnce.Do(func() {
+		close(m.stop)
+		<-m.done
+	})
+}
+
+func (m *PoolMonitor) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.sample()
+		}
+	}
+}
+
+// sample 采样一次所有数据源，饱和条件为使用中连接占比达到阈值且本周期内出现等待
+func (m *PoolMonitor) sample() {
+	names := make([]string, 0, len(m.sources))
+	for name := range m.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	saturated := false
+	for _, name := range names {
+		stats := m.sources[name]()
+		prev := m.last[name]
+		m.last[name] = stats
+
+		waits := stats.WaitCount - prev.WaitCount
+		waited := stats.WaitDuration - prev.WaitDuration
+
+		poolConnections.WithLabelValues(name, "in_use").Set(float64(stats.InUse))
+		poolConnections.WithLabelValues(name, "idle").Set(float64(stats.Idle))
+		poolConnections.WithLabelValues(name, "max_open").Set(float64(stats.MaxOpenConnections))
+		if waits > 0 {
+			poolWaitTotal.WithLabelValues(name).Add(float64(waits))
+			poolWaitSeconds.WithLabelValues(name).Add(waited.Seconds())
+		}
+
+		var avgWait time.Duration
+		if waits > 0 {
+			avgWait = waited / time.Duration(waits)
+		}
+		if avgWait > m.warnThreshold {
+			m.logger.Warn("Database connection pool under pressure",
+				zap.String("data_source", name),
+				zap.Int64("waits", waits),
+				zap.Duration("avg_wait", avgWait),
+				zap.Int("in_use", stats.InUse),
+				zap.Int("max_open", stats.MaxOpenConnections),
+			)
+		}
+
+		full := stats.MaxOpenConnections > 0 &&
+			float64(stats.InUse)/float64(stats.MaxOpenConnections) >= m.shedUtilization &&
+			waits > 0
+		if full {
+			poolSaturated.WithLabelValues(name).Set(1)
+			saturated = true
+		} else {
+			poolSaturated.WithLabelValues(name).Set(0)
+		}
+	}
+
+	if m.saturated.Swap(saturated) != saturated {
+		if saturated {
+			m.logger.Warn("Database connection pool saturated")
+		} else {
+			m.logger.Info("Database connection pool recovered")
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestPoolMonitorSaturation(t *testing.T) {
+	stats := sql.DBStats{MaxOpenConnections: 10}
+	core, logs := observer.New(zap.WarnLevel)
+	m := newPoolMonitor(map[string]func() sql.DBStats{
+		"default": func() sql.DBStats { return stats },
+	}, &config.DBPool{WaitWarnThreshold: 50 * time.Millisecond, ShedUtilization: 0.9}, zap.New(core))
+
+	// 连接全部占用但没有等待，不视为饱和
+	stats.InUse = 10
+	m.sample()
+	if m.Saturated() {
+		t.Error("pool without waits should not be saturated")
+	}
+
+	// 出现等待且平均等待超过阈值
+	stats.WaitCount = 4
+	stats.WaitDuration = 400 * time.Millisecond
+	m.sample()
+	if !m.Saturated() {
+		t.Error("pool with waits at full utilization should be saturated")
+	}
+	if logs.FilterMessage("Database connection pool under pressure").Len() != 1 {
+		t.Errorf("pressure warnings = %d, want 1", logs.FilterMessage("Database connection pool under pressure").Len())
+	}
+
+	// 等待计数不再增长后恢复
+	stats.InUse = 3
+	m.sample()
+	if m.Saturated() {
+		t.Error("pool should recover when waits stop")
+	}
+	if logs.FilterMessage("Database connection pool under pressure").Len() != 1 {
+		t.Error("no new waits should not log another warning")
+	}
+}
+
+func TestPoolMonitorNil(t *testing.T) {
+	var m *PoolMonitor
+	if m.Saturated() {
+		t.Error("nil monitor should never be saturated")
+	}
+	m.Stop()
+
+	monitor, err := NewPoolMonitor(nil, &config.DBPool{}, zap.NewNop())
+	if monitor != nil || err != nil {
+		t.Errorf("disabled monitor = %v, %v", monitor, err)
+	}
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/timing"
+
+	"gorm.io/gorm"
+)
+
+// queryStatsKey 查询统计在 context 中的键
+type queryStatsKey struct{}
+
+// QueryStats 单个请求内的 SQL 查询统计
+type QueryStats struct {
+	mu      sync.Mutex
+	count   int
+	queries map[string]int
+}
+
+// WithQueryStats 在 context 中挂载一个新的查询统计
+func WithQueryStats(ctx context.Context) (context.Context, *QueryStats) {
+	stats := &QueryStats{queries: make(map[string]int)}
+	return context.WithValue(ctx, queryStatsKey{}, stats), stats
+}
+
+// QueryStatsFromContext 从 context 中获取查询统计，不存在时返回 nil
+func QueryStatsFromContext(ctx context.Context) *QueryStats {
+	if ctx == nil {
+		return nil
+	}
+	stats, _ := ctx.Value(queryStatsKey{}).(*QueryStats)
+	return stats
+}
+
+// record 记录一条执行过的 SQL
+func (s *QueryStats) record(sql string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	s.queries[sql]++
+}
+
+// Count 返回查询总数
+func (s *QueryStats) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Duplicates 返回重复执行次数不少于 min 的 SQL 及其次数
+func (s *QueryStats) Duplicates(min int) map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	duplicates := make(map[string]int)
+	for sql, n := range s.queries {
+		if n >= min {
+			duplicates[sql] = n
+		}
+	}
+	return duplicates
+}
+
+// queryStartKey SQL 开始执行时间在 Statement 实例中的键
+const queryStartKey = "query_counter:start"
+
+// QueryCounterPlugin 统计每个请求执行 SQL 次数和耗时的 GORM 插件
+// 只有 context 中挂载了 QueryStats 或 timing.Recorder 时才会记录，否则不做任何事情
+type QueryCounterPlugin struct{}
+
+// Name 实现 gorm.Plugin 接口
+func (p *QueryCounterPlugin) Name() string {
+	return "query_counter"
+}
+
+// Initialize 实现 gorm.Plugin 接口，在各类操作前后注册计时和计数回调
+func (p *QueryCounterPlugin) Initialize(db *gorm.DB) error {
+	const (
+		beforeName = "query_counter:before"
+		afterName  = "query_counter:after"
+	)
+
+	callbacks := []error{
+		db.Callback().Create().Before("gorm:create").Register(beforeName, p.before),
+		db.Callback().Create().After("gorm:create").Register(afterName, p.after("create")),
+		db.Callback().Query().Before("gorm:query").Register(beforeName, p.before),
+		db.Callback().Query().After("gorm:query").Register(afterName, p.after("query")),
+		db.Callback().Update().Before("gorm:update").Register(beforeName, p.before),
+		db.Callback().Update().After("gorm:update").Register(afterName, p.after("update")),
+		db.Callback().Delete().Before("gorm:delete").Register(beforeName, p.before),
+		db.Callback().Delete().After("gorm:delete").Register(afterName, p.after("delete")),
+		db.Callback().Row().Before("gorm:row").Register(beforeName, p.before),
+		db.Callback().Row().After("gorm:row").Register(afterName, p.after("row")),
+		db.Callback().Raw().Before("gorm:raw").Register(beforeName, p.before),
+		db.Callback().Raw().After("gorm:raw").Register(afterName, p.after("raw")),
+	}
+	for _, err := range callbacks {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// before 记录 SQL 开始执行时间
+func (p *QueryCounterPlugin) before(db *gorm.DB) {
+	if db.Statement == nil || timing.FromContext(db.Statement.Context) == nil {
+		return
+	}
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+// after 在 SQL 执行后记录到请求级统计中
+func (p *QueryCounterPlugin) after(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement == nil {
+			return
+		}
+
+		if start, ok := db.InstanceGet(queryStartKey); ok {
+			timing.Record(db.Statement.Context, timing.KindDB, operation+" "+db.Statement.Table, time.Since(start.(time.Time)))
+		}
+
+		stats := QueryStatsFromContext(db.Statement.Context)
+		if stats == nil {
+			return
+		}
+		// 使用未绑定参数的 SQL，便于识别 N+1 这类参数不同但语句相同的查询
+		stats.record(db.Statement.SQL.String())
+	}
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// queryTimeoutKey 按调用覆盖的 SQL 超时在 context 中的键
+type queryTimeoutKey struct{}
+
+// WithQueryTimeout 覆盖数据源的 query_timeout，ctx 内执行的每条 SQL 最多执行 timeout，0 表示不限制
+// 用于报表、批量清理等已知较慢的调用：
+//
+//	db := database.Conn(database.WithQueryTimeout(ctx, time.Minute), r.db)
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, queryTimeoutKey{}, timeout)
+}
+
+// queryDeadlineKey 本条 SQL 的截止时间在 Statement 实例中的键
+const queryDeadlineKey = "query_timeout:deadline"
+
+// queryDeadline 设置截止时间前的 context 及取消函数
+type queryDeadline struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// QueryTimeoutPlugin 为每条 SQL 设置执行截止时间的 GORM 插件
+// 超时后驱动取消查询并返回 context.DeadlineExceeded：pgx 向服务端发送取消请求，MySQL 驱动关闭该连接。
+// ctx 中已有更早的截止时间时以 ctx 为准；Rows() 返回的结果集在回调之外读取，不设置截止时间
+type QueryTimeoutPlugin struct {
+	// Timeout 未通过 WithQueryTimeout 覆盖时的默认超时，0 表示不限制
+	Timeout time.Duration
+}
+
+// Name 实现 gorm.Plugin 接口
+func (p *QueryTimeoutPlugin) Name() string {
+	return "query_timeout"
+}
+
+// Initialize 实现 gorm.Plugin 接口，在执行 SQL 的回调前后设置和取消截止时间
+func (p *QueryTimeoutPlugin) Initialize(db *gorm.DB) error {
+	const (
+		beforeName = "query_timeout:before"
+		afterName  = "query_timeout:after"
+	)
+
+	callbacks := []error{
+		db.Callback().Create().Before("gorm:create").Register(beforeName, p.before),
+		db.Callback().Create().After("gorm:create").Register(afterName, p.after),
+		db.Callback().Query().Before("gorm:query").Register(beforeName, p.before),
+		db.Callback().Query().After("gorm:query").Register(afterName, p.after),
+		db.Callback().Update().Before("gorm:update").Register(beforeName, p.before),
+		db.Callback().Update().After("gorm:update").Register(afterName, p.after),
+		db.Callback().Delete().Before("gorm:delete").Register(beforeName, p.before),
+		db.Callback().Delete().After("gorm:delete").Register(afterName, p.after),
+		db.Callback().Raw().Before("gorm:raw").Register(beforeName, p.before),
+		db.Callback().Raw().After("gorm:raw").Register(afterName, p.after),
+	}
+	for _, err := range callbacks {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// timeout 返回 ctx 对应的超时，WithQueryTimeout 优先于插件默认值
+func (p *QueryTimeoutPlugin) timeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(queryTimeoutKey{}).(time.Duration); ok {
+		return timeout
+	}
+	return p.Timeout
+}
+
+// before 为本条 SQL 的 context 设置截止时间
+func (p *QueryTimeoutPlugin) before(db *gorm.DB) {
+	if db.Statement == nil || db.Statement.Context == nil {
+		return
+	}
+	ctx := db.Statement.Context
+	timeout := p.timeout(ctx)
+	if timeout <= 0 {
+		return
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+		return
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	db.InstanceSet(queryDeadlineKey, queryDeadline{ctx: ctx, cancel: cancel})
+	db.Statement.Context = timeoutCtx
+}
+
+// after 取消截止时间并恢复原 context，同一会话上的后续调用不受本条 SQL 的超时影响
+func (p *QueryTimeoutPlugin) after(db *gorm.DB) {
+	if db.Statement == nil {
+		return
+	}
+	// 与 InstanceSet 使用相同的键，取出后删除，避免会话复用时重复取消
+	value, ok := db.Statement.Settings.LoadAndDelete(fmt.Sprintf("%p", db.Statement) + queryDeadlineKey)
+	if !ok {
+		return
+	}
+	deadline := value.(queryDeadline)
+	deadline.cancel()
+	db.Statement.Context = deadline.ctx
+}
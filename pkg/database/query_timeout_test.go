@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// deadlineConnector 记录每条语句执行时 context 的截止时间，SLOW 语句一直阻塞到 context 结束
+type deadlineConnector struct{ deadlines *[]time.Duration }
+
+func (c deadlineConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return deadlineConn(c), nil
+}
+
+func (c deadlineConnector) Driver() driver.Driver { return nil }
+
+type deadlineConn struct{ deadlines *[]time.Duration }
+
+func (c deadlineConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c deadlineConn) Close() error { return nil }
+
+func (c deadlineConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions not supported")
+}
+
+func (c deadlineConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	var remaining time.Duration
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining = time.Until(deadline)
+	}
+	*c.deadlines = append(*c.deadlines, remaining)
+
+	if query == "SLOW" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func deadlineDB(t *testing.T, timeout time.Duration) (*gorm.DB, *[]time.Duration) {
+	t.Helper()
+	deadlines := &[]time.Duration{}
+	sqlDB := sql.OpenDB(deadlineConnector{deadlines: deadlines})
+	sqlDB.SetMaxOpenConns(1)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.Use(&QueryTimeoutPlugin{Timeout: timeout}); err != nil {
+		t.Fatalf("Failed to register plugin: %v", err)
+	}
+	return db, deadlines
+}
+
+func TestQueryTimeoutPlugin(t *testing.T) {
+	db, deadlines := deadlineDB(t, 20*time.Millisecond)
+
+	err := db.WithContext(context.Background()).Exec("SLOW").Error
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Exec() error = %v, want context.DeadlineExceeded", err)
+	}
+
+	// 同一会话的后续语句重新计时，不受上一条语句的截止时间影响
+	session := db.WithContext(context.Background())
+	if err := session.Exec("SELECT 1").Error; err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+	if err := session.Exec("SELECT 2").Error; err != nil {
+		t.Fatalf("Exec() error = %v", err)
+	}
+
+	// ctx 中更早的截止时间优先
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+	defer cancel()
+	db.WithContext(ctx).Exec("SELECT 3")
+
+	// 按调用覆盖，0 表示不限制
+	db.WithContext(WithQueryTimeout(context.Background(), time.Minute)).Exec("SELECT 4")
+	db.WithContext(WithQueryTimeout(context.Background(), 0)).Exec("SELECT 5")
+
+	got := *deadlines
+	if len(got) != 6 {
+		t.Fatalf("executed %d statements, want 6", len(got))
+	}
+	for i, remaining := range got[1:3] {
+		if remaining <= 0 || remaining > 20*time.Millisecond {
+			t.Errorf("statement %d deadline = %s, want within default timeout", i+1, remaining)
+		}
+	}
+	if got[3] <= 0 || got[3] > 5*time.Millisecond {
+		t.Errorf("ctx deadline = %s, want the earlier ctx deadline", got[3])
+	}
+	if got[4] <= 20*time.Millisecond {
+		t.Errorf("overridden deadline = %s, want about a minute", got[4])
+	}
+	if got[5] != 0 {
+		t.Errorf("disabled timeout deadline = %s, want none", got[5])
+	}
+}
+
+func TestWithRuntimeParam(t *testing.T) {
+	tests := []struct {
+		dsn  string
+		want string
+	}{
+		{"host=localhost user=app dbname=app", "host=localhost user=app dbname=app statement_timeout=30000"},
+		{"postgres://app@localhost:5432/app?sslmode=disable", "postgres://app@localhost:5432/app?sslmode=disable&statement_timeout=30000"},
+	}
+	for _, tt := range tests {
+		got, err := withRuntimeParam(tt.dsn, "statement_timeout", "30000")
+		if err != nil || got != tt.want {
+			t.Errorf("withRuntimeParam(%q) = %q, %v, want %q", tt.dsn, got, err, tt.want)
+		}
+	}
+}
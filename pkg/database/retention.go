@@ -0,0 +1,172 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 保留策略默认值
+const (
+	defaultRetentionBatchSize = 1000
+	defaultRetentionKeyColumn = "id"
+)
+
+// identifierPattern 表名、列名只允许字母、数字、下划线，表名可带 schema 前缀
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+var retentionPurgedRows = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: metrics.Namespace,
+	Subsystem: "retention",
+	Name:      "purged_rows_total",
+	Help:      "Total number of rows removed by retention policies, by policy and action (delete, archive).",
+}, []string{"policy", "action"})
+
+func init() {
+	metrics.Registry.MustRegister(retentionPurgedRows)
+}
+
+// ValidateRetentionPolicy 校验保留策略，表名和列名会拼接到 SQL 中，只允许普通标识符
+func ValidateRetentionPolicy(policy config.RetentionPolicy) error {
+	switch {
+	case !identifierPattern.MatchString(policy.Table):
+		return fmt.Errorf("invalid table %q", policy.Table)
+	case !identifierPattern.MatchString(policy.Column):
+		return fmt.Errorf("invalid column %q", policy.Column)
+	case policy.KeyColumn != "" && !identifierPattern.MatchString(policy.KeyColumn):
+		return fmt.Errorf("invalid key_column %q", policy.KeyColumn)
+	case policy.After <= 0:
+		return fmt.Errorf("after must be positive")
+	}
+
+	switch policy.Action {
+	case "", config.RetentionActionDelete:
+	case config.RetentionActionArchive:
+		if !identifierPattern.MatchString(policy.ArchiveTable) {
+			return fmt.Errorf("invalid archive_table %q", policy.ArchiveTable)
+		}
+	default:
+		return fmt.Errorf("unknown action %q", policy.Action)
+	}
+	return nil
+}
+
+// Purger 按保留策略分批删除或归档过期数据
+//
+// 每批先按主键查出到期的行，再按主键删除（archive 时在同一事务中先复制到归档表），
+// 避免一次删除大量数据长时间锁表；直接执行 SQL，软删除的行同样会被彻底删除
+type Purger struct {
+	db        *gorm.DB
+	batchSize int
+}
+
+// NewPurger 创建保留策略执行器，batchSize 不大于 0 时使用默认值
+func NewPurger(db *gorm.DB, batchSize int) *Purger {
+	if batchSize <= 0 {
+		batchSize = defaultRetentionBatchSize
+	}
+	return &Purger{db: db, batchSize: batchSize}
+}
+
+// Count 返回时间列早于 before 的行数，用于 dry-run
+func (p *Purger) Count(ctx context.Context, policy config.RetentionPolicy, before time.Time) (int64, error) {
+	var count int64
+	err := p.db.WithContext(ctx).
+		Raw("SELECT COUNT(*) FROM ? WHERE ? < ?", clause.Table{Name: policy.Table}, clause.Column{Name: policy.Column}, before).
+		Scan(&count).Error
+	return count, err
+}
+
+// Purge 分批处理时间列早于 before 的行，返回已处理的行数
+// ctx 取消或某一批失败时停止，已完成的批次不回滚，返回值包含这些批次
+func (p *Purger) Purge(ctx context.Context, name string, policy config.RetentionPolicy, before time.Time) (int64, error) {
+	action := policy.Action
+	if action == "" {
+		action = config.RetentionActionDelete
+	}
+	key := policy.KeyColumn
+	if key == "" {
+		key = defaultRetentionKeyColumn
+	}
+
+	var purged int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return purged, err
+		}
+
+		keys, err := p.expiredKeys(ctx, policy, key, before)
+		if err != nil {
+			return purged, err
+		}
+		if len(keys) == 0 {
+			return purged, nil
+		}
+
+		rows, err := p.removeBatch(ctx, policy, action, key, keys)
+		purged += rows
+		retentionPurgedRows.WithLabelValues(name, action).Add(float64(rows))
+		if err != nil {
+			return purged, err
+		}
+		if len(keys) < p.batchSize {
+			return purged, nil
+		}
+	}
+}
+
+// expiredKeys 按主键顺序查出一批到期行的主键
+func (p *Purger) expiredKeys(ctx context.Context, policy config.RetentionPolicy, key string, before time.Time) ([]interface{}, error) {
+	rows, err := p.db.WithContext(ctx).
+		Raw("SELECT ? FROM ? WHERE ? < ? ORDER BY ? LIMIT ?",
+			clause.Column{Name: key}, clause.Table{Name: policy.Table}, clause.Column{Name: policy.Column}, before,
+			clause.Column{Name: key}, p.batchSize).
+		Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []interface{}
+	for rows.Next() {
+		var value interface{}
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		keys = append(keys, value)
+	}
+	return keys, rows.Err()
+}
+
+// removeBatch 删除一批行，archive 时在同一事务中先复制到归档表
+func (p *Purger) removeBatch(ctx context.Context, policy config.RetentionPolicy, action, key string, keys []interface{}) (int64, error) {
+	var removed int64
+	err := p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if action == config.RetentionActionArchive {
+			err := tx.Exec("INSERT INTO ? SELECT * FROM ? WHERE ? IN ?",
+				clause.Table{Name: policy.ArchiveTable}, clause.Table{Name: policy.Table}, clause.Column{Name: key}, keys).Error
+			if err != nil {
+				return fmt.Errorf("failed to archive rows into %s: %w", policy.ArchiveTable, err)
+			}
+		}
+
+		result := tx.Exec("DELETE FROM ? WHERE ? IN ?", clause.Table{Name: policy.Table}, clause.Column{Name: key}, keys)
+		if result.Error != nil {
+			return fmt.Errorf("failed to delete rows from %s: %w", policy.Table, result.Error)
+		}
+		removed = result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// keysConnector 查询时依次返回预设的主键批次，其余语句只记录
+type keysConnector struct {
+	rec     *recorder
+	batches *[][]int64
+}
+
+func (c keysConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return keysConn(c), nil
+}
+
+func (c keysConnector) Driver() driver.Driver { return nil }
+
+type keysConn keysConnector
+
+func (c keysConn) Prepare(query string) (driver.Stmt, error) {
+	return recordingConn{rec: c.rec}.Prepare(query)
+}
+
+func (c keysConn) Close() error { return nil }
+
+func (c keysConn) Begin() (driver.Tx, error) {
+	return recordingConn{rec: c.rec}.Begin()
+}
+
+func (c keysConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.rec.add(query)
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (c keysConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.rec.add(query)
+	var batch []int64
+	if len(*c.batches) > 0 {
+		batch, *c.batches = (*c.batches)[0], (*c.batches)[1:]
+	}
+	return &keyRows{keys: batch}, nil
+}
+
+type keyRows struct{ keys []int64 }
+
+func (r *keyRows) Columns() []string { return []string{"id"} }
+func (r *keyRows) Close() error      { return nil }
+
+func (r *keyRows) Next(dest []driver.Value) error {
+	if len(r.keys) == 0 {
+		return io.EOF
+	}
+	dest[0], r.keys = r.keys[0], r.keys[1:]
+	return nil
+}
+
+func keysDB(t *testing.T, batches ...[]int64) (*gorm.DB, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	sqlDB := sql.OpenDB(keysConnector{rec: rec, batches: &batches})
+	sqlDB.SetMaxOpenConns(1)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("Failed to open test database: %v", err)
+	}
+	return db, rec
+}
+
+func TestPurgerDeletesInBatches(t *testing.T) {
+	db, rec := keysDB(t, []int64{1, 2}, []int64{3})
+	policy := config.RetentionPolicy{Table: "users", Column: "deleted_at", After: time.Hour}
+
+	purged, err := NewPurger(db, 2).Purge(context.Background(), "users", policy, time.Now())
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if purged != 3 {
+		t.Errorf("purged = %d, want 3", purged)
+	}
+
+	stmts := rec.String()
+	for _, want := range []string{
+		`SELECT "id" FROM "users" WHERE "deleted_at" < $1 ORDER BY "id" LIMIT $2`,
+		`DELETE FROM "users" WHERE "id" IN ($1,$2)`,
+		`DELETE FROM "users" WHERE "id" IN ($1)`,
+	} {
+		if !strings.Contains(stmts, want) {
+			t.Errorf("statements missing %q:\n%s", want, stmts)
+		}
+	}
+	// 最后一批不足 batch_size 时不再查询
+	if n := strings.Count(stmts, "SELECT"); n != 2 {
+		t.Errorf("queried %d batches, want 2", n)
+	}
+}
+
+func TestPurgerArchivesBeforeDelete(t *testing.T) {
+	db, rec := keysDB(t, []int64{7})
+	policy := config.RetentionPolicy{
+		Table: "audit_logs", Column: "created_at", After: time.Hour,
+		Action: config.RetentionActionArchive, ArchiveTable: "audit_logs_archive",
+	}
+
+	if _, err := NewPurger(db, 10).Purge(context.Background(), "audit_logs", policy, time.Now()); err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+
+	want := `BEGIN; INSERT INTO "audit_logs_archive" SELECT * FROM "audit_logs" WHERE "id" IN ($1); DELETE FROM "audit_logs" WHERE "id" IN ($1); COMMIT`
+	if got := rec.String(); !strings.HasSuffix(got, want) {
+		t.Errorf("statements = %s\nwant suffix %s", got, want)
+	}
+}
+
+func TestValidateRetentionPolicy(t *testing.T) {
+	valid := config.RetentionPolicy{Table: "public.users", Column: "deleted_at", After: time.Hour}
+	if err := ValidateRetentionPolicy(valid); err != nil {
+		t.Errorf("valid policy: %v", err)
+	}
+
+	for _, policy := range []config.RetentionPolicy{
+		{Table: "users; DROP TABLE users", Column: "deleted_at", After: time.Hour},
+		{Table: "users", Column: "", After: time.Hour},
+		{Table: "users", Column: "deleted_at"},
+		{Table: "users", Column: "deleted_at", After: time.Hour, Action: "truncate"},
+		{Table: "users", Column: "deleted_at", After: time.Hour, Action: config.RetentionActionArchive},
+	} {
+		if err := ValidateRetentionPolicy(policy); err == nil {
+			t.Errorf("ValidateRetentionPolicy(%+v) should fail", policy)
+		}
+	}
+}
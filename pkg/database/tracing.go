@@ -0,0 +1,113 @@
+package database
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// tracerName 数据库链路追踪的 instrumentation 名称
+const tracerName = "github.com/hedeqiang/skeleton/pkg/database"
+
+// tracingSpanKey span 在 Statement 实例中的键
+const tracingSpanKey = "tracing:span"
+
+// TracingPlugin 为每条 SQL 创建 client span 的 GORM 插件
+// 只有 context 中存在正在记录的 span 时才创建，未采样的请求和后台任务不产生额外开销
+type TracingPlugin struct{}
+
+// Name 实现 gorm.Plugin 接口
+func (p *TracingPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize 实现 gorm.Plugin 接口，在各类操作前后注册 span 的开始和结束回调
+func (p *TracingPlugin) Initialize(db *gorm.DB) error {
+	const (
+		beforeName = "tracing:before"
+		afterName  = "tracing:after"
+	)
+
+	callbacks := []error{
+		db.Callback().Create().Before("gorm:create").Register(beforeName, p.before("create")),
+		db.Callback().Create().After("gorm:create").Register(afterName, p.after),
+		db.Callback().Query().Before("gorm:query").Register(beforeName, p.before("query")),
+		db.Callback().Query().After("gorm:query").Register(afterName, p.after),
+		db.Callback().Update().Before("gorm:update").Register(beforeName, p.before("update")),
+		db.Callback().Update().After("gorm:update").Register(afterName, p.after),
+		db.Callback().Delete().Before("gorm:delete").Register(beforeName, p.before("delete")),
+		db.Callback().Delete().After("gorm:delete").Register(afterName, p.after),
+		db.Callback().Row().Before("gorm:row").Register(beforeName, p.before("row")),
+		db.Callback().Row().After("gorm:row").Register(afterName, p.after),
+		db.Callback().Raw().Before("gorm:raw").Register(beforeName, p.before("raw")),
+		db.Callback().Raw().After("gorm:raw").Register(afterName, p.after),
+	}
+	for _, err := range callbacks {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// before 开始 span，span 的 context 写回 Statement，事务内的后续 SQL 仍以请求 span 为父 span
+func (p *TracingPlugin) before(operation string) func(db *gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement == nil || db.Statement.Context == nil || !oteltrace.SpanFromContext(db.Statement.Context).IsRecording() {
+			return
+		}
+
+		name := operation
+		if db.Statement.Table != "" {
+			name += " " + db.Statement.Table
+		}
+		_, span := otel.Tracer(tracerName).Start(db.Statement.Context, name,
+			oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+			oteltrace.WithAttributes(
+				dbSystem(db.Dialector.Name()),
+				semconv.DBOperationName(operation),
+				semconv.DBCollectionName(db.Statement.Table),
+			),
+		)
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+// after 记录未绑定参数的 SQL 和错误并结束 span
+func (p *TracingPlugin) after(db *gorm.DB) {
+	value, _ := db.InstanceGet(tracingSpanKey)
+	span, ok := value.(oteltrace.Span)
+	if !ok {
+		return
+	}
+	// 同一 Statement 可能被后续操作复用，清除已结束的 span
+	db.InstanceSet(tracingSpanKey, nil)
+
+	// 不记录绑定参数，避免密码、令牌等敏感数据进入链路
+	span.SetAttributes(semconv.DBQueryText(db.Statement.SQL.String()))
+	// 未找到记录属于正常业务结果，不标记为错误
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+	span.End()
+}
+
+// dbSystem 将 GORM 方言名称转换为 db.system 属性
+func dbSystem(dialector string) attribute.KeyValue {
+	switch dialector {
+	case "postgres":
+		return semconv.DBSystemPostgreSQL
+	case "mysql":
+		return semconv.DBSystemMySQL
+	case "sqlite":
+		return semconv.DBSystemSqlite
+	default:
+		return semconv.DBSystemKey.String(dialector)
+	}
+}
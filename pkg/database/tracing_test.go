@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestTracingPlugin(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(tp)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	deadlines := &[]time.Duration{}
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(deadlineConnector{deadlines: deadlines})}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	if err := db.Use(&TracingPlugin{}); err != nil {
+		t.Fatalf("Failed to register plugin: %v", err)
+	}
+
+	// 没有父 span 时不创建
+	if err := db.WithContext(context.Background()).Exec("UPDATE users SET status = ?", 1).Error; err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	if spans := recorder.Ended(); len(spans) != 0 {
+		t.Fatalf("recorded %d spans without a parent span", len(spans))
+	}
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	if err := db.WithContext(ctx).Exec("UPDATE users SET status = ?", 1).Error; err != nil {
+		t.Fatalf("Exec failed: %v", err)
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want the SQL span and its parent", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "raw" || span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("unexpected span %q with parent %s", span.Name(), span.Parent().SpanID())
+	}
+	attrs := make(map[string]string)
+	for _, attr := range span.Attributes() {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	if attrs[string(semconv.DBSystemKey)] != "postgresql" || attrs[string(semconv.DBQueryTextKey)] != "UPDATE users SET status = $1" {
+		t.Fatalf("unexpected attributes %v", attrs)
+	}
+}
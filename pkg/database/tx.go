@@ -0,0 +1,109 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// ErrSavepointUnsupported 数据库驱动不支持保存点，无法执行嵌套事务
+var ErrSavepointUnsupported = errors.New("database driver does not support savepoints")
+
+// txKey context 中保存当前事务的键
+type txKey struct{}
+
+// txState 当前事务及其所属数据源
+type txState struct {
+	// config 数据源根连接的配置，用于区分不同数据源
+	config *gorm.Config
+	tx     *gorm.DB
+	depth  int
+}
+
+// TxManager 事务管理器，事务通过 context 传递给仓储
+//
+// 在已有事务的 context 中再次调用 Transaction 时以保存点嵌套执行：
+// 内层失败只回滚到保存点并把错误返回给外层，由外层决定忽略错误继续提交还是整体回滚。
+type TxManager struct {
+	db    *gorm.DB
+	retry TxRetryPolicy // WithRetryableTx 使用的重试策略
+}
+
+// NewTxManager 创建事务管理器，WithRetryableTx 使用 DefaultTxRetryPolicy
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db, retry: DefaultTxRetryPolicy}
+}
+
+// Transaction 在事务中执行 fn，fn 内应使用传入的 ctx 调用仓储
+func (m *TxManager) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if state := m.current(ctx); state != nil {
+		return m.nested(ctx, state, fn)
+	}
+
+	return m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, &txState{config: m.db.Config, tx: tx}))
+	})
+}
+
+// nested 以保存点执行内层事务，fn 返回错误或 panic 时回滚到保存点
+func (m *TxManager) nested(ctx context.Context, state *txState, fn func(ctx context.Context) error) (err error) {
+	if _, ok := state.tx.Dialector.(gorm.SavePointerDialectorInterface); !ok {
+		return fmt.Errorf("%w: %s", ErrSavepointUnsupported, state.tx.Dialector.Name())
+	}
+
+	depth := state.depth + 1
+	name := fmt.Sprintf("sp_%d", depth)
+	if err := savepointExec(ctx, state.tx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("failed to create savepoint %s: %w", name, err)
+	}
+
+	panicked := true
+	defer func() {
+		if !panicked && err == nil {
+			return
+		}
+		// panic 时回滚后继续向上传播，由外层事务整体回滚
+		if rbErr := savepointExec(ctx, state.tx, "ROLLBACK TO SAVEPOINT "+name); rbErr != nil && !panicked {
+			err = fmt.Errorf("failed to rollback to savepoint %s: %v: %w", name, rbErr, err)
+		}
+	}()
+
+	err = fn(context.WithValue(ctx, txKey{}, &txState{config: state.config, tx: state.tx, depth: depth}))
+	panicked = false
+	return err
+}
+
+// savepointExec 执行保存点语句
+// GORM 方言的 SavePoint/RollbackTo 会丢弃执行错误，这里直接执行以便返回错误；
+// 保存点语句不支持预编译，预编译模式下使用底层事务执行
+func savepointExec(ctx context.Context, tx *gorm.DB, sql string) error {
+	session := tx.Session(&gorm.Session{Context: ctx})
+	if stmtTx, ok := session.Statement.ConnPool.(*gorm.PreparedStmtTX); ok {
+		session.Statement.ConnPool = stmtTx.Tx
+	}
+	return session.Exec(sql).Error
+}
+
+// current 返回 context 中属于本数据源的事务
+func (m *TxManager) current(ctx context.Context) *txState {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok || state.config != m.db.Config {
+		return nil
+	}
+	return state
+}
+
+// InTransaction 判断 context 中是否已有 db 所属数据源的事务
+func (m *TxManager) InTransaction(ctx context.Context) bool {
+	return m.current(ctx) != nil
+}
+
+// Conn 返回 ctx 对应的数据库会话：ctx 中有同一数据源的事务时使用事务连接，否则使用 db
+func Conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.config == db.Config {
+		return state.tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
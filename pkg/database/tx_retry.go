@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 可重试的事务冲突错误码
+const (
+	mysqlDeadlock                = 1213
+	postgresSerializationFailure = "40001"
+	postgresDeadlockDetected     = "40P01"
+)
+
+// 事务冲突原因，用作重试指标的 reason 标签
+const (
+	txConflictDeadlock      = "deadlock"
+	txConflictSerialization = "serialization"
+)
+
+var (
+	txRetriesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db_tx",
+		Name:      "retries_total",
+		Help:      "Total number of transactions retried after a deadlock or serialization failure, by reason.",
+	}, []string{"reason"})
+
+	txRetriesExhausted = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "db_tx",
+		Name:      "retries_exhausted_total",
+		Help:      "Total number of transactions that still failed with a deadlock or serialization failure after all retries, by reason.",
+	}, []string{"reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(txRetriesTotal, txRetriesExhausted)
+}
+
+// TxRetryPolicy 可重试事务的重试策略，按指数退避并带随机抖动
+type TxRetryPolicy struct {
+	MaxRetries int           // 最大重试次数，0 表示不重试
+	MinBackoff time.Duration // 首次重试等待时间
+	MaxBackoff time.Duration // 单次等待时间上限
+}
+
+// DefaultTxRetryPolicy 默认事务重试策略，冲突通常在毫秒级内解除，等待时间较短
+var DefaultTxRetryPolicy = TxRetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 20 * time.Millisecond,
+	MaxBackoff: time.Second,
+}
+
+// backoff 返回第 attempt 次重试前的等待时间，在 [wait/2, wait] 之间随机，避免冲突的事务同时重试再次冲突
+func (p TxRetryPolicy) backoff(attempt int) time.Duration {
+	maxBackoff := p.MaxBackoff
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultTxRetryPolicy.MaxBackoff
+	}
+	wait := p.MinBackoff
+	if wait <= 0 {
+		wait = DefaultTxRetryPolicy.MinBackoff
+	}
+	for i := 0; i < attempt && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	wait = min(wait, maxBackoff)
+	return wait/2 + rand.N(wait/2+1)
+}
+
+// txConflict 返回事务冲突的原因：MySQL 死锁 (1213)、Postgres 序列化失败 (40001) 或死锁 (40P01)，
+// 其他错误返回空字符串。发生这些错误时数据库已回滚整个事务，重新执行即可
+func txConflict(err error) string {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDeadlock {
+		return txConflictDeadlock
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case postgresSerializationFailure:
+			return txConflictSerialization
+		case postgresDeadlockDetected:
+			return txConflictDeadlock
+		}
+	}
+	return ""
+}
+
+// IsRetryableTxError 判断错误是否为可重试的事务冲突（死锁或序列化失败）
+func IsRetryableTxError(err error) bool {
+	return err != nil && txConflict(err) != ""
+}
+
+// WithRetryPolicy 返回使用指定重试策略的事务管理器，与原事务管理器共用数据源
+func (m *TxManager) WithRetryPolicy(policy TxRetryPolicy) *TxManager {
+	return &TxManager{db: m.db, retry: policy}
+}
+
+// WithRetryableTx 在事务中执行 fn，遇到死锁或序列化失败时按重试策略退避后重新执行整个事务
+//
+// fn 可能被执行多次，除数据库写入外不应有其他副作用，消息等应在提交后发布或写入 outbox。
+// ctx 中已有同一数据源的事务时按 Transaction 嵌套执行且不重试：冲突会使外层事务整体回滚，
+// 只能由最外层的 WithRetryableTx 重新执行
+func (m *TxManager) WithRetryableTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.InTransaction(ctx) {
+		return m.Transaction(ctx, fn)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := m.Transaction(ctx, fn)
+		reason := txConflict(err)
+		if reason == "" {
+			return err
+		}
+		if attempt >= m.retry.MaxRetries {
+			txRetriesExhausted.WithLabelValues(reason).Inc()
+			return err
+		}
+
+		txRetriesTotal.WithLabelValues(reason).Inc()
+		timer := time.NewTimer(m.retry.backoff(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestIsRetryableTxError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"mysql deadlock", fmt.Errorf("update: %w", &mysql.MySQLError{Number: 1213}), true},
+		{"mysql duplicate entry", &mysql.MySQLError{Number: 1062}, false},
+		{"postgres serialization failure", &pgconn.PgError{Code: "40001"}, true},
+		{"postgres deadlock", &pgconn.PgError{Code: "40P01"}, true},
+		{"postgres unique violation", &pgconn.PgError{Code: "23505"}, false},
+		{"other", errors.New("boom"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryableTxError(tt.err); got != tt.want {
+				t.Fatalf("IsRetryableTxError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithRetryableTxRetriesConflicts(t *testing.T) {
+	db, rec := recordingDB(t)
+	m := NewTxManager(db).WithRetryPolicy(TxRetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	attempts := 0
+	err := m.WithRetryableTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		Conn(ctx, db).Exec("UPDATE a")
+		if attempts < 3 {
+			return &pgconn.PgError{Code: "40001"}
+		}
+		return nil
+	})
+	if err != nil || attempts != 3 {
+		t.Fatalf("WithRetryableTx() = %v after %d attempts, want success after 3", err, attempts)
+	}
+	want := "BEGIN; UPDATE a; ROLLBACK; BEGIN; UPDATE a; ROLLBACK; BEGIN; UPDATE a; COMMIT"
+	if got := rec.String(); got != want {
+		t.Errorf("statements = %s\nwant %s", got, want)
+	}
+}
+
+func TestWithRetryableTxGivesUp(t *testing.T) {
+	db, _ := recordingDB(t)
+	m := NewTxManager(db).WithRetryPolicy(TxRetryPolicy{MaxRetries: 2, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	deadlock := &mysql.MySQLError{Number: 1213}
+	attempts := 0
+	err := m.WithRetryableTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return deadlock
+	})
+	if !errors.Is(err, deadlock) || attempts != 3 {
+		t.Fatalf("WithRetryableTx() = %v after %d attempts, want deadlock after 3", err, attempts)
+	}
+
+	// 其他错误不重试
+	attempts = 0
+	other := errors.New("boom")
+	if err := m.WithRetryableTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		return other
+	}); !errors.Is(err, other) || attempts != 1 {
+		t.Fatalf("WithRetryableTx() = %v after %d attempts, want boom after 1", err, attempts)
+	}
+}
+
+func TestWithRetryableTxNestedDoesNotRetry(t *testing.T) {
+	db, _ := recordingDB(t)
+	m := NewTxManager(db).WithRetryPolicy(TxRetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	inner, outer := 0, 0
+	err := m.WithRetryableTx(context.Background(), func(ctx context.Context) error {
+		outer++
+		return m.WithRetryableTx(ctx, func(ctx context.Context) error {
+			inner++
+			if outer == 1 {
+				return &pgconn.PgError{Code: "40P01"}
+			}
+			return nil
+		})
+	})
+	// 内层冲突不在保存点重试，由外层重新执行整个事务
+	if err != nil || outer != 2 || inner != 2 {
+		t.Fatalf("WithRetryableTx() = %v, outer %d, inner %d, want success with 2 outer attempts", err, outer, inner)
+	}
+}
@@ -0,0 +1,158 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// recorder 记录发送到数据库的语句
+type recorder struct {
+	mu    sync.Mutex
+	stmts []string
+}
+
+func (r *recorder) add(stmt string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stmts = append(r.stmts, stmt)
+}
+
+func (r *recorder) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return strings.Join(r.stmts, "; ")
+}
+
+// recordingConnector 只记录语句、不连接真实数据库的驱动
+type recordingConnector struct{ rec *recorder }
+
+func (c recordingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return recordingConn(c), nil
+}
+
+func (c recordingConnector) Driver() driver.Driver { return nil }
+
+type recordingConn struct{ rec *recorder }
+
+func (c recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c recordingConn) Close() error { return nil }
+
+func (c recordingConn) Begin() (driver.Tx, error) {
+	c.rec.add("BEGIN")
+	return recordingTx(c), nil
+}
+
+func (c recordingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.rec.add(query)
+	return driver.RowsAffected(1), nil
+}
+
+type recordingTx struct{ rec *recorder }
+
+func (t recordingTx) Commit() error {
+	t.rec.add("COMMIT")
+	return nil
+}
+
+func (t recordingTx) Rollback() error {
+	t.rec.add("ROLLBACK")
+	return nil
+}
+
+func recordingDB(t *testing.T) (*gorm.DB, *recorder) {
+	t.Helper()
+	rec := &recorder{}
+	sqlDB := sql.OpenDB(recordingConnector{rec: rec})
+	sqlDB.SetMaxOpenConns(1)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("Failed to open recording database: %v", err)
+	}
+	return db, rec
+}
+
+func TestTxManagerNestedRollbackToSavepoint(t *testing.T) {
+	db, rec := recordingDB(t)
+	m := NewTxManager(db)
+	innerErr := errors.New("inner failed")
+
+	err := m.Transaction(context.Background(), func(ctx context.Context) error {
+		if !m.InTransaction(ctx) {
+			t.Error("expected context to carry the transaction")
+		}
+		Conn(ctx, db).Exec("UPDATE a")
+
+		// 内层失败只回滚到保存点，外层忽略错误继续提交
+		if err := m.Transaction(ctx, func(ctx context.Context) error {
+			Conn(ctx, db).Exec("UPDATE b")
+			return innerErr
+		}); !errors.Is(err, innerErr) {
+			t.Errorf("nested error = %v, want %v", err, innerErr)
+		}
+
+		return m.Transaction(ctx, func(ctx context.Context) error {
+			Conn(ctx, db).Exec("UPDATE c")
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("Transaction failed: %v", err)
+	}
+
+	want := "BEGIN; UPDATE a; SAVEPOINT sp_1; UPDATE b; ROLLBACK TO SAVEPOINT sp_1; SAVEPOINT sp_1; UPDATE c; COMMIT"
+	if got := rec.String(); got != want {
+		t.Errorf("statements = %s\nwant %s", got, want)
+	}
+}
+
+func TestTxManagerNestedFailureRollsBackOuter(t *testing.T) {
+	db, rec := recordingDB(t)
+	m := NewTxManager(db)
+	innerErr := errors.New("inner failed")
+
+	err := m.Transaction(context.Background(), func(ctx context.Context) error {
+		return m.Transaction(ctx, func(ctx context.Context) error {
+			return m.Transaction(ctx, func(ctx context.Context) error {
+				return innerErr
+			})
+		})
+	})
+	if !errors.Is(err, innerErr) {
+		t.Fatalf("Transaction error = %v, want %v", err, innerErr)
+	}
+
+	want := "BEGIN; SAVEPOINT sp_1; SAVEPOINT sp_2; ROLLBACK TO SAVEPOINT sp_2; ROLLBACK TO SAVEPOINT sp_1; ROLLBACK"
+	if got := rec.String(); got != want {
+		t.Errorf("statements = %s\nwant %s", got, want)
+	}
+}
+
+func TestTxManagerOtherDataSource(t *testing.T) {
+	main, _ := recordingDB(t)
+	other, otherRec := recordingDB(t)
+	m := NewTxManager(main)
+
+	_ = m.Transaction(context.Background(), func(ctx context.Context) error {
+		// 其他数据源不使用本事务
+		if NewTxManager(other).InTransaction(ctx) {
+			t.Error("transaction should not leak to another data source")
+		}
+		Conn(ctx, other).Exec("UPDATE other")
+		return nil
+	})
+
+	if got := otherRec.String(); got != "UPDATE other" {
+		t.Errorf("other data source statements = %s", got)
+	}
+}
@@ -0,0 +1,29 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+)
+
+// OpenConnections 同时占用 n 个连接后归还连接池，使首批请求无需等待建立连接
+// 归还后保留的空闲连接数受 max_idle_conns 限制
+func OpenConnections(ctx context.Context, db *sql.DB, n int) error {
+	conns := make([]*sql.Conn, 0, n)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+
+	for i := 0; i < n; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		conns = append(conns, conn)
+		if err := conn.PingContext(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
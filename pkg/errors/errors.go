@@ -18,6 +18,8 @@ const (
 	ErrorTypeInternal      ErrorType = "internal"
 	ErrorTypeDatabase      ErrorType = "database"
 	ErrorTypeExternal      ErrorType = "external"
+	ErrorTypeRateLimited   ErrorType = "rate_limited"
+	ErrorTypeUnavailable   ErrorType = "unavailable"
 )
 
 // AppError 应用错误结构
@@ -27,6 +29,11 @@ type AppError struct {
 	Code    int       `json:"code"`
 	Err     error    `json:"-"`
 	Details string    `json:"details,omitempty"`
+
+	// MessageID 多语言消息 ID，响应时按请求语言翻译，客户端也可据此自行本地化
+	MessageID string `json:"message_id,omitempty"`
+	// Data 替换翻译消息中 {name} 占位符的参数
+	Data map[string]interface{} `json:"data,omitempty"`
 }
 
 // Error 实现error接口
@@ -82,6 +89,19 @@ func (e *AppError) WithDetails(details string) *AppError {
 	return e
 }
 
+// WithMessageID 设置多语言消息 ID
+func (e *AppError) WithMessageID(id string) *AppError {
+	e.MessageID = id
+	return e
+}
+
+// WithData 返回带有翻译参数的副本，避免修改预定义错误
+func (e *AppError) WithData(data map[string]interface{}) *AppError {
+	clone := *e
+	clone.Data = data
+	return &clone
+}
+
 // getStatusCodeByType 根据错误类型获取HTTP状态码
 func getStatusCodeByType(errorType ErrorType) int {
 	switch errorType {
@@ -95,6 +115,10 @@ func getStatusCodeByType(errorType ErrorType) int {
 		return http.StatusForbidden
 	case ErrorTypeConflict:
 		return http.StatusConflict
+	case ErrorTypeRateLimited:
+		return http.StatusTooManyRequests
+	case ErrorTypeUnavailable:
+		return http.StatusServiceUnavailable
 	case ErrorTypeInternal, ErrorTypeDatabase, ErrorTypeExternal:
 		return http.StatusInternalServerError
 	default:
@@ -104,16 +128,56 @@ func getStatusCodeByType(errorType ErrorType) int {
 
 // 预定义错误
 var (
-	ErrUserNotFound     = New(ErrorTypeNotFound, "用户不存在")
-	ErrUserExists       = New(ErrorTypeConflict, "用户已存在")
-	ErrInvalidPassword  = New(ErrorTypeUnauthorized, "密码错误")
-	ErrAccountDisabled  = New(ErrorTypeForbidden, "账户已禁用")
-	ErrInvalidToken     = New(ErrorTypeUnauthorized, "无效的令牌")
-	ErrTokenExpired     = New(ErrorTypeUnauthorized, "令牌已过期")
-	ErrInvalidInput     = New(ErrorTypeValidation, "输入参数无效")
-	ErrDatabaseError    = New(ErrorTypeDatabase, "数据库错误")
-	ErrExternalService  = New(ErrorTypeExternal, "外部服务错误")
-	ErrInternalError    = New(ErrorTypeInternal, "内部服务器错误")
+	ErrUserNotFound            = New(ErrorTypeNotFound, "用户不存在").WithMessageID("user.not_found")
+	ErrUserExists              = New(ErrorTypeConflict, "用户已存在").WithMessageID("user.exists")
+	ErrInvalidPassword         = New(ErrorTypeUnauthorized, "密码错误").WithMessageID("user.invalid_password")
+	ErrAccountDisabled         = New(ErrorTypeForbidden, "账户已禁用").WithMessageID("user.disabled")
+	ErrInvalidToken            = New(ErrorTypeUnauthorized, "无效的令牌").WithMessageID("auth.invalid_token")
+	ErrTokenExpired            = New(ErrorTypeUnauthorized, "令牌已过期").WithMessageID("auth.token_expired")
+	ErrTokenMissing            = New(ErrorTypeUnauthorized, "未登录").WithMessageID("auth.token_missing")
+	ErrTokenMalformed          = New(ErrorTypeUnauthorized, "令牌格式错误").WithMessageID("auth.token_malformed")
+	ErrTokenSignature          = New(ErrorTypeUnauthorized, "令牌签名无效").WithMessageID("auth.token_signature_invalid")
+	ErrTokenNotYetValid        = New(ErrorTypeUnauthorized, "令牌尚未生效").WithMessageID("auth.token_not_yet_valid")
+	ErrTokenIssuer             = New(ErrorTypeUnauthorized, "令牌签发者无效").WithMessageID("auth.token_invalid_issuer")
+	ErrTokenAudience           = New(ErrorTypeUnauthorized, "令牌不适用于当前服务").WithMessageID("auth.token_invalid_audience")
+	ErrTokenRevoked            = New(ErrorTypeUnauthorized, "令牌已失效，请重新登录").WithMessageID("auth.token_revoked")
+	ErrAuthUnavailable         = New(ErrorTypeUnavailable, "暂时无法校验登录状态，请稍后再试").WithMessageID("auth.unavailable")
+	ErrRefreshTokenInvalid     = New(ErrorTypeUnauthorized, "刷新令牌无效或已过期，请重新登录").WithMessageID("auth.refresh_token_invalid")
+	ErrPermissionDenied        = New(ErrorTypeForbidden, "没有访问权限").WithMessageID("auth.permission_denied")
+	ErrInvalidInput            = New(ErrorTypeValidation, "输入参数无效").WithMessageID("common.invalid_input")
+	ErrDatabaseError           = New(ErrorTypeDatabase, "数据库错误").WithMessageID("common.database_error")
+	ErrExternalService         = New(ErrorTypeExternal, "外部服务错误").WithMessageID("common.external_service")
+	ErrInternalError           = New(ErrorTypeInternal, "内部服务器错误").WithMessageID("common.internal_error")
+	ErrTaskNotFound            = New(ErrorTypeNotFound, "任务不存在").WithMessageID("task.not_found")
+	ErrUploadNotFound          = New(ErrorTypeNotFound, "上传会话不存在或已过期").WithMessageID("upload.not_found")
+	ErrUploadQuotaExceeded     = New(ErrorTypeRateLimited, "未完成的上传过多，请完成或取消后再试").WithMessageID("upload.quota_exceeded")
+	ErrUserReserved            = New(ErrorTypeConflict, "用户名或邮箱已被占用").WithMessageID("user.reserved")
+	ErrReindexRunning          = New(ErrorTypeConflict, "索引重建正在进行").WithMessageID("search.reindex_running")
+	ErrUnknownIndex            = New(ErrorTypeNotFound, "搜索索引不存在").WithMessageID("search.unknown_index")
+	ErrDeletionNotRequested    = New(ErrorTypeNotFound, "未申请删除账户").WithMessageID("user.deletion_not_requested")
+	ErrEmailChangeNotRequested = New(ErrorTypeNotFound, "未申请修改邮箱").WithMessageID("user.email_change_not_requested")
+	ErrEmailChangeTokenInvalid = New(ErrorTypeValidation, "邮箱确认令牌无效").WithMessageID("user.email_change_token_invalid")
+	ErrEmailChangeExpired      = New(ErrorTypeValidation, "邮箱确认令牌已过期，请重新修改邮箱").WithMessageID("user.email_change_expired")
+	ErrExportUnavailable       = New(ErrorTypeInternal, "未配置签名下载，无法导出数据").WithMessageID("privacy.export_unavailable")
+	ErrExportInProgress        = New(ErrorTypeRateLimited, "已有导出任务正在进行，请完成后再试").WithMessageID("privacy.export_in_progress")
+	ErrInvalidTimezone         = New(ErrorTypeValidation, "无法识别的时区").WithMessageID("request.invalid_timezone")
+	ErrExchangeNotFound        = New(ErrorTypeNotFound, "交换机不存在").WithMessageID("mq.exchange_not_found")
+	ErrEventTypeNotAllowed     = New(ErrorTypeForbidden, "不允许发布该类型的事件").WithMessageID("event.type_not_allowed")
+	ErrEventRateLimited        = New(ErrorTypeRateLimited, "事件发布过于频繁，请稍后再试").WithMessageID("event.rate_limited")
+	ErrPasswordResetRequired   = New(ErrorTypeForbidden, "需要重置密码，请使用邮件中的链接设置新密码").WithMessageID("user.password_reset_required")
+	ErrResetNotRequired        = New(ErrorTypeNotFound, "未要求重置密码").WithMessageID("user.password_reset_not_required")
+	ErrResetTokenInvalid       = New(ErrorTypeValidation, "密码重置令牌无效").WithMessageID("user.password_reset_token_invalid")
+	ErrResetTokenExpired       = New(ErrorTypeValidation, "密码重置令牌已过期，请联系管理员重新发起").WithMessageID("user.password_reset_expired")
+	ErrAdminSelfAction         = New(ErrorTypeForbidden, "不能对自己的账户执行该操作").WithMessageID("admin.self_action")
+	ErrPasswordTooShort        = New(ErrorTypeValidation, "密码长度不足").WithMessageID("user.password_too_short")
+	ErrPasswordCommon          = New(ErrorTypeValidation, "密码过于常见，请更换").WithMessageID("user.password_common")
+	ErrPasswordWeak            = New(ErrorTypeValidation, "密码强度不足，请混合使用大小写字母、数字和符号").WithMessageID("user.password_weak")
+	ErrPasswordHasIdentity     = New(ErrorTypeValidation, "密码不能包含用户名或邮箱").WithMessageID("user.password_has_identity")
+	ErrPasswordBreached        = New(ErrorTypeValidation, "该密码已出现在公开泄露的数据中，请更换").WithMessageID("user.password_breached")
+	ErrIdentityNotFound        = New(ErrorTypeNotFound, "外部账号未关联").WithMessageID("identity.not_found")
+	ErrIdentityLinked          = New(ErrorTypeConflict, "该外部账号已关联其他用户").WithMessageID("identity.linked_elsewhere")
+	ErrIdentityProviderLinked  = New(ErrorTypeConflict, "已关联该平台的其他账号，请先解除关联").WithMessageID("identity.provider_linked")
+	ErrIdentityEmailConflict   = New(ErrorTypeConflict, "该邮箱已注册，请登录后在账户中关联外部账号").WithMessageID("identity.email_conflict")
 )
 
 // 便利函数
@@ -0,0 +1,131 @@
+package fault
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+)
+
+// defaultErrorStatus 规则未设置状态码时返回的错误
+const defaultErrorStatus = http.StatusServiceUnavailable
+
+// Decision 单个请求的故障注入结果
+type Decision struct {
+	Delay  time.Duration
+	Status int  // 非 0 时返回该状态码
+	Drop   bool // 不返回响应直接断开连接
+}
+
+// Injector 故障注入规则集，规则可在运行时整体替换
+type Injector struct {
+	mu    sync.RWMutex
+	rules []config.FaultRule
+	rand  func() float64
+}
+
+// New 创建故障注入器，未启用或运行在生产环境时返回 nil
+func New(cfg *config.Config) (*Injector, error) {
+	if !cfg.Faults.Enabled || cfg.App.IsProduction() {
+		return nil, nil
+	}
+
+	i := &Injector{rand: rand.Float64}
+	if err := i.SetRules(cfg.Faults.Rules); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// Rules 返回当前规则
+func (i *Injector) Rules() []config.FaultRule {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return append([]config.FaultRule{}, i.rules...)
+}
+
+// SetRules 校验并整体替换规则，传入空列表时清除所有故障
+func (i *Injector) SetRules(rules []config.FaultRule) error {
+	for idx, rule := range rules {
+		if err := validate(rule); err != nil {
+			return fmt.Errorf("rule %d: %w", idx, err)
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.rules = append([]config.FaultRule{}, rules...)
+	return nil
+}
+
+// Decide 按第一条匹配的规则决定当前请求的故障，未命中任何规则时返回 false
+func (i *Injector) Decide(method, fullPath string) (Decision, bool) {
+	if i == nil || fullPath == "" {
+		return Decision{}, false
+	}
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, rule := range i.rules {
+		if !matches(rule, method, fullPath) {
+			continue
+		}
+
+		decision := Decision{Delay: rule.Latency}
+		if rule.Jitter > 0 {
+			decision.Delay += time.Duration(i.rand() * float64(rule.Jitter))
+		}
+		switch {
+		case rule.DropRate > 0 && i.rand() < rule.DropRate:
+			decision.Drop = true
+		case rule.ErrorRate > 0 && i.rand() < rule.ErrorRate:
+			decision.Status = rule.ErrorStatus
+			if decision.Status == 0 {
+				decision.Status = defaultErrorStatus
+			}
+		}
+		return decision, true
+	}
+	return Decision{}, false
+}
+
+// validate 校验单条规则
+func validate(rule config.FaultRule) error {
+	switch {
+	case rule.Path == "":
+		return fmt.Errorf("path is required")
+	case rule.Latency < 0 || rule.Jitter < 0:
+		return fmt.Errorf("latency and jitter must not be negative")
+	case rule.ErrorRate < 0 || rule.ErrorRate > 1:
+		return fmt.Errorf("error_rate must be between 0 and 1")
+	case rule.DropRate < 0 || rule.DropRate > 1:
+		return fmt.Errorf("drop_rate must be between 0 and 1")
+	case rule.ErrorStatus != 0 && (rule.ErrorStatus < 400 || rule.ErrorStatus > 599):
+		return fmt.Errorf("error_status must be a 4xx or 5xx status code")
+	}
+	return nil
+}
+
+// matches 按 Gin 路由模板和方法匹配，模板以 /* 结尾时按前缀匹配
+func matches(rule config.FaultRule, method, fullPath string) bool {
+	if len(rule.Methods) > 0 {
+		found := false
+		for _, m := range rule.Methods {
+			if strings.EqualFold(m, method) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if prefix, ok := strings.CutSuffix(rule.Path, "*"); ok && strings.HasSuffix(prefix, "/") {
+		return strings.HasPrefix(fullPath, prefix)
+	}
+	return fullPath == rule.Path
+}
@@ -0,0 +1,88 @@
+package fault
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+)
+
+func newTestInjector(t *testing.T, roll float64, rules ...config.FaultRule) *Injector {
+	t.Helper()
+	i := &Injector{rand: func() float64 { return roll }}
+	if err := i.SetRules(rules); err != nil {
+		t.Fatalf("SetRules failed: %v", err)
+	}
+	return i
+}
+
+func TestDecide(t *testing.T) {
+	i := newTestInjector(t, 0.5,
+		config.FaultRule{Path: "/api/v1/users/:id", Methods: []string{"get"}, Latency: 100 * time.Millisecond, Jitter: 20 * time.Millisecond},
+		config.FaultRule{Path: "/api/v1/users/*", ErrorRate: 0.6},
+		config.FaultRule{Path: "/api/v1/tasks/*", DropRate: 0.6, ErrorRate: 1, ErrorStatus: http.StatusBadGateway},
+		config.FaultRule{Path: "/api/v1/search/*", ErrorRate: 0.4},
+	)
+
+	for _, tc := range []struct {
+		method, path string
+		want         Decision
+		matched      bool
+	}{
+		{"GET", "/api/v1/users/:id", Decision{Delay: 110 * time.Millisecond}, true},
+		{"PUT", "/api/v1/users/:id", Decision{Status: http.StatusServiceUnavailable}, true},
+		{"GET", "/api/v1/tasks/:id", Decision{Drop: true}, true},
+		{"GET", "/api/v1/search/users", Decision{}, true},
+		{"GET", "/health", Decision{}, false},
+		{"GET", "", Decision{}, false},
+	} {
+		got, matched := i.Decide(tc.method, tc.path)
+		if got != tc.want || matched != tc.matched {
+			t.Errorf("Decide(%s %s) = %+v, %v; want %+v, %v", tc.method, tc.path, got, matched, tc.want, tc.matched)
+		}
+	}
+}
+
+func TestSetRulesValidates(t *testing.T) {
+	i := newTestInjector(t, 0, config.FaultRule{Path: "/api/v1/*", ErrorRate: 1})
+
+	for _, rule := range []config.FaultRule{
+		{},
+		{Path: "/a", ErrorRate: 1.5},
+		{Path: "/a", DropRate: -0.1},
+		{Path: "/a", Latency: -time.Second},
+		{Path: "/a", ErrorStatus: 200},
+	} {
+		if err := i.SetRules([]config.FaultRule{rule}); err == nil {
+			t.Errorf("SetRules(%+v) should fail", rule)
+		}
+	}
+	if len(i.Rules()) != 1 {
+		t.Error("invalid rules should not replace existing rules")
+	}
+
+	if err := i.SetRules(nil); err != nil || len(i.Rules()) != 0 {
+		t.Errorf("clearing rules = %v, %v", i.Rules(), err)
+	}
+}
+
+func TestNewNeverEnabledInProduction(t *testing.T) {
+	cfg := &config.Config{
+		App:    config.App{Env: "prod"},
+		Faults: config.FaultInjection{Enabled: true},
+	}
+	if i, err := New(cfg); i != nil || err != nil {
+		t.Errorf("production injector = %v, %v", i, err)
+	}
+
+	cfg.App.Env = "staging"
+	if i, err := New(cfg); i == nil || err != nil {
+		t.Errorf("staging injector = %v, %v", i, err)
+	}
+
+	var nilInjector *Injector
+	if _, matched := nilInjector.Decide("GET", "/api/v1/users"); matched {
+		t.Error("nil injector should not match")
+	}
+}
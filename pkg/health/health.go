@@ -0,0 +1,179 @@
+// Package health 依赖健康检查注册表，汇总数据库、Redis、RabbitMQ 等依赖的状态
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Level 检查的重要程度
+type Level string
+
+const (
+	// Critical 失败时整体状态为 unhealthy
+	Critical Level = "critical"
+	// Optional 失败时整体状态为 degraded
+	Optional Level = "optional"
+)
+
+// 整体状态
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+)
+
+// 单项检查状态
+const (
+	CheckUp   = "up"
+	CheckDown = "down"
+)
+
+// defaultTimeout 未指定超时时单项检查的超时时间
+const defaultTimeout = 2 * time.Second
+
+// CheckFunc 检查函数，返回错误表示依赖不可用
+type CheckFunc func(ctx context.Context) error
+
+// check 已注册的检查
+type check struct {
+	name    string
+	level   Level
+	timeout time.Duration
+	fn      CheckFunc
+}
+
+// CheckResult 单项检查结果
+type CheckResult struct {
+	Status    string  `json:"status"`
+	Level     Level   `json:"level"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report 健康检查报告
+type Report struct {
+	Status    string                 `json:"status"`
+	Checks    map[string]CheckResult `json:"checks"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+// Registry 健康检查注册表，可被多个 goroutine 并发使用
+type Registry struct {
+	mu     sync.RWMutex
+	checks []check
+}
+
+// NewRegistry 创建健康检查注册表
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register 注册检查，timeout 为 0 时使用默认超时；同名检查会被替换
+func (r *Registry) Register(name string, level Level, timeout time.Duration, fn CheckFunc) {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := check{name: name, level: level, timeout: timeout, fn: fn}
+	for i := range r.checks {
+		if r.checks[i].name == name {
+			r.checks[i] = c
+			return
+		}
+	}
+	r.checks = append(r.checks, c)
+}
+
+// Names 返回已注册的检查名称
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, len(r.checks))
+	for i, c := range r.checks {
+		names[i] = c.name
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check 并发执行所有检查并汇总状态
+// 任一 critical 检查失败时为 unhealthy，仅 optional 检查失败时为 degraded
+func (r *Registry) Check(ctx context.Context) *Report {
+	r.mu.RLock()
+	checks := make([]check, len(r.checks))
+	copy(checks, r.checks)
+	r.mu.RUnlock()
+
+	results := make([]CheckResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := &Report{
+		Status:    StatusHealthy,
+		Checks:    make(map[string]CheckResult, len(checks)),
+		CheckedAt: time.Now(),
+	}
+	for i, c := range checks {
+		result := results[i]
+		report.Checks[c.name] = result
+		if result.Status == CheckUp {
+			continue
+		}
+		if c.level == Critical {
+			report.Status = StatusUnhealthy
+		} else if report.Status == StatusHealthy {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// run 在超时内执行单项检查，检查函数未响应 context 取消时同样按超时处理
+func run(ctx context.Context, c check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- fmt.Errorf("check panicked: %v", recovered)
+			}
+		}()
+		done <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", c.timeout)
+	}
+
+	result := CheckResult{
+		Status:    CheckUp,
+		Level:     c.level,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = CheckDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,59 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryStatus(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	fail := func(context.Context) error { return errors.New("connection refused") }
+	hang := func(ctx context.Context) error { time.Sleep(time.Second); return nil }
+
+	tests := []struct {
+		name     string
+		register func(r *Registry)
+		status   string
+	}{
+		{"all up", func(r *Registry) {
+			r.Register("database", Critical, 0, ok)
+			r.Register("rabbitmq", Optional, 0, ok)
+		}, StatusHealthy},
+		{"optional down", func(r *Registry) {
+			r.Register("database", Critical, 0, ok)
+			r.Register("rabbitmq", Optional, 0, fail)
+		}, StatusDegraded},
+		{"critical down", func(r *Registry) {
+			r.Register("database", Critical, 0, fail)
+			r.Register("rabbitmq", Optional, 0, fail)
+		}, StatusUnhealthy},
+		{"critical timeout", func(r *Registry) {
+			r.Register("redis", Critical, 10*time.Millisecond, hang)
+		}, StatusUnhealthy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry()
+			tt.register(r)
+
+			report := r.Check(context.Background())
+			if report.Status != tt.status {
+				t.Fatalf("expected %s, got %s: %+v", tt.status, report.Status, report.Checks)
+			}
+		})
+	}
+}
+
+func TestRegistryReplacesCheck(t *testing.T) {
+	r := NewRegistry()
+	r.Register("redis", Critical, 0, func(context.Context) error { return errors.New("down") })
+	r.Register("redis", Optional, 0, func(context.Context) error { return nil })
+
+	report := r.Check(context.Background())
+	if len(report.Checks) != 1 || report.Checks["redis"].Status != CheckUp || report.Checks["redis"].Level != Optional {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
@@ -0,0 +1,46 @@
+package i18n
+
+import (
+	"context"
+
+	"golang.org/x/text/language"
+)
+
+type contextKey struct{}
+
+type localizer struct {
+	i18n *I18n
+	lang language.Tag
+}
+
+// NewContext 将 I18n 实例和请求语言写入 context，由 i18n 中间件调用
+func NewContext(ctx context.Context, i *I18n, lang language.Tag) context.Context {
+	return context.WithValue(ctx, contextKey{}, localizer{i18n: i, lang: lang})
+}
+
+// FromContext 从 context 中获取 I18n 实例和请求语言，未经过 i18n 中间件时返回 false
+func FromContext(ctx context.Context) (*I18n, language.Tag, bool) {
+	l, ok := ctx.Value(contextKey{}).(localizer)
+	if !ok {
+		return nil, language.Und, false
+	}
+	return l.i18n, l.lang, true
+}
+
+// LanguageFromContext 获取请求语言，不存在时返回 fallback
+func LanguageFromContext(ctx context.Context, fallback language.Tag) language.Tag {
+	if _, lang, ok := FromContext(ctx); ok {
+		return lang
+	}
+	return fallback
+}
+
+// Localize 使用 context 中的 I18n 实例和请求语言翻译 id
+// 未经过 i18n 中间件或消息不存在时返回 false，调用方应回退到原始消息
+func Localize(ctx context.Context, id string, data map[string]interface{}) (string, bool) {
+	i, lang, ok := FromContext(ctx)
+	if !ok || id == "" {
+		return "", false
+	}
+	return i.Localize(lang, id, data)
+}
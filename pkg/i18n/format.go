@@ -0,0 +1,73 @@
+package i18n
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// dateLayouts 各语言的日期格式，x/text 不提供日期格式数据，未列出的语言使用 ISO 8601
+var dateLayouts = map[language.Base]string{
+	language.MustParseBase("zh"): "2006年1月2日",
+	language.MustParseBase("ja"): "2006年1月2日",
+	language.MustParseBase("en"): "Jan 2, 2006",
+	language.MustParseBase("de"): "02.01.2006",
+	language.MustParseBase("fr"): "02/01/2006",
+}
+
+// defaultDateLayout 未配置日期格式的语言使用的格式
+const defaultDateLayout = "2006-01-02"
+
+// FormatNumber 按请求语言格式化数字，包括千分位和小数点符号
+func (i *I18n) FormatNumber(ctx context.Context, v interface{}) string {
+	return i.printer(ctx).Sprint(number.Decimal(v))
+}
+
+// FormatCurrency 按请求语言格式化金额，code 为 ISO 4217 货币代码，小数位数由货币决定
+// code 无法识别时按普通数字格式化
+func (i *I18n) FormatCurrency(ctx context.Context, amount float64, code string) string {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return i.FormatNumber(ctx, amount)
+	}
+	return i.printer(ctx).Sprint(currency.Symbol(unit.Amount(amount)))
+}
+
+// FormatDate 按请求语言格式化日期，请求指定了时区时先转换到该时区
+func (i *I18n) FormatDate(ctx context.Context, t time.Time) string {
+	base, _ := i.language(ctx).Base()
+	layout, ok := dateLayouts[base]
+	if !ok {
+		layout = defaultDateLayout
+	}
+	return InLocation(ctx, t).Format(layout)
+}
+
+// Funcs 返回绑定到 ctx 请求语言的模板函数，通过 template.WithContextFuncs 注册
+func (i *I18n) Funcs(ctx context.Context) map[string]interface{} {
+	return map[string]interface{}{
+		"formatNumber": func(v interface{}) string {
+			return i.FormatNumber(ctx, v)
+		},
+		"formatCurrency": func(amount float64, code string) string {
+			return i.FormatCurrency(ctx, amount, code)
+		},
+		"formatDate": func(t time.Time) string {
+			return i.FormatDate(ctx, t)
+		},
+	}
+}
+
+// language 获取请求语言，未经过 i18n 中间件时使用默认语言
+func (i *I18n) language(ctx context.Context) language.Tag {
+	return LanguageFromContext(ctx, i.fallback)
+}
+
+// printer 返回请求语言的格式化器
+func (i *I18n) printer(ctx context.Context) *message.Printer {
+	return message.NewPrinter(i.language(ctx))
+}
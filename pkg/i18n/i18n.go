@@ -0,0 +1,222 @@
+package i18n
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// extension 消息文件扩展名，文件名为语言标签，如 zh.json、en.json
+const extension = ".json"
+
+// I18n 多语言消息目录，按 Accept-Language 匹配语言
+// 消息文件内容为 message id 到消息的映射，消息中的 {name} 使用 data 中的同名字段替换
+type I18n struct {
+	fallback  language.Tag
+	tags      []language.Tag
+	matcher   language.Matcher
+	messages  map[language.Tag]map[string]string
+	fallbacks map[language.Tag][]language.Tag
+}
+
+// Option I18n 可选配置
+type Option func(*options)
+
+type options struct {
+	supported []string
+	fallbacks map[string][]string
+}
+
+// WithSupportedLanguages 限定可匹配的语言，默认为全部消息文件的语言
+// 没有消息文件的语言也可以匹配，消息按回退链查找，日期、数字仍按该语言格式化
+func WithSupportedLanguages(langs ...string) Option {
+	return func(o *options) {
+		o.supported = langs
+	}
+}
+
+// WithFallbacks 设置语言的回退链，如 zh-TW: [zh-HK, zh]
+// 请求语言不受支持时按回退链选择第一个支持的语言；翻译时当前语言缺少的消息按回退链查找，最后使用默认语言
+func WithFallbacks(fallbacks map[string][]string) Option {
+	return func(o *options) {
+		o.fallbacks = fallbacks
+	}
+}
+
+// New 从 fsys 根目录加载消息文件，fallback 为请求语言无法匹配时使用的语言
+func New(fsys fs.FS, fallback string, opts ...Option) (*I18n, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	fallbackTag, err := language.Parse(fallback)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fallback language %q: %w", fallback, err)
+	}
+
+	files, err := fs.Glob(fsys, "*"+extension)
+	if err != nil {
+		return nil, err
+	}
+
+	i := &I18n{
+		fallback:  fallbackTag,
+		tags:      []language.Tag{fallbackTag},
+		messages:  make(map[language.Tag]map[string]string, len(files)),
+		fallbacks: make(map[language.Tag][]language.Tag, len(o.fallbacks)),
+	}
+	for _, file := range files {
+		tag, err := language.Parse(strings.TrimSuffix(path.Base(file), extension))
+		if err != nil {
+			return nil, fmt.Errorf("invalid message file %s: %w", file, err)
+		}
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read message file %s: %w", file, err)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(content, &messages); err != nil {
+			return nil, fmt.Errorf("failed to parse message file %s: %w", file, err)
+		}
+
+		i.messages[tag] = messages
+		if tag != fallbackTag && len(o.supported) == 0 {
+			i.tags = append(i.tags, tag)
+		}
+	}
+
+	for _, lang := range o.supported {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, fmt.Errorf("invalid supported language %q: %w", lang, err)
+		}
+		if !i.supports(tag) {
+			i.tags = append(i.tags, tag)
+		}
+	}
+
+	for lang, chain := range o.fallbacks {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fallback chain language %q: %w", lang, err)
+		}
+		for _, next := range chain {
+			nextTag, err := language.Parse(next)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fallback language %q for %s: %w", next, lang, err)
+			}
+			i.fallbacks[tag] = append(i.fallbacks[tag], nextTag)
+		}
+	}
+
+	// 第一个语言作为匹配失败时的默认值
+	i.matcher = language.NewMatcher(i.tags)
+	return i, nil
+}
+
+// Fallback 返回默认语言
+func (i *I18n) Fallback() language.Tag {
+	return i.fallback
+}
+
+// Supported 返回可匹配的语言，第一个为默认语言
+func (i *I18n) Supported() []language.Tag {
+	return append([]language.Tag(nil), i.tags...)
+}
+
+// Match 根据 Accept-Language 请求头匹配支持的语言，无法匹配时返回默认语言
+// 按权重从高到低逐个尝试请求语言：完全或高度匹配（如 en-GB→en、pt-BR→pt）时直接使用，
+// 否则按该语言的回退链选择，仍无法选择时接受地区回退（如 zh-TW→zh），都失败时尝试下一个请求语言
+func (i *I18n) Match(acceptLanguage string) language.Tag {
+	prefs, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return i.fallback
+	}
+	for _, pref := range prefs {
+		_, index, confidence := i.matcher.Match(pref)
+		if confidence >= language.High {
+			return i.tags[index]
+		}
+		for _, next := range i.chain(pref) {
+			if i.supports(next) {
+				return next
+			}
+		}
+		if confidence == language.Low {
+			return i.tags[index]
+		}
+	}
+	return i.fallback
+}
+
+// supports 判断 tag 是否为支持的语言
+func (i *I18n) supports(tag language.Tag) bool {
+	for _, supported := range i.tags {
+		if supported == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// chain 返回 lang 的回退链，lang 本身没有配置时依次查找其父语言（zh-Hant-TW→zh-Hant→zh）的配置
+func (i *I18n) chain(lang language.Tag) []language.Tag {
+	for tag := lang; !tag.IsRoot(); tag = tag.Parent() {
+		if chain, ok := i.fallbacks[tag]; ok {
+			return chain
+		}
+	}
+	return nil
+}
+
+// Localize 返回 id 在指定语言下的消息，该语言缺少时依次查找回退链、父语言（pt-BR→pt）和默认语言，都不存在时返回 false
+func (i *I18n) Localize(lang language.Tag, id string, data map[string]interface{}) (string, bool) {
+	if message, ok := i.messages[lang][id]; ok {
+		return format(message, data), true
+	}
+	for _, next := range i.chain(lang) {
+		if message, ok := i.messages[next][id]; ok {
+			return format(message, data), true
+		}
+	}
+	for tag := lang.Parent(); !tag.IsRoot(); tag = tag.Parent() {
+		if message, ok := i.messages[tag][id]; ok {
+			return format(message, data), true
+		}
+	}
+	if message, ok := i.messages[i.fallback][id]; ok {
+		return format(message, data), true
+	}
+	return "", false
+}
+
+// T 按 ctx 中的请求语言翻译 id，签名与 template.Translator 一致
+// 消息不存在时返回 id，args 非空时按 fmt.Sprintf 格式化
+func (i *I18n) T(ctx context.Context, id string, args ...interface{}) string {
+	message, ok := i.Localize(i.language(ctx), id, nil)
+	if !ok {
+		message = id
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// format 使用 data 替换消息中的 {name} 占位符
+func format(message string, data map[string]interface{}) string {
+	if len(data) == 0 || !strings.Contains(message, "{") {
+		return message
+	}
+	pairs := make([]string, 0, len(data)*2)
+	for name, value := range data {
+		pairs = append(pairs, "{"+name+"}", fmt.Sprint(value))
+	}
+	return strings.NewReplacer(pairs...).Replace(message)
+}
@@ -0,0 +1,189 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+func newTestI18n(t *testing.T) *I18n {
+	t.Helper()
+	i, err := New(fstest.MapFS{
+		"zh.json": {Data: []byte(`{"user.not_found": "用户不存在", "task.limit": "最多 {max} 个任务"}`)},
+		"en.json": {Data: []byte(`{"user.not_found": "User not found"}`)},
+	}, "zh")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return i
+}
+
+func TestMatch(t *testing.T) {
+	i := newTestI18n(t)
+	for header, want := range map[string]language.Tag{
+		"en-US,en;q=0.9": language.English,
+		"zh-CN":          language.Chinese,
+		"fr":             language.Chinese,
+		"":               language.Chinese,
+	} {
+		if got := i.Match(header); got != want {
+			t.Errorf("Match(%q) = %s, want %s", header, got, want)
+		}
+	}
+}
+
+func TestMatchFallbacks(t *testing.T) {
+	i, err := New(fstest.MapFS{
+		"zh.json":    {Data: []byte(`{"hello": "你好"}`)},
+		"en.json":    {Data: []byte(`{"hello": "Hello"}`)},
+		"pt.json":    {Data: []byte(`{"hello": "Olá", "bye": "Tchau"}`)},
+		"pt-BR.json": {Data: []byte(`{"hello": "Oi"}`)},
+	}, "en", WithSupportedLanguages("zh", "pt", "pt-BR", "de"), WithFallbacks(map[string][]string{
+		"es":    {"fr", "pt"},
+		"zh-tw": {"zh-hk", "zh"},
+	}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for header, want := range map[string]string{
+		"zh-TW":                "zh",    // 地区回退
+		"zh-Hant-TW":           "zh",    // 地区回退
+		"pt-BR":                "pt-BR", // 完全匹配
+		"pt-PT":                "pt",    // 地区回退
+		"es":                   "pt",    // 回退链中第一个支持的语言
+		"es-MX":                "pt",    // 父语言的回退链
+		"de-AT":                "de",    // 没有消息文件的支持语言
+		"fr,pt;q=0.8":          "pt",    // 第一个无法匹配时尝试下一个
+		"de;q=0.5,zh;q=0.9":    "zh",    // 按权重
+		"zh;q=0,pt;q=0.5":      "pt",    // q=0 表示不接受
+		"ja":                   "en",    // 无法匹配时使用默认语言
+		"invalid language!!!!": "en",
+	} {
+		if got := i.Match(header); got.String() != want {
+			t.Errorf("Match(%q) = %s, want %s", header, got, want)
+		}
+	}
+
+	// 消息文件中有、但不在支持列表中的语言不匹配
+	if got := i.Match("en-GB"); got != language.English {
+		t.Errorf("Match(en-GB) = %s, want default language", got)
+	}
+	if _, err := New(fstest.MapFS{}, "en", WithFallbacks(map[string][]string{"zh": {"??"}})); err == nil {
+		t.Error("New with invalid fallback language should fail")
+	}
+}
+
+func TestLocalizeFallbacks(t *testing.T) {
+	i, err := New(fstest.MapFS{
+		"zh.json":    {Data: []byte(`{"hello": "你好", "bye": "再见"}`)},
+		"zh-HK.json": {Data: []byte(`{"hello": "哈囉"}`)},
+		"en.json":    {Data: []byte(`{"hello": "Hello", "bye": "Bye", "thanks": "Thanks"}`)},
+		"pt.json":    {Data: []byte(`{"hello": "Olá", "bye": "Tchau"}`)},
+		"pt-BR.json": {Data: []byte(`{"hello": "Oi"}`)},
+	}, "en", WithFallbacks(map[string][]string{"zh-TW": {"zh-HK", "zh"}}))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	for _, tc := range []struct{ lang, id, want string }{
+		{"zh-TW", "hello", "哈囉"},      // 回退链第一个
+		{"zh-TW", "bye", "再见"},        // 回退链第二个
+		{"zh-TW", "thanks", "Thanks"}, // 默认语言
+		{"pt-BR", "hello", "Oi"},
+		{"pt-BR", "bye", "Tchau"}, // 父语言
+	} {
+		if got, _ := i.Localize(language.MustParse(tc.lang), tc.id, nil); got != tc.want {
+			t.Errorf("Localize(%s, %s) = %q, want %q", tc.lang, tc.id, got, tc.want)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	i := newTestI18n(t)
+
+	if msg, _ := i.Localize(language.English, "user.not_found", nil); msg != "User not found" {
+		t.Fatalf("unexpected message: %q", msg)
+	}
+	// 英文缺少翻译时回退到默认语言，并替换占位符
+	if msg, _ := i.Localize(language.English, "task.limit", map[string]interface{}{"max": 10}); msg != "最多 10 个任务" {
+		t.Fatalf("unexpected fallback message: %q", msg)
+	}
+	if _, ok := i.Localize(language.English, "missing", nil); ok {
+		t.Fatal("expected missing message")
+	}
+
+	ctx := NewContext(context.Background(), i, language.English)
+	if msg, ok := Localize(ctx, "user.not_found", nil); !ok || msg != "User not found" {
+		t.Fatalf("unexpected context message: %q", msg)
+	}
+	if _, ok := Localize(context.Background(), "user.not_found", nil); ok {
+		t.Fatal("expected no localization without i18n context")
+	}
+	if msg := i.T(ctx, "missing"); msg != "missing" {
+		t.Fatalf("T should return id for missing message, got %q", msg)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	i := newTestI18n(t)
+	zh := NewContext(context.Background(), i, language.Chinese)
+	en := NewContext(context.Background(), i, language.English)
+	de := NewContext(context.Background(), i, language.German)
+	date := time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC)
+
+	for _, tc := range []struct{ got, want string }{
+		{i.FormatNumber(en, 1234567.5), "1,234,567.5"},
+		{i.FormatNumber(de, 1234567.5), "1.234.567,5"},
+		{i.FormatCurrency(en, 1234.5, "USD"), "$ 1,234.50"},
+		{i.FormatCurrency(en, 1234.5, "invalid"), "1,234.5"},
+		{i.FormatDate(zh, date), "2024年3月5日"},
+		{i.FormatDate(en, date), "Mar 5, 2024"},
+		// 未经过中间件时使用默认语言
+		{i.FormatDate(context.Background(), date), "2024年3月5日"},
+	} {
+		if tc.got != tc.want {
+			t.Errorf("got %q, want %q", tc.got, tc.want)
+		}
+	}
+}
+
+func TestLocale(t *testing.T) {
+	i := newTestI18n(t)
+
+	locale, err := i.Locale("en-GB,en;q=0.9", "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("Locale failed: %v", err)
+	}
+	if locale.Language != language.English || locale.RegionCode() != "GB" {
+		t.Fatalf("unexpected locale: %s %s", locale.Language, locale.RegionCode())
+	}
+
+	ctx := WithLocale(context.Background(), locale)
+	utc := time.Date(2025, 1, 1, 16, 0, 0, 0, time.UTC)
+	if got := InLocation(ctx, utc); got.Hour() != 1 || got.Day() != 2 {
+		t.Fatalf("time not converted to Asia/Tokyo: %v", got)
+	}
+	// 未指定时区时保持原样
+	if got := InLocation(context.Background(), utc); !got.Equal(utc) || got.Location() != time.UTC {
+		t.Fatalf("time should be unchanged: %v", got)
+	}
+
+	// 未指定地区时不推断
+	if locale, _ := i.Locale("zh", ""); locale.RegionCode() != "" || locale.Location != nil {
+		t.Fatalf("unexpected locale: %+v", locale)
+	}
+
+	for _, tz := range []string{"Mars/Olympus", "Local"} {
+		locale, err := i.Locale("en", tz)
+		if err == nil {
+			t.Errorf("expected error for timezone %q", tz)
+		}
+		if locale.Language != language.English {
+			t.Errorf("language should be matched even when timezone is invalid: %s", locale.Language)
+		}
+	}
+}
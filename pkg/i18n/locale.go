@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/text/language"
+)
+
+// TimezoneHeader 请求时区头，取值为 IANA 时区名，如 Asia/Shanghai
+const TimezoneHeader = "X-Timezone"
+
+type localeKey struct{}
+
+// Locale 请求的语言、地区和时区，由 i18n 中间件根据请求头写入 context
+type Locale struct {
+	Language language.Tag    // 匹配到的支持语言
+	Region   language.Region // Accept-Language 首选项中明确指定的地区，未指定时为零值
+	Location *time.Location  // X-Timezone 指定的时区，未指定时为 nil，时间保持原样
+}
+
+// RegionCode 返回 ISO 3166-1 地区代码，未指定地区时返回空字符串
+func (l Locale) RegionCode() string {
+	if l.Region == (language.Region{}) {
+		return ""
+	}
+	return l.Region.String()
+}
+
+// In 将 t 转换到请求时区，未指定时区时原样返回
+func (l Locale) In(t time.Time) time.Time {
+	if l.Location == nil {
+		return t
+	}
+	return t.In(l.Location)
+}
+
+// Locale 根据 Accept-Language 和时区请求头解析请求的 Locale
+// 语言无法匹配时使用默认语言；时区无法识别时返回错误，Locale 中的语言和地区仍然有效
+func (i *I18n) Locale(acceptLanguage, timezone string) (Locale, error) {
+	locale := Locale{Language: i.Match(acceptLanguage)}
+
+	if prefs, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil && len(prefs) > 0 {
+		if region, confidence := prefs[0].Region(); confidence == language.Exact {
+			locale.Region = region
+		}
+	}
+
+	location, err := ParseTimezone(timezone)
+	if err != nil {
+		return locale, err
+	}
+	locale.Location = location
+	return locale, nil
+}
+
+// ParseTimezone 解析 IANA 时区名，为空时返回 nil；不接受依赖服务器环境的 Local
+func ParseTimezone(name string) (*time.Location, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if name == "Local" {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	return location, nil
+}
+
+// WithLocale 将 Locale 写入 context
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFromContext 获取请求的 Locale，未经过 i18n 中间件时返回 false
+func LocaleFromContext(ctx context.Context) (Locale, bool) {
+	locale, ok := ctx.Value(localeKey{}).(Locale)
+	return locale, ok
+}
+
+// InLocation 将 t 转换到请求时区，请求未指定时区时原样返回，供服务层渲染响应中的时间
+func InLocation(ctx context.Context, t time.Time) time.Time {
+	locale, _ := LocaleFromContext(ctx)
+	return locale.In(t)
+}
@@ -0,0 +1,115 @@
+package jwt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jweKeySize A256GCM 的密钥长度
+const jweKeySize = 32
+
+// jweHeader 嵌套 JWT 的 JWE 头，alg=dir 表示直接使用共享密钥作为内容加密密钥
+type jweHeader struct {
+	Alg string `json:"alg"`
+	Enc string `json:"enc"`
+	Cty string `json:"cty"`
+}
+
+// encrypter 以 JWE 紧凑格式（RFC 7516）加密签名后的令牌
+// 格式为 header..iv.ciphertext.tag，dir 模式下加密密钥段为空，头部作为附加认证数据
+type encrypter struct {
+	aead   cipher.AEAD
+	header string
+}
+
+// newEncrypter 从 base64 编码的密钥创建加密器
+func newEncrypter(encodedKey string) (*encrypter, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("jwt.encryption.key must be base64 encoded: %w", err)
+	}
+	if len(key) != jweKeySize {
+		return nil, fmt.Errorf("jwt.encryption.key must be %d bytes, got %d", jweKeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := json.Marshal(jweHeader{Alg: "dir", Enc: "A256GCM", Cty: "JWT"})
+	if err != nil {
+		return nil, err
+	}
+	return &encrypter{aead: aead, header: encodeSegment(header)}, nil
+}
+
+// encrypt 加密已签名的令牌
+func (e *encrypter) encrypt(signed string) (string, error) {
+	iv := make([]byte, e.aead.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return "", err
+	}
+
+	sealed := e.aead.Seal(nil, iv, []byte(signed), []byte(e.header))
+	tagStart := len(sealed) - e.aead.Overhead()
+	return strings.Join([]string{
+		e.header,
+		"",
+		encodeSegment(iv),
+		encodeSegment(sealed[:tagStart]),
+		encodeSegment(sealed[tagStart:]),
+	}, "."), nil
+}
+
+// decrypt 解密 JWE，返回其中已签名的令牌，签名仍需单独校验
+func (e *encrypter) decrypt(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 5 || parts[1] != "" {
+		return "", fmt.Errorf("%w: token is not an encrypted JWE", jwt.ErrTokenMalformed)
+	}
+
+	var header jweHeader
+	if err := decodeJSONSegment(parts[0], &header); err != nil || header.Alg != "dir" || header.Enc != "A256GCM" {
+		return "", fmt.Errorf("%w: unsupported JWE header", jwt.ErrTokenMalformed)
+	}
+
+	iv, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(iv) != e.aead.NonceSize() {
+		return "", fmt.Errorf("%w: invalid JWE iv", jwt.ErrTokenMalformed)
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(parts[3])
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid JWE ciphertext", jwt.ErrTokenMalformed)
+	}
+	tag, err := base64.RawURLEncoding.DecodeString(parts[4])
+	if err != nil || len(tag) != e.aead.Overhead() {
+		return "", fmt.Errorf("%w: invalid JWE tag", jwt.ErrTokenMalformed)
+	}
+
+	plaintext, err := e.aead.Open(nil, iv, append(ciphertext, tag...), []byte(parts[0]))
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to decrypt JWE", jwt.ErrTokenUnverifiable)
+	}
+	return string(plaintext), nil
+}
+
+// decodeJSONSegment 解码 base64url 编码的 JSON
+func decodeJSONSegment(segment string, out interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
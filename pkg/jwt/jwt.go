@@ -1,63 +1,187 @@
 package jwt
 
 import (
-	"time"
+	"errors"
+	"fmt"
+	"slices"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	apperrors "github.com/hedeqiang/skeleton/pkg/errors"
 )
 
+// defaultIssuer 未配置 jwt.issuer 时使用的签发者
+const defaultIssuer = "go-skeleton"
+
 // CustomClaims 定义了自定义的 JWT 声明
 type CustomClaims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
+	UserID   uint     `json:"user_id"`
+	Username string   `json:"username"`
+	Roles    []string `json:"roles,omitempty"`
 	jwt.RegisteredClaims
 }
 
 // JWT 定义了 JWT 工具
 type JWT struct {
-	secret []byte
-	config *config.JWT
+	config     *config.JWT
+	clock      clock.Clock
+	issuer     string
+	method     jwt.SigningMethod
+	signingKey interface{}
+	keyID      string
+	keys       *keySet    // RS256、ES256 的校验公钥
+	encrypter  *encrypter // 未开启加密时为 nil
 }
 
 // NewJWT 创建一个新的 JWT 工具实例
-func NewJWT(cfg *config.Config) *JWT {
-	return &JWT{
-		secret: []byte(cfg.JWT.Secret),
+// 签发时间、过期时间和校验时使用的当前时间都取自 clk；算法未知、密钥无法读取或与算法不匹配时返回错误
+func NewJWT(cfg *config.Config, clk clock.Clock) (*JWT, error) {
+	j := &JWT{
 		config: &cfg.JWT,
+		clock:  clk,
+		issuer: cfg.JWT.Issuer,
+	}
+	if j.issuer == "" {
+		j.issuer = defaultIssuer
+	}
+
+	switch cfg.JWT.Algorithm {
+	case "", config.JWTAlgorithmHS256:
+		j.method = jwt.SigningMethodHS256
+		j.signingKey = []byte(cfg.JWT.Secret)
+	case config.JWTAlgorithmRS256, config.JWTAlgorithmES256:
+		keys, err := loadKeySet(cfg.JWT.Algorithm, cfg.JWT.Keys)
+		if err != nil {
+			return nil, err
+		}
+		signingKey, ok := keys.private[cfg.JWT.KeyID]
+		if !ok {
+			return nil, fmt.Errorf("jwt.key_id %q must refer to a key with private_key_file", cfg.JWT.KeyID)
+		}
+		j.method = jwt.GetSigningMethod(cfg.JWT.Algorithm)
+		j.signingKey = signingKey
+		j.keyID = cfg.JWT.KeyID
+		j.keys = keys
+	default:
+		return nil, fmt.Errorf("unsupported jwt.algorithm %q", cfg.JWT.Algorithm)
+	}
+
+	if cfg.JWT.Encryption.Enabled {
+		enc, err := newEncrypter(cfg.JWT.Encryption.Key)
+		if err != nil {
+			return nil, err
+		}
+		j.encrypter = enc
 	}
+	return j, nil
 }
 
-// GenerateToken 生成一个新的 JWT Token
-func (j *JWT) GenerateToken(userID uint, username string) (string, error) {
+// GenerateToken 生成一个新的 JWT Token，roles 写入 roles 声明供 route_policies 校验
+// 非对称算法在头部写入 kid，开启加密时返回 JWE
+func (j *JWT) GenerateToken(userID uint, username string, roles ...string) (string, error) {
+	now := j.clock.Now()
 	claims := CustomClaims{
 		UserID:   userID,
 		Username: username,
+		Roles:    roles,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(j.config.ExpireDuration)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-			Issuer:    "go-skeleton", // It's better to get this from config as well
+			ExpiresAt: jwt.NewNumericDate(now.Add(j.config.ExpireDuration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    j.issuer,
+			Audience:  j.config.Audience,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(j.secret)
+	token := jwt.NewWithClaims(j.method, claims)
+	if j.keyID != "" {
+		token.Header["kid"] = j.keyID
+	}
+	signed, err := token.SignedString(j.signingKey)
+	if err != nil || j.encrypter == nil {
+		return signed, err
+	}
+	return j.encrypter.encrypt(signed)
 }
 
 // ParseToken 解析并验证一个 JWT Token
+// 开启加密时只接受 JWE；非对称算法按 kid 选择校验公钥
 func (j *JWT) ParseToken(tokenString string) (*CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return j.secret, nil
-	})
+	if j.encrypter != nil {
+		decrypted, err := j.encrypter.decrypt(tokenString)
+		if err != nil {
+			return nil, err
+		}
+		tokenString = decrypted
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, j.verificationKey,
+		jwt.WithTimeFunc(j.clock.Now),
+		jwt.WithValidMethods([]string{j.method.Alg()}),
+		jwt.WithIssuer(j.issuer),
+		jwt.WithLeeway(j.config.Leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*CustomClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*CustomClaims)
+	if !ok || !token.Valid {
+		return nil, jwt.ErrInvalidKey
+	}
+	if j.config.RequireNotBefore && claims.NotBefore == nil {
+		return nil, fmt.Errorf("%w: nbf", jwt.ErrTokenRequiredClaimMissing)
+	}
+	if len(j.config.Audience) > 0 && !slices.ContainsFunc(claims.Audience, func(aud string) bool {
+		return slices.Contains(j.config.Audience, aud)
+	}) {
+		return nil, jwt.ErrTokenInvalidAudience
+	}
+	return claims, nil
+}
+
+// TokenError 将 ParseToken 返回的错误转换为 AppError，客户端可按 message_id 区分过期、格式错误等情况
+func TokenError(err error) *apperrors.AppError {
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return apperrors.ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return apperrors.ErrTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		return apperrors.ErrTokenIssuer
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return apperrors.ErrTokenAudience
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return apperrors.ErrTokenSignature
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return apperrors.ErrTokenMalformed
+	default:
+		return apperrors.ErrInvalidToken
 	}
+}
 
-	return nil, jwt.ErrInvalidKey
+// JWKS 返回校验公钥集合，HS256 没有可公开的密钥，返回 nil
+func (j *JWT) JWKS() *JWKS {
+	if j.keys == nil {
+		return nil
+	}
+	return j.keys.jwks(j.method.Alg())
+}
+
+// verificationKey 返回令牌对应的校验密钥
+func (j *JWT) verificationKey(token *jwt.Token) (interface{}, error) {
+	if j.keys == nil {
+		return j.signingKey, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := j.keys.public[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown kid %q", jwt.ErrTokenUnverifiable, kid)
+	}
+	return key, nil
 }
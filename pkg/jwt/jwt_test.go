@@ -0,0 +1,266 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	apperrors "github.com/hedeqiang/skeleton/pkg/errors"
+)
+
+func TestTokenExpiry(t *testing.T) {
+	clk := clock.Frozen()
+	tokens, err := NewJWT(&config.Config{JWT: config.JWT{Secret: "secret", ExpireDuration: time.Hour}}, clk)
+	if err != nil {
+		t.Fatalf("NewJWT failed: %v", err)
+	}
+
+	token, err := tokens.GenerateToken(1, "alice")
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	claims, err := tokens.ParseToken(token)
+	if err != nil {
+		t.Fatalf("ParseToken failed: %v", err)
+	}
+	if claims.UserID != 1 || !claims.ExpiresAt.Equal(clk.Now().Add(time.Hour)) {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+
+	clk.Advance(time.Hour + time.Second)
+	if _, err := tokens.ParseToken(token); !errors.Is(err, jwt.ErrTokenExpired) {
+		t.Fatalf("expected ErrTokenExpired, got %v", err)
+	}
+}
+
+// writeKey 生成密钥并写入 PEM 文件，返回私钥和公钥文件路径
+func writeKey(t *testing.T, algorithm string) (privateFile, publicFile string) {
+	t.Helper()
+	var signer crypto.Signer
+	var err error
+	if algorithm == config.JWTAlgorithmRS256 {
+		signer, err = rsa.GenerateKey(rand.Reader, 2048)
+	} else {
+		signer, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	}
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		t.Fatalf("Failed to marshal private key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		t.Fatalf("Failed to marshal public key: %v", err)
+	}
+
+	dir := t.TempDir()
+	privateFile = filepath.Join(dir, "private.pem")
+	publicFile = filepath.Join(dir, "public.pem")
+	if err := os.WriteFile(privateFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return privateFile, publicFile
+}
+
+func TestAsymmetricKeyRotation(t *testing.T) {
+	for _, algorithm := range []string{config.JWTAlgorithmRS256, config.JWTAlgorithmES256} {
+		t.Run(algorithm, func(t *testing.T) {
+			oldPrivate, oldPublic := writeKey(t, algorithm)
+			newPrivate, _ := writeKey(t, algorithm)
+			clk := clock.Frozen()
+
+			before, err := NewJWT(&config.Config{JWT: config.JWT{
+				Algorithm: algorithm, ExpireDuration: time.Hour, KeyID: "2024",
+				Keys: []config.JWTKey{{ID: "2024", PrivateKeyFile: oldPrivate}},
+			}}, clk)
+			if err != nil {
+				t.Fatalf("NewJWT failed: %v", err)
+			}
+			oldToken, err := before.GenerateToken(1, "alice")
+			if err != nil {
+				t.Fatalf("GenerateToken failed: %v", err)
+			}
+
+			// 轮换后使用新密钥签发，旧密钥只保留公钥
+			after, err := NewJWT(&config.Config{JWT: config.JWT{
+				Algorithm: algorithm, ExpireDuration: time.Hour, KeyID: "2025",
+				Keys: []config.JWTKey{{ID: "2025", PrivateKeyFile: newPrivate}, {ID: "2024", PublicKeyFile: oldPublic}},
+			}}, clk)
+			if err != nil {
+				t.Fatalf("NewJWT failed: %v", err)
+			}
+			newToken, err := after.GenerateToken(2, "bob")
+			if err != nil {
+				t.Fatalf("GenerateToken failed: %v", err)
+			}
+
+			if claims, err := after.ParseToken(oldToken); err != nil || claims.UserID != 1 {
+				t.Errorf("token signed with retired key: %+v, %v", claims, err)
+			}
+			if claims, err := after.ParseToken(newToken); err != nil || claims.UserID != 2 {
+				t.Errorf("token signed with current key: %+v, %v", claims, err)
+			}
+			if _, err := before.ParseToken(newToken); !errors.Is(err, jwt.ErrTokenUnverifiable) {
+				t.Errorf("unknown kid should be unverifiable, got %v", err)
+			}
+
+			jwks := after.JWKS()
+			if len(jwks.Keys) != 2 || jwks.Keys[0].Kid != "2024" || jwks.Keys[0].Alg != algorithm {
+				t.Errorf("unexpected JWKS: %+v", jwks)
+			}
+		})
+	}
+}
+
+func TestAlgorithmConfusionRejected(t *testing.T) {
+	private, _ := writeKey(t, config.JWTAlgorithmRS256)
+	tokens, err := NewJWT(&config.Config{JWT: config.JWT{
+		Algorithm: config.JWTAlgorithmRS256, ExpireDuration: time.Hour, KeyID: "k1",
+		Keys: []config.JWTKey{{ID: "k1", PrivateKeyFile: private}},
+	}}, clock.Frozen())
+	if err != nil {
+		t.Fatalf("NewJWT failed: %v", err)
+	}
+
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, CustomClaims{UserID: 1})
+	forged.Header["kid"] = "k1"
+	signed, _ := forged.SignedString([]byte("secret"))
+	if _, err := tokens.ParseToken(signed); err == nil {
+		t.Error("HS256 token should be rejected when RS256 is configured")
+	}
+	if tokens.JWKS() == nil {
+		t.Error("RS256 should publish JWKS")
+	}
+}
+
+func TestEncryptedTokens(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString(make([]byte, 32))
+	cfg := &config.Config{JWT: config.JWT{
+		Secret: "secret", ExpireDuration: time.Hour,
+		Encryption: config.JWTEncryption{Enabled: true, Key: key},
+	}}
+	tokens, err := NewJWT(cfg, clock.Frozen())
+	if err != nil {
+		t.Fatalf("NewJWT failed: %v", err)
+	}
+
+	token, err := tokens.GenerateToken(1, "alice")
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+	if parts := strings.Split(token, "."); len(parts) != 5 || strings.Contains(token, "alice") {
+		t.Fatalf("expected compact JWE, got %s", token)
+	}
+	if claims, err := tokens.ParseToken(token); err != nil || claims.Username != "alice" {
+		t.Fatalf("ParseToken = %+v, %v", claims, err)
+	}
+
+	// 加密后只接受 JWE，篡改密文无法解密
+	cfg.JWT.Encryption.Enabled = false
+	plain, _ := NewJWT(cfg, clock.Frozen())
+	signed, _ := plain.GenerateToken(1, "alice")
+	if _, err := tokens.ParseToken(signed); !errors.Is(err, jwt.ErrTokenMalformed) {
+		t.Errorf("plain token should be rejected, got %v", err)
+	}
+	tampered := token[:len(token)-30] + strings.Repeat("A", 8) + token[len(token)-22:]
+	if _, err := tokens.ParseToken(tampered); err == nil {
+		t.Error("tampered JWE should be rejected")
+	}
+
+	cfg.JWT.Encryption = config.JWTEncryption{Enabled: true, Key: "short"}
+	if _, err := NewJWT(cfg, clock.Frozen()); err == nil {
+		t.Error("invalid encryption key should fail")
+	}
+}
+
+func TestClaimsValidation(t *testing.T) {
+	clk := clock.Frozen()
+	newTokens := func(cfg config.JWT) *JWT {
+		t.Helper()
+		cfg.Secret, cfg.ExpireDuration = "secret", time.Hour
+		tokens, err := NewJWT(&config.Config{JWT: cfg}, clk)
+		if err != nil {
+			t.Fatalf("NewJWT failed: %v", err)
+		}
+		return tokens
+	}
+
+	issuer := newTokens(config.JWT{Issuer: "auth", Audience: []string{"api", "admin"}})
+	token, err := issuer.GenerateToken(1, "alice")
+	if err != nil {
+		t.Fatalf("GenerateToken failed: %v", err)
+	}
+
+	if _, err := newTokens(config.JWT{Issuer: "auth", Audience: []string{"admin"}}).ParseToken(token); err != nil {
+		t.Errorf("matching audience should pass: %v", err)
+	}
+	if _, err := newTokens(config.JWT{Issuer: "auth", Audience: []string{"billing"}}).ParseToken(token); TokenError(err) != apperrors.ErrTokenAudience {
+		t.Errorf("audience mismatch = %v", err)
+	}
+	if _, err := newTokens(config.JWT{Issuer: "other"}).ParseToken(token); TokenError(err) != apperrors.ErrTokenIssuer {
+		t.Errorf("issuer mismatch = %v", err)
+	}
+	if _, err := newTokens(config.JWT{}).ParseToken("not-a-token"); TokenError(err) != apperrors.ErrTokenMalformed {
+		t.Errorf("malformed token = %v", err)
+	}
+	if _, err := newTokens(config.JWT{Issuer: "auth"}).ParseToken(token[:len(token)-2] + "xx"); TokenError(err) != apperrors.ErrTokenSignature {
+		t.Errorf("bad signature = %v", err)
+	}
+
+	// 签发方时钟超前 30 秒，leeway 内的令牌可以使用
+	clk.Advance(-30 * time.Second)
+	if _, err := newTokens(config.JWT{Issuer: "auth"}).ParseToken(token); TokenError(err) != apperrors.ErrTokenNotYetValid {
+		t.Errorf("token from the future = %v", err)
+	}
+	if _, err := newTokens(config.JWT{Issuer: "auth", Leeway: time.Minute}).ParseToken(token); err != nil {
+		t.Errorf("leeway should tolerate clock skew: %v", err)
+	}
+
+	clk.Advance(time.Hour + time.Minute)
+	if _, err := newTokens(config.JWT{Issuer: "auth"}).ParseToken(token); TokenError(err) != apperrors.ErrTokenExpired {
+		t.Errorf("expired token = %v", err)
+	}
+}
+
+func TestRequireNotBefore(t *testing.T) {
+	clk := clock.Frozen()
+	tokens, err := NewJWT(&config.Config{JWT: config.JWT{Secret: "secret", ExpireDuration: time.Hour, RequireNotBefore: true}}, clk)
+	if err != nil {
+		t.Fatalf("NewJWT failed: %v", err)
+	}
+
+	claims := CustomClaims{UserID: 1, RegisteredClaims: jwt.RegisteredClaims{
+		Issuer:    defaultIssuer,
+		ExpiresAt: jwt.NewNumericDate(clk.Now().Add(time.Hour)),
+	}}
+	signed, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if _, err := tokens.ParseToken(signed); !errors.Is(err, jwt.ErrTokenRequiredClaimMissing) {
+		t.Errorf("token without nbf = %v", err)
+	}
+
+	issued, _ := tokens.GenerateToken(1, "alice")
+	if _, err := tokens.ParseToken(issued); err != nil {
+		t.Errorf("issued token should carry nbf: %v", err)
+	}
+}
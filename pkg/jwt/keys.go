@@ -0,0 +1,155 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/hedeqiang/skeleton/internal/config"
+)
+
+// JWKS JSON Web Key Set，/.well-known/jwks.json 的响应
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK 单个公钥
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// keySet 按 kid 索引的非对称密钥
+type keySet struct {
+	private map[string]crypto.Signer
+	public  map[string]crypto.PublicKey
+}
+
+// loadKeySet 读取配置中的 PEM 密钥，密钥类型必须与算法匹配
+func loadKeySet(algorithm string, keys []config.JWTKey) (*keySet, error) {
+	set := &keySet{
+		private: make(map[string]crypto.Signer, len(keys)),
+		public:  make(map[string]crypto.PublicKey, len(keys)),
+	}
+
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("jwt.keys: id is required")
+		}
+		if _, exists := set.public[key.ID]; exists {
+			return nil, fmt.Errorf("jwt.keys: duplicate id %q", key.ID)
+		}
+
+		switch {
+		case key.PrivateKeyFile != "":
+			signer, err := readPrivateKey(algorithm, key.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("jwt.keys[%s]: %w", key.ID, err)
+			}
+			set.private[key.ID] = signer
+			set.public[key.ID] = signer.Public()
+		case key.PublicKeyFile != "":
+			public, err := readPublicKey(algorithm, key.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("jwt.keys[%s]: %w", key.ID, err)
+			}
+			set.public[key.ID] = public
+		default:
+			return nil, fmt.Errorf("jwt.keys[%s]: private_key_file or public_key_file is required", key.ID)
+		}
+	}
+	return set, nil
+}
+
+// readPrivateKey 读取 PEM 私钥，支持 PKCS#1、PKCS#8 和 SEC 1 格式
+func readPrivateKey(algorithm, path string) (crypto.Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm == config.JWTAlgorithmRS256 {
+		return jwt.ParseRSAPrivateKeyFromPEM(data)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	if key.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("ES256 requires a P-256 key")
+	}
+	return key, nil
+}
+
+// readPublicKey 读取 PEM 公钥或证书
+func readPublicKey(algorithm, path string) (crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if algorithm == config.JWTAlgorithmRS256 {
+		return jwt.ParseRSAPublicKeyFromPEM(data)
+	}
+	key, err := jwt.ParseECPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, err
+	}
+	if key.Curve != elliptic.P256() {
+		return nil, fmt.Errorf("ES256 requires a P-256 key")
+	}
+	return key, nil
+}
+
+// jwks 按 kid 排序输出全部公钥
+func (s *keySet) jwks(algorithm string) *JWKS {
+	ids := make([]string, 0, len(s.public))
+	for id := range s.public {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := &JWKS{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		jwk := JWK{Kid: id, Use: "sig", Alg: algorithm}
+		switch key := s.public[id].(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = encodeSegment(key.N.Bytes())
+			jwk.E = encodeSegment(big.NewInt(int64(key.E)).Bytes())
+		case *ecdsa.PublicKey:
+			// 未压缩格式为 0x04 || X || Y
+			point, err := key.ECDH()
+			if err != nil {
+				continue
+			}
+			raw := point.Bytes()
+			size := (len(raw) - 1) / 2
+			jwk.Kty = "EC"
+			jwk.Crv = key.Curve.Params().Name
+			jwk.X = encodeSegment(raw[1 : 1+size])
+			jwk.Y = encodeSegment(raw[1+size:])
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// encodeSegment base64url 编码，不带填充
+func encodeSegment(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
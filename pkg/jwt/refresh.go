@@ -0,0 +1,110 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// refreshPrefix 刷新令牌的 key 前缀，key 中只保存令牌的哈希
+	refreshPrefix = "auth:refresh:"
+
+	defaultRefreshDuration = 7 * 24 * time.Hour
+	refreshTokenBytes      = 32
+)
+
+// ErrRefreshTokenNotFound 刷新令牌不存在、已过期或已被使用
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
+// RefreshToken 已签发的刷新令牌
+type RefreshToken struct {
+	Token     string
+	UserID    uint
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
+
+// RefreshTokens 基于 Redis 保存刷新令牌，多实例部署时共享
+// 刷新令牌是随机字符串而不是 JWT，只能使用一次，换取新令牌时旧令牌随之删除
+type RefreshTokens struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRefreshTokens 创建刷新令牌存储，jwt.refresh_duration 未配置时有效期为 7 天
+func NewRefreshTokens(client *redis.Client, cfg *config.Config) *RefreshTokens {
+	return &RefreshTokens{
+		client: client,
+		ttl:    refreshDuration(&cfg.JWT),
+	}
+}
+
+// Issue 为用户签发刷新令牌，签发时间取自 now，用于与令牌吊销时间比较
+func (r *RefreshTokens) Issue(ctx context.Context, userID uint, now time.Time) (*RefreshToken, error) {
+	b := make([]byte, refreshTokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	token := &RefreshToken{
+		Token:     base64.RawURLEncoding.EncodeToString(b),
+		UserID:    userID,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(r.ttl),
+	}
+
+	value := strconv.FormatUint(uint64(userID), 10) + ":" + strconv.FormatInt(now.Unix(), 10)
+	if err := r.client.Set(ctx, r.key(token.Token), value, r.ttl).Err(); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Consume 校验并删除刷新令牌，返回令牌所属的用户和签发时间
+// 读取和删除是一次原子操作，同一令牌并发刷新时只有一个请求成功；令牌不存在时返回 ErrRefreshTokenNotFound
+func (r *RefreshTokens) Consume(ctx context.Context, token string) (*RefreshToken, error) {
+	value, err := r.client.GetDel(ctx, r.key(token)).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrRefreshTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	id, issued, ok := strings.Cut(value, ":")
+	userID, idErr := strconv.ParseUint(id, 10, 64)
+	unix, issuedErr := strconv.ParseInt(issued, 10, 64)
+	if !ok || idErr != nil || issuedErr != nil {
+		return nil, ErrRefreshTokenNotFound
+	}
+	issuedAt := time.Unix(unix, 0)
+	return &RefreshToken{
+		Token:     token,
+		UserID:    uint(userID),
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(r.ttl),
+	}, nil
+}
+
+// refreshDuration 返回刷新令牌有效期
+func refreshDuration(cfg *config.JWT) time.Duration {
+	if cfg.RefreshDuration <= 0 {
+		return defaultRefreshDuration
+	}
+	return cfg.RefreshDuration
+}
+
+func (r *RefreshTokens) key(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return refreshPrefix + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,77 @@
+package jwt
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// revocationPrefix 令牌吊销时间的 key 前缀
+const revocationPrefix = "auth:revoked:"
+
+// Revocations 基于 Redis 记录每个用户的令牌吊销时间，多实例部署时共享
+// 签发时间不晚于吊销时间的令牌视为已吊销。JWT 的签发时间精确到秒，吊销当秒签发的令牌同样失效
+type Revocations struct {
+	client   *redis.Client
+	ttl      time.Duration
+	failOpen bool
+}
+
+// NewRevocations 创建令牌吊销存储
+// 吊销记录保留访问令牌和刷新令牌中较长的有效期加时钟偏差，之后吊销前签发的令牌都已过期，记录随之失效
+func NewRevocations(client *redis.Client, cfg *config.Config) *Revocations {
+	return &Revocations{
+		client:   client,
+		ttl:      max(cfg.JWT.ExpireDuration, refreshDuration(&cfg.JWT)) + cfg.JWT.Leeway,
+		failOpen: cfg.Auth.RevocationFailOpen,
+	}
+}
+
+// FailOpen 返回吊销检查失败时是否放行令牌
+func (r *Revocations) FailOpen() bool {
+	return r != nil && r.failOpen
+}
+
+// RevokeUser 吊销用户在 at 之前签发的全部令牌
+func (r *Revocations) RevokeUser(ctx context.Context, userID uint, at time.Time) error {
+	if r == nil || r.client == nil {
+		return nil
+	}
+	return r.client.Set(ctx, r.key(userID), at.Unix(), max(r.ttl, 0)).Err()
+}
+
+// RevokedAt 返回用户最近一次吊销令牌的时间，没有有效的吊销记录时返回零值
+func (r *Revocations) RevokedAt(ctx context.Context, userID uint) (time.Time, error) {
+	if r == nil || r.client == nil {
+		return time.Time{}, nil
+	}
+	unix, err := r.client.Get(ctx, r.key(userID)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// Revoked 判断令牌是否已被吊销，令牌没有签发时间时按已吊销处理
+func (r *Revocations) Revoked(ctx context.Context, claims *CustomClaims) (bool, error) {
+	revokedAt, err := r.RevokedAt(ctx, claims.UserID)
+	if err != nil || revokedAt.IsZero() {
+		return false, err
+	}
+	if claims.IssuedAt == nil {
+		return true, nil
+	}
+	return !claims.IssuedAt.After(revokedAt), nil
+}
+
+func (r *Revocations) key(userID uint) string {
+	return revocationPrefix + strconv.FormatUint(uint64(userID), 10)
+}
@@ -0,0 +1,281 @@
+package loadshed
+
+import (
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	appmetrics "github.com/hedeqiang/skeleton/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// 降载监控默认值
+const (
+	defaultInterval = time.Second
+	// recoverRatio 压力回落到阈值的该比例以下才降低降载等级，避免在阈值附近反复切换
+	recoverRatio = 0.9
+)
+
+// 降载等级，压力为各项指标与阈值之比的最大值，达到 1、1.25、1.5 时依次拒绝 low、normal、high 优先级的请求
+// critical 优先级的请求从不拒绝
+var levelThresholds = []float64{1, 1.25, 1.5}
+
+// priorityRanks 各优先级在降载等级达到多少时被拒绝
+var priorityRanks = map[string]int32{
+	config.LoadShedPriorityLow:    1,
+	config.LoadShedPriorityNormal: 2,
+	config.LoadShedPriorityHigh:   3,
+}
+
+// 运行时指标名称
+const (
+	schedLatenciesMetric = "/sched/latencies:seconds"
+	heapObjectsMetric    = "/memory/classes/heap/objects:bytes"
+)
+
+var (
+	pressureGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: appmetrics.Namespace,
+		Subsystem: "load_shed",
+		Name:      "pressure",
+		Help:      "Ratio of each runtime signal to its configured limit (goroutines, sched_latency, heap).",
+	}, []string{"signal"})
+
+	levelGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: appmetrics.Namespace,
+		Subsystem: "load_shed",
+		Name:      "level",
+		Help:      "Current load shedding level: 0 none, 1 low, 2 normal, 3 high priority requests rejected.",
+	})
+
+	shedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: appmetrics.Namespace,
+		Subsystem: "load_shed",
+		Name:      "shed_requests_total",
+		Help:      "Total number of requests rejected because the instance was overloaded, by route priority.",
+	}, []string{"priority"})
+)
+
+func init() {
+	appmetrics.Registry.MustRegister(pressureGauge, levelGauge, shedTotal)
+}
+
+// Stats 一次采样得到的运行时指标
+type Stats struct {
+	Goroutines   int
+	SchedLatency time.Duration // 采样周期内 goroutine 从就绪到运行的 p99 等待时间
+	HeapBytes    uint64        // 存活与未回收对象占用的堆内存
+}
+
+// Monitor 定期采样 goroutine 数、调度延迟和堆内存，按与阈值之比计算降载等级供 HTTP 层拒绝低优先级请求
+type Monitor struct {
+	read            func() Stats
+	logger          *zap.Logger
+	interval        time.Duration
+	maxGoroutines   int
+	maxSchedLatency time.Duration
+	maxHeapBytes    uint64
+
+	level atomic.Int32
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewMonitor 创建并启动降载监控，未启用时返回 nil
+func NewMonitor(cfg *config.LoadShedding, logger *zap.Logger) *Monitor {
+	if !cfg.Enabled {
+		return nil
+	}
+
+	m := newMonitor(newRuntimeReader().read, cfg, logger)
+	go m.run()
+	return m
+}
+
+func newMonitor(read func() Stats, cfg *config.LoadShedding, logger *zap.Logger) *Monitor {
+	m := &Monitor{
+		read:            read,
+		logger:          logger,
+		interval:        cfg.Interval,
+		maxGoroutines:   cfg.MaxGoroutines,
+		maxSchedLatency: cfg.MaxSchedLatency,
+		maxHeapBytes:    uint64(cfg.MaxHeapMB) << 20,
+		stop:            make(chan struct{}),
+		done:            make(chan struct{}),
+	}
+	if m.interval <= 0 {
+		m.interval = defaultInterval
+	}
+	return m
+}
+
+// Level 当前降载等级，nil 接收者返回 0
+func (m *Monitor) Level() int {
+	if m == nil {
+		return 0
+	}
+	return int(m.level.Load())
+}
+
+// Shed 判断当前等级下是否应拒绝该优先级的请求，未知优先级按 normal 处理
+func (m *Monitor) Shed(priority string) bool {
+	if m == nil || priority == config.LoadShedPriorityCritical {
+		return false
+	}
+	rank, ok := priorityRanks[priority]
+	if !ok {
+		rank = priorityRanks[config.LoadShedPriorityNormal]
+	}
+	return m.level.Load() >= rank
+}
+
+// RecordShed 记录一次因实例过载被拒绝的请求
+func (m *Monitor) RecordShed(priority string) {
+	shedTotal.WithLabelValues(priority).Inc()
+}
+
+// Stop 停止采样，可重复调用
+func (m *Monitor) Stop() {
+	if m == nil {
+		return
+	}
+	m.stopOnce.Do(func() {
+		close(m.stop)
+		<-m.done
+	})
+}
+
+func (m *Monitor) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.sample()
+		}
+	}
+}
+
+// sample 采样一次运行时指标并更新降载等级，未配置阈值的指标不参与计算
+func (m *Monitor) sample() {
+	stats := m.read()
+
+	var goroutines, schedLatency, heap float64
+	if m.maxGoroutines > 0 {
+		goroutines = float64(stats.Goroutines) / float64(m.maxGoroutines)
+	}
+	if m.maxSchedLatency > 0 {
+		schedLatency = float64(stats.SchedLatency) / float64(m.maxSchedLatency)
+	}
+	if m.maxHeapBytes > 0 {
+		heap = float64(stats.HeapBytes) / float64(m.maxHeapBytes)
+	}
+	pressureGauge.WithLabelValues("goroutines").Set(goroutines)
+	pressureGauge.WithLabelValues("sched_latency").Set(schedLatency)
+	pressureGauge.WithLabelValues("heap").Set(heap)
+
+	pressure := math.Max(goroutines, math.Max(schedLatency, heap))
+	current := m.level.Load()
+	next := levelFor(pressure)
+	if next < current && levelFor(pressure/recoverRatio) >= current {
+		next = current
+	}
+	if next == current {
+		return
+	}
+
+	m.level.Store(next)
+	levelGauge.Set(float64(next))
+	fields := []zap.Field{
+		zap.Int32("level", next),
+		zap.Int("goroutines", stats.Goroutines),
+		zap.Duration("sched_latency", stats.SchedLatency),
+		zap.Uint64("heap_bytes", stats.HeapBytes),
+	}
+	if next > current {
+		m.logger.Warn("Instance overloaded, shedding low priority requests", fields...)
+	} else {
+		m.logger.Info("Instance load decreased", fields...)
+	}
+}
+
+// levelFor 返回压力对应的降载等级
+func levelFor(pressure float64) int32 {
+	var level int32
+	for _, threshold := range levelThresholds {
+		if pressure >= threshold {
+			level++
+		}
+	}
+	return level
+}
+
+// runtimeReader 通过 runtime/metrics 读取调度延迟和堆内存，调度延迟按两次采样间新增的直方图计数计算
+type runtimeReader struct {
+	samples    []metrics.Sample
+	lastCounts []uint64
+}
+
+func newRuntimeReader() *runtimeReader {
+	return &runtimeReader{samples: []metrics.Sample{
+		{Name: schedLatenciesMetric},
+		{Name: heapObjectsMetric},
+	}}
+}
+
+func (r *runtimeReader) read() Stats {
+	metrics.Read(r.samples)
+
+	stats := Stats{Goroutines: runtime.NumGoroutine()}
+	if r.samples[0].Value.Kind() == metrics.KindFloat64Histogram {
+		stats.SchedLatency = r.schedLatency(r.samples[0].Value.Float64Histogram())
+	}
+	if r.samples[1].Value.Kind() == metrics.KindUint64 {
+		stats.HeapBytes = r.samples[1].Value.Uint64()
+	}
+	return stats
+}
+
+// schedLatency 估算本周期内调度延迟的 p99，取所在桶的上界，上界为 +Inf 时取下界
+func (r *runtimeReader) schedLatency(h *metrics.Float64Histogram) time.Duration {
+	delta := make([]uint64, len(h.Counts))
+	var total uint64
+	for i, count := range h.Counts {
+		delta[i] = count
+		if i < len(r.lastCounts) {
+			delta[i] -= r.lastCounts[i]
+		}
+		total += delta[i]
+	}
+	r.lastCounts = append(r.lastCounts[:0], h.Counts...)
+	if total == 0 {
+		return 0
+	}
+
+	target := uint64(math.Ceil(float64(total) * 0.99))
+	var seen uint64
+	for i, count := range delta {
+		seen += count
+		if seen < target {
+			continue
+		}
+		bound := h.Buckets[i+1]
+		if math.IsInf(bound, 1) {
+			bound = h.Buckets[i]
+		}
+		return time.Duration(bound * float64(time.Second))
+	}
+	return 0
+}
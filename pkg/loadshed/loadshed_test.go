@@ -0,0 +1,90 @@
+package loadshed
+
+import (
+	"runtime/metrics"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"go.uber.org/zap"
+)
+
+func TestMonitorLevels(t *testing.T) {
+	var stats Stats
+	m := newMonitor(func() Stats { return stats }, &config.LoadShedding{
+		MaxGoroutines:   1000,
+		MaxSchedLatency: 10 * time.Millisecond,
+		MaxHeapMB:       100,
+	}, zap.NewNop())
+
+	for _, tc := range []struct {
+		name  string
+		stats Stats
+		level int
+	}{
+		{"idle", Stats{Goroutines: 100, HeapBytes: 10 << 20}, 0},
+		{"goroutines over limit", Stats{Goroutines: 1000}, 1},
+		{"sched latency far over limit", Stats{SchedLatency: 20 * time.Millisecond}, 3},
+		// 压力回落但仍高于阈值的 0.9 倍时保持原等级
+		{"slightly below high threshold", Stats{HeapBytes: 140 << 20}, 3},
+		{"heap below normal threshold", Stats{HeapBytes: 110 << 20}, 1},
+		{"recovered", Stats{HeapBytes: 50 << 20}, 0},
+	} {
+		stats = tc.stats
+		m.sample()
+		if got := m.Level(); got != tc.level {
+			t.Errorf("%s: level = %d, want %d", tc.name, got, tc.level)
+		}
+	}
+}
+
+func TestMonitorShedByPriority(t *testing.T) {
+	m := newMonitor(func() Stats { return Stats{Goroutines: 1300} }, &config.LoadShedding{MaxGoroutines: 1000}, zap.NewNop())
+	m.sample()
+
+	for priority, want := range map[string]bool{
+		config.LoadShedPriorityLow:      true,
+		config.LoadShedPriorityNormal:   true,
+		"unknown":                       true,
+		config.LoadShedPriorityHigh:     false,
+		config.LoadShedPriorityCritical: false,
+	} {
+		if got := m.Shed(priority); got != want {
+			t.Errorf("Shed(%s) = %v, want %v", priority, got, want)
+		}
+	}
+}
+
+func TestMonitorNil(t *testing.T) {
+	var m *Monitor
+	if m.Level() != 0 || m.Shed(config.LoadShedPriorityLow) {
+		t.Error("nil monitor should never shed")
+	}
+	m.Stop()
+
+	if monitor := NewMonitor(&config.LoadShedding{}, zap.NewNop()); monitor != nil {
+		t.Errorf("disabled monitor = %v", monitor)
+	}
+}
+
+func TestSchedLatencyUsesDelta(t *testing.T) {
+	r := &runtimeReader{}
+	h := &metrics.Float64Histogram{
+		Counts:  []uint64{100, 0, 0},
+		Buckets: []float64{0, 0.001, 0.01, 0.1},
+	}
+	if got := r.schedLatency(h); got != time.Millisecond {
+		t.Errorf("first sample p99 = %s, want 1ms", got)
+	}
+
+	// 本周期新增的计数全部落在 10ms-100ms 桶
+	h.Counts = []uint64{100, 0, 5}
+	if got := r.schedLatency(h); got != 100*time.Millisecond {
+		t.Errorf("second sample p99 = %s, want 100ms", got)
+	}
+
+	if got := r.schedLatency(h); got != 0 {
+		t.Errorf("sample without new counts = %s, want 0", got)
+	}
+}
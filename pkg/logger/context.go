@@ -0,0 +1,56 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// fieldsKey 日志字段在 context 中的键
+type fieldsKey struct{}
+
+// WithFields 返回携带日志字段的 context，与已有字段合并，同名字段以新值为准
+// 之后通过 FromContext 取得的 logger 会在每条日志中自动带上这些字段
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	existing := Fields(ctx)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	for _, field := range existing {
+		if !hasKey(fields, field.Key) {
+			merged = append(merged, field)
+		}
+	}
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// Fields 返回 context 中携带的日志字段
+func Fields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	return fields
+}
+
+// FromContext 返回附加了 context 日志字段的 logger，没有字段时直接返回 base
+func FromContext(ctx context.Context, base *zap.Logger) *zap.Logger {
+	fields := Fields(ctx)
+	if len(fields) == 0 {
+		return base
+	}
+	return base.With(fields...)
+}
+
+// hasKey 判断字段列表中是否存在指定的键
+func hasKey(fields []zap.Field, key string) bool {
+	for _, field := range fields {
+		if field.Key == key {
+			return true
+		}
+	}
+	return false
+}
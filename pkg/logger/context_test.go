@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	base := zap.New(core)
+
+	ctx := WithFields(context.Background(), zap.String("queue", "hello"), zap.String("message_id", "1"))
+	ctx = WithFields(ctx, zap.String("message_id", "2"))
+	FromContext(ctx, base).Info("processed")
+
+	fields := logs.All()[0].ContextMap()
+	if len(fields) != 2 || fields["queue"] != "hello" || fields["message_id"] != "2" {
+		t.Fatalf("unexpected fields: %v", fields)
+	}
+
+	if FromContext(context.Background(), base) != base {
+		t.Fatal("expected base logger when context has no fields")
+	}
+}
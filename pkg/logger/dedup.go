@@ -0,0 +1,190 @@
+package logger
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// defaultDedupMaxKeys 未配置 max_keys 时同时跟踪的不同消息数上限
+const defaultDedupMaxKeys = 1000
+
+// dedupSweepInterval 检查去重窗口是否结束的最小间隔，窗口结束后在下一次输出日志或 Sync 时输出汇总
+const dedupSweepInterval = time.Second
+
+// dedupKey 去重的依据：同一 logger、同一级别、同一消息，字段不参与比较
+type dedupKey struct {
+	level   zapcore.Level
+	logger  string
+	message string
+}
+
+// dedupEntry 去重窗口内的状态
+type dedupEntry struct {
+	expires    time.Time
+	window     time.Duration
+	suppressed int
+	// 最后一条被抑制日志的写入目标、条目和字段，用于输出汇总
+	core   zapcore.Core
+	entry  zapcore.Entry
+	fields []zapcore.Field
+}
+
+// summary 返回窗口结束时输出的汇总日志，消息不变，repeated 为窗口内被抑制的条数
+func (e *dedupEntry) summary(now time.Time) (zapcore.Core, zapcore.Entry, []zapcore.Field) {
+	entry := e.entry
+	entry.Time = now
+	fields := append(e.fields[:len(e.fields):len(e.fields)],
+		zap.Int("repeated", e.suppressed),
+		zap.Duration("repeated_window", e.window),
+	)
+	return e.core, entry, fields
+}
+
+// dedupState 同一 logger 经 With 派生出的所有 core 共享的去重状态
+type dedupState struct {
+	windows map[zapcore.Level]time.Duration
+	maxKeys int
+
+	mu        sync.Mutex
+	entries   map[dedupKey]*dedupEntry
+	nextSweep atomic.Int64
+}
+
+// dedupCore 在窗口内抑制重复日志：窗口内第一条正常输出，其余只计数，
+// 窗口结束后输出一条带 repeated 字段的汇总，避免依赖故障时同一错误刷屏
+type dedupCore struct {
+	zapcore.Core
+	state *dedupState
+}
+
+// newDedupCore 按 logger.dedup 配置包装 core，未开启或未配置任何级别时原样返回
+func newDedupCore(core zapcore.Core, cfg config.LogDedup) (zapcore.Core, error) {
+	if !cfg.Enabled || len(cfg.Windows) == 0 {
+		return core, nil
+	}
+
+	windows := make(map[zapcore.Level]time.Duration, len(cfg.Windows))
+	for name, window := range cfg.Windows {
+		l, err := zapcore.ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid dedup level %q: %w", name, err)
+		}
+		if window > 0 {
+			windows[l] = window
+		}
+	}
+	maxKeys := cfg.MaxKeys
+	if maxKeys <= 0 {
+		maxKeys = defaultDedupMaxKeys
+	}
+
+	return dedupCore{Core: core, state: &dedupState{
+		windows: windows,
+		maxKeys: maxKeys,
+		entries: make(map[dedupKey]*dedupEntry),
+	}}, nil
+}
+
+// With 实现 zapcore.Core 接口
+func (c dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return dedupCore{Core: c.Core.With(fields), state: c.state}
+}
+
+// Check 实现 zapcore.Core 接口，需要去重的级别在 Write 中判断
+func (c dedupCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	c.sweep(entry.Time, false)
+
+	if _, ok := c.state.windows[entry.Level]; !ok {
+		return c.Core.Check(entry, checked)
+	}
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write 实现 zapcore.Core 接口
+func (c dedupCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	window, ok := c.state.windows[entry.Level]
+	if !ok {
+		return c.Core.Write(entry, fields)
+	}
+	suppressed, expired := c.suppress(entry, fields, window)
+	if suppressed {
+		return nil
+	}
+	if expired != nil {
+		core, summary, summaryFields := expired.summary(entry.Time)
+		_ = core.Write(summary, summaryFields)
+	}
+	return c.Core.Write(entry, fields)
+}
+
+// Sync 实现 zapcore.Core 接口，先输出所有未结束窗口的汇总，保证退出前不丢失计数
+func (c dedupCore) Sync() error {
+	c.sweep(time.Now(), true)
+	return c.Core.Sync()
+}
+
+// suppress 记录一次出现，窗口内的重复日志返回 true；上一个窗口已结束且有被抑制的日志时一并返回，由调用方先输出汇总
+// 跟踪的消息数达到上限时新消息不去重，宁可多输出也不丢日志
+func (c dedupCore) suppress(entry zapcore.Entry, fields []zapcore.Field, window time.Duration) (bool, *dedupEntry) {
+	key := dedupKey{level: entry.Level, logger: entry.LoggerName, message: entry.Message}
+
+	s := c.state
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if ok && entry.Time.Before(e.expires) {
+		e.suppressed++
+		e.core = c.Core
+		e.entry = entry
+		e.fields = append(e.fields[:0], fields...)
+		return true, nil
+	}
+	if !ok && len(s.entries) >= s.maxKeys {
+		return false, nil
+	}
+	s.entries[key] = &dedupEntry{expires: entry.Time.Add(window), window: window}
+	if ok && e.suppressed > 0 {
+		return false, e
+	}
+	return false, nil
+}
+
+// sweep 输出已结束窗口的汇总并清理状态，force 为 true 时不论窗口是否结束都输出
+// 非 force 时每 dedupSweepInterval 最多执行一次
+func (c dedupCore) sweep(now time.Time, force bool) {
+	s := c.state
+	if !force {
+		next := s.nextSweep.Load()
+		if now.UnixNano() < next || !s.nextSweep.CompareAndSwap(next, now.Add(dedupSweepInterval).UnixNano()) {
+			return
+		}
+	}
+
+	var done []*dedupEntry
+	s.mu.Lock()
+	for key, e := range s.entries {
+		if force || !now.Before(e.expires) {
+			if e.suppressed > 0 {
+				done = append(done, e)
+			}
+			delete(s.entries, key)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, e := range done {
+		core, entry, fields := e.summary(now)
+		_ = core.Write(entry, fields)
+	}
+}
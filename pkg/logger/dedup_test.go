@@ -0,0 +1,100 @@
+package logger
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDedupCore(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	core, err := newDedupCore(observed, config.LogDedup{
+		Enabled: true,
+		Windows: map[string]time.Duration{"error": time.Hour},
+	})
+	if err != nil {
+		t.Fatalf("newDedupCore failed: %v", err)
+	}
+	log := zap.New(core).Named("mq")
+
+	for i := 0; i < 5; i++ {
+		log.Error("broker down", zap.Int("attempt", i))
+		log.Warn("retrying")
+	}
+	log.Named("consumer").Error("broker down")
+
+	// 未配置窗口的级别不去重，不同 logger 名称分别计数
+	if n := logs.FilterMessage("retrying").Len(); n != 5 {
+		t.Fatalf("expected 5 warn logs, got %d", n)
+	}
+	if n := logs.FilterMessage("broker down").Len(); n != 2 {
+		t.Fatalf("expected first error of each logger only, got %d", n)
+	}
+
+	// Sync 时输出汇总，字段取最后一条被抑制的日志
+	if err := core.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	summaries := logs.FilterField(zap.Int("repeated", 4)).All()
+	if len(summaries) != 1 || summaries[0].LoggerName != "mq" || summaries[0].ContextMap()["attempt"] != int64(4) {
+		t.Fatalf("unexpected summary: %+v", summaries)
+	}
+
+	// 汇总后开始新的窗口
+	log.Error("broker down")
+	if n := logs.FilterMessage("broker down").Len(); n != 4 {
+		t.Fatalf("expected new window after sync, got %d", n)
+	}
+}
+
+func TestDedupCoreWindowExpiry(t *testing.T) {
+	observed, logs := observer.New(zapcore.DebugLevel)
+	core, err := newDedupCore(observed, config.LogDedup{
+		Enabled: true,
+		Windows: map[string]time.Duration{"error": time.Minute},
+		MaxKeys: 1,
+	})
+	if err != nil {
+		t.Fatalf("newDedupCore failed: %v", err)
+	}
+
+	start := time.Now()
+	write := func(msg string, at time.Duration) {
+		entry := zapcore.Entry{Level: zapcore.ErrorLevel, Message: msg, Time: start.Add(at)}
+		if ce := core.Check(entry, nil); ce != nil {
+			ce.Write(zap.Error(errors.New("connection refused")))
+		}
+	}
+
+	write("broker down", 0)
+	write("broker down", time.Second)
+	write("broker down", 2*time.Second)
+	// 超出 max_keys 的新消息不去重
+	write("other", 3*time.Second)
+	write("other", 4*time.Second)
+	// 窗口结束后先输出上一个窗口的汇总，再输出本条
+	write("broker down", 2*time.Minute)
+
+	entries := logs.All()
+	if len(entries) != 5 {
+		t.Fatalf("expected 5 logs, got %d: %+v", len(entries), entries)
+	}
+	if entries[3].ContextMap()["repeated"] != int64(2) || entries[4].ContextMap()["repeated"] != nil {
+		t.Fatalf("summary must precede the first log of the new window: %+v", entries[3:])
+	}
+}
+
+func TestNewDedupCoreInvalidLevel(t *testing.T) {
+	if _, err := newDedupCore(zapcore.NewNopCore(), config.LogDedup{
+		Enabled: true,
+		Windows: map[string]time.Duration{"loud": time.Second},
+	}); err == nil {
+		t.Fatal("expected error for invalid level")
+	}
+}
@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/buildinfo"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 日志编码格式
+const (
+	EncodingConsole = "console"
+	EncodingJSON    = "json"
+	EncodingECS     = "ecs"
+)
+
+// ecsVersion 输出字段遵循的 Elastic Common Schema 版本
+const ecsVersion = "8.11.0"
+
+// ecsFieldNames 业务代码使用的字段名到 ECS 字段名的映射
+var ecsFieldNames = map[string]string{
+	"trace_id":   "trace.id",
+	"span_id":    "span.id",
+	"request_id": "http.request.id",
+	"error":      "error.message",
+}
+
+// ecsEncoderConfig 返回 ECS 字段名的 JSON 编码配置
+func ecsEncoderConfig() zapcore.EncoderConfig {
+	return zapcore.EncoderConfig{
+		TimeKey:        "@timestamp",
+		LevelKey:       "log.level",
+		NameKey:        "log.logger",
+		CallerKey:      "log.origin.file.name",
+		FunctionKey:    zapcore.OmitKey,
+		MessageKey:     "message",
+		StacktraceKey:  "error.stack_trace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     zapcore.RFC3339NanoTimeEncoder,
+		EncodeDuration: zapcore.NanosDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+	}
+}
+
+// serviceFields 返回写入每条日志的服务信息，console 编码时不添加
+func serviceFields(cfg *config.Logger) []zap.Field {
+	version := cfg.Service.Version
+	if version == "" {
+		version = buildinfo.Version
+	}
+
+	switch cfg.Encoding {
+	case EncodingECS:
+		return compactFields(
+			zap.String("ecs.version", ecsVersion),
+			zap.String("service.name", cfg.Service.Name),
+			zap.String("service.environment", cfg.Service.Env),
+			zap.String("service.version", version),
+		)
+	case EncodingJSON:
+		return compactFields(
+			zap.String("service", cfg.Service.Name),
+			zap.String("env", cfg.Service.Env),
+			zap.String("version", version),
+		)
+	default:
+		return nil
+	}
+}
+
+// compactFields 去掉值为空的字符串字段
+func compactFields(fields ...zap.Field) []zap.Field {
+	compacted := fields[:0]
+	for _, field := range fields {
+		if field.Type == zapcore.StringType && field.String == "" {
+			continue
+		}
+		compacted = append(compacted, field)
+	}
+	return compacted
+}
+
+// ecsCore 将字段名改写为 ECS 字段名，业务代码无需关心输出格式
+type ecsCore struct {
+	zapcore.Core
+}
+
+// With 实现 zapcore.Core 接口
+func (c ecsCore) With(fields []zapcore.Field) zapcore.Core {
+	return ecsCore{Core: c.Core.With(renameECSFields(fields))}
+}
+
+// Check 实现 zapcore.Core 接口，确保写入时经过字段改写
+func (c ecsCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// Write 实现 zapcore.Core 接口
+func (c ecsCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(entry, renameECSFields(fields))
+}
+
+// renameECSFields 按 ecsFieldNames 改写字段名，没有需要改写的字段时返回原切片
+func renameECSFields(fields []zapcore.Field) []zapcore.Field {
+	var renamed []zapcore.Field
+	for i, field := range fields {
+		name, ok := ecsFieldNames[field.Key]
+		if !ok {
+			continue
+		}
+		if renamed == nil {
+			renamed = make([]zapcore.Field, len(fields))
+			copy(renamed, fields)
+		}
+		renamed[i].Key = name
+	}
+	if renamed == nil {
+		return fields
+	}
+	return renamed
+}
@@ -0,0 +1,51 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestECSEncoding(t *testing.T) {
+	cfg := &config.Logger{
+		Encoding: EncodingECS,
+		Service:  config.LogService{Name: "skeleton", Env: "prod", Version: "v1.2.0"},
+	}
+
+	var buf bytes.Buffer
+	core := ecsCore{Core: zapcore.NewCore(getEncoder(cfg.Encoding, false), zapcore.AddSync(&buf), zapcore.InfoLevel)}
+	zap.New(core, zap.Fields(serviceFields(cfg)...)).
+		With(zap.String("request_id", "req-1")).
+		Info("hello", zap.String("trace_id", "abc"))
+
+	var record map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+		t.Fatalf("invalid json %q: %v", buf.String(), err)
+	}
+
+	want := map[string]string{
+		"log.level":           "info",
+		"message":             "hello",
+		"service.name":        "skeleton",
+		"service.environment": "prod",
+		"service.version":     "v1.2.0",
+		"trace.id":            "abc",
+		"http.request.id":     "req-1",
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %q", key, record[key], value)
+		}
+	}
+	if _, ok := record["@timestamp"]; !ok {
+		t.Error("missing @timestamp")
+	}
+	if _, ok := record["trace_id"]; ok {
+		t.Error("trace_id should be renamed")
+	}
+}
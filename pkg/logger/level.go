@@ -0,0 +1,198 @@
+package logger
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// level 进程内所有由 New 创建的 logger 共享的日志级别
+var level = zap.NewAtomicLevel()
+
+var (
+	levelMu     sync.Mutex
+	configured  = zapcore.InfoLevel // 配置文件中的日志级别
+	debugging   bool                // 是否临时切换到了 debug 级别
+	namedConfig map[string]zapcore.Level
+	named       map[string]zapcore.Level // 当前生效的命名 logger 级别
+	toggleNames []string                 // ToggleDebug 只切换这些命名 logger，为空时切换全局级别
+)
+
+// namedLevels 发布给日志写入路径的命名 logger 级别，只读
+var namedLevels atomic.Pointer[map[string]zapcore.Level]
+
+// namedMin 所有命名 logger 级别中的最低级别，没有命名 logger 级别时高于 FatalLevel
+var namedMin atomic.Int32
+
+func init() {
+	namedMin.Store(int32(zapcore.FatalLevel + 1))
+}
+
+// Level 返回当前日志级别
+func Level() zapcore.Level {
+	return level.Level()
+}
+
+// SetLevel 设置日志级别，同时作为 ToggleDebug 关闭调试时恢复的级别
+func SetLevel(l zapcore.Level) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	configured = l
+	debugging = false
+	level.SetLevel(l)
+}
+
+// ToggleDebug 在 debug 级别和配置的级别之间切换，返回切换后的全局级别
+// 配置了 logger.toggle_debug 时只切换其中的命名 logger，全局级别不变
+func ToggleDebug() zapcore.Level {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	debugging = !debugging
+	if len(toggleNames) > 0 {
+		for _, name := range toggleNames {
+			if debugging {
+				named[name] = zapcore.DebugLevel
+			} else {
+				resetNamed(name)
+			}
+		}
+		publishNamed()
+		return level.Level()
+	}
+
+	if debugging {
+		level.SetLevel(zapcore.DebugLevel)
+	} else {
+		level.SetLevel(configured)
+	}
+	return level.Level()
+}
+
+// Configure 应用 logger 配置中的全局级别、命名 logger 级别和 toggle_debug，运行时设置的级别会被覆盖
+func Configure(cfg *config.Logger) error {
+	global, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	levels := make(map[string]zapcore.Level, len(cfg.Levels))
+	for name, value := range cfg.Levels {
+		l, err := zapcore.ParseLevel(value)
+		if err != nil {
+			return fmt.Errorf("invalid level for logger %q: %w", name, err)
+		}
+		levels[strings.ToLower(name)] = l
+	}
+
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	configured = global
+	debugging = false
+	level.SetLevel(global)
+	namedConfig = levels
+	named = maps.Clone(levels)
+	toggleNames = nil
+	for _, name := range cfg.ToggleDebug {
+		toggleNames = append(toggleNames, strings.ToLower(name))
+	}
+	publishNamed()
+	return nil
+}
+
+// SetNamedLevel 设置命名 logger 的级别，名称为 mq 时同时作用于 mq.consumer 等子 logger
+func SetNamedLevel(name string, l zapcore.Level) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	named[strings.ToLower(name)] = l
+	publishNamed()
+}
+
+// ResetNamedLevel 将命名 logger 恢复为配置文件中的级别，未配置时使用全局级别
+func ResetNamedLevel(name string) {
+	levelMu.Lock()
+	defer levelMu.Unlock()
+
+	resetNamed(strings.ToLower(name))
+	publishNamed()
+}
+
+// NamedLevels 返回当前单独设置了级别的命名 logger
+func NamedLevels() map[string]zapcore.Level {
+	if levels := namedLevels.Load(); levels != nil {
+		return maps.Clone(*levels)
+	}
+	return map[string]zapcore.Level{}
+}
+
+// resetNamed 恢复命名 logger 的配置级别，调用方需持有 levelMu
+func resetNamed(name string) {
+	if l, ok := namedConfig[name]; ok {
+		named[name] = l
+	} else {
+		delete(named, name)
+	}
+}
+
+// publishNamed 发布命名 logger 级别的只读副本，调用方需持有 levelMu
+func publishNamed() {
+	if named == nil {
+		named = make(map[string]zapcore.Level)
+	}
+	levels := maps.Clone(named)
+	minimum := zapcore.FatalLevel + 1
+	for _, l := range levels {
+		minimum = min(minimum, l)
+	}
+	namedLevels.Store(&levels)
+	namedMin.Store(int32(minimum))
+}
+
+// levelOf 返回 logger 名称对应的级别，按 a.b.c、a.b、a 的顺序查找，都未设置时使用全局级别
+func levelOf(name string) zapcore.Level {
+	if levels := namedLevels.Load(); levels != nil && len(*levels) > 0 {
+		for name != "" {
+			if l, ok := (*levels)[name]; ok {
+				return l
+			}
+			i := strings.LastIndexByte(name, '.')
+			if i < 0 {
+				break
+			}
+			name = name[:i]
+		}
+	}
+	return level.Level()
+}
+
+// namedCore 按 logger 名称判断级别，未单独设置级别的 logger 使用全局级别
+type namedCore struct {
+	zapcore.Core
+}
+
+// Enabled 实现 zapcore.Core 接口，任一 logger 可能输出该级别时返回 true，由 Check 按名称过滤
+func (c namedCore) Enabled(l zapcore.Level) bool {
+	return level.Enabled(l) || l >= zapcore.Level(namedMin.Load())
+}
+
+// With 实现 zapcore.Core 接口
+func (c namedCore) With(fields []zapcore.Field) zapcore.Core {
+	return namedCore{Core: c.Core.With(fields)}
+}
+
+// Check 实现 zapcore.Core 接口
+func (c namedCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if entry.Level < levelOf(entry.LoggerName) {
+		return checked
+	}
+	return c.Core.Check(entry, checked)
+}
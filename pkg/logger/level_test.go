@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestToggleDebug(t *testing.T) {
+	SetLevel(zapcore.WarnLevel)
+
+	if l := ToggleDebug(); l != zapcore.DebugLevel {
+		t.Fatalf("expected debug after toggle, got %s", l)
+	}
+	if l := ToggleDebug(); l != zapcore.WarnLevel {
+		t.Fatalf("expected configured level after second toggle, got %s", l)
+	}
+
+	ToggleDebug()
+	SetLevel(zapcore.ErrorLevel)
+	if l := ToggleDebug(); l != zapcore.DebugLevel {
+		t.Fatalf("SetLevel should reset toggle state, got %s", l)
+	}
+}
+
+func TestNamedLevels(t *testing.T) {
+	t.Cleanup(func() { _ = Configure(&config.Logger{Level: "info"}) })
+
+	if err := Configure(&config.Logger{Level: "info", Levels: map[string]string{"MQ": "debug"}, ToggleDebug: []string{"gorm"}}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	observed, logs := observer.New(zapcore.DebugLevel)
+	log := zap.New(namedCore{Core: observed})
+
+	enabled := func(l *zap.Logger) bool {
+		before := logs.Len()
+		l.Debug("probe")
+		return logs.Len() > before
+	}
+
+	// 名称不区分大小写，子 logger 继承父名称的级别
+	if !enabled(log.Named("mq")) || !enabled(log.Named("mq").Named("consumer")) {
+		t.Fatal("mq loggers must log at debug")
+	}
+	if enabled(log) || enabled(log.Named("gorm")) || enabled(log.Named("mqx")) {
+		t.Fatal("loggers without a level must use the global level")
+	}
+
+	SetNamedLevel("gorm", zapcore.DebugLevel)
+	SetNamedLevel("mq", zapcore.ErrorLevel)
+	if !enabled(log.Named("gorm")) || enabled(log.Named("mq")) {
+		t.Fatalf("runtime levels not applied: %v", NamedLevels())
+	}
+
+	// 恢复时使用配置文件中的级别，未配置的恢复为全局级别
+	ResetNamedLevel("gorm")
+	ResetNamedLevel("mq")
+	if enabled(log.Named("gorm")) || !enabled(log.Named("mq")) {
+		t.Fatalf("reset levels = %v", NamedLevels())
+	}
+
+	// 配置了 toggle_debug 时只切换其中的 logger
+	if l := ToggleDebug(); l != zapcore.InfoLevel || !enabled(log.Named("gorm")) || enabled(log) {
+		t.Fatalf("toggle must only affect gorm, global level %s", l)
+	}
+	ToggleDebug()
+	if enabled(log.Named("gorm")) {
+		t.Fatal("second toggle must restore gorm")
+	}
+
+	if err := Configure(&config.Logger{Level: "info", Levels: map[string]string{"mq": "verbose"}}); err == nil {
+		t.Fatal("invalid named level must be rejected")
+	}
+}
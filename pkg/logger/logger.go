@@ -10,37 +10,52 @@ import (
 
 // New 根据提供的配置创建一个新的 zap Logger 实例
 func New(cfg *config.Logger) (*zap.Logger, error) {
-	// 设置日志级别
-	level, err := zapcore.ParseLevel(cfg.Level)
-	if err != nil {
+	// 设置全局和命名 logger 的日志级别
+	if err := Configure(cfg); err != nil {
 		return nil, err
 	}
 
-	// 创建 zap core
-	core := zapcore.NewCore(
-		getEncoder(cfg.Encoding),
+	// 创建 zap core，级别由 namedCore 按 logger 名称判断，运行时可通过 SetLevel、SetNamedLevel 调整
+	var core zapcore.Core = zapcore.NewCore(
+		getEncoder(cfg.Encoding, useColor(cfg.OutputPath)),
 		getWriteSyncer(cfg.OutputPath),
-		level,
+		zapcore.DebugLevel,
 	)
+	if cfg.Encoding == EncodingECS {
+		core = ecsCore{Core: core}
+	}
+	// 去重位于级别判断之后，未开启的级别不占用去重状态
+	core, err := newDedupCore(core, cfg.Dedup)
+	if err != nil {
+		return nil, err
+	}
+	core = namedCore{Core: core}
 
 	// 创建 logger
 	// zap.AddCaller() 会显示调用者信息
 	// zap.AddCallerSkip(1) 可以跳过封装函数的调用栈，直接显示业务代码的位置
-	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	// json、ecs 编码时每条日志带上服务信息，便于日志平台按服务过滤
+	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1), zap.Fields(serviceFields(cfg)...))
 
 	return logger, nil
 }
 
-// getEncoder 根据配置返回不同的编码器
-func getEncoder(encoding string) zapcore.Encoder {
+// getEncoder 根据配置返回不同的编码器，color 为 true 时 console 格式输出彩色日志级别
+func getEncoder(encoding string, color bool) zapcore.Encoder {
 	encoderConfig := zap.NewProductionEncoderConfig()
 	// 人性化时间格式
 	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 	// 小写字母级别
 	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
 
-	if encoding == "json" {
+	switch encoding {
+	case EncodingJSON:
 		return zapcore.NewJSONEncoder(encoderConfig)
+	case EncodingECS:
+		return zapcore.NewJSONEncoder(ecsEncoderConfig())
+	}
+	if color {
+		encoderConfig.EncodeLevel = zapcore.CapitalColorLevelEncoder
 	}
 	// 默认为 console 格式
 	return zapcore.NewConsoleEncoder(encoderConfig)
@@ -64,3 +79,15 @@ func getWriteSyncer(outputPaths []string) zapcore.WriteSyncer {
 	}
 	return zapcore.NewMultiWriteSyncer(writers...)
 }
+
+// useColor 只输出到标准输出且标准输出为终端时使用彩色日志，避免颜色控制符写入文件或日志采集
+func useColor(outputPaths []string) bool {
+	if len(outputPaths) != 1 || outputPaths[0] != "stdout" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,161 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"go.uber.org/zap"
+)
+
+// Message 邮件内容
+type Message struct {
+	To      []string
+	Subject string
+	HTML    string
+	Text    string // 可选的纯文本版本
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New 根据配置创建邮件发送器，未启用时只记录日志不实际发送
+func New(cfg *config.Mailer, logger *zap.Logger) Mailer {
+	if !cfg.Enabled {
+		return &logMailer{logger: logger}
+	}
+	return &smtpMailer{cfg: cfg}
+}
+
+// smtpMailer 基于 SMTP 的邮件发送器，服务器支持时自动使用 STARTTLS
+type smtpMailer struct {
+	cfg *config.Mailer
+}
+
+// Send 发送邮件
+func (m *smtpMailer) Send(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("mail has no recipients")
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	from := mail.Address{Name: m.cfg.FromName, Address: m.cfg.From}
+	body, err := buildMessage(from, msg)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	if err := smtp.SendMail(addr, auth, m.cfg.From, msg.To, body); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// logMailer 开发环境使用的邮件发送器，只记录日志
+type logMailer struct {
+	logger *zap.Logger
+}
+
+// Send 记录邮件内容
+func (m *logMailer) Send(_ context.Context, msg *Message) error {
+	m.logger.Info("Mailer disabled, mail not sent",
+		zap.Strings("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.Int("html_size", len(msg.HTML)),
+	)
+	return nil
+}
+
+// buildMessage 构建 MIME 邮件，同时提供纯文本时使用 multipart/alternative
+func buildMessage(from mail.Address, msg *Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	to := make([]string, len(msg.To))
+	for i, addr := range msg.To {
+		to[i] = (&mail.Address{Address: addr}).String()
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", from.String())
+	header.Set("To", strings.Join(to, ", "))
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID(from.Address))
+	header.Set("MIME-Version", "1.0")
+
+	if msg.Text == "" {
+		header.Set("Content-Type", "text/html; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "8bit")
+		writeHeader(&buf, header)
+		buf.WriteString(msg.HTML)
+		return buf.Bytes(), nil
+	}
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		pw, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"8bit"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := pw.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	header.Set("Content-Type", "multipart/alternative; boundary="+writer.Boundary())
+	writeHeader(&buf, header)
+	buf.Write(body.Bytes())
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for key, values := range header {
+		for _, value := range values {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func messageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain)
+}
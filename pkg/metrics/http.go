@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// UnmatchedRoute 未匹配任何路由的请求使用的 route 标签，避免按原始路径记录导致指标膨胀
+const UnmatchedRoute = "unmatched"
+
+// sizeBuckets 请求体和响应体大小的分桶，100B 到约 100MB
+var sizeBuckets = prometheus.ExponentialBuckets(100, 10, 7)
+
+// HTTPMetrics HTTP 请求指标，按方法、路由模板和状态码记录请求数、耗时和请求体、响应体大小
+type HTTPMetrics struct {
+	requests     *prometheus.CounterVec
+	duration     *prometheus.HistogramVec
+	requestSize  *prometheus.HistogramVec
+	responseSize *prometheus.HistogramVec
+}
+
+// NewHTTPMetrics 创建 HTTP 请求指标并注册到 reg，durationBuckets 为空时使用 prometheus.DefBuckets
+// 已注册过同名指标时复用已有的指标，多次创建路由（如测试）不会失败
+func NewHTTPMetrics(reg prometheus.Registerer, durationBuckets []float64) (*HTTPMetrics, error) {
+	if len(durationBuckets) == 0 {
+		durationBuckets = prometheus.DefBuckets
+	}
+	labels := []string{"method", "route", "status"}
+	m := &HTTPMetrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "Total number of HTTP requests by method, route and status.",
+		}, labels),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency in seconds by method, route and status.",
+			Buckets:   durationBuckets,
+		}, labels),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "request_size_bytes",
+			Help:      "HTTP request body size in bytes by method, route and status.",
+			Buckets:   sizeBuckets,
+		}, labels),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: Namespace,
+			Subsystem: "http",
+			Name:      "response_size_bytes",
+			Help:      "HTTP response body size in bytes by method, route and status.",
+			Buckets:   sizeBuckets,
+		}, labels),
+	}
+
+	var err error
+	if m.requests, err = register(reg, m.requests); err != nil {
+		return nil, err
+	}
+	if m.duration, err = register(reg, m.duration); err != nil {
+		return nil, err
+	}
+	if m.requestSize, err = register(reg, m.requestSize); err != nil {
+		return nil, err
+	}
+	if m.responseSize, err = register(reg, m.responseSize); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Observe 记录一次请求，route 为路由模板（如 /api/v1/users/:id），为空时记为 UnmatchedRoute；大小小于 0 时按 0 记录
+func (m *HTTPMetrics) Observe(method, route string, status int, duration time.Duration, requestSize, responseSize int64) {
+	if route == "" {
+		route = UnmatchedRoute
+	}
+	code := strconv.Itoa(status)
+	m.requests.WithLabelValues(method, route, code).Inc()
+	m.duration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+	m.requestSize.WithLabelValues(method, route, code).Observe(float64(max(requestSize, 0)))
+	m.responseSize.WithLabelValues(method, route, code).Observe(float64(max(responseSize, 0)))
+}
+
+// register 注册指标，已注册同名指标时返回已有的指标
+func register[C prometheus.Collector](reg prometheus.Registerer, c C) (C, error) {
+	if err := reg.Register(c); err != nil {
+		var already prometheus.AlreadyRegisteredError
+		if errors.As(err, &already) {
+			if existing, ok := already.ExistingCollector.(C); ok {
+				return existing, nil
+			}
+		}
+		return c, err
+	}
+	return c, nil
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHTTPMetricsObserve(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewHTTPMetrics(reg, []float64{0.1, 1})
+	if err != nil {
+		t.Fatalf("NewHTTPMetrics failed: %v", err)
+	}
+
+	m.Observe("GET", "/api/v1/users/:id", 200, 50*time.Millisecond, -1, 512)
+	m.Observe("GET", "/api/v1/users/:id", 200, 2*time.Second, 0, 128)
+	m.Observe("GET", "", 404, time.Millisecond, 0, 64)
+
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("GET", "/api/v1/users/:id", "200")); got != 2 {
+		t.Fatalf("requests_total = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(m.requests.WithLabelValues("GET", UnmatchedRoute, "404")); got != 1 {
+		t.Fatalf("unmatched requests_total = %v, want 1", got)
+	}
+	if got := testutil.CollectAndCount(m.duration); got != 2 {
+		t.Fatalf("duration series = %d, want 2", got)
+	}
+}
+
+func TestNewHTTPMetricsReusesRegisteredCollectors(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	first, err := NewHTTPMetrics(reg, nil)
+	if err != nil {
+		t.Fatalf("NewHTTPMetrics failed: %v", err)
+	}
+	second, err := NewHTTPMetrics(reg, nil)
+	if err != nil {
+		t.Fatalf("second NewHTTPMetrics failed: %v", err)
+	}
+
+	second.Observe("POST", "/api/v1/users", 201, time.Millisecond, 10, 10)
+	if got := testutil.ToFloat64(first.requests.WithLabelValues("POST", "/api/v1/users", "201")); got != 1 {
+		t.Fatalf("collectors are not shared, requests_total = %v", got)
+	}
+}
@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace 应用指标的统一前缀
+const Namespace = "skeleton"
+
+// Registry 应用级 Prometheus 注册表，各模块的指标都注册到这里
+var Registry = prometheus.NewRegistry()
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Registerer 返回 Registry，供依赖注入使用
+// 处理器和服务通过注入的 prometheus.Registerer 注册自定义指标，测试时可以传入独立的注册表
+func Registerer() prometheus.Registerer {
+	return Registry
+}
+
+// Handler 返回输出 Registry 中所有指标的 HTTP 处理器
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
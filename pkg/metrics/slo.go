@@ -0,0 +1,303 @@
+package metrics
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+)
+
+// SLI 类型
+const (
+	SLIAvailability = "availability" // 非 5xx 响应的比例
+	SLILatency      = "latency"      // 耗时不超过延迟阈值的请求比例
+)
+
+// defaultSLOWindows 未配置时计算燃烧率的窗口，对应常用的多窗口燃烧率告警 (5m/1h、30m/6h)
+var defaultSLOWindows = []time.Duration{5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour}
+
+// sloBucketsPerWindow 最短窗口划分的桶数，决定燃烧率的时间精度，如 5m 窗口精度为 10s
+const sloBucketsPerWindow = 30
+
+// Objective 单个服务等级目标，Availability 为 0 时不考核可用性，Latency 为 0 时不考核延迟
+type Objective struct {
+	Name          string
+	Availability  float64       // 可用性目标，如 0.999
+	Latency       time.Duration // 延迟阈值
+	LatencyTarget float64       // 耗时不超过 Latency 的请求比例目标，如 0.99，为 0 时使用 Availability
+}
+
+// SLOWindow 单个窗口内的统计
+type SLOWindow struct {
+	Window    string  `json:"window"`
+	Total     uint64  `json:"total"`
+	Bad       uint64  `json:"bad"`
+	ErrorRate float64 `json:"error_rate"`
+	BurnRate  float64 `json:"burn_rate"` // 错误率与错误预算 (1 - 目标) 之比，1 表示恰好在窗口内用完预算
+}
+
+// SLOReport 单个 SLI 的错误预算报告
+type SLOReport struct {
+	Name             string      `json:"name"`
+	SLI              string      `json:"sli"`
+	Objective        float64     `json:"objective"`
+	LatencyThreshold float64     `json:"latency_threshold_seconds,omitempty"`
+	Windows          []SLOWindow `json:"windows"`
+	BudgetRemaining  float64     `json:"error_budget_remaining"` // 最长窗口内剩余的错误预算比例，超支时为负数
+}
+
+// SLOMetrics 按服务等级目标统计请求，计算各窗口的错误预算燃烧率
+// 请求数通过 skeleton_slo_events_total 输出，燃烧率和剩余预算在抓取 /metrics 时按进程内的滑动窗口计算；
+// 多实例部署时各实例分别计算，跨实例告警应基于 skeleton_slo_events_total 聚合
+type SLOMetrics struct {
+	trackers   map[string][]*sloTracker // 按目标名称索引
+	order      []string
+	windows    []time.Duration
+	resolution time.Duration
+	now        func() time.Time
+
+	events *prometheus.CounterVec
+}
+
+// NewSLOMetrics 创建 SLO 指标并注册到 reg，windows 为空时使用 5m、30m、1h、6h
+// 已注册过时复用已有的指标，多次创建路由（如测试）不会失败
+func NewSLOMetrics(reg prometheus.Registerer, objectives []Objective, windows []time.Duration) (*SLOMetrics, error) {
+	m, err := newSLOMetrics(objectives, windows, time.Now)
+	if err != nil {
+		return nil, err
+	}
+
+	// 已注册时沿用已有的采集器及其计数器，保证返回的实例写入的是注册表中的指标
+	collector, err := register(reg, &sloCollector{m: m})
+	if err != nil {
+		return nil, err
+	}
+	if collector.m.events, err = register(reg, collector.m.events); err != nil {
+		return nil, err
+	}
+	return collector.m, nil
+}
+
+func newSLOMetrics(objectives []Objective, windows []time.Duration, now func() time.Time) (*SLOMetrics, error) {
+	if len(windows) == 0 {
+		windows = defaultSLOWindows
+	}
+	windows = slices.Clone(windows)
+	slices.Sort(windows)
+	if windows[0] <= 0 {
+		return nil, fmt.Errorf("slo windows must be positive, got %s", windows[0])
+	}
+	resolution := max(windows[0]/sloBucketsPerWindow, time.Second)
+	size := int(windows[len(windows)-1]/resolution) + 1
+
+	m := &SLOMetrics{
+		trackers:   make(map[string][]*sloTracker, len(objectives)),
+		windows:    windows,
+		resolution: resolution,
+		now:        now,
+		events: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: Namespace,
+			Subsystem: "slo",
+			Name:      "events_total",
+			Help:      "Requests counted against a service level objective, by objective, SLI and result (good or bad).",
+		}, []string{"slo", "sli", "result"}),
+	}
+	for _, o := range objectives {
+		if o.Name == "" {
+			return nil, fmt.Errorf("slo objective name is required")
+		}
+		if _, ok := m.trackers[o.Name]; ok {
+			return nil, fmt.Errorf("duplicate slo objective: %s", o.Name)
+		}
+
+		var trackers []*sloTracker
+		if o.Availability != 0 {
+			if o.Availability <= 0 || o.Availability >= 1 {
+				return nil, fmt.Errorf("slo %s: availability must be between 0 and 1, got %v", o.Name, o.Availability)
+			}
+			trackers = append(trackers, newSLOTracker(SLIAvailability, o.Availability, 0, size))
+		}
+		if o.Latency > 0 {
+			target := o.LatencyTarget
+			if target == 0 {
+				target = o.Availability
+			}
+			if target <= 0 || target >= 1 {
+				return nil, fmt.Errorf("slo %s: latency_target must be between 0 and 1, got %v", o.Name, target)
+			}
+			trackers = append(trackers, newSLOTracker(SLILatency, target, o.Latency, size))
+		}
+		if len(trackers) == 0 {
+			return nil, fmt.Errorf("slo %s: availability or latency is required", o.Name)
+		}
+		m.trackers[o.Name] = trackers
+		m.order = append(m.order, o.Name)
+	}
+	return m, nil
+}
+
+// Observe 记录一次属于目标 name 的请求，5xx 计为可用性失败，耗时超过阈值计为延迟失败；未知目标忽略
+func (m *SLOMetrics) Observe(name string, status int, duration time.Duration) {
+	trackers, ok := m.trackers[name]
+	if !ok {
+		return
+	}
+
+	slot := m.slot(m.now())
+	for _, t := range trackers {
+		bad := false
+		switch t.sli {
+		case SLIAvailability:
+			bad = status >= 500
+		case SLILatency:
+			bad = duration > t.threshold
+		}
+		t.record(slot, bad)
+
+		result := "good"
+		if bad {
+			result = "bad"
+		}
+		m.events.WithLabelValues(name, t.sli, result).Inc()
+	}
+}
+
+// Report 返回所有目标各窗口的燃烧率和剩余错误预算，顺序与配置一致
+func (m *SLOMetrics) Report() []SLOReport {
+	slot := m.slot(m.now())
+
+	var reports []SLOReport
+	for _, name := range m.order {
+		for _, t := range m.trackers[name] {
+			report := SLOReport{
+				Name:      name,
+				SLI:       t.sli,
+				Objective: t.objective,
+				Windows:   make([]SLOWindow, 0, len(m.windows)),
+			}
+			if t.threshold > 0 {
+				report.LatencyThreshold = t.threshold.Seconds()
+			}
+			for _, window := range m.windows {
+				total, bad := t.sum(slot, int64(window/m.resolution))
+				w := SLOWindow{Window: model.Duration(window).String(), Total: total, Bad: bad}
+				if total > 0 {
+					w.ErrorRate = float64(bad) / float64(total)
+					w.BurnRate = w.ErrorRate / (1 - t.objective)
+				}
+				report.Windows = append(report.Windows, w)
+			}
+			report.BudgetRemaining = 1 - report.Windows[len(report.Windows)-1].BurnRate
+			reports = append(reports, report)
+		}
+	}
+	return reports
+}
+
+// slot 返回时间所在的桶序号
+func (m *SLOMetrics) slot(t time.Time) int64 {
+	return t.UnixNano() / int64(m.resolution)
+}
+
+// sloBucket 一个时间桶内的请求数
+type sloBucket struct {
+	slot       int64
+	total, bad uint64
+}
+
+// sloTracker 单个 SLI 的环形时间桶，覆盖最长的窗口
+type sloTracker struct {
+	sli       string
+	objective float64
+	threshold time.Duration
+
+	mu      sync.Mutex
+	buckets []sloBucket
+}
+
+func newSLOTracker(sli string, objective float64, threshold time.Duration, size int) *sloTracker {
+	return &sloTracker{sli: sli, objective: objective, threshold: threshold, buckets: make([]sloBucket, size)}
+}
+
+// record 在桶 slot 中记录一次请求，桶中是已过期的数据时先清空
+func (t *sloTracker) record(slot int64, bad bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	b := &t.buckets[slot%int64(len(t.buckets))]
+	if b.slot != slot {
+		*b = sloBucket{slot: slot}
+	}
+	b.total++
+	if bad {
+		b.bad++
+	}
+}
+
+// sum 汇总截至 slot 的最近 n 个桶
+func (t *sloTracker) sum(slot, n int64) (total, bad uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for s := slot - n + 1; s <= slot; s++ {
+		b := t.buckets[s%int64(len(t.buckets))]
+		if b.slot == s {
+			total += b.total
+			bad += b.bad
+		}
+	}
+	return total, bad
+}
+
+var (
+	sloObjectiveDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "slo", "objective_ratio"),
+		"Target ratio of good events for each service level objective and SLI.",
+		[]string{"slo", "sli"}, nil,
+	)
+	sloLatencyThresholdDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "slo", "latency_threshold_seconds"),
+		"Latency threshold above which a request counts as bad for the latency SLI.",
+		[]string{"slo"}, nil,
+	)
+	sloBurnRateDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "slo", "error_budget_burn_rate"),
+		"Error budget burn rate over a sliding window in this instance: error rate divided by (1 - objective).",
+		[]string{"slo", "sli", "window"}, nil,
+	)
+	sloBudgetRemainingDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(Namespace, "slo", "error_budget_remaining_ratio"),
+		"Fraction of the error budget left over the longest window in this instance, negative when overspent.",
+		[]string{"slo", "sli"}, nil,
+	)
+)
+
+// sloCollector 在抓取时计算燃烧率，避免额外的采样 goroutine
+type sloCollector struct {
+	m *SLOMetrics
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (c *sloCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sloObjectiveDesc
+	ch <- sloLatencyThresholdDesc
+	ch <- sloBurnRateDesc
+	ch <- sloBudgetRemainingDesc
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (c *sloCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, report := range c.m.Report() {
+		ch <- prometheus.MustNewConstMetric(sloObjectiveDesc, prometheus.GaugeValue, report.Objective, report.Name, report.SLI)
+		if report.SLI == SLILatency {
+			ch <- prometheus.MustNewConstMetric(sloLatencyThresholdDesc, prometheus.GaugeValue, report.LatencyThreshold, report.Name)
+		}
+		for _, w := range report.Windows {
+			ch <- prometheus.MustNewConstMetric(sloBurnRateDesc, prometheus.GaugeValue, w.BurnRate, report.Name, report.SLI, w.Window)
+		}
+		ch <- prometheus.MustNewConstMetric(sloBudgetRemainingDesc, prometheus.GaugeValue, report.BudgetRemaining, report.Name, report.SLI)
+	}
+}
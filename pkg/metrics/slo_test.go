@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestSLOMetricsBurnRate(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	m, err := newSLOMetrics([]Objective{
+		{Name: "users", Availability: 0.99, Latency: 300 * time.Millisecond, LatencyTarget: 0.9},
+	}, []time.Duration{time.Hour, 5 * time.Minute}, func() time.Time { return now })
+	if err != nil {
+		t.Fatalf("newSLOMetrics failed: %v", err)
+	}
+
+	// 一小时前的请求只计入 1h 窗口
+	now = now.Add(-50 * time.Minute)
+	for i := 0; i < 90; i++ {
+		m.Observe("users", 200, 100*time.Millisecond)
+	}
+	now = now.Add(50 * time.Minute)
+	for i := 0; i < 8; i++ {
+		m.Observe("users", 200, 500*time.Millisecond)
+	}
+	m.Observe("users", 503, 10*time.Millisecond)
+	m.Observe("users", 200, 10*time.Millisecond)
+	m.Observe("unknown", 500, time.Second)
+
+	reports := m.Report()
+	if len(reports) != 2 || reports[0].SLI != SLIAvailability || reports[1].SLI != SLILatency {
+		t.Fatalf("unexpected reports: %+v", reports)
+	}
+
+	// 窗口按时长排序: 5m、1h
+	availability := reports[0]
+	if w := availability.Windows[0]; w.Window != "5m" || w.Total != 10 || w.Bad != 1 || !near(w.BurnRate, 10) {
+		t.Fatalf("unexpected 5m availability window: %+v", w)
+	}
+	if w := availability.Windows[1]; w.Window != "1h" || w.Total != 100 || !near(w.BurnRate, 1) {
+		t.Fatalf("unexpected 1h availability window: %+v", w)
+	}
+	if !near(availability.BudgetRemaining, 0) {
+		t.Fatalf("budget remaining = %v, want 0", availability.BudgetRemaining)
+	}
+
+	latency := reports[1]
+	if latency.LatencyThreshold != 0.3 || !near(latency.Windows[0].BurnRate, 8) || !near(latency.BudgetRemaining, 0.2) {
+		t.Fatalf("unexpected latency report: %+v", latency)
+	}
+
+	// 窗口滑过后请求不再计入
+	now = now.Add(2 * time.Hour)
+	if w := m.Report()[0].Windows[1]; w.Total != 0 || w.BurnRate != 0 {
+		t.Fatalf("expired requests still counted: %+v", w)
+	}
+}
+
+func TestNewSLOMetricsValidation(t *testing.T) {
+	tests := []struct {
+		name       string
+		objectives []Objective
+	}{
+		{"missing name", []Objective{{Availability: 0.99}}},
+		{"duplicate", []Objective{{Name: "a", Availability: 0.99}, {Name: "a", Availability: 0.9}}},
+		{"availability out of range", []Objective{{Name: "a", Availability: 1}}},
+		{"latency without target", []Objective{{Name: "a", Latency: time.Second}}},
+		{"no sli", []Objective{{Name: "a"}}},
+	}
+	for _, tt := range tests {
+		if _, err := newSLOMetrics(tt.objectives, nil, time.Now); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
+
+func TestSLOMetricsCollect(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	m, err := NewSLOMetrics(reg, []Objective{{Name: "users", Availability: 0.5}}, nil)
+	if err != nil {
+		t.Fatalf("NewSLOMetrics failed: %v", err)
+	}
+	m.Observe("users", 500, time.Millisecond)
+
+	if got := testutil.ToFloat64(m.events.WithLabelValues("users", SLIAvailability, "bad")); got != 1 {
+		t.Fatalf("events_total = %v, want 1", got)
+	}
+	expected := `
+# HELP skeleton_slo_error_budget_burn_rate Error budget burn rate over a sliding window in this instance: error rate divided by (1 - objective).
+# TYPE skeleton_slo_error_budget_burn_rate gauge
+skeleton_slo_error_budget_burn_rate{sli="availability",slo="users",window="1h"} 2
+skeleton_slo_error_budget_burn_rate{sli="availability",slo="users",window="30m"} 2
+skeleton_slo_error_budget_burn_rate{sli="availability",slo="users",window="5m"} 2
+skeleton_slo_error_budget_burn_rate{sli="availability",slo="users",window="6h"} 2
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "skeleton_slo_error_budget_burn_rate"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 重复创建时复用已注册的指标
+	second, err := NewSLOMetrics(reg, []Objective{{Name: "users", Availability: 0.5}}, nil)
+	if err != nil || second != m {
+		t.Fatalf("second NewSLOMetrics = %p, %v; want %p", second, err, m)
+	}
+}
+
+func TestNewSLOMetricsReusesRegisteredCollectors(t *testing.T) {
+	objectives := []Objective{{Name: "users", Availability: 0.5}}
+	reg := prometheus.NewRegistry()
+	if _, err := NewSLOMetrics(reg, objectives, nil); err != nil {
+		t.Fatalf("NewSLOMetrics failed: %v", err)
+	}
+	second, err := NewSLOMetrics(reg, objectives, nil)
+	if err != nil {
+		t.Fatalf("second NewSLOMetrics failed: %v", err)
+	}
+
+	second.Observe("users", 500, time.Millisecond)
+	expected := `
+# HELP skeleton_slo_events_total Requests counted against a service level objective, by objective, SLI and result (good or bad).
+# TYPE skeleton_slo_events_total counter
+skeleton_slo_events_total{result="bad",sli="availability",slo="users"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "skeleton_slo_events_total"); err != nil {
+		t.Fatalf("events are not recorded on the registered counter: %v", err)
+	}
+
+	// 计数器先被注册时，新实例同样写入已注册的计数器
+	reg = prometheus.NewRegistry()
+	fresh, err := newSLOMetrics(objectives, nil, time.Now)
+	if err != nil {
+		t.Fatalf("newSLOMetrics failed: %v", err)
+	}
+	reg.MustRegister(fresh.events)
+	third, err := NewSLOMetrics(reg, objectives, nil)
+	if err != nil {
+		t.Fatalf("NewSLOMetrics with registered events failed: %v", err)
+	}
+	third.Observe("users", 500, time.Millisecond)
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "skeleton_slo_events_total"); err != nil {
+		t.Fatalf("events are not recorded on the registered counter: %v", err)
+	}
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
@@ -0,0 +1,56 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// softDeleteColumn GORM 软删除列
+const softDeleteColumn = "deleted_at"
+
+// CreateActiveUniqueIndex 创建只约束未软删除行的唯一索引，软删除的行不再占用唯一值
+//
+// PostgreSQL、SQLite 使用部分索引 WHERE deleted_at IS NULL；MySQL 不支持部分索引，
+// 使用函数索引（需 8.0.13+），软删除的行索引值为 NULL，而唯一索引允许多个 NULL。
+// 注意 MySQL 上不能用 (column, deleted_at) 复合唯一索引代替：未删除行的 deleted_at 都是 NULL，互不冲突，唯一约束会失效
+func CreateActiveUniqueIndex(tx *gorm.DB, table, name string, columns ...string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("index %s: at least one column is required", name)
+	}
+	return tx.Exec(activeUniqueIndexSQL(tx, table, name, columns)).Error
+}
+
+// activeUniqueIndexSQL 按数据库方言生成建索引语句
+func activeUniqueIndexSQL(tx *gorm.DB, table, name string, columns []string) string {
+	quote := func(identifier string) string {
+		var b strings.Builder
+		tx.QuoteTo(&b, identifier)
+		return b.String()
+	}
+
+	parts := make([]string, len(columns))
+	if tx.Dialector.Name() == "mysql" {
+		for i, column := range columns {
+			parts[i] = fmt.Sprintf("(CASE WHEN %s IS NULL THEN %s END)", quote(softDeleteColumn), quote(column))
+		}
+		return fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", quote(name), quote(table), strings.Join(parts, ", "))
+	}
+
+	for i, column := range columns {
+		parts[i] = quote(column)
+	}
+	return fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s) WHERE %s IS NULL",
+		quote(name), quote(table), strings.Join(parts, ", "), quote(softDeleteColumn))
+}
+
+// DropIndexIfExists 删除索引，索引不存在时忽略
+// model 用于解析表名，例如 &model.User{}
+func DropIndexIfExists(tx *gorm.DB, model interface{}, name string) error {
+	migrator := tx.Migrator()
+	if !migrator.HasIndex(model, name) {
+		return nil
+	}
+	return migrator.DropIndex(model, name)
+}
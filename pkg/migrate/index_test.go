@@ -0,0 +1,42 @@
+package migrate
+
+import (
+	"database/sql"
+	"testing"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func openDialect(t *testing.T, dialector func(*sql.DB) gorm.Dialector) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(dialector(sql.OpenDB(getLockConnector{})), &gorm.Config{DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	return db
+}
+
+func TestActiveUniqueIndexSQL(t *testing.T) {
+	pg := openDialect(t, func(conn *sql.DB) gorm.Dialector {
+		return postgres.New(postgres.Config{Conn: conn})
+	})
+	want := `CREATE UNIQUE INDEX "uk_users_email_active" ON "users" ("email") WHERE "deleted_at" IS NULL`
+	if got := activeUniqueIndexSQL(pg, "users", "uk_users_email_active", []string{"email"}); got != want {
+		t.Errorf("postgres:\n got %s\nwant %s", got, want)
+	}
+
+	my := openDialect(t, func(conn *sql.DB) gorm.Dialector {
+		return mysql.New(mysql.Config{Conn: conn, SkipInitializeWithVersion: true})
+	})
+	want = "CREATE UNIQUE INDEX `uk_users_tenant_email_active` ON `users` " +
+		"((CASE WHEN `deleted_at` IS NULL THEN `tenant_id` END), (CASE WHEN `deleted_at` IS NULL THEN `email` END))"
+	if got := activeUniqueIndexSQL(my, "users", "uk_users_tenant_email_active", []string{"tenant_id", "email"}); got != want {
+		t.Errorf("mysql:\n got %s\nwant %s", got, want)
+	}
+
+	if err := CreateActiveUniqueIndex(pg, "users", "uk_empty"); err == nil {
+		t.Error("index without columns should fail")
+	}
+}
@@ -0,0 +1,87 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 迁移咨询锁，多个实例同时启动时只有持有锁的实例执行迁移
+const (
+	lockName = "skeleton_schema_migrations"
+	// pgLockKey PostgreSQL 咨询锁只接受整数键，业务代码使用咨询锁时避免与之冲突
+	pgLockKey int64 = 7283914206519035
+)
+
+// ErrLockTimeout 等待迁移锁超时
+var ErrLockTimeout = errors.New("timed out waiting for migration lock")
+
+// UpLocked 持有数据库咨询锁执行 Up，未获得锁的实例等待持锁实例完成后再检查待执行迁移，
+// 因此同一批迁移只会执行一次。锁绑定在单个连接上，进程退出连接断开时数据库自动释放
+func (m *Migrator) UpLocked(ctx context.Context, timeout time.Duration) ([]Migration, error) {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying database: %w", err)
+	}
+
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	dialect := m.db.Dialector.Name()
+	if err := acquireLock(ctx, conn, dialect, timeout); err != nil {
+		return nil, err
+	}
+	defer releaseLock(conn, dialect)
+
+	return m.Up(ctx)
+}
+
+// acquireLock 获取咨询锁，MySQL 使用 GET_LOCK，PostgreSQL 使用 pg_advisory_lock
+func acquireLock(ctx context.Context, conn *sql.Conn, dialect string, timeout time.Duration) error {
+	switch dialect {
+	case "mysql":
+		var acquired sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(timeout.Seconds())).Scan(&acquired); err != nil {
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		if !acquired.Valid || acquired.Int64 != 1 {
+			return ErrLockTimeout
+		}
+		return nil
+	case "postgres":
+		lockCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		if _, err := conn.ExecContext(lockCtx, "SELECT pg_advisory_lock($1)", pgLockKey); err != nil {
+			if lockCtx.Err() == context.DeadlineExceeded {
+				return ErrLockTimeout
+			}
+			return fmt.Errorf("failed to acquire migration lock: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("migration lock is not supported for %s", dialect)
+	}
+}
+
+// releaseLock 释放咨询锁；释放失败时丢弃该连接，避免持锁连接回到连接池
+func releaseLock(conn *sql.Conn, dialect string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var err error
+	switch dialect {
+	case "mysql":
+		_, err = conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", lockName)
+	case "postgres":
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", pgLockKey)
+	}
+	if err != nil {
+		_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+}
@@ -0,0 +1,80 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// getLockConnector 模拟 MySQL GET_LOCK 返回固定结果
+type getLockConnector struct{ result driver.Value }
+
+func (c getLockConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return getLockConn(c), nil
+}
+
+func (c getLockConnector) Driver() driver.Driver { return nil }
+
+type getLockConn struct{ result driver.Value }
+
+func (c getLockConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("prepare not supported")
+}
+
+func (c getLockConn) Close() error { return nil }
+
+func (c getLockConn) Begin() (driver.Tx, error) { return nil, errors.New("begin not supported") }
+
+func (c getLockConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return &singleRow{value: c.result}, nil
+}
+
+type singleRow struct {
+	value driver.Value
+	done  bool
+}
+
+func (r *singleRow) Columns() []string { return []string{"result"} }
+
+func (r *singleRow) Close() error { return nil }
+
+func (r *singleRow) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+func lockConn(t *testing.T, result driver.Value) *sql.Conn {
+	t.Helper()
+	conn, err := sql.OpenDB(getLockConnector{result: result}).Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to open connection: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestAcquireLockMySQL(t *testing.T) {
+	if err := acquireLock(context.Background(), lockConn(t, int64(1)), "mysql", time.Second); err != nil {
+		t.Errorf("expected lock acquired, got %v", err)
+	}
+	// GET_LOCK 超时返回 0，出错返回 NULL
+	for _, result := range []driver.Value{int64(0), nil} {
+		if err := acquireLock(context.Background(), lockConn(t, result), "mysql", time.Second); !errors.Is(err, ErrLockTimeout) {
+			t.Errorf("GET_LOCK result %v: expected ErrLockTimeout, got %v", result, err)
+		}
+	}
+}
+
+func TestAcquireLockUnsupportedDialect(t *testing.T) {
+	if err := acquireLock(context.Background(), nil, "sqlite", time.Second); err == nil {
+		t.Error("expected error for unsupported dialect")
+	}
+}
@@ -0,0 +1,133 @@
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 版本化迁移
+type Migration struct {
+	// Version 版本号，按字典序执行，推荐使用时间戳格式 20250101120000
+	Version string `json:"version"`
+	// Name 迁移名称，用于展示
+	Name string `json:"name"`
+	// Up 执行迁移，迁移和版本记录在同一事务中提交
+	Up func(tx *gorm.DB) error `json:"-"`
+}
+
+// Record 已执行的迁移记录
+type Record struct {
+	Version   string    `gorm:"primaryKey;size:32" json:"version"`
+	Name      string    `gorm:"size:255;not null" json:"name"`
+	AppliedAt time.Time `gorm:"not null" json:"applied_at"`
+}
+
+// TableName 迁移记录表名
+func (Record) TableName() string {
+	return "schema_migrations"
+}
+
+// Status 数据源的迁移状态
+type Status struct {
+	// Version 当前数据库结构版本，即最后执行的迁移版本，未执行过迁移时为空
+	Version string      `json:"version"`
+	Applied []Record    `json:"applied"`
+	Pending []Migration `json:"pending"`
+}
+
+// Migrator 迁移执行器
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// New 创建迁移执行器
+func New(db *gorm.DB, migrations []Migration) *Migrator {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+	return &Migrator{db: db, migrations: sorted}
+}
+
+// Status 查询已执行和待执行的迁移
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		Applied: applied,
+		Pending: pending(m.migrations, applied),
+	}
+	if len(applied) > 0 {
+		status.Version = applied[len(applied)-1].Version
+	}
+	return status, nil
+}
+
+// Up 按版本顺序执行所有待执行的迁移，返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	db := m.db.WithContext(ctx)
+	if err := db.AutoMigrate(&Record{}); err != nil {
+		return nil, fmt.Errorf("failed to create migration table: %w", err)
+	}
+
+	status, err := m.Status(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, migration := range status.Pending {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := migration.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&Record{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return status.Pending[:i], fmt.Errorf("failed to apply migration %s_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+	return status.Pending, nil
+}
+
+// applied 读取迁移记录，迁移表不存在时视为未执行任何迁移
+func (m *Migrator) applied(ctx context.Context) ([]Record, error) {
+	db := m.db.WithContext(ctx)
+	if !db.Migrator().HasTable(&Record{}) {
+		return []Record{}, nil
+	}
+
+	var records []Record
+	if err := db.Order("version").Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to load migration records: %w", err)
+	}
+	return records, nil
+}
+
+// pending 计算未执行的迁移，已执行版本之前新增的迁移同样视为待执行
+func pending(migrations []Migration, applied []Record) []Migration {
+	done := make(map[string]struct{}, len(applied))
+	for _, record := range applied {
+		done[record.Version] = struct{}{}
+	}
+
+	result := []Migration{}
+	for _, migration := range migrations {
+		if _, ok := done[migration.Version]; !ok {
+			result = append(result, migration)
+		}
+	}
+	return result
+}
@@ -0,0 +1,23 @@
+package migrate
+
+import (
+	"testing"
+)
+
+func TestPending(t *testing.T) {
+	m := New(nil, []Migration{
+		{Version: "20250301000000", Name: "c"},
+		{Version: "20250101000000", Name: "a"},
+		{Version: "20250201000000", Name: "b"},
+	})
+
+	got := pending(m.migrations, []Record{{Version: "20250101000000"}, {Version: "20250301000000"}})
+	if len(got) != 1 || got[0].Name != "b" {
+		t.Fatalf("expected only b pending, got %+v", got)
+	}
+
+	got = pending(m.migrations, nil)
+	if len(got) != 3 || got[0].Name != "a" || got[2].Name != "c" {
+		t.Fatalf("expected all migrations pending in version order, got %+v", got)
+	}
+}
@@ -0,0 +1,122 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/timing"
+
+	"go.mongodb.org/mongo-driver/v2/event"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// defaultConnectTimeout 建立连接和启动时 Ping 的默认超时
+const defaultConnectTimeout = 10 * time.Second
+
+// Client MongoDB 客户端，绑定配置中的默认数据库
+type Client struct {
+	client   *mongo.Client
+	database *mongo.Database
+}
+
+// New 根据配置创建 MongoDB 客户端并检查连接，appName 显示在服务端的连接信息中
+func New(cfg *config.Mongo, appName string) (*Client, error) {
+	if cfg.URI == "" {
+		return nil, errors.New("mongo uri is required")
+	}
+	if cfg.Database == "" {
+		return nil, errors.New("mongo database is required")
+	}
+
+	client, err := mongo.Connect(clientOptions(cfg, appName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to mongo: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout(cfg))
+	defer cancel()
+	if err := client.Ping(ctx, nil); err != nil {
+		client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to ping mongo: %w", err)
+	}
+
+	return &Client{client: client, database: client.Database(cfg.Database)}, nil
+}
+
+// Client 返回底层驱动客户端，用于事务等需要直接访问驱动的场景
+func (c *Client) Client() *mongo.Client {
+	return c.client
+}
+
+// Database 返回默认数据库
+func (c *Client) Database() *mongo.Database {
+	return c.database
+}
+
+// Collection 返回默认数据库中的集合
+func (c *Client) Collection(name string) *mongo.Collection {
+	return c.database.Collection(name)
+}
+
+// Ping 检查与服务端的连接，用于健康检查和就绪检查
+func (c *Client) Ping(ctx context.Context) error {
+	return c.client.Ping(ctx, nil)
+}
+
+// Close 关闭连接池，等待正在执行的操作完成或 ctx 到期
+func (c *Client) Close(ctx context.Context) error {
+	return c.client.Disconnect(ctx)
+}
+
+// clientOptions 根据配置生成驱动选项，单独配置的用户名和密码覆盖 URI 中的认证信息
+func clientOptions(cfg *config.Mongo, appName string) *options.ClientOptions {
+	opts := options.Client().
+		ApplyURI(cfg.URI).
+		SetAppName(appName).
+		SetConnectTimeout(connectTimeout(cfg)).
+		SetMonitor(commandMonitor())
+
+	if cfg.Username != "" {
+		opts.SetAuth(options.Credential{
+			Username:   cfg.Username,
+			Password:   cfg.Password,
+			AuthSource: cfg.AuthSource,
+		})
+	}
+	if cfg.MinPoolSize > 0 {
+		opts.SetMinPoolSize(cfg.MinPoolSize)
+	}
+	if cfg.MaxPoolSize > 0 {
+		opts.SetMaxPoolSize(cfg.MaxPoolSize)
+	}
+	if cfg.MaxConnIdleTime > 0 {
+		opts.SetMaxConnIdleTime(cfg.MaxConnIdleTime)
+	}
+	if cfg.Timeout > 0 {
+		opts.SetTimeout(cfg.Timeout)
+	}
+	return opts
+}
+
+func connectTimeout(cfg *config.Mongo) time.Duration {
+	if cfg.ConnectTimeout > 0 {
+		return cfg.ConnectTimeout
+	}
+	return defaultConnectTimeout
+}
+
+// commandMonitor 记录请求内的 MongoDB 命令耗时，用于慢请求诊断
+func commandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			timing.Record(ctx, timing.KindMongo, e.CommandName, e.Duration)
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			timing.Record(ctx, timing.KindMongo, e.CommandName, e.Duration)
+		},
+	}
+}
@@ -0,0 +1,223 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// AsyncPublisher 默认参数
+const (
+	defaultAsyncBufferSize    = 10000
+	defaultAsyncBatchSize     = 100
+	defaultAsyncFlushInterval = time.Second
+	defaultAsyncTimeout       = 10 * time.Second
+)
+
+var (
+	// ErrPublishBufferFull 异步发布缓冲已满，消息未入队
+	ErrPublishBufferFull = errors.New("async publish buffer is full")
+	// ErrPublisherClosed 异步发布器已关闭
+	ErrPublisherClosed = errors.New("async publisher is closed")
+)
+
+// asyncPublisherKey 标记由 AsyncPublisher 发起的批量发布，批量指标由 AsyncPublisher 按触发原因记录
+type asyncPublisherKey struct{}
+
+// fromAsyncPublisher 批量发布是否由 AsyncPublisher 发起
+func fromAsyncPublisher(ctx context.Context) bool {
+	return ctx.Value(asyncPublisherKey{}) != nil
+}
+
+// AsyncPublisher 异步批量发布器，用于遥测等允许少量丢失、吞吐优先的生产者
+// Publish 只将消息放入缓冲，后台按 batch_size 和 flush_interval 整批调用 BatchPublisher。
+// 发布失败只记录日志和指标，不重试；需要可靠投递的业务事件应直接使用 Publish 或 PublishBatch
+type AsyncPublisher struct {
+	publisher BatchPublisher
+	logger    *zap.Logger
+	batchSize int
+	interval  time.Duration
+	timeout   time.Duration
+
+	events  chan Event
+	flushes chan chan error
+	closing chan struct{}
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncPublisher 创建异步批量发布器并启动后台发布，退出前需调用 Close 发布缓冲中剩余的消息
+func NewAsyncPublisher(publisher BatchPublisher, logger *zap.Logger, cfg config.AsyncPublish) *AsyncPublisher {
+	p := &AsyncPublisher{
+		publisher: publisher,
+		logger:    logger.Named(LoggerName),
+		batchSize: cfg.BatchSize,
+		interval:  cfg.FlushInterval,
+		timeout:   cfg.Timeout,
+		flushes:   make(chan chan error),
+		closing:   make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	if p.batchSize <= 0 {
+		p.batchSize = defaultAsyncBatchSize
+	}
+	if p.interval <= 0 {
+		p.interval = defaultAsyncFlushInterval
+	}
+	if p.timeout <= 0 {
+		p.timeout = defaultAsyncTimeout
+	}
+	bufferSize := cfg.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = defaultAsyncBufferSize
+	}
+	p.events = make(chan Event, bufferSize)
+
+	go p.run()
+	return p
+}
+
+// Publish 将消息放入缓冲后立即返回，不等待 broker 确认，实现 Publisher 接口，可作为 EventPublisher 的生产者
+// 调用方的链路上下文写入消息头，消费者仍能关联到发起请求的链路；缓冲已满时返回 ErrPublishBufferFull
+func (p *AsyncPublisher) Publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) error {
+	// 复制消息头，避免修改调用方持有的 amqp.Table
+	headers := make(amqp.Table, len(message.Headers)+2)
+	for key, value := range message.Headers {
+		headers[key] = value
+	}
+	propagator.Inject(withRequestTrace(ctx), headerCarrier(headers))
+	message.Headers = headers
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPublisherClosed
+	}
+	select {
+	case p.events <- Event{Exchange: exchange, RoutingKey: routingKey, Message: message}:
+		return nil
+	default:
+		asyncPublishDropped.Inc()
+		return ErrPublishBufferFull
+	}
+}
+
+// Flush 立即发布缓冲中的所有消息并等待完成，返回发布失败的 *BatchError
+func (p *AsyncPublisher) Flush(ctx context.Context) error {
+	reply := make(chan error, 1)
+	select {
+	case p.flushes <- reply:
+	case <-p.done:
+		return ErrPublisherClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-reply:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close 停止接收新消息，发布缓冲中剩余的消息后返回；ctx 结束时不再等待，剩余消息在后台继续发布
+func (p *AsyncPublisher) Close(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.closing)
+	}
+	p.mu.Unlock()
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run 后台发布循环，缓冲达到 batch_size、距上次发布达到 flush_interval、Flush 和 Close 时整批发布
+func (p *AsyncPublisher) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, p.batchSize)
+	flush := func(trigger string) error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := p.publish(trigger, batch)
+		batch = batch[:0]
+		ticker.Reset(p.interval)
+		return err
+	}
+	// drain 取出缓冲中已有的消息，每满 batch_size 发布一批
+	drain := func(trigger string) error {
+		var errs []error
+		for {
+			select {
+			case event := <-p.events:
+				batch = append(batch, event)
+				if len(batch) >= p.batchSize {
+					errs = append(errs, flush(trigger))
+				}
+			default:
+				return errors.Join(append(errs, flush(trigger))...)
+			}
+		}
+	}
+
+	for {
+		select {
+		case event := <-p.events:
+			batch = append(batch, event)
+			if len(batch) >= p.batchSize {
+				flush(batchTriggerSize)
+			}
+		case <-ticker.C:
+			flush(batchTriggerInterval)
+		case reply := <-p.flushes:
+			reply <- drain(batchTriggerFlush)
+		case <-p.closing:
+			// Close 持有写锁后不再有新消息入队，取完缓冲即可退出
+			drain(batchTriggerClose)
+			return
+		}
+	}
+}
+
+// publish 发布一批消息，失败只记录日志
+func (p *AsyncPublisher) publish(trigger string, batch []Event) error {
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), asyncPublisherKey{}, true), p.timeout)
+	defer cancel()
+
+	started := time.Now()
+	err := p.publisher.PublishBatch(ctx, batch)
+	observeBatch(trigger, len(batch), started)
+	if err != nil {
+		failed := len(batch)
+		var batchErr *BatchError
+		if errors.As(err, &batchErr) {
+			failed = batchErr.Failed
+		}
+		p.logger.Warn("Async batch publish failed",
+			zap.String("trigger", trigger),
+			zap.Int("batch_size", len(batch)),
+			zap.Int("failed", failed),
+			zap.Error(err),
+		)
+	}
+	return err
+}
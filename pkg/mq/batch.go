@@ -0,0 +1,229 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/timing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// Event 批量发布中的一条消息
+type Event struct {
+	Exchange   string
+	RoutingKey string
+	Message    amqp.Publishing
+}
+
+// BatchPublisher 批量发布接口，RabbitMQ 生产者和按驱动路由的发布器实现该接口
+type BatchPublisher interface {
+	PublishBatch(ctx context.Context, events []Event) error
+}
+
+// BatchError 批量发布中部分消息失败，Errors 与传入的消息一一对应，发布成功的消息为 nil
+// 可通过 errors.Is 判断其中是否包含 ErrPublishNacked、ErrMessageReturned 等错误
+type BatchError struct {
+	Errors []error
+	Failed int
+}
+
+// Error 实现 error 接口，只包含第一个错误
+func (e *BatchError) Error() string {
+	for _, err := range e.Errors {
+		if err != nil {
+			return fmt.Sprintf("%d of %d messages failed to publish, first error: %v", e.Failed, len(e.Errors), err)
+		}
+	}
+	return fmt.Sprintf("%d of %d messages failed to publish", e.Failed, len(e.Errors))
+}
+
+// Unwrap 返回所有非 nil 的错误
+func (e *BatchError) Unwrap() []error {
+	errs := make([]error, 0, e.Failed)
+	for _, err := range e.Errors {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// newBatchError 没有失败的消息时返回 nil
+func newBatchError(errs []error) error {
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return &BatchError{Errors: errs, Failed: failed}
+}
+
+// PublishBatch 在同一个 confirm 模式的 channel 上发布一批消息，全部发出后统一等待 broker 确认
+// 相比逐条 Publish 省去了每条消息打开 channel 和往返等待确认的开销。每条消息仍有独立的 span 和发布指标；
+// 消息头中已带有链路上下文时（如经过 AsyncPublisher）沿用该链路。部分消息失败时返回 *BatchError
+func (p *Producer) PublishBatch(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	started := time.Now()
+	messages := make([]amqp.Publishing, len(events))
+	spans := make([]oteltrace.Span, len(events))
+	for i, event := range events {
+		messages[i] = event.Message
+		_, spans[i] = startPublishSpan(propagator.Extract(ctx, headerCarrier(event.Message.Headers)), event.Exchange, event.RoutingKey, &messages[i])
+	}
+
+	statuses, errs := p.publishBatch(ctx, events, messages)
+	for i, event := range events {
+		observePublish(event.Exchange, event.RoutingKey, statuses[i], started)
+		endSpan(spans[i], errs[i])
+	}
+	if !fromAsyncPublisher(ctx) {
+		observeBatch(batchTriggerDirect, len(events), started)
+	}
+	timing.Record(ctx, timing.KindMQ, "batch publish "+strconv.Itoa(len(events)), time.Since(started))
+	return newBatchError(errs)
+}
+
+// publishBatch 发布一批消息，返回每条消息用于指标统计的发布结果和错误
+func (p *Producer) publishBatch(ctx context.Context, events []Event, messages []amqp.Publishing) ([]string, []error) {
+	statuses := make([]string, len(events))
+	errs := make([]error, len(events))
+	fail := func(from int, err error) {
+		for i := from; i < len(events); i++ {
+			statuses[i], errs[i] = publishStatusError, err
+		}
+	}
+
+	conn, err := p.connect()
+	if err != nil {
+		fail(0, err)
+		return statuses, errs
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		fail(0, fmt.Errorf("failed to open a channel: %w", err))
+		return statuses, errs
+	}
+	defer ch.Close()
+
+	if err := ch.Confirm(false); err != nil {
+		fail(0, fmt.Errorf("failed to enable publisher confirms: %w", err))
+		return statuses, errs
+	}
+	// 缓冲足够容纳整批消息，broker 退回消息时不会阻塞连接的读取
+	returns := ch.NotifyReturn(make(chan amqp.Return, len(events)))
+
+	confirms := make([]*amqp.DeferredConfirmation, 0, len(events))
+	for i, event := range events {
+		confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, event.Exchange, event.RoutingKey, true, false, messages[i])
+		if err != nil {
+			// 之后的消息不再发布，已发出的消息仍等待确认
+			fail(i, fmt.Errorf("failed to publish message: %w", err))
+			break
+		}
+		confirms = append(confirms, confirm)
+	}
+
+	for i, confirm := range confirms {
+		acked, err := confirm.WaitContext(ctx)
+		switch {
+		case err != nil:
+			statuses[i], errs[i] = publishStatusNacked, fmt.Errorf("failed to wait for publisher confirm: %w", err)
+		case !acked:
+			statuses[i], errs[i] = publishStatusNacked, ErrPublishNacked
+		default:
+			statuses[i] = publishStatusSuccess
+		}
+	}
+
+	// broker 在确认之前投递 basic.return，全部确认后即可得知哪些消息被退回
+	for {
+		select {
+		case ret := <-returns:
+			if i := returnedIndex(events, messages, statuses, ret); i >= 0 {
+				statuses[i], errs[i] = publishStatusReturned, fmt.Errorf("%w: %d %s", ErrMessageReturned, ret.ReplyCode, ret.ReplyText)
+			}
+		default:
+			return statuses, errs
+		}
+	}
+}
+
+// returnedIndex 查找被退回的消息：优先按消息 ID 匹配，没有消息 ID 时取交换机和路由键相同的第一条已确认消息
+func returnedIndex(events []Event, messages []amqp.Publishing, statuses []string, ret amqp.Return) int {
+	for i, event := range events {
+		if statuses[i] != publishStatusSuccess || event.Exchange != ret.Exchange || event.RoutingKey != ret.RoutingKey {
+			continue
+		}
+		if messages[i].MessageId == ret.MessageId {
+			return i
+		}
+	}
+	return -1
+}
+
+// PublishBatch 按交换机所属驱动分组发布，支持批量发布的驱动整组发布，其余逐条发布
+// 部分消息失败时返回 *BatchError，Errors 与传入的消息一一对应
+func (p *routedPublisher) PublishBatch(ctx context.Context, events []Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	groups := make(map[string][]int)
+	var drivers []string
+	for i, event := range events {
+		driver := p.cfg.ExchangeDriver(event.Exchange)
+		if _, ok := groups[driver]; !ok {
+			drivers = append(drivers, driver)
+		}
+		groups[driver] = append(groups[driver], i)
+	}
+
+	errs := make([]error, len(events))
+	for _, driver := range drivers {
+		indices := groups[driver]
+		publisher, ok := p.publishers[driver]
+		if !ok {
+			for _, i := range indices {
+				errs[i] = brokerNotFound(driver, events[i].Exchange)
+			}
+			continue
+		}
+
+		batch, ok := publisher.(BatchPublisher)
+		if !ok {
+			for _, i := range indices {
+				errs[i] = publisher.Publish(ctx, events[i].Exchange, events[i].RoutingKey, events[i].Message)
+			}
+			continue
+		}
+
+		group := make([]Event, len(indices))
+		for j, i := range indices {
+			group[j] = events[i]
+		}
+		err := batch.PublishBatch(ctx, group)
+		var batchErr *BatchError
+		for j, i := range indices {
+			switch {
+			case err == nil:
+			case errors.As(err, &batchErr):
+				errs[i] = batchErr.Errors[j]
+			default:
+				errs[i] = err
+			}
+		}
+	}
+	return newBatchError(errs)
+}
@@ -0,0 +1,203 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// recordingBatchPublisher 记录每批消息，failKey 路由键的消息发布失败
+type recordingBatchPublisher struct {
+	mu      sync.Mutex
+	batches [][]Event
+	failKey string
+}
+
+func (p *recordingBatchPublisher) Publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) error {
+	return p.PublishBatch(ctx, []Event{{Exchange: exchange, RoutingKey: routingKey, Message: message}})
+}
+
+func (p *recordingBatchPublisher) PublishBatch(ctx context.Context, events []Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.batches = append(p.batches, append([]Event(nil), events...))
+	errs := make([]error, len(events))
+	for i, event := range events {
+		if event.RoutingKey == p.failKey {
+			errs[i] = ErrPublishNacked
+		}
+	}
+	return newBatchError(errs)
+}
+
+func (p *recordingBatchPublisher) sizes() []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sizes := make([]int, len(p.batches))
+	for i, batch := range p.batches {
+		sizes[i] = len(batch)
+	}
+	return sizes
+}
+
+// singlePublisher 只支持逐条发布
+type singlePublisher struct {
+	published []string
+}
+
+func (p *singlePublisher) Publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) error {
+	p.published = append(p.published, routingKey)
+	return nil
+}
+
+func TestRoutedPublisherPublishBatch(t *testing.T) {
+	cfg := &config.RabbitMQ{Queues: []config.QueueConfig{
+		{Name: "hello.queue", Exchange: "hello.exchange", RoutingKeys: []string{"hello"}},
+		{Name: "audit.queue", Exchange: "audit.exchange", RoutingKeys: []string{"audit"}, Driver: config.DriverRedis},
+		{Name: "metrics.queue", Exchange: "metrics.exchange", RoutingKeys: []string{"metrics"}, Driver: config.DriverNATS},
+	}}
+	rabbit := &recordingBatchPublisher{failKey: "bad"}
+	streams := &singlePublisher{}
+	p := &routedPublisher{cfg: cfg, publishers: map[string]Publisher{
+		config.DriverRabbitMQ: rabbit,
+		config.DriverRedis:    streams,
+	}}
+
+	events := []Event{
+		{Exchange: "hello.exchange", RoutingKey: "hello"},
+		{Exchange: "audit.exchange", RoutingKey: "audit"},
+		{Exchange: "hello.exchange", RoutingKey: "bad"},
+		{Exchange: "metrics.exchange", RoutingKey: "metrics"},
+	}
+	err := p.PublishBatch(context.Background(), events)
+
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Failed != 2 {
+		t.Fatalf("expected 2 failures, got %v", err)
+	}
+	if batchErr.Errors[0] != nil || batchErr.Errors[1] != nil {
+		t.Errorf("successful messages reported as failed: %v", batchErr.Errors)
+	}
+	if !errors.Is(batchErr.Errors[2], ErrPublishNacked) || !errors.Is(batchErr.Errors[3], ErrBrokerNotFound) {
+		t.Errorf("unexpected errors: %v", batchErr.Errors)
+	}
+	if !errors.Is(err, ErrPublishNacked) {
+		t.Error("BatchError should unwrap to the individual errors")
+	}
+	if sizes := rabbit.sizes(); len(sizes) != 1 || sizes[0] != 2 {
+		t.Errorf("rabbitmq events should be published in one batch, got %v", sizes)
+	}
+	if len(streams.published) != 1 || streams.published[0] != "audit" {
+		t.Errorf("redis events should be published one by one, got %v", streams.published)
+	}
+}
+
+func TestReturnedIndex(t *testing.T) {
+	events := []Event{
+		{Exchange: "ex", RoutingKey: "a"},
+		{Exchange: "ex", RoutingKey: "b"},
+		{Exchange: "ex", RoutingKey: "b"},
+	}
+	messages := []amqp.Publishing{{MessageId: "1"}, {MessageId: "2"}, {MessageId: "3"}}
+	statuses := []string{publishStatusSuccess, publishStatusSuccess, publishStatusSuccess}
+
+	if i := returnedIndex(events, messages, statuses, amqp.Return{Exchange: "ex", RoutingKey: "b", MessageId: "3"}); i != 2 {
+		t.Errorf("returnedIndex by message id = %d, want 2", i)
+	}
+	if i := returnedIndex(events, messages, statuses, amqp.Return{Exchange: "ex", RoutingKey: "a", MessageId: "3"}); i != -1 {
+		t.Errorf("returnedIndex with mismatched routing key = %d, want -1", i)
+	}
+}
+
+func TestAsyncPublisher(t *testing.T) {
+	target := &recordingBatchPublisher{}
+	p := NewAsyncPublisher(target, zap.NewNop(), config.AsyncPublish{BufferSize: 10, BatchSize: 3, FlushInterval: time.Hour})
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		if err := p.Publish(ctx, "ex", "key", amqp.Publishing{Headers: amqp.Table{"n": i}}); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+	if err := p.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	// 达到 batch_size 的 3 条整批发布，剩余 1 条由 Flush 发布
+	if sizes := target.sizes(); len(sizes) != 2 || sizes[0] != 3 || sizes[1] != 1 {
+		t.Fatalf("batch sizes = %v, want [3 1]", sizes)
+	}
+
+	if err := p.Publish(ctx, "ex", "key", amqp.Publishing{}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := p.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if sizes := target.sizes(); len(sizes) != 3 || sizes[2] != 1 {
+		t.Fatalf("remaining message should be published on close, batch sizes = %v", sizes)
+	}
+	if err := p.Publish(ctx, "ex", "key", amqp.Publishing{}); !errors.Is(err, ErrPublisherClosed) {
+		t.Errorf("Publish after Close = %v, want ErrPublisherClosed", err)
+	}
+}
+
+func TestAsyncPublisherInterval(t *testing.T) {
+	target := &recordingBatchPublisher{failKey: "key"}
+	p := NewAsyncPublisher(target, zap.NewNop(), config.AsyncPublish{BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+	defer p.Close(context.Background())
+
+	if err := p.Publish(context.Background(), "ex", "key", amqp.Publishing{}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for len(target.sizes()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("message not published after flush_interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAsyncPublisherBufferFull(t *testing.T) {
+	block := make(chan struct{})
+	target := &blockingBatchPublisher{release: block, started: make(chan struct{})}
+	p := NewAsyncPublisher(target, zap.NewNop(), config.AsyncPublish{BufferSize: 1, BatchSize: 1, FlushInterval: time.Hour})
+	ctx := context.Background()
+
+	// 第一条被后台取出并阻塞在发布中，第二条占满缓冲
+	if err := p.Publish(ctx, "ex", "key", amqp.Publishing{}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	<-target.started
+	if err := p.Publish(ctx, "ex", "key", amqp.Publishing{}); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if err := p.Publish(ctx, "ex", "key", amqp.Publishing{}); !errors.Is(err, ErrPublishBufferFull) {
+		t.Fatalf("expected ErrPublishBufferFull, got %v", err)
+	}
+
+	close(block)
+	if err := p.Close(ctx); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+}
+
+// blockingBatchPublisher 第一次发布时阻塞直到 release 关闭
+type blockingBatchPublisher struct {
+	release <-chan struct{}
+	started chan struct{}
+	once    sync.Once
+}
+
+func (p *blockingBatchPublisher) PublishBatch(ctx context.Context, events []Event) error {
+	p.once.Do(func() { close(p.started) })
+	<-p.release
+	return nil
+}
@@ -0,0 +1,321 @@
+package mq
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// 连接用途，生产和消费使用独立连接，避免消费端触发的流控阻塞发布
+const (
+	RoleProducer = "producer"
+	RoleConsumer = "consumer"
+)
+
+// 连接参数默认值，与 amqp.Dial 保持一致
+const (
+	defaultHeartbeat   = 10 * time.Second
+	defaultDialTimeout = 30 * time.Second
+)
+
+// ErrBrokerNotFound 引用了未配置的 broker
+var ErrBrokerNotFound = errors.New("rabbitmq broker not found")
+
+// Dial 根据 broker 配置建立连接，连接名称会显示在 RabbitMQ 管理界面中
+func Dial(name, role string, cfg config.Broker) (*amqp.Connection, error) {
+	amqpCfg := amqp.Config{
+		Heartbeat:  cfg.Heartbeat,
+		Locale:     "en_US",
+		Properties: amqp.NewConnectionProperties(),
+	}
+	if amqpCfg.Heartbeat <= 0 {
+		amqpCfg.Heartbeat = defaultHeartbeat
+	}
+	dialTimeout := cfg.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = defaultDialTimeout
+	}
+	amqpCfg.Dial = amqp.DefaultDial(dialTimeout)
+	amqpCfg.Properties.SetClientConnectionName(fmt.Sprintf("%s-%s-%d", name, role, os.Getpid()))
+
+	if cfg.TLS.Enabled {
+		if !strings.HasPrefix(cfg.URL, "amqps://") {
+			return nil, fmt.Errorf("broker %s: tls requires an amqps:// url", name)
+		}
+		tlsCfg, err := tlsConfig(cfg.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("broker %s: %w", name, err)
+		}
+		amqpCfg.TLSClientConfig = tlsCfg
+	}
+
+	return amqp.DialConfig(cfg.URL, amqpCfg)
+}
+
+// tlsConfig 根据配置加载 CA 和客户端证书
+func tlsConfig(cfg config.BrokerTLS) (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		ServerName:         cfg.ServerName,
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+		MinVersion:         tls.VersionTLS12,
+	}
+
+	if cfg.CAFile != "" {
+		ca, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in ca file %s", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsCfg, nil
+}
+
+// Brokers 管理所有命名 broker 的连接
+// 每个 broker 按用途维护生产和消费两条连接，首次使用时建立，连接断开后下次获取时重新建立
+type Brokers struct {
+	configs map[string]config.Broker
+
+	mu    sync.Mutex
+	conns map[string]*amqp.Connection // name/role -> connection
+
+	// producers 各 broker 当前的生产连接，Ping 读取时不需要等待 mu（Dial 期间持有 mu）
+	producers    map[string]*atomic.Pointer[amqp.Connection]
+	reconnecting sync.Map // 正在后台重连的 broker 名称
+}
+
+// NewBrokers 根据配置创建 broker 连接管理器，并立即建立 default broker 的生产连接
+// 交换机或队列引用了未配置的 broker 时返回错误；driver 为 redis 或 nats 的队列及其交换机不需要 broker
+func NewBrokers(cfg *config.RabbitMQ) (*Brokers, error) {
+	b := newBrokers(cfg.BrokerConfigs())
+
+	for _, exchange := range cfg.Exchanges {
+		if cfg.ExchangeDriver(exchange.Name) != config.DriverRabbitMQ {
+			continue
+		}
+		if _, ok := b.configs[exchange.BrokerName()]; !ok {
+			return nil, fmt.Errorf("exchange %s: %w: %s", exchange.Name, ErrBrokerNotFound, exchange.BrokerName())
+		}
+	}
+	for _, queue := range cfg.Queues {
+		switch queue.DriverName() {
+		case config.DriverRabbitMQ, config.DriverRedis, config.DriverNATS:
+		default:
+			return nil, fmt.Errorf("queue %s: unsupported driver %q", queue.Name, queue.Driver)
+		}
+		// 同一交换机只能由一种驱动路由
+		if driver := cfg.ExchangeDriver(queue.Exchange); queue.Exchange != "" && driver != queue.DriverName() {
+			return nil, fmt.Errorf("queue %s: exchange %s is bound to %s queues", queue.Name, queue.Exchange, driver)
+		}
+		if queue.DriverName() != config.DriverRabbitMQ {
+			continue
+		}
+		if _, ok := b.configs[queue.BrokerName()]; !ok {
+			return nil, fmt.Errorf("queue %s: %w: %s", queue.Name, ErrBrokerNotFound, queue.BrokerName())
+		}
+	}
+
+	if _, ok := b.configs[config.DefaultBroker]; ok {
+		if _, err := b.Connection(config.DefaultBroker, RoleProducer); err != nil {
+			return nil, err
+		}
+	}
+
+	return b, nil
+}
+
+func newBrokers(configs map[string]config.Broker) *Brokers {
+	b := &Brokers{
+		configs:   configs,
+		conns:     make(map[string]*amqp.Connection),
+		producers: make(map[string]*atomic.Pointer[amqp.Connection], len(configs)),
+	}
+	for name := range configs {
+		b.producers[name] = new(atomic.Pointer[amqp.Connection])
+	}
+	return b
+}
+
+// Names 返回所有已配置的 broker 名称
+func (b *Brokers) Names() []string {
+	names := make([]string, 0, len(b.configs))
+	for name := range b.configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Has 判断是否配置了指定 broker
+func (b *Brokers) Has(name string) bool {
+	_, ok := b.configs[name]
+	return ok
+}
+
+// Connection 返回指定 broker 和用途的连接
+func (b *Brokers) Connection(name, role string) (*amqp.Connection, error) {
+	cfg, ok := b.configs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBrokerNotFound, name)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := name + "/" + role
+	if conn, ok := b.conns[key]; ok && !conn.IsClosed() {
+		return conn, nil
+	}
+
+	conn, err := Dial(name, role, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to broker %s: %w", name, err)
+	}
+	b.conns[key] = conn
+	if role == RoleProducer {
+		b.producers[name].Store(conn)
+	}
+	return conn, nil
+}
+
+// Producer 返回使用指定 broker 生产连接的生产者，连接断开后下次发布时自动重连
+func (b *Brokers) Producer(name string) (*Producer, error) {
+	if _, ok := b.configs[name]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBrokerNotFound, name)
+	}
+	return &Producer{connect: func() (*amqp.Connection, error) {
+		return b.Connection(name, RoleProducer)
+	}}, nil
+}
+
+// Ping 在 broker 的生产连接上打开并关闭一个 channel，用于健康检查
+// 连接未建立或已断开时立即返回错误，并在后台重新连接（同一 broker 同时只有一次），不阻塞调用方；
+// 打开 channel 超过 ctx 时返回 ctx 的错误，未完成的操作在连接心跳超时后结束
+func (b *Brokers) Ping(ctx context.Context, name string) error {
+	producer, ok := b.producers[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrBrokerNotFound, name)
+	}
+	conn := producer.Load()
+	if conn == nil || conn.IsClosed() {
+		b.reconnect(name)
+		return fmt.Errorf("broker %s is not connected", name)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		ch, err := conn.Channel()
+		if err == nil {
+			err = ch.Close()
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to open channel on broker %s: %w", name, err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// reconnect 在后台重新建立 broker 的生产连接，已有重连在进行时直接返回
+func (b *Brokers) reconnect(name string) {
+	if _, running := b.reconnecting.LoadOrStore(name, struct{}{}); running {
+		return
+	}
+	go func() {
+		defer b.reconnecting.Delete(name)
+		_, _ = b.Connection(name, RoleProducer)
+	}()
+}
+
+// InspectQueue 被动声明队列，返回队列中的消息数和消费者数，队列不存在时返回错误
+// 被动声明失败时 broker 会关闭 channel，每次检查使用独立的 channel
+func (b *Brokers) InspectQueue(name, queue string) (amqp.Queue, error) {
+	ch, err := b.channel(name)
+	if err != nil {
+		return amqp.Queue{}, err
+	}
+	defer ch.Close()
+
+	q, err := ch.QueueDeclarePassive(queue, false, false, false, false, nil)
+	if err != nil {
+		return amqp.Queue{}, fmt.Errorf("failed to inspect queue %s on broker %s: %w", queue, name, err)
+	}
+	return q, nil
+}
+
+// InspectExchange 被动声明交换机，交换机不存在时返回错误
+func (b *Brokers) InspectExchange(name, exchange, kind string) error {
+	ch, err := b.channel(name)
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	if err := ch.ExchangeDeclarePassive(exchange, kind, false, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to inspect exchange %s on broker %s: %w", exchange, name, err)
+	}
+	return nil
+}
+
+// channel 在 broker 的生产连接上打开 channel
+func (b *Brokers) channel(name string) (*amqp.Channel, error) {
+	conn, err := b.Connection(name, RoleProducer)
+	if err != nil {
+		return nil, err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open channel on broker %s: %w", name, err)
+	}
+	return ch, nil
+}
+
+// Close 关闭所有连接
+func (b *Brokers) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var errs []error
+	for key, conn := range b.conns {
+		if !conn.IsClosed() {
+			if err := conn.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("failed to close %s: %w", key, err))
+			}
+		}
+		delete(b.conns, key)
+	}
+	for _, producer := range b.producers {
+		producer.Store(nil)
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,112 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// 转发到死信交换机时附加的消息头
+const (
+	HeaderDeadLetterReason   = "x-dead-letter-reason"
+	HeaderDeadLetterError    = "x-dead-letter-error"
+	HeaderOriginalExchange   = "x-original-exchange"
+	HeaderOriginalRoutingKey = "x-original-routing-key"
+	HeaderOriginalQueue      = "x-original-queue"
+)
+
+// deadLetterPublishTimeout 转发死信消息或停放消息的超时时间
+const deadLetterPublishTimeout = 5 * time.Second
+
+// DeadLetterError 处理函数返回该错误时消息不再重试
+// 队列配置了死信交换机时，消息连同 Headers 转发到死信交换机并确认原消息，否则直接拒绝
+type DeadLetterError struct {
+	Reason  string
+	Headers amqp.Table
+	Err     error
+}
+
+// Error 实现 error 接口
+func (e *DeadLetterError) Error() string {
+	return e.Reason + ": " + e.Err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *DeadLetterError) Unwrap() error {
+	return e.Err
+}
+
+// DeadLetter 包装错误，标记消息应转入死信队列
+// reason 写入 x-dead-letter-reason 消息头，headers 为附加到死信消息上的诊断信息
+func DeadLetter(err error, reason string, headers amqp.Table) error {
+	return &DeadLetterError{Reason: reason, Headers: headers, Err: err}
+}
+
+// deadLetterTarget 队列的死信转发目标
+type deadLetterTarget struct {
+	exchange   string
+	routingKey string
+}
+
+// deadLetter 将消息转发到队列配置的死信交换机并确认原消息
+// 未配置死信交换机、没有可用的生产者或转发失败时拒绝消息且不重新入队，由 broker 按队列的 x-dead-letter-exchange 参数处理
+func (c *Consumer) deadLetter(ctx context.Context, queue string, d amqp.Delivery, dlErr *DeadLetterError) {
+	c.mu.Lock()
+	target, ok := c.deadLetters[queue]
+	c.mu.Unlock()
+	if !ok || c.producer == nil {
+		d.Nack(false, false)
+		return
+	}
+
+	headers := make(amqp.Table, len(d.Headers)+len(dlErr.Headers)+5)
+	for key, value := range d.Headers {
+		headers[key] = value
+	}
+	for key, value := range dlErr.Headers {
+		headers[key] = value
+	}
+	headers[HeaderDeadLetterReason] = dlErr.Reason
+	headers[HeaderDeadLetterError] = dlErr.Err.Error()
+	headers[HeaderOriginalExchange] = d.Exchange
+	headers[HeaderOriginalRoutingKey] = d.RoutingKey
+	headers[HeaderOriginalQueue] = queue
+
+	if err := c.forward(ctx, target.exchange, target.routingKey, d, headers); err != nil {
+		d.Nack(false, false)
+		return
+	}
+	d.Ack(false)
+}
+
+// forward 将消息连同 headers 以持久化方式转发到 exchange，routingKey 为空时沿用消息原路由键
+func (c *Consumer) forward(ctx context.Context, exchange, routingKey string, d amqp.Delivery, headers amqp.Table) error {
+	if routingKey == "" {
+		routingKey = d.RoutingKey
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, deadLetterPublishTimeout)
+	defer cancel()
+
+	return c.producer.Publish(ctx, exchange, routingKey, amqp.Publishing{
+		Headers:         headers,
+		ContentType:     d.ContentType,
+		ContentEncoding: d.ContentEncoding,
+		DeliveryMode:    amqp.Persistent,
+		MessageId:       d.MessageId,
+		Timestamp:       d.Timestamp,
+		Type:            d.Type,
+		Body:            d.Body,
+	})
+}
+
+// asDeadLetter 判断处理函数返回的错误是否要求转入死信队列
+func asDeadLetter(err error) (*DeadLetterError, bool) {
+	var dlErr *DeadLetterError
+	if errors.As(err, &dlErr) {
+		return dlErr, true
+	}
+	return nil, false
+}
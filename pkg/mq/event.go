@@ -0,0 +1,191 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/clock"
+	"github.com/hedeqiang/skeleton/pkg/idgen"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// DefaultEventVersion 未指定版本时事件信封使用的版本号
+const DefaultEventVersion = "1"
+
+// Envelope 业务消息信封，生产者和消费者共用同一结构
+type Envelope struct {
+	MessageID   string          `json:"message_id"`
+	MessageType string          `json:"message_type"`
+	Payload     json.RawMessage `json:"payload"` // 使用 RawMessage 延迟解析
+	Timestamp   int64           `json:"timestamp"`
+	Source      string          `json:"source,omitempty"`
+	Version     string          `json:"version,omitempty"`
+}
+
+// GetMessageType 返回消息类型
+func (e *Envelope) GetMessageType() string {
+	return e.MessageType
+}
+
+// GetMessageID 返回消息 ID
+func (e *Envelope) GetMessageID() string {
+	return e.MessageID
+}
+
+// UnmarshalPayload 解析消息载荷到具体结构
+func (e *Envelope) UnmarshalPayload(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// EventOption 发布事件的可选参数
+type EventOption func(*eventOptions)
+
+type eventOptions struct {
+	messageID  string
+	version    string
+	headers    amqp.Table
+	expiration time.Duration
+	transient  bool
+}
+
+// WithMessageID 使用指定的消息 ID，默认由 ID 生成器生成
+func WithMessageID(id string) EventOption {
+	return func(o *eventOptions) {
+		o.messageID = id
+	}
+}
+
+// WithEventVersion 设置事件载荷的版本号
+func WithEventVersion(version string) EventOption {
+	return func(o *eventOptions) {
+		o.version = version
+	}
+}
+
+// WithHeaders 附加 AMQP 消息头
+func WithHeaders(headers amqp.Table) EventOption {
+	return func(o *eventOptions) {
+		o.headers = headers
+	}
+}
+
+// WithExpiration 设置消息过期时间，过期后由 broker 丢弃或转入死信队列
+func WithExpiration(ttl time.Duration) EventOption {
+	return func(o *eventOptions) {
+		o.expiration = ttl
+	}
+}
+
+// WithTransient 以非持久化方式投递，broker 重启后消息丢失
+func WithTransient() EventOption {
+	return func(o *eventOptions) {
+		o.transient = true
+	}
+}
+
+// EventPublisher 业务事件发布器，统一构造消息信封、生成消息 ID 并以持久化方式投递
+type EventPublisher struct {
+	producer    Publisher
+	idGenerator idgen.IDGenerator
+	source      string
+	clock       clock.Clock
+	schemas     *SchemaRegistry
+}
+
+// EventPublisherOption 事件发布器的可选参数
+type EventPublisherOption func(*EventPublisher)
+
+// WithSchemaRegistry 发布前按消息类型和版本声明的 schema 校验载荷
+func WithSchemaRegistry(schemas *SchemaRegistry) EventPublisherOption {
+	return func(p *EventPublisher) {
+		p.schemas = schemas
+	}
+}
+
+// NewEventPublisher 创建事件发布器，source 写入信封的 source 字段和 AMQP app_id，消息时间戳取自 clk
+func NewEventPublisher(producer Publisher, idGenerator idgen.IDGenerator, clk clock.Clock, source string, opts ...EventPublisherOption) *EventPublisher {
+	p := &EventPublisher{
+		producer:    producer,
+		idGenerator: idGenerator,
+		source:      source,
+		clock:       clk,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// PublishEvent 将载荷包装为 Envelope 发布到指定交换机，返回消息 ID
+// 配置了 schema 注册表时，载荷与声明的 schema 不一致或版本未声明时不发布，返回 *SchemaError
+func (p *EventPublisher) PublishEvent(ctx context.Context, exchange, routingKey, messageType string, payload interface{}, opts ...EventOption) (string, error) {
+	msg, err := p.newPublishing(messageType, payload, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.producer.Publish(ctx, exchange, routingKey, msg); err != nil {
+		return "", fmt.Errorf("failed to publish %s event: %w", messageType, err)
+	}
+	return msg.MessageId, nil
+}
+
+// newPublishing 构造事件信封和 AMQP 消息
+func (p *EventPublisher) newPublishing(messageType string, payload interface{}, opts ...EventOption) (amqp.Publishing, error) {
+	options := eventOptions{version: DefaultEventVersion}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	messageID := options.messageID
+	if messageID == "" {
+		id, err := p.idGenerator.NextIDString()
+		if err != nil {
+			return amqp.Publishing{}, fmt.Errorf("failed to generate message id: %w", err)
+		}
+		messageID = id
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return amqp.Publishing{}, fmt.Errorf("failed to marshal %s payload: %w", messageType, err)
+	}
+	if err := p.schemas.Validate(messageType, options.version, data); err != nil {
+		return amqp.Publishing{}, err
+	}
+
+	now := p.clock.Now()
+	body, err := json.Marshal(&Envelope{
+		MessageID:   messageID,
+		MessageType: messageType,
+		Payload:     data,
+		Timestamp:   now.Unix(),
+		Source:      p.source,
+		Version:     options.version,
+	})
+	if err != nil {
+		return amqp.Publishing{}, fmt.Errorf("failed to marshal message envelope: %w", err)
+	}
+
+	msg := amqp.Publishing{
+		Headers:      options.headers,
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent,
+		MessageId:    messageID,
+		Timestamp:    now,
+		Type:         messageType,
+		AppId:        p.source,
+		Body:         body,
+	}
+	if options.transient {
+		msg.DeliveryMode = amqp.Transient
+	}
+	if options.expiration > 0 {
+		msg.Expiration = strconv.FormatInt(options.expiration.Milliseconds(), 10)
+	}
+	return msg, nil
+}
@@ -0,0 +1,71 @@
+package mq
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/clock"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+type staticIDGenerator struct{}
+
+func (staticIDGenerator) NextID() (int64, error)        { return 42, nil }
+func (staticIDGenerator) NextIDString() (string, error) { return "42", nil }
+
+func TestEventPublisherNewPublishing(t *testing.T) {
+	clk := clock.Frozen()
+	publisher := NewEventPublisher(nil, staticIDGenerator{}, clk, "skeleton")
+
+	msg, err := publisher.newPublishing("hello", map[string]string{"content": "hi"})
+	if err != nil {
+		t.Fatalf("newPublishing failed: %v", err)
+	}
+	if msg.MessageId != "42" || msg.Type != "hello" || msg.AppId != "skeleton" {
+		t.Fatalf("unexpected publishing: %+v", msg)
+	}
+	if msg.DeliveryMode != amqp.Persistent || msg.ContentType != "application/json" {
+		t.Fatalf("event should be persistent json: %+v", msg)
+	}
+	if !msg.Timestamp.Equal(clk.Now()) {
+		t.Fatalf("timestamp = %v, want %v", msg.Timestamp, clk.Now())
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(msg.Body, &envelope); err != nil {
+		t.Fatalf("invalid envelope: %v", err)
+	}
+	if envelope.MessageID != "42" || envelope.MessageType != "hello" || envelope.Source != "skeleton" ||
+		envelope.Version != DefaultEventVersion || envelope.Timestamp != clk.Now().Unix() {
+		t.Fatalf("unexpected envelope: %+v", envelope)
+	}
+
+	var payload map[string]string
+	if err := envelope.UnmarshalPayload(&payload); err != nil || payload["content"] != "hi" {
+		t.Fatalf("unexpected payload: %v, %v", payload, err)
+	}
+}
+
+func TestEventPublisherOptions(t *testing.T) {
+	publisher := NewEventPublisher(nil, staticIDGenerator{}, clock.Frozen(), "skeleton")
+
+	msg, err := publisher.newPublishing("hello", struct{}{},
+		WithMessageID("custom"),
+		WithEventVersion("2"),
+		WithExpiration(time.Minute),
+		WithTransient(),
+	)
+	if err != nil {
+		t.Fatalf("newPublishing failed: %v", err)
+	}
+	if msg.MessageId != "custom" || msg.Expiration != "60000" || msg.DeliveryMode != amqp.Transient {
+		t.Fatalf("options not applied: %+v", msg)
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(msg.Body, &envelope); err != nil || envelope.Version != "2" {
+		t.Fatalf("unexpected envelope: %+v, %v", envelope, err)
+	}
+}
@@ -0,0 +1,93 @@
+package mq
+
+import (
+	"context"
+	"errors"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// HeaderParkedError 停放消息携带的处理错误
+const HeaderParkedError = "x-parked-error"
+
+// DropError 处理函数返回该错误时确认并丢弃消息，不重试也不转入死信队列
+type DropError struct {
+	Err error
+}
+
+// Error 实现 error 接口
+func (e *DropError) Error() string {
+	return "dropped: " + e.Err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *DropError) Unwrap() error {
+	return e.Err
+}
+
+// Drop 包装错误，标记消息应确认并丢弃，用于尽力而为的消息
+func Drop(err error) error {
+	return &DropError{Err: err}
+}
+
+// ParkError 处理函数返回该错误时消息转发到停放交换机并确认原消息，等待人工处理后重放
+type ParkError struct {
+	Exchange   string
+	RoutingKey string // 为空时沿用消息原路由键
+	Err        error
+}
+
+// Error 实现 error 接口
+func (e *ParkError) Error() string {
+	return "parked: " + e.Err.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *ParkError) Unwrap() error {
+	return e.Err
+}
+
+// Park 包装错误，标记消息应转发到 exchange 停放
+func Park(err error, exchange, routingKey string) error {
+	return &ParkError{Exchange: exchange, RoutingKey: routingKey, Err: err}
+}
+
+// park 将消息转发到停放交换机并确认原消息
+// 没有可用的生产者或转发失败时重新入队，避免丢失需要人工处理的消息
+func (c *Consumer) park(ctx context.Context, queue string, d amqp.Delivery, parkErr *ParkError) bool {
+	if c.producer == nil {
+		d.Nack(false, true)
+		return false
+	}
+
+	headers := make(amqp.Table, len(d.Headers)+4)
+	for key, value := range d.Headers {
+		headers[key] = value
+	}
+	headers[HeaderParkedError] = parkErr.Err.Error()
+	headers[HeaderOriginalExchange] = d.Exchange
+	headers[HeaderOriginalRoutingKey] = d.RoutingKey
+	headers[HeaderOriginalQueue] = queue
+
+	if err := c.forward(ctx, parkErr.Exchange, parkErr.RoutingKey, d, headers); err != nil {
+		d.Nack(false, true)
+		return false
+	}
+	d.Ack(false)
+	return true
+}
+
+// asDrop 判断处理函数返回的错误是否要求丢弃消息
+func asDrop(err error) bool {
+	var dropErr *DropError
+	return errors.As(err, &dropErr)
+}
+
+// asPark 判断处理函数返回的错误是否要求停放消息
+func asPark(err error) (*ParkError, bool) {
+	var parkErr *ParkError
+	if errors.As(err, &parkErr) {
+		return parkErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,53 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestHandleDeliveryDrop(t *testing.T) {
+	var outcome string
+	c := &Consumer{ctx: context.Background(), observer: func(queue string, d amqp.Delivery, o string, err error) {
+		outcome = o
+	}}
+	ack := &recordingAcknowledger{}
+
+	c.handleDelivery("analytics", amqp.Delivery{Acknowledger: ack}, func(ctx context.Context, body []byte) error {
+		return Drop(errors.New("tracker unavailable"))
+	})
+	if !ack.acked || ack.nacked || outcome != OutcomeDropped {
+		t.Fatalf("dropped message should be acked: %+v, outcome = %q", ack, outcome)
+	}
+}
+
+func TestHandleDeliveryPark(t *testing.T) {
+	var outcome string
+	producer := &recordingPublisher{}
+	c := &Consumer{ctx: context.Background(), producer: producer, observer: func(queue string, d amqp.Delivery, o string, err error) {
+		outcome = o
+	}}
+	ack := &recordingAcknowledger{}
+
+	c.handleDelivery("orders", amqp.Delivery{Acknowledger: ack, RoutingKey: "created"}, func(ctx context.Context, body []byte) error {
+		return Park(errors.New("payment gateway rejected"), "orders.parking", "")
+	})
+	if !ack.acked || ack.nacked || outcome != OutcomeParked {
+		t.Fatalf("parked message should be acked: %+v, outcome = %q", ack, outcome)
+	}
+	if len(producer.exchanges) != 1 || producer.exchanges[0] != "orders.parking" {
+		t.Fatalf("parked message published to %v, want orders.parking", producer.exchanges)
+	}
+
+	// 没有可用的生产者时重新入队，不丢失消息
+	c = &Consumer{ctx: context.Background(), observer: c.observer}
+	ack = &recordingAcknowledger{}
+	c.handleDelivery("orders", amqp.Delivery{Acknowledger: ack}, func(ctx context.Context, body []byte) error {
+		return Park(errors.New("payment gateway rejected"), "orders.parking", "")
+	})
+	if ack.acked || !ack.nacked || !ack.requeue || outcome != OutcomeRequeued {
+		t.Fatalf("message that cannot be parked should be requeued: %+v, outcome = %q", ack, outcome)
+	}
+}
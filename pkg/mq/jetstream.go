@@ -0,0 +1,579 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/timing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// NATS JetStream 配置默认值
+const (
+	defaultNATSStream        = "MQ"
+	defaultNATSSubjectPrefix = "mq"
+	defaultNATSAckWait       = 30 * time.Second
+	defaultNATSBatchSize     = 10
+	defaultNATSFetchWait     = 5 * time.Second
+
+	// natsSetupTimeout 创建 stream 和消费者的超时时间
+	natsSetupTimeout = 10 * time.Second
+)
+
+// deadSubjectToken 死信 subject 为 <subject_prefix>.dead.<队列名>，该 subject 同样保存在 stream 中
+const deadSubjectToken = "dead"
+
+// 消息属性对应的 NATS 消息头，消息 ID 使用 Nats-Msg-Id 以便 JetStream 按 ID 去重
+const (
+	natsHeaderType        = "X-Message-Type"
+	natsHeaderContentType = "Content-Type"
+	natsHeaderAppID       = "X-App-Id"
+	natsHeaderTimestamp   = "X-Timestamp"
+	natsHeaderExchange    = "X-Exchange"
+	natsHeaderRoutingKey  = "X-Routing-Key"
+	natsHeaderHeaders     = "X-Amqp-Headers"
+
+	// natsHeaderOriginalMessageID 转入死信 subject 时保存原消息 ID
+	natsHeaderOriginalMessageID = "X-Original-Message-Id"
+)
+
+// natsOptions 规范化后的 NATS 配置
+type natsOptions struct {
+	stream     string
+	prefix     string
+	ackWait    time.Duration
+	maxDeliver int
+	batchSize  int
+	fetchWait  time.Duration
+}
+
+func newNATSOptions(cfg *config.NATS) natsOptions {
+	o := natsOptions{
+		stream:     cfg.Stream,
+		prefix:     strings.TrimSuffix(cfg.SubjectPrefix, "."),
+		ackWait:    cfg.AckWait,
+		maxDeliver: cfg.MaxDeliver,
+		batchSize:  cfg.BatchSize,
+		fetchWait:  cfg.FetchWait,
+	}
+	if o.stream == "" {
+		o.stream = defaultNATSStream
+	}
+	if o.prefix == "" {
+		o.prefix = defaultNATSSubjectPrefix
+	}
+	if o.ackWait <= 0 {
+		o.ackWait = defaultNATSAckWait
+	}
+	if o.batchSize <= 0 {
+		o.batchSize = defaultNATSBatchSize
+	}
+	if o.fetchWait <= 0 {
+		o.fetchWait = defaultNATSFetchWait
+	}
+	return o
+}
+
+// subject 返回交换机和路由键对应的 subject，路由键中的 . 分隔为多个 token
+func (o natsOptions) subject(exchange, routingKey string) string {
+	if exchange == "" {
+		return o.prefix + "." + routingKey
+	}
+	return o.prefix + "." + exchange + "." + routingKey
+}
+
+// filterSubject 将队列绑定的路由键转换为消费者过滤 subject，topic 通配符 # 转换为 >，* 保持不变
+func (o natsOptions) filterSubject(exchange, routingKey string) (string, error) {
+	tokens := strings.Split(routingKey, ".")
+	for i, token := range tokens {
+		if token == "#" {
+			if i != len(tokens)-1 {
+				return "", fmt.Errorf("routing key %s: # is only supported as the last word", routingKey)
+			}
+			tokens[i] = ">"
+		}
+	}
+	return o.subject(exchange, strings.Join(tokens, ".")), nil
+}
+
+// deadSubject 返回队列的死信 subject
+func (o natsOptions) deadSubject(queue string) string {
+	return o.prefix + "." + deadSubjectToken + "." + queue
+}
+
+// JetStream NATS 连接和 JetStream 上下文，生产者和消费者共用
+type JetStream struct {
+	conn *nats.Conn
+	js   jetstream.JetStream
+	opts natsOptions
+}
+
+// NewJetStream 连接 NATS 并创建或更新 stream，stream 订阅 <subject_prefix>.> 下的所有 subject
+// name 为连接名称，cfg.Name 非空时以其为准
+func NewJetStream(cfg *config.NATS, name string) (*JetStream, error) {
+	if cfg.Name != "" {
+		name = cfg.Name
+	}
+	natsOpts := []nats.Option{
+		nats.Name(name),
+		nats.MaxReconnects(-1), // 断开后持续重连
+	}
+	if cfg.CredsFile != "" {
+		natsOpts = append(natsOpts, nats.UserCredentials(cfg.CredsFile))
+	}
+
+	conn, err := nats.Connect(cfg.URL, natsOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to nats: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create jetstream context: %w", err)
+	}
+
+	opts := newNATSOptions(cfg)
+	ctx, cancel := context.WithTimeout(context.Background(), natsSetupTimeout)
+	defer cancel()
+
+	replicas := cfg.Replicas
+	if replicas <= 0 {
+		replicas = 1
+	}
+	_, err = js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      opts.stream,
+		Subjects:  []string{opts.prefix + ".>"},
+		Storage:   jetstream.FileStorage,
+		Retention: jetstream.LimitsPolicy,
+		MaxAge:    cfg.MaxAge,
+		Replicas:  replicas,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create stream %s: %w", opts.stream, err)
+	}
+
+	return &JetStream{conn: conn, js: js, opts: opts}, nil
+}
+
+// Ping 查询 JetStream 账户信息，用于健康检查
+func (j *JetStream) Ping(ctx context.Context) error {
+	_, err := j.js.AccountInfo(ctx)
+	return err
+}
+
+// Close 处理完已收到的消息后关闭连接
+func (j *JetStream) Close() error {
+	return j.conn.Drain()
+}
+
+// JetStreamProducer 将消息发布到 NATS JetStream
+// 交换机和路由键映射为 subject，只有 subject 匹配某个 nats 队列的过滤 subject 时才发布
+type JetStreamProducer struct {
+	js      *JetStream
+	filters map[string][]string // exchange -> 绑定队列的过滤 subject
+}
+
+// NewJetStreamProducer 创建 JetStream 生产者，只路由 driver 为 nats 的队列；js 为 nil 时返回 nil
+func NewJetStreamProducer(js *JetStream, mqCfg *config.RabbitMQ) (*JetStreamProducer, error) {
+	if js == nil {
+		return nil, nil
+	}
+	p := &JetStreamProducer{js: js, filters: make(map[string][]string)}
+	for _, queue := range mqCfg.Queues {
+		if queue.DriverName() != config.DriverNATS {
+			continue
+		}
+		filters, err := js.opts.queueFilters(queue)
+		if err != nil {
+			return nil, err
+		}
+		p.filters[queue.Exchange] = append(p.filters[queue.Exchange], filters...)
+	}
+	return p, nil
+}
+
+// Publish 发布消息并等待 JetStream 确认，没有匹配的队列时返回 ErrMessageReturned
+func (p *JetStreamProducer) Publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) error {
+	ctx, span := startPublishSpan(ctx, exchange, routingKey, &message)
+
+	started := time.Now()
+	status, err := p.publish(ctx, exchange, routingKey, message)
+	observePublish(exchange, routingKey, status, started)
+	timing.Record(ctx, timing.KindMQ, exchangeName(exchange)+" "+routingKey, time.Since(started))
+	endSpan(span, err)
+	return err
+}
+
+// publish 发布消息，返回用于指标统计的发布结果
+func (p *JetStreamProducer) publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) (string, error) {
+	subject := p.js.opts.subject(exchange, routingKey)
+	if !p.routable(exchange, subject) {
+		return publishStatusReturned, fmt.Errorf("%w: no nats queue bound to %s with routing key %s", ErrMessageReturned, exchangeName(exchange), routingKey)
+	}
+
+	msg, err := natsMessage(subject, exchange, routingKey, message)
+	if err != nil {
+		return publishStatusError, err
+	}
+	if _, err := p.js.js.PublishMsg(ctx, msg); err != nil {
+		if errors.Is(err, jetstream.ErrNoStreamResponse) {
+			return publishStatusNacked, fmt.Errorf("%w: %v", ErrPublishNacked, err)
+		}
+		return publishStatusError, fmt.Errorf("failed to publish message: %w", err)
+	}
+	return publishStatusSuccess, nil
+}
+
+// routable 判断 subject 是否匹配交换机上某个队列的过滤 subject
+func (p *JetStreamProducer) routable(exchange, subject string) bool {
+	for _, filter := range p.filters[exchange] {
+		if subjectMatches(filter, subject) {
+			return true
+		}
+	}
+	return false
+}
+
+// queueFilters 返回队列所有路由键对应的过滤 subject
+func (o natsOptions) queueFilters(queue config.QueueConfig) ([]string, error) {
+	filters := make([]string, 0, len(queue.RoutingKeys))
+	for _, routingKey := range queue.RoutingKeys {
+		filter, err := o.filterSubject(queue.Exchange, routingKey)
+		if err != nil {
+			return nil, fmt.Errorf("queue %s: %w", queue.Name, err)
+		}
+		filters = append(filters, filter)
+	}
+	return filters, nil
+}
+
+// subjectMatches 按 NATS 通配符规则判断 subject 是否匹配 filter
+func subjectMatches(filter, subject string) bool {
+	filterTokens := strings.Split(filter, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, token := range filterTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) || (token != "*" && token != subjectTokens[i]) {
+			return false
+		}
+	}
+	return len(filterTokens) == len(subjectTokens)
+}
+
+// natsMessage 将 AMQP 消息转换为 NATS 消息，消息 ID 写入 Nats-Msg-Id 用于去重
+func natsMessage(subject, exchange, routingKey string, message amqp.Publishing) (*nats.Msg, error) {
+	msg := nats.NewMsg(subject)
+	msg.Data = message.Body
+	msg.Header.Set(natsHeaderExchange, exchange)
+	msg.Header.Set(natsHeaderRoutingKey, routingKey)
+	if message.MessageId != "" {
+		msg.Header.Set(nats.MsgIdHdr, message.MessageId)
+	}
+	if message.Type != "" {
+		msg.Header.Set(natsHeaderType, message.Type)
+	}
+	if message.ContentType != "" {
+		msg.Header.Set(natsHeaderContentType, message.ContentType)
+	}
+	if message.AppId != "" {
+		msg.Header.Set(natsHeaderAppID, message.AppId)
+	}
+	if !message.Timestamp.IsZero() {
+		msg.Header.Set(natsHeaderTimestamp, strconv.FormatInt(message.Timestamp.UnixMilli(), 10))
+	}
+	if len(message.Headers) > 0 {
+		headers, err := json.Marshal(message.Headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal message headers: %w", err)
+		}
+		msg.Header.Set(natsHeaderHeaders, string(headers))
+	}
+	return msg, nil
+}
+
+// natsDelivery 将 JetStream 消息转换为 amqp.Delivery，deliveries 为该消息的投递次数
+// 消息头中的数字经过 JSON 编码后统一为 float64
+func natsDelivery(header nats.Header, data []byte, deliveries uint64, ack amqp.Acknowledger) amqp.Delivery {
+	d := amqp.Delivery{
+		Acknowledger: ack,
+		MessageId:    header.Get(nats.MsgIdHdr),
+		Type:         header.Get(natsHeaderType),
+		ContentType:  header.Get(natsHeaderContentType),
+		AppId:        header.Get(natsHeaderAppID),
+		Exchange:     header.Get(natsHeaderExchange),
+		RoutingKey:   header.Get(natsHeaderRoutingKey),
+		Body:         data,
+		Redelivered:  deliveries > 1,
+	}
+	if ms, err := strconv.ParseInt(header.Get(natsHeaderTimestamp), 10, 64); err == nil {
+		d.Timestamp = time.UnixMilli(ms)
+	}
+	if raw := header.Get(natsHeaderHeaders); raw != "" {
+		var headers amqp.Table
+		if json.Unmarshal([]byte(raw), &headers) == nil {
+			d.Headers = headers
+		}
+	}
+	return d
+}
+
+// jetStreamAcknowledger 确认或拒绝一条 JetStream 消息
+// 重新入队的 Nack 执行 NAK 由服务端立即重新投递；不重新入队的 Nack 以及最后一次投递仍失败时，
+// 将消息复制到死信 subject 后 TERM，服务端不再投递
+type jetStreamAcknowledger struct {
+	js    *JetStream
+	msg   jetstream.Msg
+	queue string
+	final bool
+	onErr func(error)
+}
+
+// Ack 确认消息
+func (a *jetStreamAcknowledger) Ack(tag uint64, multiple bool) error {
+	return a.report(a.msg.Ack())
+}
+
+// Nack 拒绝消息
+func (a *jetStreamAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	if requeue && !a.final {
+		return a.report(a.msg.Nak())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deadLetterPublishTimeout)
+	defer cancel()
+
+	deadSubject := a.js.opts.deadSubject(a.queue)
+	dead := nats.NewMsg(deadSubject)
+	dead.Data = a.msg.Data()
+	for key, values := range a.msg.Headers() {
+		// 保留原消息 ID 会被 JetStream 当作重复消息丢弃
+		if key != nats.MsgIdHdr {
+			dead.Header[key] = values
+		}
+	}
+	dead.Header.Set(HeaderOriginalQueue, a.queue)
+	if id := a.msg.Headers().Get(nats.MsgIdHdr); id != "" {
+		dead.Header.Set(natsHeaderOriginalMessageID, id)
+	}
+	if _, err := a.js.js.PublishMsg(ctx, dead); err != nil {
+		// 转入死信失败时交还服务端，超过最大投递次数后由服务端停止投递
+		a.msg.Nak()
+		return a.report(fmt.Errorf("failed to publish to dead letter subject %s: %w", deadSubject, err))
+	}
+	return a.report(a.msg.Term())
+}
+
+// Reject 拒绝消息
+func (a *jetStreamAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func (a *jetStreamAcknowledger) report(err error) error {
+	if err != nil && a.onErr != nil {
+		a.onErr(fmt.Errorf("failed to acknowledge nats message: %w", err))
+	}
+	return err
+}
+
+// JetStreamConsumer 通过 durable pull 消费者消费 JetStream 中的消息
+// 每个队列对应一个 durable 消费者，多个进程共享同一 durable 消费者分摊消息；处理、超时、死信和观察者的行为与 RabbitMQ 消费者一致
+type JetStreamConsumer struct {
+	js       *JetStream
+	consumer *Consumer
+	onError  func(queue string, err error)
+
+	mu        sync.Mutex
+	consumers map[string]jetstream.Consumer
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewJetStreamConsumer 创建 JetStream 消费者，支持 RabbitMQ 消费者的工作池、根 context、处理超时、
+// 死信生产者和观察者选项；onError 非空时接收拉取和确认失败的错误，拉取失败后消费者会自动重试
+func NewJetStreamConsumer(js *JetStream, onError func(queue string, err error), opts ...ConsumerOption) *JetStreamConsumer {
+	c := &Consumer{
+		broker: config.DefaultBroker,
+		ctx:    context.Background(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return &JetStreamConsumer{
+		js:        js,
+		consumer:  c,
+		onError:   onError,
+		consumers: make(map[string]jetstream.Consumer),
+		stop:      make(chan struct{}),
+	}
+}
+
+// SetupInfrastructureFromConfig 为 driver 为 nats 的队列创建或更新 durable 消费者，并登记死信转发目标
+// 消费者按队列的路由键过滤 subject，使用显式确认，ack_wait 和 max_deliver 取自配置
+func (c *JetStreamConsumer) SetupInfrastructureFromConfig(cfg *config.RabbitMQ) error {
+	ctx, cancel := context.WithTimeout(c.consumer.ctx, natsSetupTimeout)
+	defer cancel()
+
+	opts := c.js.opts
+	maxDeliver := opts.maxDeliver
+	if maxDeliver <= 0 {
+		maxDeliver = -1
+	}
+
+	for _, queueCfg := range cfg.Queues {
+		if queueCfg.DriverName() != config.DriverNATS {
+			continue
+		}
+		maxAckPending := 0
+		if workers := c.consumer.workerPool(queueCfg.Name); workers != nil {
+			maxAckPending = workers.Capacity()
+		}
+		filters, err := opts.queueFilters(queueCfg)
+		if err != nil {
+			return err
+		}
+
+		consumerCfg := jetstream.ConsumerConfig{
+			Durable:       durableName(queueCfg.Name),
+			AckPolicy:     jetstream.AckExplicitPolicy,
+			AckWait:       opts.ackWait,
+			MaxDeliver:    maxDeliver,
+			MaxAckPending: maxAckPending,
+		}
+		// 单个过滤 subject 兼容不支持 filter_subjects 的旧版服务端
+		if len(filters) == 1 {
+			consumerCfg.FilterSubject = filters[0]
+		} else {
+			consumerCfg.FilterSubjects = filters
+		}
+
+		consumer, err := c.js.js.CreateOrUpdateConsumer(ctx, opts.stream, consumerCfg)
+		if err != nil {
+			return fmt.Errorf("failed to create consumer for queue %s: %w", queueCfg.Name, err)
+		}
+		c.mu.Lock()
+		c.consumers[queueCfg.Name] = consumer
+		c.mu.Unlock()
+
+		if queueCfg.DeadLetterExchange != "" {
+			c.consumer.SetDeadLetter(queueCfg.Name, queueCfg.DeadLetterExchange, queueCfg.DeadLetterRoutingKey)
+		}
+	}
+	return nil
+}
+
+// Consume 开始消费消息，阻塞直到调用 Cancel 或根 context 取消
+// consumerName 不使用，同一队列的所有进程共享 durable 消费者
+func (c *JetStreamConsumer) Consume(queueName, consumerName string, handler MessageHandler) error {
+	c.mu.Lock()
+	consumer, ok := c.consumers[queueName]
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("nats consumer for queue %s is not set up", queueName)
+	}
+
+	for !c.stopped() {
+		batch, err := consumer.Fetch(c.js.opts.batchSize, jetstream.FetchMaxWait(c.js.opts.fetchWait))
+		if err != nil {
+			c.reportError(queueName, fmt.Errorf("failed to fetch messages: %w", err))
+			c.wait(streamRetryInterval)
+			continue
+		}
+		for msg := range batch.Messages() {
+			c.dispatch(queueName, msg, handler)
+		}
+		if err := batch.Error(); err != nil && !errors.Is(err, nats.ErrTimeout) && !c.stopped() {
+			c.reportError(queueName, fmt.Errorf("failed to fetch messages: %w", err))
+			c.wait(streamRetryInterval)
+		}
+	}
+	return nil
+}
+
+// dispatch 将消息交给处理函数，使用工作池时在工作池满时阻塞
+func (c *JetStreamConsumer) dispatch(queue string, msg jetstream.Msg, handler MessageHandler) {
+	var deliveries uint64 = 1
+	if meta, err := msg.Metadata(); err == nil {
+		deliveries = meta.NumDelivered
+	}
+	maxDeliver := c.js.opts.maxDeliver
+	ack := &jetStreamAcknowledger{
+		js:    c.js,
+		msg:   msg,
+		queue: queue,
+		final: maxDeliver > 0 && deliveries >= uint64(maxDeliver),
+		onErr: func(err error) { c.reportError(queue, err) },
+	}
+
+	d := natsDelivery(msg.Headers(), msg.Data(), deliveries, ack)
+	workers := c.consumer.workerPool(queue)
+	if workers == nil {
+		c.consumer.handleDelivery(queue, d, handler)
+		return
+	}
+	if err := workers.Submit(c.consumer.ctx, func() { c.consumer.handleDelivery(queue, d, handler) }); err != nil {
+		// 工作池已关闭或消费者已停止，交还服务端重新投递
+		msg.Nak()
+	}
+}
+
+func (c *JetStreamConsumer) reportError(queue string, err error) {
+	if c.onError != nil {
+		c.onError(queue, err)
+	}
+}
+
+// stopped 判断是否已调用 Cancel 或根 context 已取消
+func (c *JetStreamConsumer) stopped() bool {
+	select {
+	case <-c.stop:
+		return true
+	case <-c.consumer.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// wait 等待 d 或消费者停止
+func (c *JetStreamConsumer) wait(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-c.stop:
+	case <-c.consumer.ctx.Done():
+	}
+}
+
+// Cancel 停止拉取新消息，正在等待的拉取最多在 fetch_wait 后返回，已投递的消息仍可正常确认
+func (c *JetStreamConsumer) Cancel() error {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+	return nil
+}
+
+// Close 关闭消费者，NATS 连接由调用方管理
+func (c *JetStreamConsumer) Close() error {
+	return c.Cancel()
+}
+
+// durableName 将队列名转换为合法的 durable 消费者名称
+func durableName(queue string) string {
+	return strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_").Replace(queue)
+}
@@ -0,0 +1,131 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"github.com/nats-io/nats.go"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestNATSSubjectMapping(t *testing.T) {
+	opts := newNATSOptions(&config.NATS{SubjectPrefix: "app."})
+
+	if got := opts.subject("order.events", "order.created"); got != "app.order.events.order.created" {
+		t.Errorf("subject = %s", got)
+	}
+	if got := opts.subject("", "jobs"); got != "app.jobs" {
+		t.Errorf("default exchange subject = %s", got)
+	}
+	if got := opts.deadSubject("order.audit"); got != "app.dead.order.audit" {
+		t.Errorf("dead subject = %s", got)
+	}
+
+	for routingKey, want := range map[string]string{
+		"order.created": "app.order.events.order.created",
+		"order.*":       "app.order.events.order.*",
+		"order.#":       "app.order.events.order.>",
+		"#":             "app.order.events.>",
+	} {
+		got, err := opts.filterSubject("order.events", routingKey)
+		if err != nil || got != want {
+			t.Errorf("filterSubject(%s) = %s, %v, want %s", routingKey, got, err, want)
+		}
+	}
+	if _, err := opts.filterSubject("order.events", "#.created"); err == nil {
+		t.Error("expected error for # in the middle of a routing key")
+	}
+}
+
+func TestSubjectMatches(t *testing.T) {
+	for _, tc := range []struct {
+		filter, subject string
+		want            bool
+	}{
+		{"mq.orders.created", "mq.orders.created", true},
+		{"mq.orders.*", "mq.orders.created", true},
+		{"mq.orders.*", "mq.orders.created.eu", false},
+		{"mq.orders.>", "mq.orders.created.eu", true},
+		{"mq.orders.>", "mq.orders", false},
+		{"mq.orders.created", "mq.orders", false},
+	} {
+		if got := subjectMatches(tc.filter, tc.subject); got != tc.want {
+			t.Errorf("subjectMatches(%s, %s) = %v, want %v", tc.filter, tc.subject, got, tc.want)
+		}
+	}
+}
+
+func TestNATSDeliveryRoundTrip(t *testing.T) {
+	sent := amqp.Publishing{
+		Headers:     amqp.Table{"x-tenant": "acme"},
+		ContentType: "application/json",
+		MessageId:   "1001",
+		Type:        "hello",
+		AppId:       "skeleton",
+		Timestamp:   time.UnixMilli(1700000000123),
+		Body:        []byte(`{"message_id":"1001"}`),
+	}
+	msg, err := natsMessage("mq.hello.exchange.hello", "hello.exchange", "hello", sent)
+	if err != nil {
+		t.Fatalf("natsMessage failed: %v", err)
+	}
+	if msg.Header.Get(nats.MsgIdHdr) != "1001" {
+		t.Errorf("Nats-Msg-Id = %s", msg.Header.Get(nats.MsgIdHdr))
+	}
+
+	d := natsDelivery(msg.Header, msg.Data, 1, nil)
+	if d.MessageId != sent.MessageId || d.Type != sent.Type || d.ContentType != sent.ContentType || d.AppId != sent.AppId {
+		t.Errorf("properties = %+v", d)
+	}
+	if d.Exchange != "hello.exchange" || d.RoutingKey != "hello" {
+		t.Errorf("exchange = %s, routing key = %s", d.Exchange, d.RoutingKey)
+	}
+	if string(d.Body) != string(sent.Body) || !d.Timestamp.Equal(sent.Timestamp) {
+		t.Errorf("body = %s, timestamp = %v", d.Body, d.Timestamp)
+	}
+	if d.Headers["x-tenant"] != "acme" {
+		t.Errorf("headers = %v", d.Headers)
+	}
+	if d.Redelivered {
+		t.Error("first delivery should not be marked redelivered")
+	}
+}
+
+func TestJetStreamProducerRoutable(t *testing.T) {
+	mqCfg := &config.RabbitMQ{
+		Queues: []config.QueueConfig{
+			{Name: "order.audit", Exchange: "order.events", RoutingKeys: []string{"order.#"}, Driver: config.DriverNATS},
+			{Name: "hello.queue", Exchange: "hello.exchange", RoutingKeys: []string{"hello"}},
+		},
+	}
+	p, err := NewJetStreamProducer(&JetStream{opts: newNATSOptions(&config.NATS{})}, mqCfg)
+	if err != nil {
+		t.Fatalf("NewJetStreamProducer failed: %v", err)
+	}
+
+	if !p.routable("order.events", p.js.opts.subject("order.events", "order.created")) {
+		t.Error("order.created should be routed to order.audit")
+	}
+	if p.routable("hello.exchange", p.js.opts.subject("hello.exchange", "hello")) {
+		t.Error("rabbitmq queue should not be routed")
+	}
+
+	err = p.Publish(context.Background(), "order.events", "user.created", amqp.Publishing{})
+	if !errors.Is(err, ErrMessageReturned) {
+		t.Errorf("unroutable publish error = %v, want ErrMessageReturned", err)
+	}
+
+	if p, err := NewJetStreamProducer(nil, mqCfg); p != nil || err != nil {
+		t.Errorf("nil JetStream should yield nil producer, got %v, %v", p, err)
+	}
+}
+
+func TestDurableName(t *testing.T) {
+	if got := durableName("order.audit"); got != "order_audit" {
+		t.Errorf("durableName = %s", got)
+	}
+}
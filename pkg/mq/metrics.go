@@ -0,0 +1,113 @@
+package mq
+
+import (
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 消息发布结果
+const (
+	publishStatusSuccess  = "success"
+	publishStatusError    = "error"
+	publishStatusNacked   = "nacked"
+	publishStatusReturned = "returned"
+)
+
+var (
+	publishTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "publish_total",
+		Help:      "Total number of published messages by exchange, routing key and result.",
+	}, []string{"exchange", "routing_key", "status"})
+
+	publishDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "publish_duration_seconds",
+		Help:      "Latency of publishing a message until it is confirmed by the broker.",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, []string{"exchange", "routing_key"})
+
+	publishConfirmFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "publish_confirm_failures_total",
+		Help:      "Total number of messages nacked by the broker or not confirmed in time.",
+	}, []string{"exchange", "routing_key"})
+
+	publishReturned = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "publish_returned_total",
+		Help:      "Total number of unroutable messages returned by the broker.",
+	}, []string{"exchange", "routing_key"})
+
+	publishBatchSize = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "publish_batch_size",
+		Help:      "Number of messages per batch publish by flush trigger.",
+		Buckets:   []float64{1, 5, 10, 25, 50, 100, 250, 500, 1000},
+	}, []string{"trigger"})
+
+	publishBatchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "publish_batch_duration_seconds",
+		Help:      "Latency of publishing a batch until all messages are confirmed by the broker.",
+		Buckets:   []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5},
+	}, []string{"trigger"})
+
+	asyncPublishDropped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "mq",
+		Name:      "async_publish_dropped_total",
+		Help:      "Total number of messages rejected because the async publish buffer was full.",
+	})
+)
+
+// 触发批量发布的原因
+const (
+	batchTriggerDirect   = "direct"   // 直接调用 PublishBatch
+	batchTriggerSize     = "size"     // 缓冲达到 batch_size
+	batchTriggerInterval = "interval" // 距上次发布达到 flush_interval
+	batchTriggerFlush    = "flush"    // 调用 AsyncPublisher.Flush
+	batchTriggerClose    = "close"    // 关闭 AsyncPublisher 时发布剩余消息
+)
+
+func init() {
+	metrics.Registry.MustRegister(publishTotal, publishDuration, publishConfirmFailures, publishReturned,
+		publishBatchSize, publishBatchDuration, asyncPublishDropped)
+}
+
+// observeBatch 记录一次批量发布的消息数和耗时
+func observeBatch(trigger string, size int, started time.Time) {
+	publishBatchSize.WithLabelValues(trigger).Observe(float64(size))
+	publishBatchDuration.WithLabelValues(trigger).Observe(time.Since(started).Seconds())
+}
+
+// observePublish 记录一次发布的结果和耗时
+func observePublish(exchange, routingKey, status string, started time.Time) {
+	exchange = exchangeName(exchange)
+	publishTotal.WithLabelValues(exchange, routingKey, status).Inc()
+	publishDuration.WithLabelValues(exchange, routingKey).Observe(time.Since(started).Seconds())
+
+	switch status {
+	case publishStatusNacked:
+		publishConfirmFailures.WithLabelValues(exchange, routingKey).Inc()
+	case publishStatusReturned:
+		publishReturned.WithLabelValues(exchange, routingKey).Inc()
+	}
+}
+
+// exchangeName 默认交换机的名称为空字符串，在指标和 span 中显示为 (default)
+func exchangeName(exchange string) string {
+	if exchange == "" {
+		return "(default)"
+	}
+	return exchange
+}
@@ -0,0 +1,59 @@
+package mq
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// LoggerName 消息队列组件的 logger 名称，通过 logger.levels.mq 单独调整级别
+const LoggerName = "mq"
+
+// Publisher 消息发布接口，RabbitMQ、Redis Streams 和 NATS JetStream 生产者均实现该接口
+type Publisher interface {
+	Publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) error
+}
+
+// routedPublisher 按交换机所属驱动选择生产者
+type routedPublisher struct {
+	cfg        *config.RabbitMQ
+	publishers map[string]Publisher // driver -> publisher
+}
+
+// NewRoutedPublisher 创建按驱动路由的发布器
+// 交换机绑定了 redis 或 nats 队列时发布到对应驱动，其余发布到 RabbitMQ；对应驱动未配置时发布返回 ErrBrokerNotFound
+func NewRoutedPublisher(cfg *config.RabbitMQ, rabbit *Producer, streams *RedisStreamProducer, jetStream *JetStreamProducer) Publisher {
+	p := &routedPublisher{cfg: cfg, publishers: make(map[string]Publisher, 3)}
+	// 避免将 nil 指针包装为非 nil 接口
+	if rabbit != nil {
+		p.publishers[config.DriverRabbitMQ] = rabbit
+	}
+	if streams != nil {
+		p.publishers[config.DriverRedis] = streams
+	}
+	if jetStream != nil {
+		p.publishers[config.DriverNATS] = jetStream
+	}
+	return p
+}
+
+// Publish 发布消息
+func (p *routedPublisher) Publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) error {
+	driver := p.cfg.ExchangeDriver(exchange)
+	publisher, ok := p.publishers[driver]
+	if !ok {
+		return brokerNotFound(driver, exchange)
+	}
+	return publisher.Publish(ctx, exchange, routingKey, message)
+}
+
+// brokerNotFound 交换机所属驱动的生产者未配置
+func brokerNotFound(driver, exchange string) error {
+	if driver == config.DriverRabbitMQ {
+		return fmt.Errorf("%w: %s", ErrBrokerNotFound, config.DefaultBroker)
+	}
+	return fmt.Errorf("%w: %s producer for exchange %s", ErrBrokerNotFound, driver, exchange)
+}
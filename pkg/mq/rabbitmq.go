@@ -2,81 +2,243 @@ package mq
 
 import (
 	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/pool"
+	"github.com/hedeqiang/skeleton/pkg/timing"
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
-// NewRabbitMQ 根据提供的配置初始化 RabbitMQ 连接
+// NewRabbitMQ 根据提供的配置初始化 default broker 的连接
 func NewRabbitMQ(cfg *config.RabbitMQ) (*amqp.Connection, error) {
-	conn, err := amqp.Dial(cfg.URL)
-	if err != nil {
-		return nil, err
+	broker, ok := cfg.BrokerConfigs()[config.DefaultBroker]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBrokerNotFound, config.DefaultBroker)
 	}
-	return conn, nil
+	return Dial(config.DefaultBroker, RoleProducer, broker)
 }
 
 // Producer 是一个 RabbitMQ 生产者
 type Producer struct {
-	conn *amqp.Connection
+	connect func() (*amqp.Connection, error)
 }
 
 // NewProducer 创建一个新的生产者实例
 func NewProducer(conn *amqp.Connection) *Producer {
-	return &Producer{conn: conn}
+	return &Producer{connect: func() (*amqp.Connection, error) { return conn, nil }}
 }
 
-// Publish 向指定的 exchange 发送一条消息
+// 发布失败的原因，可通过 errors.Is 判断
+var (
+	// ErrPublishNacked 消息被 broker 拒绝确认
+	ErrPublishNacked = errors.New("message nacked by broker")
+	// ErrMessageReturned 消息无法路由到任何队列，被 broker 退回
+	ErrMessageReturned = errors.New("message returned by broker")
+)
+
+// Publish 向指定的 exchange 发送一条消息并等待 broker 确认
 // exchange: 交换机名称
 // routingKey: 路由键
 // message: amqp.Publishing 结构，包含了消息体和各种属性
+// 消息以 mandatory 方式发布，无法路由时返回 ErrMessageReturned，被 broker 拒绝时返回 ErrPublishNacked
 func (p *Producer) Publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) error {
+	// 创建生产者 span，并将链路上下文写入消息头传递给消费者
+	ctx, span := startPublishSpan(ctx, exchange, routingKey, &message)
+
+	started := time.Now()
+	status, err := p.publish(ctx, exchange, routingKey, message)
+	observePublish(exchange, routingKey, status, started)
+	timing.Record(ctx, timing.KindMQ, exchangeName(exchange)+" "+routingKey, time.Since(started))
+	endSpan(span, err)
+	return err
+}
+
+// publish 在 confirm 模式的 channel 上发布消息，返回用于指标统计的发布结果
+func (p *Producer) publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) (string, error) {
+	conn, err := p.connect()
+	if err != nil {
+		return publishStatusError, err
+	}
+
 	// 为保证线程安全，每次发布都创建一个新的 channel
-	ch, err := p.conn.Channel()
+	ch, err := conn.Channel()
 	if err != nil {
-		return fmt.Errorf("failed to open a channel: %w", err)
+		return publishStatusError, fmt.Errorf("failed to open a channel: %w", err)
 	}
 	defer ch.Close()
 
+	if err := ch.Confirm(false); err != nil {
+		return publishStatusError, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	// broker 在确认之前投递 basic.return，确认后检查即可得知消息是否被退回
+	returns := ch.NotifyReturn(make(chan amqp.Return, 1))
+
 	// 使用上下文进行发布
-	return ch.PublishWithContext(
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(
 		ctx,
 		exchange,   // exchange
 		routingKey, // routing key
-		false,      // mandatory
+		true,       // mandatory
 		false,      // immediate
 		message,
 	)
+	if err != nil {
+		return publishStatusError, fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return publishStatusNacked, fmt.Errorf("failed to wait for publisher confirm: %w", err)
+	}
+	if !acked {
+		return publishStatusNacked, ErrPublishNacked
+	}
+
+	select {
+	case ret := <-returns:
+		return publishStatusReturned, fmt.Errorf("%w: %d %s", ErrMessageReturned, ret.ReplyCode, ret.ReplyText)
+	default:
+		return publishStatusSuccess, nil
+	}
 }
 
 // MessageHandler 定义消息处理函数接口
 type MessageHandler func(ctx context.Context, body []byte) error
 
+// 消息处理结果，传给 DeliveryObserver
+const (
+	OutcomeAcked        = "acked"         // 处理成功并确认
+	OutcomeDeadLettered = "dead_lettered" // 不可重试的错误，转入死信队列
+	OutcomeRequeued     = "requeued"      // 处理失败、超时或消费者停止，重新入队
+	OutcomeRejected     = "rejected"      // 处理函数 panic，拒绝且不重新入队
+	OutcomeDropped      = "dropped"       // 按失败策略确认并丢弃
+	OutcomeParked       = "parked"        // 按失败策略转发到停放交换机
+)
+
+// DeliveryObserver 在消息确认或拒绝之后调用，err 为处理函数返回的错误
+// 观察者在消费 goroutine 中同步执行，不应阻塞
+type DeliveryObserver func(queue string, d amqp.Delivery, outcome string, err error)
+
 // Consumer 是一个 RabbitMQ 消费者
 type Consumer struct {
-	conn    *amqp.Connection
-	channel *amqp.Channel
+	conn       *amqp.Connection
+	channel    *amqp.Channel
+	pool       *pool.Pool
+	queuePools map[string]*pool.Pool
+	broker     string
+	producer   Publisher
+	ctx        context.Context
+	timeout    time.Duration
+	observer   DeliveryObserver
+
+	mu          sync.Mutex
+	tags        []string
+	deadLetters map[string]deadLetterTarget
+}
+
+// ConsumerOption 消费者选项
+type ConsumerOption func(*Consumer)
+
+// WithWorkerPool 使用工作池并发处理消息
+// 预取数量与工作池容量一致，工作池繁忙时停止投递新消息，形成背压
+func WithWorkerPool(p *pool.Pool) ConsumerOption {
+	return func(c *Consumer) {
+		c.pool = p
+	}
+}
+
+// WithQueueWorkerPools 为指定队列使用独立的工作池，其余队列使用 WithWorkerPool 设置的共享工作池
+// RabbitMQ 队列的预取数量和 JetStream 的 max_ack_pending 与队列所用工作池的容量一致
+func WithQueueWorkerPools(pools map[string]*pool.Pool) ConsumerOption {
+	return func(c *Consumer) {
+		c.queuePools = pools
+	}
+}
+
+// WithBroker 设置消费者所属的 broker，SetupInfrastructureFromConfig 只声明属于该 broker 的交换机和队列
+func WithBroker(name string) ConsumerOption {
+	return func(c *Consumer) {
+		c.broker = name
+	}
+}
+
+// WithDeadLetterProducer 设置转发死信消息使用的生产者，默认复用消费连接
+func WithDeadLetterProducer(p Publisher) ConsumerOption {
+	return func(c *Consumer) {
+		c.producer = p
+	}
+}
+
+// WithContext 设置消费者的根 context，每条消息的处理 context 由它派生
+// 根 context 取消后，正在处理的消息随之取消并重新入队
+func WithContext(ctx context.Context) ConsumerOption {
+	return func(c *Consumer) {
+		c.ctx = ctx
+	}
+}
+
+// WithProcessingTimeout 设置单条消息的处理超时，超时后处理 context 取消，消息 Nack 并重新入队
+func WithProcessingTimeout(d time.Duration) ConsumerOption {
+	return func(c *Consumer) {
+		c.timeout = d
+	}
+}
+
+// WithDeliveryObserver 设置消息处理结果的观察者，用于归档、审计等
+func WithDeliveryObserver(fn DeliveryObserver) ConsumerOption {
+	return func(c *Consumer) {
+		c.observer = fn
+	}
 }
 
 // NewConsumer 创建一个新的消费者实例
-func NewConsumer(conn *amqp.Connection) (*Consumer, error) {
+func NewConsumer(conn *amqp.Connection, opts ...ConsumerOption) (*Consumer, error) {
 	ch, err := conn.Channel()
 	if err != nil {
 		return nil, fmt.Errorf("failed to open a channel: %w", err)
 	}
 
+	c := &Consumer{
+		conn:    conn,
+		channel: ch,
+		broker:  config.DefaultBroker,
+		ctx:     context.Background(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.producer == nil {
+		c.producer = NewProducer(conn)
+	}
+
 	// 设置 QoS，控制消费者预取消息数量
-	err = ch.Qos(1, 0, false)
+	err = ch.Qos(c.prefetch(""), 0, false)
 	if err != nil {
 		ch.Close()
 		return nil, fmt.Errorf("failed to set QoS: %w", err)
 	}
 
-	return &Consumer{
-		conn:    conn,
-		channel: ch,
-	}, nil
+	return c, nil
+}
+
+// workerPool 返回处理队列消息的工作池，nil 表示在消费 goroutine 中直接处理
+func (c *Consumer) workerPool(queue string) *pool.Pool {
+	if p, ok := c.queuePools[queue]; ok {
+		return p
+	}
+	return c.pool
+}
+
+// prefetch 返回队列的预取数量，与所用工作池的容量一致，工作池繁忙时停止投递新消息
+func (c *Consumer) prefetch(queue string) int {
+	if p := c.workerPool(queue); p != nil {
+		return p.Capacity()
+	}
+	return 1
 }
 
 // DeclareExchange 声明交换机
@@ -94,14 +256,53 @@ func (c *Consumer) DeclareExchange(name, kind string, durable, autoDelete bool)
 
 // DeclareQueue 声明队列
 func (c *Consumer) DeclareQueue(name string, durable, autoDelete, exclusive bool) (amqp.Queue, error) {
+	return c.DeclareQueueWithArgs(name, durable, autoDelete, exclusive, nil)
+}
+
+// DeclareQueueWithArgs 声明带参数的队列，如 x-dead-letter-exchange
+func (c *Consumer) DeclareQueueWithArgs(name string, durable, autoDelete, exclusive bool, args amqp.Table) (amqp.Queue, error) {
 	return c.channel.QueueDeclare(
 		name,       // name
 		durable,    // durable
 		autoDelete, // delete when unused
 		exclusive,  // exclusive
 		false,      // no-wait
-		nil,        // arguments
+		args,       // arguments
+	)
+}
+
+// SetDeadLetter 设置队列的死信转发目标，routingKey 为空时沿用消息原路由键
+func (c *Consumer) SetDeadLetter(queue, exchange, routingKey string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.deadLetters == nil {
+		c.deadLetters = make(map[string]deadLetterTarget)
+	}
+	c.deadLetters[queue] = deadLetterTarget{exchange: exchange, routingKey: routingKey}
+}
+
+// registerConsumer 按队列的预取数量设置 QoS 后注册消费者，调用方需持有 c.mu
+func (c *Consumer) registerConsumer(queueName, consumerName string) (<-chan amqp.Delivery, error) {
+	if len(c.queuePools) > 0 {
+		if err := c.channel.Qos(c.prefetch(queueName), 0, false); err != nil {
+			return nil, fmt.Errorf("failed to set QoS: %w", err)
+		}
+	}
+
+	msgs, err := c.channel.Consume(
+		queueName,    // queue
+		consumerName, // consumer
+		false,        // auto-ack (设置为false，手动确认)
+		false,        // exclusive
+		false,        // no-local
+		false,        // no-wait
+		nil,          // args
 	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register a consumer: %w", err)
+	}
+	return msgs, nil
 }
 
 // BindQueue 绑定队列到交换机
@@ -117,33 +318,34 @@ func (c *Consumer) BindQueue(queueName, routingKey, exchangeName string) error {
 
 // Consume 开始消费消息
 func (c *Consumer) Consume(queueName, consumerName string, handler MessageHandler) error {
-	msgs, err := c.channel.Consume(
-		queueName,    // queue
-		consumerName, // consumer
-		false,        // auto-ack (设置为false，手动确认)
-		false,        // exclusive
-		false,        // no-local
-		false,        // no-wait
-		nil,          // args
-	)
+	if consumerName == "" {
+		consumerName = fmt.Sprintf("%s-%d", queueName, time.Now().UnixNano())
+	}
+	c.mu.Lock()
+	c.tags = append(c.tags, consumerName)
+	// global=false 的 QoS 作用于之后创建的消费者，设置与注册需要在锁内完成，避免并发注册的队列使用其他队列的预取数量
+	msgs, err := c.registerConsumer(queueName, consumerName)
+	c.mu.Unlock()
 	if err != nil {
-		return fmt.Errorf("failed to register a consumer: %w", err)
+		return err
 	}
 
 	// 创建一个 channel 来接收停止信号
 	forever := make(chan bool)
 
+	workers := c.workerPool(queueName)
 	go func() {
 		for d := range msgs {
-			ctx := context.Background()
+			if workers == nil {
+				c.handleDelivery(queueName, d, handler)
+				continue
+			}
 
-			// 调用业务处理函数
-			if err := handler(ctx, d.Body); err != nil {
-				// 处理失败，拒绝消息并重新入队
+			d := d
+			// Submit 在工作池满时阻塞，停止从 channel 读取新消息
+			if err := workers.Submit(c.ctx, func() { c.handleDelivery(queueName, d, handler) }); err != nil {
+				// 工作池已关闭或消费者已停止，消息重新入队交给其他消费者
 				d.Nack(false, true)
-			} else {
-				// 处理成功，确认消息
-				d.Ack(false)
 			}
 		}
 	}()
@@ -152,6 +354,90 @@ func (c *Consumer) Consume(queueName, consumerName string, handler MessageHandle
 	return nil
 }
 
+// handleDelivery 调用业务处理函数并确认消息
+// 处理函数收到的 ctx 由消费者根 context 派生，携带处理超时和消费者 span，其父 span 为消息头中传递的生产者 span
+func (c *Consumer) handleDelivery(queue string, d amqp.Delivery, handler MessageHandler) {
+	ctx, span := startConsumeSpan(c.ctx, queue, d)
+	var cancel context.CancelFunc
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	} else {
+		ctx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			// 处理函数 panic，拒绝消息且不重新入队，避免毒消息反复重试
+			d.Nack(false, false)
+			err := fmt.Errorf("message handler panic: %v", r)
+			endSpan(span, err)
+			c.observe(queue, d, OutcomeRejected, err)
+			panic(r)
+		}
+	}()
+
+	// 调用业务处理函数
+	err := handler(ctx, d.Body)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("message processing timed out after %s: %w", c.timeout, err)
+	}
+	endSpan(span, err)
+	if err == nil {
+		// 处理成功，确认消息
+		d.Ack(false)
+		c.observe(queue, d, OutcomeAcked, nil)
+		return
+	}
+
+	if dlErr, ok := asDeadLetter(err); ok {
+		// 不可重试的错误，转入死信队列；处理 context 可能已超时，转发时不继承取消
+		c.deadLetter(context.WithoutCancel(ctx), queue, d, dlErr)
+		c.observe(queue, d, OutcomeDeadLettered, err)
+		return
+	}
+
+	if asDrop(err) {
+		d.Ack(false)
+		c.observe(queue, d, OutcomeDropped, err)
+		return
+	}
+
+	if parkErr, ok := asPark(err); ok {
+		if c.park(context.WithoutCancel(ctx), queue, d, parkErr) {
+			c.observe(queue, d, OutcomeParked, err)
+		} else {
+			c.observe(queue, d, OutcomeRequeued, err)
+		}
+		return
+	}
+
+	// 处理失败、超时或消费者停止，拒绝消息并重新入队
+	d.Nack(false, true)
+	c.observe(queue, d, OutcomeRequeued, err)
+}
+
+// observe 通知消息处理结果的观察者
+func (c *Consumer) observe(queue string, d amqp.Delivery, outcome string, err error) {
+	if c.observer != nil {
+		c.observer(queue, d, outcome, err)
+	}
+}
+
+// Cancel 停止接收新消息，已投递的消息仍可正常确认
+func (c *Consumer) Cancel() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range c.tags {
+		if err := c.channel.Cancel(tag, false); err != nil {
+			return fmt.Errorf("failed to cancel consumer %s: %w", tag, err)
+		}
+	}
+	c.tags = nil
+	return nil
+}
+
 // Close 关闭消费者
 func (c *Consumer) Close() error {
 	if c.channel != nil {
@@ -161,9 +447,13 @@ func (c *Consumer) Close() error {
 }
 
 // SetupInfrastructureFromConfig 根据配置设置RabbitMQ基础设施
+// 只处理属于当前消费者 broker 的交换机和队列
 func (c *Consumer) SetupInfrastructureFromConfig(cfg *config.RabbitMQ) error {
 	// 设置交换机
 	for _, exchangeCfg := range cfg.Exchanges {
+		if exchangeCfg.BrokerName() != c.broker {
+			continue
+		}
 		if err := c.DeclareExchange(exchangeCfg.Name, exchangeCfg.Type, exchangeCfg.Durable, exchangeCfg.AutoDelete); err != nil {
 			return fmt.Errorf("failed to declare exchange %s: %w", exchangeCfg.Name, err)
 		}
@@ -171,10 +461,22 @@ func (c *Consumer) SetupInfrastructureFromConfig(cfg *config.RabbitMQ) error {
 
 	// 设置队列并绑定
 	for _, queueCfg := range cfg.Queues {
+		if queueCfg.BrokerName() != c.broker {
+			continue
+		}
+
+		args, err := queueArguments(queueCfg)
+		if err != nil {
+			return fmt.Errorf("invalid arguments for queue %s: %w", queueCfg.Name, err)
+		}
+
 		// 声明队列
-		if _, err := c.DeclareQueue(queueCfg.Name, queueCfg.Durable, queueCfg.AutoDelete, queueCfg.Exclusive); err != nil {
+		if _, err := c.DeclareQueueWithArgs(queueCfg.Name, queueCfg.Durable, queueCfg.AutoDelete, queueCfg.Exclusive, args); err != nil {
 			return fmt.Errorf("failed to declare queue %s: %w", queueCfg.Name, err)
 		}
+		if queueCfg.DeadLetterExchange != "" {
+			c.SetDeadLetter(queueCfg.Name, queueCfg.DeadLetterExchange, queueCfg.DeadLetterRoutingKey)
+		}
 
 		// 绑定队列到交换机
 		for _, routingKey := range queueCfg.RoutingKeys {
@@ -187,3 +489,55 @@ func (c *Consumer) SetupInfrastructureFromConfig(cfg *config.RabbitMQ) error {
 
 	return nil
 }
+
+// queueArguments 根据队列配置生成声明参数
+// 配置死信交换机后，被拒绝且不重新入队的消息（如处理函数 panic）以及 TTL 过期、
+// 超出长度上限被丢弃的消息也会由 broker 转发到死信交换机
+func queueArguments(cfg config.QueueConfig) (amqp.Table, error) {
+	args := amqp.Table{}
+	if cfg.DeadLetterExchange != "" {
+		args["x-dead-letter-exchange"] = cfg.DeadLetterExchange
+		if cfg.DeadLetterRoutingKey != "" {
+			args["x-dead-letter-routing-key"] = cfg.DeadLetterRoutingKey
+		}
+	}
+
+	if cfg.MessageTTL < 0 || cfg.MaxLength < 0 || cfg.MaxLengthBytes < 0 {
+		return nil, fmt.Errorf("message_ttl, max_length and max_length_bytes must not be negative")
+	}
+	if cfg.MessageTTL > 0 {
+		if cfg.MessageTTL < time.Millisecond {
+			return nil, fmt.Errorf("message_ttl must be at least 1ms")
+		}
+		args["x-message-ttl"] = cfg.MessageTTL.Milliseconds()
+	}
+	if cfg.MaxLength > 0 {
+		args["x-max-length"] = int64(cfg.MaxLength)
+	}
+	if cfg.MaxLengthBytes > 0 {
+		args["x-max-length-bytes"] = cfg.MaxLengthBytes
+	}
+
+	switch cfg.Overflow {
+	case "":
+	case "drop-head", "reject-publish", "reject-publish-dlx":
+		if cfg.MaxLength == 0 && cfg.MaxLengthBytes == 0 {
+			return nil, fmt.Errorf("overflow requires max_length or max_length_bytes")
+		}
+		if cfg.Overflow == "reject-publish-dlx" && cfg.DeadLetterExchange == "" {
+			return nil, fmt.Errorf("overflow reject-publish-dlx requires dead_letter_exchange")
+		}
+		args["x-overflow"] = cfg.Overflow
+	default:
+		return nil, fmt.Errorf("unsupported overflow %q", cfg.Overflow)
+	}
+
+	if cfg.Lazy {
+		args["x-queue-mode"] = "lazy"
+	}
+
+	if len(args) == 0 {
+		return nil, nil
+	}
+	return args, nil
+}
@@ -0,0 +1,145 @@
+package mq
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/pool"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestQueueArguments(t *testing.T) {
+	args, err := queueArguments(config.QueueConfig{
+		Name:               "orders",
+		DeadLetterExchange: "orders.dlx",
+		MessageTTL:         90 * time.Second,
+		MaxLength:          1000,
+		Overflow:           "reject-publish-dlx",
+		Lazy:               true,
+	})
+	if err != nil {
+		t.Fatalf("queueArguments failed: %v", err)
+	}
+
+	want := map[string]interface{}{
+		"x-dead-letter-exchange": "orders.dlx",
+		"x-message-ttl":          int64(90000),
+		"x-max-length":           int64(1000),
+		"x-overflow":             "reject-publish-dlx",
+		"x-queue-mode":           "lazy",
+	}
+	if len(args) != len(want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+	for key, value := range want {
+		if args[key] != value {
+			t.Errorf("%s = %v, want %v", key, args[key], value)
+		}
+	}
+
+	if args, err := queueArguments(config.QueueConfig{Name: "plain"}); err != nil || args != nil {
+		t.Fatalf("plain queue should have no arguments, got %v, %v", args, err)
+	}
+}
+
+func TestQueueArgumentsInvalid(t *testing.T) {
+	for _, cfg := range []config.QueueConfig{
+		{Overflow: "drop-tail", MaxLength: 10},
+		{Overflow: "reject-publish"},
+		{Overflow: "reject-publish-dlx", MaxLength: 10},
+		{MaxLength: -1},
+		{MessageTTL: time.Microsecond},
+	} {
+		if _, err := queueArguments(cfg); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
+
+// recordingAcknowledger 记录消息的确认结果
+type recordingAcknowledger struct {
+	acked   bool
+	nacked  bool
+	requeue bool
+}
+
+func (a *recordingAcknowledger) Ack(tag uint64, multiple bool) error {
+	a.acked = true
+	return nil
+}
+
+func (a *recordingAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	a.nacked, a.requeue = true, requeue
+	return nil
+}
+
+func (a *recordingAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func TestHandleDeliveryTimeout(t *testing.T) {
+	c := &Consumer{ctx: context.Background(), timeout: 10 * time.Millisecond}
+	ack := &recordingAcknowledger{}
+
+	c.handleDelivery("orders", amqp.Delivery{Acknowledger: ack}, func(ctx context.Context, body []byte) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("handler context should have a deadline")
+		}
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if ack.acked || !ack.nacked || !ack.requeue {
+		t.Fatalf("timed out message should be nacked and requeued: %+v", ack)
+	}
+}
+
+func TestHandleDeliveryRootCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &Consumer{ctx: ctx}
+	ack := &recordingAcknowledger{}
+
+	c.handleDelivery("orders", amqp.Delivery{Acknowledger: ack}, func(ctx context.Context, body []byte) error {
+		cancel()
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	if ack.acked || !ack.nacked || !ack.requeue {
+		t.Fatalf("cancelled message should be nacked and requeued: %+v", ack)
+	}
+
+	var outcome string
+	ack = &recordingAcknowledger{}
+	c = &Consumer{ctx: context.Background(), timeout: time.Second, observer: func(queue string, d amqp.Delivery, o string, err error) {
+		outcome = o
+	}}
+	c.handleDelivery("orders", amqp.Delivery{Acknowledger: ack}, func(ctx context.Context, body []byte) error {
+		return nil
+	})
+	if !ack.acked || ack.nacked || outcome != OutcomeAcked {
+		t.Fatalf("processed message should be acked: %+v, outcome = %q", ack, outcome)
+	}
+}
+
+func TestQueueWorkerPools(t *testing.T) {
+	shared := pool.New(pool.Config{Workers: 2, QueueSize: 2})
+	dedicated := pool.New(pool.Config{Workers: 8, QueueSize: 24})
+	defer shared.Shutdown(context.Background())
+	defer dedicated.Shutdown(context.Background())
+
+	c := &Consumer{}
+	WithWorkerPool(shared)(c)
+	WithQueueWorkerPools(map[string]*pool.Pool{"search.indexer": dedicated})(c)
+
+	if c.workerPool("search.indexer") != dedicated || c.prefetch("search.indexer") != 32 {
+		t.Errorf("search.indexer should use its dedicated pool, prefetch = %d", c.prefetch("search.indexer"))
+	}
+	if c.workerPool("hello.queue") != shared || c.prefetch("hello.queue") != 4 {
+		t.Errorf("other queues should use the shared pool, prefetch = %d", c.prefetch("hello.queue"))
+	}
+	if prefetch := (&Consumer{}).prefetch("hello.queue"); prefetch != 1 {
+		t.Errorf("prefetch without pool = %d, want 1", prefetch)
+	}
+}
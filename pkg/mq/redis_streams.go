@@ -0,0 +1,492 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/timing"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis Streams 配置默认值
+const (
+	defaultStreamPrefix    = "stream"
+	defaultStreamBatchSize = 10
+	defaultStreamBlock     = 5 * time.Second
+	defaultStreamClaimIdle = time.Minute
+
+	// streamAckTimeout 确认或转入死信 stream 的超时时间
+	streamAckTimeout = 5 * time.Second
+	// streamRetryInterval 读取失败后的重试间隔
+	streamRetryInterval = time.Second
+)
+
+// deadStreamSuffix 死信 stream 的后缀，被拒绝且不重新入队的消息写入 <stream>:dead
+const deadStreamSuffix = ":dead"
+
+// stream 消息字段，对应 amqp.Publishing 的属性
+const (
+	streamFieldBody        = "body"
+	streamFieldMessageID   = "message_id"
+	streamFieldType        = "type"
+	streamFieldContentType = "content_type"
+	streamFieldAppID       = "app_id"
+	streamFieldTimestamp   = "timestamp"
+	streamFieldExchange    = "exchange"
+	streamFieldRoutingKey  = "routing_key"
+	streamFieldHeaders     = "headers"
+)
+
+// streamOptions 规范化后的 Redis Streams 配置
+type streamOptions struct {
+	prefix        string
+	maxLen        int64
+	batchSize     int64
+	block         time.Duration
+	claimIdle     time.Duration
+	maxDeliveries int64
+}
+
+func newStreamOptions(cfg *config.RedisStreams) streamOptions {
+	o := streamOptions{
+		prefix:        strings.TrimSuffix(cfg.Prefix, ":"),
+		maxLen:        cfg.MaxLen,
+		batchSize:     cfg.BatchSize,
+		block:         cfg.Block,
+		claimIdle:     cfg.ClaimIdle,
+		maxDeliveries: cfg.MaxDeliveries,
+	}
+	if o.prefix == "" {
+		o.prefix = defaultStreamPrefix
+	}
+	if o.batchSize <= 0 {
+		o.batchSize = defaultStreamBatchSize
+	}
+	if o.block <= 0 {
+		o.block = defaultStreamBlock
+	}
+	if o.claimIdle <= 0 {
+		o.claimIdle = defaultStreamClaimIdle
+	}
+	return o
+}
+
+// stream 返回队列对应的 stream key
+func (o streamOptions) stream(queue string) string {
+	return o.prefix + ":" + queue
+}
+
+// RedisStreamProducer 将消息写入 Redis Streams
+// 交换机和路由键按队列配置解析为目标队列（精确匹配路由键），消息写入每个目标队列的 stream
+type RedisStreamProducer struct {
+	client *redis.Client
+	opts   streamOptions
+	queues []config.QueueConfig
+}
+
+// NewRedisStreamProducer 创建 Redis Streams 生产者，只路由 driver 为 redis 的队列
+func NewRedisStreamProducer(client *redis.Client, cfg *config.RedisStreams, mqCfg *config.RabbitMQ) *RedisStreamProducer {
+	p := &RedisStreamProducer{client: client, opts: newStreamOptions(cfg)}
+	for _, queue := range mqCfg.Queues {
+		if queue.DriverName() == config.DriverRedis {
+			p.queues = append(p.queues, queue)
+		}
+	}
+	return p
+}
+
+// Publish 将消息写入绑定到 exchange 且路由键匹配的所有队列，没有匹配的队列时返回 ErrMessageReturned
+func (p *RedisStreamProducer) Publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) error {
+	ctx, span := startPublishSpan(ctx, exchange, routingKey, &message)
+
+	started := time.Now()
+	status, err := p.publish(ctx, exchange, routingKey, message)
+	observePublish(exchange, routingKey, status, started)
+	timing.Record(ctx, timing.KindMQ, exchangeName(exchange)+" "+routingKey, time.Since(started))
+	endSpan(span, err)
+	return err
+}
+
+// publish 在一个事务中写入所有目标 stream，返回用于指标统计的发布结果
+func (p *RedisStreamProducer) publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) (string, error) {
+	queues := p.route(exchange, routingKey)
+	if len(queues) == 0 {
+		return publishStatusReturned, fmt.Errorf("%w: no redis queue bound to %s with routing key %s", ErrMessageReturned, exchangeName(exchange), routingKey)
+	}
+
+	values, err := streamValues(exchange, routingKey, message)
+	if err != nil {
+		return publishStatusError, err
+	}
+
+	_, err = p.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, queue := range queues {
+			pipe.XAdd(ctx, &redis.XAddArgs{
+				Stream: p.opts.stream(queue),
+				MaxLen: p.opts.maxLen,
+				Approx: p.opts.maxLen > 0,
+				Values: values,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return publishStatusError, fmt.Errorf("failed to add message to stream: %w", err)
+	}
+	return publishStatusSuccess, nil
+}
+
+// route 返回绑定到 exchange 且路由键匹配的队列名称
+func (p *RedisStreamProducer) route(exchange, routingKey string) []string {
+	var queues []string
+	for _, queue := range p.queues {
+		if queue.Exchange == exchange && slices.Contains(queue.RoutingKeys, routingKey) {
+			queues = append(queues, queue.Name)
+		}
+	}
+	return queues
+}
+
+// streamValues 将 AMQP 消息转换为 stream 字段
+func streamValues(exchange, routingKey string, message amqp.Publishing) (map[string]interface{}, error) {
+	values := map[string]interface{}{
+		streamFieldBody:       message.Body,
+		streamFieldExchange:   exchange,
+		streamFieldRoutingKey: routingKey,
+	}
+	if message.MessageId != "" {
+		values[streamFieldMessageID] = message.MessageId
+	}
+	if message.Type != "" {
+		values[streamFieldType] = message.Type
+	}
+	if message.ContentType != "" {
+		values[streamFieldContentType] = message.ContentType
+	}
+	if message.AppId != "" {
+		values[streamFieldAppID] = message.AppId
+	}
+	if !message.Timestamp.IsZero() {
+		values[streamFieldTimestamp] = message.Timestamp.UnixMilli()
+	}
+	if len(message.Headers) > 0 {
+		headers, err := json.Marshal(message.Headers)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal message headers: %w", err)
+		}
+		values[streamFieldHeaders] = string(headers)
+	}
+	return values, nil
+}
+
+// streamDelivery 将 stream 消息转换为 amqp.Delivery，deliveries 为该消息的投递次数
+// 消息头中的数字经过 JSON 编码后统一为 float64
+func streamDelivery(msg redis.XMessage, deliveries int64, ack amqp.Acknowledger) amqp.Delivery {
+	field := func(name string) string {
+		value, _ := msg.Values[name].(string)
+		return value
+	}
+
+	d := amqp.Delivery{
+		Acknowledger: ack,
+		MessageId:    field(streamFieldMessageID),
+		Type:         field(streamFieldType),
+		ContentType:  field(streamFieldContentType),
+		AppId:        field(streamFieldAppID),
+		Exchange:     field(streamFieldExchange),
+		RoutingKey:   field(streamFieldRoutingKey),
+		Body:         []byte(field(streamFieldBody)),
+		Redelivered:  deliveries > 1,
+	}
+	if ms, err := strconv.ParseInt(field(streamFieldTimestamp), 10, 64); err == nil {
+		d.Timestamp = time.UnixMilli(ms)
+	}
+	if raw := field(streamFieldHeaders); raw != "" {
+		var headers amqp.Table
+		if json.Unmarshal([]byte(raw), &headers) == nil {
+			d.Headers = headers
+		}
+	}
+	return d
+}
+
+// streamAcknowledger 确认或拒绝一条 stream 消息
+// Ack 执行 XACK；重新入队的 Nack 不做处理，消息留在 pending 列表中，空闲超过 claim_idle 后被重新认领；
+// 不重新入队的 Nack 将原始字段写入死信 stream 后 XACK
+type streamAcknowledger struct {
+	client *redis.Client
+	stream string
+	group  string
+	msg    redis.XMessage
+	onErr  func(error)
+}
+
+// Ack 确认消息
+func (a *streamAcknowledger) Ack(tag uint64, multiple bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), streamAckTimeout)
+	defer cancel()
+	return a.report(a.client.XAck(ctx, a.stream, a.group, a.msg.ID).Err())
+}
+
+// Nack 拒绝消息
+func (a *streamAcknowledger) Nack(tag uint64, multiple, requeue bool) error {
+	if requeue {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), streamAckTimeout)
+	defer cancel()
+	_, err := a.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: a.stream + deadStreamSuffix, Values: a.msg.Values})
+		pipe.XAck(ctx, a.stream, a.group, a.msg.ID)
+		return nil
+	})
+	return a.report(err)
+}
+
+// Reject 拒绝消息
+func (a *streamAcknowledger) Reject(tag uint64, requeue bool) error {
+	return a.Nack(tag, false, requeue)
+}
+
+func (a *streamAcknowledger) report(err error) error {
+	if err != nil && a.onErr != nil {
+		a.onErr(fmt.Errorf("failed to acknowledge stream message %s: %w", a.msg.ID, err))
+	}
+	return err
+}
+
+// RedisStreamConsumer 通过消费者组消费 Redis Streams 中的消息
+// 每个队列对应一个 stream 和同名消费者组；处理、超时、死信和观察者的行为与 RabbitMQ 消费者一致
+type RedisStreamConsumer struct {
+	client   *redis.Client
+	opts     streamOptions
+	consumer *Consumer
+	onError  func(queue string, err error)
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewRedisStreamConsumer 创建 Redis Streams 消费者，支持 RabbitMQ 消费者的工作池、根 context、处理超时、
+// 死信生产者和观察者选项；onError 非空时接收读取和确认失败的错误，读取失败后消费者会自动重试
+func NewRedisStreamConsumer(client *redis.Client, cfg *config.RedisStreams, onError func(queue string, err error), opts ...ConsumerOption) *RedisStreamConsumer {
+	c := &Consumer{
+		broker: config.DefaultBroker,
+		ctx:    context.Background(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return &RedisStreamConsumer{
+		client:   client,
+		opts:     newStreamOptions(cfg),
+		consumer: c,
+		onError:  onError,
+		stop:     make(chan struct{}),
+	}
+}
+
+// SetupInfrastructureFromConfig 为 driver 为 redis 的队列创建 stream 和消费者组，并登记死信转发目标
+// 新建的消费者组从 stream 的第一条消息开始消费
+func (c *RedisStreamConsumer) SetupInfrastructureFromConfig(cfg *config.RabbitMQ) error {
+	ctx, cancel := context.WithTimeout(c.consumer.ctx, streamAckTimeout)
+	defer cancel()
+
+	for _, queueCfg := range cfg.Queues {
+		if queueCfg.DriverName() != config.DriverRedis {
+			continue
+		}
+		err := c.client.XGroupCreateMkStream(ctx, c.opts.stream(queueCfg.Name), queueCfg.Name, "0").Err()
+		if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+			return fmt.Errorf("failed to create consumer group for queue %s: %w", queueCfg.Name, err)
+		}
+		if queueCfg.DeadLetterExchange != "" {
+			c.consumer.SetDeadLetter(queueCfg.Name, queueCfg.DeadLetterExchange, queueCfg.DeadLetterRoutingKey)
+		}
+	}
+	return nil
+}
+
+// Consume 开始消费消息，阻塞直到调用 Cancel 或根 context 取消
+// 每轮先认领空闲超过 claim_idle 的 pending 消息（其他消费者崩溃或处理失败留下的），再读取新消息
+func (c *RedisStreamConsumer) Consume(queueName, consumerName string, handler MessageHandler) error {
+	if consumerName == "" {
+		hostname, _ := os.Hostname()
+		consumerName = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	stream := c.opts.stream(queueName)
+
+	var lastClaim time.Time
+	for !c.stopped() {
+		if time.Since(lastClaim) >= c.opts.claimIdle {
+			lastClaim = time.Now()
+			if err := c.claim(queueName, stream, consumerName, handler); err != nil {
+				c.reportError(queueName, err)
+			}
+		}
+
+		streams, err := c.client.XReadGroup(c.consumer.ctx, &redis.XReadGroupArgs{
+			Group:    queueName,
+			Consumer: consumerName,
+			Streams:  []string{stream, ">"},
+			Count:    c.opts.batchSize,
+			Block:    c.opts.block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if c.stopped() {
+				break
+			}
+			c.reportError(queueName, fmt.Errorf("failed to read from stream %s: %w", stream, err))
+			c.wait(streamRetryInterval)
+			continue
+		}
+
+		for _, s := range streams {
+			for _, msg := range s.Messages {
+				c.dispatch(queueName, stream, msg, 1, handler)
+			}
+		}
+	}
+	return nil
+}
+
+// claim 认领空闲的 pending 消息，投递次数超过 max_deliveries 的消息直接转入死信 stream
+func (c *RedisStreamConsumer) claim(queue, stream, consumerName string, handler MessageHandler) error {
+	start := "0-0"
+	for !c.stopped() {
+		messages, next, err := c.client.XAutoClaim(c.consumer.ctx, &redis.XAutoClaimArgs{
+			Stream:   stream,
+			Group:    queue,
+			Consumer: consumerName,
+			MinIdle:  c.opts.claimIdle,
+			Start:    start,
+			Count:    c.opts.batchSize,
+		}).Result()
+		if err != nil {
+			return fmt.Errorf("failed to claim pending messages from stream %s: %w", stream, err)
+		}
+		if len(messages) == 0 {
+			return nil
+		}
+
+		counts, err := c.deliveryCounts(stream, queue, messages)
+		if err != nil {
+			return err
+		}
+		for _, msg := range messages {
+			count := counts[msg.ID]
+			if c.opts.maxDeliveries > 0 && count > c.opts.maxDeliveries {
+				c.acknowledger(queue, stream, msg).Nack(0, false, false)
+				continue
+			}
+			c.dispatch(queue, stream, msg, count, handler)
+		}
+
+		if next == "0-0" {
+			return nil
+		}
+		start = next
+	}
+	return nil
+}
+
+// deliveryCounts 查询认领到的消息的投递次数
+func (c *RedisStreamConsumer) deliveryCounts(stream, group string, messages []redis.XMessage) (map[string]int64, error) {
+	pending, err := c.client.XPendingExt(c.consumer.ctx, &redis.XPendingExtArgs{
+		Stream: stream,
+		Group:  group,
+		Start:  messages[0].ID,
+		End:    messages[len(messages)-1].ID,
+		Count:  int64(len(messages)),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect pending messages of stream %s: %w", stream, err)
+	}
+
+	counts := make(map[string]int64, len(pending))
+	for _, p := range pending {
+		counts[p.ID] = p.RetryCount
+	}
+	return counts, nil
+}
+
+// dispatch 将消息交给处理函数，使用工作池时在工作池满时阻塞
+func (c *RedisStreamConsumer) dispatch(queue, stream string, msg redis.XMessage, deliveries int64, handler MessageHandler) {
+	d := streamDelivery(msg, deliveries, c.acknowledger(queue, stream, msg))
+	workers := c.consumer.workerPool(queue)
+	if workers == nil {
+		c.consumer.handleDelivery(queue, d, handler)
+		return
+	}
+	if err := workers.Submit(c.consumer.ctx, func() { c.consumer.handleDelivery(queue, d, handler) }); err != nil {
+		// 工作池已关闭或消费者已停止，消息留在 pending 列表中等待重新认领
+		d.Nack(false, true)
+	}
+}
+
+func (c *RedisStreamConsumer) acknowledger(queue, stream string, msg redis.XMessage) *streamAcknowledger {
+	return &streamAcknowledger{
+		client: c.client,
+		stream: stream,
+		group:  queue,
+		msg:    msg,
+		onErr:  func(err error) { c.reportError(queue, err) },
+	}
+}
+
+func (c *RedisStreamConsumer) reportError(queue string, err error) {
+	if c.onError != nil {
+		c.onError(queue, err)
+	}
+}
+
+// stopped 判断是否已调用 Cancel 或根 context 已取消
+func (c *RedisStreamConsumer) stopped() bool {
+	select {
+	case <-c.stop:
+		return true
+	case <-c.consumer.ctx.Done():
+		return true
+	default:
+		return false
+	}
+}
+
+// wait 等待 d 或消费者停止
+func (c *RedisStreamConsumer) wait(d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-c.stop:
+	case <-c.consumer.ctx.Done():
+	}
+}
+
+// Cancel 停止读取新消息，正在阻塞的读取最多在 block 时长后返回，已投递的消息仍可正常确认
+func (c *RedisStreamConsumer) Cancel() error {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+	return nil
+}
+
+// Close 关闭消费者，Redis 连接由调用方管理
+func (c *RedisStreamConsumer) Close() error {
+	return c.Cancel()
+}
@@ -0,0 +1,160 @@
+package mq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisMQConfig hello.exchange 路由到 redis 队列，orders.exchange 路由到 RabbitMQ 队列
+func redisMQConfig() *config.RabbitMQ {
+	return &config.RabbitMQ{
+		Exchanges: []config.ExchangeConfig{
+			{Name: "hello.exchange", Type: "direct"},
+			{Name: "orders.exchange", Type: "direct"},
+		},
+		Queues: []config.QueueConfig{
+			{Name: "hello.queue", Exchange: "hello.exchange", RoutingKeys: []string{"hello"}, Driver: config.DriverRedis},
+			{Name: "hello.audit", Exchange: "hello.exchange", RoutingKeys: []string{"hello", "bye"}, Driver: config.DriverRedis},
+			{Name: "orders.queue", Exchange: "orders.exchange", RoutingKeys: []string{"created"}},
+		},
+	}
+}
+
+func TestStreamDeliveryRoundTrip(t *testing.T) {
+	sent := amqp.Publishing{
+		Headers:     amqp.Table{"x-tenant": "acme"},
+		ContentType: "application/json",
+		MessageId:   "1001",
+		Type:        "hello",
+		AppId:       "skeleton",
+		Timestamp:   time.UnixMilli(1700000000123),
+		Body:        []byte(`{"message_id":"1001"}`),
+	}
+	values, err := streamValues("hello.exchange", "hello", sent)
+	if err != nil {
+		t.Fatalf("streamValues failed: %v", err)
+	}
+
+	// Redis 返回的字段值均为字符串
+	fields := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if b, ok := value.([]byte); ok {
+			value = string(b)
+		}
+		fields[key] = fmt.Sprint(value)
+	}
+
+	d := streamDelivery(redis.XMessage{ID: "1-0", Values: fields}, 2, nil)
+	if d.MessageId != sent.MessageId || d.Type != sent.Type || d.ContentType != sent.ContentType || d.AppId != sent.AppId {
+		t.Errorf("properties = %+v", d)
+	}
+	if d.Exchange != "hello.exchange" || d.RoutingKey != "hello" {
+		t.Errorf("exchange = %s, routing key = %s", d.Exchange, d.RoutingKey)
+	}
+	if string(d.Body) != string(sent.Body) {
+		t.Errorf("body = %s", d.Body)
+	}
+	if !d.Timestamp.Equal(sent.Timestamp) {
+		t.Errorf("timestamp = %v, want %v", d.Timestamp, sent.Timestamp)
+	}
+	if d.Headers["x-tenant"] != "acme" {
+		t.Errorf("headers = %v", d.Headers)
+	}
+	if !d.Redelivered {
+		t.Error("second delivery should be marked redelivered")
+	}
+}
+
+func TestRedisStreamProducerRoute(t *testing.T) {
+	p := NewRedisStreamProducer(nil, &config.RedisStreams{Prefix: "app:"}, redisMQConfig())
+
+	if got := p.route("hello.exchange", "hello"); len(got) != 2 {
+		t.Errorf("hello routes to %v, want both redis queues", got)
+	}
+	if got := p.route("hello.exchange", "bye"); len(got) != 1 || got[0] != "hello.audit" {
+		t.Errorf("bye routes to %v", got)
+	}
+	if got := p.route("orders.exchange", "created"); len(got) != 0 {
+		t.Errorf("rabbitmq queue should not be routed, got %v", got)
+	}
+	if got := p.opts.stream("hello.queue"); got != "app:hello.queue" {
+		t.Errorf("stream key = %s", got)
+	}
+
+	err := p.Publish(context.Background(), "hello.exchange", "unknown", amqp.Publishing{})
+	if !errors.Is(err, ErrMessageReturned) {
+		t.Errorf("unroutable publish error = %v, want ErrMessageReturned", err)
+	}
+}
+
+// recordingPublisher 记录发布的交换机
+type recordingPublisher struct {
+	exchanges []string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, exchange, routingKey string, message amqp.Publishing) error {
+	p.exchanges = append(p.exchanges, exchange)
+	return nil
+}
+
+func TestRoutedPublisher(t *testing.T) {
+	rabbit, streams := &recordingPublisher{}, &recordingPublisher{}
+	p := &routedPublisher{cfg: redisMQConfig(), publishers: map[string]Publisher{
+		config.DriverRabbitMQ: rabbit,
+		config.DriverRedis:    streams,
+	}}
+
+	ctx := context.Background()
+	if err := p.Publish(ctx, "hello.exchange", "hello", amqp.Publishing{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Publish(ctx, "orders.exchange", "created", amqp.Publishing{}); err != nil {
+		t.Fatal(err)
+	}
+	if len(streams.exchanges) != 1 || streams.exchanges[0] != "hello.exchange" {
+		t.Errorf("streams published %v", streams.exchanges)
+	}
+	if len(rabbit.exchanges) != 1 || rabbit.exchanges[0] != "orders.exchange" {
+		t.Errorf("rabbit published %v", rabbit.exchanges)
+	}
+
+	// 未配置 RabbitMQ 时发布到 RabbitMQ 交换机返回 ErrBrokerNotFound
+	p = NewRoutedPublisher(redisMQConfig(), nil, nil, nil).(*routedPublisher)
+	if err := p.Publish(ctx, "orders.exchange", "created", amqp.Publishing{}); !errors.Is(err, ErrBrokerNotFound) {
+		t.Errorf("error = %v, want ErrBrokerNotFound", err)
+	}
+}
+
+func TestNewBrokersRedisOnly(t *testing.T) {
+	cfg := redisMQConfig()
+	cfg.Exchanges = cfg.Exchanges[:1]
+	cfg.Queues = cfg.Queues[:2]
+
+	brokers, err := NewBrokers(cfg)
+	if err != nil {
+		t.Fatalf("redis-only config should not require a broker: %v", err)
+	}
+	if brokers.Has(config.DefaultBroker) {
+		t.Error("default broker should not be configured")
+	}
+
+	// 同一交换机不能同时绑定两种驱动的队列
+	cfg.Queues = append(cfg.Queues, config.QueueConfig{Name: "hello.rabbit", Exchange: "hello.exchange"})
+	if _, err := NewBrokers(cfg); err == nil {
+		t.Error("expected error for exchange bound to both drivers")
+	}
+
+	cfg.Exchanges = nil
+	cfg.Queues = []config.QueueConfig{{Name: "kafka.queue", Driver: "kafka"}}
+	if _, err := NewBrokers(cfg); err == nil || errors.Is(err, ErrBrokerNotFound) {
+		t.Error("expected error for unsupported driver")
+	}
+}
@@ -0,0 +1,46 @@
+package mq
+
+import (
+	"strings"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+)
+
+// ExchangeFor 返回按配置能把 routingKey 投递给消费队列的交换机
+// 按配置顺序查找第一个路由键匹配的队列，只声明不消费的队列（如死信队列）不参与匹配
+func ExchangeFor(cfg *config.RabbitMQ, routingKey string) (string, bool) {
+	for _, queue := range cfg.Queues {
+		if queue.NoConsume || queue.Exchange == "" {
+			continue
+		}
+		for _, pattern := range queue.RoutingKeys {
+			if topicMatches(pattern, routingKey) {
+				return queue.Exchange, true
+			}
+		}
+	}
+	return "", false
+}
+
+// topicMatches 按 topic 交换机规则判断路由键是否匹配绑定键，* 匹配一个单词，# 匹配零个或多个单词
+func topicMatches(pattern, routingKey string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(routingKey, "."))
+}
+
+func matchWords(pattern, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+	if pattern[0] == "#" {
+		for i := 0; i <= len(words); i++ {
+			if matchWords(pattern[1:], words[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(words) == 0 || (pattern[0] != "*" && pattern[0] != words[0]) {
+		return false
+	}
+	return matchWords(pattern[1:], words[1:])
+}
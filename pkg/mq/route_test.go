@@ -0,0 +1,48 @@
+package mq
+
+import (
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+)
+
+func TestTopicMatches(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, routingKey string
+		want                bool
+	}{
+		{"hello", "hello", true},
+		{"hello", "hello.world", false},
+		{"user.*", "user.created", true},
+		{"user.*", "user.created.eu", false},
+		{"user.#", "user.created.eu", true},
+		{"user.#", "user", true},
+		{"#", "anything.at.all", true},
+		{"#.created", "user.created", true},
+		{"*.created", "created", false},
+	} {
+		if got := topicMatches(tc.pattern, tc.routingKey); got != tc.want {
+			t.Errorf("topicMatches(%s, %s) = %v, want %v", tc.pattern, tc.routingKey, got, tc.want)
+		}
+	}
+}
+
+func TestExchangeFor(t *testing.T) {
+	cfg := &config.RabbitMQ{Queues: []config.QueueConfig{
+		{Name: "hello.dead", Exchange: "hello.dlx", RoutingKeys: []string{"hello"}, NoConsume: true},
+		{Name: "hello.queue", Exchange: "hello.exchange", RoutingKeys: []string{"hello"}},
+		{Name: "user.index", Exchange: "domain.events", RoutingKeys: []string{"user.#"}},
+	}}
+
+	for routingKey, want := range map[string]string{
+		"hello":        "hello.exchange",
+		"user.created": "domain.events",
+	} {
+		if got, ok := ExchangeFor(cfg, routingKey); !ok || got != want {
+			t.Errorf("ExchangeFor(%s) = %s, %v, want %s", routingKey, got, ok, want)
+		}
+	}
+	if got, ok := ExchangeFor(cfg, "order.created"); ok {
+		t.Errorf("ExchangeFor(order.created) = %s, want no match", got)
+	}
+}
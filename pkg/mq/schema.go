@@ -0,0 +1,152 @@
+package mq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// errSchemaVersionNotDeclared 消息类型声明了 schema，但没有信封版本对应的 schema
+var errSchemaVersionNotDeclared = errors.New("version is not declared")
+
+// SchemaError 载荷与消息类型声明的 schema 不一致，或信封版本未声明 schema
+type SchemaError struct {
+	MessageType string
+	Version     string
+	Err         error
+}
+
+// Error 实现 error 接口
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("payload does not match schema %s v%s: %v", e.MessageType, e.Version, e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e *SchemaError) Unwrap() error {
+	return e.Err
+}
+
+// SchemaDefinition 一个消息类型某个版本的 JSON Schema 原文
+type SchemaDefinition struct {
+	MessageType string
+	Version     string
+	Schema      json.RawMessage
+}
+
+// SchemaRegistry 消息类型的载荷 JSON Schema，按消息类型和信封版本查找
+// 未声明 schema 的消息类型不校验；声明了 schema 的类型只接受已声明的版本
+type SchemaRegistry struct {
+	schemas     map[string]map[string]*openapi3.Schema
+	definitions []SchemaDefinition
+}
+
+// LoadSchemas 从 fsys 加载 schema，文件路径为 <message_type>/v<version>.json
+func LoadSchemas(fsys fs.FS) (*SchemaRegistry, error) {
+	r := &SchemaRegistry{schemas: make(map[string]map[string]*openapi3.Schema)}
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || path.Ext(p) != ".json" {
+			return err
+		}
+
+		messageType, file := path.Split(p)
+		messageType = strings.TrimSuffix(messageType, "/")
+		version := strings.TrimSuffix(strings.TrimPrefix(file, "v"), ".json")
+		if messageType == "" || strings.Contains(messageType, "/") || !strings.HasPrefix(file, "v") || version == "" {
+			return fmt.Errorf("invalid schema path %s, expected <message_type>/v<version>.json", p)
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		return r.Register(messageType, version, data)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Register 注册一个消息类型某个版本的 schema
+func (r *SchemaRegistry) Register(messageType, version string, data []byte) error {
+	schema := &openapi3.Schema{}
+	if err := json.Unmarshal(data, schema); err != nil {
+		return fmt.Errorf("failed to parse schema %s v%s: %w", messageType, version, err)
+	}
+	if err := schema.Validate(context.Background()); err != nil {
+		return fmt.Errorf("invalid schema %s v%s: %w", messageType, version, err)
+	}
+
+	versions, ok := r.schemas[messageType]
+	if !ok {
+		versions = make(map[string]*openapi3.Schema)
+		r.schemas[messageType] = versions
+	}
+	versions[version] = schema
+	r.definitions = append(r.definitions, SchemaDefinition{
+		MessageType: messageType,
+		Version:     version,
+		Schema:      json.RawMessage(data),
+	})
+	return nil
+}
+
+// Validate 按消息类型和信封版本校验载荷，version 为空时按 DefaultEventVersion 处理
+// r 为 nil 或消息类型未声明 schema 时返回 nil
+func (r *SchemaRegistry) Validate(messageType, version string, payload []byte) error {
+	if r == nil {
+		return nil
+	}
+	versions, ok := r.schemas[messageType]
+	if !ok {
+		return nil
+	}
+	if version == "" {
+		version = DefaultEventVersion
+	}
+	schema, ok := versions[version]
+	if !ok {
+		return &SchemaError{MessageType: messageType, Version: version, Err: errSchemaVersionNotDeclared}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return &SchemaError{MessageType: messageType, Version: version, Err: err}
+	}
+	if err := schema.VisitJSON(value); err != nil {
+		return &SchemaError{MessageType: messageType, Version: version, Err: schemaViolation(err)}
+	}
+	return nil
+}
+
+// schemaViolation 只保留出错字段的路径和原因，kin-openapi 的错误信息包含完整的 schema 和值，不适合写入日志和消息头
+func schemaViolation(err error) error {
+	var violation *openapi3.SchemaError
+	if !errors.As(err, &violation) {
+		return err
+	}
+	return fmt.Errorf("/%s: %s", strings.Join(violation.JSONPointer(), "/"), violation.Reason)
+}
+
+// Definitions 返回所有 schema 原文，按消息类型和版本号升序
+func (r *SchemaRegistry) Definitions() []SchemaDefinition {
+	definitions := append([]SchemaDefinition(nil), r.definitions...)
+	sort.Slice(definitions, func(i, j int) bool {
+		a, b := definitions[i], definitions[j]
+		if a.MessageType != b.MessageType {
+			return a.MessageType < b.MessageType
+		}
+		if len(a.Version) != len(b.Version) {
+			return len(a.Version) < len(b.Version)
+		}
+		return a.Version < b.Version
+	})
+	return definitions
+}
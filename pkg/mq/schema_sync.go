@@ -0,0 +1,45 @@
+package mq
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// PushSchemas 将 schema 按版本顺序注册到 Confluent Schema Registry 兼容的服务，subject 为消息类型
+// 服务端对内容相同的 schema 返回已有的 ID，重复推送不会产生新版本
+func PushSchemas(ctx context.Context, client *http.Client, baseURL string, definitions []SchemaDefinition) error {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	for _, definition := range definitions {
+		body, err := json.Marshal(map[string]string{
+			"schemaType": "JSON",
+			"schema":     string(definition.Schema),
+		})
+		if err != nil {
+			return err
+		}
+
+		endpoint := baseURL + "/subjects/" + url.PathEscape(definition.MessageType) + "/versions"
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return fmt.Errorf("failed to push schema %s v%s: %w", definition.MessageType, definition.Version, err)
+		}
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusMultipleChoices {
+			return fmt.Errorf("failed to push schema %s v%s: %s: %s", definition.MessageType, definition.Version, resp.Status, bytes.TrimSpace(message))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package mq
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hedeqiang/skeleton/pkg/clock"
+)
+
+const helloSchemaV1 = `{
+  "type": "object",
+  "required": ["content"],
+  "properties": {"content": {"type": "string", "minLength": 1}}
+}`
+
+func testSchemas(t *testing.T) *SchemaRegistry {
+	t.Helper()
+	schemas, err := LoadSchemas(fstest.MapFS{
+		"hello/v1.json":  {Data: []byte(helloSchemaV1)},
+		"hello/v2.json":  {Data: []byte(`{"type": "object", "required": ["text"]}`)},
+		"hello/v10.json": {Data: []byte(`{"type": "object"}`)},
+		"README.md":      {Data: []byte("ignored")},
+	})
+	if err != nil {
+		t.Fatalf("LoadSchemas() error = %v", err)
+	}
+	return schemas
+}
+
+func TestSchemaRegistryValidate(t *testing.T) {
+	schemas := testSchemas(t)
+
+	tests := []struct {
+		name        string
+		messageType string
+		version     string
+		payload     string
+		wantErr     bool
+	}{
+		{"valid", "hello", "1", `{"content":"hi"}`, false},
+		{"default version", "hello", "", `{"content":"hi"}`, false},
+		{"missing field", "hello", "1", `{"sender":"dev"}`, true},
+		{"wrong type", "hello", "1", `{"content":1}`, true},
+		{"other version", "hello", "2", `{"text":"hi"}`, false},
+		{"undeclared version", "hello", "3", `{"content":"hi"}`, true},
+		{"undeclared type", "user.created", "1", `{"anything":true}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schemas.Validate(tt.messageType, tt.version, []byte(tt.payload))
+			var schemaErr *SchemaError
+			if tt.wantErr != errors.As(err, &schemaErr) {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	var nilSchemas *SchemaRegistry
+	if err := nilSchemas.Validate("hello", "1", []byte(`{}`)); err != nil {
+		t.Errorf("nil registry Validate() error = %v", err)
+	}
+}
+
+func TestLoadSchemasInvalidPath(t *testing.T) {
+	for _, name := range []string{"hello.json", "hello/1.json", "a/b/v1.json"} {
+		if _, err := LoadSchemas(fstest.MapFS{name: {Data: []byte(`{}`)}}); err == nil {
+			t.Errorf("LoadSchemas(%s) error = nil, want invalid path", name)
+		}
+	}
+	if _, err := LoadSchemas(fstest.MapFS{"hello/v1.json": {Data: []byte(`{"type": "unknown"}`)}}); err == nil {
+		t.Error("LoadSchemas() with invalid schema error = nil")
+	}
+}
+
+func TestSchemaRegistryDefinitions(t *testing.T) {
+	var versions []string
+	for _, definition := range testSchemas(t).Definitions() {
+		versions = append(versions, definition.Version)
+	}
+	if len(versions) != 3 || versions[0] != "1" || versions[1] != "2" || versions[2] != "10" {
+		t.Errorf("Definitions() versions = %v, want [1 2 10]", versions)
+	}
+}
+
+func TestEventPublisherSchemaValidation(t *testing.T) {
+	publisher := NewEventPublisher(nil, staticIDGenerator{}, clock.Frozen(), "skeleton", WithSchemaRegistry(testSchemas(t)))
+
+	if _, err := publisher.newPublishing("hello", map[string]string{"content": "hi"}); err != nil {
+		t.Fatalf("newPublishing() with valid payload error = %v", err)
+	}
+
+	var schemaErr *SchemaError
+	_, err := publisher.newPublishing("hello", map[string]string{"sender": "dev"})
+	if !errors.As(err, &schemaErr) || schemaErr.Version != DefaultEventVersion {
+		t.Errorf("newPublishing() with invalid payload error = %v, want SchemaError", err)
+	}
+	_, err = publisher.newPublishing("hello", map[string]string{"content": "hi"}, WithEventVersion("3"))
+	if !errors.As(err, &schemaErr) {
+		t.Errorf("newPublishing() with undeclared version error = %v, want SchemaError", err)
+	}
+}
+
+func TestPushSchemas(t *testing.T) {
+	var subjects []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["schemaType"] != "JSON" || body["schema"] == "" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			return
+		}
+		subjects = append(subjects, r.URL.Path)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer server.Close()
+
+	err := PushSchemas(t.Context(), server.Client(), server.URL+"/", []SchemaDefinition{
+		{MessageType: "user.created", Version: "1", Schema: json.RawMessage(`{"type":"object"}`)},
+	})
+	if err != nil {
+		t.Fatalf("PushSchemas() error = %v", err)
+	}
+	if len(subjects) != 1 || subjects[0] != "/subjects/user.created/versions" {
+		t.Errorf("pushed subjects = %v", subjects)
+	}
+
+	err = PushSchemas(t.Context(), server.Client(), server.URL, []SchemaDefinition{{MessageType: "hello", Version: "1"}})
+	if err == nil {
+		t.Error("PushSchemas() with rejected schema error = nil")
+	}
+}
+
+func TestSchemaErrorMessage(t *testing.T) {
+	err := testSchemas(t).Validate("hello", "1", []byte(`{"content":""}`))
+	want := `payload does not match schema hello v1: /content: minimum string length is 1`
+	if err == nil || err.Error() != want {
+		t.Errorf("Validate() error = %v, want %s", err, want)
+	}
+}
@@ -0,0 +1,123 @@
+package mq
+
+import (
+	"context"
+
+	"github.com/hedeqiang/skeleton/pkg/trace"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// tracerName 消息队列链路追踪的 instrumentation 名称
+const tracerName = "github.com/hedeqiang/skeleton/pkg/mq"
+
+// propagator 通过 AMQP 消息头传递 W3C traceparent 和 baggage
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// headerCarrier 将 amqp.Table 适配为 propagation.TextMapCarrier
+type headerCarrier amqp.Table
+
+// Get 读取消息头
+func (c headerCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+// Set 写入消息头
+func (c headerCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// Keys 返回所有消息头名称
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// startPublishSpan 创建生产者 span 并将链路上下文写入消息头
+func startPublishSpan(ctx context.Context, exchange, routingKey string, msg *amqp.Publishing) (context.Context, oteltrace.Span) {
+	ctx, span := otel.Tracer(tracerName).Start(withRequestTrace(ctx), exchangeName(exchange)+" publish",
+		oteltrace.WithSpanKind(oteltrace.SpanKindProducer),
+		oteltrace.WithAttributes(append(messagingAttributes(exchange, routingKey, msg.MessageId),
+			semconv.MessagingOperationTypePublish,
+		)...),
+	)
+
+	// 复制消息头，避免修改调用方持有的 amqp.Table
+	headers := make(amqp.Table, len(msg.Headers)+2)
+	for key, value := range msg.Headers {
+		headers[key] = value
+	}
+	msg.Headers = headers
+	propagator.Inject(ctx, headerCarrier(msg.Headers))
+	return ctx, span
+}
+
+// startConsumeSpan 从消息头中提取生产者的链路上下文并创建消费者 span，返回的 ctx 由 parent 派生
+// 消费者 span 作为生产者 span 的子 span 并同时链接到生产者，使一次请求触发的异步处理出现在同一条链路中
+func startConsumeSpan(parent context.Context, queue string, d amqp.Delivery) (context.Context, oteltrace.Span) {
+	parent = propagator.Extract(parent, headerCarrier(d.Headers))
+
+	// 消费端的目标为队列名，交换机记录在独立属性中
+	attrs := append(messagingAttributes(queue, d.RoutingKey, d.MessageId),
+		semconv.MessagingOperationTypeDeliver,
+		attribute.String("messaging.rabbitmq.exchange", d.Exchange),
+	)
+	opts := []oteltrace.SpanStartOption{
+		oteltrace.WithSpanKind(oteltrace.SpanKindConsumer),
+		oteltrace.WithAttributes(attrs...),
+	}
+	if link := oteltrace.LinkFromContext(parent); link.SpanContext.IsValid() {
+		opts = append(opts, oteltrace.WithLinks(link))
+	}
+
+	ctx, span := otel.Tracer(tracerName).Start(parent, queue+" process", opts...)
+
+	// 同步写入 traceparent，未启用 OpenTelemetry SDK 时日志仍可按 trace id 关联
+	if tp, ok := trace.FromSpanContext(span.SpanContext()); ok {
+		ctx = trace.NewContext(ctx, tp)
+	}
+	return ctx, span
+}
+
+// endSpan 记录错误并结束 span
+func endSpan(span oteltrace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// withRequestTrace 当前没有 OpenTelemetry span 时，使用 RequestID 中间件生成的 traceparent 作为远程父 span
+func withRequestTrace(ctx context.Context) context.Context {
+	if oteltrace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	tp, ok := trace.FromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{trace.Header: tp.String()})
+}
+
+func messagingAttributes(destination, routingKey, messageID string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.MessagingSystemRabbitmq,
+		semconv.MessagingDestinationName(destination),
+		semconv.MessagingRabbitmqDestinationRoutingKey(routingKey),
+	}
+	if messageID != "" {
+		attrs = append(attrs, semconv.MessagingMessageID(messageID))
+	}
+	return attrs
+}
@@ -0,0 +1,49 @@
+package mq
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/pkg/trace"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func TestTracePropagation(t *testing.T) {
+	request, err := trace.Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	caller := amqp.Table{"x-custom": "value"}
+	msg := amqp.Publishing{MessageId: "msg-1", Headers: caller}
+	_, span := startPublishSpan(trace.NewContext(context.Background(), request), "hello.exchange", "hello.world", &msg)
+	endSpan(span, nil)
+
+	if _, ok := caller[trace.Header]; ok {
+		t.Fatal("publish should not modify caller headers")
+	}
+	if msg.Headers["x-custom"] != "value" {
+		t.Fatalf("custom header lost: %v", msg.Headers)
+	}
+
+	ctx, span := startConsumeSpan(context.Background(), "hello.queue", amqp.Delivery{Headers: msg.Headers, RoutingKey: "hello.world", MessageId: "msg-1"})
+	endSpan(span, nil)
+
+	got, ok := trace.FromContext(ctx)
+	if !ok {
+		t.Fatal("consumer context should carry traceparent")
+	}
+	if got.TraceID != request.TraceID {
+		t.Fatalf("trace id = %s, want %s", got.TraceID, request.TraceID)
+	}
+}
+
+func TestConsumeWithoutTrace(t *testing.T) {
+	ctx, span := startConsumeSpan(context.Background(), "hello.queue", amqp.Delivery{})
+	endSpan(span, nil)
+
+	if _, ok := trace.FromContext(ctx); ok {
+		t.Fatal("delivery without headers should not carry traceparent")
+	}
+}
@@ -0,0 +1,309 @@
+package openapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxMockDepth 生成示例时的最大嵌套深度，避免自引用的 schema 无限递归
+const maxMockDepth = 8
+
+// Mock 按 OpenAPI 规范返回示例响应的 HTTP 处理器，用于后端接口实现之前的前端联调
+//
+// 请求先按规范校验，不符合时返回 400；响应优先使用规范中的 example/examples，
+// 没有时按 schema 生成（使用字段上的 example、default、enum）。
+// 请求可通过 Prefer 头选择响应：code=404 指定状态码，example=<name> 指定命名示例，dynamic=true 按类型随机生成
+type Mock struct {
+	validator *Validator
+	dynamic   bool
+}
+
+// MockOption Mock 选项
+type MockOption func(*Mock)
+
+// WithDynamicExamples 忽略规范中的示例，始终按 schema 类型随机生成响应
+func WithDynamicExamples(dynamic bool) MockOption {
+	return func(m *Mock) {
+		m.dynamic = dynamic
+	}
+}
+
+// LoadMock 加载规范文件并创建 Mock
+func LoadMock(path string, opts ...MockOption) (*Mock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+	return NewMock(data, opts...)
+}
+
+// NewMock 从规范内容创建 Mock
+func NewMock(data []byte, opts ...MockOption) (*Mock, error) {
+	_, router, err := load(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Mock{validator: &Validator{router: router}}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m, nil
+}
+
+// ServeHTTP 返回请求对应操作的示例响应，允许任意来源跨域访问
+func (m *Mock) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		w.Header().Set("Access-Control-Allow-Methods", r.Header.Get("Access-Control-Request-Method"))
+		w.Header().Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	input, err := m.validator.ValidateRequest(r.Context(), r)
+	if errors.Is(err, ErrRouteNotFound) {
+		writeMockJSON(w, http.StatusNotFound, map[string]string{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		writeMockJSON(w, http.StatusBadRequest, map[string]string{"message": err.Error()})
+		return
+	}
+
+	prefer := parsePrefer(r.Header.Get("Prefer"))
+	status, response := selectResponse(input.Route.Operation, prefer["code"])
+	if response == nil {
+		writeMockJSON(w, http.StatusNotImplemented, map[string]string{"message": "no response defined for " + input.Route.Path})
+		return
+	}
+
+	contentType, media := selectMediaType(response.Content)
+	if media == nil {
+		w.WriteHeader(status)
+		return
+	}
+
+	dynamic := m.dynamic || prefer["dynamic"] == "true"
+	body, err := json.Marshal(mockBody(media, prefer["example"], dynamic))
+	if err != nil {
+		writeMockJSON(w, http.StatusInternalServerError, map[string]string{"message": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// parsePrefer 解析 Prefer 请求头，例如 "code=404, example=not_found"
+func parsePrefer(header string) map[string]string {
+	prefer := map[string]string{}
+	for _, part := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ';' }) {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		prefer[strings.ToLower(key)] = strings.Trim(value, `"`)
+	}
+	return prefer
+}
+
+// selectResponse 选择响应，未指定状态码时使用最小的 2xx，没有 2xx 时使用 default
+func selectResponse(op *openapi3.Operation, code string) (int, *openapi3.Response) {
+	if op.Responses == nil {
+		return 0, nil
+	}
+
+	if code != "" {
+		status, err := strconv.Atoi(code)
+		if ref := op.Responses.Value(code); err == nil && ref != nil && ref.Value != nil {
+			return status, ref.Value
+		}
+	}
+
+	codes := make([]string, 0, op.Responses.Len())
+	for key := range op.Responses.Map() {
+		codes = append(codes, key)
+	}
+	sort.Strings(codes)
+	for _, key := range codes {
+		status, err := strconv.Atoi(key)
+		if err == nil && status >= 200 && status < 300 {
+			if ref := op.Responses.Value(key); ref.Value != nil {
+				return status, ref.Value
+			}
+		}
+	}
+	if ref := op.Responses.Default(); ref != nil && ref.Value != nil {
+		return http.StatusOK, ref.Value
+	}
+	return 0, nil
+}
+
+// selectMediaType 优先选择 JSON 内容类型
+func selectMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	if media := content.Get("application/json"); media != nil {
+		return "application/json", media
+	}
+	types := make([]string, 0, len(content))
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	for _, contentType := range types {
+		if strings.HasSuffix(contentType, "json") {
+			return contentType, content[contentType]
+		}
+	}
+	return "", nil
+}
+
+// mockBody 生成响应体：指定的命名示例、媒体类型上的示例、第一个命名示例，最后按 schema 生成
+func mockBody(media *openapi3.MediaType, exampleName string, dynamic bool) any {
+	if !dynamic {
+		if ref := media.Examples[exampleName]; ref != nil && ref.Value != nil {
+			return ref.Value.Value
+		}
+		if media.Example != nil {
+			return media.Example
+		}
+		names := make([]string, 0, len(media.Examples))
+		for name := range media.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if ref := media.Examples[name]; ref.Value != nil {
+				return ref.Value.Value
+			}
+		}
+	}
+	return generate(media.Schema, dynamic, 0)
+}
+
+// generate 按 schema 生成示例值，dynamic 为 false 时优先使用 example、default 且结果稳定
+func generate(ref *openapi3.SchemaRef, dynamic bool, depth int) any {
+	if ref == nil || ref.Value == nil || depth > maxMockDepth {
+		return nil
+	}
+	schema := ref.Value
+
+	if !dynamic {
+		if schema.Example != nil {
+			return schema.Example
+		}
+		if schema.Default != nil {
+			return schema.Default
+		}
+	}
+	if len(schema.Enum) > 0 {
+		if dynamic {
+			return schema.Enum[rand.IntN(len(schema.Enum))]
+		}
+		return schema.Enum[0]
+	}
+
+	// swag 将 response.Response{data=X} 生成为 allOf，合并各部分的字段
+	if len(schema.AllOf) > 0 {
+		merged := map[string]any{}
+		for _, part := range schema.AllOf {
+			if obj, ok := generate(part, dynamic, depth+1).(map[string]any); ok {
+				for key, value := range obj {
+					merged[key] = value
+				}
+			}
+		}
+		for key, value := range generateProperties(schema, dynamic, depth) {
+			merged[key] = value
+		}
+		return merged
+	}
+	if len(schema.OneOf) > 0 {
+		return generate(schema.OneOf[0], dynamic, depth+1)
+	}
+	if len(schema.AnyOf) > 0 {
+		return generate(schema.AnyOf[0], dynamic, depth+1)
+	}
+
+	switch {
+	case schema.Type.Is(openapi3.TypeObject) || len(schema.Properties) > 0:
+		return generateProperties(schema, dynamic, depth)
+	case schema.Type.Is(openapi3.TypeArray):
+		item := generate(schema.Items, dynamic, depth+1)
+		if item == nil {
+			return []any{}
+		}
+		return []any{item}
+	case schema.Type.Is(openapi3.TypeString):
+		return generateString(schema, dynamic)
+	case schema.Type.Is(openapi3.TypeInteger):
+		return int64(generateNumber(schema, dynamic))
+	case schema.Type.Is(openapi3.TypeNumber):
+		return generateNumber(schema, dynamic)
+	case schema.Type.Is(openapi3.TypeBoolean):
+		return !dynamic || rand.IntN(2) == 0
+	}
+	return nil
+}
+
+// generateProperties 为对象的每个字段生成示例值
+func generateProperties(schema *openapi3.Schema, dynamic bool, depth int) map[string]any {
+	obj := make(map[string]any, len(schema.Properties))
+	for name, property := range schema.Properties {
+		obj[name] = generate(property, dynamic, depth+1)
+	}
+	return obj
+}
+
+// generateString 按 format 生成字符串
+func generateString(schema *openapi3.Schema, dynamic bool) string {
+	n := 1
+	if dynamic {
+		n = rand.IntN(1000) + 1
+	}
+	switch schema.Format {
+	case "date-time":
+		return fmt.Sprintf("2024-01-01T00:00:%02dZ", n%60)
+	case "date":
+		return fmt.Sprintf("2024-01-%02d", n%28+1)
+	case "email":
+		return fmt.Sprintf("user%d@example.com", n)
+	case "uri", "url":
+		return fmt.Sprintf("https://example.com/%d", n)
+	case "uuid":
+		return fmt.Sprintf("00000000-0000-4000-8000-%012d", n)
+	}
+	if dynamic {
+		return fmt.Sprintf("string-%d", n)
+	}
+	return "string"
+}
+
+// generateNumber 生成满足 minimum、maximum 的数值
+func generateNumber(schema *openapi3.Schema, dynamic bool) float64 {
+	lower := 1.0
+	if schema.Min != nil {
+		lower = *schema.Min
+	}
+	upper := lower + 1000
+	if schema.Max != nil && *schema.Max < upper {
+		upper = *schema.Max
+	}
+	if !dynamic || upper <= lower {
+		return lower
+	}
+	return lower + float64(rand.IntN(int(upper-lower)+1))
+}
+
+// writeMockJSON 写出 JSON 响应
+func writeMockJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
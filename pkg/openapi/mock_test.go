@@ -0,0 +1,130 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const mockSpec = `
+swagger: "2.0"
+info:
+  title: test
+  version: "1.0"
+basePath: /api/v1
+paths:
+  /users/{id}:
+    get:
+      produces: [application/json]
+      parameters:
+        - {in: path, name: id, required: true, type: integer}
+      responses:
+        "200":
+          description: ok
+          schema:
+            allOf:
+              - $ref: "#/definitions/Response"
+              - type: object
+                properties:
+                  data: {$ref: "#/definitions/User"}
+        "404":
+          description: not found
+          schema: {$ref: "#/definitions/Response"}
+          examples:
+            application/json: {code: 404, message: "用户不存在"}
+  /users:
+    delete:
+      responses:
+        "204": {description: deleted}
+definitions:
+  Response:
+    type: object
+    properties:
+      code: {type: integer}
+      message: {type: string, example: "success"}
+  User:
+    type: object
+    properties:
+      id: {type: integer, minimum: 1}
+      username: {type: string, example: "alice"}
+      email: {type: string, format: email}
+      status: {type: string, enum: [active, disabled]}
+      roles: {type: array, items: {type: string}}
+      created_at: {type: string, format: date-time}
+`
+
+func serveMock(t *testing.T, m *Mock, method, target string, header map[string]string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, target, nil)
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	w := httptest.NewRecorder()
+	m.ServeHTTP(w, req)
+	return w
+}
+
+func TestMockGeneratesFromSchema(t *testing.T) {
+	m, err := NewMock([]byte(mockSpec))
+	if err != nil {
+		t.Fatalf("NewMock failed: %v", err)
+	}
+
+	w := serveMock(t, m, http.MethodGet, "/api/v1/users/1", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	want := `{"code":1,"data":{"created_at":"2024-01-01T00:00:01Z","email":"user1@example.com","id":1,"roles":["string"],"status":"active","username":"alice"},"message":"success"}`
+	if got, _ := json.Marshal(body); string(got) != want {
+		t.Errorf("body = %s\nwant %s", got, want)
+	}
+}
+
+func TestMockPrefer(t *testing.T) {
+	m, err := NewMock([]byte(mockSpec))
+	if err != nil {
+		t.Fatalf("NewMock failed: %v", err)
+	}
+
+	w := serveMock(t, m, http.MethodGet, "/api/v1/users/1", map[string]string{"Prefer": "code=404"})
+	if w.Code != http.StatusNotFound || !strings.Contains(w.Body.String(), "用户不存在") {
+		t.Errorf("Prefer code=404: %d %s", w.Code, w.Body)
+	}
+
+	w = serveMock(t, m, http.MethodGet, "/api/v1/users/1", map[string]string{"Prefer": "dynamic=true"})
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "alice") {
+		t.Errorf("Prefer dynamic=true should ignore examples: %d %s", w.Code, w.Body)
+	}
+}
+
+func TestMockRequests(t *testing.T) {
+	m, err := NewMock([]byte(mockSpec))
+	if err != nil {
+		t.Fatalf("NewMock failed: %v", err)
+	}
+
+	for _, tc := range []struct {
+		method, target string
+		header         map[string]string
+		status         int
+	}{
+		{http.MethodGet, "/api/v1/users/abc", nil, http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/orders", nil, http.StatusNotFound},
+		{http.MethodDelete, "/api/v1/users", nil, http.StatusNoContent},
+		{http.MethodOptions, "/api/v1/users/1", map[string]string{"Access-Control-Request-Method": "GET"}, http.StatusNoContent},
+	} {
+		w := serveMock(t, m, tc.method, tc.target, tc.header)
+		if w.Code != tc.status {
+			t.Errorf("%s %s = %d, want %d (%s)", tc.method, tc.target, w.Code, tc.status, w.Body)
+		}
+		if w.Header().Get("Access-Control-Allow-Origin") != "*" {
+			t.Errorf("%s %s missing CORS header", tc.method, tc.target)
+		}
+	}
+}
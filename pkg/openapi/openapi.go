@@ -0,0 +1,167 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/oasdiff/yaml"
+)
+
+// ErrRouteNotFound 请求的路径或方法未在规范中定义
+var ErrRouteNotFound = errors.New("route not found in OpenAPI spec")
+
+// Validator 按 OpenAPI 规范校验请求和响应
+type Validator struct {
+	router routers.Router
+}
+
+// Load 加载规范文件，支持 OpenAPI 3 和 swag 生成的 Swagger 2.0，格式可以是 JSON 或 YAML
+func Load(path string) (*Validator, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI spec: %w", err)
+	}
+	return New(data)
+}
+
+// New 从规范内容创建校验器
+func New(data []byte) (*Validator, error) {
+	_, router, err := load(data)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{router: router}, nil
+}
+
+// load 解析并校验规范，返回文档和按路径、方法匹配操作的路由器
+func load(data []byte) (*openapi3.T, routers.Router, error) {
+	doc, err := parse(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+
+	// 只保留 server 的路径部分，规范中的 host 与实际部署地址不一致时也能匹配
+	for _, server := range doc.Servers {
+		if u, err := url.Parse(server.URL); err == nil {
+			server.URL = u.Path
+		}
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build OpenAPI router: %w", err)
+	}
+	return doc, router, nil
+}
+
+// parse 解析规范，Swagger 2.0 转换为 OpenAPI 3
+func parse(data []byte) (*openapi3.T, error) {
+	var probe struct {
+		Swagger string `json:"swagger"`
+	}
+	if err := yaml.Unmarshal(data, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+
+	if probe.Swagger == "" {
+		doc, err := openapi3.NewLoader().LoadFromData(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+		}
+		return doc, nil
+	}
+
+	var doc2 openapi2.T
+	if err := yaml.Unmarshal(data, &doc2); err != nil {
+		return nil, fmt.Errorf("failed to parse Swagger spec: %w", err)
+	}
+	doc, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert Swagger spec: %w", err)
+	}
+	// 未声明 host 时转换结果不包含 server，basePath 会丢失
+	if len(doc.Servers) == 0 && doc2.BasePath != "" {
+		doc.Servers = openapi3.Servers{{URL: doc2.BasePath}}
+	}
+	copyResponseExamples(&doc2, doc)
+	return doc, nil
+}
+
+// ValidateRequest 校验请求的路径参数、查询参数、请求头和请求体，认证要求不做校验
+// 请求体读取后会重新放回 req.Body；返回的 input 用于之后校验响应。路由未定义时返回 ErrRouteNotFound
+func (v *Validator) ValidateRequest(ctx context.Context, req *http.Request) (*openapi3filter.RequestValidationInput, error) {
+	route, pathParams, err := v.router.FindRoute(req)
+	if err != nil {
+		if errors.Is(err, routers.ErrPathNotFound) || errors.Is(err, routers.ErrMethodNotAllowed) {
+			return nil, ErrRouteNotFound
+		}
+		return nil, err
+	}
+
+	input := &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			MultiError:         true,
+		},
+	}
+	return input, openapi3filter.ValidateRequest(ctx, input)
+}
+
+// ValidateResponse 校验响应状态码、响应头和响应体
+func (v *Validator) ValidateResponse(ctx context.Context, input *openapi3filter.RequestValidationInput, status int, header http.Header, body []byte) error {
+	return openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 status,
+		Header:                 header,
+		Body:                   io.NopCloser(bytes.NewReader(body)),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+	})
+}
+
+// copyResponseExamples 转换时会丢弃 Swagger 2.0 响应的 examples，按内容类型补回到 OpenAPI 3 的 example
+func copyResponseExamples(doc2 *openapi2.T, doc *openapi3.T) {
+	for path, item2 := range doc2.Paths {
+		item := doc.Paths.Value(path)
+		if item == nil {
+			continue
+		}
+		for method, op2 := range item2.Operations() {
+			op := item.GetOperation(method)
+			if op == nil || op.Responses == nil {
+				continue
+			}
+			for code, resp2 := range op2.Responses {
+				ref := op.Responses.Value(code)
+				if resp2 == nil || ref == nil || ref.Value == nil {
+					continue
+				}
+				for contentType, example := range resp2.Examples {
+					if media := ref.Value.Content.Get(contentType); media != nil && media.Example == nil {
+						media.Example = example
+					}
+				}
+			}
+		}
+	}
+}
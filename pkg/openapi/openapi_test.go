@@ -0,0 +1,75 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const swaggerSpec = `
+swagger: "2.0"
+info:
+  title: test
+  version: "1.0"
+host: api.example.com
+basePath: /api/v1
+paths:
+  /users:
+    post:
+      consumes: [application/json]
+      produces: [application/json]
+      parameters:
+        - in: body
+          name: user
+          required: true
+          schema:
+            type: object
+            required: [username]
+            properties:
+              username: {type: string}
+      responses:
+        "201":
+          description: created
+          schema:
+            type: object
+            required: [code]
+            properties:
+              code: {type: integer}
+`
+
+func TestValidateRequest(t *testing.T) {
+	v, err := New([]byte(swaggerSpec))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	ctx := context.Background()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(`{"username":"alice"}`))
+	req.Header.Set("Content-Type", "application/json")
+	input, err := v.ValidateRequest(ctx, req)
+	if err != nil {
+		t.Fatalf("valid request rejected: %v", err)
+	}
+
+	header := http.Header{"Content-Type": []string{"application/json"}}
+	if err := v.ValidateResponse(ctx, input, http.StatusCreated, header, []byte(`{"code":0}`)); err != nil {
+		t.Fatalf("valid response rejected: %v", err)
+	}
+	if err := v.ValidateResponse(ctx, input, http.StatusCreated, header, []byte(`{"code":"0"}`)); err == nil {
+		t.Fatal("expected response validation error")
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	if _, err := v.ValidateRequest(ctx, req); err == nil {
+		t.Fatal("expected request validation error")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/undocumented", nil)
+	if _, err := v.ValidateRequest(ctx, req); !errors.Is(err, ErrRouteNotFound) {
+		t.Fatalf("expected ErrRouteNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package password
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// argon2idPrefix Argon2id 哈希前缀，完整格式为 $argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>，与 libsodium、PHP 等实现兼容
+const argon2idPrefix = "$argon2id$"
+
+// RFC 9106 第二推荐参数，适合内存受限的服务端
+const (
+	defaultArgon2Memory      = 64 * 1024
+	defaultArgon2Iterations  = 3
+	defaultArgon2Parallelism = 2
+	defaultArgon2SaltLength  = 16
+	defaultArgon2KeyLength   = 32
+)
+
+// argon2idParams Argon2id 参数，盐长度和哈希长度同样影响是否需要升级
+type argon2idParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	saltLength  uint32
+	keyLength   uint32
+}
+
+// newArgon2idParams 补全未配置的参数
+func newArgon2idParams(cfg config.Argon2idParams) argon2idParams {
+	p := argon2idParams{
+		memory:      cfg.Memory,
+		iterations:  cfg.Iterations,
+		parallelism: cfg.Parallelism,
+		saltLength:  cfg.SaltLength,
+		keyLength:   cfg.KeyLength,
+	}
+	if p.memory == 0 {
+		p.memory = defaultArgon2Memory
+	}
+	if p.iterations == 0 {
+		p.iterations = defaultArgon2Iterations
+	}
+	if p.parallelism == 0 {
+		p.parallelism = defaultArgon2Parallelism
+	}
+	if p.saltLength == 0 {
+		p.saltLength = defaultArgon2SaltLength
+	}
+	if p.keyLength == 0 {
+		p.keyLength = defaultArgon2KeyLength
+	}
+	return p
+}
+
+// key 计算密码的 Argon2id 哈希
+func (p argon2idParams) key(password string, salt []byte) []byte {
+	return argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, p.keyLength)
+}
+
+// hashArgon2id 生成随机盐并编码哈希
+func hashArgon2id(password string, p argon2idParams) (string, error) {
+	salt := make([]byte, p.saltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix, argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(p.key(password, salt)),
+	), nil
+}
+
+// decodeArgon2id 解析编码后的哈希
+func decodeArgon2id(hash string) (argon2idParams, []byte, []byte, error) {
+	var p argon2idParams
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return p, nil, nil, ErrUnknownHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, nil, nil, ErrUnknownHash
+	}
+	if version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("unsupported argon2 version %d", version)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return p, nil, nil, ErrUnknownHash
+	}
+	if p.iterations == 0 || p.parallelism == 0 {
+		return p, nil, nil, ErrUnknownHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, nil, nil, ErrUnknownHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return p, nil, nil, ErrUnknownHash
+	}
+	if len(key) == 0 {
+		return p, nil, nil, ErrUnknownHash
+	}
+	p.saltLength = uint32(len(salt))
+	p.keyLength = uint32(len(key))
+	return p, salt, key, nil
+}
@@ -0,0 +1,73 @@
+package password
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultBreachURL Have I Been Pwned 的 Pwned Passwords 范围查询接口
+const DefaultBreachURL = "https://api.pwnedpasswords.com/range/"
+
+// BreachChecker 按 k-anonymity 方式查询密码在泄露数据中出现的次数
+// 只发送密码 SHA-1 的前 5 位十六进制字符，服务端返回该前缀下所有哈希后缀及次数，在本地比对，密码和完整哈希都不会离开本机
+type BreachChecker struct {
+	client *http.Client
+	url    string
+}
+
+// NewBreachChecker 创建泄露检查器，url 为空时使用 DefaultBreachURL，兼容 Pwned Passwords 协议的自建服务同样可用
+func NewBreachChecker(url string, client *http.Client) *BreachChecker {
+	if url == "" {
+		url = DefaultBreachURL
+	}
+	if !strings.HasSuffix(url, "/") {
+		url += "/"
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &BreachChecker{client: client, url: url}
+}
+
+// Count 返回密码在泄露数据中出现的次数，未出现时返回 0
+func (b *BreachChecker) Count(ctx context.Context, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:5], hash[5:]
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.url+prefix, nil)
+	if err != nil {
+		return 0, err
+	}
+	// 响应中补充随机的无效后缀，避免通过响应大小推断查询的前缀
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("breach range query returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		candidate, count, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if !ok || !strings.EqualFold(candidate, suffix) {
+			continue
+		}
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return 0, fmt.Errorf("invalid breach count %q", count)
+		}
+		return n, nil
+	}
+	return 0, scanner.Err()
+}
@@ -0,0 +1,103 @@
+// Package password 密码哈希和强度检查
+// 支持 bcrypt 和 Argon2id，哈希中记录算法和参数，校验时按哈希自身的参数计算；
+// 与当前配置不一致的哈希在校验成功后报告需要升级，由调用方用明文密码重新哈希并保存
+package password
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrUnknownHash 无法识别的哈希格式
+var ErrUnknownHash = errors.New("unknown password hash format")
+
+// Hasher 按配置的算法和参数哈希密码
+type Hasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2id   argon2idParams
+}
+
+// New 创建密码哈希器，算法未知或参数超出范围时返回错误
+func New(cfg *config.Config) (*Hasher, error) {
+	c := cfg.Password
+	h := &Hasher{
+		algorithm:  c.Algorithm,
+		bcryptCost: c.BcryptCost,
+		argon2id:   newArgon2idParams(c.Argon2id),
+	}
+	if h.algorithm == "" {
+		h.algorithm = config.PasswordAlgorithmBcrypt
+	}
+	if h.bcryptCost == 0 {
+		h.bcryptCost = bcrypt.DefaultCost
+	}
+
+	switch h.algorithm {
+	case config.PasswordAlgorithmBcrypt:
+		if h.bcryptCost < bcrypt.MinCost || h.bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("password.bcrypt_cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, h.bcryptCost)
+		}
+	case config.PasswordAlgorithmArgon2id:
+	default:
+		return nil, fmt.Errorf("unsupported password.algorithm %q", h.algorithm)
+	}
+	return h, nil
+}
+
+// Hash 使用配置的算法哈希密码
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.algorithm == config.PasswordAlgorithmArgon2id {
+		return hashArgon2id(password, h.argon2id)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.bcryptCost)
+	return string(hash), err
+}
+
+// Verify 校验密码，返回是否匹配以及哈希是否需要升级
+// 只有匹配时才会报告需要升级；哈希格式无法识别时返回 ErrUnknownHash
+func (h *Hasher) Verify(password, hash string) (match, rehash bool, err error) {
+	switch {
+	case strings.HasPrefix(hash, argon2idPrefix):
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, false, err
+		}
+		if !Equal(string(params.key(password, salt)), string(key)) {
+			return false, false, nil
+		}
+		return true, h.algorithm != config.PasswordAlgorithmArgon2id || params != h.argon2id, nil
+
+	case isBcrypt(hash):
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, false, nil
+			}
+			return false, false, err
+		}
+		cost, err := bcrypt.Cost([]byte(hash))
+		if err != nil {
+			return false, false, err
+		}
+		return true, h.algorithm != config.PasswordAlgorithmBcrypt || cost != h.bcryptCost, nil
+
+	default:
+		return false, false, ErrUnknownHash
+	}
+}
+
+// Equal 以常量时间比较两个字符串，用于比较令牌哈希等秘密值，避免通过响应时间逐字节猜测
+func Equal(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// isBcrypt 判断是否为 bcrypt 哈希（$2a$、$2b$、$2y$）
+func isBcrypt(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
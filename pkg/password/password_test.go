@@ -0,0 +1,108 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// fastArgon2id 测试使用的低成本参数
+var fastArgon2id = config.Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1}
+
+func newHasher(t *testing.T, cfg config.Password) *Hasher {
+	t.Helper()
+	h, err := New(&config.Config{Password: cfg})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return h
+}
+
+func TestHashAndVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    config.Password
+		prefix string
+	}{
+		{"bcrypt", config.Password{BcryptCost: bcrypt.MinCost}, "$2a$04$"},
+		{"argon2id", config.Password{Algorithm: config.PasswordAlgorithmArgon2id, Argon2id: fastArgon2id}, "$argon2id$v=19$m=64,t=1,p=1$"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHasher(t, tt.cfg)
+			hash, err := h.Hash("secret")
+			if err != nil {
+				t.Fatalf("Hash failed: %v", err)
+			}
+			if !strings.HasPrefix(hash, tt.prefix) {
+				t.Fatalf("hash %q does not start with %q", hash, tt.prefix)
+			}
+
+			match, rehash, err := h.Verify("secret", hash)
+			if !match || rehash || err != nil {
+				t.Fatalf("Verify(correct) = %v, %v, %v", match, rehash, err)
+			}
+			match, rehash, err = h.Verify("wrong", hash)
+			if match || rehash || err != nil {
+				t.Fatalf("Verify(wrong) = %v, %v, %v", match, rehash, err)
+			}
+		})
+	}
+}
+
+func TestVerifyReportsOutdatedHashes(t *testing.T) {
+	oldBcrypt, _ := newHasher(t, config.Password{BcryptCost: bcrypt.MinCost}).Hash("secret")
+	oldArgon2id, _ := newHasher(t, config.Password{Algorithm: config.PasswordAlgorithmArgon2id, Argon2id: fastArgon2id}).Hash("secret")
+
+	tests := []struct {
+		name string
+		cfg  config.Password
+		hash string
+	}{
+		{"bcrypt cost raised", config.Password{BcryptCost: bcrypt.MinCost + 1}, oldBcrypt},
+		{"bcrypt to argon2id", config.Password{Algorithm: config.PasswordAlgorithmArgon2id, Argon2id: fastArgon2id}, oldBcrypt},
+		{"argon2id memory raised", config.Password{Algorithm: config.PasswordAlgorithmArgon2id, Argon2id: config.Argon2idParams{Memory: 128, Iterations: 1, Parallelism: 1}}, oldArgon2id},
+		{"argon2id to bcrypt", config.Password{BcryptCost: bcrypt.MinCost}, oldArgon2id},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHasher(t, tt.cfg)
+			match, rehash, err := h.Verify("secret", tt.hash)
+			if !match || !rehash || err != nil {
+				t.Fatalf("Verify = %v, %v, %v; want match and rehash", match, rehash, err)
+			}
+			// 密码错误时不报告升级
+			if _, rehash, _ := h.Verify("wrong", tt.hash); rehash {
+				t.Fatal("mismatched password must not report rehash")
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsUnknownHashes(t *testing.T) {
+	h := newHasher(t, config.Password{})
+	for _, hash := range []string{"", "plaintext", "$argon2id$v=19$m=64,t=0,p=1$c2FsdA$a2V5", "$argon2id$v=19$broken"} {
+		if _, _, err := h.Verify("secret", hash); !errors.Is(err, ErrUnknownHash) {
+			t.Errorf("Verify(%q) error = %v, want ErrUnknownHash", hash, err)
+		}
+	}
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	if _, err := New(&config.Config{Password: config.Password{Algorithm: "md5"}}); err == nil {
+		t.Error("unknown algorithm must be rejected")
+	}
+	if _, err := New(&config.Config{Password: config.Password{BcryptCost: 40}}); err == nil {
+		t.Error("bcrypt cost above the maximum must be rejected")
+	}
+}
+
+func TestEqual(t *testing.T) {
+	if !Equal("abc", "abc") || Equal("abc", "abd") || Equal("abc", "ab") {
+		t.Fatal("Equal returned an unexpected result")
+	}
+}
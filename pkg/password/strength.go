@@ -0,0 +1,125 @@
+package password
+
+import (
+	_ "embed"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Weakness 密码强度检查未通过的原因
+type Weakness string
+
+// 密码强度检查未通过的原因
+const (
+	WeaknessTooShort         Weakness = "too_short"
+	WeaknessCommon           Weakness = "common"
+	WeaknessLowEntropy       Weakness = "low_entropy"
+	WeaknessContainsIdentity Weakness = "contains_identity"
+)
+
+// minIdentityLength 用户名、邮箱前缀短于该长度时不检查密码是否包含它们，避免误伤
+const minIdentityLength = 3
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+// commonPasswords 内置常见密码，已转为小写
+var commonPasswords = parseCommonPasswords(commonPasswordsFile)
+
+// StrengthRules 密码强度规则，为 0 或 false 的规则不检查
+type StrengthRules struct {
+	MinLength      int
+	MinEntropyBits float64
+	RejectCommon   bool
+}
+
+// Check 按长度、常见密码、是否包含用户身份信息、估算熵的顺序检查密码，返回第一个未通过的原因
+// identities 为用户名、邮箱等，密码包含其中任一个（忽略大小写，邮箱只比较 @ 之前的部分）时不通过
+func (r StrengthRules) Check(password string, identities ...string) (Weakness, bool) {
+	if r.MinLength > 0 && utf8.RuneCountInString(password) < r.MinLength {
+		return WeaknessTooShort, false
+	}
+	if r.RejectCommon && IsCommon(password) {
+		return WeaknessCommon, false
+	}
+
+	lower := strings.ToLower(password)
+	for _, identity := range identities {
+		identity, _, _ = strings.Cut(strings.ToLower(identity), "@")
+		if utf8.RuneCountInString(identity) >= minIdentityLength && strings.Contains(lower, identity) {
+			return WeaknessContainsIdentity, false
+		}
+	}
+
+	if r.MinEntropyBits > 0 && Entropy(password) < r.MinEntropyBits {
+		return WeaknessLowEntropy, false
+	}
+	return "", true
+}
+
+// IsCommon 判断密码是否在内置常见密码列表中，忽略大小写
+func IsCommon(password string) bool {
+	_, ok := commonPasswords[strings.ToLower(password)]
+	return ok
+}
+
+// Entropy 按字符类别估算密码的熵（比特）
+// 每个字符贡献 log2(字符池大小)，字符池由出现的字符类别决定；与前一个字符相同或相邻（如 aaa、123、cba）的字符只贡献 1 比特
+func Entropy(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < utf8.RuneSelf && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+
+	perChar := math.Log2(float64(pool))
+	var bits float64
+	prev := rune(-1)
+	for _, r := range password {
+		if prev >= 0 && (r == prev || r == prev+1 || r == prev-1) {
+			bits++
+		} else {
+			bits += perChar
+		}
+		prev = r
+	}
+	return bits
+}
+
+// parseCommonPasswords 解析常见密码列表，忽略空行和 # 开头的注释
+func parseCommonPasswords(file string) map[string]struct{} {
+	passwords := make(map[string]struct{})
+	for _, line := range strings.Split(file, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords[strings.ToLower(line)] = struct{}{}
+	}
+	return passwords
+}
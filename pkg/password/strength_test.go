@@ -0,0 +1,96 @@
+package password
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestStrengthRulesCheck(t *testing.T) {
+	rules := StrengthRules{MinLength: 8, MinEntropyBits: 36, RejectCommon: true}
+	tests := []struct {
+		name       string
+		password   string
+		identities []string
+		want       Weakness
+	}{
+		{"too short", "k9x2m", nil, WeaknessTooShort},
+		{"common", "Password123", nil, WeaknessCommon},
+		{"contains username", "xAlice!2024", []string{"alice", "alice@example.com"}, WeaknessContainsIdentity},
+		{"contains email local part", "bob.smith#77", []string{"bs", "bob.smith@example.com"}, WeaknessContainsIdentity},
+		{"sequential", "abcdefghij", nil, WeaknessLowEntropy},
+		{"repeated", "zzzzzzzzzz", nil, WeaknessLowEntropy},
+		{"strong", "k9x2m4q7", []string{"alice", "alice@example.com"}, ""},
+		{"short identity ignored", "k9x2m4q7", []string{"k9"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			weakness, ok := rules.Check(tt.password, tt.identities...)
+			if weakness != tt.want || ok != (tt.want == "") {
+				t.Fatalf("Check(%q) = %q, %v; want %q", tt.password, weakness, ok, tt.want)
+			}
+		})
+	}
+
+	// 未配置的规则不检查
+	if _, ok := (StrengthRules{}).Check("123"); !ok {
+		t.Fatal("zero rules must accept any password")
+	}
+}
+
+func TestEntropy(t *testing.T) {
+	if got := Entropy(""); got != 0 {
+		t.Fatalf("Entropy(\"\") = %v, want 0", got)
+	}
+	if a, b := Entropy("k9x2m4q7"), Entropy("K9x#m4Q7"); a >= b {
+		t.Fatalf("mixing character classes must raise entropy: %v >= %v", a, b)
+	}
+	if a, b := Entropy("12345678"), Entropy("15926348"); a >= b {
+		t.Fatalf("sequential digits must score lower: %v >= %v", a, b)
+	}
+}
+
+func TestBreachCheckerCount(t *testing.T) {
+	sum := sha1.Sum([]byte("hunter2"))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	var gotPath, gotPadding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotPadding = r.URL.Path, r.Header.Get("Add-Padding")
+		fmt.Fprintf(w, "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n%s:42\r\n00D4F6E8FA6EECAD2A3AA415EEC418D38EC:0\r\n", hash[5:])
+	}))
+	defer server.Close()
+
+	checker := NewBreachChecker(server.URL+"/range", server.Client())
+	count, err := checker.Count(context.Background(), "hunter2")
+	if err != nil {
+		t.Fatalf("Count failed: %v", err)
+	}
+	if count != 42 {
+		t.Fatalf("count = %d, want 42", count)
+	}
+	// 只发送哈希前 5 位
+	if gotPath != "/range/"+hash[:5] || gotPadding != "true" {
+		t.Fatalf("request path %q, Add-Padding %q", gotPath, gotPadding)
+	}
+
+	if count, err := checker.Count(context.Background(), "not-in-the-list"); err != nil || count != 0 {
+		t.Fatalf("Count(unlisted) = %d, %v; want 0", count, err)
+	}
+}
+
+func TestBreachCheckerServerError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	if _, err := NewBreachChecker(server.URL, server.Client()).Count(context.Background(), "hunter2"); err == nil {
+		t.Fatal("non-200 response must return an error")
+	}
+}
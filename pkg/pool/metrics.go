@@ -0,0 +1,116 @@
+package pool
+
+import (
+	"sync"
+
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 工作池指标，按 pool 标签区分，同名工作池的数值合并
+var (
+	queuedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "pool", "queued_tasks"),
+		"Number of tasks waiting in the worker pool queue.",
+		[]string{"pool"}, nil,
+	)
+	activeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "pool", "active_workers"),
+		"Number of workers currently running a task.",
+		[]string{"pool"}, nil,
+	)
+	workersDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "pool", "workers"),
+		"Configured number of workers.",
+		[]string{"pool"}, nil,
+	)
+	completedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "pool", "completed_tasks_total"),
+		"Total number of tasks finished, including panicked ones.",
+		[]string{"pool"}, nil,
+	)
+	rejectedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "pool", "rejected_tasks_total"),
+		"Total number of tasks rejected because the queue was full, the context ended or the pool was closed.",
+		[]string{"pool"}, nil,
+	)
+	panicsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(metrics.Namespace, "pool", "panics_total"),
+		"Total number of tasks that panicked.",
+		[]string{"pool"}, nil,
+	)
+)
+
+// live 正在运行的工作池，Shutdown 完成后移除，累计计数并入 retired，避免计数器回退
+var (
+	liveMu  sync.Mutex
+	live    = map[*Pool]struct{}{}
+	retired = map[string]Stats{}
+)
+
+func init() {
+	metrics.Registry.MustRegister(collector{})
+}
+
+func track(p *Pool) {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+	live[p] = struct{}{}
+}
+
+func untrack(p *Pool) {
+	liveMu.Lock()
+	defer liveMu.Unlock()
+	delete(live, p)
+
+	stats := p.Stats()
+	sum := retired[stats.Name]
+	sum.Completed += stats.Completed
+	sum.Rejected += stats.Rejected
+	sum.Panicked += stats.Panicked
+	retired[stats.Name] = sum
+}
+
+// collector 在抓取时读取各工作池的 Stats
+type collector struct{}
+
+// Describe 实现 prometheus.Collector 接口
+func (collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queuedDesc
+	ch <- activeDesc
+	ch <- workersDesc
+	ch <- completedDesc
+	ch <- rejectedDesc
+	ch <- panicsDesc
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (collector) Collect(ch chan<- prometheus.Metric) {
+	byName := make(map[string]Stats)
+	liveMu.Lock()
+	for name, stats := range retired {
+		byName[name] = stats
+	}
+	for p := range live {
+		stats := p.Stats()
+		sum := byName[stats.Name]
+		sum.Workers += stats.Workers
+		sum.Queued += stats.Queued
+		sum.Running += stats.Running
+		sum.Completed += stats.Completed
+		sum.Rejected += stats.Rejected
+		sum.Panicked += stats.Panicked
+		byName[stats.Name] = sum
+	}
+	liveMu.Unlock()
+
+	for name, stats := range byName {
+		ch <- prometheus.MustNewConstMetric(queuedDesc, prometheus.GaugeValue, float64(stats.Queued), name)
+		ch <- prometheus.MustNewConstMetric(activeDesc, prometheus.GaugeValue, float64(stats.Running), name)
+		ch <- prometheus.MustNewConstMetric(workersDesc, prometheus.GaugeValue, float64(stats.Workers), name)
+		ch <- prometheus.MustNewConstMetric(completedDesc, prometheus.CounterValue, float64(stats.Completed), name)
+		ch <- prometheus.MustNewConstMetric(rejectedDesc, prometheus.CounterValue, float64(stats.Rejected), name)
+		ch <- prometheus.MustNewConstMetric(panicsDesc, prometheus.CounterValue, float64(stats.Panicked), name)
+	}
+}
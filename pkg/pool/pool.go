@@ -0,0 +1,225 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	// ErrPoolClosed 工作池已关闭
+	ErrPoolClosed = errors.New("worker pool is closed")
+	// ErrPoolFull 工作池队列已满
+	ErrPoolFull = errors.New("worker pool queue is full")
+)
+
+// Task 提交到工作池的任务
+type Task func()
+
+// PanicHandler 任务 panic 时的回调
+type PanicHandler func(recovered interface{}, stack []byte)
+
+// Config 工作池配置
+type Config struct {
+	Name      string // 工作池名称，用于日志和指标
+	Workers   int    // 并发 worker 数量
+	QueueSize int    // 等待队列长度，队列满时 Submit 阻塞、TrySubmit 返回 ErrPoolFull
+}
+
+// Stats 工作池运行指标
+type Stats struct {
+	Name      string `json:"name"`
+	Workers   int    `json:"workers"`
+	Queued    int    `json:"queued"`
+	Running   int64  `json:"running"`
+	Submitted uint64 `json:"submitted"`
+	Completed uint64 `json:"completed"`
+	Panicked  uint64 `json:"panicked"`
+	Rejected  uint64 `json:"rejected"`
+}
+
+// Pool 有界 goroutine 工作池
+// 固定数量的 worker 从有界队列中取任务执行，队列满时对提交方形成背压
+type Pool struct {
+	name         string
+	workers      int
+	tasks        chan Task
+	panicHandler PanicHandler
+
+	// mu 只保护 closed 和 senders 的登记，Submit 阻塞等待队列空位时不持有锁，
+	// 关闭时通过 done 唤醒阻塞的提交方，等待它们全部返回后再关闭 tasks
+	mu        sync.RWMutex
+	closed    bool
+	done      chan struct{}
+	senders   sync.WaitGroup
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+
+	running   atomic.Int64
+	submitted atomic.Uint64
+	completed atomic.Uint64
+	panicked  atomic.Uint64
+	rejected  atomic.Uint64
+}
+
+// Option 工作池选项
+type Option func(*Pool)
+
+// WithPanicHandler 设置任务 panic 回调
+func WithPanicHandler(handler PanicHandler) Option {
+	return func(p *Pool) {
+		p.panicHandler = handler
+	}
+}
+
+// New 创建并启动工作池
+func New(cfg Config, opts ...Option) *Pool {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	if cfg.QueueSize < 0 {
+		cfg.QueueSize = 0
+	}
+
+	p := &Pool{
+		name:    cfg.Name,
+		workers: cfg.Workers,
+		tasks:   make(chan Task, cfg.QueueSize),
+		done:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.wg.Add(cfg.Workers)
+	for i := 0; i < cfg.Workers; i++ {
+		go p.worker()
+	}
+	track(p)
+	return p
+}
+
+// Submit 提交任务，队列满时阻塞直到有空位、ctx 结束或工作池关闭
+func (p *Pool) Submit(ctx context.Context, task Task) error {
+	if !p.enter() {
+		p.rejected.Add(1)
+		return ErrPoolClosed
+	}
+	defer p.senders.Done()
+
+	select {
+	case p.tasks <- task:
+		p.submitted.Add(1)
+		return nil
+	case <-ctx.Done():
+		p.rejected.Add(1)
+		return ctx.Err()
+	case <-p.done:
+		p.rejected.Add(1)
+		return ErrPoolClosed
+	}
+}
+
+// TrySubmit 非阻塞提交任务，队列满时立即返回 ErrPoolFull
+func (p *Pool) TrySubmit(task Task) error {
+	if !p.enter() {
+		p.rejected.Add(1)
+		return ErrPoolClosed
+	}
+	defer p.senders.Done()
+
+	select {
+	case p.tasks <- task:
+		p.submitted.Add(1)
+		return nil
+	default:
+		p.rejected.Add(1)
+		return ErrPoolFull
+	}
+}
+
+// enter 登记一个提交方，工作池已关闭时返回 false；返回 true 时调用方需调用 p.senders.Done()
+func (p *Pool) enter() bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return false
+	}
+	p.senders.Add(1)
+	return true
+}
+
+// Shutdown 停止接收新任务，并等待队列中已有任务执行完毕
+// 阻塞中的 Submit 立即返回 ErrPoolClosed，ctx 结束时不再等待剩余任务
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.done)
+	}
+	p.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		// 已登记的提交方都已返回后不会再有发送，此时才能关闭 tasks
+		p.senders.Wait()
+		p.closeOnce.Do(func() { close(p.tasks) })
+		p.wg.Wait()
+		untrack(p)
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("worker pool %s drain timeout: %w", p.name, ctx.Err())
+	}
+}
+
+// Capacity 工作池可同时容纳的任务数（worker 数 + 队列长度）
+func (p *Pool) Capacity() int {
+	return p.workers + cap(p.tasks)
+}
+
+// Stats 获取工作池运行指标
+func (p *Pool) Stats() Stats {
+	return Stats{
+		Name:      p.name,
+		Workers:   p.workers,
+		Queued:    len(p.tasks),
+		Running:   p.running.Load(),
+		Submitted: p.submitted.Load(),
+		Completed: p.completed.Load(),
+		Panicked:  p.panicked.Load(),
+		Rejected:  p.rejected.Load(),
+	}
+}
+
+// worker 循环执行任务直到队列关闭
+func (p *Pool) worker() {
+	defer p.wg.Done()
+	for task := range p.tasks {
+		p.run(task)
+	}
+}
+
+// run 执行单个任务，隔离 panic 以免影响其他任务
+func (p *Pool) run(task Task) {
+	p.running.Add(1)
+	defer func() {
+		p.running.Add(-1)
+		p.completed.Add(1)
+		if r := recover(); r != nil {
+			p.panicked.Add(1)
+			if p.panicHandler != nil {
+				p.panicHandler(r, debug.Stack())
+			}
+		}
+	}()
+	task()
+}
@@ -0,0 +1,189 @@
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/metrics"
+)
+
+func TestPoolRunsAllTasks(t *testing.T) {
+	p := New(Config{Name: "test", Workers: 4, QueueSize: 8})
+
+	var count atomic.Int64
+	for i := 0; i < 100; i++ {
+		if err := p.Submit(context.Background(), func() { count.Add(1) }); err != nil {
+			t.Fatalf("Submit failed: %v", err)
+		}
+	}
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if got := count.Load(); got != 100 {
+		t.Fatalf("expected 100 tasks executed, got %d", got)
+	}
+}
+
+func TestPoolBackpressure(t *testing.T) {
+	p := New(Config{Name: "test", Workers: 1, QueueSize: 1})
+	defer p.Shutdown(context.Background())
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	_ = p.Submit(context.Background(), func() {
+		close(started)
+		<-block
+	})
+	<-started
+
+	// 占满队列
+	if err := p.TrySubmit(func() {}); err != nil {
+		t.Fatalf("expected queue slot available, got %v", err)
+	}
+	if err := p.TrySubmit(func() {}); !errors.Is(err, ErrPoolFull) {
+		t.Fatalf("expected ErrPoolFull, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Submit(ctx, func() {}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected Submit to block until deadline, got %v", err)
+	}
+
+	close(block)
+}
+
+func TestPoolPanicIsolation(t *testing.T) {
+	var recovered atomic.Value
+	p := New(Config{Name: "test", Workers: 1}, WithPanicHandler(func(r interface{}, _ []byte) {
+		recovered.Store(r)
+	}))
+
+	var after atomic.Bool
+	_ = p.Submit(context.Background(), func() { panic("boom") })
+	_ = p.Submit(context.Background(), func() { after.Store(true) })
+
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if recovered.Load() != "boom" {
+		t.Fatalf("expected panic to be reported, got %v", recovered.Load())
+	}
+	if !after.Load() {
+		t.Fatal("expected task after panic to run")
+	}
+	if stats := p.Stats(); stats.Panicked != 1 || stats.Completed != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestPoolRejectsAfterShutdown(t *testing.T) {
+	p := New(Config{Name: "test", Workers: 1})
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	if err := p.Submit(context.Background(), func() {}); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestPoolShutdownUnblocksSubmit(t *testing.T) {
+	p := New(Config{Name: "test", Workers: 1})
+
+	block := make(chan struct{})
+	defer close(block)
+	started := make(chan struct{})
+	_ = p.Submit(context.Background(), func() {
+		close(started)
+		<-block
+	})
+	<-started
+
+	// 队列长度为 0，worker 忙时 Submit 一直阻塞
+	submitted := make(chan error, 1)
+	go func() {
+		submitted <- p.Submit(context.Background(), func() {})
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// Shutdown 不等待阻塞中的 Submit，按 ctx 超时返回
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected drain timeout, got %v", err)
+	}
+	select {
+	case err := <-submitted:
+		if !errors.Is(err, ErrPoolClosed) {
+			t.Fatalf("expected ErrPoolClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Submit still blocked after Shutdown")
+	}
+}
+
+func TestPoolMetrics(t *testing.T) {
+	// 指标注册在全局 Registry，使用唯一名称避免受其他测试影响
+	name := fmt.Sprintf("metrics_test_%d", time.Now().UnixNano())
+	p := New(Config{Name: name, Workers: 1}, WithPanicHandler(func(interface{}, []byte) {}))
+	_ = p.Submit(context.Background(), func() { panic("boom") })
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	_ = p.Submit(context.Background(), func() {
+		close(started)
+		<-block
+	})
+	<-started
+	if err := p.TrySubmit(func() {}); !errors.Is(err, ErrPoolFull) {
+		t.Fatalf("expected ErrPoolFull, got %v", err)
+	}
+
+	if got := poolMetric(t, "skeleton_pool_active_workers", name); got != 1 {
+		t.Fatalf("active_workers = %v, want 1", got)
+	}
+	if got := poolMetric(t, "skeleton_pool_rejected_tasks_total", name); got != 1 {
+		t.Fatalf("rejected_tasks_total = %v, want 1", got)
+	}
+
+	close(block)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	// 关闭后累计计数仍然保留，计数器不会回退
+	if got := poolMetric(t, "skeleton_pool_panics_total", name); got != 1 {
+		t.Fatalf("panics_total = %v, want 1", got)
+	}
+	if got := poolMetric(t, "skeleton_pool_completed_tasks_total", name); got != 2 {
+		t.Fatalf("completed_tasks_total = %v, want 2", got)
+	}
+}
+
+// poolMetric 返回全局 Registry 中指定工作池的指标值，不存在时返回 -1
+func poolMetric(t *testing.T, metric, pool string) float64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather failed: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != metric {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			if m.GetLabel()[0].GetValue() != pool {
+				continue
+			}
+			if m.GetCounter() != nil {
+				return m.GetCounter().GetValue()
+			}
+			return m.GetGauge().GetValue()
+		}
+	}
+	return -1
+}
@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ConcurrencyLimiter 进程内按 key 限制同时处理的请求数，槽位占满时请求排队等待
+// 与 Limiter 不同，并发数按实例计算，多实例部署时总并发为 limit × 实例数
+type ConcurrencyLimiter struct {
+	mu    sync.Mutex
+	slots map[string]*semaphore
+}
+
+// semaphore 单个 key 的信号量，refs 为持有和等待槽位的请求数，归零时回收
+type semaphore struct {
+	tokens chan struct{}
+	refs   int
+}
+
+// NewConcurrencyLimiter 创建并发限制器
+func NewConcurrencyLimiter() *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{slots: make(map[string]*semaphore)}
+}
+
+// Acquire 获取 key 的一个槽位，limit 个槽位都被占用时最多等待 wait
+// 超时或 ctx 取消时返回 false；成功时调用方处理完成后必须调用 release 归还槽位
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context, key string, limit int, wait time.Duration) (release func(), ok bool) {
+	if limit <= 0 {
+		return func() {}, true
+	}
+
+	s := l.ref(key, limit)
+	release = func() {
+		<-s.tokens
+		l.unref(key, s)
+	}
+
+	select {
+	case s.tokens <- struct{}{}:
+		return release, true
+	default:
+	}
+	if wait <= 0 {
+		l.unref(key, s)
+		return nil, false
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case s.tokens <- struct{}{}:
+		return release, true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+	l.unref(key, s)
+	return nil, false
+}
+
+// ref 取得 key 的信号量并增加引用
+func (l *ConcurrencyLimiter) ref(key string, limit int) *semaphore {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s, ok := l.slots[key]
+	if !ok {
+		s = &semaphore{tokens: make(chan struct{}, limit)}
+		l.slots[key] = s
+	}
+	s.refs++
+	return s
+}
+
+// unref 减少引用，没有请求持有或等待时删除信号量，避免按用户、IP 区分时 key 无限增长
+func (l *ConcurrencyLimiter) unref(key string, s *semaphore) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s.refs--
+	if s.refs == 0 {
+		delete(l.slots, key)
+	}
+}
@@ -0,0 +1,76 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestConcurrencyLimiterRejectsWhenSaturated(t *testing.T) {
+	l := NewConcurrencyLimiter()
+	ctx := context.Background()
+
+	release, ok := l.Acquire(ctx, "export:user:1", 1, 0)
+	if !ok {
+		t.Fatal("first acquire rejected")
+	}
+	if _, ok := l.Acquire(ctx, "export:user:1", 1, 10*time.Millisecond); ok {
+		t.Fatal("acquire beyond limit allowed")
+	}
+	if _, ok := l.Acquire(ctx, "export:user:2", 1, 0); !ok {
+		t.Fatal("other key rejected")
+	}
+
+	release()
+	release, ok = l.Acquire(ctx, "export:user:1", 1, 0)
+	if !ok {
+		t.Fatal("acquire after release rejected")
+	}
+	release()
+}
+
+func TestConcurrencyLimiterQueuesUntilRelease(t *testing.T) {
+	l := NewConcurrencyLimiter()
+	ctx := context.Background()
+
+	release, _ := l.Acquire(ctx, "export", 1, 0)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		release()
+	}()
+
+	release, ok := l.Acquire(ctx, "export", 1, time.Second)
+	if !ok {
+		t.Fatal("queued acquire rejected")
+	}
+	release()
+}
+
+func TestConcurrencyLimiterStopsWaitingOnCancel(t *testing.T) {
+	l := NewConcurrencyLimiter()
+	release, _ := l.Acquire(context.Background(), "export", 1, 0)
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, ok := l.Acquire(ctx, "export", 1, time.Second); ok {
+		t.Fatal("acquire allowed after cancel")
+	}
+}
+
+func TestConcurrencyLimiterReleasesIdleKeys(t *testing.T) {
+	l := NewConcurrencyLimiter()
+	ctx := context.Background()
+
+	release, _ := l.Acquire(ctx, "a", 2, 0)
+	l.Acquire(ctx, "b", 1, 0)
+	l.Acquire(ctx, "b", 1, 0)
+	release()
+
+	if _, exists := l.slots["a"]; exists {
+		t.Error("idle key a not released")
+	}
+	if s := l.slots["b"]; s == nil || s.refs != 1 {
+		t.Errorf("key b refs = %v, want 1 holder", s)
+	}
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fixedWindowScript 原子地递增计数并在窗口首次请求时设置过期时间
+// 返回当前计数和窗口剩余毫秒数
+var fixedWindowScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+local ttl = redis.call("PTTL", KEYS[1])
+if ttl < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+	ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`)
+
+// Result 限流结果
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetIn   time.Duration // 当前窗口剩余时间
+}
+
+// Limiter 基于 Redis 固定窗口计数的分布式限流器，多实例部署时共享配额
+type Limiter struct {
+	client *redis.Client
+	prefix string
+}
+
+// New 创建限流器
+func New(client *redis.Client) *Limiter {
+	return &Limiter{client: client, prefix: "ratelimit:"}
+}
+
+// Allow 判断 key 在 window 窗口内的请求数是否超过 limit
+func (l *Limiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (*Result, error) {
+	if limit <= 0 || window <= 0 {
+		return nil, fmt.Errorf("invalid rate limit: %d per %s", limit, window)
+	}
+
+	values, err := fixedWindowScript.Run(ctx, l.client, []string{l.prefix + key}, window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run rate limit script: %w", err)
+	}
+
+	count, ttl := int(values[0]), time.Duration(values[1])*time.Millisecond
+	remaining := limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &Result{
+		Allowed:   count <= limit,
+		Limit:     limit,
+		Remaining: remaining,
+		ResetIn:   ttl,
+	}, nil
+}
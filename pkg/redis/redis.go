@@ -2,7 +2,9 @@ package redis
 
 import (
 	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/timing"
 	"context"
+	"sync"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -15,6 +17,11 @@ func NewRedis(cfg *config.Redis) (*redis.Client, error) {
 		DB:       cfg.DB,
 	})
 
+	// 记录请求内的 Redis 命令耗时，用于慢请求诊断
+	rdb.AddHook(timing.RedisHook{})
+	// 请求链路中的 Redis 命令 span
+	rdb.AddHook(TracingHook{})
+
 	// 使用 Ping 命令检查连接是否正常
 	_, err := rdb.Ping(context.Background()).Result()
 	if err != nil {
@@ -23,3 +30,24 @@ func NewRedis(cfg *config.Redis) (*redis.Client, error) {
 
 	return rdb, nil
 }
+
+// OpenConnections 并发执行 n 次 Ping，使连接池预先建立连接，返回第一个错误
+func OpenConnections(ctx context.Context, rdb *redis.Client, n int) error {
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = rdb.Ping(ctx).Err()
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package redis
+
+import (
+	"context"
+	"net"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// tracerName Redis 链路追踪的 instrumentation 名称
+const tracerName = "github.com/hedeqiang/skeleton/pkg/redis"
+
+// TracingHook 为 Redis 命令创建 client span 的 go-redis hook
+// 只有 context 中存在正在记录的 span 时才创建，不记录命令参数
+type TracingHook struct{}
+
+// DialHook 实现 redis.Hook 接口
+func (TracingHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook 实现 redis.Hook 接口
+func (TracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !oteltrace.SpanFromContext(ctx).IsRecording() {
+			return next(ctx, cmd)
+		}
+		ctx, span := startSpan(ctx, cmd.Name())
+		err := next(ctx, cmd)
+		endSpan(span, err)
+		return err
+	}
+}
+
+// ProcessPipelineHook 实现 redis.Hook 接口，整个 pipeline 记为一个 span
+func (TracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !oteltrace.SpanFromContext(ctx).IsRecording() {
+			return next(ctx, cmds)
+		}
+		ctx, span := startSpan(ctx, "pipeline")
+		span.SetAttributes(attribute.Int("db.redis.pipeline_length", len(cmds)))
+		err := next(ctx, cmds)
+		endSpan(span, err)
+		return err
+	}
+}
+
+func startSpan(ctx context.Context, operation string) (context.Context, oteltrace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, operation,
+		oteltrace.WithSpanKind(oteltrace.SpanKindClient),
+		oteltrace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperationName(operation)),
+	)
+}
+
+// endSpan 记录错误并结束 span，redis.Nil 表示键不存在，不标记为错误
+func endSpan(span oteltrace.Span, err error) {
+	if err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
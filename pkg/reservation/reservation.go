@@ -0,0 +1,77 @@
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// acquireScript key 不存在时写入 token 并设置过期时间
+// 返回 1 表示新占用，2 表示已由同一 token 占用，0 表示被其他 token 占用
+var acquireScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if not owner then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+if owner == ARGV[1] then
+	return 2
+end
+return 0
+`)
+
+// releaseScript 仅当 key 仍由 token 占用时删除，避免误删过期后被他人重新占用的 key
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// 占用结果
+const (
+	Taken    = 0 // 已被其他 token 占用
+	Acquired = 1 // 新占用
+	Owned    = 2 // 已由同一 token 占用
+)
+
+// Store 基于 Redis 的值占用存储，多实例部署时共享
+// 同一个值在过期前只能被一个 token 占用，用于在多步骤流程中保留用户名等唯一值
+type Store struct {
+	client *redis.Client
+	prefix string
+}
+
+// New 创建占用存储，prefix 为 key 前缀
+func New(client *redis.Client, prefix string) *Store {
+	return &Store{client: client, prefix: prefix}
+}
+
+// Acquire 以 token 占用 key，ttl 后自动释放
+// 已由同一 token 占用时不刷新过期时间，返回 Owned
+func (s *Store) Acquire(ctx context.Context, key, token string, ttl time.Duration) (int, error) {
+	if ttl <= 0 {
+		return Taken, fmt.Errorf("invalid reservation ttl: %s", ttl)
+	}
+
+	result, err := acquireScript.Run(ctx, s.client, []string{s.key(key)}, token, ttl.Milliseconds()).Int()
+	if err != nil {
+		return Taken, fmt.Errorf("failed to acquire reservation: %w", err)
+	}
+	return result, nil
+}
+
+// Release 释放 token 对 key 的占用，key 已过期或被他人占用时不做处理
+func (s *Store) Release(ctx context.Context, key, token string) error {
+	if err := releaseScript.Run(ctx, s.client, []string{s.key(key)}, token).Err(); err != nil {
+		return fmt.Errorf("failed to release reservation: %w", err)
+	}
+	return nil
+}
+
+// key 返回带前缀的 Redis key
+func (s *Store) key(key string) string {
+	return s.prefix + ":" + key
+}
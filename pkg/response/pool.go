@@ -0,0 +1,67 @@
+package response
+
+import (
+	"bytes"
+	"encoding/json"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxPooledBufferSize 超过该大小的缓冲区不放回池中，避免偶发大响应长期占用内存
+const maxPooledBufferSize = 64 << 10
+
+const jsonContentType = "application/json; charset=utf-8"
+
+var (
+	bufferPool = sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	}
+
+	responsePool = sync.Pool{
+		New: func() interface{} {
+			return new(Response)
+		},
+	}
+)
+
+// acquireResponse 从池中获取 Response
+func acquireResponse() *Response {
+	return responsePool.Get().(*Response)
+}
+
+// releaseResponse 清空并归还 Response，避免持有业务数据的引用
+func releaseResponse(resp *Response) {
+	*resp = Response{}
+	responsePool.Put(resp)
+}
+
+// writeJSON 使用池化缓冲区序列化并写出响应
+func writeJSON(c *gin.Context, httpStatus int, obj interface{}) {
+	writeBody(c, httpStatus, jsonContentType, obj)
+}
+
+// writeBody 使用池化缓冲区序列化并以指定的 Content-Type 写出响应
+func writeBody(c *gin.Context, httpStatus int, contentType string, obj interface{}) {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+	defer func() {
+		if buf.Cap() <= maxPooledBufferSize {
+			bufferPool.Put(buf)
+		}
+	}()
+
+	if err := json.NewEncoder(buf).Encode(obj); err != nil {
+		// 序列化失败时交给 gin 处理，保持与 c.JSON 一致的错误行为
+		c.JSON(httpStatus, obj)
+		return
+	}
+
+	// Encoder 会追加换行符，去掉以保持与 c.JSON 相同的输出
+	body := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
+
+	// c.Data 同步写出，返回后缓冲区即可复用
+	c.Data(httpStatus, contentType, body)
+}
@@ -0,0 +1,100 @@
+package response
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProblemContentType RFC 7807 错误响应的媒体类型
+const ProblemContentType = "application/problem+json"
+
+// defaultProblemTypeBase 未配置 type_base_uri 时 type 使用的 URN 前缀
+const defaultProblemTypeBase = "urn:problem-type:"
+
+// Problem RFC 7807 错误响应
+// instance 为请求 ID；message_id 为扩展字段，与默认响应格式中的同名字段含义一致
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	MessageID string `json:"message_id,omitempty"`
+}
+
+// problemDetails 是否按 Accept 协商 problem+json，problemTypeBase 为 type 的 URI 前缀
+var (
+	problemDetails  bool
+	problemTypeBase = defaultProblemTypeBase
+)
+
+// SetProblemDetails 开启后请求的 Accept 包含 application/problem+json 时，错误响应按 RFC 7807 输出，
+// 其余请求仍使用默认的 code/msg 格式；typeBase 为 type 的 URI 前缀，为空时使用 urn:problem-type:
+func SetProblemDetails(enabled bool, typeBase string) {
+	problemDetails = enabled
+	problemTypeBase = typeBase
+	if problemTypeBase == "" {
+		problemTypeBase = defaultProblemTypeBase
+	}
+}
+
+// statusProblemTypes 没有 AppError 时按状态码推断错误类型，与 errors 包中类型到状态码的映射一致
+var statusProblemTypes = map[int]errors.ErrorType{
+	http.StatusBadRequest:          errors.ErrorTypeValidation,
+	http.StatusNotFound:            errors.ErrorTypeNotFound,
+	http.StatusUnauthorized:        errors.ErrorTypeUnauthorized,
+	http.StatusForbidden:           errors.ErrorTypeForbidden,
+	http.StatusConflict:            errors.ErrorTypeConflict,
+	http.StatusTooManyRequests:     errors.ErrorTypeRateLimited,
+	http.StatusInternalServerError: errors.ErrorTypeInternal,
+	http.StatusServiceUnavailable:  errors.ErrorTypeUnavailable,
+}
+
+// problemType 返回错误类型对应的 type URI，没有对应类型时为 about:blank，表示含义与状态码相同
+func problemType(errorType errors.ErrorType) string {
+	if errorType == "" {
+		return "about:blank"
+	}
+	return problemTypeBase + string(errorType)
+}
+
+// wantsProblem 开启 problem+json 且请求的 Accept 明确接受 application/problem+json 时返回 true
+// 开启后错误响应随 Accept 变化，因此同时设置 Vary: Accept
+func wantsProblem(c *gin.Context) bool {
+	if !problemDetails || c.Request == nil {
+		return false
+	}
+	c.Writer.Header().Add("Vary", "Accept")
+
+	for _, accept := range strings.Split(c.GetHeader("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil || mediaType != ProblemContentType {
+			continue
+		}
+		if q, ok := params["q"]; ok {
+			if weight, err := strconv.ParseFloat(q, 64); err != nil || weight <= 0 {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// writeProblem 以 application/problem+json 写出错误响应，instance 使用请求 ID
+func writeProblem(c *gin.Context, status int, errorType errors.ErrorType, detail, messageID string) {
+	writeBody(c, status, ProblemContentType, &Problem{
+		Type:      problemType(errorType),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  requestIDOf(c),
+		MessageID: messageID,
+	})
+}
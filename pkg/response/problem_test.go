@@ -0,0 +1,74 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestProblemDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	SetProblemDetails(true, "https://api.example.com/problems/")
+	defer SetProblemDetails(false, "")
+
+	send := func(accept string, write func(c *gin.Context)) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+		if accept != "" {
+			c.Request.Header.Set("Accept", accept)
+		}
+		c.Set("RequestID", "req-1")
+		write(c)
+		return w
+	}
+	appErr := errors.New(errors.ErrorTypeNotFound, "用户不存在").WithMessageID("user.not_found")
+
+	w := send("application/json, application/problem+json;q=0.9", func(c *gin.Context) { AppError(c, appErr) })
+	if ct := w.Header().Get("Content-Type"); ct != ProblemContentType {
+		t.Fatalf("Content-Type = %q, want %q", ct, ProblemContentType)
+	}
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("invalid problem body %q: %v", w.Body.String(), err)
+	}
+	want := Problem{
+		Type:      "https://api.example.com/problems/not_found",
+		Title:     "Not Found",
+		Status:    http.StatusNotFound,
+		Detail:    "用户不存在",
+		Instance:  "req-1",
+		MessageID: "user.not_found",
+	}
+	if w.Code != http.StatusNotFound || problem != want {
+		t.Fatalf("problem = %d %+v, want %+v", w.Code, problem, want)
+	}
+	if vary := w.Header().Get("Vary"); vary != "Accept" {
+		t.Errorf("Vary = %q, want Accept", vary)
+	}
+
+	// 没有对应错误类型的状态码使用 about:blank
+	w = send(ProblemContentType, func(c *gin.Context) { Error(c, http.StatusBadGateway, "上游服务异常") })
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil || problem.Type != "about:blank" || problem.Status != http.StatusBadGateway {
+		t.Fatalf("unexpected problem: %s", w.Body.String())
+	}
+
+	// 未接受或 q=0 时使用默认格式
+	for _, accept := range []string{"", "application/json", "*/*", "application/problem+json;q=0"} {
+		w = send(accept, func(c *gin.Context) { AppError(c, appErr) })
+		if resp := decode(t, w); resp.Code != ErrorCode || resp.RequestID != "req-1" {
+			t.Errorf("Accept %q: expected envelope, got %s", accept, w.Body.String())
+		}
+	}
+
+	// 成功响应不受影响
+	w = send(ProblemContentType, func(c *gin.Context) { Success(c, "ok") })
+	if resp := decode(t, w); resp.Code != SuccessCode {
+		t.Errorf("success response changed: %s", w.Body.String())
+	}
+}
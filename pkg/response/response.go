@@ -3,6 +3,9 @@ package response
 import (
 	"net/http"
 
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/i18n"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -10,6 +13,7 @@ import (
 type Response struct {
 	Code      int         `json:"code"`
 	Msg       string      `json:"msg"`
+	MessageID string      `json:"message_id,omitempty"`
 	Data      interface{} `json:"data,omitempty"`
 	RequestID string      `json:"request_id"`
 }
@@ -31,24 +35,20 @@ const (
 
 // Result 是一个通用的辅助函数，用于构建和发送响应
 func Result(code int, msg string, data interface{}, c *gin.Context) {
-	requestID, _ := c.Get("RequestID")
-	c.JSON(http.StatusOK, Response{
-		Code:      code,
-		Msg:       msg,
-		Data:      data,
-		RequestID: requestID.(string),
-	})
+	ResultWithStatus(http.StatusOK, code, msg, data, c)
 }
 
 // ResultWithStatus 是一个通用的辅助函数，用于构建和发送带有自定义HTTP状态码的响应
 func ResultWithStatus(httpStatus, code int, msg string, data interface{}, c *gin.Context) {
-	requestID, _ := c.Get("RequestID")
-	c.JSON(httpStatus, Response{
-		Code:      code,
-		Msg:       msg,
-		Data:      data,
-		RequestID: requestID.(string),
-	})
+	resp := acquireResponse()
+	defer releaseResponse(resp)
+
+	resp.Code = code
+	resp.Msg = msg
+	resp.Data = data
+	resp.RequestID = requestIDOf(c)
+
+	writeJSON(c, httpStatus, resp)
 }
 
 // Success 发送一个成功的响应
@@ -62,7 +62,12 @@ func SuccessWithMsg(c *gin.Context, httpStatus int, msg string, data interface{}
 }
 
 // Error 发送一个错误响应
+// 开启 problem+json 且请求的 Accept 接受时按 RFC 7807 输出，type 由状态码推断
 func Error(c *gin.Context, httpStatus int, msg string) {
+	if wantsProblem(c) {
+		writeProblem(c, httpStatus, statusProblemTypes[httpStatus], msg, "")
+		return
+	}
 	ResultWithStatus(httpStatus, ErrorCode, msg, nil, c)
 }
 
@@ -75,3 +80,45 @@ func Fail(c *gin.Context, msg string) {
 func FailWithCode(c *gin.Context, code int, msg string) {
 	Result(code, msg, nil, c)
 }
+
+// AppError 发送 AppError 对应的错误响应，状态码由错误类型决定
+// 经过 i18n 中间件时按请求语言翻译 MessageID，未配置或缺少翻译时使用原始消息；
+// 响应中包含 message_id，便于客户端自行本地化；开启 problem+json 且请求的 Accept 接受时按 RFC 7807 输出
+func AppError(c *gin.Context, err *errors.AppError) {
+	msg := err.Message
+	if c.Request != nil {
+		if localized, ok := i18n.Localize(c.Request.Context(), err.MessageID, err.Data); ok {
+			msg = localized
+		}
+	}
+	if wantsProblem(c) {
+		writeProblem(c, err.StatusCode(), err.Type, msg, err.MessageID)
+		return
+	}
+
+	resp := acquireResponse()
+	defer releaseResponse(resp)
+
+	resp.Code = ErrorCode
+	resp.Msg = msg
+	resp.MessageID = err.MessageID
+	resp.RequestID = requestIDOf(c)
+
+	writeJSON(c, err.StatusCode(), resp)
+}
+
+// NoRoute 未匹配任何路由时返回 404，通过 engine.NoRoute 注册
+func NoRoute(c *gin.Context) {
+	Error(c, http.StatusNotFound, "接口不存在")
+}
+
+// NoMethod 路由存在但请求方法不被允许时返回 405，通过 engine.NoMethod 注册
+// 需要开启 engine.HandleMethodNotAllowed，Allow 响应头由 gin 设置
+func NoMethod(c *gin.Context) {
+	Error(c, http.StatusMethodNotAllowed, "请求方法不允许")
+}
+
+// requestIDOf 获取请求 ID，未经过 RequestID 中间件时返回空字符串
+func requestIDOf(c *gin.Context) string {
+	return c.GetString("RequestID")
+}
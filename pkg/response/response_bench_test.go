@@ -0,0 +1,85 @@
+package response
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type benchUser struct {
+	ID        uint      `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Status    int       `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newBenchContext() *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Set("RequestID", "5f0c7c2e-5b1a-4a3e-9d7e-1f2a3b4c5d6e")
+	return c
+}
+
+func benchPage() PageResponse {
+	users := make([]benchUser, 20)
+	for i := range users {
+		users[i] = benchUser{
+			ID:        uint(i + 1),
+			Username:  "benchmark_user",
+			Email:     "benchmark@example.com",
+			Status:    1,
+			CreatedAt: time.Unix(1700000000, 0),
+			UpdatedAt: time.Unix(1700000000, 0),
+		}
+	}
+	return PageResponse{List: users, Total: 20, Page: 1, PageSize: 20}
+}
+
+// BenchmarkSuccess 池化序列化路径
+func BenchmarkSuccess(b *testing.B) {
+	gin.SetMode(gin.ReleaseMode)
+	data := benchPage()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Success(newBenchContext(), data)
+	}
+}
+
+// BenchmarkGinJSON 未池化的 c.JSON 基线，用于对比
+func BenchmarkGinJSON(b *testing.B) {
+	gin.SetMode(gin.ReleaseMode)
+	data := benchPage()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c := newBenchContext()
+		requestID, _ := c.Get("RequestID")
+		c.JSON(http.StatusOK, Response{
+			Code:      SuccessCode,
+			Msg:       "success",
+			Data:      data,
+			RequestID: requestID.(string),
+		})
+	}
+}
+
+// BenchmarkSuccessParallel 高并发下的池化序列化路径
+func BenchmarkSuccessParallel(b *testing.B) {
+	gin.SetMode(gin.ReleaseMode)
+	data := benchPage()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			Success(newBenchContext(), data)
+		}
+	})
+}
@@ -0,0 +1,91 @@
+package response
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hedeqiang/skeleton/pkg/errors"
+	"github.com/hedeqiang/skeleton/pkg/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) Response {
+	t.Helper()
+	var resp Response
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response body %q: %v", w.Body.String(), err)
+	}
+	return resp
+}
+
+func TestResultWithoutRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	Success(c, "ok")
+
+	resp := decode(t, w)
+	if resp.Code != SuccessCode || resp.RequestID != "" {
+		t.Fatalf("unexpected response: %+v", resp)
+	}
+}
+
+func TestNoRouteAndNoMethod(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.HandleMethodNotAllowed = true
+	r.NoRoute(NoRoute)
+	r.NoMethod(NoMethod)
+	r.GET("/users", func(c *gin.Context) { Success(c, nil) })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusNotFound || decode(t, w).Code != ErrorCode {
+		t.Fatalf("expected JSON 404, got %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/users", nil))
+	if w.Code != http.StatusMethodNotAllowed || decode(t, w).Code != ErrorCode {
+		t.Fatalf("expected JSON 405, got %d %s", w.Code, w.Body.String())
+	}
+	if allow := w.Header().Get("Allow"); allow != http.MethodGet {
+		t.Fatalf("expected Allow header GET, got %q", allow)
+	}
+}
+
+func TestAppErrorLocalized(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	bundle, err := i18n.New(fstest.MapFS{
+		"zh.json": {Data: []byte(`{"user.not_found": "用户不存在"}`)},
+		"en.json": {Data: []byte(`{"user.not_found": "User not found"}`)},
+	}, "zh")
+	if err != nil {
+		t.Fatalf("i18n.New failed: %v", err)
+	}
+
+	appErr := errors.New(errors.ErrorTypeNotFound, "用户不存在").WithMessageID("user.not_found")
+
+	// 未经过 i18n 中间件时使用原始消息
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	AppError(c, appErr)
+	if resp := decode(t, w); w.Code != http.StatusNotFound || resp.Msg != "用户不存在" || resp.MessageID != "user.not_found" {
+		t.Fatalf("unexpected response: %d %+v", w.Code, resp)
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	c.Request = c.Request.WithContext(i18n.NewContext(c.Request.Context(), bundle, bundle.Match("en")))
+	AppError(c, appErr)
+	if resp := decode(t, w); resp.Msg != "User not found" || resp.MessageID != "user.not_found" {
+		t.Fatalf("expected localized message, got %+v", resp)
+	}
+}
@@ -0,0 +1,335 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/timing"
+)
+
+// defaultOpenSearchTimeout 单次请求的默认超时
+const defaultOpenSearchTimeout = 10 * time.Second
+
+// OpenSearch 基于 REST API 的 OpenSearch 驱动，同样适用于 Elasticsearch 7.x
+type OpenSearch struct {
+	addresses []string
+	username  string
+	password  string
+	client    *http.Client
+}
+
+// NewOpenSearch 创建 OpenSearch 驱动，请求失败或节点返回 5xx 时依次尝试下一个节点
+func NewOpenSearch(cfg *config.Search) (*OpenSearch, error) {
+	if len(cfg.Addresses) == 0 {
+		return nil, errors.New("search addresses are required")
+	}
+
+	addresses := make([]string, len(cfg.Addresses))
+	for i, addr := range cfg.Addresses {
+		if _, err := url.Parse(addr); err != nil {
+			return nil, fmt.Errorf("invalid search address %q: %w", addr, err)
+		}
+		addresses[i] = strings.TrimRight(addr, "/")
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultOpenSearchTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.InsecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+
+	return &OpenSearch{
+		addresses: addresses,
+		username:  cfg.Username,
+		password:  cfg.Password,
+		client:    &http.Client{Timeout: timeout, Transport: transport},
+	}, nil
+}
+
+// Ping 实现 Engine 接口
+func (o *OpenSearch) Ping(ctx context.Context) error {
+	return o.do(ctx, http.MethodGet, "/_cluster/health", nil, nil)
+}
+
+// IndexExists 实现 Engine 接口
+func (o *OpenSearch) IndexExists(ctx context.Context, index string) (bool, error) {
+	err := o.do(ctx, http.MethodHead, "/"+url.PathEscape(index), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CreateIndex 实现 Engine 接口
+func (o *OpenSearch) CreateIndex(ctx context.Context, index string, def *IndexDefinition) error {
+	return o.do(ctx, http.MethodPut, "/"+url.PathEscape(index), def, nil)
+}
+
+// DeleteIndex 实现 Engine 接口
+func (o *OpenSearch) DeleteIndex(ctx context.Context, index string) error {
+	err := o.do(ctx, http.MethodDelete, "/"+url.PathEscape(index), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// AliasIndices 实现 Engine 接口
+func (o *OpenSearch) AliasIndices(ctx context.Context, alias string) ([]string, error) {
+	var resp map[string]json.RawMessage
+	err := o.do(ctx, http.MethodGet, "/_alias/"+url.PathEscape(alias), nil, &resp)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	indices := make([]string, 0, len(resp))
+	for index := range resp {
+		indices = append(indices, index)
+	}
+	return indices, nil
+}
+
+// SwapAlias 实现 Engine 接口，通过 _aliases 的 remove 和 add 动作原子切换
+func (o *OpenSearch) SwapAlias(ctx context.Context, alias, index string) error {
+	previous, err := o.AliasIndices(ctx, alias)
+	if err != nil {
+		return err
+	}
+
+	actions := make([]map[string]interface{}, 0, len(previous)+1)
+	for _, name := range previous {
+		if name != index {
+			actions = append(actions, map[string]interface{}{
+				"remove": map[string]string{"index": name, "alias": alias},
+			})
+		}
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]interface{}{"index": index, "alias": alias, "is_write_index": true},
+	})
+	return o.do(ctx, http.MethodPost, "/_aliases", map[string]interface{}{"actions": actions}, nil)
+}
+
+// Index 实现 Engine 接口
+func (o *OpenSearch) Index(ctx context.Context, index, id string, doc interface{}) error {
+	return o.do(ctx, http.MethodPut, "/"+url.PathEscape(index)+"/_doc/"+url.PathEscape(id), doc, nil)
+}
+
+// Bulk 实现 Engine 接口，任一文档写入失败时返回第一个失败原因
+func (o *OpenSearch) Bulk(ctx context.Context, index string, docs []Document) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, doc := range docs {
+		action := map[string]interface{}{"index": map[string]string{"_index": index, "_id": doc.ID}}
+		if err := enc.Encode(action); err != nil {
+			return err
+		}
+		if err := enc.Encode(doc.Source); err != nil {
+			return fmt.Errorf("failed to encode document %s: %w", doc.ID, err)
+		}
+	}
+
+	var resp struct {
+		Errors bool `json:"errors"`
+		Items  []map[string]struct {
+			ID     string          `json:"_id"`
+			Status int             `json:"status"`
+			Error  json.RawMessage `json:"error"`
+		} `json:"items"`
+	}
+	if err := o.doRaw(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", &body, &resp); err != nil {
+		return err
+	}
+	if !resp.Errors {
+		return nil
+	}
+	for _, item := range resp.Items {
+		for _, result := range item {
+			if result.Status >= http.StatusMultipleChoices {
+				return fmt.Errorf("failed to index document %s: status %d: %s", result.ID, result.Status, result.Error)
+			}
+		}
+	}
+	return errors.New("bulk request reported errors")
+}
+
+// Delete 实现 Engine 接口
+func (o *OpenSearch) Delete(ctx context.Context, index, id string) error {
+	err := o.do(ctx, http.MethodDelete, "/"+url.PathEscape(index)+"/_doc/"+url.PathEscape(id), nil, nil)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// Search 实现 Engine 接口
+func (o *OpenSearch) Search(ctx context.Context, index string, req *Request) (*Result, error) {
+	var resp struct {
+		Hits struct {
+			Total struct {
+				Value int64 `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID     string          `json:"_id"`
+				Score  float64         `json:"_score"`
+				Source json.RawMessage `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := o.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_search", searchBody(req), &resp); err != nil {
+		return nil, err
+	}
+
+	result := &Result{Total: resp.Hits.Total.Value, Hits: make([]Hit, len(resp.Hits.Hits))}
+	for i, hit := range resp.Hits.Hits {
+		result.Hits[i] = Hit{ID: hit.ID, Score: hit.Score, Source: hit.Source}
+	}
+	return result, nil
+}
+
+// searchBody 将搜索请求转换为 bool 查询，关键字使用 multi_match，过滤条件使用 term
+func searchBody(req *Request) map[string]interface{} {
+	boolQuery := map[string]interface{}{}
+	if req.Query != "" {
+		match := map[string]interface{}{"query": req.Query, "operator": "and"}
+		if len(req.Fields) > 0 {
+			match["fields"] = req.Fields
+		}
+		boolQuery["must"] = []interface{}{map[string]interface{}{"multi_match": match}}
+	}
+	if len(req.Filters) > 0 {
+		filters := make([]interface{}, 0, len(req.Filters))
+		for field, value := range req.Filters {
+			filters = append(filters, map[string]interface{}{"term": map[string]interface{}{field: value}})
+		}
+		boolQuery["filter"] = filters
+	}
+
+	body := map[string]interface{}{
+		"query":            map[string]interface{}{"bool": boolQuery},
+		"track_total_hits": true,
+	}
+	if req.From > 0 {
+		body["from"] = req.From
+	}
+	if req.Size > 0 {
+		body["size"] = req.Size
+	}
+	if len(req.Sort) > 0 {
+		sorts := make([]interface{}, len(req.Sort))
+		for i, field := range req.Sort {
+			order := "asc"
+			if strings.HasPrefix(field, "-") {
+				field, order = field[1:], "desc"
+			}
+			sorts[i] = map[string]string{field: order}
+		}
+		body["sort"] = sorts
+	}
+	return body
+}
+
+// do 以 JSON 发送请求并解析响应
+func (o *OpenSearch) do(ctx context.Context, method, path string, reqBody, respBody interface{}) error {
+	var body io.Reader
+	if reqBody != nil {
+		data, err := json.Marshal(reqBody)
+		if err != nil {
+			return fmt.Errorf("failed to encode search request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+	return o.doRaw(ctx, method, path, "application/json", body, respBody)
+}
+
+// doRaw 依次尝试各节点，网络错误和 5xx 响应换下一个节点重试，404 返回 ErrNotFound
+func (o *OpenSearch) doRaw(ctx context.Context, method, path, contentType string, body io.Reader, respBody interface{}) error {
+	start := time.Now()
+	defer func() {
+		timing.Record(ctx, timing.KindSearch, method+" "+path, time.Since(start))
+	}()
+
+	var payload []byte
+	if body != nil {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return err
+		}
+		payload = data
+	}
+
+	var lastErr error
+	for _, addr := range o.addresses {
+		req, err := http.NewRequestWithContext(ctx, method, addr+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		if payload != nil {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if o.username != "" {
+			req.SetBasicAuth(o.username, o.password)
+		}
+
+		resp, err := o.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			lastErr = fmt.Errorf("search request %s %s failed: %w", method, path, err)
+			continue
+		}
+
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 32<<20))
+		resp.Body.Close()
+		switch {
+		case err != nil:
+			lastErr = fmt.Errorf("failed to read search response: %w", err)
+			continue
+		case resp.StatusCode >= http.StatusInternalServerError:
+			lastErr = fmt.Errorf("search request %s %s failed: status %d: %s", method, path, resp.StatusCode, truncate(data))
+			continue
+		case resp.StatusCode == http.StatusNotFound:
+			return fmt.Errorf("%w: %s %s", ErrNotFound, method, path)
+		case resp.StatusCode >= http.StatusMultipleChoices:
+			return fmt.Errorf("search request %s %s failed: status %d: %s", method, path, resp.StatusCode, truncate(data))
+		}
+
+		if respBody != nil && len(data) > 0 {
+			if err := json.Unmarshal(data, respBody); err != nil {
+				return fmt.Errorf("failed to decode search response: %w", err)
+			}
+		}
+		return nil
+	}
+	return lastErr
+}
+
+// truncate 截断错误响应，避免大段响应写入错误信息
+func truncate(data []byte) string {
+	const max = 512
+	if len(data) > max {
+		return string(data[:max]) + "..."
+	}
+	return string(data)
+}
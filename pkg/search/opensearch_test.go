@@ -0,0 +1,229 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+)
+
+// fakeCluster 记录请求并返回预设响应的 OpenSearch 模拟服务
+type fakeCluster struct {
+	mu       sync.Mutex
+	requests []string
+	bodies   map[string]string
+	aliases  map[string][]string
+}
+
+func newFakeCluster(t *testing.T) (*fakeCluster, *httptest.Server) {
+	f := &fakeCluster{bodies: map[string]string{}, aliases: map[string][]string{}}
+	srv := httptest.NewServer(http.HandlerFunc(f.handle))
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeCluster) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := r.Method + " " + r.URL.Path
+	body, _ := io.ReadAll(r.Body)
+	f.requests = append(f.requests, key)
+	f.bodies[key] = string(body)
+
+	switch {
+	case r.Method == http.MethodHead:
+		for alias := range f.aliases {
+			if "/"+alias == r.URL.Path {
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/_alias/"):
+		indices, ok := f.aliases[strings.TrimPrefix(r.URL.Path, "/_alias/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		resp := map[string]interface{}{}
+		for _, index := range indices {
+			resp[index] = map[string]interface{}{}
+		}
+		json.NewEncoder(w).Encode(resp)
+	case r.URL.Path == "/_aliases":
+		var req struct {
+			Actions []map[string]map[string]interface{} `json:"actions"`
+		}
+		json.Unmarshal(body, &req)
+		for _, action := range req.Actions {
+			if add, ok := action["add"]; ok {
+				alias := add["alias"].(string)
+				f.aliases[alias] = []string{add["index"].(string)}
+			}
+		}
+	case r.URL.Path == "/_bulk":
+		w.Write([]byte(`{"errors":true,"items":[{"index":{"_id":"1","status":201}},{"index":{"_id":"2","status":400,"error":{"type":"mapper_parsing_exception"}}}]}`))
+	case strings.HasSuffix(r.URL.Path, "/_search"):
+		w.Write([]byte(`{"hits":{"total":{"value":2},"hits":[{"_id":"1","_score":1.5,"_source":{"id":1,"name":"alice"}},{"_id":"2","_score":1.2,"_source":{"id":2,"name":"alina"}}]}}`))
+	case r.Method == http.MethodDelete:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTestEngine(t *testing.T, addresses ...string) *OpenSearch {
+	engine, err := NewOpenSearch(&config.Search{Addresses: addresses, Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("NewOpenSearch failed: %v", err)
+	}
+	return engine
+}
+
+func TestManagerEnsureCreatesVersionedIndex(t *testing.T) {
+	f, srv := newFakeCluster(t)
+	m := NewManager(newTestEngine(t, srv.URL), "app_")
+	def := &IndexDefinition{Name: "users", Mappings: map[string]interface{}{"dynamic": "strict"}}
+	ctx := context.Background()
+
+	if err := m.Ensure(ctx, def); err != nil {
+		t.Fatalf("Ensure failed: %v", err)
+	}
+	indices := f.aliases["app_users"]
+	if len(indices) != 1 || !strings.HasPrefix(indices[0], "app_users_") {
+		t.Fatalf("alias app_users points to %v", indices)
+	}
+	if body := f.bodies["PUT /"+indices[0]]; !strings.Contains(body, `"dynamic":"strict"`) {
+		t.Errorf("create index body = %s", body)
+	}
+
+	// 别名已存在时不再创建索引
+	f.requests = nil
+	if err := m.Ensure(ctx, def); err != nil {
+		t.Fatalf("second Ensure failed: %v", err)
+	}
+	if len(f.requests) != 1 {
+		t.Errorf("second Ensure requests = %v", f.requests)
+	}
+}
+
+func TestManagerSwapReturnsPreviousIndices(t *testing.T) {
+	f, srv := newFakeCluster(t)
+	f.aliases["app_users"] = []string{"app_users_20240101000000"}
+	m := NewManager(newTestEngine(t, srv.URL), "app_")
+
+	old, err := m.Swap(context.Background(), &IndexDefinition{Name: "users"}, "app_users_20250101000000")
+	if err != nil {
+		t.Fatalf("Swap failed: %v", err)
+	}
+	if len(old) != 1 || old[0] != "app_users_20240101000000" {
+		t.Errorf("old indices = %v", old)
+	}
+	body := f.bodies["POST /_aliases"]
+	if !strings.Contains(body, `"remove":{"alias":"app_users","index":"app_users_20240101000000"}`) {
+		t.Errorf("aliases body missing remove action: %s", body)
+	}
+	if got := f.aliases["app_users"]; len(got) != 1 || got[0] != "app_users_20250101000000" {
+		t.Errorf("alias points to %v", got)
+	}
+}
+
+func TestOpenSearchFailover(t *testing.T) {
+	var calls int
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	_, up := newFakeCluster(t)
+
+	engine := newTestEngine(t, down.URL, up.URL)
+	result, err := engine.Search(context.Background(), "app_users", &Request{Query: "ali"})
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("unavailable node calls = %d", calls)
+	}
+	if result.Total != 2 || len(result.Hits) != 2 || result.Hits[0].ID != "1" {
+		t.Errorf("result = %+v", result)
+	}
+
+	var docs []struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := result.Decode(&docs); err != nil || len(docs) != 2 || docs[1].Name != "alina" {
+		t.Errorf("Decode = %+v, %v", docs, err)
+	}
+}
+
+func TestOpenSearchNotFound(t *testing.T) {
+	_, srv := newFakeCluster(t)
+	engine := newTestEngine(t, srv.URL)
+	ctx := context.Background()
+
+	if err := engine.Delete(ctx, "app_users", "42"); err != nil {
+		t.Errorf("Delete of missing document = %v, want nil", err)
+	}
+	if exists, err := engine.IndexExists(ctx, "app_missing"); exists || err != nil {
+		t.Errorf("IndexExists = %v, %v", exists, err)
+	}
+	if indices, err := engine.AliasIndices(ctx, "app_missing"); len(indices) != 0 || err != nil {
+		t.Errorf("AliasIndices = %v, %v", indices, err)
+	}
+}
+
+func TestOpenSearchBulkReportsItemErrors(t *testing.T) {
+	f, srv := newFakeCluster(t)
+	engine := newTestEngine(t, srv.URL)
+
+	err := engine.Bulk(context.Background(), "app_users", []Document{
+		{ID: "1", Source: map[string]int{"id": 1}},
+		{ID: "2", Source: map[string]int{"id": 2}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "document 2") {
+		t.Errorf("Bulk error = %v", err)
+	}
+	if lines := strings.Count(f.bodies["POST /_bulk"], "\n"); lines != 4 {
+		t.Errorf("bulk body lines = %d, want 4", lines)
+	}
+}
+
+func TestSearchBody(t *testing.T) {
+	body := searchBody(&Request{
+		Query:   "alice",
+		Fields:  []string{"username^2"},
+		Filters: map[string]interface{}{"status": 1},
+		Sort:    []string{"-created_at"},
+		From:    20,
+		Size:    10,
+	})
+	data, _ := json.Marshal(body)
+	for _, want := range []string{
+		`"multi_match":{"fields":["username^2"],"operator":"and","query":"alice"}`,
+		`"filter":[{"term":{"status":1}}]`,
+		`"sort":[{"created_at":"desc"}]`,
+		`"from":20`,
+		`"size":10`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("search body %s missing %s", data, want)
+		}
+	}
+}
+
+func TestNewRejectsUnknownDriver(t *testing.T) {
+	if _, err := New(&config.Search{Driver: "solr", Addresses: []string{"http://127.0.0.1:9200"}}); err == nil {
+		t.Error("expected error for unsupported driver")
+	}
+	if _, err := New(&config.Search{}); err == nil || errors.Is(err, ErrNotFound) {
+		t.Errorf("expected error without addresses, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+)
+
+// 搜索驱动
+const (
+	DriverOpenSearch = "opensearch"
+)
+
+// ErrNotFound 索引或文档不存在
+var ErrNotFound = errors.New("search resource not found")
+
+// Engine 搜索引擎接口
+// index 参数既可以是物理索引名也可以是别名，业务代码只通过别名读写，由 Manager 维护别名指向的物理索引
+type Engine interface {
+	// Ping 检查集群是否可用
+	Ping(ctx context.Context) error
+	// IndexExists 检查索引或别名是否存在
+	IndexExists(ctx context.Context, index string) (bool, error)
+	// CreateIndex 按定义创建物理索引
+	CreateIndex(ctx context.Context, index string, def *IndexDefinition) error
+	// DeleteIndex 删除物理索引，索引不存在时不返回错误
+	DeleteIndex(ctx context.Context, index string) error
+	// AliasIndices 返回别名当前指向的物理索引，别名不存在时返回空
+	AliasIndices(ctx context.Context, alias string) ([]string, error)
+	// SwapAlias 在一次请求中将别名从原有索引移到 index，切换期间读写不会落空
+	SwapAlias(ctx context.Context, alias, index string) error
+	// Index 写入或覆盖文档
+	Index(ctx context.Context, index, id string, doc interface{}) error
+	// Bulk 批量写入或覆盖文档
+	Bulk(ctx context.Context, index string, docs []Document) error
+	// Delete 删除文档，文档不存在时不返回错误
+	Delete(ctx context.Context, index, id string) error
+	// Search 执行查询
+	Search(ctx context.Context, index string, req *Request) (*Result, error)
+}
+
+// Document 批量写入的文档
+type Document struct {
+	ID     string
+	Source interface{}
+}
+
+// Request 搜索请求
+type Request struct {
+	Query   string                 // 全文检索关键字，为空时匹配全部文档
+	Fields  []string               // 参与检索的字段，支持 "username^2" 形式的权重
+	Filters map[string]interface{} // 精确匹配的过滤条件，不参与评分
+	Sort    []string               // 排序字段，"-created_at" 表示倒序，为空时按相关度排序
+	From    int
+	Size    int
+}
+
+// Result 搜索结果
+type Result struct {
+	Total int64
+	Hits  []Hit
+}
+
+// Hit 命中的文档
+type Hit struct {
+	ID     string
+	Score  float64
+	Source json.RawMessage
+}
+
+// Decode 将命中文档解析到 dst 切片，dst 为 *[]T 或 *[]*T
+func (r *Result) Decode(dst interface{}) error {
+	sources := make([]json.RawMessage, len(r.Hits))
+	for i, hit := range r.Hits {
+		sources[i] = hit.Source
+	}
+	data, err := json.Marshal(sources)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst)
+}
+
+// IndexDefinition 索引定义，Name 为不带前缀的逻辑名称
+type IndexDefinition struct {
+	Name     string                 `json:"-"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	Mappings map[string]interface{} `json:"mappings,omitempty"`
+}
+
+// New 根据配置创建搜索引擎实例
+func New(cfg *config.Search) (Engine, error) {
+	switch cfg.Driver {
+	case DriverOpenSearch, "":
+		return NewOpenSearch(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported search driver: %s", cfg.Driver)
+	}
+}
+
+// Manager 索引管理，逻辑名称加上前缀作为别名，别名指向带时间版本号的物理索引，
+// 修改映射时创建新版本索引、写入数据后切换别名，旧版本索引由调用方决定是否删除
+type Manager struct {
+	engine Engine
+	prefix string
+}
+
+// NewManager 创建索引管理器，prefix 为别名前缀
+func NewManager(engine Engine, prefix string) *Manager {
+	return &Manager{engine: engine, prefix: prefix}
+}
+
+// Engine 返回搜索引擎
+func (m *Manager) Engine() Engine {
+	return m.engine
+}
+
+// Alias 返回逻辑名称对应的别名
+func (m *Manager) Alias(name string) string {
+	return m.prefix + name
+}
+
+// Ensure 别名不存在时创建第一个版本的物理索引并指向它，已存在时不做修改
+func (m *Manager) Ensure(ctx context.Context, def *IndexDefinition) error {
+	alias := m.Alias(def.Name)
+	exists, err := m.engine.IndexExists(ctx, alias)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	index, err := m.CreateVersion(ctx, def)
+	if err != nil {
+		return err
+	}
+	return m.engine.SwapAlias(ctx, alias, index)
+}
+
+// CreateVersion 创建新版本的物理索引并返回索引名，别名保持不变
+func (m *Manager) CreateVersion(ctx context.Context, def *IndexDefinition) (string, error) {
+	index := versionedIndex(m.Alias(def.Name), time.Now())
+	if err := m.engine.CreateIndex(ctx, index, def); err != nil {
+		return "", err
+	}
+	return index, nil
+}
+
+// Swap 将别名切换到 index，返回切换前别名指向的物理索引
+func (m *Manager) Swap(ctx context.Context, def *IndexDefinition, index string) ([]string, error) {
+	alias := m.Alias(def.Name)
+	previous, err := m.engine.AliasIndices(ctx, alias)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.engine.SwapAlias(ctx, alias, index); err != nil {
+		return nil, err
+	}
+
+	old := previous[:0]
+	for _, name := range previous {
+		if name != index {
+			old = append(old, name)
+		}
+	}
+	return old, nil
+}
+
+// versionedIndex 物理索引名，别名后追加 UTC 时间版本号
+func versionedIndex(alias string, now time.Time) string {
+	return strings.ToLower(alias) + "_" + now.UTC().Format("20060102150405")
+}
@@ -0,0 +1,83 @@
+// Package secretbox 使用 AES-256-GCM 加密保存在数据库中的秘密值，例如外部账号的访问令牌
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize 密钥长度
+const KeySize = 32
+
+// sealedPrefix 密文前缀，更换格式或算法时递增版本
+const sealedPrefix = "v1."
+
+// ErrInvalidCiphertext 密文格式错误、被篡改、附加数据不一致或由其他密钥加密
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// Box 对称加密器
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New 从 base64 编码的 32 字节密钥创建加密器
+func New(encodedKey string) (*Box, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("secretbox key must be base64 encoded: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("secretbox key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Seal 加密明文，空字符串原样返回
+// additional 为附加认证数据，不加密但参与校验，用于把密文绑定到所属记录，防止在记录之间挪用
+func (b *Box) Seal(plaintext, additional string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), []byte(additional))
+	return sealedPrefix + base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Open 解密 Seal 生成的密文，additional 必须与加密时一致，空字符串原样返回
+func (b *Box) Open(sealed, additional string) (string, error) {
+	if sealed == "" {
+		return "", nil
+	}
+	encoded, ok := strings.CutPrefix(sealed, sealedPrefix)
+	if !ok {
+		return "", ErrInvalidCiphertext
+	}
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil || len(data) < b.aead.NonceSize()+b.aead.Overhead() {
+		return "", ErrInvalidCiphertext
+	}
+
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, []byte(additional))
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,72 @@
+package secretbox
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func newKey(t *testing.T) string {
+	t.Helper()
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(key)
+}
+
+func TestSealAndOpen(t *testing.T) {
+	box, err := New(newKey(t))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	sealed, err := box.Seal("access-token", "github:42")
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if !strings.HasPrefix(sealed, sealedPrefix) || strings.Contains(sealed, "access-token") {
+		t.Fatalf("unexpected ciphertext %q", sealed)
+	}
+	if again, _ := box.Seal("access-token", "github:42"); again == sealed {
+		t.Fatal("sealing twice must use a fresh nonce")
+	}
+
+	plaintext, err := box.Open(sealed, "github:42")
+	if err != nil || plaintext != "access-token" {
+		t.Fatalf("Open = %q, %v", plaintext, err)
+	}
+
+	// 附加数据不一致、其他密钥或被篡改的密文都无法解密
+	other, _ := New(newKey(t))
+	for name, open := range map[string]func() (string, error){
+		"additional": func() (string, error) { return box.Open(sealed, "github:43") },
+		"other key":  func() (string, error) { return other.Open(sealed, "github:42") },
+		"tampered":   func() (string, error) { return box.Open(sealed[:len(sealed)-2]+"AA", "github:42") },
+		"no prefix":  func() (string, error) { return box.Open(strings.TrimPrefix(sealed, sealedPrefix), "github:42") },
+	} {
+		if _, err := open(); !errors.Is(err, ErrInvalidCiphertext) {
+			t.Errorf("%s: error = %v, want ErrInvalidCiphertext", name, err)
+		}
+	}
+}
+
+func TestEmptyValues(t *testing.T) {
+	box, _ := New(newKey(t))
+	if sealed, err := box.Seal("", "x"); sealed != "" || err != nil {
+		t.Fatalf("Seal(\"\") = %q, %v", sealed, err)
+	}
+	if plaintext, err := box.Open("", "x"); plaintext != "" || err != nil {
+		t.Fatalf("Open(\"\") = %q, %v", plaintext, err)
+	}
+}
+
+func TestNewValidatesKey(t *testing.T) {
+	for _, key := range []string{"", "not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := New(key); err == nil {
+			t.Errorf("New(%q) must fail", key)
+		}
+	}
+}
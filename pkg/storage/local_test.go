@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalPutGetDelete(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal failed: %v", err)
+	}
+
+	n, err := s.Put(ctx, "a/b/file.txt", strings.NewReader("hello"))
+	if err != nil || n != 5 {
+		t.Fatalf("Put failed: n=%d err=%v", n, err)
+	}
+
+	r, err := s.Get(ctx, "a/b/file.txt")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "hello" {
+		t.Fatalf("unexpected content: %q", data)
+	}
+
+	if err := s.DeletePrefix(ctx, "a"); err != nil {
+		t.Fatalf("DeletePrefix failed: %v", err)
+	}
+	if _, err := s.Get(ctx, "a/b/file.txt"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := s.Delete(ctx, "a/b/file.txt"); err != nil {
+		t.Fatalf("Delete of missing object should succeed, got %v", err)
+	}
+}
+
+func TestLocalRejectsEscapingKeys(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocal(root)
+	if err != nil {
+		t.Fatalf("NewLocal failed: %v", err)
+	}
+
+	path, err := s.path("../../etc/passwd")
+	if err != nil {
+		t.Fatalf("path failed: %v", err)
+	}
+	if !strings.HasPrefix(path, root) {
+		t.Fatalf("expected path inside root, got %s", path)
+	}
+
+	if err := s.DeletePrefix(context.Background(), ".."); err == nil {
+		t.Fatal("expected DeletePrefix on root to fail")
+	}
+}
+
+func TestLocalList(t *testing.T) {
+	ctx := context.Background()
+	s, err := NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewLocal failed: %v", err)
+	}
+	for _, key := range []string{"a/2/y.txt", "a/1/x.txt", "b/z.txt"} {
+		if _, err := s.Put(ctx, key, strings.NewReader(key)); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	keys, err := s.List(ctx, "a")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if strings.Join(keys, ",") != "a/1/x.txt,a/2/y.txt" {
+		t.Fatalf("unexpected keys: %v", keys)
+	}
+	if keys, err := s.List(ctx, "missing"); err != nil || len(keys) != 0 {
+		t.Fatalf("missing prefix should list nothing, got %v, %v", keys, err)
+	}
+}
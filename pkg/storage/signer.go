@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// 签名校验错误
+var (
+	ErrInvalidSignature = errors.New("invalid download signature")
+	ErrSignatureExpired = errors.New("download signature expired")
+)
+
+// URLSigner 为对象生成带过期时间的下载签名，签名内容为 key 和过期时间戳
+type URLSigner struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewURLSigner 创建下载签名器，secret 为空时返回 nil，不提供签名下载
+func NewURLSigner(secret string) *URLSigner {
+	if secret == "" {
+		return nil
+	}
+	return &URLSigner{secret: []byte(secret), now: time.Now}
+}
+
+// Sign 返回 key 在 expires 之前有效的签名
+func (s *URLSigner) Sign(key string, expires time.Time) string {
+	return s.sign(key, expires.Unix())
+}
+
+// Verify 校验签名，expires 为 Unix 秒级时间戳
+func (s *URLSigner) Verify(key, expires, signature string) error {
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal([]byte(signature), []byte(s.sign(key, unix))) {
+		return ErrInvalidSignature
+	}
+	if s.now().Unix() > unix {
+		return ErrSignatureExpired
+	}
+	return nil
+}
+
+func (s *URLSigner) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(key))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestURLSigner(t *testing.T) {
+	if NewURLSigner("") != nil {
+		t.Fatal("empty secret should disable signing")
+	}
+
+	now := time.Unix(1700000000, 0)
+	s := NewURLSigner("secret")
+	s.now = func() time.Time { return now }
+
+	expires := now.Add(time.Hour)
+	sig := s.Sign("exports/a.zip", expires)
+	exp := strconv.FormatInt(expires.Unix(), 10)
+
+	if err := s.Verify("exports/a.zip", exp, sig); err != nil {
+		t.Fatalf("valid signature rejected: %v", err)
+	}
+	if err := s.Verify("exports/b.zip", exp, sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("signature for another key: %v", err)
+	}
+	if err := s.Verify("exports/a.zip", strconv.FormatInt(expires.Unix()+1, 10), sig); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("tampered expiry: %v", err)
+	}
+
+	now = expires.Add(time.Second)
+	if err := s.Verify("exports/a.zip", exp, sig); !errors.Is(err, ErrSignatureExpired) {
+		t.Errorf("expired signature: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+)
+
+// 存储驱动
+const (
+	DriverLocal = "local"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("storage object not found")
+
+// Storage 对象存储接口
+// key 使用 "/" 分隔的相对路径，例如 "uploads/files/123/report.csv"
+type Storage interface {
+	// Put 写入对象，返回写入的字节数
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Get 读取对象，对象不存在时返回 ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete 删除对象，对象不存在时不返回错误
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix 删除指定前缀下的所有对象
+	DeletePrefix(ctx context.Context, prefix string) error
+	// List 按字典序返回指定前缀（目录）下所有对象的 key，前缀不存在时返回空
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// New 根据配置创建存储实例
+func New(cfg *config.Storage) (Storage, error) {
+	switch cfg.Driver {
+	case DriverLocal, "":
+		return NewLocal(cfg.Local.Root)
+	default:
+		return nil, fmt.Errorf("unsupported storage driver: %s", cfg.Driver)
+	}
+}
@@ -0,0 +1,230 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 模板目录约定
+// layouts/  布局，通过 {{block "content" .}}{{end}} 预留内容区域
+// partials/ 片段，页面和布局中通过 {{template "partials/<name>" .}} 引用
+// 其他目录  页面，通过 {{define "content"}}...{{end}} 填充布局
+const (
+	layoutsDir  = "layouts"
+	partialsDir = "partials"
+	extension   = ".html"
+)
+
+// Translator 模板中 t 函数使用的翻译器，locale 由实现从 ctx 中获取
+type Translator func(ctx context.Context, key string, args ...interface{}) string
+
+// ContextFuncs 返回绑定到 ctx 的模板函数，每次渲染时调用，用于按请求语言格式化等场景
+type ContextFuncs func(ctx context.Context) map[string]interface{}
+
+// Engine html/template 封装，支持布局、片段、embed.FS 加载和开发环境热加载
+type Engine struct {
+	fsys       fs.FS
+	reload     bool
+	funcs      htmltemplate.FuncMap
+	ctxFuncs   []ContextFuncs
+	translator Translator
+
+	mu    sync.RWMutex
+	cache map[string]*htmltemplate.Template
+}
+
+// Option 模板引擎选项
+type Option func(*Engine)
+
+// WithReload 每次渲染都重新解析模板，用于开发环境
+func WithReload(reload bool) Option {
+	return func(e *Engine) {
+		e.reload = reload
+	}
+}
+
+// WithFuncs 注册自定义模板函数
+func WithFuncs(funcs htmltemplate.FuncMap) Option {
+	return func(e *Engine) {
+		for name, fn := range funcs {
+			e.funcs[name] = fn
+		}
+	}
+}
+
+// WithContextFuncs 注册依赖请求上下文的模板函数
+func WithContextFuncs(fn ContextFuncs) Option {
+	return func(e *Engine) {
+		e.ctxFuncs = append(e.ctxFuncs, fn)
+	}
+}
+
+// WithTranslator 设置 t 函数使用的翻译器
+func WithTranslator(translator Translator) Option {
+	return func(e *Engine) {
+		e.translator = translator
+	}
+}
+
+// New 创建模板引擎，fsys 可以是 embed.FS 或 os.DirFS
+func New(fsys fs.FS, opts ...Option) *Engine {
+	e := &Engine{
+		fsys:       fsys,
+		funcs:      htmltemplate.FuncMap{},
+		translator: defaultTranslator,
+		cache:      make(map[string]*htmltemplate.Template),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Render 使用布局渲染页面，layout 为空时直接渲染页面
+// layout 和 page 均为不带扩展名的相对路径，例如 Render(ctx, w, "email", "emails/welcome", data)
+func (e *Engine) Render(ctx context.Context, w io.Writer, layout, page string, data interface{}) error {
+	tpl, err := e.lookup(layout, page)
+	if err != nil {
+		return err
+	}
+
+	// 克隆后绑定当前请求的翻译函数，避免并发渲染时互相影响
+	tpl, err = tpl.Clone()
+	if err != nil {
+		return err
+	}
+	tpl.Funcs(htmltemplate.FuncMap{
+		"t": func(key string, args ...interface{}) string {
+			return e.translator(ctx, key, args...)
+		},
+	})
+	for _, fn := range e.ctxFuncs {
+		tpl.Funcs(fn(ctx))
+	}
+
+	name := page
+	if layout != "" {
+		name = path.Join(layoutsDir, layout)
+	}
+	return tpl.ExecuteTemplate(w, name, data)
+}
+
+// RenderString 渲染为字符串
+func (e *Engine) RenderString(ctx context.Context, layout, page string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	if err := e.Render(ctx, &buf, layout, page, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// HTML 渲染页面并作为 HTML 响应输出，用于简单的服务端页面
+func (e *Engine) HTML(c *gin.Context, code int, layout, page string, data interface{}) {
+	html, err := e.RenderString(c.Request.Context(), layout, page, data)
+	if err != nil {
+		_ = c.Error(err)
+		c.String(http.StatusInternalServerError, "template render failed")
+		return
+	}
+	c.Data(code, "text/html; charset=utf-8", []byte(html))
+}
+
+// Preload 预先解析 dir 下所有页面与 layout 的组合，非热加载模式下写入缓存，模板有错误时返回错误
+func (e *Engine) Preload(layout, dir string) error {
+	pages, err := fs.Glob(e.fsys, path.Join(dir, "*"+extension))
+	if err != nil {
+		return err
+	}
+	for _, page := range pages {
+		if _, err := e.lookup(layout, strings.TrimSuffix(page, extension)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookup 获取解析后的模板，非热加载模式下缓存解析结果
+func (e *Engine) lookup(layout, page string) (*htmltemplate.Template, error) {
+	key := layout + "|" + page
+	if !e.reload {
+		e.mu.RLock()
+		tpl, ok := e.cache[key]
+		e.mu.RUnlock()
+		if ok {
+			return tpl, nil
+		}
+	}
+
+	tpl, err := e.parse(layout, page)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.reload {
+		e.mu.Lock()
+		e.cache[key] = tpl
+		e.mu.Unlock()
+	}
+	return tpl, nil
+}
+
+// parse 解析片段、布局和页面，模板以不带扩展名的相对路径命名
+func (e *Engine) parse(layout, page string) (*htmltemplate.Template, error) {
+	funcs := htmltemplate.FuncMap{"t": defaultTranslatorFunc}
+	for name, fn := range e.funcs {
+		funcs[name] = fn
+	}
+	// 解析时只需要函数名，渲染时替换为绑定请求上下文的实现
+	for _, fn := range e.ctxFuncs {
+		for name, f := range fn(context.Background()) {
+			funcs[name] = f
+		}
+	}
+	// 根模板只作为容器，页面、布局和片段都以各自路径命名关联到根模板
+	tpl := htmltemplate.New("").Funcs(funcs)
+
+	partials, err := fs.Glob(e.fsys, path.Join(partialsDir, "*"+extension))
+	if err != nil {
+		return nil, err
+	}
+
+	files := partials
+	if layout != "" {
+		files = append(files, path.Join(layoutsDir, layout)+extension)
+	}
+	files = append(files, page+extension)
+
+	for _, file := range files {
+		content, err := fs.ReadFile(e.fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template %s: %w", file, err)
+		}
+		name := strings.TrimSuffix(file, extension)
+		if _, err := tpl.New(name).Parse(string(content)); err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", file, err)
+		}
+	}
+	return tpl, nil
+}
+
+// defaultTranslator 未配置翻译器时原样返回 key
+func defaultTranslator(_ context.Context, key string, args ...interface{}) string {
+	return defaultTranslatorFunc(key, args...)
+}
+
+func defaultTranslatorFunc(key string, args ...interface{}) string {
+	if len(args) == 0 {
+		return key
+	}
+	return fmt.Sprintf(key, args...)
+}
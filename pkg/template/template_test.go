@@ -0,0 +1,104 @@
+package template
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func testFS() fstest.MapFS {
+	return fstest.MapFS{
+		"layouts/base.html":    {Data: []byte(`<main>{{block "content" .}}{{end}}</main>{{template "partials/footer" .}}`)},
+		"partials/footer.html": {Data: []byte(`<footer>{{t "bye"}}</footer>`)},
+		"pages/hello.html":     {Data: []byte(`{{define "content"}}{{t "hello %s" .Name}}{{end}}`)},
+		"pages/plain.html":     {Data: []byte(`plain {{.Name}}`)},
+	}
+}
+
+func TestRenderWithLayout(t *testing.T) {
+	type ctxKey struct{}
+	engine := New(testFS(), WithTranslator(func(ctx context.Context, key string, args ...interface{}) string {
+		return ctx.Value(ctxKey{}).(string) + ":" + key
+	}))
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "zh")
+	out, err := engine.RenderString(ctx, "base", "pages/hello", map[string]string{"Name": "<b>"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	want := "<main>zh:hello %s</main><footer>zh:bye</footer>"
+	if out != want {
+		t.Fatalf("unexpected output:\n got: %s\nwant: %s", out, want)
+	}
+}
+
+func TestRenderWithoutLayoutEscapesHTML(t *testing.T) {
+	engine := New(testFS())
+
+	out, err := engine.RenderString(context.Background(), "", "pages/plain", map[string]string{"Name": "<b>"})
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(out, "&lt;b&gt;") {
+		t.Fatalf("expected escaped output, got %s", out)
+	}
+}
+
+func TestRenderReload(t *testing.T) {
+	fsys := testFS()
+	engine := New(fsys, WithReload(true))
+
+	if _, err := engine.RenderString(context.Background(), "", "pages/plain", nil); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	fsys["pages/plain.html"] = &fstest.MapFile{Data: []byte(`changed`)}
+
+	out, err := engine.RenderString(context.Background(), "", "pages/plain", nil)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if out != "changed" {
+		t.Fatalf("expected reloaded template, got %s", out)
+	}
+}
+
+func TestRenderWithContextFuncs(t *testing.T) {
+	type ctxKey struct{}
+	fsys := fstest.MapFS{"pages/lang.html": {Data: []byte(`{{lang}}`)}}
+	engine := New(fsys, WithContextFuncs(func(ctx context.Context) map[string]interface{} {
+		return map[string]interface{}{
+			"lang": func() string {
+				lang, _ := ctx.Value(ctxKey{}).(string)
+				return lang
+			},
+		}
+	}))
+
+	for _, lang := range []string{"zh", "en"} {
+		out, err := engine.RenderString(context.WithValue(context.Background(), ctxKey{}, lang), "", "pages/lang", nil)
+		if err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+		if out != lang {
+			t.Fatalf("expected %s, got %s", lang, out)
+		}
+	}
+}
+
+func TestPreload(t *testing.T) {
+	engine := New(testFS())
+	if err := engine.Preload("base", "pages"); err != nil {
+		t.Fatalf("Preload failed: %v", err)
+	}
+	if len(engine.cache) != 2 {
+		t.Errorf("cached templates = %d, want 2", len(engine.cache))
+	}
+
+	broken := testFS()
+	broken["pages/broken.html"] = &fstest.MapFile{Data: []byte(`{{if}}`)}
+	if err := New(broken).Preload("base", "pages"); err == nil {
+		t.Error("Preload should fail on invalid template")
+	}
+}
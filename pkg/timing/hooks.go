@@ -0,0 +1,65 @@
+package timing
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisHook 记录 Redis 命令耗时的 go-redis hook
+type RedisHook struct{}
+
+// DialHook 实现 redis.Hook 接口
+func (RedisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+// ProcessHook 实现 redis.Hook 接口
+func (RedisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if FromContext(ctx) == nil {
+			return next(ctx, cmd)
+		}
+		start := time.Now()
+		err := next(ctx, cmd)
+		Record(ctx, KindRedis, cmd.Name(), time.Since(start))
+		return err
+	}
+}
+
+// ProcessPipelineHook 实现 redis.Hook 接口，整个 pipeline 记为一次调用
+func (RedisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if FromContext(ctx) == nil {
+			return next(ctx, cmds)
+		}
+		start := time.Now()
+		err := next(ctx, cmds)
+		Record(ctx, KindRedis, "pipeline", time.Since(start))
+		return err
+	}
+}
+
+// Transport 包装 http.RoundTripper，记录出站 HTTP 请求耗时，base 为 nil 时使用 http.DefaultTransport
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		defer Start(req.Context(), KindHTTP, req.Method+" "+req.URL.Host)()
+		return base.RoundTrip(req)
+	})
+}
+
+// roundTripperFunc 函数形式的 http.RoundTripper
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip 实现 http.RoundTripper 接口
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
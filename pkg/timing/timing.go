@@ -0,0 +1,125 @@
+// Package timing 记录单个请求内下游调用（数据库、Redis、MongoDB、搜索引擎、消息队列、HTTP）的耗时
+// 只有 context 中挂载了 Recorder 时才会记录，否则所有记录操作都是空操作
+package timing
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 下游调用类型
+const (
+	KindDB     = "db"
+	KindRedis  = "redis"
+	KindMongo  = "mongo"
+	KindSearch = "search"
+	KindMQ     = "mq"
+	KindHTTP   = "http"
+)
+
+// maxCalls 单个请求最多保留的调用明细数量，超出后只累计汇总
+const maxCalls = 256
+
+// recorderKey Recorder 在 context 中的键
+type recorderKey struct{}
+
+// Call 一次下游调用
+type Call struct {
+	Kind     string        `json:"kind"`
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Summary 同类下游调用的汇总
+type Summary struct {
+	Count int           `json:"count"`
+	Total time.Duration `json:"total"`
+}
+
+// Recorder 单个请求的下游调用记录
+type Recorder struct {
+	mu        sync.Mutex
+	calls     []Call
+	summaries map[string]*Summary
+}
+
+// WithRecorder 在 context 中挂载一个新的 Recorder
+func WithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	r := &Recorder{summaries: make(map[string]*Summary)}
+	return context.WithValue(ctx, recorderKey{}, r), r
+}
+
+// FromContext 从 context 中获取 Recorder，不存在时返回 nil
+func FromContext(ctx context.Context) *Recorder {
+	if ctx == nil {
+		return nil
+	}
+	r, _ := ctx.Value(recorderKey{}).(*Recorder)
+	return r
+}
+
+// Record 记录一次下游调用
+func Record(ctx context.Context, kind, name string, d time.Duration) {
+	if r := FromContext(ctx); r != nil {
+		r.record(kind, name, d)
+	}
+}
+
+// Start 开始计时，返回的函数结束计时并记录
+//
+//	defer timing.Start(ctx, timing.KindHTTP, "GET example.com")()
+func Start(ctx context.Context, kind, name string) func() {
+	r := FromContext(ctx)
+	if r == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() {
+		r.record(kind, name, time.Since(start))
+	}
+}
+
+// record 写入调用记录
+func (r *Recorder) record(kind, name string, d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.calls) < maxCalls {
+		r.calls = append(r.calls, Call{Kind: kind, Name: name, Duration: d})
+	}
+	summary, ok := r.summaries[kind]
+	if !ok {
+		summary = &Summary{}
+		r.summaries[kind] = summary
+	}
+	summary.Count++
+	summary.Total += d
+}
+
+// Summaries 返回按类型汇总的调用次数和总耗时
+func (r *Recorder) Summaries() map[string]Summary {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	summaries := make(map[string]Summary, len(r.summaries))
+	for kind, s := range r.summaries {
+		summaries[kind] = *s
+	}
+	return summaries
+}
+
+// Slowest 返回耗时最长的 n 次调用
+func (r *Recorder) Slowest(n int) []Call {
+	r.mu.Lock()
+	calls := make([]Call, len(r.calls))
+	copy(calls, r.calls)
+	r.mu.Unlock()
+
+	sort.SliceStable(calls, func(i, j int) bool { return calls[i].Duration > calls[j].Duration })
+	if len(calls) > n {
+		calls = calls[:n]
+	}
+	return calls
+}
@@ -0,0 +1,28 @@
+package timing
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	// 未挂载 Recorder 时不记录
+	Record(context.Background(), KindDB, "query users", time.Second)
+	Start(context.Background(), KindHTTP, "GET example.com")()
+
+	ctx, recorder := WithRecorder(context.Background())
+	Record(ctx, KindDB, "query users", 30*time.Millisecond)
+	Record(ctx, KindDB, "update users", 10*time.Millisecond)
+	Record(ctx, KindRedis, "get", 50*time.Millisecond)
+
+	summaries := recorder.Summaries()
+	if db := summaries[KindDB]; db.Count != 2 || db.Total != 40*time.Millisecond {
+		t.Fatalf("unexpected db summary: %+v", db)
+	}
+
+	slowest := recorder.Slowest(2)
+	if len(slowest) != 2 || slowest[0].Kind != KindRedis || slowest[1].Name != "query users" {
+		t.Fatalf("unexpected slowest calls: %+v", slowest)
+	}
+}
@@ -0,0 +1,113 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+	"github.com/hedeqiang/skeleton/pkg/buildinfo"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// 采样策略
+const (
+	SamplerConst         = "const"         // sampler_param 大于 0 时全部采样，否则不采样
+	SamplerProbabilistic = "probabilistic" // 按 sampler_param 的比例采样
+)
+
+// defaultURLPath OTLP/HTTP 默认的上报路径
+const defaultURLPath = "/v1/traces"
+
+// Provider OpenTelemetry TracerProvider，通过 OTLP/HTTP 上报 span
+// 创建后注册为全局 TracerProvider，pkg/mq、pkg/database 等通过 otel.Tracer 创建的 span 都由它导出
+type Provider struct {
+	tp *sdktrace.TracerProvider
+}
+
+// NewProvider 根据 trace 配置创建 Provider 并注册为全局 TracerProvider 和传播器
+// 采样器以上游的采样决定为准，没有上游链路时按 sampler_type 采样
+func NewProvider(cfg *config.Trace, app *config.App) (*Provider, error) {
+	sampler, err := newSampler(cfg.SamplerType, cfg.SamplerParam)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid trace endpoint: %q", cfg.Endpoint)
+	}
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpointURL(cfg.Endpoint)}
+	if endpoint.Path == "" || endpoint.Path == "/" {
+		options = append(options, otlptracehttp.WithURLPath(defaultURLPath))
+	}
+	// 只创建客户端，首次上报时才建立连接，collector 不可用不影响启动
+	exporter, err := otlptracehttp.New(context.Background(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(app.Name),
+			semconv.ServiceVersion(buildinfo.Version),
+			semconv.DeploymentEnvironment(app.Env),
+		)),
+	)
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return &Provider{tp: tp}, nil
+}
+
+// Tracer 返回指定 instrumentation 名称的 Tracer
+func (p *Provider) Tracer(name string) oteltrace.Tracer {
+	return p.tp.Tracer(name)
+}
+
+// Shutdown 上报缓冲中的 span 并关闭导出器，p 为 nil 时不做任何事情
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p == nil {
+		return nil
+	}
+	return p.tp.Shutdown(ctx)
+}
+
+// newSampler 根据 sampler_type 创建根 span 的采样器
+func newSampler(samplerType string, param float64) (sdktrace.Sampler, error) {
+	switch strings.ToLower(samplerType) {
+	case "", SamplerConst:
+		if param > 0 {
+			return sdktrace.AlwaysSample(), nil
+		}
+		return sdktrace.NeverSample(), nil
+	case SamplerProbabilistic:
+		if param < 0 || param > 1 {
+			return nil, fmt.Errorf("trace sampler_param must be between 0 and 1, got %v", param)
+		}
+		return sdktrace.TraceIDRatioBased(param), nil
+	default:
+		return nil, fmt.Errorf("unsupported trace sampler_type: %q", samplerType)
+	}
+}
+
+// FromSpanContext 将 OpenTelemetry span 转换为 traceparent，span 无效时返回 false
+func FromSpanContext(sc oteltrace.SpanContext) (TraceParent, bool) {
+	if !sc.IsValid() {
+		return TraceParent{}, false
+	}
+	return TraceParent{
+		TraceID:  sc.TraceID().String(),
+		ParentID: sc.SpanID().String(),
+		Flags:    byte(sc.TraceFlags()),
+	}, true
+}
@@ -0,0 +1,96 @@
+package trace
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/hedeqiang/skeleton/internal/config"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestNewSampler(t *testing.T) {
+	tests := []struct {
+		samplerType string
+		param       float64
+		want        string
+		wantErr     bool
+	}{
+		{"", 1, "AlwaysOnSampler", false},
+		{"const", 0, "AlwaysOffSampler", false},
+		{"probabilistic", 0.1, "TraceIDRatioBased{0.1}", false},
+		{"probabilistic", 2, "", true},
+		{"rateLimiting", 100, "", true},
+	}
+	for _, tt := range tests {
+		sampler, err := newSampler(tt.samplerType, tt.param)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("newSampler(%q, %v) must fail", tt.samplerType, tt.param)
+			}
+			continue
+		}
+		if err != nil || sampler.Description() != tt.want {
+			t.Errorf("newSampler(%q, %v) = %v, %v; want %s", tt.samplerType, tt.param, sampler, err, tt.want)
+		}
+	}
+}
+
+func TestProviderExportsSpans(t *testing.T) {
+	previous, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+
+	paths := make(chan string, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+	}))
+	defer collector.Close()
+
+	p, err := NewProvider(&config.Trace{Endpoint: collector.URL, SamplerType: "const", SamplerParam: 1}, &config.App{Name: "skeleton", Env: "test"})
+	if err != nil {
+		t.Fatalf("NewProvider failed: %v", err)
+	}
+	if fields := otel.GetTextMapPropagator().Fields(); len(fields) == 0 || fields[0] != Header {
+		t.Fatalf("global propagator fields = %v, want %s first", fields, Header)
+	}
+
+	// 全局 Tracer 创建的 span 同样由 Provider 导出
+	_, span := otel.Tracer("test").Start(context.Background(), "request")
+	tp, ok := FromSpanContext(span.SpanContext())
+	span.End()
+	if !ok || !tp.Sampled() {
+		t.Fatalf("FromSpanContext = %+v, %v; want a sampled traceparent", tp, ok)
+	}
+
+	// Shutdown 时上报缓冲中的 span，未指定路径时使用 OTLP 默认路径
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+	select {
+	case path := <-paths:
+		if path != defaultURLPath {
+			t.Fatalf("exported to %q, want %q", path, defaultURLPath)
+		}
+	default:
+		t.Fatal("spans were not exported on shutdown")
+	}
+
+	if err := (*Provider)(nil).Shutdown(context.Background()); err != nil {
+		t.Fatalf("nil Provider Shutdown = %v", err)
+	}
+}
+
+func TestNewProviderRejectsInvalidConfig(t *testing.T) {
+	app := &config.App{Name: "skeleton"}
+	if _, err := NewProvider(&config.Trace{Endpoint: "127.0.0.1:4318"}, app); err == nil {
+		t.Fatal("endpoint without scheme must be rejected")
+	}
+	if _, err := NewProvider(&config.Trace{Endpoint: "http://127.0.0.1:4318", SamplerType: "remote"}, app); err == nil {
+		t.Fatal("unsupported sampler must be rejected")
+	}
+}
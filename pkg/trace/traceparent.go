@@ -0,0 +1,118 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Header W3C Trace Context 请求头
+const Header = "traceparent"
+
+// flagSampled 采样标志位
+const flagSampled = 0x01
+
+// TraceParent W3C traceparent，格式为 version-trace_id-parent_id-flags
+// 参见 https://www.w3.org/TR/trace-context/#traceparent-header
+type TraceParent struct {
+	TraceID  string // 32 位十六进制
+	ParentID string // 16 位十六进制，当前服务中即为本次请求的 span ID
+	Flags    byte
+}
+
+// Parse 解析 traceparent 请求头，格式非法或 ID 全为 0 时返回错误
+func Parse(value string) (TraceParent, error) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return TraceParent{}, fmt.Errorf("invalid traceparent: %q", value)
+	}
+
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	// 版本 00 必须正好 4 段，未来版本允许追加字段
+	if !isHex(version, 2) || version == "ff" || (version == "00" && len(parts) != 4) {
+		return TraceParent{}, fmt.Errorf("invalid traceparent version: %q", value)
+	}
+	if !isHex(traceID, 32) || isZero(traceID) {
+		return TraceParent{}, fmt.Errorf("invalid trace id: %q", value)
+	}
+	if !isHex(parentID, 16) || isZero(parentID) {
+		return TraceParent{}, fmt.Errorf("invalid parent id: %q", value)
+	}
+	if !isHex(flags, 2) {
+		return TraceParent{}, fmt.Errorf("invalid trace flags: %q", value)
+	}
+
+	b, _ := hex.DecodeString(flags)
+	return TraceParent{TraceID: traceID, ParentID: parentID, Flags: b[0]}, nil
+}
+
+// New 创建新的链路，默认采样
+func New() TraceParent {
+	return TraceParent{
+		TraceID:  randomHex(16),
+		ParentID: randomHex(8),
+		Flags:    flagSampled,
+	}
+}
+
+// Child 在同一链路上创建新的 span，用于服务处理上游请求或调用下游服务
+func (t TraceParent) Child() TraceParent {
+	return TraceParent{
+		TraceID:  t.TraceID,
+		ParentID: randomHex(8),
+		Flags:    t.Flags,
+	}
+}
+
+// Sampled 上游是否要求采样
+func (t TraceParent) Sampled() bool {
+	return t.Flags&flagSampled != 0
+}
+
+// String 序列化为 traceparent 请求头
+func (t TraceParent) String() string {
+	return fmt.Sprintf("00-%s-%s-%02x", t.TraceID, t.ParentID, t.Flags)
+}
+
+type contextKey struct{}
+
+// NewContext 将 traceparent 写入 context，调用下游服务时通过 FromContext 取出并传递
+func NewContext(ctx context.Context, t TraceParent) context.Context {
+	return context.WithValue(ctx, contextKey{}, t)
+}
+
+// FromContext 从 context 中获取 traceparent
+func FromContext(ctx context.Context) (TraceParent, bool) {
+	t, ok := ctx.Value(contextKey{}).(TraceParent)
+	return t, ok
+}
+
+func isHex(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		// 规范要求小写十六进制
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func isZero(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	for {
+		_, _ = rand.Read(b)
+		if s := hex.EncodeToString(b); !isZero(s) {
+			return s
+		}
+	}
+}
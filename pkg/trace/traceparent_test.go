@@ -0,0 +1,37 @@
+package trace
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tp, err := Parse("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if tp.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || tp.ParentID != "00f067aa0ba902b7" || !tp.Sampled() {
+		t.Fatalf("unexpected traceparent: %+v", tp)
+	}
+
+	child := tp.Child()
+	if child.TraceID != tp.TraceID || child.ParentID == tp.ParentID {
+		t.Fatalf("child should keep trace id and use new span id: %+v", child)
+	}
+	if _, err := Parse(child.String()); err != nil {
+		t.Fatalf("child traceparent should be valid: %v", err)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	} {
+		if _, err := Parse(value); err == nil {
+			t.Errorf("expected error for %q", value)
+		}
+	}
+}
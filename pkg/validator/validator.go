@@ -1,7 +1,9 @@
 package validator
 
 import (
+	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/gin-gonic/gin/binding"
@@ -22,6 +24,14 @@ type CustomValidator struct {
 // Initialize a singleton instance of the validator and translator
 var defaultValidator *CustomValidator
 
+// verbose 开启后 Message 附带校验规则、参数和实际值，仅用于开发环境
+var verbose bool
+
+// SetVerbose 设置是否输出详细的校验错误
+func SetVerbose(v bool) {
+	verbose = v
+}
+
 func init() {
 	// 初始化翻译器
 	en := en.New()
@@ -66,6 +76,31 @@ func (v *CustomValidator) Engine() interface{} {
 	return v.Validate
 }
 
+// Struct 使用默认校验器校验结构体
+func Struct(obj interface{}) error {
+	return defaultValidator.Validate.Struct(obj)
+}
+
+// Message 将校验错误转换为返回给客户端的提示
+// 默认返回翻译后的提示，开启 verbose 时追加字段路径、校验规则和实际值
+func Message(err error) string {
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return err.Error()
+	}
+
+	messages := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		msg := fe.Translate(defaultValidator.Trans)
+		if verbose {
+			msg = fmt.Sprintf("%s (field=%s tag=%s param=%s value=%v)", msg, fe.Namespace(), fe.Tag(), fe.Param(), fe.Value())
+		}
+		messages = append(messages, msg)
+	}
+	sort.Strings(messages)
+	return strings.Join(messages, "; ")
+}
+
 // Translate 将校验错误翻译成更友好的格式
 func Translate(err error) map[string]string {
 	if defaultValidator == nil {
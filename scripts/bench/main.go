@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/hedeqiang/skeleton/pkg/bench"
+	"github.com/hedeqiang/skeleton/pkg/buildinfo"
+)
+
+// targetList 可重复的 -target 参数
+type targetList []bench.Target
+
+func (t *targetList) String() string {
+	names := make([]string, len(*t))
+	for i, target := range *t {
+		names[i] = target.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+func (t *targetList) Set(value string) error {
+	target, err := bench.ParseTarget(value)
+	if err != nil {
+		return err
+	}
+	*t = append(*t, target)
+	return nil
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "http" {
+		fmt.Fprintln(os.Stderr, "usage: bench http [flags]")
+		os.Exit(2)
+	}
+
+	fs := flag.NewFlagSet("http", flag.ExitOnError)
+	var targets targetList
+	baseURL := fs.String("url", "http://localhost:8080", "服务地址")
+	fs.Var(&targets, "target", "压测接口，格式为 \"METHOD /path\"，可重复指定，默认 GET /ping")
+	rps := fs.Int("rps", 50, "每个接口每秒请求数")
+	duration := fs.Duration("duration", 30*time.Second, "压测时长")
+	concurrency := fs.Int("concurrency", 0, "每个接口最大并发请求数，默认等于 rps")
+	timeout := fs.Duration("timeout", 10*time.Second, "单个请求超时")
+	token := fs.String("token", "", "附加 Authorization: Bearer 请求头")
+	baselinePath := fs.String("baseline", "", "基线文件路径，指定后与基线对比，退化时以状态码 1 退出")
+	save := fs.Bool("save", false, "将本次结果保存为基线")
+	tolerance := fs.Float64("tolerance", 0.2, "允许的退化比例，0.2 表示延迟增加 20% 以内不算退化")
+	fs.Parse(os.Args[2:])
+
+	if len(targets) == 0 {
+		targets = targetList{{Method: "GET", Path: "/ping"}}
+	}
+	if *token != "" {
+		for i := range targets {
+			targets[i].Header = map[string]string{"Authorization": "Bearer " + *token}
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Printf("Benchmarking %s: %d rps x %s per target\n", *baseURL, *rps, *duration)
+	results, err := bench.Run(ctx, bench.Options{
+		BaseURL:     *baseURL,
+		Targets:     targets,
+		RPS:         *rps,
+		Duration:    *duration,
+		Concurrency: *concurrency,
+		Timeout:     *timeout,
+	})
+	if err != nil {
+		log.Fatalf("Benchmark failed: %v", err)
+	}
+	printResults(results)
+
+	if *baselinePath == "" {
+		return
+	}
+
+	if *save {
+		err := bench.SaveBaseline(*baselinePath, &bench.Baseline{
+			CreatedAt: time.Now(),
+			Version:   buildinfo.Version,
+			RPS:       *rps,
+			Duration:  duration.String(),
+			Results:   results,
+		})
+		if err != nil {
+			log.Fatalf("Failed to save baseline: %v", err)
+		}
+		fmt.Printf("Baseline saved to %s\n", *baselinePath)
+		return
+	}
+
+	baseline, err := bench.LoadBaseline(*baselinePath)
+	if err != nil {
+		log.Fatalf("Failed to load baseline: %v", err)
+	}
+	regressions := bench.Compare(baseline, results, *tolerance)
+	if len(regressions) == 0 {
+		fmt.Printf("No regressions against baseline from %s\n", baseline.CreatedAt.Format(time.RFC3339))
+		return
+	}
+	fmt.Printf("%d regressions against baseline from %s:\n", len(regressions), baseline.CreatedAt.Format(time.RFC3339))
+	for _, r := range regressions {
+		fmt.Println("  " + r.String())
+	}
+	os.Exit(1)
+}
+
+// printResults 以表格形式输出结果
+func printResults(results []bench.Result) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TARGET\tREQUESTS\tERRORS\tDROPPED\tP50\tP95\tP99\tMAX")
+	for _, r := range results {
+		fmt.Fprintf(w, "%s\t%d\t%.2f%%\t%d\t%s\t%s\t%s\t%s\n",
+			r.Target, r.Requests, r.ErrorRate*100, r.Dropped,
+			r.P50.Round(time.Microsecond), r.P95.Round(time.Microsecond),
+			r.P99.Round(time.Microsecond), r.Max.Round(time.Microsecond))
+	}
+	w.Flush()
+}
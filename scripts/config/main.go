@@ -0,0 +1,59 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/hedeqiang/skeleton/configs"
+)
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "init" {
+		fmt.Fprintln(os.Stderr, "usage: config init [-env dev|docker|prod] [-o path] [-force]")
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("init", flag.ExitOnError)
+	env := flags.String("env", "dev", "配置模板对应的环境: dev、docker、prod")
+	output := flags.String("o", "config.yaml", "输出文件路径，- 表示输出到标准输出")
+	force := flags.Bool("force", false, "覆盖已存在的文件")
+	flags.Parse(os.Args[2:])
+
+	data, err := configs.Default(*env)
+	if err != nil {
+		log.Fatalf("Failed to load sample config: %v", err)
+	}
+
+	if *output == "-" {
+		os.Stdout.Write(data)
+		return
+	}
+
+	if err := writeConfig(*output, data, *force); err != nil {
+		log.Fatalf("Failed to write config: %v", err)
+	}
+	fmt.Printf("Sample %s config written to %s (use CONFIG_FILE=%s to load it)\n", *env, *output, *output)
+}
+
+// writeConfig 写入配置文件，未指定 force 时不覆盖已有文件
+func writeConfig(path string, data []byte, force bool) error {
+	if !force {
+		if _, err := os.Stat(path); err == nil {
+			return fmt.Errorf("%s already exists, use -force to overwrite", path)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, data, 0o644)
+}
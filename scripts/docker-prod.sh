@@ -22,6 +22,7 @@ check_env_vars() {
         "RABBITMQ_USER"
         "RABBITMQ_PASSWORD"
         "JWT_SECRET"
+        "STORAGE_SIGNING_SECRET"
     )
     
     missing_vars=()
@@ -1,17 +1,25 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"sort"
+
 	"github.com/hedeqiang/skeleton/internal/config"
-	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/migrations"
 	"github.com/hedeqiang/skeleton/pkg/database"
 	"github.com/hedeqiang/skeleton/pkg/logger"
-	"fmt"
-	"log"
+	"github.com/hedeqiang/skeleton/pkg/migrate"
 
 	"go.uber.org/zap"
 )
 
 func main() {
+	statusOnly := flag.Bool("status", false, "只打印迁移状态，不执行迁移")
+	flag.Parse()
+
 	fmt.Println("Starting database migration...")
 
 	// 1. 加载配置
@@ -28,27 +36,51 @@ func main() {
 	defer zapLogger.Sync()
 
 	// 3. 初始化数据库连接
-	dataSources, err := database.NewDatabases(cfg.Databases)
+	dataSources, err := database.NewDatabases(cfg.Databases, zapLogger)
 	if err != nil {
 		zapLogger.Fatal("Failed to initialize databases", zap.Error(err))
 	}
 
-	// 4. 获取主数据库连接
-	mainDB, exists := dataSources["default"]
-	if !exists {
-		zapLogger.Fatal("Main database connection not found")
+	names := make([]string, 0, len(dataSources))
+	for name := range dataSources {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	// 5. 执行自动迁移
-	zapLogger.Info("Running auto migration...")
+	// 4. 按数据源执行版本化迁移，迁移定义在 internal/migrations
+	// DDL 和数据回填可能执行较久，不受数据源 query_timeout 限制
+	ctx := database.WithQueryTimeout(context.Background(), 0)
+	for _, name := range names {
+		migrator := migrate.New(dataSources[name], migrations.For(name))
 
-	err = mainDB.AutoMigrate(
-		&model.User{},
-		// 在这里添加其他模型
-	)
+		if *statusOnly {
+			status, err := migrator.Status(ctx)
+			if err != nil {
+				zapLogger.Fatal("Failed to load migration status", zap.String("data_source", name), zap.Error(err))
+			}
+			fmt.Printf("[%s] version=%q applied=%d pending=%d\n", name, status.Version, len(status.Applied), len(status.Pending))
+			for _, m := range status.Pending {
+				fmt.Printf("  pending %s_%s\n", m.Version, m.Name)
+			}
+			continue
+		}
 
-	if err != nil {
-		zapLogger.Fatal("Failed to run auto migration", zap.Error(err))
+		zapLogger.Info("Running migrations...", zap.String("data_source", name))
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			zapLogger.Info("Migration applied",
+				zap.String("data_source", name),
+				zap.String("version", m.Version),
+				zap.String("name", m.Name),
+			)
+		}
+		if err != nil {
+			zapLogger.Fatal("Failed to run migrations", zap.String("data_source", name), zap.Error(err))
+		}
+	}
+
+	if *statusOnly {
+		return
 	}
 
 	zapLogger.Info("Database migration completed successfully!")
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/model"
+	"github.com/hedeqiang/skeleton/internal/wire"
+	"github.com/hedeqiang/skeleton/pkg/logger"
+	"github.com/hedeqiang/skeleton/pkg/mq"
+
+	"go.uber.org/zap"
+)
+
+// 重放归档消息：按条件读取 message_archive 中的消息，交给消费者进程注册的处理器重新处理
+// 用于重建读模型或排查历史事件，处理器需要保证幂等
+func main() {
+	messageType := flag.String("type", "", "只重放该类型的消息")
+	queue := flag.String("queue", "", "只重放来自该队列的消息")
+	outcome := flag.String("outcome", mq.OutcomeAcked, "只重放该处理结果的消息，为空时重放全部")
+	since := flag.String("since", "", "起始归档时间（包含），格式 RFC3339 或 2006-01-02")
+	until := flag.String("until", "", "结束归档时间（不包含），格式 RFC3339 或 2006-01-02")
+	dryRun := flag.Bool("dry-run", false, "只打印匹配的消息，不处理")
+	flag.Parse()
+
+	query := &model.MessageArchiveQuery{
+		MessageType: *messageType,
+		Queue:       *queue,
+		Outcome:     *outcome,
+	}
+	var err error
+	if query.Since, err = parseTime(*since); err != nil {
+		log.Fatalf("Invalid -since: %v", err)
+	}
+	if query.Until, err = parseTime(*until); err != nil {
+		log.Fatalf("Invalid -until: %v", err)
+	}
+
+	// 使用消费者的注入器，处理器及其依赖与消费者进程一致
+	consumerApp, err := wire.InitializeConsumer()
+	if err != nil {
+		log.Fatalf("Failed to initialize application: %v", err)
+	}
+	application := consumerApp.App
+	if consumerApp.Archiver == nil {
+		application.Logger().Fatal("Message archive is not enabled, set message_archive.enabled to true")
+	}
+
+	ctx := context.Background()
+	var replayed, failed int
+	err = consumerApp.Archiver.Sink().Replay(ctx, query, func(m *model.ArchivedMessage) error {
+		if *dryRun {
+			fmt.Printf("%s\t%s\t%s\t%s\t%s\n", m.ArchivedAt.Format(time.RFC3339), m.MessageID, m.MessageType, m.Queue, m.Outcome)
+			replayed++
+			return nil
+		}
+
+		msgCtx := logger.WithFields(ctx, zap.String("queue", m.Queue), zap.Bool("replay", true))
+		if err := consumerApp.Service.ConsumeMessage(msgCtx, []byte(m.Body)); err != nil {
+			application.Logger().Error("Failed to replay message", zap.String("message_id", m.MessageID), zap.Error(err))
+			failed++
+			return nil
+		}
+		replayed++
+		return nil
+	})
+	if err != nil {
+		application.Logger().Error("Replay stopped", zap.Error(err))
+	}
+
+	fmt.Printf("Replayed %d messages, %d failed\n", replayed, failed)
+
+	stopCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if err := application.Stop(stopCtx); err != nil {
+		application.Logger().Error("Error during application shutdown", zap.Error(err))
+	}
+}
+
+// parseTime 解析 RFC3339 时间或日期，空字符串返回零值
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/hedeqiang/skeleton/internal/service"
+	"github.com/hedeqiang/skeleton/internal/wire"
+
+	"go.uber.org/zap"
+)
+
+// 搜索索引管理命令
+//
+//	go run scripts/search/main.go reindex [-index users] [-task]
+//
+// reindex 从数据库分批读取数据写入新版本索引，完成后原子切换别名并删除旧索引
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Usage: search reindex [-index users] [-task]")
+	}
+	flag.Parse()
+	if flag.NArg() < 1 || flag.Arg(0) != "reindex" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cmd := flag.NewFlagSet("reindex", flag.ExitOnError)
+	index := cmd.String("index", service.UserIndex.Name, "要重建的索引逻辑名称")
+	withTask := cmd.Bool("task", false, "创建后台任务记录进度，可通过 GET /api/v1/tasks/{id} 查询")
+	cmd.Parse(flag.Args()[1:])
+
+	application, err := wire.InitializeApplication()
+	if err != nil {
+		log.Fatalf("Failed to initialize application: %v", err)
+	}
+	zapLogger := application.Logger()
+	if application.SearchService == nil {
+		zapLogger.Fatal("Search is not enabled, set search.enabled to true")
+	}
+
+	// Ctrl+C 中止重建，未切换的新索引会被删除
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var taskID string
+	if *withTask {
+		task, err := application.TaskService.CreateTask(ctx, service.TaskTypeSearchReindex, 0)
+		if err != nil {
+			zapLogger.Fatal("Failed to create reindex task", zap.Error(err))
+		}
+		taskID = task.ID
+		fmt.Printf("Reindex task: %s\n", taskID)
+	}
+
+	result, err := application.SearchService.Reindex(ctx, *index, taskID)
+	if err != nil {
+		zapLogger.Error("Reindex failed", zap.String("index", *index), zap.Error(err))
+	} else {
+		fmt.Printf("Reindexed %d documents into %s in %s, removed %v\n", result.Indexed, result.Index, result.Duration.Round(time.Millisecond), result.Removed)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if stopErr := application.Stop(stopCtx); stopErr != nil {
+		zapLogger.Error("Error during application shutdown", zap.Error(stopErr))
+	}
+	if err != nil {
+		os.Exit(1)
+	}
+}
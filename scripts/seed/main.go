@@ -5,11 +5,11 @@ import (
 	"github.com/hedeqiang/skeleton/internal/model"
 	"github.com/hedeqiang/skeleton/pkg/database"
 	"github.com/hedeqiang/skeleton/pkg/logger"
+	"github.com/hedeqiang/skeleton/pkg/password"
 	"fmt"
 	"log"
 
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
@@ -30,7 +30,7 @@ func main() {
 	defer zapLogger.Sync()
 
 	// 3. 初始化数据库连接
-	dataSources, err := database.NewDatabases(cfg.Databases)
+	dataSources, err := database.NewDatabases(cfg.Databases, zapLogger)
 	if err != nil {
 		zapLogger.Fatal("Failed to initialize databases", zap.Error(err))
 	}
@@ -44,7 +44,11 @@ func main() {
 	// 5. 创建种子数据
 	zapLogger.Info("Creating seed data...")
 
-	if err := seedUsers(mainDB, zapLogger); err != nil {
+	passwords, err := password.New(cfg)
+	if err != nil {
+		zapLogger.Fatal("Failed to initialize password hasher", zap.Error(err))
+	}
+	if err := seedUsers(mainDB, passwords, zapLogger); err != nil {
 		zapLogger.Fatal("Failed to seed users", zap.Error(err))
 	}
 
@@ -53,7 +57,7 @@ func main() {
 }
 
 // seedUsers 创建示例用户数据
-func seedUsers(db *gorm.DB, logger *zap.Logger) error {
+func seedUsers(db *gorm.DB, passwords *password.Hasher, logger *zap.Logger) error {
 	// 检查是否已经有用户数据
 	var count int64
 	if err := db.Model(&model.User{}).Count(&count).Error; err != nil {
@@ -70,19 +74,19 @@ func seedUsers(db *gorm.DB, logger *zap.Logger) error {
 		{
 			Username: "admin",
 			Email:    "admin@example.com",
-			Password: hashPassword("admin123"),
+			Password: hashPassword(passwords, "admin123"),
 			Status:   1,
 		},
 		{
 			Username: "testuser",
 			Email:    "test@example.com",
-			Password: hashPassword("test123"),
+			Password: hashPassword(passwords, "test123"),
 			Status:   1,
 		},
 		{
 			Username: "john_doe",
 			Email:    "john@example.com",
-			Password: hashPassword("john123"),
+			Password: hashPassword(passwords, "john123"),
 			Status:   1,
 		},
 	}
@@ -99,11 +103,11 @@ func seedUsers(db *gorm.DB, logger *zap.Logger) error {
 	return nil
 }
 
-// hashPassword 加密密码
-func hashPassword(password string) string {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+// hashPassword 按 password 配置加密密码
+func hashPassword(passwords *password.Hasher, plain string) string {
+	hashedPassword, err := passwords.Hash(plain)
 	if err != nil {
 		log.Fatalf("Failed to hash password: %v", err)
 	}
-	return string(hashedPassword)
+	return hashedPassword
 }